@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pocket-doc/internal/catalog"
+	"pocket-doc/internal/pipeline"
+)
+
+// runCatalog pushes the tables, columns and descriptions of the schema to
+// the data catalog of the catalog section
+func runCatalog(ctx context.Context, args []string) error {
+	fs := newFlagSet("catalog", "[flags]")
+	conn := addConnFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, schema, err := loadSchema(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if cfg.Catalog.Type == "" {
+		return errors.New("no catalog configured (set catalog.type and catalog.url)")
+	}
+
+	pusher, err := catalog.New(pipeline.Catalog(cfg.Catalog))
+	if err != nil {
+		return err
+	}
+	res, err := pusher.Push(ctx, schema)
+	if res != nil && (res.Tables > 0 || res.Views > 0) {
+		fmt.Printf("📚 Pushed %d tables and %d views in %d schemas to %s\n", res.Tables, res.Views, res.Schemas, cfg.Catalog.Type)
+	}
+	return err
+}
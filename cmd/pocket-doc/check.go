@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"pocket-doc/internal/annotation"
+	"pocket-doc/internal/config"
+	"pocket-doc/internal/dbquery"
+	"pocket-doc/internal/exporter"
+	"pocket-doc/internal/extractor"
+	"pocket-doc/internal/glossary"
+	"pocket-doc/internal/logging"
+	"pocket-doc/internal/pii"
+	"pocket-doc/internal/pipeline"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// checkReport prints check results and counts failures
+type checkReport struct {
+	failures   int
+	dbFailures int // connection and privilege failures
+}
+
+func (r *checkReport) ok(item, detail string) {
+	fmt.Printf("  ✅ %-22s %s\n", item, detail)
+}
+
+func (r *checkReport) warn(item, detail string) {
+	fmt.Printf("  ⚠️  %-22s %s\n", item, detail)
+}
+
+func (r *checkReport) fail(item, detail, hint string) {
+	r.failures++
+	fmt.Printf("  ❌ %-22s %s\n", item, detail)
+	if hint != "" {
+		fmt.Printf("     %-22s → %s\n", "", hint)
+	}
+}
+
+// failDB reports a failure of the database itself (exit code 2 unless the
+// configuration failed as well)
+func (r *checkReport) failDB(item, detail, hint string) {
+	r.dbFailures++
+	r.fail(item, detail, hint)
+}
+
+// runCheck validates the configuration, connects and probes catalog access
+// for the connection (or every multi.profiles entry) without extracting
+func runCheck(ctx context.Context, args []string) error {
+	fs := newFlagSet("check", "[flags]")
+	conn := addConnFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	profiles := splitList(conn.profiles)
+	if len(profiles) == 0 && conn.profile == "" {
+		if base, err := config.ReadProfile(conn.configFile, ""); err == nil {
+			profiles = base.Multi.Profiles
+		}
+	}
+
+	r := &checkReport{}
+	if len(profiles) == 0 {
+		fmt.Printf("Checking %s\n", conn.configFile)
+		cfg, err := readConnConfig(conn)
+		r.check(ctx, cfg, err, conn)
+	}
+	for _, name := range profiles {
+		fmt.Printf("Checking %s (profile %s)\n", conn.configFile, name)
+		cfg, err := config.ReadProfile(conn.configFile, name)
+		r.check(ctx, cfg, err, nil)
+	}
+
+	if r.failures > 0 {
+		err := fmt.Errorf("❌ Check failed: %d problem(s)", r.failures)
+		if r.failures == r.dbFailures {
+			return connectionError(err)
+		}
+		return configError(err)
+	}
+	fmt.Println("\n✅ All checks passed")
+	return nil
+}
+
+// check reports on one connection. conn re-applies the connection flags
+// after secret store credentials, as loadConnConfig does; nil skips that.
+func (r *checkReport) check(ctx context.Context, cfg *config.Config, err error, conn *connFlags) {
+	if err != nil {
+		r.fail("configuration", err.Error(), "")
+		return
+	}
+	r.ok("configuration", "loaded")
+
+	// Settings
+	configErrors := cfg.Check(extractor.IsSupported, exporter.IsSupported)
+	for _, e := range configErrors {
+		r.fail("settings", e.Error(), "")
+	}
+	if len(configErrors) == 0 {
+		r.ok("settings", fmt.Sprintf("%s at %s:%d/%s", cfg.Database.Type, cfg.Database.Host, cfg.Database.Port, cfg.Database.Database))
+	}
+	if _, err := exporter.NewExporter("json", pipeline.ExporterConfig(cfg)); err != nil {
+		r.fail("output", err.Error(), "")
+	}
+	if err := pipeline.LintRules(cfg.Lint).Validate(); err != nil {
+		r.fail("lint", err.Error(), "")
+	}
+	if _, err := logging.New(io.Discard, cfg.Logging.Level, cfg.Logging.Format); err != nil {
+		r.fail("logging", err.Error(), "")
+	}
+	if cfg.Output.GlossaryFile != "" {
+		if g, err := glossary.Load(cfg.Output.GlossaryFile); err != nil {
+			r.fail("glossary", err.Error(), "")
+		} else if err := g.Validate(); err != nil {
+			r.fail("glossary", err.Error(), "")
+		} else {
+			r.ok("glossary", fmt.Sprintf("%d terms", len(g.Terms)))
+		}
+	}
+	if cfg.Output.AnnotationsFile != "" {
+		if a, err := annotation.Load(cfg.Output.AnnotationsFile); err != nil {
+			r.fail("annotations", err.Error(), "")
+		} else {
+			r.ok("annotations", fmt.Sprintf("%d objects", a.Len()))
+		}
+	}
+	if cfg.Output.PIIRulesFile != "" {
+		if rules, err := pii.Load(cfg.Output.PIIRulesFile); err != nil {
+			r.fail("pii", err.Error(), "")
+		} else {
+			r.ok("pii", fmt.Sprintf("%d rules", len(rules.Rules)))
+		}
+	}
+
+	// Credentials
+	if cfg.Credentials.Provider != "" {
+		if err := fetchCredentials(ctx, cfg); err != nil {
+			r.fail("credentials", err.Error(), "")
+			return
+		}
+		r.ok("credentials", "fetched from "+cfg.Credentials.Provider)
+		if conn != nil {
+			conn.apply(cfg)
+		}
+	}
+	if cfg.Database.Username == "" {
+		r.warn("credentials", "no username configured")
+	}
+
+	if len(configErrors) > 0 {
+		r.warn("connection", "skipped until the settings are fixed")
+		return
+	}
+
+	// Connection
+	ext, err := pipeline.NewExtractor(cfg)
+	if err != nil {
+		r.fail("connection", err.Error(), "")
+		return
+	}
+	defer ext.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if err := ext.Connect(ctx); err != nil {
+		r.failDB("connection", err.Error(), "check host, port, firewall rules and credentials")
+		return
+	}
+	r.ok("connection", fmt.Sprintf("connected in %s", time.Since(start).Round(time.Millisecond)))
+
+	if name, version, err := ext.GetDatabaseInfo(ctx); err != nil {
+		r.failDB("database info", err.Error(), "")
+	} else {
+		r.ok("database info", fmt.Sprintf("%s (%s)", name, strings.SplitN(version, "\n", 2)[0]))
+	}
+
+	// Version and catalog privileges
+	preflight, ok, err := extractor.RunPreflight(ctx, ext)
+	if !ok {
+		r.warn("catalog privileges", "not checked: extractor does not support probing")
+		return
+	}
+	denied := 0
+	for _, c := range preflight.Catalogs {
+		if c.Err != nil {
+			denied++
+			r.failDB("catalog "+c.Catalog, strings.SplitN(c.Err.Error(), "\n", 2)[0], "")
+		}
+	}
+	var unsupported *dbquery.UnsupportedVersionError
+	switch {
+	case denied > 0:
+		if hint := dbquery.PrivilegeHint(canonicalType(cfg.Database.Type), "", cfg.Database.Username); hint != "" {
+			fmt.Printf("     → %s\n", hint)
+		}
+	case errors.As(err, &unsupported):
+		r.failDB("database version", err.Error(), dbquery.Hint(err))
+	case err != nil:
+		r.failDB("catalog privileges", err.Error(), dbquery.Hint(err))
+	default:
+		n := len(preflight.Catalogs)
+		r.ok("catalog privileges", fmt.Sprintf("%d/%d catalog views readable", n, n))
+	}
+	if err != nil {
+		return
+	}
+
+	// Capabilities: the sections an extraction would leave out
+	for _, c := range preflight.Sections {
+		if len(c.Missing) == 0 {
+			r.ok("section "+c.Section, "extracted")
+		} else {
+			r.warn("section "+c.Section, "skipped: cannot read "+missingCatalogs(c))
+		}
+	}
+}
+
+// missingCatalogs lists the unreadable catalogs of a section
+func missingCatalogs(c extractor.Capability) string {
+	names := make([]string, len(c.Missing))
+	for i, m := range c.Missing {
+		names[i] = m.Catalog
+	}
+	return strings.Join(names, ", ")
+}
+
+// canonicalType maps database type aliases to the privilege hint keys
+func canonicalType(dbType string) string {
+	switch t := strings.ToLower(dbType); t {
+	case "postgres", "pg":
+		return "postgresql"
+	case "sqlserver":
+		return "mssql"
+	default:
+		return t
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"pocket-doc/internal/comments"
+)
+
+// runComments implements "pocket-doc comments [flags] FILE": it diffs the
+// comments of a reviewed spreadsheet against the live schema and writes
+// the statements that apply them. The database is only read.
+func runComments(ctx context.Context, args []string) error {
+	fs := newFlagSet("comments", "[flags] FILE\n  FILE: Excel export (.xlsx) or CSV in the layout of its Columns sheet, with reviewed comments")
+	conn := addConnFlags(fs)
+	output := fs.String("output", "", "Script file (default: stdout)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return configError(errors.New("comments needs one argument: FILE"))
+	}
+	if conn.profiles != "" {
+		return configError(errors.New("comments scripts one database; use -profile instead of -profiles"))
+	}
+
+	cfg, err := loadConnConfig(ctx, conn)
+	if err != nil {
+		return configError(err)
+	}
+	// The live comments, without glossary, annotations or dbt descriptions
+	schema, err := extractSchema(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	imported, err := comments.Import(fs.Arg(0), schema)
+	if err != nil {
+		return configError(err)
+	}
+	for _, s := range imported.Skipped {
+		stats.warn("⚠️  " + s)
+	}
+	script, err := comments.Script(schema, imported.Edits)
+	if err != nil {
+		return configError(err)
+	}
+
+	if *output == "" {
+		fmt.Print(script)
+	} else {
+		if err := os.WriteFile(*output, []byte(script), 0644); err != nil {
+			return fmt.Errorf("failed to write script: %w", err)
+		}
+		log.Printf("Comment script written to %s", *output)
+	}
+	log.Printf("✅ Comments: %d changed, %d unchanged, %d skipped", len(imported.Edits), imported.Unchanged, len(imported.Skipped))
+	return nil
+}
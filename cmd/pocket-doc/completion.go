@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"pocket-doc/internal/diff"
+	"pocket-doc/internal/exporter"
+	"pocket-doc/internal/extractor"
+	"pocket-doc/internal/i18n"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// shells lists the shells "pocket-doc completion" writes scripts for
+var shells = []string{"bash", "zsh", "fish"}
+
+// completionCommand describes one subcommand for a completion script
+type completionCommand struct {
+	name    string
+	summary string
+	flags   []completionFlag
+	args    []string // values of positional arguments
+	files   bool     // positional arguments are files
+}
+
+// completionFlag describes one flag of a subcommand
+type completionFlag struct {
+	name   string
+	usage  string
+	isBool bool
+	values []string // accepted values; none means free text
+	files  bool     // the value is a file name
+}
+
+// runCompletion prints the completion script for the given shell
+func runCompletion(ctx context.Context, args []string) error {
+	fs := newFlagSet("completion", strings.Join(shells, "|"))
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return configError(fmt.Errorf("completion needs one argument: %s", strings.Join(shells, ", ")))
+	}
+
+	cmds := completionCommands(ctx)
+	switch fs.Arg(0) {
+	case "bash":
+		return writeBashCompletion(os.Stdout, cmds)
+	case "zsh":
+		return writeZshCompletion(os.Stdout, cmds)
+	case "fish":
+		return writeFishCompletion(os.Stdout, cmds)
+	}
+	return configError(fmt.Errorf("unsupported shell: %s (use %s)", fs.Arg(0), strings.Join(shells, ", ")))
+}
+
+// completionCommands describes every subcommand with its flags and values
+func completionCommands(ctx context.Context) []completionCommand {
+	names := make([]string, 0, len(commands))
+	for _, c := range commands {
+		names = append(names, c.name)
+	}
+
+	var cmds []completionCommand
+	for _, c := range commands {
+		cc := completionCommand{name: c.name, summary: c.summary}
+		switch c.name {
+		case "help":
+			cc.args = names
+		case "completion":
+			cc.args = shells
+		case "diff":
+			cc.files = true
+		}
+		for _, f := range commandFlags(ctx, c) {
+			_, isBool := f.Value.(interface{ IsBoolFlag() bool })
+			cc.flags = append(cc.flags, completionFlag{
+				name:   f.Name,
+				usage:  f.Usage,
+				isBool: isBool,
+				values: flagValues(c.name, f.Name),
+				files:  f.Name == "config" || f.Name == "output" || f.Name == "compare",
+			})
+		}
+		cmds = append(cmds, cc)
+	}
+	return cmds
+}
+
+// commandFlags returns the flags of a command. Commands build their flag
+// set when they run, so this runs the command with -h (which returns right
+// after parsing) and takes the flag set from newFlagSet.
+func commandFlags(ctx context.Context, c command) []*flag.Flag {
+	switch c.name {
+	case "version", "help", "completion":
+		return nil
+	}
+
+	var fs *flag.FlagSet
+	collectFlags = func(f *flag.FlagSet) {
+		f.SetOutput(io.Discard)
+		fs = f
+	}
+	defer func() { collectFlags = nil }()
+	c.run(ctx, []string{"-h"})
+
+	var flags []*flag.Flag
+	if fs != nil {
+		fs.VisitAll(func(f *flag.Flag) { flags = append(flags, f) })
+	}
+	return flags
+}
+
+// flagValues returns the accepted values of a command's flag, or nil for
+// free text
+func flagValues(cmd, name string) []string {
+	switch name {
+	case "format":
+		if cmd == "diff" {
+			return diff.Formats
+		}
+		return exporter.GetSupportedFormats()
+	case "db-type", "type":
+		return extractor.GetSupportedDatabases()
+	case "layout":
+		return []string{layoutCombined, layoutSeparate, layoutEnvironments}
+	case "log-level":
+		return []string{"debug", "info", "warn", "error"}
+	case "language":
+		return i18n.Languages()
+	}
+	return nil
+}
+
+// writeBashCompletion writes a bash completion script
+func writeBashCompletion(w io.Writer, cmds []completionCommand) error {
+	var b strings.Builder
+	b.WriteString("# bash completion for pocket-doc (generated by \"pocket-doc completion bash\")\n")
+	b.WriteString("_pocket_doc() {\n")
+	b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	b.WriteString("    local flags=\"\" args=\"\"\n")
+	b.WriteString("    if [[ $COMP_CWORD -eq 1 ]]; then\n")
+	fmt.Fprintf(&b, "        COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", strings.Join(commandNames(cmds), " "))
+	b.WriteString("        return\n")
+	b.WriteString("    fi\n\n")
+
+	b.WriteString("    case \"${COMP_WORDS[1]}:$prev\" in\n")
+	for _, c := range cmds {
+		for _, f := range c.flags {
+			switch {
+			case len(f.values) > 0:
+				fmt.Fprintf(&b, "        %s:-%s) COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")); return ;;\n",
+					c.name, f.name, strings.Join(f.values, " "))
+			case f.files:
+				fmt.Fprintf(&b, "        %s:-%s) COMPREPLY=($(compgen -f -- \"$cur\")); return ;;\n", c.name, f.name)
+			case !f.isBool:
+				fmt.Fprintf(&b, "        %s:-%s) return ;;\n", c.name, f.name)
+			}
+		}
+	}
+	b.WriteString("    esac\n\n")
+
+	b.WriteString("    case \"${COMP_WORDS[1]}\" in\n")
+	for _, c := range cmds {
+		if len(c.flags) == 0 && len(c.args) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "        %s) flags=\"%s\" args=\"%s\" ;;\n", c.name, strings.Join(flagNames(c.flags), " "), strings.Join(c.args, " "))
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("    if [[ $cur == -* ]]; then\n")
+	b.WriteString("        COMPREPLY=($(compgen -W \"$flags\" -- \"$cur\"))\n")
+	b.WriteString("    else\n")
+	b.WriteString("        COMPREPLY=($(compgen -W \"$args\" -- \"$cur\"))\n")
+	b.WriteString("    fi\n")
+	b.WriteString("}\n")
+	b.WriteString("complete -o default -F _pocket_doc pocket-doc\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeZshCompletion writes a zsh completion script; install it as
+// _pocket-doc in a directory on $fpath, or source it
+func writeZshCompletion(w io.Writer, cmds []completionCommand) error {
+	var b strings.Builder
+	b.WriteString("#compdef pocket-doc\n")
+	b.WriteString("# zsh completion for pocket-doc (generated by \"pocket-doc completion zsh\")\n\n")
+	b.WriteString("_pocket_doc() {\n")
+	b.WriteString("    local -a items\n")
+	b.WriteString("    if (( CURRENT == 2 )); then\n")
+	b.WriteString("        items=(\n")
+	for _, c := range cmds {
+		fmt.Fprintf(&b, "            %s\n", zshQuote(c.name+":"+c.summary))
+	}
+	b.WriteString("        )\n")
+	b.WriteString("        _describe -t commands 'pocket-doc command' items\n")
+	b.WriteString("        return\n")
+	b.WriteString("    fi\n\n")
+
+	b.WriteString("    case \"${words[2]}:${words[CURRENT-1]}\" in\n")
+	for _, c := range cmds {
+		for _, f := range c.flags {
+			switch {
+			case len(f.values) > 0:
+				fmt.Fprintf(&b, "        %s:-%s) compadd -- %s; return ;;\n", c.name, f.name, strings.Join(f.values, " "))
+			case f.files:
+				fmt.Fprintf(&b, "        %s:-%s) _files; return ;;\n", c.name, f.name)
+			case !f.isBool:
+				fmt.Fprintf(&b, "        %s:-%s) return ;;\n", c.name, f.name)
+			}
+		}
+	}
+	b.WriteString("    esac\n\n")
+
+	b.WriteString("    case \"${words[2]}\" in\n")
+	for _, c := range cmds {
+		if len(c.flags) == 0 && len(c.args) == 0 && !c.files {
+			continue
+		}
+		fmt.Fprintf(&b, "        %s)\n", c.name)
+		if len(c.flags) > 0 {
+			b.WriteString("            if [[ ${words[CURRENT]} == -* ]]; then\n")
+			b.WriteString("                items=(\n")
+			for _, f := range c.flags {
+				fmt.Fprintf(&b, "                    %s\n", zshQuote("-"+f.name+":"+f.usage))
+			}
+			b.WriteString("                )\n")
+			b.WriteString("                _describe -t flags 'flag' items\n")
+			b.WriteString("                return\n")
+			b.WriteString("            fi\n")
+		}
+		switch {
+		case len(c.args) > 0:
+			fmt.Fprintf(&b, "            compadd -- %s\n", strings.Join(c.args, " "))
+		case c.files:
+			b.WriteString("            _files\n")
+		}
+		b.WriteString("            ;;\n")
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("}\n\n")
+	b.WriteString("if [[ \"${funcstack[1]}\" == \"_pocket-doc\" ]]; then\n")
+	b.WriteString("    _pocket_doc \"$@\"\n")
+	b.WriteString("else\n")
+	b.WriteString("    compdef _pocket_doc pocket-doc\n")
+	b.WriteString("fi\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeFishCompletion writes a fish completion script
+func writeFishCompletion(w io.Writer, cmds []completionCommand) error {
+	var b strings.Builder
+	b.WriteString("# fish completion for pocket-doc (generated by \"pocket-doc completion fish\")\n")
+	b.WriteString("complete -c pocket-doc -f\n")
+	for _, c := range cmds {
+		fmt.Fprintf(&b, "complete -c pocket-doc -n __fish_use_subcommand -a %s -d %s\n", c.name, fishQuote(c.summary))
+	}
+
+	for _, c := range cmds {
+		cond := fishQuote("__fish_seen_subcommand_from " + c.name)
+		for _, f := range c.flags {
+			fmt.Fprintf(&b, "complete -c pocket-doc -n %s -o %s", cond, f.name)
+			switch {
+			case len(f.values) > 0:
+				fmt.Fprintf(&b, " -x -a %s", fishQuote(strings.Join(f.values, " ")))
+			case f.files:
+				b.WriteString(" -r -F")
+			case !f.isBool:
+				b.WriteString(" -x")
+			}
+			fmt.Fprintf(&b, " -d %s\n", fishQuote(f.usage))
+		}
+		switch {
+		case len(c.args) > 0:
+			fmt.Fprintf(&b, "complete -c pocket-doc -n %s -a %s\n", cond, fishQuote(strings.Join(c.args, " ")))
+		case c.files:
+			fmt.Fprintf(&b, "complete -c pocket-doc -n %s -F\n", cond)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func commandNames(cmds []completionCommand) []string {
+	names := make([]string, len(cmds))
+	for i, c := range cmds {
+		names[i] = c.name
+	}
+	return names
+}
+
+func flagNames(flags []completionFlag) []string {
+	names := make([]string, len(flags))
+	for i, f := range flags {
+		names[i] = "-" + f.name
+	}
+	return names
+}
+
+// zshQuote single-quotes s for zsh
+func zshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote single-quotes s for fish, which only escapes \ and ' inside
+func fishQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+	return "'" + s + "'"
+}
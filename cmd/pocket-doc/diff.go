@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"pocket-doc/internal/ci"
+	"pocket-doc/internal/diff"
+	"pocket-doc/internal/i18n"
+	"pocket-doc/internal/model"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// runDiff implements "pocket-doc diff [flags] OLD NEW". OLD and NEW are JSON
+// snapshots (see "pocket-doc snapshot") or config files of live databases.
+func runDiff(ctx context.Context, args []string) error {
+	fs := newFlagSet("diff", "[flags] OLD NEW\n  OLD, NEW: schema snapshot (.json) or config file (.yaml[#profile]) of a live database")
+	format := fs.String("format", "text", "Report format: "+strings.Join(diff.Formats, ", "))
+	output := fs.String("output", "", "Output file name without extension (default: stdout)")
+	language := fs.String("language", "en", "Report language")
+	failOnChange := fs.Bool("fail-on-change", false, "Exit with code 1 when the schemas differ (schema drift gate)")
+	ciMode := addCIFlag(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return configError(fmt.Errorf("diff needs two arguments: OLD NEW"))
+	}
+
+	oldSchema, err := loadDiffSource(ctx, fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", fs.Arg(0), err)
+	}
+	newSchema, err := loadDiffSource(ctx, fs.Arg(1))
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", fs.Arg(1), err)
+	}
+
+	result := diff.Compare(oldSchema, newSchema)
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		filename := *output + diff.FileExtension(*format)
+		f, err := os.Create(filename)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+		log.Printf("Writing diff report to %s...", filename)
+	}
+
+	if err := diff.Write(w, result, *format, i18n.New(*language)); err != nil {
+		return fmt.Errorf("failed to write diff report: %w", err)
+	}
+	log.Printf("✅ Diff complete: %d added, %d removed, %d changed",
+		result.Count(diff.Added), result.Count(diff.Removed), result.Count(diff.Changed))
+
+	if *ciMode {
+		if err := diffCI(result, *failOnChange, i18n.New(*language)); err != nil {
+			return err
+		}
+	}
+	if *failOnChange && len(result.Changes) > 0 {
+		return fmt.Errorf("❌ Schema drift: %d change(s)", len(result.Changes))
+	}
+	return nil
+}
+
+// diffCI annotates the changes of a diff (as errors when they fail the
+// run), appends the changelog to the job summary and sets the changes
+// step output to their number
+func diffCI(result *diff.Result, failOnChange bool, msg *i18n.Bundle) error {
+	level := ci.LevelNotice
+	if failOnChange {
+		level = ci.LevelError
+	}
+	if err := ci.Annotate(os.Stdout, ci.DiffAnnotations(result, level)); err != nil {
+		return err
+	}
+	var summary strings.Builder
+	if err := diff.WriteMarkdown(&summary, result, msg); err != nil {
+		return err
+	}
+	if err := ci.Summary(summary.String()); err != nil {
+		return err
+	}
+	return ci.SetOutput("changes", strconv.Itoa(len(result.Changes)))
+}
+
+// loadDiffSource reads a JSON snapshot, or extracts the schema of the
+// database described by a YAML config file ("config.yaml#profile" selects a
+// connection profile)
+func loadDiffSource(ctx context.Context, path string) (*model.Schema, error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return diff.LoadSnapshot(path)
+	}
+
+	file, profile := path, ""
+	if i := strings.LastIndex(path, "#"); i > 0 {
+		file, profile = path[:i], path[i+1:]
+	}
+
+	cfg, err := loadConfig(ctx, file, profile)
+	if err != nil {
+		return nil, err
+	}
+	return extractSchema(ctx, cfg)
+}
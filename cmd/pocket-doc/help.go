@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Command groups, in help order
+const (
+	groupSetup   = "Setup"
+	groupDocs    = "Documentation"
+	groupQuality = "Quality checks"
+	groupOther   = "Other"
+)
+
+var groups = []string{groupSetup, groupDocs, groupQuality, groupOther}
+
+// overviewExamples show a typical first run in the command overview
+var overviewExamples = []string{
+	"pocket-doc init                    # write config.yaml",
+	"pocket-doc check                   # validate it and test the connection",
+	"pocket-doc export -format html     # write the documentation",
+	"pocket-doc help export             # flags and examples of a command",
+}
+
+// usage prints the command overview, grouped by purpose
+func usage() {
+	out := os.Stderr
+	fmt.Fprintf(out, "pocket-doc %s - database schema documentation\n\n", Version)
+	fmt.Fprintln(out, "Usage: pocket-doc <command> [flags]")
+	for _, g := range groups {
+		fmt.Fprintf(out, "\n%s:\n", g)
+		for _, c := range commands {
+			if c.group == g {
+				fmt.Fprintf(out, "  %-10s %s\n", c.name, c.summary)
+			}
+		}
+	}
+
+	fmt.Fprintln(out, "\nExamples:")
+	for _, e := range overviewExamples {
+		fmt.Fprintf(out, "  %s\n", e)
+	}
+	fmt.Fprintln(out, "\nRun 'pocket-doc help <command>' for command flags and examples.")
+	fmt.Fprintln(out, "Run 'pocket-doc completion bash|zsh|fish' for shell completion.")
+	fmt.Fprintln(out, "The legacy form 'pocket-doc -mode <mode> ...' is still accepted.")
+}
+
+// collectFlags, when set, receives every flag set newFlagSet creates (see
+// commandFlags)
+var collectFlags func(fs *flag.FlagSet)
+
+// newFlagSet creates the flag set of a subcommand. Its usage lists the
+// command's own flags, then the shared connection flags, then examples.
+func newFlagSet(name, usageLine string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Usage: pocket-doc %s %s\n", name, usageLine)
+		c := findCommand(name)
+		if c != nil {
+			fmt.Fprintf(out, "\n%s\n", c.summary)
+		}
+
+		conn := connFlagNames()
+		printFlags(out, "Flags", fs, func(f *flag.Flag) bool { return !conn[f.Name] })
+		printFlags(out, "Connection flags", fs, func(f *flag.Flag) bool { return conn[f.Name] })
+
+		if c != nil && len(c.examples) > 0 {
+			fmt.Fprintln(out, "\nExamples:")
+			for _, e := range c.examples {
+				fmt.Fprintf(out, "  %s\n", e)
+			}
+		}
+	}
+	if collectFlags != nil {
+		collectFlags(fs)
+	}
+	return fs
+}
+
+// connFlagNames returns the names of the flags registered by addConnFlags
+func connFlagNames() map[string]bool {
+	names := make(map[string]bool)
+	addConnFlags(flag.NewFlagSet("", flag.ContinueOnError)).fs.VisitAll(func(f *flag.Flag) {
+		names[f.Name] = true
+	})
+	return names
+}
+
+// printFlags prints the flags of fs selected by keep under heading, in the
+// flag package's default format; nothing when none is selected
+func printFlags(w io.Writer, heading string, fs *flag.FlagSet, keep func(*flag.Flag) bool) {
+	sub := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+	sub.SetOutput(w)
+	n := 0
+	fs.VisitAll(func(f *flag.Flag) {
+		if keep(f) {
+			sub.Var(f.Value, f.Name, f.Usage)
+			sub.Lookup(f.Name).DefValue = f.DefValue
+			n++
+		}
+	})
+	if n == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s:\n", heading)
+	sub.PrintDefaults()
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"pocket-doc/internal/config"
+	"pocket-doc/internal/extractor"
+	"pocket-doc/internal/pipeline"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runInit writes a starter configuration file. Values not given as flags are
+// asked for on the terminal; with -yes (or without a terminal) the defaults
+// are used.
+func runInit(ctx context.Context, args []string) error {
+	fs := newFlagSet("init", "[flags]")
+	output := fs.String("output", "config.yaml", "Configuration file to write")
+	force := fs.Bool("force", false, "Overwrite an existing file")
+	yes := fs.Bool("yes", false, "Do not prompt; use flags and defaults")
+	skipTest := fs.Bool("skip-test", false, "Do not test the database connection")
+	dbType := fs.String("type", "", "Database type ("+strings.Join(extractor.GetSupportedDatabases(), ", ")+")")
+	host := fs.String("host", "", "Database host (default localhost)")
+	port := fs.Int("port", 0, "Database port (default: standard port of the type)")
+	database := fs.String("database", "", "Database name (Oracle: service name)")
+	username := fs.String("username", "", "Database user")
+	password := fs.String("password", "", "Database password")
+	schemas := fs.String("schema", "", "Comma-separated schema/owner filter (empty = all)")
+	language := fs.String("language", "", "Document language (default en)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(*output); err == nil && !*force {
+		return fmt.Errorf("%s already exists (use -force to overwrite)", *output)
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	p := &prompter{in: bufio.NewReader(os.Stdin), enabled: !*yes && isTerminal(os.Stdin)}
+
+	cfg := config.Default()
+	db := &cfg.Database
+	db.Type = p.ask("Database type ("+strings.Join(extractor.GetSupportedDatabases(), ", ")+")", *dbType, set["type"], "postgresql")
+	db.Host = p.ask("Host", *host, set["host"], "localhost")
+
+	portDefault := config.DefaultPort(db.Type)
+	if portDefault == 0 && !set["port"] {
+		return fmt.Errorf("unsupported database type: %s (supported: %s; use -port for other types)",
+			db.Type, strings.Join(extractor.GetSupportedDatabases(), ", "))
+	}
+	portValue := p.ask("Port", strconv.Itoa(*port), set["port"], strconv.Itoa(portDefault))
+	n, err := strconv.Atoi(portValue)
+	if err != nil || n <= 0 || n > 65535 {
+		return fmt.Errorf("%w: %s", config.ErrInvalidPort, portValue)
+	}
+	db.Port = n
+
+	dbLabel := "Database"
+	if strings.EqualFold(db.Type, "oracle") {
+		dbLabel = "Service name"
+	}
+	db.Database = p.ask(dbLabel, *database, set["database"], "")
+	db.Username = p.ask("Username", *username, set["username"], "")
+	db.Password = p.ask("Password", *password, set["password"], "")
+	schemaDefault := ""
+	if strings.EqualFold(db.Type, "oracle") {
+		// Without a filter Oracle reads every schema (see config.DatabaseConfig.Schemas)
+		schemaDefault = strings.ToUpper(db.Username)
+	}
+	db.SchemaFilter = splitList(p.ask("Schema filter (comma-separated, empty = all)", *schemas, set["schema"], schemaDefault))
+	cfg.Output.Language = p.ask("Document language", *language, set["language"], "en")
+
+	// Validates the type as well; the extractor is only opened when testing
+	ext, err := pipeline.NewExtractor(cfg)
+	if err != nil {
+		return err
+	}
+	defer ext.Close()
+
+	if !*skipTest {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		log.Printf("Testing connection to %s database at %s:%d...", db.Type, db.Host, db.Port)
+		if err := ext.Connect(ctx); err != nil {
+			if !p.confirm(fmt.Sprintf("Connection failed (%v). Write %s anyway?", err, *output)) {
+				return fmt.Errorf("failed to connect to database: %w", err)
+			}
+		} else {
+			log.Println("✅ Connection successful")
+		}
+	}
+
+	if err := os.WriteFile(*output, []byte(renderConfig(cfg)), 0600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	log.Printf("✅ Configuration written: %s", *output)
+	log.Printf("   Next: pocket-doc export -config %s -format xlsx", *output)
+	return nil
+}
+
+// prompter reads answers from the terminal
+type prompter struct {
+	in      *bufio.Reader
+	enabled bool
+}
+
+// ask returns value when its flag was given; otherwise it prompts (if
+// enabled) and falls back to def on an empty answer
+func (p *prompter) ask(label, value string, given bool, def string) string {
+	if given {
+		return value
+	}
+	if !p.enabled {
+		return def
+	}
+
+	if def != "" {
+		fmt.Printf("%s [%s]: ", label, def)
+	} else {
+		fmt.Printf("%s: ", label)
+	}
+	line, _ := p.in.ReadString('\n')
+	if line = strings.TrimSpace(line); line != "" {
+		return line
+	}
+	return def
+}
+
+// confirm asks a yes/no question; it answers no when prompting is disabled
+func (p *prompter) confirm(question string) bool {
+	if !p.enabled {
+		return false
+	}
+	fmt.Printf("%s [y/N]: ", question)
+	line, _ := p.in.ReadString('\n')
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
+
+// isTerminal reports whether f is an interactive character device
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// renderConfig writes the configuration as commented YAML
+func renderConfig(cfg *config.Config) string {
+	db := cfg.Database
+	var b strings.Builder
+
+	fmt.Fprintln(&b, "# pocket-doc configuration (created by pocket-doc init)")
+	fmt.Fprintln(&b, "database:")
+	fmt.Fprintf(&b, "  type: %s\n", strconv.Quote(db.Type))
+	fmt.Fprintf(&b, "  host: %s\n", strconv.Quote(db.Host))
+	fmt.Fprintf(&b, "  port: %d\n", db.Port)
+	fmt.Fprintf(&b, "  database: %s\n", strconv.Quote(db.Database))
+	fmt.Fprintf(&b, "  username: %s\n", strconv.Quote(db.Username))
+	fmt.Fprintf(&b, "  password: %s\n", strconv.Quote(db.Password))
+	if len(db.SchemaFilter) > 0 {
+		quoted := make([]string, len(db.SchemaFilter))
+		for i, s := range db.SchemaFilter {
+			quoted[i] = strconv.Quote(s)
+		}
+		fmt.Fprintf(&b, "  schema_filter: [%s]\n", strings.Join(quoted, ", "))
+	} else {
+		fmt.Fprintln(&b, "  # schema_filter: [\"HR\", \"SALES\"]   # only document these schemas/owners")
+	}
+
+	fmt.Fprintln(&b, "\noutput:")
+	fmt.Fprintf(&b, "  language: %s             # en, ko, ja, zh-CN, de, fr, es\n", strconv.Quote(cfg.Output.Language))
+	fmt.Fprintln(&b, "  include_toc: true")
+	fmt.Fprintln(&b, "  include_cover_page: true")
+	fmt.Fprintln(&b, "  # company_name: \"\"")
+	fmt.Fprintln(&b, "  # project_name: \"\"")
+	fmt.Fprintln(&b, "  # author: \"\"")
+	fmt.Fprintln(&b, "  # classification: \"INTERNAL\"")
+	return b.String()
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runMainEnv makes the test binary run pocket-doc instead of the tests
+const runMainEnv = "POCKETDOC_TEST_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) == "1" {
+		main()
+		os.Exit(exitOK)
+	}
+	os.Exit(m.Run())
+}
+
+// dbtManifest and dbtCatalog are the artifacts of a dbt project of one
+// table and one view, documented without a database connection
+const (
+	dbtManifest = `{
+  "metadata": {"dbt_version": "1.8.2", "adapter_type": "postgres", "project_name": "shop"},
+  "nodes": {
+    "model.shop.orders": {"resource_type": "model", "name": "orders", "schema": "analytics", "description": "One row per order",
+      "config": {"materialized": "table"}, "columns": {"order_id": {"name": "order_id", "description": "Order key"}}},
+    "model.shop.stg_orders": {"resource_type": "model", "name": "stg_orders", "schema": "staging", "config": {"materialized": "view"}, "columns": {}}
+  },
+  "sources": {}
+}`
+	dbtCatalog = `{
+  "metadata": {"generated_at": "2026-03-02T09:30:00Z"},
+  "nodes": {
+    "model.shop.orders": {"metadata": {"type": "BASE TABLE", "schema": "analytics", "name": "orders"},
+      "columns": {"order_id": {"name": "order_id", "type": "integer", "index": 1}}},
+    "model.shop.stg_orders": {"metadata": {"type": "VIEW", "schema": "staging", "name": "stg_orders"}, "columns": {}}
+  },
+  "sources": {}
+}`
+)
+
+// testProject returns a directory holding the dbt artifacts in target/ and
+// a config.yaml of two profiles: shop reads them, gone a missing directory
+func testProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"target/manifest.json": dbtManifest,
+		"target/catalog.json":  dbtCatalog,
+		"config.yaml": `database:
+  type: dbt
+profiles:
+  shop:
+    database: { database: target }
+  gone:
+    database: { database: missing }
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// pocketDoc runs pocket-doc with args in dir and returns its exit code,
+// standard output and standard error
+func pocketDoc(t *testing.T, dir string, args ...string) (int, []byte, string) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, "POCKETDOC_") {
+			cmd.Env = append(cmd.Env, kv)
+		}
+	}
+	cmd.Env = append(cmd.Env, runMainEnv+"=1")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	var exit *exec.ExitError
+	if err != nil && !errors.As(err, &exit) {
+		t.Fatalf("pocket-doc %s: %v", strings.Join(args, " "), err)
+	}
+	return cmd.ProcessState.ExitCode(), stdout.Bytes(), stderr.String()
+}
+
+// TestExitCodes validates the exit code of each kind of failure
+func TestExitCodes(t *testing.T) {
+	dir := testProject(t)
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"success", []string{"export", "-profile", "shop", "-format", "json", "-output", "doc"}, exitOK},
+		{"help", []string{"help", "export"}, exitOK},
+		{"flag help", []string{"export", "-h"}, exitOK},
+		{"unknown command", []string{"publish-all"}, exitConfig},
+		{"unknown flag", []string{"export", "-colour"}, exitConfig},
+		{"unknown format", []string{"export", "-profile", "shop", "-format", "pdf"}, exitConfig},
+		{"missing config file", []string{"export", "-config", "none.yaml"}, exitConfig},
+		{"unknown profile", []string{"export", "-profile", "nowhere"}, exitConfig},
+		{"unsupported database", []string{"export", "-db-type", "sqlite"}, exitConfig},
+		{"connection", []string{"export", "-profile", "gone", "-format", "json"}, exitConnection},
+		{"all profiles failed", []string{"export", "-profiles", "gone", "-format", "json"}, exitConnection},
+		{"partial", []string{"export", "-profiles", "shop,gone", "-format", "json", "-output", "partial"}, exitPartial},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, _, stderr := pocketDoc(t, dir, tt.args...); code != tt.code {
+				t.Errorf("exit code %d, want %d\n%s", code, tt.code, stderr)
+			}
+		})
+	}
+}
+
+// TestExitCode validates interruption takes precedence over the class of
+// the error it caused
+func TestExitCode(t *testing.T) {
+	canceled := fmt.Errorf("failed to connect to database: %w", context.Canceled)
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{nil, exitOK},
+		{errors.New("failed to export"), exitConfig},
+		{configError(errors.New("unknown format")), exitConfig},
+		{connectionError(errors.New("connection refused")), exitConnection},
+		{canceled, exitCanceled},
+		{connectionError(canceled), exitCanceled},
+	}
+	for _, tt := range tests {
+		if code := exitCode(tt.err); code != tt.code {
+			t.Errorf("exitCode(%v) = %d, want %d", tt.err, code, tt.code)
+		}
+	}
+}
+
+// TestExportStdout validates -output - writes the document and nothing
+// else to stdout: the same bytes as the file export, with the progress
+// log on stderr
+func TestExportStdout(t *testing.T) {
+	dir := testProject(t)
+	code, stdout, stderr := pocketDoc(t, dir, "export", "-profile", "shop", "-format", "json", "-output", "-")
+	if code != exitOK {
+		t.Fatalf("exit code %d\n%s", code, stderr)
+	}
+	if !strings.Contains(stderr, "Export complete") {
+		t.Errorf("progress log missing from stderr:\n%s", stderr)
+	}
+
+	if code, _, stderr := pocketDoc(t, dir, "export", "-profile", "shop", "-format", "json", "-output", "doc"); code != exitOK {
+		t.Fatalf("file export: exit code %d\n%s", code, stderr)
+	}
+	want, err := os.ReadFile(filepath.Join(dir, "doc.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stdout, want) {
+		t.Errorf("stdout is not the document:\n%s", stdout)
+	}
+
+	// A failed run writes nothing to stdout
+	if code, stdout, _ := pocketDoc(t, dir, "export", "-profile", "gone", "-format", "json", "-output", "-"); code != exitConnection || len(stdout) != 0 {
+		t.Errorf("failed export: exit code %d, stdout %q", code, stdout)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"pocket-doc/internal/combine"
+	"pocket-doc/internal/config"
+	"pocket-doc/internal/diff"
+	"pocket-doc/internal/i18n"
+	"pocket-doc/internal/model"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// Multi-database layouts
+const (
+	layoutCombined     = "combined"     // one document, objects qualified by database
+	layoutSeparate     = "separate"     // one document per database plus an index page
+	layoutEnvironments = "environments" // one document of the same schema, flagging differences between the databases
+)
+
+// loadSources extracts the schema of the connection, or of every profile
+// listed by -profiles / multi.profiles. Several profiles are extracted
+// concurrently (multi.concurrency at a time) and returned in list order;
+// the returned configuration is the file's base configuration. Profiles
+// that fail are skipped with a warning (a partial run, exit code 3) unless
+// all of them fail or the run is interrupted.
+func loadSources(ctx context.Context, conn *connFlags) (*config.Config, []combine.Part, error) {
+	profiles := splitList(conn.profiles)
+	if len(profiles) == 0 {
+		if base, err := config.ReadProfile(conn.configFile, ""); err == nil {
+			profiles = base.Multi.Profiles
+		}
+	}
+
+	if len(profiles) == 0 {
+		cfg, err := loadConnConfig(ctx, conn)
+		if err != nil {
+			return nil, nil, configError(err)
+		}
+		schema, err := extractSchema(ctx, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Printf("✅ Extraction complete: %d tables, %d views, %d routines",
+			len(schema.Tables), len(schema.Views), len(schema.Routines))
+		return cfg, []combine.Part{{Name: cfg.Profile, Schema: schema}}, nil
+	}
+
+	var conflict []string
+	conn.fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "profile", "dsn", "db-type", "host", "port", "database", "user", "password":
+			conflict = append(conflict, "-"+f.Name)
+		}
+	})
+	if len(conflict) > 0 {
+		return nil, nil, configError(fmt.Errorf("%s cannot be combined with multiple profiles", strings.Join(conflict, ", ")))
+	}
+
+	base, err := loadConfig(ctx, conn.configFile, "")
+	if err != nil {
+		return nil, nil, configError(err)
+	}
+	if err := setupLogging(base.Logging, conn.logLevel); err != nil {
+		return nil, nil, configError(err)
+	}
+
+	limit := base.Multi.Concurrency
+	if limit <= 0 {
+		limit = 4
+	}
+	log.Printf("Extracting %d databases (%s), %d at a time...", len(profiles), strings.Join(profiles, ", "), limit)
+
+	parts := make([]combine.Part, len(profiles))
+	errs := make([]error, len(profiles))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i, name := range profiles {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			cfg, err := loadConfig(ctx, conn.configFile, name)
+			if err != nil {
+				err = configError(err)
+				stats.extracted(name, config.DatabaseConfig{}, nil, 0, err)
+			} else {
+				conn.applyFilters(cfg)
+				parts[i].Schema, err = extractSchema(ctx, cfg)
+			}
+			if err != nil {
+				errs[i] = fmt.Errorf("profile %s: %w", name, err)
+				return
+			}
+			parts[i].Name = name
+			log.Printf("✅ %s: %d tables, %d views, %d routines", name,
+				len(parts[i].Schema.Tables), len(parts[i].Schema.Views), len(parts[i].Schema.Routines))
+		}(i, name)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		// Interrupted: don't export a partial document
+		return nil, nil, err
+	}
+
+	var ok []combine.Part
+	var firstErr error
+	for i, err := range errs {
+		if err == nil {
+			ok = append(ok, parts[i])
+			continue
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		stats.warn(fmt.Sprintf("⚠️  Skipping %v", err))
+	}
+	if len(ok) == 0 {
+		return nil, nil, firstErr
+	}
+	return base, ok, nil
+}
+
+// consolidate documents the same schema extracted from several
+// environments as one (see diff.Consolidate), the first profile being the
+// reference
+func consolidate(parts []combine.Part) *model.Schema {
+	schema := diff.Consolidate(parts)
+	names := make([]string, len(parts))
+	for i, p := range parts {
+		names[i] = p.Name
+	}
+	schema.DatabaseName = strings.Join(names, ", ")
+	log.Printf("Consolidated %s: %d tables and views differ from %s", schema.DatabaseName, diff.Flagged(schema), names[0])
+	return schema
+}
+
+// exportSeparate writes one document per database and an index page
+// linking them (see writeParts)
+func exportSeparate(ctx context.Context, cfg *config.Config, parts []combine.Part, format, output string) error {
+	names := make([]string, len(parts))
+	for i, p := range parts {
+		if err := enrich(cfg, p.Schema); err != nil {
+			return err
+		}
+		names[i] = p.Name
+	}
+
+	msg := i18n.New(cfg.Output.Language)
+	return writeParts(ctx, cfg, combine.Merge(parts), partSet{
+		parts:      parts,
+		title:      strings.Join(names, ", "),
+		heading:    msg.T("section.databases"),
+		perProfile: true,
+	}, format, output)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"pocket-doc/internal/config"
+	"pocket-doc/internal/notify"
+	"time"
+)
+
+// useNotify records the notifications of a configuration that wrote a
+// document. The first one is kept: the profiles of a multi-database run
+// share the output section.
+func (s *runStats) useNotify(cfg config.NotifyConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.notify == nil {
+		s.notify = &cfg
+	}
+}
+
+// announce sends the notifications of output.notify for a run that wrote
+// documents and succeeded, fully or partially. A failed notification is
+// logged; it does not fail the run.
+func (s *runStats) announce(run *notify.Run) {
+	s.mu.Lock()
+	cfg := s.notify
+	s.mu.Unlock()
+	if cfg == nil || len(run.Outputs) == 0 || (run.ExitCode != exitOK && run.ExitCode != exitPartial) {
+		return
+	}
+
+	for _, n := range notifiers(cfg) {
+		if err := n.notifier.Send(context.Background(), run); err != nil {
+			slog.Warn("⚠️  Notification failed", "error", err)
+		} else {
+			slog.Info("Notified", "channel", n.name)
+		}
+	}
+}
+
+// channel is a configured notification channel
+type channel struct {
+	name     string
+	notifier notify.Notifier
+}
+
+// notifiers returns the channels cfg configures
+func notifiers(cfg *config.NotifyConfig) []channel {
+	seconds := func(n int) time.Duration { return time.Duration(n) * time.Second }
+	var out []channel
+	if w := cfg.Webhook; w.URL != "" {
+		out = append(out, channel{"webhook", notify.Webhook{URL: w.URL, Headers: w.Headers, Timeout: seconds(w.Timeout)}})
+	}
+	if c := cfg.Slack; c.WebhookURL != "" {
+		out = append(out, channel{"slack", notify.Slack{URL: c.WebhookURL, LinkBase: cfg.LinkBase, Timeout: seconds(c.Timeout)}})
+	}
+	if c := cfg.Teams; c.WebhookURL != "" {
+		out = append(out, channel{"teams", notify.Teams{URL: c.WebhookURL, LinkBase: cfg.LinkBase, Timeout: seconds(c.Timeout)}})
+	}
+	if e := cfg.Email; e.Host != "" {
+		out = append(out, channel{"email", notify.Email{
+			Host:          e.Host,
+			Port:          e.Port,
+			Security:      e.TLS,
+			Username:      e.Username,
+			Password:      e.Password,
+			From:          e.From,
+			To:            e.To,
+			Subject:       e.Subject,
+			Formats:       e.Formats,
+			MaxAttachment: int64(e.MaxAttachmentMB) << 20,
+			Timeout:       seconds(e.Timeout),
+		}})
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"pocket-doc/internal/config"
+	"pocket-doc/internal/extractor"
+	"pocket-doc/internal/pipeline"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// addPlanFlag registers -plan
+func addPlanFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("plan", false, "Count the objects of the filtered schemas and estimate the extraction time, without extracting")
+}
+
+// runPlan pre-scans the connection (or every multi.profiles entry): it
+// counts the objects an extraction would read and estimates its time, so
+// filters can be adjusted before a long run
+func runPlan(ctx context.Context, conn *connFlags) error {
+	profiles := splitList(conn.profiles)
+	if len(profiles) == 0 && conn.profile == "" {
+		if base, err := config.ReadProfile(conn.configFile, ""); err == nil {
+			profiles = base.Multi.Profiles
+		}
+	}
+
+	if len(profiles) == 0 {
+		cfg, err := loadConnConfig(ctx, conn)
+		if err != nil {
+			return configError(err)
+		}
+		return printPlan(ctx, cfg)
+	}
+
+	var firstErr error
+	for _, name := range profiles {
+		cfg, err := loadConfig(ctx, conn.configFile, name)
+		if err != nil {
+			err = configError(err)
+		} else {
+			conn.applyFilters(cfg)
+			err = printPlan(ctx, cfg)
+		}
+		if err != nil {
+			fmt.Printf("  ❌ %v\n", err)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("profile %s: %w", name, err)
+			}
+		}
+	}
+	return firstErr
+}
+
+// printPlan connects to the database of cfg and prints the object counts
+// and estimated extraction time
+func printPlan(ctx context.Context, cfg *config.Config) error {
+	title := fmt.Sprintf("Plan for %s at %s:%d/%s", cfg.Database.Type, cfg.Database.Host, cfg.Database.Port, cfg.Database.Database)
+	if cfg.Profile != "" {
+		title += fmt.Sprintf(" (profile %s)", cfg.Profile)
+	}
+	fmt.Println(title)
+
+	ext, err := pipeline.NewExtractor(cfg)
+	if err != nil {
+		return configError(err)
+	}
+	defer ext.Close()
+
+	if err := pipeline.Connect(ctx, cfg, ext); err != nil {
+		return connectionError(fmt.Errorf("failed to connect to database: %w", err))
+	}
+
+	plan, ok, err := extractor.PlanExtraction(ctx, ext)
+	if !ok {
+		return configError(fmt.Errorf("%s does not support counting objects", cfg.Database.Type))
+	}
+	if err != nil {
+		return connectionError(err)
+	}
+
+	for _, kind := range extractor.PlanTypes {
+		if n, ok := plan.Counts[kind]; ok {
+			fmt.Printf("  %-10s %8d\n", kind, n)
+		}
+	}
+	fmt.Printf("  ≈ %d catalog queries at %s each: about %s\n", plan.Queries, roundDuration(plan.PerQuery), roundDuration(plan.Estimate))
+	if filter := cfg.Extract.TableFilter; len(filter) > 0 {
+		fmt.Printf("  extract.table_filter (%s) applies after extraction; narrow database.schema_filter to shorten it\n", strings.Join(filter, ", "))
+	}
+	return nil
+}
+
+// roundDuration rounds d to a precision that suits its size
+func roundDuration(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Minute:
+		return d.Round(time.Second)
+	case d >= time.Second:
+		return d.Round(100 * time.Millisecond)
+	default:
+		return d.Round(time.Millisecond)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"log"
+	"pocket-doc/internal/config"
+	"pocket-doc/internal/gitpub"
+	"pocket-doc/internal/notify"
+	"slices"
+	"strings"
+)
+
+// defaultCommitMessage is the commit message without output.git.message
+const defaultCommitMessage = "Update database documentation: {db} ({date})"
+
+// publishGit commits the documents the run wrote to output.git.repo and
+// pushes them when output.git.push is set
+func publishGit(ctx context.Context, cfg *config.Config) error {
+	g := cfg.Output.Git
+	if g.Repo == "" {
+		return nil
+	}
+
+	run := stats.summary("export", nil, exitOK)
+	var files []string
+	for _, o := range run.Outputs {
+		wanted := func(f string) bool { return strings.EqualFold(f, o.Format) }
+		if o.File != stdoutOutput && (len(g.Formats) == 0 || slices.ContainsFunc(g.Formats, wanted)) {
+			files = append(files, o.File)
+		}
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	message := g.Message
+	if message == "" {
+		message = defaultCommitMessage
+	}
+	res, err := gitpub.Publish(ctx, gitpub.Config{
+		Repo:   g.Repo,
+		Path:   g.Path,
+		Push:   g.Push,
+		Remote: g.Remote,
+		Branch: g.Branch,
+		Author: g.Author,
+	}, files, notify.Expand(message, run))
+	if err != nil {
+		return err
+	}
+	switch {
+	case res.Commit == "":
+		log.Printf("Documentation in %s is up to date; nothing to commit", g.Repo)
+	case res.Pushed:
+		log.Printf("✅ Committed and pushed %d documents to %s (%s)", len(res.Files), g.Repo, res.Commit[:min(len(res.Commit), 12)])
+	default:
+		log.Printf("✅ Committed %d documents to %s (%s)", len(res.Files), g.Repo, res.Commit[:min(len(res.Commit), 12)])
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"pocket-doc/internal/config"
+	"pocket-doc/internal/extractor"
+	"pocket-doc/internal/model"
+	"pocket-doc/internal/pipeline"
+	"fmt"
+	"log"
+	"time"
+)
+
+// sampleValues fills example values when extract.samples.enabled is set
+// (see pipeline.Sample)
+func sampleValues(ctx context.Context, cfg *config.Config, ext extractor.DBExtractor, schema *model.Schema) error {
+	samples := cfg.Extract.Samples
+	if !samples.Enabled {
+		return nil
+	}
+
+	log.Printf("Sampling example values (up to %d per column)...", samples.Values())
+	start := time.Now()
+	n, ok, err := pipeline.Sample(ctx, cfg, ext, schema)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		stats.warn(fmt.Sprintf("⚠️  %s does not support example values", cfg.Database.Type))
+		return nil
+	}
+	profileLogger(cfg).Debug("sampled", "columns", n, "duration", time.Since(start))
+	log.Printf("Example values: %d columns", n)
+	return nil
+}
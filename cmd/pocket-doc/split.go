@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"pocket-doc/internal/combine"
+	"pocket-doc/internal/config"
+	"pocket-doc/internal/i18n"
+	"pocket-doc/internal/model"
+	"pocket-doc/internal/pipeline"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// partSet describes documents written per part and their index page
+type partSet struct {
+	parts      []combine.Part
+	title      string                   // index page title
+	heading    string                   // index section heading
+	label      func(name string) string // display name of a part (nil = name)
+	perProfile bool                     // parts are connection profiles
+}
+
+// splittable reports whether format is a document format; the coverage,
+// lint, PII and relationships reports and the diagrams always cover the
+// whole schema
+func splittable(format string) bool {
+	switch strings.ToLower(format) {
+	case "coverage", "lint", "pii", "relationships", "erd", "svg", "erd-png", "png":
+		return false
+	}
+	return true
+}
+
+// exportSplit writes schema as one document per object type, schema or
+// business domain (output.split_by) plus an index page
+func exportSplit(ctx context.Context, cfg *config.Config, schema *model.Schema, split, format, output string) error {
+	msg := i18n.New(cfg.Output.Language)
+	set := partSet{title: schema.DatabaseName, heading: msg.T("section.documents")}
+
+	switch split {
+	case config.SplitType:
+		set.parts = combine.SplitByType(schema)
+		set.label = func(name string) string { return msg.T("section." + name) }
+	case config.SplitSchema:
+		set.parts = combine.SplitBySchema(schema)
+	case config.SplitDomain:
+		set.parts = combine.SplitByDomain(schema)
+	default:
+		return fmt.Errorf("unknown split mode: %s", split)
+	}
+	if len(set.parts) == 0 {
+		_, err := writeExport(ctx, cfg, schema, format, output, "")
+		return err
+	}
+
+	log.Printf("Splitting by %s into %d documents...", split, len(set.parts))
+	return writeParts(ctx, cfg, schema, set, format, output)
+}
+
+// writeParts writes one document per part and an HTML index page linking
+// them. With -output the files are "<output>-<part>" and
+// "<output>-index.html"; otherwise file_name is used with -{part} appended
+// when it does not already tell the parts apart, and the index is the
+// "index" part. whole supplies the placeholders of the index file name.
+func writeParts(ctx context.Context, cfg *config.Config, whole *model.Schema, set partSet, format, output string) error {
+	partCfg := *cfg
+	partCfg.Output.FileName = partFileName(cfg.Output.FileName, set.perProfile)
+
+	index := output + "-index.html"
+	if output == "" {
+		at := whole.ExtractedAt
+		if at.IsZero() {
+			at = time.Now()
+		}
+		profile := cfg.Profile
+		if set.perProfile {
+			profile = "index"
+		}
+		vars := config.FileNameVars(whole.DatabaseName, whole.DatabaseType, profile, "index", format, cfg.Output.Language, pipeline.Dates(cfg).In(at))
+		indexOutput := partCfg.Output
+		indexOutput.FileName = strings.TrimSuffix(indexOutput.FileName, filepath.Ext(indexOutput.FileName))
+		index = indexOutput.OutputPath(vars, ".html")
+	}
+
+	entries := make([]combine.IndexEntry, 0, len(set.parts))
+	for _, p := range set.parts {
+		if set.perProfile {
+			partCfg.Profile = p.Name
+		}
+		partOutput := ""
+		if output != "" {
+			partOutput = output + "-" + p.Name
+		}
+
+		filename, err := writeExport(ctx, &partCfg, p.Schema, format, partOutput, p.Name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.Name, err)
+		}
+
+		link, err := filepath.Rel(filepath.Dir(index), filename)
+		if err != nil {
+			link = filename
+		}
+		source := combine.SourceOf(p)
+		if set.label != nil {
+			source.Name = set.label(p.Name)
+		}
+		entries = append(entries, combine.IndexEntry{Source: source, File: filepath.ToSlash(link)})
+	}
+
+	if err := os.MkdirAll(filepath.Dir(index), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	f, err := os.Create(index)
+	if err != nil {
+		return fmt.Errorf("failed to create index file: %w", err)
+	}
+	defer f.Close()
+
+	if err := combine.WriteIndex(f, set.title, set.heading, entries, i18n.New(cfg.Output.Language)); err != nil {
+		return err
+	}
+	log.Printf("✅ Index written: %s", index)
+	return nil
+}
+
+// partFileName appends -{part} to a file_name that would give every part
+// the same file; for profiles {profile} tells them apart as well
+func partFileName(name string, perProfile bool) string {
+	lower := strings.ToLower(name)
+	if strings.Contains(lower, "{part}") || (perProfile && strings.Contains(lower, "{profile}")) {
+		return name
+	}
+	if name == "" {
+		name = "schema"
+	}
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "-{part}" + ext
+}
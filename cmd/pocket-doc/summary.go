@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"pocket-doc/internal/config"
+	"pocket-doc/internal/model"
+	"pocket-doc/internal/notify"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Exit codes for CI pipelines
+const (
+	exitOK         = 0
+	exitConfig     = 1   // invalid configuration or flags, or any other failure
+	exitConnection = 2   // database connection or extraction failed
+	exitPartial    = 3   // some databases failed; the output covers the others
+	exitCanceled   = 130 // interrupted by SIGINT/SIGTERM before completing
+)
+
+// exitError attaches an exit code to an error. quiet errors have already
+// been reported (flag parse errors).
+type exitError struct {
+	code  int
+	err   error
+	quiet bool
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode classifies err unless it already carries an exit code
+func withExitCode(code int, err error) error {
+	var e *exitError
+	if err == nil || errors.As(err, &e) {
+		return err
+	}
+	return &exitError{code: code, err: err}
+}
+
+// configError marks err as a configuration error (exit code 1)
+func configError(err error) error {
+	return withExitCode(exitConfig, err)
+}
+
+// connectionError marks err as a connection/extraction error (exit code 2)
+func connectionError(err error) error {
+	return withExitCode(exitConnection, err)
+}
+
+// parseFlags parses args with fs (which reports errors and -h itself)
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return &exitError{code: exitOK, err: err, quiet: true}
+		}
+		return &exitError{code: exitConfig, err: err, quiet: true}
+	}
+	return nil
+}
+
+// exitCode returns the process exit code for the result of a command
+func exitCode(err error) int {
+	if err == nil {
+		if stats.partial() {
+			return exitPartial
+		}
+		return exitOK
+	}
+	if errors.Is(err, context.Canceled) {
+		return exitCanceled
+	}
+	var e *exitError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	return exitConfig
+}
+
+// runStats collects what a run extracted and wrote for -summary-json and
+// the notifications of output.notify
+type runStats struct {
+	mu        sync.Mutex
+	enabled   bool
+	start     time.Time
+	databases []notify.Database
+	outputs   []notify.Output
+	warnings  []string
+	notify    *config.NotifyConfig // of the first configuration that wrote a document
+}
+
+// stats is the statistics of the current run
+var stats = &runStats{start: time.Now()}
+
+// addSummaryFlag registers -summary-json
+func addSummaryFlag(fs *flag.FlagSet) {
+	fs.BoolVar(&stats.enabled, "summary-json", false, "Print a JSON run summary (object counts, durations, warnings) to stdout")
+}
+
+// addCIFlag registers -ci
+func addCIFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("ci", false, "GitHub Actions: annotate findings, append to the job summary and set step outputs")
+}
+
+// extracted records the extraction of one database; schema is nil on
+// failure, when the configured database name and type are recorded instead
+func (s *runStats) extracted(profile string, conn config.DatabaseConfig, schema *model.Schema, d time.Duration, err error) {
+	db := notify.Database{Profile: profile, DatabaseName: conn.Database, DatabaseType: conn.Type, DurationMs: d.Milliseconds()}
+	if schema != nil {
+		db.DatabaseName, db.DatabaseType = schema.DatabaseName, schema.DatabaseType
+		db.Tables, db.Views, db.Routines = len(schema.Tables), len(schema.Views), len(schema.Routines)
+		db.Sequences, db.Triggers, db.Synonyms = len(schema.Sequences), len(schema.Triggers), len(schema.Synonyms)
+		db.Indexes = len(schema.Indexes)
+		for _, t := range schema.Tables {
+			db.Columns += len(t.Columns)
+		}
+	}
+	if err != nil {
+		db.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.databases = append(s.databases, db)
+}
+
+// wrote records an output file
+func (s *runStats) wrote(file, format string, d time.Duration) {
+	out := notify.Output{File: file, Format: format, DurationMs: d.Milliseconds()}
+	if info, err := os.Stat(file); err == nil {
+		out.Bytes = info.Size()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outputs = append(s.outputs, out)
+}
+
+// uploaded records the URL an output file was uploaded to
+func (s *runStats) uploaded(file, url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.outputs {
+		if s.outputs[i].File == file {
+			s.outputs[i].URL = url
+		}
+	}
+}
+
+// warn logs a warning and records it for the summary
+func (s *runStats) warn(msg string) {
+	slog.Warn(msg)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.warnings = append(s.warnings, msg)
+}
+
+// partial reports whether some, but not all, databases failed
+func (s *runStats) partial() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	failed := 0
+	for _, db := range s.databases {
+		if db.Error != "" {
+			failed++
+		}
+	}
+	return failed > 0 && failed < len(s.databases)
+}
+
+// summary returns the summary of a finished command
+func (s *runStats) summary(command string, err error, code int) *notify.Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := "ok"
+	switch code {
+	case exitOK:
+	case exitPartial:
+		status = "partial"
+	default:
+		status = "error"
+	}
+
+	run := &notify.Run{
+		Command:    command,
+		Version:    Version,
+		Status:     status,
+		ExitCode:   code,
+		StartedAt:  s.start,
+		DurationMs: time.Since(s.start).Milliseconds(),
+		Databases:  append([]notify.Database{}, s.databases...),
+		Outputs:    append([]notify.Output{}, s.outputs...),
+		Warnings:   append([]string{}, s.warnings...),
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+	return run
+}
+
+// writeSummary prints the summary of a finished command as JSON
+func writeSummary(w io.Writer, run *notify.Run) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(run)
+}
@@ -0,0 +1,212 @@
+package annotation
+
+import (
+	"bytes"
+	"pocket-doc/internal/model"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Annotations are notes kept next to the database rather than in it, for
+// teams that cannot write comments to the catalog.
+//
+// File format (YAML):
+//
+//	tables:
+//	  HR.EMPLOYEES:                # [owner.]table (views too)
+//	    note: "Loaded nightly from the HR system"
+//	    team: "People Platform"
+//	    classification: INTERNAL
+//	    domain: hr                 # business domain (see output.domains)
+//	columns:
+//	  HR.EMPLOYEES.SSN:            # [owner.]table.column
+//	    note: "Masked in non-production copies"
+//	    classification: RESTRICTED
+//	arguments:
+//	  HR.RAISE_SALARY.P_PERCENT:   # [owner.]routine.argument
+//	    note: "Percentage, 0-20"
+//	sequences:                     # triggers and synonyms alike
+//	  HR.EMPLOYEES_SEQ:            # [owner.]name
+//	    note: "Employee IDs"
+//
+// Object names are matched case-insensitively; the owner may be omitted to
+// match the table in any schema. Argument, sequence, trigger and synonym
+// notes become the comment of objects the catalog has no comment for
+// (arguments on every overload of the routine).
+type Annotations struct {
+	Tables    map[string]Note `yaml:"tables"`
+	Columns   map[string]Note `yaml:"columns"`
+	Arguments map[string]Note `yaml:"arguments"`
+	Sequences map[string]Note `yaml:"sequences"`
+	Triggers  map[string]Note `yaml:"triggers"`
+	Synonyms  map[string]Note `yaml:"synonyms"`
+}
+
+// Note is the annotation of one object. Team and domain apply to tables
+// and views only, classification to tables, views and columns.
+type Note struct {
+	Note           string `yaml:"note"`
+	Team           string `yaml:"team"`
+	Classification string `yaml:"classification"`
+	Domain         string `yaml:"domain"`
+}
+
+// Load reads and validates an annotations file
+func Load(path string) (*Annotations, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read annotations file: %w", err)
+	}
+
+	var a Annotations
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&a); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse annotations file: %w", err)
+	}
+
+	if err := a.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid annotations file %s: %w", path, err)
+	}
+	return &a, nil
+}
+
+// Validate checks that column and argument keys name their table or
+// routine and that notes only set the fields that apply
+func (a *Annotations) Validate() error {
+	for key, n := range a.Columns {
+		if !strings.Contains(key, ".") {
+			return fmt.Errorf("columns: %q must be [owner.]table.column", key)
+		}
+		if n.Team != "" || n.Domain != "" {
+			return fmt.Errorf("columns: %s: team and domain apply to tables only", key)
+		}
+	}
+	for key := range a.Tables {
+		if key == "" {
+			return fmt.Errorf("tables: empty table name")
+		}
+	}
+	for key, n := range a.Arguments {
+		if !strings.Contains(key, ".") {
+			return fmt.Errorf("arguments: %q must be [owner.]routine.argument", key)
+		}
+		if n.Team != "" || n.Classification != "" || n.Domain != "" {
+			return fmt.Errorf("arguments: %s: only note applies to arguments", key)
+		}
+	}
+	for section, notes := range map[string]map[string]Note{"sequences": a.Sequences, "triggers": a.Triggers, "synonyms": a.Synonyms} {
+		for key, n := range notes {
+			if key == "" {
+				return fmt.Errorf("%s: empty name", section)
+			}
+			if n.Team != "" || n.Classification != "" || n.Domain != "" {
+				return fmt.Errorf("%s: %s: only note applies to %s", section, key, section)
+			}
+		}
+	}
+	return nil
+}
+
+// Len returns the number of annotated objects
+func (a *Annotations) Len() int {
+	return len(a.Tables) + len(a.Columns) + len(a.Arguments) + len(a.Sequences) + len(a.Triggers) + len(a.Synonyms)
+}
+
+// Apply sets the notes, teams, classifications and domains of the tables,
+// views and columns of schema and the missing comments of routine arguments,
+// sequences, triggers and synonyms, and returns the keys that matched no
+// object, sorted
+func (a *Annotations) Apply(schema *model.Schema) []string {
+	used := make(map[string]bool)
+
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		if n, ok := a.lookup(a.Tables, used, t.Owner, t.Name); ok {
+			t.Note, t.Team, t.Classification, t.Domain = n.Note, n.Team, n.Classification, n.Domain
+		}
+		a.applyColumns(t.Columns, used, t.Owner, t.Name)
+	}
+	for i := range schema.Views {
+		v := &schema.Views[i]
+		if n, ok := a.lookup(a.Tables, used, v.Owner, v.Name); ok {
+			v.Note, v.Team, v.Classification, v.Domain = n.Note, n.Team, n.Classification, n.Domain
+		}
+		a.applyColumns(v.Columns, used, v.Owner, v.Name)
+	}
+	for i := range schema.Routines {
+		r := &schema.Routines[i]
+		for j := range r.Arguments {
+			arg := &r.Arguments[j]
+			a.applyComment(a.Arguments, used, r.Owner, r.Name+"."+arg.Name, &arg.Comment)
+		}
+	}
+	for i := range schema.Sequences {
+		q := &schema.Sequences[i]
+		a.applyComment(a.Sequences, used, q.Owner, q.Name, &q.Comment)
+	}
+	for i := range schema.Triggers {
+		t := &schema.Triggers[i]
+		a.applyComment(a.Triggers, used, t.Owner, t.Name, &t.Comment)
+	}
+	for i := range schema.Synonyms {
+		y := &schema.Synonyms[i]
+		a.applyComment(a.Synonyms, used, y.Owner, y.Name, &y.Comment)
+	}
+
+	var unmatched []string
+	for _, keys := range []map[string]Note{a.Tables, a.Columns, a.Arguments, a.Sequences, a.Triggers, a.Synonyms} {
+		for key := range keys {
+			if !used[key] {
+				unmatched = append(unmatched, key)
+			}
+		}
+	}
+	sort.Strings(unmatched)
+	return unmatched
+}
+
+func (a *Annotations) applyColumns(columns []model.Column, used map[string]bool, owner, table string) {
+	for j := range columns {
+		c := &columns[j]
+		if n, ok := a.lookup(a.Columns, used, owner, table+"."+c.Name); ok {
+			c.Note, c.Classification = n.Note, n.Classification
+		}
+	}
+}
+
+// applyComment sets an empty comment to the note of owner.name
+func (a *Annotations) applyComment(notes map[string]Note, used map[string]bool, owner, name string, comment *string) {
+	if n, ok := a.lookup(notes, used, owner, name); ok && *comment == "" {
+		*comment = n.Note
+	}
+}
+
+// lookup returns the note of "owner.name" or, failing that, "name", and
+// marks the key as used
+func (a *Annotations) lookup(notes map[string]Note, used map[string]bool, owner, name string) (Note, bool) {
+	var qualified, bare string
+	for key := range notes {
+		switch {
+		case owner != "" && strings.EqualFold(key, owner+"."+name):
+			qualified = key
+		case strings.EqualFold(key, name):
+			bare = key
+		}
+	}
+	key := qualified
+	if key == "" {
+		key = bare
+	}
+	if key == "" {
+		return Note{}, false
+	}
+	used[key] = true
+	return notes[key], true
+}
@@ -0,0 +1,143 @@
+// Package cache stores per-object extraction results on disk, keyed by the
+// object and its state (its last DDL time and whatever else the database
+// changes without touching it), so repeated runs against a slowly changing
+// database only query the objects changed since the previous run
+// (extract.cache). Entries older than the maximum age, or written by
+// extractors that stored other data, are read again.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"pocket-doc/internal/model"
+	"sync/atomic"
+	"time"
+)
+
+// Cache is the cache directory of one database, safe for concurrent use.
+// A nil Cache caches nothing, so extractors can use it unconditionally.
+type Cache struct {
+	dir    string
+	maxAge time.Duration
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// version is the format of the cached values. Raise it whenever the
+// extractors store other data per object, e.g. a column attribute read by
+// a new query, so that entries lacking it are read again.
+const version = 2
+
+// entry is the file of one cached object
+type entry struct {
+	Version  int             `json:"version"`
+	Key      string          `json:"key"`
+	Modified string          `json:"modified"`
+	Stored   time.Time       `json:"stored"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// table is the cached part of a table: what extractors read with queries
+// of their own
+type table struct {
+	Columns []model.Column `json:"columns"`
+	Indexes []model.Index  `json:"indexes,omitempty"`
+}
+
+// Open returns the cache of the database named by namespace (e.g.
+// "oracle://db:1521/ORCL") under dir, creating the directory if needed
+func Open(dir, namespace string, maxAge time.Duration) (*Cache, error) {
+	dir = filepath.Join(dir, hash(namespace)[:16])
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &Cache{dir: dir, maxAge: maxAge}, nil
+}
+
+// Table fills the columns and indexes of t from the cache and reports
+// whether they were stored for the same state, which changes whenever they
+// may have (e.g. the last DDL time); an empty state is never cached
+func (c *Cache) Table(t *model.Table, state string) bool {
+	var v table
+	if !c.get("TABLE "+model.QualifiedName(t.Owner, t.Name), state, &v) {
+		return false
+	}
+	t.Columns, t.Indexes = v.Columns, v.Indexes
+	return true
+}
+
+// StoreTable caches the columns and indexes of t for state
+func (c *Cache) StoreTable(t *model.Table, state string) error {
+	return c.put("TABLE "+model.QualifiedName(t.Owner, t.Name), state, table{Columns: t.Columns, Indexes: t.Indexes})
+}
+
+// Stats returns the number of lookups found in the cache and of those
+// read from the database
+func (c *Cache) Stats() (hits, misses int) {
+	if c == nil {
+		return 0, 0
+	}
+	return int(c.hits.Load()), int(c.misses.Load())
+}
+
+// get decodes the value of key into v when it was stored for modified
+// within the maximum age
+func (c *Cache) get(key, modified string, v any) bool {
+	if c == nil || modified == "" {
+		return false
+	}
+	data, err := os.ReadFile(c.path(key))
+	var e entry
+	if err != nil || json.Unmarshal(data, &e) != nil ||
+		e.Version != version || e.Key != key || e.Modified != modified || time.Since(e.Stored) > c.maxAge ||
+		json.Unmarshal(e.Value, v) != nil {
+		c.misses.Add(1)
+		return false
+	}
+	c.hits.Add(1)
+	return true
+}
+
+// put stores v for key and modified. The file is written under a
+// temporary name and renamed, so concurrent runs never read half an entry.
+func (c *Cache) put(key, modified string, v any) error {
+	if c == nil || modified == "" {
+		return nil
+	}
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry{Version: version, Key: key, Modified: modified, Stored: time.Now(), Value: value})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// path returns the file of key
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, hash(key)[:32]+".json")
+}
+
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"pocket-doc/internal/model"
+	"testing"
+	"time"
+)
+
+// TestExtractionCache validates tables are reused only for the same
+// database, state and cache version
+func TestExtractionCache(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(dir, "oracle://app@db:1521/ORCL", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := model.Table{Owner: "HR", Name: "EMP", ModifiedAt: "2026-01-01 10:00:00",
+		Columns: []model.Column{{Name: "EMP_ID", DataType: "NUMBER", IsAutoIncrement: true}}, Indexes: []model.Index{{Name: "EMP_PK"}}}
+	if err := c.StoreTable(&stored, stored.ModifiedAt); err != nil {
+		t.Fatal(err)
+	}
+
+	same := model.Table{Owner: "HR", Name: "EMP"}
+	if !c.Table(&same, stored.ModifiedAt) || len(same.Columns) != 1 || !same.Columns[0].IsAutoIncrement || len(same.Indexes) != 1 {
+		t.Errorf("unchanged table = %+v", same)
+	}
+	altered := model.Table{Owner: "HR", Name: "EMP"}
+	if c.Table(&altered, "2026-02-01 08:00:00") || altered.Columns != nil {
+		t.Errorf("altered table taken from the cache: %+v", altered)
+	}
+	// No DDL time (PostgreSQL, MySQL): never cached
+	if c.Table(&model.Table{Owner: "HR", Name: "EMP"}, "") {
+		t.Error("table without state taken from the cache")
+	}
+	if hits, misses := c.Stats(); hits != 1 || misses != 1 {
+		t.Errorf("Stats() = %d, %d", hits, misses)
+	}
+
+	// SQL Server keys tables by modify_date and a checksum of the column
+	// descriptions, which change without touching modify_date
+	if err := c.StoreTable(&stored, "2026-01-01 10:00:00 comments 17"); err != nil {
+		t.Fatal(err)
+	}
+	if c.Table(&model.Table{Owner: "HR", Name: "EMP"}, "2026-01-01 10:00:00 comments 42") {
+		t.Error("table with edited descriptions taken from the cache")
+	}
+
+	other, err := Open(dir, "oracle://app@db:1521/TEST", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Table(&model.Table{Owner: "HR", Name: "EMP"}, stored.ModifiedAt) {
+		t.Error("table of another database taken from the cache")
+	}
+
+	// Entries of an older format lack data extracted since
+	if err := c.StoreTable(&stored, stored.ModifiedAt); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var e map[string]any
+		if err := json.Unmarshal(data, &e); err != nil {
+			t.Fatal(err)
+		}
+		delete(e, "version")
+		data, _ = json.Marshal(e)
+		if err := os.WriteFile(file, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(files) == 0 || c.Table(&model.Table{Owner: "HR", Name: "EMP"}, stored.ModifiedAt) {
+		t.Errorf("entry without version taken from the cache (%d files)", len(files))
+	}
+
+	var none *Cache
+	if none.Table(&same, stored.ModifiedAt) || none.StoreTable(&same, stored.ModifiedAt) != nil {
+		t.Error("nil cache caches")
+	}
+}
@@ -0,0 +1,178 @@
+// Package catalog pushes the documented schema to enterprise data catalogs
+// through their REST APIs: OpenMetadata (databases, schemas, tables) and
+// DataHub (datasets). Tables and views are sent with their columns, types,
+// primary keys and descriptions, so the catalog shows the comments
+// maintained in the database. Lineage is not pushed.
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"pocket-doc/internal/model"
+	"strings"
+	"time"
+)
+
+// Catalog types
+const (
+	TypeOpenMetadata = "openmetadata"
+	TypeDataHub      = "datahub"
+)
+
+// Types lists the supported catalogs
+var Types = []string{TypeOpenMetadata, TypeDataHub}
+
+// DefaultTimeout bounds each API request when no timeout is configured
+const DefaultTimeout = 30 * time.Second
+
+// Config selects the catalog and where the schema goes in it
+type Config struct {
+	Type     string        // openmetadata, datahub
+	URL      string        // API server, e.g. http://openmetadata:8585 or http://datahub-gms:8080
+	Token    string        // bearer token (JWT of a bot or personal access token)
+	Service  string        // OpenMetadata database service, which must exist
+	Platform string        // DataHub platform (default from the database type)
+	Env      string        // DataHub environment (default PROD)
+	Database string        // database name in the catalog (default the extracted one)
+	Timeout  time.Duration // per request (0 = DefaultTimeout)
+}
+
+// Result counts what was pushed
+type Result struct {
+	Schemas int
+	Tables  int
+	Views   int
+}
+
+// Pusher sends a schema to a catalog
+type Pusher interface {
+	Push(ctx context.Context, schema *model.Schema) (*Result, error)
+}
+
+// New returns the pusher of cfg.Type
+func New(cfg Config) (Pusher, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("catalog: url is required")
+	}
+	c := &client{base: strings.TrimRight(cfg.URL, "/"), token: cfg.Token, timeout: cfg.Timeout}
+	if c.timeout <= 0 {
+		c.timeout = DefaultTimeout
+	}
+	switch strings.ToLower(cfg.Type) {
+	case TypeOpenMetadata:
+		if cfg.Service == "" {
+			return nil, errors.New("catalog: service is required for openmetadata")
+		}
+		return &openMetadata{cfg: cfg, client: c}, nil
+	case TypeDataHub:
+		return &dataHub{cfg: cfg, client: c}, nil
+	default:
+		return nil, fmt.Errorf("catalog: unknown type %s (use %s)", cfg.Type, strings.Join(Types, ", "))
+	}
+}
+
+// object is a table or view with what the catalogs need of it
+type object struct {
+	owner   string
+	name    string
+	kind    string // Table, View, MaterializedView, Partitioned
+	comment string
+	columns []model.Column
+}
+
+// objects returns the tables and views of schema in schema order
+func objects(schema *model.Schema) []object {
+	var out []object
+	for _, t := range schema.Tables {
+		kind := "Table"
+		if strings.Contains(strings.ToUpper(t.Type), "PARTITION") {
+			kind = "Partitioned"
+		}
+		out = append(out, object{t.Owner, t.Name, kind, description(t.Comment, t.Note), t.Columns})
+	}
+	for _, v := range schema.Views {
+		kind := "View"
+		if strings.Contains(strings.ToUpper(v.Type), "MATERIALIZED") {
+			kind = "MaterializedView"
+		}
+		out = append(out, object{v.Owner, v.Name, kind, description(v.Comment, v.Note), v.Columns})
+	}
+	return out
+}
+
+// description joins a database comment and an annotation note
+func description(comment, note string) string {
+	switch {
+	case note == "":
+		return comment
+	case comment == "":
+		return note
+	}
+	return comment + "\n\n" + note
+}
+
+// schemaName returns the schema of an object; objects without an owner
+// (SQLite, MySQL databases documented alone) go to "default"
+func schemaName(owner string) string {
+	if owner == "" {
+		return "default"
+	}
+	return owner
+}
+
+// client calls a catalog API with a bearer token
+type client struct {
+	base    string
+	token   string
+	timeout time.Duration
+	headers map[string]string
+}
+
+// do sends body as JSON and decodes the response into out (when not nil).
+// Errors name the host only; the token is never part of them.
+func (c *client) do(ctx context.Context, method, path string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("catalog: invalid url: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("catalog %s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("catalog %s: %s %s: %s: %s", req.URL.Host, method, req.URL.Path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("catalog %s: failed to parse response: %w", req.URL.Host, err)
+		}
+	}
+	return nil
+}
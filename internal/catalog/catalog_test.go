@@ -0,0 +1,130 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pocket-doc/internal/model"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testSchema returns an Oracle schema of two tables and a view
+func testSchema() *model.Schema {
+	return &model.Schema{
+		DatabaseName: "인사관리DB",
+		DatabaseType: "Oracle",
+		Tables: []model.Table{
+			{
+				Name:    "사원",
+				Owner:   "HR",
+				Type:    "TABLE",
+				Comment: "사원 기본 정보 테이블",
+				Columns: []model.Column{
+					{Name: "사원번호", Position: 1, DataType: "NUMBER(6)", IsPrimaryKey: true, Precision: 6},
+					{Name: "이름", Position: 2, DataType: "VARCHAR2(50)", Comment: "사원 성명", Length: 50},
+					{Name: "부서코드", Position: 3, DataType: "NUMBER(4)", Nullable: true, IsForeignKey: true,
+						FKTargetTable: "부서", FKTargetColumn: "부서코드", Precision: 4},
+				},
+			},
+			{
+				Name:  "부서",
+				Owner: "HR",
+				Type:  "TABLE",
+				Columns: []model.Column{
+					{Name: "부서코드", Position: 1, DataType: "NUMBER(4)", IsPrimaryKey: true, Precision: 4},
+				},
+			},
+		},
+		Views: []model.View{
+			{Name: "사원_V", Owner: "HR", Columns: []model.Column{{Name: "이름", DataType: "VARCHAR2(50)", Nullable: true}}},
+		},
+	}
+}
+
+// TestCatalogPush tests the OpenMetadata and DataHub requests of the
+// catalog command
+func TestCatalogPush(t *testing.T) {
+	type request struct {
+		method, path, auth, restli string
+		body                       map[string]any
+	}
+	var requests []request
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, request{r.Method, r.URL.RequestURI(), r.Header.Get("Authorization"), r.Header.Get("X-RestLi-Protocol-Version"), body})
+		w.WriteHeader(status)
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+	schema := testSchema()
+
+	om, err := New(Config{Type: "openmetadata", URL: srv.URL + "/", Token: "om-secret", Service: "oracle_svc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := om.Push(context.Background(), schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Tables != len(schema.Tables) || res.Views != len(schema.Views) {
+		t.Errorf("Result = %+v", res)
+	}
+	if r := requests[0]; r.method != http.MethodPut || r.path != "/api/v1/databases" || r.auth != "Bearer om-secret" || r.body["service"] != "oracle_svc" {
+		t.Errorf("Database request = %+v", r)
+	}
+	var table map[string]any
+	for _, r := range requests {
+		if r.path == "/api/v1/tables" && r.body["name"] == "사원" {
+			table = r.body
+		}
+	}
+	if table == nil || table["databaseSchema"] != "oracle_svc.인사관리DB.HR" || table["tableType"] != "Regular" || table["description"] != "사원 기본 정보 테이블" {
+		t.Fatalf("Table request = %v", table)
+	}
+	columns := table["columns"].([]any)
+	if c := columns[0].(map[string]any); c["constraint"] != "PRIMARY_KEY" {
+		t.Errorf("Key column = %v", c)
+	}
+	if c := columns[1].(map[string]any); c["dataType"] != "VARCHAR" || c["dataLength"] != float64(50) || c["constraint"] != "NOT_NULL" || c["description"] != "사원 성명" {
+		t.Errorf("Name column = %v", c)
+	}
+
+	requests = nil
+	dh, _ := New(Config{Type: "datahub", URL: srv.URL, Token: "dh-secret"})
+	if _, err := dh.Push(context.Background(), schema); err != nil {
+		t.Fatal(err)
+	}
+	if want := 3 * (len(schema.Tables) + len(schema.Views)); len(requests) != want {
+		t.Fatalf("DataHub requests = %d, want %d", len(requests), want)
+	}
+	r := requests[1]
+	proposal := r.body["proposal"].(map[string]any)
+	if r.path != "/aspects?action=ingestProposal" || r.restli != "2.0.0" || r.auth != "Bearer dh-secret" ||
+		proposal["entityUrn"] != "urn:li:dataset:(urn:li:dataPlatform:oracle,HR.사원,PROD)" || proposal["aspectName"] != "schemaMetadata" {
+		t.Fatalf("DataHub request = %+v", r)
+	}
+	var metadata struct {
+		PrimaryKeys []string `json:"primaryKeys"`
+		Fields      []struct {
+			FieldPath string                    `json:"fieldPath"`
+			Type      map[string]map[string]any `json:"type"`
+		} `json:"fields"`
+	}
+	json.Unmarshal([]byte(proposal["aspect"].(map[string]any)["value"].(string)), &metadata)
+	if !slices.Equal(metadata.PrimaryKeys, []string{"사원번호"}) || metadata.Fields[1].FieldPath != "이름" {
+		t.Errorf("Schema metadata = %+v", metadata)
+	}
+	if _, ok := metadata.Fields[1].Type["type"]["com.linkedin.schema.StringType"]; !ok {
+		t.Errorf("Name field type = %v", metadata.Fields[1].Type)
+	}
+
+	status = http.StatusUnauthorized
+	if _, err := dh.Push(context.Background(), schema); err == nil || strings.Contains(err.Error(), "dh-secret") {
+		t.Errorf("Unauthorized push error = %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"pocket-doc/internal/datatype"
+	"pocket-doc/internal/model"
+	"strings"
+)
+
+// dataHub upserts one dataset per table and view through the
+// ingestProposal endpoint of DataHub's metadata service (GMS): its
+// properties (name, description), schema (columns, types, primary key) and
+// sub type (Table or View)
+type dataHub struct {
+	cfg    Config
+	client *client
+}
+
+func (d *dataHub) Push(ctx context.Context, schema *model.Schema) (*Result, error) {
+	d.client.headers = map[string]string{"X-RestLi-Protocol-Version": "2.0.0"}
+	platform := d.cfg.Platform
+	if platform == "" {
+		platform = dataHubPlatform(schema.DatabaseType)
+	}
+	env := d.cfg.Env
+	if env == "" {
+		env = "PROD"
+	}
+	database := d.cfg.Database
+	if database == "" {
+		database = schema.DatabaseName
+	}
+	platformURN := "urn:li:dataPlatform:" + platform
+
+	r := &Result{}
+	schemas := make(map[string]bool)
+	for _, obj := range objects(schema) {
+		owner := schemaName(obj.owner)
+		if !schemas[owner] {
+			schemas[owner] = true
+			r.Schemas++
+		}
+		name := owner + "." + obj.name
+		if platform != "mysql" && platform != "oracle" {
+			name = database + "." + name
+		}
+		urn := fmt.Sprintf("urn:li:dataset:(%s,%s,%s)", platformURN, name, env)
+
+		subType := "Table"
+		if obj.kind == "View" || obj.kind == "MaterializedView" {
+			subType = "View"
+			r.Views++
+		} else {
+			r.Tables++
+		}
+		properties := map[string]any{"name": obj.name, "qualifiedName": name, "customProperties": map[string]string{}}
+		if obj.comment != "" {
+			properties["description"] = obj.comment
+		}
+		aspects := []struct {
+			name  string
+			value any
+		}{
+			{"datasetProperties", properties},
+			{"schemaMetadata", d.schemaMetadata(schema.DatabaseType, name, platformURN, obj.columns)},
+			{"subTypes", map[string]any{"typeNames": []string{subType}}},
+		}
+		for _, a := range aspects {
+			if err := d.ingest(ctx, urn, a.name, a.value); err != nil {
+				return r, err
+			}
+		}
+	}
+	return r, nil
+}
+
+// ingest upserts one aspect of a dataset
+func (d *dataHub) ingest(ctx context.Context, urn, aspect string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return d.client.do(ctx, http.MethodPost, "/aspects?action=ingestProposal", map[string]any{
+		"proposal": map[string]any{
+			"entityType": "dataset",
+			"entityUrn":  urn,
+			"changeType": "UPSERT",
+			"aspectName": aspect,
+			"aspect":     map[string]string{"value": string(data), "contentType": "application/json"},
+		},
+	}, nil)
+}
+
+// schemaMetadata builds the schemaMetadata aspect of a dataset
+func (d *dataHub) schemaMetadata(dbType, name, platformURN string, columns []model.Column) map[string]any {
+	fields := make([]map[string]any, 0, len(columns))
+	for _, c := range columns {
+		f := map[string]any{
+			"fieldPath":      c.Name,
+			"nativeDataType": c.DataType,
+			"type":           map[string]any{"type": map[string]any{"com.linkedin.schema." + dataHubType(datatype.Of(dbType, c)): map[string]any{}}},
+			"nullable":       c.Nullable,
+			"isPartOfKey":    c.IsPrimaryKey,
+		}
+		if desc := description(c.Comment, c.Note); desc != "" {
+			f["description"] = desc
+		}
+		fields = append(fields, f)
+	}
+	m := map[string]any{
+		"schemaName":     name,
+		"platform":       platformURN,
+		"version":        0,
+		"hash":           "",
+		"platformSchema": map[string]any{"com.linkedin.schema.OtherSchema": map[string]any{"rawSchema": ""}},
+		"fields":         fields,
+	}
+	if pk := primaryKey(columns); len(pk) > 0 {
+		m["primaryKeys"] = pk
+	}
+	return m
+}
+
+// dataHubType maps a canonical type to a DataHub schema field type
+func dataHubType(t datatype.Type) string {
+	switch {
+	case t.Array:
+		return "ArrayType"
+	case t.Name == "date":
+		return "DateType"
+	case t.Name == "enum":
+		return "EnumType"
+	case t.Name == "uuid":
+		return "StringType"
+	}
+	switch t.Kind {
+	case datatype.KindString:
+		return "StringType"
+	case datatype.KindInteger, datatype.KindNumber:
+		return "NumberType"
+	case datatype.KindBoolean:
+		return "BooleanType"
+	case datatype.KindTemporal:
+		return "TimeType"
+	case datatype.KindBinary:
+		return "BytesType"
+	}
+	return "NullType"
+}
+
+// dataHubPlatform returns the DataHub platform of a database type
+func dataHubPlatform(dbType string) string {
+	t := strings.ToLower(dbType)
+	switch {
+	case strings.Contains(t, "postgres"):
+		return "postgres"
+	case strings.Contains(t, "mysql"), strings.Contains(t, "maria"):
+		return "mysql"
+	case strings.Contains(t, "oracle"):
+		return "oracle"
+	case strings.Contains(t, "sqlserver"), strings.Contains(t, "mssql"):
+		return "mssql"
+	case strings.Contains(t, "sqlite"):
+		return "sqlite"
+	}
+	return t
+}
@@ -0,0 +1,177 @@
+package catalog
+
+import (
+	"context"
+	"net/http"
+	"pocket-doc/internal/datatype"
+	"pocket-doc/internal/model"
+	"strings"
+)
+
+// openMetadata creates or updates the database, its schemas and their
+// tables under an existing database service with the PUT (create or
+// update) endpoints of the OpenMetadata API
+type openMetadata struct {
+	cfg    Config
+	client *client
+}
+
+// omColumn is a column of the OpenMetadata table API
+type omColumn struct {
+	Name            string `json:"name"`
+	DataType        string `json:"dataType"`
+	DataTypeDisplay string `json:"dataTypeDisplay,omitempty"`
+	DataLength      int    `json:"dataLength,omitempty"`
+	ArrayDataType   string `json:"arrayDataType,omitempty"`
+	Precision       int    `json:"precision,omitempty"`
+	Scale           int    `json:"scale,omitempty"`
+	Description     string `json:"description,omitempty"`
+	Constraint      string `json:"constraint,omitempty"`
+	OrdinalPosition int    `json:"ordinalPosition,omitempty"`
+}
+
+// omConstraint is a table constraint of the OpenMetadata table API
+type omConstraint struct {
+	ConstraintType string   `json:"constraintType"`
+	Columns        []string `json:"columns"`
+}
+
+func (o *openMetadata) Push(ctx context.Context, schema *model.Schema) (*Result, error) {
+	database := o.cfg.Database
+	if database == "" {
+		database = schema.DatabaseName
+	}
+	dbFQN := fqn(o.cfg.Service, database)
+	if err := o.client.do(ctx, http.MethodPut, "/api/v1/databases", map[string]any{
+		"name":    database,
+		"service": o.cfg.Service,
+	}, nil); err != nil {
+		return nil, err
+	}
+
+	r := &Result{}
+	schemas := make(map[string]bool)
+	for _, obj := range objects(schema) {
+		owner := schemaName(obj.owner)
+		if !schemas[owner] {
+			if err := o.client.do(ctx, http.MethodPut, "/api/v1/databaseSchemas", map[string]any{
+				"name":     owner,
+				"database": dbFQN,
+			}, nil); err != nil {
+				return r, err
+			}
+			schemas[owner] = true
+			r.Schemas++
+		}
+
+		table := map[string]any{
+			"name":           obj.name,
+			"databaseSchema": fqn(o.cfg.Service, database, owner),
+			"tableType":      omTableType(obj.kind),
+			"columns":        o.columns(schema.DatabaseType, obj.columns),
+		}
+		if obj.comment != "" {
+			table["description"] = obj.comment
+		}
+		if pk := primaryKey(obj.columns); len(pk) > 1 {
+			table["tableConstraints"] = []omConstraint{{ConstraintType: "PRIMARY_KEY", Columns: pk}}
+		}
+		if err := o.client.do(ctx, http.MethodPut, "/api/v1/tables", table, nil); err != nil {
+			return r, err
+		}
+		if obj.kind == "Table" || obj.kind == "Partitioned" {
+			r.Tables++
+		} else {
+			r.Views++
+		}
+	}
+	return r, nil
+}
+
+// columns converts columns to the OpenMetadata column model
+func (o *openMetadata) columns(dbType string, columns []model.Column) []omColumn {
+	pk := primaryKey(columns)
+	out := make([]omColumn, 0, len(columns))
+	for _, c := range columns {
+		t := datatype.Of(dbType, c)
+		col := omColumn{
+			Name:            c.Name,
+			DataType:        omDataType(t),
+			DataTypeDisplay: c.DataType,
+			Description:     description(c.Comment, c.Note),
+			OrdinalPosition: c.Position,
+		}
+		if t.Array {
+			col.ArrayDataType = col.DataType
+			col.DataType = "ARRAY"
+		}
+		switch col.DataType {
+		case "CHAR", "VARCHAR", "BINARY", "VARBINARY":
+			// Required for these types; 1 stands for an undeclared length
+			col.DataLength = max(t.Length, 1)
+		case "DECIMAL":
+			col.Precision, col.Scale = t.Precision, t.Scale
+		}
+		switch {
+		case c.IsPrimaryKey && len(pk) == 1:
+			col.Constraint = "PRIMARY_KEY"
+		case c.IsUnique:
+			col.Constraint = "UNIQUE"
+		case !c.Nullable:
+			col.Constraint = "NOT_NULL"
+		default:
+			col.Constraint = "NULL"
+		}
+		out = append(out, col)
+	}
+	return out
+}
+
+// omDataType maps a canonical type to an OpenMetadata data type
+func omDataType(t datatype.Type) string {
+	switch t.Name {
+	case "char", "varchar", "text", "smallint", "bigint", "decimal", "double", "boolean",
+		"date", "time", "timestamp", "interval", "binary", "varbinary", "blob", "json", "xml", "uuid",
+		"enum", "set", "geography", "geometry":
+		return strings.ToUpper(t.Name)
+	case "integer":
+		return "INT"
+	case "real":
+		return "FLOAT"
+	case "timestamptz":
+		return "TIMESTAMPZ"
+	}
+	return "UNKNOWN"
+}
+
+// omTableType maps an object kind to an OpenMetadata table type
+func omTableType(kind string) string {
+	if kind == "Table" {
+		return "Regular"
+	}
+	return kind
+}
+
+// primaryKey returns the primary key columns of a table in column order
+func primaryKey(columns []model.Column) []string {
+	var pk []string
+	for _, c := range columns {
+		if c.IsPrimaryKey {
+			pk = append(pk, c.Name)
+		}
+	}
+	return pk
+}
+
+// fqn joins names into an OpenMetadata fully qualified name, quoting the
+// names that contain dots
+func fqn(names ...string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		if strings.Contains(n, ".") {
+			n = `"` + n + `"`
+		}
+		quoted[i] = n
+	}
+	return strings.Join(quoted, ".")
+}
@@ -0,0 +1,63 @@
+package ci
+
+import (
+	"os"
+	"path/filepath"
+	"pocket-doc/internal/diff"
+	"pocket-doc/internal/i18n"
+	"pocket-doc/internal/lint"
+	"pocket-doc/internal/model"
+	"strings"
+	"testing"
+)
+
+// TestGitHubActions tests workflow commands, the job summary and step
+// outputs of -ci
+func TestGitHubActions(t *testing.T) {
+	dir := t.TempDir()
+	summaryFile, outputFile := filepath.Join(dir, "summary.md"), filepath.Join(dir, "output")
+	t.Setenv("GITHUB_STEP_SUMMARY", summaryFile)
+	t.Setenv("GITHUB_OUTPUT", outputFile)
+
+	result := &lint.Result{Findings: []lint.Finding{
+		{Rule: lint.RuleMissingPK, Severity: lint.SeverityError, Object: "HR.EMP", Message: "no primary key"},
+		{Rule: lint.RuleNameCase, Severity: lint.SeverityWarning, Object: "HR.emp|x", Message: "100% lower\ncase"},
+	}}
+	var out strings.Builder
+	if err := Annotate(&out, LintAnnotations(result)); err != nil {
+		t.Fatal(err)
+	}
+	want := "::error title=lint missing-pk::HR.EMP: no primary key\n" +
+		"::warning title=lint name-case::HR.emp|x: 100%25 lower%0Acase\n"
+	if out.String() != want {
+		t.Errorf("Annotations =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	if err := Summary(LintSummary(result)); err != nil {
+		t.Fatal(err)
+	}
+	old := &model.Schema{DatabaseName: "shop", Tables: []model.Table{{Owner: "HR", Name: "EMP"}, {Owner: "HR", Name: "DEPT"}}}
+	changed := &model.Schema{DatabaseName: "shop", Tables: old.Tables[1:]}
+	d := diff.Compare(old, changed)
+	var md strings.Builder
+	diff.WriteMarkdown(&md, d, i18n.New("en"))
+	Summary(md.String())
+	summary, _ := os.ReadFile(summaryFile)
+	for _, part := range []string{"**1 error(s), 1 warning(s)**", "| error | missing-pk | `HR.EMP` |", "`HR.emp\\|x`", "Changelog"} {
+		if !strings.Contains(string(summary), part) {
+			t.Errorf("Summary lacks %q:\n%s", part, summary)
+		}
+	}
+	for _, a := range DiffAnnotations(d, LevelError) {
+		if a.Level != LevelError || !strings.Contains(a.Message, "removed") {
+			t.Errorf("Diff annotation = %+v", a)
+		}
+	}
+
+	SetOutput("snapshot", "out/snapshot.json")
+	SetOutput("files", "a.html\nb.html")
+	outputs, _ := os.ReadFile(outputFile)
+	if want := "snapshot=out/snapshot.json\nfiles<<POCKET_DOC_EOF\na.html\nb.html\nPOCKET_DOC_EOF\n"; string(outputs) != want {
+		t.Errorf("Outputs = %q", outputs)
+	}
+}
@@ -0,0 +1,134 @@
+// Package ci makes pocket-doc a step of GitHub Actions workflows: lint
+// findings and schema changes become workflow commands (annotations on the
+// run and its pull request), reports are appended to the job summary and
+// written files are set as step outputs for later steps such as
+// actions/upload-artifact.
+package ci
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"pocket-doc/internal/diff"
+	"pocket-doc/internal/lint"
+	"strings"
+)
+
+// Annotation levels
+const (
+	LevelNotice  = "notice"
+	LevelWarning = "warning"
+	LevelError   = "error"
+)
+
+// Annotation is a workflow command that GitHub shows on the run
+type Annotation struct {
+	Level   string
+	Title   string
+	Message string
+}
+
+// Annotate writes annotations as workflow commands, e.g.
+// "::error title=missing-pk::HR.EMP has no primary key"
+func Annotate(w io.Writer, annotations []Annotation) error {
+	var b strings.Builder
+	for _, a := range annotations {
+		fmt.Fprintf(&b, "::%s", a.Level)
+		if a.Title != "" {
+			fmt.Fprintf(&b, " title=%s", escapeProperty(a.Title))
+		}
+		fmt.Fprintf(&b, "::%s\n", escapeData(a.Message))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Summary appends markdown to the job summary; outside Actions
+// (GITHUB_STEP_SUMMARY unset) it does nothing
+func Summary(markdown string) error {
+	return appendEnvFile("GITHUB_STEP_SUMMARY", markdown+"\n")
+}
+
+// SetOutput sets a step output that later steps read as
+// steps.<id>.outputs.<name>; outside Actions it does nothing
+func SetOutput(name, value string) error {
+	if !strings.Contains(value, "\n") {
+		return appendEnvFile("GITHUB_OUTPUT", name+"="+value+"\n")
+	}
+	const delimiter = "POCKET_DOC_EOF"
+	return appendEnvFile("GITHUB_OUTPUT", fmt.Sprintf("%s<<%s\n%s\n%s\n", name, delimiter, value, delimiter))
+}
+
+// appendEnvFile appends text to the file named by the environment
+// variable env
+func appendEnvFile(env, text string) error {
+	path := os.Getenv(env)
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("%s: %w", env, err)
+	}
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return fmt.Errorf("%s: %w", env, err)
+	}
+	return f.Close()
+}
+
+// LintAnnotations annotates each lint finding: errors as errors, warnings
+// as warnings
+func LintAnnotations(r *lint.Result) []Annotation {
+	out := make([]Annotation, 0, len(r.Findings))
+	for _, f := range r.Findings {
+		level := LevelWarning
+		if f.Severity == lint.SeverityError {
+			level = LevelError
+		}
+		out = append(out, Annotation{Level: level, Title: "lint " + f.Rule, Message: f.Object + ": " + f.Message})
+	}
+	return out
+}
+
+// LintSummary renders lint findings as a markdown table
+func LintSummary(r *lint.Result) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Schema lint\n\n**%d error(s), %d warning(s)**\n", r.Errors(), r.Warnings())
+	if len(r.Findings) == 0 {
+		return b.String()
+	}
+	b.WriteString("\n| Severity | Rule | Object | Message |\n|---|---|---|---|\n")
+	for _, f := range r.Findings {
+		fmt.Fprintf(&b, "| %s | %s | `%s` | %s |\n", f.Severity, f.Rule, escapeCell(f.Object), escapeCell(f.Message))
+	}
+	return b.String()
+}
+
+// DiffAnnotations annotates each schema change at level
+func DiffAnnotations(r *diff.Result, level string) []Annotation {
+	out := make([]Annotation, 0, len(r.Changes))
+	for _, c := range r.Changes {
+		msg := fmt.Sprintf("%s %s %s", c.Object, c.Path(), c.Type)
+		if c.Type == diff.Changed {
+			msg = fmt.Sprintf("%s %s %s: %q → %q", c.Object, c.Path(), c.Field, c.Old, c.New)
+		}
+		out = append(out, Annotation{Level: level, Title: "schema " + string(c.Type), Message: msg})
+	}
+	return out
+}
+
+// escapeData escapes the message of a workflow command
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// escapeCell keeps text from breaking a markdown table row
+func escapeCell(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
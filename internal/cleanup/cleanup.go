@@ -0,0 +1,91 @@
+// Package cleanup lists the objects a cleanup initiative looks at first:
+// tables without foreign keys in or out, empty tables, disabled triggers
+// and indexes, and objects the database reports as invalid. The findings
+// form the cleanup appendix of the documents (output.include_cleanup).
+package cleanup
+
+import (
+	"pocket-doc/internal/model"
+	"strings"
+)
+
+// Check IDs
+const (
+	CheckUnrelated       = "unrelated"        // table with no foreign key in or out
+	CheckEmpty           = "empty"            // table whose row count is 0
+	CheckDisabledTrigger = "disabled-trigger" // trigger with status DISABLED
+	CheckDisabledIndex   = "disabled-index"   // index disabled or unusable
+	CheckInvalid         = "invalid"          // object that failed to compile (Oracle)
+)
+
+// Checks lists the check IDs in report order
+var Checks = []string{CheckUnrelated, CheckEmpty, CheckDisabledTrigger, CheckDisabledIndex, CheckInvalid}
+
+// Finding is one object to review. Detail holds names only (the table of
+// a trigger or index, the type of an invalid object), so documents can
+// show it in any language.
+type Finding struct {
+	Check  string `json:"check"`
+	Owner  string `json:"owner,omitempty"`
+	Object string `json:"object"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Report holds the findings, ordered by check, then in schema order
+type Report struct {
+	Findings []Finding `json:"findings"`
+}
+
+// ByCheck returns the findings of one check
+func (r *Report) ByCheck(check string) []Finding {
+	var out []Finding
+	for _, f := range r.Findings {
+		if f.Check == check {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Check lists the cleanup candidates of schema. Row counts come from the
+// optimizer statistics, so a table that was never analyzed counts as
+// empty too.
+func Check(schema *model.Schema) *Report {
+	byCheck := make(map[string][]Finding)
+	add := func(check, owner, object, detail string) {
+		byCheck[check] = append(byCheck[check], Finding{Check: check, Owner: owner, Object: object, Detail: detail})
+	}
+
+	for _, t := range model.NewGraph(schema).Orphans() {
+		add(CheckUnrelated, t.Owner, t.Name, "")
+	}
+	indexes := schema.Indexes
+	for _, t := range schema.Tables {
+		if t.RowCount == 0 {
+			add(CheckEmpty, t.Owner, t.Name, "")
+		}
+		// Extractors list indexes on the tables or schema-wide
+		if len(schema.Indexes) == 0 {
+			indexes = append(indexes, t.Indexes...)
+		}
+	}
+	for _, idx := range indexes {
+		if !idx.IsEnabled {
+			add(CheckDisabledIndex, idx.Owner, idx.Name, model.QualifiedName(idx.Owner, idx.TableName))
+		}
+	}
+	for _, trg := range schema.Triggers {
+		if strings.EqualFold(trg.Status, "DISABLED") {
+			add(CheckDisabledTrigger, trg.Owner, trg.Name, model.QualifiedName(trg.Owner, trg.TargetTable))
+		}
+	}
+	for _, obj := range schema.InvalidObjects {
+		add(CheckInvalid, obj.Owner, obj.Name, obj.Type)
+	}
+
+	r := &Report{}
+	for _, check := range Checks {
+		r.Findings = append(r.Findings, byCheck[check]...)
+	}
+	return r
+}
@@ -0,0 +1,215 @@
+// Package combine merges the schemas of several databases into one
+// document, splits a schema into per-type or per-schema parts, and writes
+// the index page for per-part files.
+package combine
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"pocket-doc/internal/i18n"
+	"pocket-doc/internal/model"
+	"strings"
+)
+
+// Part is the extracted schema of one named database (connection profile)
+type Part struct {
+	Name   string
+	Schema *model.Schema
+}
+
+// Merge combines the parts into one schema. Every object's owner is
+// qualified with its part name ("prod.HR"), so objects stay distinct and
+// group by database when sorted by schema; Sources lists the databases.
+func Merge(parts []Part) *model.Schema {
+	merged := &model.Schema{}
+	names := make([]string, 0, len(parts))
+	types := make(map[string]bool)
+	terms := make(map[string]bool)
+
+	for _, p := range parts {
+		s := p.Schema
+		names = append(names, p.Name)
+		types[s.DatabaseType] = true
+		if s.ExtractedAt.After(merged.ExtractedAt) {
+			merged.ExtractedAt = s.ExtractedAt
+		}
+		merged.Sources = append(merged.Sources, SourceOf(p))
+
+		owner := func(o string) string { return Qualify(p.Name, o) }
+		for _, t := range s.Tables {
+			t.Owner = owner(t.Owner)
+			t.Indexes = qualifyIndexes(t.Indexes, owner)
+			t.ReferencedBy = qualifyRefs(t.ReferencedBy, owner)
+			merged.Tables = append(merged.Tables, t)
+		}
+		for _, v := range s.Views {
+			v.Owner = owner(v.Owner)
+			merged.Views = append(merged.Views, v)
+		}
+		for _, r := range s.Routines {
+			r.Owner = owner(r.Owner)
+			r.Touches = qualifyRefs(r.Touches, owner)
+			merged.Routines = append(merged.Routines, r)
+		}
+		for _, q := range s.Sequences {
+			q.Owner = owner(q.Owner)
+			merged.Sequences = append(merged.Sequences, q)
+		}
+		for _, t := range s.Triggers {
+			t.Owner = owner(t.Owner)
+			if t.Function != nil {
+				fn := *t.Function
+				fn.Owner = owner(fn.Owner)
+				t.Function = &fn
+			}
+			merged.Triggers = append(merged.Triggers, t)
+		}
+		for _, y := range s.Synonyms {
+			y.Owner = owner(y.Owner)
+			if y.TargetOwner != "" {
+				y.TargetOwner = owner(y.TargetOwner)
+			}
+			merged.Synonyms = append(merged.Synonyms, y)
+		}
+		merged.Indexes = append(merged.Indexes, qualifyIndexes(s.Indexes, owner)...)
+		for _, m := range s.Migrations {
+			m.Owner = owner(m.Owner)
+			merged.Migrations = append(merged.Migrations, m)
+		}
+		merged.InvalidObjects = append(merged.InvalidObjects, qualifyRefs(s.InvalidObjects, owner)...)
+		for _, g := range s.Glossary {
+			if key := strings.ToUpper(g.Term); !terms[key] {
+				terms[key] = true
+				merged.Glossary = append(merged.Glossary, g)
+			}
+		}
+	}
+
+	merged.DatabaseName = strings.Join(names, ", ")
+	if len(types) == 1 && len(parts) > 0 {
+		merged.DatabaseType = parts[0].Schema.DatabaseType
+	} else {
+		merged.DatabaseType = "mixed"
+	}
+	return merged
+}
+
+// SourceOf summarizes a part for the databases overview and index page
+func SourceOf(p Part) model.Source {
+	return model.Source{
+		Name:         p.Name,
+		DatabaseName: p.Schema.DatabaseName,
+		DatabaseType: p.Schema.DatabaseType,
+		Version:      p.Schema.Version,
+		Tables:       len(p.Schema.Tables),
+		Views:        len(p.Schema.Views),
+		Routines:     len(p.Schema.Routines),
+	}
+}
+
+// Qualify prefixes owner with the database name ("prod.HR", or "prod" when
+// owner is empty)
+func Qualify(name, owner string) string {
+	if owner == "" {
+		return name
+	}
+	return name + "." + owner
+}
+
+func qualifyIndexes(indexes []model.Index, owner func(string) string) []model.Index {
+	if indexes == nil {
+		return nil
+	}
+	out := make([]model.Index, len(indexes))
+	for i, idx := range indexes {
+		idx.Owner = owner(idx.Owner)
+		out[i] = idx
+	}
+	return out
+}
+
+func qualifyRefs(refs []model.ObjectRef, owner func(string) string) []model.ObjectRef {
+	if refs == nil {
+		return nil
+	}
+	out := make([]model.ObjectRef, len(refs))
+	for i, r := range refs {
+		r.Owner = owner(r.Owner)
+		out[i] = r
+	}
+	return out
+}
+
+// IndexEntry links one part (database, object type or schema) to its document file
+type IndexEntry struct {
+	Source model.Source
+	File   string
+}
+
+// WriteIndex writes an HTML page linking the per-part documents under the
+// given section heading
+func WriteIndex(w io.Writer, title, heading string, entries []IndexEntry, msg *i18n.Bundle) error {
+	tmpl, err := template.New("index").Funcs(template.FuncMap{"t": msg.T}).Parse(indexTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse index template: %w", err)
+	}
+
+	data := struct {
+		Lang    string
+		Title   string
+		Heading string
+		Entries []IndexEntry
+	}{msg.Language(), title, heading, entries}
+	return tmpl.Execute(w, data)
+}
+
+const indexTemplate = `<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+    <meta charset="UTF-8">
+    <title>{{t "doc.title" .Title}}</title>
+    <style>
+        * {
+            font-family: 'Malgun Gothic', 'Apple SD Gothic Neo', 'Noto Sans KR',
+                         -apple-system, BlinkMacSystemFont, 'Segoe UI', Arial, sans-serif;
+        }
+        body { margin: 20px; color: #333; }
+        h1 { color: #2c3e50; border-bottom: 3px solid #3498db; padding-bottom: 10px; }
+        table { width: 100%; border-collapse: collapse; font-size: 13px; }
+        th { background: #D9D9D9; border: 1px solid #bdc3c7; padding: 8px; text-align: left; }
+        td { border: 1px solid #ecf0f1; padding: 6px 8px; }
+    </style>
+</head>
+<body>
+    <h1>{{t "doc.title" .Title}}</h1>
+    <h2>{{.Heading}}</h2>
+    <table>
+        <thead>
+            <tr>
+                <th>{{t "label.name"}}</th>
+                <th>{{t "label.database_name"}}</th>
+                <th>{{t "label.database_type"}}</th>
+                <th>{{t "label.version"}}</th>
+                <th>{{t "label.total_tables"}}</th>
+                <th>{{t "label.total_views"}}</th>
+                <th>{{t "label.total_routines"}}</th>
+            </tr>
+        </thead>
+        <tbody>
+            {{range .Entries}}
+            <tr>
+                <td><strong><a href="{{.File}}">{{.Source.Name}}</a></strong></td>
+                <td>{{.Source.DatabaseName}}</td>
+                <td>{{.Source.DatabaseType}}</td>
+                <td>{{.Source.Version}}</td>
+                <td>{{.Source.Tables}}</td>
+                <td>{{.Source.Views}}</td>
+                <td>{{.Source.Routines}}</td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+</body>
+</html>
+`
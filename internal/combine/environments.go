@@ -0,0 +1,36 @@
+package combine
+
+import (
+	"pocket-doc/internal/i18n"
+	"pocket-doc/internal/model"
+)
+
+// EnvNote describes a difference of a consolidated schema (see
+// diff.Consolidate) as a sentence, e.g. "Missing in staging" or "type in
+// staging: VARCHAR2(100)"
+func EnvNote(d model.EnvDiff, msg *i18n.Bundle) string {
+	value := d.Value
+	if value == "" {
+		value = msg.T("changelog.empty")
+	}
+	switch {
+	case d.Object != "" && d.Missing:
+		return msg.T("env."+d.Object+"_missing", d.Name, d.Source)
+	case d.Object != "" && d.Field != "":
+		return msg.T("env."+d.Object+"_field", d.Name, msg.T("field."+d.Field), d.Source, value)
+	case d.Object != "":
+		return msg.T("env."+d.Object+"_only", d.Name, d.Source)
+	case d.Missing:
+		return msg.T("env.missing", d.Source)
+	}
+	return msg.T("env.field", msg.T("field."+d.Field), d.Source, value)
+}
+
+// EnvNotes describes the differences of an object, one sentence each
+func EnvNotes(diffs []model.EnvDiff, msg *i18n.Bundle) []string {
+	notes := make([]string, len(diffs))
+	for i, d := range diffs {
+		notes[i] = EnvNote(d, msg)
+	}
+	return notes
+}
@@ -0,0 +1,316 @@
+package combine
+
+import (
+	"path"
+	"pocket-doc/internal/model"
+	"sort"
+	"strings"
+)
+
+// Object type part names used by SplitByType, in document order
+const (
+	PartTables    = "tables"
+	PartViews     = "views"
+	PartRoutines  = "routines"
+	PartSequences = "sequences"
+	PartTriggers  = "triggers"
+	PartSynonyms  = "synonyms"
+)
+
+// SplitByType returns one part per non-empty object type. Indexes, the
+// migration history and the invalid objects go with the tables; every part
+// keeps the database header and the glossary.
+func SplitByType(s *model.Schema) []Part {
+	var parts []Part
+	add := func(name string, n int, fill func(*model.Schema)) {
+		if n == 0 {
+			return
+		}
+		p := header(s)
+		fill(p)
+		parts = append(parts, Part{Name: name, Schema: p})
+	}
+
+	add(PartTables, len(s.Tables), func(p *model.Schema) {
+		p.Tables, p.Indexes, p.Migrations, p.InvalidObjects = s.Tables, s.Indexes, s.Migrations, s.InvalidObjects
+	})
+	add(PartViews, len(s.Views), func(p *model.Schema) { p.Views = s.Views })
+	add(PartRoutines, len(s.Routines), func(p *model.Schema) { p.Routines = s.Routines })
+	add(PartSequences, len(s.Sequences), func(p *model.Schema) { p.Sequences = s.Sequences })
+	add(PartTriggers, len(s.Triggers), func(p *model.Schema) { p.Triggers = s.Triggers })
+	add(PartSynonyms, len(s.Synonyms), func(p *model.Schema) { p.Synonyms = s.Synonyms })
+	return parts
+}
+
+// SplitBySchema returns one part per owner (schema), ordered by name.
+// Objects without an owner form a part named after the database.
+func SplitBySchema(s *model.Schema) []Part {
+	byOwner := make(map[string]*model.Schema)
+	get := func(owner string) *model.Schema {
+		if owner == "" {
+			owner = s.DatabaseName
+		}
+		p, ok := byOwner[owner]
+		if !ok {
+			p = header(s)
+			byOwner[owner] = p
+		}
+		return p
+	}
+
+	for _, t := range s.Tables {
+		p := get(t.Owner)
+		p.Tables = append(p.Tables, t)
+	}
+	for _, v := range s.Views {
+		p := get(v.Owner)
+		p.Views = append(p.Views, v)
+	}
+	for _, r := range s.Routines {
+		p := get(r.Owner)
+		p.Routines = append(p.Routines, r)
+	}
+	for _, q := range s.Sequences {
+		p := get(q.Owner)
+		p.Sequences = append(p.Sequences, q)
+	}
+	for _, t := range s.Triggers {
+		p := get(t.Owner)
+		p.Triggers = append(p.Triggers, t)
+	}
+	for _, y := range s.Synonyms {
+		p := get(y.Owner)
+		p.Synonyms = append(p.Synonyms, y)
+	}
+	for _, idx := range s.Indexes {
+		p := get(idx.Owner)
+		p.Indexes = append(p.Indexes, idx)
+	}
+	for _, m := range s.Migrations {
+		p := get(m.Owner)
+		p.Migrations = append(p.Migrations, m)
+	}
+	for _, o := range s.InvalidObjects {
+		p := get(o.Owner)
+		p.InvalidObjects = append(p.InvalidObjects, o)
+	}
+
+	names := make([]string, 0, len(byOwner))
+	for name := range byOwner {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]Part, len(names))
+	for i, name := range names {
+		parts[i] = Part{Name: name, Schema: byOwner[name]}
+	}
+	return parts
+}
+
+// NoDomain names the part of SplitByDomain holding the objects without a
+// business domain
+const NoDomain = "other"
+
+// SplitByDomain returns one part per business domain of the tables and
+// views (see model.Table.Domain), ordered by name, and a NoDomain part
+// last. Indexes and triggers go with their table or view; routines, sequences,
+// synonyms, the migration history and the invalid objects have no domain.
+func SplitByDomain(s *model.Schema) []Part {
+	byDomain := make(map[string]*model.Schema)
+	get := func(domain string) *model.Schema {
+		if domain == "" {
+			domain = NoDomain
+		}
+		p, ok := byDomain[domain]
+		if !ok {
+			p = header(s)
+			byDomain[domain] = p
+		}
+		return p
+	}
+
+	// Domains of the tables and views by "OWNER.NAME"
+	domains := make(map[string]string)
+	domainOf := func(owner, name string) string {
+		return domains[strings.ToUpper(model.QualifiedName(owner, name))]
+	}
+	for _, t := range s.Tables {
+		p := get(t.Domain)
+		p.Tables = append(p.Tables, t)
+		domains[strings.ToUpper(model.QualifiedName(t.Owner, t.Name))] = t.Domain
+	}
+	for _, v := range s.Views {
+		p := get(v.Domain)
+		p.Views = append(p.Views, v)
+		domains[strings.ToUpper(model.QualifiedName(v.Owner, v.Name))] = v.Domain
+	}
+	for _, idx := range s.Indexes {
+		p := get(domainOf(idx.Owner, idx.TableName))
+		p.Indexes = append(p.Indexes, idx)
+	}
+	for _, t := range s.Triggers {
+		p := get(domainOf(t.Owner, t.TargetTable))
+		p.Triggers = append(p.Triggers, t)
+	}
+	if len(s.Routines)+len(s.Sequences)+len(s.Synonyms)+len(s.Migrations)+len(s.InvalidObjects) > 0 {
+		p := get("")
+		p.Routines, p.Sequences, p.Synonyms, p.Migrations = s.Routines, s.Sequences, s.Synonyms, s.Migrations
+		p.InvalidObjects = s.InvalidObjects
+	}
+
+	names := make([]string, 0, len(byDomain))
+	for name := range byDomain {
+		if name != NoDomain {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := byDomain[NoDomain]; ok {
+		names = append(names, NoDomain)
+	}
+
+	parts := make([]Part, len(names))
+	for i, name := range names {
+		parts[i] = Part{Name: name, Schema: byDomain[name]}
+	}
+	return parts
+}
+
+// SelectDomains returns the objects of schema in the given business
+// domains (case-insensitive), as split by SplitByDomain; NoDomain selects
+// the objects without one
+func SelectDomains(s *model.Schema, domains []string) *model.Schema {
+	selected := header(s)
+	for _, p := range SplitByDomain(s) {
+		for _, d := range domains {
+			if !strings.EqualFold(p.Name, d) {
+				continue
+			}
+			selected.Tables = append(selected.Tables, p.Schema.Tables...)
+			selected.Views = append(selected.Views, p.Schema.Views...)
+			selected.Routines = append(selected.Routines, p.Schema.Routines...)
+			selected.Sequences = append(selected.Sequences, p.Schema.Sequences...)
+			selected.Triggers = append(selected.Triggers, p.Schema.Triggers...)
+			selected.Synonyms = append(selected.Synonyms, p.Schema.Synonyms...)
+			selected.Indexes = append(selected.Indexes, p.Schema.Indexes...)
+			selected.Migrations = append(selected.Migrations, p.Schema.Migrations...)
+			selected.InvalidObjects = append(selected.InvalidObjects, p.Schema.InvalidObjects...)
+			break
+		}
+	}
+	return selected
+}
+
+// SelectTables returns the tables of schema matching one of the patterns
+// (see MatchName) and, with related, the tables they reference or are
+// referenced by through a foreign key, with their indexes and triggers,
+// the views and routines using them and the synonyms pointing at the
+// selected tables and views. Sequences and the migration history are left
+// out. It also returns the number of tables added as related.
+func SelectTables(s *model.Schema, patterns []string, related bool) (*model.Schema, int) {
+	graph := model.NewGraph(s)
+	keep := make(map[*model.Table]bool)
+	var matched []*model.Table
+	for i := range s.Tables {
+		t := &s.Tables[i]
+		if MatchName(patterns, t.Owner, t.Name) {
+			keep[t] = true
+			matched = append(matched, t)
+		}
+	}
+	added := 0
+	if related {
+		for _, t := range matched {
+			for _, other := range append(graph.Parents(t), graph.Children(t)...) {
+				if !keep[other] {
+					keep[other] = true
+					added++
+				}
+			}
+		}
+	}
+
+	selected := header(s)
+	names := make(map[string]bool)          // selected tables and views by "OWNER.NAME"
+	users := make(map[model.ObjectRef]bool) // views and routines using them
+	key := func(owner, name string) string { return strings.ToUpper(model.QualifiedName(owner, name)) }
+	for i := range s.Tables {
+		t := &s.Tables[i]
+		if !keep[t] {
+			continue
+		}
+		selected.Tables = append(selected.Tables, *t)
+		names[key(t.Owner, t.Name)] = true
+		for _, ref := range t.ReferencedBy {
+			users[ref] = true
+		}
+	}
+	for _, v := range s.Views {
+		if users[model.ObjectRef{Type: v.Type, Owner: v.Owner, Name: v.Name}] {
+			selected.Views = append(selected.Views, v)
+			names[key(v.Owner, v.Name)] = true
+		}
+	}
+	for _, r := range s.Routines {
+		if users[model.ObjectRef{Type: r.Type, Owner: r.Owner, Name: r.Name}] {
+			selected.Routines = append(selected.Routines, r)
+			names[key(r.Owner, r.Name)] = true
+		}
+	}
+	for _, idx := range s.Indexes {
+		if names[key(idx.Owner, idx.TableName)] {
+			selected.Indexes = append(selected.Indexes, idx)
+		}
+	}
+	for _, t := range s.Triggers {
+		if names[key(t.Owner, t.TargetTable)] {
+			selected.Triggers = append(selected.Triggers, t)
+		}
+	}
+	for _, y := range s.Synonyms {
+		if names[key(y.TargetOwner, y.TargetObject)] {
+			selected.Synonyms = append(selected.Synonyms, y)
+		}
+	}
+	for _, o := range s.InvalidObjects {
+		if names[key(o.Owner, o.Name)] {
+			selected.InvalidObjects = append(selected.InvalidObjects, o)
+		}
+	}
+	return selected, added
+}
+
+// MatchName reports whether owner.name matches one of the patterns: globs
+// (*, ?, [...]) on "OWNER.NAME" when they contain a dot and on the bare
+// name otherwise, case-insensitively. An invalid pattern matches nothing.
+func MatchName(patterns []string, owner, name string) bool {
+	qualified := strings.ToUpper(model.QualifiedName(owner, name))
+	bare := strings.ToUpper(name)
+	for _, p := range patterns {
+		target := bare
+		if strings.Contains(p, ".") {
+			target = qualified
+		}
+		if ok, _ := path.Match(strings.ToUpper(p), target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// header copies the database-level fields of s into a schema without objects
+func header(s *model.Schema) *model.Schema {
+	return &model.Schema{
+		DatabaseName: s.DatabaseName,
+		DatabaseType: s.DatabaseType,
+		Version:      s.Version,
+		ExtractedAt:  s.ExtractedAt,
+		Comment:      s.Comment,
+		Glossary:     s.Glossary,
+		Sources:      s.Sources,
+		ChangedSince: s.ChangedSince,
+		Environments: s.Environments,
+		Tenants:      s.Tenants,
+	}
+}
@@ -0,0 +1,339 @@
+package comments
+
+import (
+	"pocket-doc/internal/model"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Edit is a new comment for a table (Column empty) or one of its columns.
+// An empty Comment removes the existing one.
+type Edit struct {
+	Owner   string `json:"owner"`
+	Table   string `json:"table"`
+	Column  string `json:"column,omitempty"`
+	Comment string `json:"comment"`
+}
+
+// Current returns the comment the edit replaces, or an error when the
+// table or column is not in the schema
+func (e Edit) Current(schema *model.Schema) (string, error) {
+	t := findTable(schema, e.Owner, e.Table)
+	if t == nil {
+		return "", fmt.Errorf("table %s not found", qualified(e.Owner, e.Table))
+	}
+	if e.Column == "" {
+		return t.Comment, nil
+	}
+	c := findColumn(t, e.Column)
+	if c == nil {
+		return "", fmt.Errorf("column %s.%s not found", qualified(e.Owner, e.Table), e.Column)
+	}
+	return c.Comment, nil
+}
+
+// Script returns the statements that apply edits to the database of
+// schema: COMMENT ON for Oracle and PostgreSQL, extended properties for
+// SQL Server and ALTER TABLE for MySQL. Edits that match the current
+// comment are left out. The script is only generated, never executed.
+func Script(schema *model.Schema, edits []Edit) (string, error) {
+	dialect, ok := dialects[strings.ToLower(schema.DatabaseType)]
+	if !ok {
+		return "", fmt.Errorf("comment scripts are not supported for database type %q", schema.DatabaseType)
+	}
+
+	edits = append([]Edit(nil), edits...)
+	sort.SliceStable(edits, func(i, j int) bool {
+		a, b := edits[i], edits[j]
+		if a.Owner != b.Owner {
+			return a.Owner < b.Owner
+		}
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		return a.Column < b.Column
+	})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "-- Comment changes for %s (%s)\n", schema.DatabaseName, schema.DatabaseType)
+	fmt.Fprintf(&sb, "-- Generated by pocket-doc at %s. Review before running.\n", time.Now().Format("2006-01-02 15:04:05"))
+	if dialect.note != "" {
+		fmt.Fprintf(&sb, "-- %s\n", dialect.note)
+	}
+
+	changes := 0
+	for _, e := range edits {
+		current, err := e.Current(schema)
+		if err != nil {
+			return "", err
+		}
+		if current == e.Comment {
+			continue
+		}
+		t := findTable(schema, e.Owner, e.Table)
+		var stmt string
+		if e.Column == "" {
+			stmt = dialect.table(t, current, e.Comment)
+		} else {
+			stmt, err = dialect.column(t, findColumn(t, e.Column), current, e.Comment)
+			if err != nil {
+				return "", fmt.Errorf("column %s.%s: %w", qualified(e.Owner, e.Table), e.Column, err)
+			}
+		}
+		sb.WriteString("\n" + stmt + "\n")
+		changes++
+	}
+	if changes == 0 {
+		sb.WriteString("\n-- No changes\n")
+	}
+	return sb.String(), nil
+}
+
+// dialect renders the comment statements of one database type; current
+// is the comment being replaced. column fails when the statement cannot
+// be written without changing the column otherwise.
+type dialect struct {
+	note   string
+	table  func(t *model.Table, current, comment string) string
+	column func(t *model.Table, c *model.Column, current, comment string) (string, error)
+}
+
+// dialects by lower-cased model.Schema.DatabaseType
+var dialects = map[string]dialect{
+	"oracle": {
+		// Oracle stores '' as NULL, which removes the comment
+		table: func(t *model.Table, _, comment string) string {
+			return fmt.Sprintf("COMMENT ON TABLE %s IS %s;", ansiName(t.Owner, t.Name), quote(comment))
+		},
+		column: func(t *model.Table, c *model.Column, _, comment string) (string, error) {
+			return fmt.Sprintf("COMMENT ON COLUMN %s IS %s;", ansiName(t.Owner, t.Name, c.Name), quote(comment)), nil
+		},
+	},
+	"postgresql": {
+		table: func(t *model.Table, _, comment string) string {
+			return fmt.Sprintf("COMMENT ON TABLE %s IS %s;", ansiName(t.Owner, t.Name), nullIfEmpty(comment))
+		},
+		column: func(t *model.Table, c *model.Column, _, comment string) (string, error) {
+			return fmt.Sprintf("COMMENT ON COLUMN %s IS %s;", ansiName(t.Owner, t.Name, c.Name), nullIfEmpty(comment)), nil
+		},
+	},
+	"mssql": {
+		table: func(t *model.Table, current, comment string) string {
+			return extendedProperty(t, "", current, comment)
+		},
+		column: func(t *model.Table, c *model.Column, current, comment string) (string, error) {
+			return extendedProperty(t, c.Name, current, comment), nil
+		},
+	},
+	"mysql": {
+		note: "MODIFY COLUMN restates the column definition as documented; check it against SHOW CREATE TABLE before running.",
+		table: func(t *model.Table, _, comment string) string {
+			return fmt.Sprintf("ALTER TABLE %s COMMENT = %s;", mysqlName(t.Owner, t.Name), mysqlQuote(comment))
+		},
+		column: func(t *model.Table, c *model.Column, _, comment string) (string, error) {
+			def, err := mysqlDefinition(c)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s COMMENT %s;",
+				mysqlName(t.Owner, t.Name), mysqlName(c.Name), def, mysqlQuote(comment)), nil
+		},
+	},
+}
+
+// extendedProperty adds, updates or drops the MS_Description property of
+// a table or column, depending on whether it exists
+func extendedProperty(t *model.Table, column, current, comment string) string {
+	proc, value := "sp_updateextendedproperty", ", @value = "+nquote(comment)
+	switch {
+	case current == "":
+		proc = "sp_addextendedproperty"
+	case comment == "":
+		proc, value = "sp_dropextendedproperty", ""
+	}
+
+	owner := t.Owner
+	if owner == "" {
+		owner = "dbo"
+	}
+	stmt := fmt.Sprintf("EXEC %s @name = N'MS_Description'%s, @level0type = N'SCHEMA', @level0name = %s, @level1type = N'TABLE', @level1name = %s",
+		proc, value, nquote(owner), nquote(t.Name))
+	if column != "" {
+		stmt += ", @level2type = N'COLUMN', @level2name = " + nquote(column)
+	}
+	return stmt + ";"
+}
+
+// mysqlDefinition restates a column for MODIFY COLUMN from its declared
+// type and attributes as INFORMATION_SCHEMA.COLUMNS reports them
+// (COLUMN_TYPE, EXTRA, GENERATION_EXPRESSION), so that UNSIGNED, ZEROFILL,
+// enum and set members, ON UPDATE and generated columns survive. Columns
+// lacking them, extracted by an older version or from a server that does
+// not report generation expressions, are refused rather than restated
+// differently.
+func mysqlDefinition(c *model.Column) (string, error) {
+	if c.ColumnType == "" {
+		return "", fmt.Errorf("its declared type was not extracted; extract the schema again to edit the comment")
+	}
+	extra, err := mysqlAttributes(c.Extra)
+	if err != nil {
+		return "", err
+	}
+
+	def := c.ColumnType
+	if c.CharacterSet != "" {
+		def += " CHARACTER SET " + c.CharacterSet
+	}
+	if c.Collation != "" {
+		def += " COLLATE " + c.Collation
+	}
+	if extra.generated != "" {
+		if c.Generated == "" {
+			return "", fmt.Errorf("the expression of the generated column was not extracted (MySQL 5.7 or later reports it)")
+		}
+		def += fmt.Sprintf(" GENERATED ALWAYS AS (%s) %s", c.Generated, extra.generated)
+	}
+	if c.Nullable {
+		def += " NULL"
+	} else {
+		def += " NOT NULL"
+	}
+	if c.DefaultValue != "" && extra.generated == "" {
+		if extra.defaultExpression && !mysqlLiteral.MatchString(c.DefaultValue) {
+			def += " DEFAULT (" + c.DefaultValue + ")"
+		} else {
+			def += " DEFAULT " + mysqlDefault(c.DefaultValue)
+		}
+	}
+	if extra.onUpdate != "" {
+		def += " ON UPDATE " + extra.onUpdate
+	}
+	if c.IsAutoIncrement {
+		def += " AUTO_INCREMENT"
+	}
+	if extra.invisible {
+		def += " INVISIBLE"
+	}
+	return def, nil
+}
+
+// mysqlExtra holds the attributes of a MySQL EXTRA value
+type mysqlExtra struct {
+	generated         string // VIRTUAL or STORED
+	onUpdate          string // e.g. CURRENT_TIMESTAMP(3)
+	defaultExpression bool   // the default is an expression (8.0.13)
+	invisible         bool
+}
+
+// mysqlExtraPart matches one attribute of EXTRA
+var mysqlExtraPart = regexp.MustCompile(`(?i)^(auto_increment|default_generated|invisible|(virtual|stored) generated|on update (current_timestamp(\([0-9]*\))?))(\s+|$)`)
+
+// mysqlAttributes parses EXTRA, failing on attributes it does not know
+// rather than leaving them out of the restated column
+func mysqlAttributes(extra string) (mysqlExtra, error) {
+	var a mysqlExtra
+	rest := strings.TrimSpace(extra)
+	for rest != "" {
+		m := mysqlExtraPart.FindStringSubmatch(rest)
+		if m == nil {
+			return a, fmt.Errorf("column attribute %q cannot be restated for MODIFY COLUMN", rest)
+		}
+		switch part := strings.ToLower(m[1]); {
+		case part == "default_generated":
+			a.defaultExpression = true
+		case part == "invisible":
+			a.invisible = true
+		case m[2] != "":
+			a.generated = strings.ToUpper(m[2])
+		case m[3] != "":
+			a.onUpdate = strings.ToUpper(m[3])
+		}
+		rest = rest[len(m[0]):]
+	}
+	return a, nil
+}
+
+// mysqlLiteral matches defaults that are written without quotes: numbers,
+// NULL and CURRENT_TIMESTAMP-like functions
+var mysqlLiteral = regexp.MustCompile(`(?i)^(-?[0-9]+(\.[0-9]+)?|null|current_timestamp(\([0-9]*\))?|now\(\)|b'[01]*')$`)
+
+// mysqlDefault renders a default as INFORMATION_SCHEMA.COLUMNS reports it
+func mysqlDefault(value string) string {
+	if mysqlLiteral.MatchString(value) || strings.HasPrefix(value, "'") || strings.HasPrefix(value, "(") {
+		return value
+	}
+	return mysqlQuote(value)
+}
+
+// ansiName double-quotes and joins identifier parts, skipping an empty owner
+func ansiName(parts ...string) string {
+	var quoted []string
+	for _, p := range parts {
+		if p != "" {
+			quoted = append(quoted, `"`+strings.ReplaceAll(p, `"`, `""`)+`"`)
+		}
+	}
+	return strings.Join(quoted, ".")
+}
+
+// mysqlName backquotes and joins identifier parts, skipping an empty owner
+func mysqlName(parts ...string) string {
+	var quoted []string
+	for _, p := range parts {
+		if p != "" {
+			quoted = append(quoted, "`"+strings.ReplaceAll(p, "`", "``")+"`")
+		}
+	}
+	return strings.Join(quoted, ".")
+}
+
+// quote returns s as an SQL string literal
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// nquote returns s as a Unicode (N'...') string literal
+func nquote(s string) string {
+	return "N" + quote(s)
+}
+
+// mysqlQuote returns s as a MySQL string literal, which also escapes
+// backslashes
+func mysqlQuote(s string) string {
+	return quote(strings.ReplaceAll(s, `\`, `\\`))
+}
+
+func nullIfEmpty(s string) string {
+	if s == "" {
+		return "NULL"
+	}
+	return quote(s)
+}
+
+func findTable(schema *model.Schema, owner, name string) *model.Table {
+	for i := range schema.Tables {
+		if t := &schema.Tables[i]; t.Owner == owner && t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+func findColumn(t *model.Table, name string) *model.Column {
+	for i := range t.Columns {
+		if c := &t.Columns[i]; c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func qualified(owner, name string) string {
+	if owner == "" {
+		return name
+	}
+	return owner + "." + name
+}
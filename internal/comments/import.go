@@ -0,0 +1,254 @@
+package comments
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"pocket-doc/internal/i18n"
+	"pocket-doc/internal/model"
+	"regexp"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Imported is the outcome of Import
+type Imported struct {
+	// Edits are the comments that differ from the schema
+	Edits []Edit
+	// Unchanged counts rows whose comment matches the schema
+	Unchanged int
+	// Skipped describes rows naming a view or an object missing from the
+	// schema, e.g. "Columns row 12: column HR.EMP.FOO not found"
+	Skipped []string
+}
+
+// Import reads comments authored in a spreadsheet in the layout of the
+// Excel export and diffs them against schema. An .xlsx file is read from
+// its Columns sheet (table, column name, ..., comment) and, when present,
+// its Tables sheet (name, owner, ..., comment); a .csv file holds the
+// Columns sheet alone. Header rows are recognized in every language of
+// the exporter. Blank comments are ignored rather than taken as removals,
+// so rows left empty by reviewers keep the current comment.
+func Import(path string, schema *model.Schema) (*Imported, error) {
+	sheets, err := readSheets(path)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Imported{}
+	found := false
+	for _, sh := range sheets {
+		switch {
+		case sh.is("Columns"):
+			if res.columns(sh, schema) {
+				found = true
+			}
+		case sh.is("Tables"):
+			res.tables(sh, schema)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%s: no Columns sheet with table, column name and comment headers", filepath.Base(path))
+	}
+	return res, nil
+}
+
+// sheet is the cell text of one worksheet
+type sheet struct {
+	name string
+	rows [][]string
+}
+
+// continuation matches the names of the sheets an export continues on
+// when one is full, e.g. "Columns (2)"
+var continuation = regexp.MustCompile(` \([0-9]+\)$`)
+
+// is reports whether the sheet is base or one of its continuations
+func (s sheet) is(base string) bool {
+	return continuation.ReplaceAllString(s.name, "") == base
+}
+
+// header returns the row index of the first row holding all headers
+// (message keys) in one language and their column indexes
+func (s sheet) header(keys ...string) (row int, cols []int, ok bool) {
+	for r, cells := range s.rows {
+		for _, lang := range i18n.Languages() {
+			msg := i18n.New(lang)
+			cols = cols[:0]
+			for _, key := range keys {
+				i := indexOf(cells, msg.T(key))
+				if i < 0 {
+					break
+				}
+				cols = append(cols, i)
+			}
+			if len(cols) == len(keys) {
+				return r, cols, true
+			}
+		}
+	}
+	return 0, nil, false
+}
+
+// columns collects the column comments of a Columns sheet; ok is false
+// without a header row
+func (res *Imported) columns(sh sheet, schema *model.Schema) (ok bool) {
+	start, cols, ok := sh.header("label.table", "label.column_name", "label.comment")
+	if !ok {
+		return false
+	}
+	names := tableNames(schema)
+	for r := start + 1; r < len(sh.rows); r++ {
+		object, column, comment := cell(sh.rows[r], cols[0]), cell(sh.rows[r], cols[1]), cell(sh.rows[r], cols[2])
+		// Classification banners and schema sections fill the first cell only
+		if column == "" || comment == "" {
+			continue
+		}
+		where := fmt.Sprintf("%s row %d", sh.name, r+1)
+		t, found := names[object]
+		if !found {
+			res.skip(where, object, column, comment, schema)
+			continue
+		}
+		res.add(where, schema, Edit{Owner: t.Owner, Table: t.Name, Column: column, Comment: comment})
+	}
+	return true
+}
+
+// tables collects the table comments of a Tables sheet
+func (res *Imported) tables(sh sheet, schema *model.Schema) {
+	start, cols, ok := sh.header("label.name", "label.owner", "label.comment")
+	if !ok {
+		return
+	}
+	for r := start + 1; r < len(sh.rows); r++ {
+		name, owner, comment := cell(sh.rows[r], cols[0]), cell(sh.rows[r], cols[1]), cell(sh.rows[r], cols[2])
+		if name == "" || comment == "" {
+			continue
+		}
+		where := fmt.Sprintf("%s row %d", sh.name, r+1)
+		object := model.QualifiedName(owner, name)
+		if findTable(schema, owner, name) == nil {
+			res.skip(where, object, "", comment, schema)
+			continue
+		}
+		res.add(where, schema, Edit{Owner: owner, Table: name, Comment: comment})
+	}
+}
+
+// add keeps e when it changes the current comment
+func (res *Imported) add(where string, schema *model.Schema, e Edit) {
+	current, err := e.Current(schema)
+	switch {
+	case err != nil:
+		res.Skipped = append(res.Skipped, fmt.Sprintf("%s: %v", where, err))
+	case current == e.Comment:
+		res.Unchanged++
+	default:
+		res.Edits = append(res.Edits, e)
+	}
+}
+
+// skip records a row naming no table. Views are not scripted, so only
+// changed view comments are reported.
+func (res *Imported) skip(where, object, column, comment string, schema *model.Schema) {
+	for _, v := range schema.Views {
+		if object != v.Name && object != model.QualifiedName(v.Owner, v.Name) {
+			continue
+		}
+		current, found := v.Comment, column == ""
+		for _, c := range v.Columns {
+			if column != "" && c.Name == column {
+				current, found = c.Comment, true
+			}
+		}
+		if found && current == comment {
+			res.Unchanged++
+		} else {
+			res.Skipped = append(res.Skipped, fmt.Sprintf("%s: %s is a view", where, object))
+		}
+		return
+	}
+	res.Skipped = append(res.Skipped, fmt.Sprintf("%s: table %s not found", where, object))
+}
+
+// tableNames maps the names the Columns sheet shows to the tables: the
+// qualified name, and the bare name when no other table shares it
+func tableNames(schema *model.Schema) map[string]*model.Table {
+	names := make(map[string]*model.Table)
+	bare := make(map[string]int)
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		names[model.QualifiedName(t.Owner, t.Name)] = t
+		bare[t.Name]++
+	}
+	for i := range schema.Tables {
+		if t := &schema.Tables[i]; bare[t.Name] == 1 {
+			if _, taken := names[t.Name]; !taken {
+				names[t.Name] = t
+			}
+		}
+	}
+	return names
+}
+
+// readSheets reads the worksheets of an .xlsx file, or a .csv file as a
+// single Columns sheet
+func readSheets(path string) ([]sheet, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xlsx":
+		f, err := excelize.OpenFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open spreadsheet: %w", err)
+		}
+		defer f.Close()
+		var sheets []sheet
+		for _, name := range f.GetSheetList() {
+			rows, err := f.GetRows(name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read sheet %s: %w", name, err)
+			}
+			sheets = append(sheets, sheet{name: name, rows: rows})
+		}
+		return sheets, nil
+	case ".csv":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV file: %w", err)
+		}
+		data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+		r := csv.NewReader(bytes.NewReader(data))
+		r.FieldsPerRecord = -1
+		// Excel writes ';' separated files in locales with a decimal comma
+		firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+		if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+			r.Comma = ';'
+		}
+		rows, err := r.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CSV file: %w", err)
+		}
+		return []sheet{{name: "Columns", rows: rows}}, nil
+	}
+	return nil, fmt.Errorf("unsupported comment file %s (use .xlsx or .csv)", filepath.Base(path))
+}
+
+// cell returns the trimmed text of column i of row, "" past its end
+func cell(row []string, i int) string {
+	if i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+func indexOf(cells []string, text string) int {
+	for i, c := range cells {
+		if strings.TrimSpace(c) == text {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultCacheMaxAge is the age in days after which cached objects are read
+// again when max_age_days is zero
+const DefaultCacheMaxAge = 7
+
+// CacheConfig enables the on-disk cache of per-object extraction results
+// (see internal/cache). Only databases that record a last DDL time per
+// table (Oracle, SQL Server) use it.
+type CacheConfig struct {
+	Dir        string `mapstructure:"dir" yaml:"dir"`                   // cache directory; empty disables caching
+	MaxAgeDays int    `mapstructure:"max_age_days" yaml:"max_age_days"` // read objects again after this many days; 0 = 7
+}
+
+// MaxAge returns how long cached objects are used
+func (c CacheConfig) MaxAge() time.Duration {
+	days := c.MaxAgeDays
+	if days <= 0 {
+		days = DefaultCacheMaxAge
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// checkCache reports a negative maximum age
+func checkCache(c CacheConfig) error {
+	if c.MaxAgeDays < 0 {
+		return fmt.Errorf("extract.cache.max_age_days must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CatalogTypes are the values of catalog.type
+var CatalogTypes = []string{"openmetadata", "datahub"}
+
+// CatalogConfig selects the data catalog the catalog command pushes
+// tables, columns and descriptions to
+type CatalogConfig struct {
+	Type     string `mapstructure:"type" yaml:"type"`         // openmetadata, datahub
+	URL      string `mapstructure:"url" yaml:"url"`           // API server
+	Token    string `mapstructure:"token" yaml:"token"`       // bearer token, e.g. ${OPENMETADATA_TOKEN}
+	Service  string `mapstructure:"service" yaml:"service"`   // OpenMetadata database service (must exist)
+	Platform string `mapstructure:"platform" yaml:"platform"` // DataHub platform (default from database.type)
+	Env      string `mapstructure:"env" yaml:"env"`           // DataHub environment (default PROD)
+	Database string `mapstructure:"database" yaml:"database"` // database name in the catalog (default the extracted one)
+	Timeout  int    `mapstructure:"timeout" yaml:"timeout"`   // seconds per request (0 = 30)
+}
+
+// checkCatalog reports an unknown catalog type, a URL that is not http(s),
+// an OpenMetadata catalog without service and a negative timeout
+func checkCatalog(c CatalogConfig) error {
+	if c.Type == "" {
+		return nil
+	}
+	if !slices.Contains(CatalogTypes, strings.ToLower(c.Type)) {
+		return fmt.Errorf("unknown catalog.type: %s (use %s)", c.Type, strings.Join(CatalogTypes, ", "))
+	}
+	if !httpURL(c.URL) {
+		return fmt.Errorf("catalog.url is not an http(s) URL")
+	}
+	if strings.EqualFold(c.Type, "openmetadata") && c.Service == "" {
+		return fmt.Errorf("catalog.service is required for openmetadata")
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("catalog.timeout must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Check reports every configuration problem instead of stopping at the
+// first one as Validate does. dbSupported and formatSupported tell whether
+// a database type or export format is registered.
+func (c *Config) Check(dbSupported, formatSupported func(string) bool) []error {
+	var errs []error
+	db := c.Database
+
+	switch {
+	case db.Type == "":
+		errs = append(errs, ErrMissingDBType)
+	case !dbSupported(db.Type):
+		errs = append(errs, fmt.Errorf("%w: %s", ErrInvalidDBType, db.Type))
+	}
+	if db.IsDbt() {
+		// No server: database names the dbt target directory
+		dir := db.Database
+		if dir == "" {
+			dir = "target"
+		}
+		if _, err := os.Stat(filepath.Join(dir, "manifest.json")); err != nil {
+			errs = append(errs, fmt.Errorf("database.database: %w", err))
+		}
+	} else {
+		if db.Host == "" {
+			errs = append(errs, ErrMissingHost)
+		}
+		if db.Database == "" {
+			errs = append(errs, ErrMissingDatabase)
+		}
+		if db.Port <= 0 || db.Port > 65535 {
+			errs = append(errs, fmt.Errorf("%w: %d", ErrInvalidPort, db.Port))
+		}
+	}
+	if c.Output.Format != "" && !formatSupported(c.Output.Format) {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrInvalidFormat, c.Output.Format))
+	}
+
+	if err := checkFileName(c.Output.FileName); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkSplit(c.Output.SplitBy); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkGroupBy(c.Output.GroupBy); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkDomains(c.Output.Domains); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkColumnWidths(c.Output.MinColumnWidth, c.Output.MaxColumnWidth); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkLineageDepth(c.Output.LineageDepth); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkTenants(c.Output.Tenants); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkTableFilter(c.Extract.TableFilter); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkTimeouts(c.Database, c.Extract); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkTimezone(c.Output.Timezone); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkPage(c.Output.Page); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkNotify(c.Output.Notify); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkUpload(c.Output.Upload); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkGit(c.Output.Git); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkCatalog(c.Catalog); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkUI(c.UI); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkSchemaDefault(db.SchemaDefault); err != nil {
+		errs = append(errs, err)
+	} else if _, err := db.Schemas(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkSamples(c.Extract.Samples); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkCache(c.Extract.Cache); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Files referenced by the output and ui sections must exist
+	for _, f := range []struct{ key, path string }{
+		{"output.template", c.Output.Template},
+		{"output.css_file", c.Output.CSSFile},
+		{"output.glossary_file", c.Output.GlossaryFile},
+		{"output.annotations_file", c.Output.AnnotationsFile},
+		{"output.dbt_manifest", c.Output.DbtManifest},
+		{"output.pii_rules_file", c.Output.PIIRulesFile},
+		{"output.label_file", c.Output.LabelFile},
+		{"output.locale_dir", c.Output.LocaleDir},
+		{"ui.tls_cert", c.UI.TLSCert},
+		{"ui.tls_key", c.UI.TLSKey},
+	} {
+		if f.path == "" {
+			continue
+		}
+		if _, err := os.Stat(f.path); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.key, err))
+		}
+	}
+
+	for _, name := range c.Multi.Profiles {
+		if _, ok := c.Profiles[name]; !ok {
+			errs = append(errs, fmt.Errorf("multi.profiles: %w: %s", ErrUnknownProfile, name))
+		}
+	}
+	return errs
+}
@@ -1,118 +1,428 @@
-package config
-
-// Config represents the complete application configuration
-// All fields use mapstructure tags for Viper compatibility
-type Config struct {
-	Database DatabaseConfig `mapstructure:"database"`
-	Output   OutputConfig   `mapstructure:"output"`
-	Extract  ExtractConfig  `mapstructure:"extract"`
-	Logging  LogConfig      `mapstructure:"logging"`
-}
-
-// DatabaseConfig holds database connection settings
-type DatabaseConfig struct {
-	Type         string            `mapstructure:"type"` // oracle, postgresql, mysql, sqlserver, sqlite
-	Host         string            `mapstructure:"host"`
-	Port         int               `mapstructure:"port"`
-	Database     string            `mapstructure:"database"`
-	Username     string            `mapstructure:"username"`
-	Password     string            `mapstructure:"password"`
-	SSLMode      string            `mapstructure:"ssl_mode"`      // disable, require, verify-ca, verify-full
-	Timeout      int               `mapstructure:"timeout"`       // connection timeout in seconds
-	SchemaFilter []string          `mapstructure:"schema_filter"` // Filter by schema/owner
-	Options      map[string]string `mapstructure:"options"`       // additional driver-specific options
-}
-
-// OutputConfig controls document generation settings
-type OutputConfig struct {
-	Format           string   `mapstructure:"format"` // markdown, html, pdf, xlsx, docx
-	OutputDir        string   `mapstructure:"output_dir"`
-	FileName         string   `mapstructure:"file_name"`
-	IncludeTOC       bool     `mapstructure:"include_toc"`        // Table of Contents
-	IncludeCoverPage bool     `mapstructure:"include_cover_page"` // Cover page for Word/PDF
-	IncludeERD       bool     `mapstructure:"include_erd"`        // Entity Relationship Diagram
-	SplitByType      bool     `mapstructure:"split_by_type"`      // Separate files per object type
-	Language         string   `mapstructure:"language"`           // en, ko for templates
-	Template         string   `mapstructure:"template"`           // custom template path
-	ExcludeTypes     []string `mapstructure:"exclude_types"`      // Object types to skip
-	CompanyName      string   `mapstructure:"company_name"`       // For cover page
-	ProjectName      string   `mapstructure:"project_name"`       // For cover page
-	Author           string   `mapstructure:"author"`             // Document author
-	ColorScheme      string   `mapstructure:"color_scheme"`       // default, professional, minimal
-}
-
-// ExtractConfig controls what metadata to extract
-type ExtractConfig struct {
-	IncludeTables    bool `mapstructure:"include_tables"`
-	IncludeViews     bool `mapstructure:"include_views"`
-	IncludeRoutines  bool `mapstructure:"include_routines"`
-	IncludeSequences bool `mapstructure:"include_sequences"`
-	IncludeTriggers  bool `mapstructure:"include_triggers"`
-	IncludeSynonyms  bool `mapstructure:"include_synonyms"`
-	IncludeIndexes   bool `mapstructure:"include_indexes"`
-
-	// Filter options
-	SchemaFilter  []string `mapstructure:"schema_filter"`  // Only extract these schemas/owners
-	TableFilter   []string `mapstructure:"table_filter"`   // Only extract these tables
-	ExcludeSystem bool     `mapstructure:"exclude_system"` // Skip system objects
-
-	// Row count estimation
-	IncludeRowCounts bool `mapstructure:"include_row_counts"`
-	MaxRowCountTime  int  `mapstructure:"max_row_count_time"` // Max seconds for counting
-}
-
-// LogConfig controls logging behavior
-type LogConfig struct {
-	Level  string `mapstructure:"level"`  // debug, info, warn, error
-	Format string `mapstructure:"format"` // json, text
-	File   string `mapstructure:"file"`   // log file path (empty = stdout)
-}
-
-// Validate performs basic validation on the configuration
-func (c *Config) Validate() error {
-	if c.Database.Type == "" {
-		return ErrMissingDBType
-	}
-	if c.Output.Format == "" {
-		c.Output.Format = "markdown" // default
-	}
-	if c.Output.OutputDir == "" {
-		c.Output.OutputDir = "./output" // default
-	}
-	return nil
-}
-
-// Default returns a configuration with sensible defaults
-func Default() *Config {
-	return &Config{
-		Database: DatabaseConfig{
-			Timeout: 30,
-			SSLMode: "disable",
-		},
-		Output: OutputConfig{
-			Format:      "markdown",
-			OutputDir:   "./output",
-			FileName:    "schema_documentation",
-			IncludeTOC:  true,
-			IncludeERD:  false,
-			SplitByType: false,
-			Language:    "en",
-		},
-		Extract: ExtractConfig{
-			IncludeTables:    true,
-			IncludeViews:     true,
-			IncludeRoutines:  true,
-			IncludeSequences: true,
-			IncludeTriggers:  true,
-			IncludeSynonyms:  true,
-			IncludeIndexes:   true,
-			ExcludeSystem:    true,
-			IncludeRowCounts: false,
-			MaxRowCountTime:  10,
-		},
-		Logging: LogConfig{
-			Level:  "info",
-			Format: "text",
-		},
-	}
-}
+package config
+
+import "strings"
+
+// Config represents the complete application configuration
+// Fields carry mapstructure tags for Viper compatibility and yaml tags for LoadConfig
+type Config struct {
+	Database DatabaseConfig `mapstructure:"database" yaml:"database"`
+	Output   OutputConfig   `mapstructure:"output" yaml:"output"`
+	Extract  ExtractConfig  `mapstructure:"extract" yaml:"extract"`
+	Logging  LogConfig      `mapstructure:"logging" yaml:"logging"`
+	Lint     LintConfig     `mapstructure:"lint" yaml:"lint"`
+	UI       UIConfig       `mapstructure:"ui" yaml:"ui"`
+
+	Credentials CredentialsConfig `mapstructure:"credentials" yaml:"credentials"`
+	Catalog     CatalogConfig     `mapstructure:"catalog" yaml:"catalog"` // data catalog of the catalog command
+
+	Profile  string             `mapstructure:"profile" yaml:"profile"`   // default profile name
+	Profiles map[string]Profile `mapstructure:"profiles" yaml:"profiles"` // named connections
+	Multi    MultiConfig        `mapstructure:"multi" yaml:"multi"`       // several profiles in one run
+}
+
+// MultiConfig documents several connection profiles in one run
+type MultiConfig struct {
+	Profiles    []string `mapstructure:"profiles" yaml:"profiles"`       // profile names, in document order
+	Layout      string   `mapstructure:"layout" yaml:"layout"`           // combined (default), separate, environments
+	Concurrency int      `mapstructure:"concurrency" yaml:"concurrency"` // parallel extractions (default 4)
+}
+
+// Profile is a named connection. Its database settings are merged over the
+// database section, so shared values (type, options) need not be repeated;
+// a profile with a credentials provider replaces the credentials section.
+type Profile struct {
+	Database    DatabaseConfig    `mapstructure:"database" yaml:"database"`
+	Credentials CredentialsConfig `mapstructure:"credentials" yaml:"credentials"`
+	Notify      NotifyConfig      `mapstructure:"notify" yaml:"notify"` // channels set here replace those of output.notify
+}
+
+// DatabaseConfig holds database connection settings
+type DatabaseConfig struct {
+	Type          string            `mapstructure:"type" yaml:"type"` // oracle, postgresql, mysql, sqlserver, sqlite
+	Host          string            `mapstructure:"host" yaml:"host"`
+	Port          int               `mapstructure:"port" yaml:"port"`
+	Database      string            `mapstructure:"database" yaml:"database"`
+	Username      string            `mapstructure:"username" yaml:"username"`
+	Password      string            `mapstructure:"password" yaml:"password"`
+	SSLMode       string            `mapstructure:"ssl_mode" yaml:"ssl_mode"`             // disable, require, verify-ca, verify-full
+	Timeout       int               `mapstructure:"timeout" yaml:"timeout"`               // connection timeout in seconds (0 = 30)
+	SchemaFilter  []string          `mapstructure:"schema_filter" yaml:"schema_filter"`   // Filter by schema/owner
+	SchemaDefault string            `mapstructure:"schema_default" yaml:"schema_default"` // Oracle without schema_filter: user, all (see Schemas)
+	Options       map[string]string `mapstructure:"options" yaml:"options"`               // additional driver-specific options
+}
+
+// CredentialsConfig reads the database user name and password from a secret
+// store instead of the database section (see internal/credentials)
+type CredentialsConfig struct {
+	Provider    string `mapstructure:"provider" yaml:"provider"`         // vault, aws, azure (empty = none)
+	Address     string `mapstructure:"address" yaml:"address"`           // Vault address / Key Vault URL
+	Secret      string `mapstructure:"secret" yaml:"secret"`             // Vault path, AWS secret ID, Key Vault secret name
+	Region      string `mapstructure:"region" yaml:"region"`             // AWS region
+	UsernameKey string `mapstructure:"username_key" yaml:"username_key"` // JSON key of the user name (default username)
+	PasswordKey string `mapstructure:"password_key" yaml:"password_key"` // JSON key of the password (default password)
+}
+
+// OutputConfig controls document generation settings
+type OutputConfig struct {
+	Format           string   `mapstructure:"format" yaml:"format"` // xlsx, docx, html, json (see exporter formats)
+	OutputDir        string   `mapstructure:"output_dir" yaml:"output_dir"`
+	FileName         string   `mapstructure:"file_name" yaml:"file_name"`                   // e.g. {db}_{date}_schema (see FileNameFields)
+	IncludeTOC       bool     `mapstructure:"include_toc" yaml:"include_toc"`               // Table of Contents
+	IncludeCoverPage bool     `mapstructure:"include_cover_page" yaml:"include_cover_page"` // Cover page for HTML, Word/PDF
+	IncludeERD       bool     `mapstructure:"include_erd" yaml:"include_erd"`               // Entity Relationship Diagram
+	SplitByType      bool     `mapstructure:"split_by_type" yaml:"split_by_type"`           // Separate files per object type
+	SplitBy          string   `mapstructure:"split_by" yaml:"split_by"`                     // type, schema, domain (split_by_type: true = type)
+	Language         string   `mapstructure:"language" yaml:"language"`                     // en, ko, ja, zh-CN, de, fr, es
+	LocaleDir        string   `mapstructure:"locale_dir" yaml:"locale_dir"`                 // extra <lang>.json message catalogs
+	LabelFile        string   `mapstructure:"label_file" yaml:"label_file"`                 // YAML label overrides for Language
+	Template         string   `mapstructure:"template" yaml:"template"`                     // custom template path
+	CSSFile          string   `mapstructure:"css_file" yaml:"css_file"`                     // extra stylesheet for HTML output
+	PageBreak        string   `mapstructure:"page_break" yaml:"page_break"`                 // HTML print breaks: avoid, table, none
+	SortBy           string   `mapstructure:"sort_by" yaml:"sort_by"`                       // catalog, name, schema, rows
+	GroupBy          string   `mapstructure:"group_by" yaml:"group_by"`                     // schema: chapters (Word, HTML) or grouping rows (Excel) per schema; domain: chapters per domain
+	RawOrder         bool     `mapstructure:"raw_order" yaml:"raw_order"`                   // keep the extractor's order instead of normalizing it
+	Classification   string   `mapstructure:"classification" yaml:"classification"`         // confidentiality label, e.g. INTERNAL
+	GlossaryFile     string   `mapstructure:"glossary_file" yaml:"glossary_file"`           // business terms + table/column mapping
+	AnnotationsFile  string   `mapstructure:"annotations_file" yaml:"annotations_file"`     // table/column notes, teams, classifications
+	DbtManifest      string   `mapstructure:"dbt_manifest" yaml:"dbt_manifest"`             // dbt manifest.json; its descriptions fill missing comments
+	PIIRulesFile     string   `mapstructure:"pii_rules_file" yaml:"pii_rules_file"`         // regex rules tagging sensitive columns
+	SinceSnapshot    string   `mapstructure:"since_snapshot" yaml:"since_snapshot"`         // mark tables and columns changed since this JSON snapshot
+	IncludeCoverage  bool     `mapstructure:"include_coverage" yaml:"include_coverage"`     // comment coverage section in documents
+	IncludeCleanup   bool     `mapstructure:"include_cleanup" yaml:"include_cleanup"`       // appendix of unrelated/empty tables, disabled and invalid objects
+	IncludeNaming    bool     `mapstructure:"include_naming" yaml:"include_naming"`         // appendix of column prefixes/suffixes and their meanings
+	IncludeImpact    bool     `mapstructure:"include_impact_matrix" yaml:"include_impact_matrix"` // views × base tables dependency matrix
+	LineageDepth     int      `mapstructure:"lineage_depth" yaml:"lineage_depth"`                 // levels of foreign key parents and children shown per table (0 = none)
+	HideSingleSchema bool     `mapstructure:"hide_single_schema" yaml:"hide_single_schema"` // bare object names when only one schema is documented
+	MinCoverage      float64  `mapstructure:"min_coverage" yaml:"min_coverage"`             // coverage mode fails below this column %
+	ExcludeTypes     []string `mapstructure:"exclude_types" yaml:"exclude_types"`           // Object types to skip
+	CompanyName      string   `mapstructure:"company_name" yaml:"company_name"`             // For cover page
+	ProjectName      string   `mapstructure:"project_name" yaml:"project_name"`             // For cover page
+	Author           string   `mapstructure:"author" yaml:"author"`                         // Document author
+	ColorScheme      string   `mapstructure:"color_scheme" yaml:"color_scheme"`             // default, professional, minimal
+	MinColumnWidth   float64  `mapstructure:"min_column_width" yaml:"min_column_width"`     // Excel column width bounds in characters
+	MaxColumnWidth   float64  `mapstructure:"max_column_width" yaml:"max_column_width"`     // (0 = 8 and 60)
+
+	// Business domains of tables and views, by name pattern (annotations
+	// set them too); include_domains documents only the objects of some
+	Domains        []DomainRule `mapstructure:"domains" yaml:"domains"`
+	IncludeDomains []string     `mapstructure:"include_domains" yaml:"include_domains"` // domain names, "other" for objects without one
+
+	// Document identical per-tenant schemas once
+	Tenants TenantsConfig `mapstructure:"tenants" yaml:"tenants"`
+
+	// Fonts of Word and HTML documents
+	Font FontConfig `mapstructure:"font" yaml:"font"`
+
+	// Paper of Word, printed HTML (and PDF) and Excel documents
+	Page PageConfig `mapstructure:"page" yaml:"page"`
+
+	// Announce finished runs to other systems
+	Notify NotifyConfig `mapstructure:"notify" yaml:"notify"`
+
+	// Copy written documents to object storage
+	Upload UploadConfig `mapstructure:"upload" yaml:"upload"`
+
+	// Commit written documents to a git repository
+	Git GitConfig `mapstructure:"git" yaml:"git"`
+
+	// Time zone and format of the timestamps shown in documents; the time
+	// zone also applies to the {date} and {time} file name placeholders
+	Timezone   string `mapstructure:"timezone" yaml:"timezone"`       // IANA name, UTC or Local (empty = as extracted)
+	DateFormat string `mapstructure:"date_format" yaml:"date_format"` // e.g. YYYY-MM-DD HH:mm, or a Go layout
+}
+
+// DomainRule assigns the tables and views matching any pattern to a
+// business domain; rules are tried in order
+type DomainRule struct {
+	Name  string   `mapstructure:"name" yaml:"name"`
+	Match []string `mapstructure:"match" yaml:"match"` // globs on NAME or OWNER.NAME, e.g. BILLING.*, *_INVOICE*
+}
+
+// FontConfig names the fonts of Word and HTML documents; empty fields use
+// the defaults of output.language
+type FontConfig struct {
+	Latin     string   `mapstructure:"latin" yaml:"latin"`           // Latin script text
+	EastAsian string   `mapstructure:"east_asian" yaml:"east_asian"` // Hangul, kana and CJK ideographs
+	Fallback  []string `mapstructure:"fallback" yaml:"fallback"`     // HTML font families tried next
+}
+
+// TenantsConfig collapses groups of structurally identical schemas, as
+// found in multi-tenant databases, into one documented schema and the list
+// of the schemas it stands for
+type TenantsConfig struct {
+	Collapse   bool   `mapstructure:"collapse" yaml:"collapse"`
+	MinSchemas int    `mapstructure:"min_schemas" yaml:"min_schemas"` // smallest group collapsed (0 = 2)
+	Match      string `mapstructure:"match" yaml:"match"`             // glob of the schema names considered, e.g. tenant_* (empty = all)
+}
+
+// PageConfig sets the paper size and orientation of the printable formats;
+// formats overrides them per export format, e.g. landscape Word documents
+// only
+type PageConfig struct {
+	Size        string                `mapstructure:"size" yaml:"size"`                 // A4 (default), Letter
+	Orientation string                `mapstructure:"orientation" yaml:"orientation"`   // portrait (default), landscape
+	Formats     map[string]PageConfig `mapstructure:"formats" yaml:"formats,omitempty"` // by format: docx, html, xlsx
+}
+
+// NotifyConfig announces runs that wrote documents
+type NotifyConfig struct {
+	Webhook  WebhookConfig `mapstructure:"webhook" yaml:"webhook"`
+	Slack    ChatConfig    `mapstructure:"slack" yaml:"slack"`
+	Teams    ChatConfig    `mapstructure:"teams" yaml:"teams"`
+	Email    EmailConfig   `mapstructure:"email" yaml:"email"`
+	LinkBase string        `mapstructure:"link_base" yaml:"link_base"` // URL the output directory is published at; chat messages link the documents there
+}
+
+// WebhookConfig posts the run summary (see -summary-json) as JSON after a
+// successful run
+type WebhookConfig struct {
+	URL     string            `mapstructure:"url" yaml:"url"`         // empty = no webhook
+	Headers map[string]string `mapstructure:"headers" yaml:"headers"` // e.g. Authorization: "Bearer ${TOKEN}"
+	Timeout int               `mapstructure:"timeout" yaml:"timeout"` // seconds (default 10)
+}
+
+// ChatConfig posts a summary message to a Slack or Microsoft Teams
+// incoming webhook after a successful run
+type ChatConfig struct {
+	WebhookURL string `mapstructure:"webhook_url" yaml:"webhook_url"` // empty = not notified
+	Timeout    int    `mapstructure:"timeout" yaml:"timeout"`         // seconds (default 10)
+}
+
+// EmailConfig mails the written documents to a distribution list after a
+// successful run
+type EmailConfig struct {
+	Host            string   `mapstructure:"host" yaml:"host"`                           // SMTP server (empty = no email)
+	Port            int      `mapstructure:"port" yaml:"port"`                           // default 587 (465 with tls: tls)
+	TLS             string   `mapstructure:"tls" yaml:"tls"`                             // starttls (default), tls, none
+	Username        string   `mapstructure:"username" yaml:"username"`                   // empty = no authentication
+	Password        string   `mapstructure:"password" yaml:"password"`                   // use ${SMTP_PASSWORD}
+	From            string   `mapstructure:"from" yaml:"from"`                           // sender address
+	To              []string `mapstructure:"to" yaml:"to"`                               // recipients
+	Subject         string   `mapstructure:"subject" yaml:"subject"`                     // with {db}, {type}, {profile}, {status}, {date}, {time}
+	Formats         []string `mapstructure:"formats" yaml:"formats"`                     // formats to attach, e.g. [xlsx, docx] (empty = all written)
+	MaxAttachmentMB int      `mapstructure:"max_attachment_mb" yaml:"max_attachment_mb"` // larger documents are named, not attached (default 20)
+	Timeout         int      `mapstructure:"timeout" yaml:"timeout"`                     // seconds (default 10)
+}
+
+// UploadConfig uploads every written document to an S3, GCS or Azure
+// Blob Storage bucket. Credentials come from the environment or the
+// identity of the host, never from this file.
+type UploadConfig struct {
+	Provider string `mapstructure:"provider" yaml:"provider"` // s3, gcs, azure (empty = no upload)
+	Bucket   string `mapstructure:"bucket" yaml:"bucket"`     // bucket, or Azure container
+	Prefix   string `mapstructure:"prefix" yaml:"prefix"`     // object name prefix, e.g. docs/prod
+	Region   string `mapstructure:"region" yaml:"region"`     // S3 (default AWS_REGION)
+	Account  string `mapstructure:"account" yaml:"account"`   // Azure storage account
+	Endpoint string `mapstructure:"endpoint" yaml:"endpoint"` // S3-compatible (MinIO), GCS or Azurite endpoint
+	Presign  int    `mapstructure:"presign" yaml:"presign"`   // seconds the printed URLs stay valid (0 = plain object URLs)
+}
+
+// GitConfig copies the written documents into a git working copy and
+// commits them (docs-as-code)
+type GitConfig struct {
+	Repo    string   `mapstructure:"repo" yaml:"repo"`       // working copy (empty = no commit)
+	Path    string   `mapstructure:"path" yaml:"path"`       // directory in the working copy (default its root)
+	Message string   `mapstructure:"message" yaml:"message"` // with {db}, {type}, {profile}, {status}, {date}, {time}
+	Formats []string `mapstructure:"formats" yaml:"formats"` // formats to commit, e.g. [html] (empty = all written)
+	Push    bool     `mapstructure:"push" yaml:"push"`
+	Remote  string   `mapstructure:"remote" yaml:"remote"` // default origin
+	Branch  string   `mapstructure:"branch" yaml:"branch"` // remote branch (default the current branch)
+	Author  string   `mapstructure:"author" yaml:"author"` // "Name <email>" (default the git configuration)
+}
+
+// ExtractConfig controls what metadata to extract
+type ExtractConfig struct {
+	IncludeTables    bool `mapstructure:"include_tables" yaml:"include_tables"`
+	IncludeViews     bool `mapstructure:"include_views" yaml:"include_views"`
+	IncludeRoutines  bool `mapstructure:"include_routines" yaml:"include_routines"`
+	IncludeSequences bool `mapstructure:"include_sequences" yaml:"include_sequences"`
+	IncludeTriggers  bool `mapstructure:"include_triggers" yaml:"include_triggers"`
+	IncludeSynonyms  bool `mapstructure:"include_synonyms" yaml:"include_synonyms"`
+	IncludeIndexes   bool `mapstructure:"include_indexes" yaml:"include_indexes"`
+
+	// Filter options
+	SchemaFilter  []string `mapstructure:"schema_filter" yaml:"schema_filter"`   // Only extract these schemas/owners
+	TableFilter   []string `mapstructure:"table_filter" yaml:"table_filter"`     // Only extract these tables (globs on NAME or OWNER.NAME)
+	ExcludeSystem *bool    `mapstructure:"exclude_system" yaml:"exclude_system"` // Skip system objects (unset = true, see SkipSystem)
+
+	// Also keep the tables the table_filter tables reference or are
+	// referenced by through a foreign key
+	IncludeRelated bool `mapstructure:"include_related" yaml:"include_related"`
+
+	// Time limits in seconds (0 = none) of each catalog query and of the
+	// whole extraction; database.timeout bounds connecting
+	QueryTimeout int `mapstructure:"query_timeout" yaml:"query_timeout"`
+	Timeout      int `mapstructure:"timeout" yaml:"timeout"`
+
+	// Row count estimation
+	IncludeRowCounts bool `mapstructure:"include_row_counts" yaml:"include_row_counts"`
+	MaxRowCountTime  int  `mapstructure:"max_row_count_time" yaml:"max_row_count_time"` // Max seconds for counting
+
+	// Skip the applied migrations read from flyway_schema_history and
+	// DATABASECHANGELOG tables
+	ExcludeMigrations bool `mapstructure:"exclude_migrations" yaml:"exclude_migrations"`
+
+	// Cross-check the extracted metadata (orphan FK targets, index columns,
+	// sequence ranges) and add a data quality appendix to documents
+	Verify bool `mapstructure:"verify" yaml:"verify"`
+
+	// Example values (opt-in, reads table data)
+	Samples SampleConfig `mapstructure:"samples" yaml:"samples"`
+
+	// Reuse the columns and indexes of tables unchanged since a previous run
+	Cache CacheConfig `mapstructure:"cache" yaml:"cache"`
+}
+
+// LintConfig configures the schema lint rules (see internal/lint)
+type LintConfig struct {
+	TablePrefixes []string          `mapstructure:"table_prefixes" yaml:"table_prefixes"`   // allowed table name prefixes
+	ViewPrefixes  []string          `mapstructure:"view_prefixes" yaml:"view_prefixes"`     // allowed view name prefixes
+	NameCase      string            `mapstructure:"name_case" yaml:"name_case"`             // upper, lower (empty = any)
+	MaxNameLength int               `mapstructure:"max_name_length" yaml:"max_name_length"` // 0 = no limit
+	ReservedWords []string          `mapstructure:"reserved_words" yaml:"reserved_words"`   // extra reserved identifiers
+	Rules         map[string]string `mapstructure:"rules" yaml:"rules"`                     // rule ID -> error, warning, off
+	FailOn        string            `mapstructure:"fail_on" yaml:"fail_on"`                 // error (default), warning, none
+}
+
+// LogConfig controls logging behavior
+type LogConfig struct {
+	Level  string `mapstructure:"level" yaml:"level"`   // debug, info, warn, error
+	Format string `mapstructure:"format" yaml:"format"` // console (default), text, json
+	File   string `mapstructure:"file" yaml:"file"`     // log file path, appended (empty = stderr)
+}
+
+// Validate performs basic validation on the configuration
+func (c *Config) Validate() error {
+	if c.Database.Type == "" {
+		return ErrMissingDBType
+	}
+	if c.Output.Format == "" {
+		c.Output.Format = "xlsx" // default
+	}
+	if c.Output.OutputDir == "" {
+		c.Output.OutputDir = "./output" // default
+	}
+	if err := checkFileName(c.Output.FileName); err != nil {
+		return err
+	}
+	if err := checkSplit(c.Output.SplitBy); err != nil {
+		return err
+	}
+	if err := checkGroupBy(c.Output.GroupBy); err != nil {
+		return err
+	}
+	if err := checkDomains(c.Output.Domains); err != nil {
+		return err
+	}
+	if err := checkColumnWidths(c.Output.MinColumnWidth, c.Output.MaxColumnWidth); err != nil {
+		return err
+	}
+	if err := checkLineageDepth(c.Output.LineageDepth); err != nil {
+		return err
+	}
+	if err := checkTenants(c.Output.Tenants); err != nil {
+		return err
+	}
+	if err := checkTableFilter(c.Extract.TableFilter); err != nil {
+		return err
+	}
+	if err := checkTimeouts(c.Database, c.Extract); err != nil {
+		return err
+	}
+	if err := checkTimezone(c.Output.Timezone); err != nil {
+		return err
+	}
+	if err := checkPage(c.Output.Page); err != nil {
+		return err
+	}
+	if err := checkNotify(c.Output.Notify); err != nil {
+		return err
+	}
+	if err := checkUpload(c.Output.Upload); err != nil {
+		return err
+	}
+	if err := checkGit(c.Output.Git); err != nil {
+		return err
+	}
+	if err := checkCatalog(c.Catalog); err != nil {
+		return err
+	}
+	if err := checkUI(c.UI); err != nil {
+		return err
+	}
+	if err := checkSchemaDefault(c.Database.SchemaDefault); err != nil {
+		return err
+	}
+	if err := checkSamples(c.Extract.Samples); err != nil {
+		return err
+	}
+	return checkCache(c.Extract.Cache)
+}
+
+// Default returns a configuration with sensible defaults
+func Default() *Config {
+	excludeSystem := true
+	return &Config{
+		Database: DatabaseConfig{
+			Timeout: 30,
+			SSLMode: "disable",
+		},
+		Output: OutputConfig{
+			Format:      "xlsx",
+			OutputDir:   "./output",
+			FileName:    "schema_documentation",
+			IncludeTOC:  true,
+			IncludeERD:  false,
+			SplitByType: false,
+			Language:    "en",
+			ColorScheme: "default",
+		},
+		Extract: ExtractConfig{
+			IncludeTables:    true,
+			IncludeViews:     true,
+			IncludeRoutines:  true,
+			IncludeSequences: true,
+			IncludeTriggers:  true,
+			IncludeSynonyms:  true,
+			IncludeIndexes:   true,
+			ExcludeSystem:    &excludeSystem,
+			IncludeRowCounts: false,
+			MaxRowCountTime:  10,
+		},
+		Logging: LogConfig{
+			Level:  "info",
+			Format: "console",
+		},
+		UI: UIConfig{
+			Listen: DefaultListen,
+		},
+	}
+}
+
+// SkipSystem reports whether system and built-in objects are left out:
+// exclude_system, true when unset
+func (e ExtractConfig) SkipSystem() bool {
+	return e.ExcludeSystem == nil || *e.ExcludeSystem
+}
+
+// IsDbt reports whether the database section documents dbt artifacts
+// (type dbt, database = target directory) instead of connecting
+func (d DatabaseConfig) IsDbt() bool {
+	return strings.EqualFold(strings.TrimSpace(d.Type), "dbt")
+}
+
+// DefaultPort returns the standard listener port of a built-in database type,
+// or 0 for unknown types
+func DefaultPort(dbType string) int {
+	switch strings.ToLower(strings.TrimSpace(dbType)) {
+	case "oracle":
+		return 1521
+	case "mysql":
+		return 3306
+	case "postgresql", "postgres", "pg":
+		return 5432
+	case "mssql", "sqlserver":
+		return 1433
+	}
+	return 0
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix prefixes the environment variables that override database settings
+const EnvPrefix = "POCKETDOC_"
+
+// envVars maps POCKETDOC_DB_* variables to database settings
+var envVars = []struct {
+	name string
+	set  func(db *DatabaseConfig, value string) error
+}{
+	{"DB_TYPE", func(db *DatabaseConfig, v string) error { db.Type = v; return nil }},
+	{"DB_HOST", func(db *DatabaseConfig, v string) error { db.Host = v; return nil }},
+	{"DB_PORT", func(db *DatabaseConfig, v string) error {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidPort, v)
+		}
+		db.Port = port
+		return nil
+	}},
+	{"DB_NAME", func(db *DatabaseConfig, v string) error { db.Database = v; return nil }},
+	{"DB_USER", func(db *DatabaseConfig, v string) error { db.Username = v; return nil }},
+	{"DB_PASSWORD", func(db *DatabaseConfig, v string) error { db.Password = v; return nil }},
+	{"DB_SSLMODE", func(db *DatabaseConfig, v string) error { db.SSLMode = v; return nil }},
+	{"DB_SCHEMA", func(db *DatabaseConfig, v string) error {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				db.SchemaFilter = append(db.SchemaFilter, s)
+			}
+		}
+		return nil
+	}},
+}
+
+// ApplyEnv merges POCKETDOC_DSN and then the POCKETDOC_DB_* variables
+// (DB_TYPE, DB_HOST, DB_PORT, DB_NAME, DB_USER, DB_PASSWORD, DB_SSLMODE,
+// DB_SCHEMA) over the database settings; POCKETDOC_LOG_LEVEL and
+// POCKETDOC_LOG_FORMAT override the logging section and
+// POCKETDOC_UI_LISTEN the preview address
+func (c *Config) ApplyEnv() error {
+	if v := os.Getenv(EnvPrefix + "LOG_LEVEL"); v != "" {
+		c.Logging.Level = v
+	}
+	if v := os.Getenv(EnvPrefix + "LOG_FORMAT"); v != "" {
+		c.Logging.Format = v
+	}
+	if v := os.Getenv(EnvPrefix + "UI_LISTEN"); v != "" {
+		c.UI.Listen = v
+	}
+
+	if dsn := os.Getenv(EnvPrefix + "DSN"); dsn != "" {
+		db, err := ParseDSN(dsn)
+		if err != nil {
+			return fmt.Errorf("%sDSN: %w", EnvPrefix, err)
+		}
+		c.Database.Merge(db)
+	}
+
+	var db DatabaseConfig
+	for _, v := range envVars {
+		value, ok := os.LookupEnv(EnvPrefix + v.name)
+		if !ok || value == "" {
+			continue
+		}
+		if err := v.set(&db, value); err != nil {
+			return fmt.Errorf("%s%s: %w", EnvPrefix, v.name, err)
+		}
+	}
+	c.Database.Merge(db)
+	return nil
+}
+
+// envRef matches ${VAR} and ${VAR:-default}; $${...} escapes a literal ${...}
+var envRef = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// ExpandEnv replaces ${VAR} references in config text with environment
+// values. ${VAR:-default} falls back to default when VAR is unset or empty;
+// an unset VAR without default is an error, so a missing secret is not
+// silently replaced by an empty string.
+func ExpandEnv(text string) (string, error) {
+	var missing []string
+	out := envRef.ReplaceAllStringFunc(text, func(ref string) string {
+		if strings.HasPrefix(ref, "$$") {
+			return ref[1:]
+		}
+		m := envRef.FindStringSubmatch(ref)
+		if value := os.Getenv(m[1]); value != "" {
+			return value
+		}
+		if m[2] != "" {
+			return m[3]
+		}
+		if _, ok := os.LookupEnv(m[1]); !ok {
+			missing = append(missing, m[1])
+		}
+		return ""
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrMissingEnv, strings.Join(missing, ", "))
+	}
+	return out, nil
+}
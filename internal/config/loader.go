@@ -1,44 +1,93 @@
-package config
-
-import (
-	"fmt"
-	"os"
-
-	"gopkg.in/yaml.v3"
-)
-
-// LoadConfig loads configuration from a YAML file
-func LoadConfig(path string) (*Config, error) {
-	// Read file
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
-
-	// Parse YAML
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config YAML: %w", err)
-	}
-
-	// Apply defaults
-	if cfg.Database.Timeout == 0 {
-		cfg.Database.Timeout = 30
-	}
-	if cfg.Database.SSLMode == "" {
-		cfg.Database.SSLMode = "disable"
-	}
-	if cfg.Output.Language == "" {
-		cfg.Output.Language = "en"
-	}
-	if cfg.Output.ColorScheme == "" {
-		cfg.Output.ColorScheme = "default"
-	}
-
-	// Validate
-	if err := cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
-	}
-
-	return &cfg, nil
-}
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadConfig loads configuration from a YAML file
+func LoadConfig(path string) (*Config, error) {
+	return LoadProfile(path, "")
+}
+
+// LoadProfile loads configuration from a YAML file with the named profile
+// applied (see Config.UseProfile)
+func LoadProfile(path, profile string) (*Config, error) {
+	cfg, err := ReadProfile(path, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// ReadConfig loads a YAML file and applies defaults without validating, so
+// that command-line values can be merged before Validate
+func ReadConfig(path string) (*Config, error) {
+	return ReadProfile(path, "")
+}
+
+// ReadProfile is ReadConfig with the named profile applied before the
+// environment overrides
+func ReadProfile(path, profile string) (*Config, error) {
+	// Read file
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// Substitute ${ENV_VAR} references (secrets stay out of the file)
+	text, err := ExpandEnv(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand config %s: %w", path, err)
+	}
+
+	// Parse YAML
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(text), &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config YAML: %w", err)
+	}
+
+	// Connection profile (flag, POCKETDOC_PROFILE or the file's default)
+	if err := cfg.UseProfile(profile); err != nil {
+		return nil, err
+	}
+
+	// POCKETDOC_* variables override the file
+	if err := cfg.ApplyEnv(); err != nil {
+		return nil, err
+	}
+
+	cfg.applyDefaults()
+	return &cfg, nil
+}
+
+// applyDefaults fills unset connection, output and extract settings
+func (c *Config) applyDefaults() {
+	if c.Database.Timeout == 0 {
+		c.Database.Timeout = 30
+	}
+	if c.Database.SSLMode == "" {
+		c.Database.SSLMode = "disable"
+	}
+	if c.Database.Port == 0 {
+		c.Database.Port = DefaultPort(c.Database.Type)
+	}
+	if c.Output.Language == "" {
+		c.Output.Language = "en"
+	}
+	if c.Output.ColorScheme == "" {
+		c.Output.ColorScheme = "default"
+	}
+	if c.Extract.ExcludeSystem == nil {
+		excludeSystem := true
+		c.Extract.ExcludeSystem = &excludeSystem
+	}
+}
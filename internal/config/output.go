@@ -0,0 +1,431 @@
+package config
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Document split modes (output.split_by)
+const (
+	SplitType   = "type"   // one document per object type
+	SplitSchema = "schema" // one document per schema/owner
+	SplitDomain = "domain" // one document per business domain
+)
+
+// FileNameFields are the placeholders accepted in output.file_name
+var FileNameFields = []string{"db", "type", "profile", "part", "format", "lang", "date", "time"}
+
+// placeholderPattern matches {name} in output.file_name
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z_]+)\}`)
+
+// unsafeFileChars are replaced in placeholder values so that a database
+// name cannot introduce directories or invalid file names (see fileValue)
+var unsafeFileChars = strings.NewReplacer(
+	"/", "_", "\\", "_", ":", "_", "*", "_", "?", "_",
+	"\"", "_", "<", "_", ">", "_", "|", "_", " ", "_",
+)
+
+// FileNameVars returns the placeholder values for one document; part names
+// a split or separate document and {date} and {time} come from now
+func FileNameVars(db, dbType, profile, part, format, lang string, now time.Time) map[string]string {
+	return map[string]string{
+		"db":      db,
+		"type":    dbType,
+		"profile": profile,
+		"part":    part,
+		"format":  format,
+		"lang":    lang,
+		"date":    now.Format("2006-01-02"),
+		"time":    now.Format("150405"),
+	}
+}
+
+// ExpandFileName replaces {field} placeholders in name with vars; unknown
+// placeholders are left as they are (Check reports them)
+func ExpandFileName(name string, vars map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(name, func(m string) string {
+		v, ok := vars[strings.ToLower(m[1:len(m)-1])]
+		if !ok {
+			return m
+		}
+		return fileValue(v)
+	})
+}
+
+// fileValue makes a placeholder value safe in a file name: path separators,
+// control characters and characters Windows refuses become "_", and so
+// does a value of dots only, which could name the parent directory
+func fileValue(v string) string {
+	v = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return '_'
+		}
+		return r
+	}, unsafeFileChars.Replace(v))
+	if strings.Trim(v, ".") == "" {
+		return strings.Repeat("_", len(v))
+	}
+	return v
+}
+
+// OutputPath returns output_dir/file_name with placeholders expanded and
+// the extension ext added unless file_name already ends with it. An empty
+// file_name defaults to "schema".
+func (o OutputConfig) OutputPath(vars map[string]string, ext string) string {
+	name := o.FileName
+	if name == "" {
+		name = "schema"
+	}
+	name = ExpandFileName(name, vars)
+	if !strings.EqualFold(filepath.Ext(name), ext) {
+		name += ext
+	}
+	return filepath.Join(o.OutputDir, name)
+}
+
+// checkFileName reports placeholders in file_name that are not FileNameFields
+func checkFileName(name string) error {
+	for _, m := range placeholderPattern.FindAllStringSubmatch(name, -1) {
+		known := false
+		for _, f := range FileNameFields {
+			if strings.EqualFold(m[1], f) {
+				known = true
+			}
+		}
+		if !known {
+			return fmt.Errorf("output.file_name: unknown placeholder {%s} (use %s)", m[1], "{"+strings.Join(FileNameFields, "}, {")+"}")
+		}
+	}
+	return nil
+}
+
+// Split returns how documents are split: SplitType, SplitSchema,
+// SplitDomain or "" for a single document. split_by takes precedence over split_by_type.
+func (o OutputConfig) Split() string {
+	if o.SplitBy != "" {
+		return strings.ToLower(o.SplitBy)
+	}
+	if o.SplitByType {
+		return SplitType
+	}
+	return ""
+}
+
+// checkSplit reports an unknown split_by value
+func checkSplit(splitBy string) error {
+	switch strings.ToLower(splitBy) {
+	case "", SplitType, SplitSchema, SplitDomain:
+		return nil
+	}
+	return fmt.Errorf("output.split_by: unknown value %s (use %s, %s or %s)", splitBy, SplitType, SplitSchema, SplitDomain)
+}
+
+// output.group_by values organizing documents by schema or business domain
+const (
+	GroupSchema = "schema"
+	GroupDomain = "domain"
+)
+
+// checkGroupBy reports an unknown group_by value
+func checkGroupBy(groupBy string) error {
+	switch strings.ToLower(groupBy) {
+	case "", GroupSchema, GroupDomain:
+		return nil
+	}
+	return fmt.Errorf("output.group_by: unknown value %s (use %s or %s)", groupBy, GroupSchema, GroupDomain)
+}
+
+// checkDomains reports a domain rule without a name or patterns, a
+// duplicate name and an invalid pattern
+func checkDomains(rules []DomainRule) error {
+	seen := make(map[string]bool)
+	for i, r := range rules {
+		switch {
+		case r.Name == "":
+			return fmt.Errorf("output.domains[%d]: name is required", i)
+		case seen[strings.ToLower(r.Name)]:
+			return fmt.Errorf("output.domains: duplicate domain %s", r.Name)
+		case len(r.Match) == 0:
+			return fmt.Errorf("output.domains: %s needs at least one match pattern", r.Name)
+		}
+		seen[strings.ToLower(r.Name)] = true
+		for _, p := range r.Match {
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("output.domains: %s: invalid pattern %q", r.Name, p)
+			}
+		}
+	}
+	return nil
+}
+
+// checkTableFilter reports an invalid glob in extract.table_filter
+func checkTableFilter(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("extract.table_filter: invalid pattern %q", p)
+		}
+	}
+	return nil
+}
+
+// checkTimeouts reports a negative connect, query or extraction timeout
+func checkTimeouts(db DatabaseConfig, e ExtractConfig) error {
+	if db.Timeout < 0 || e.QueryTimeout < 0 || e.Timeout < 0 {
+		return fmt.Errorf("database.timeout, extract.query_timeout and extract.timeout must not be negative")
+	}
+	return nil
+}
+
+// checkColumnWidths validates the Excel column width bounds (0 = default)
+func checkColumnWidths(minWidth, maxWidth float64) error {
+	switch {
+	case minWidth < 0 || maxWidth < 0:
+		return fmt.Errorf("output.min_column_width and output.max_column_width must not be negative")
+	case maxWidth > 255:
+		return fmt.Errorf("output.max_column_width: %g is above Excel's limit of 255", maxWidth)
+	case maxWidth > 0 && minWidth > maxWidth:
+		return fmt.Errorf("output.min_column_width (%g) is above output.max_column_width (%g)", minWidth, maxWidth)
+	}
+	return nil
+}
+
+// maxLineageDepth bounds output.lineage_depth; deeper chains list most of
+// a connected schema under every table
+const maxLineageDepth = 10
+
+// checkLineageDepth validates the foreign key levels shown per table
+func checkLineageDepth(depth int) error {
+	if depth < 0 || depth > maxLineageDepth {
+		return fmt.Errorf("output.lineage_depth must be between 0 and %d, got %d", maxLineageDepth, depth)
+	}
+	return nil
+}
+
+// checkTenants validates the group size and schema pattern of tenant
+// collapsing
+func checkTenants(t TenantsConfig) error {
+	if t.MinSchemas < 0 || t.MinSchemas == 1 {
+		return fmt.Errorf("output.tenants.min_schemas must be 2 or more (0 = 2), got %d", t.MinSchemas)
+	}
+	if _, err := path.Match(t.Match, ""); err != nil {
+		return fmt.Errorf("output.tenants.match: invalid pattern %q", t.Match)
+	}
+	return nil
+}
+
+// Paper sizes and orientations (output.page)
+const (
+	PageA4        = "a4"
+	PageLetter    = "letter"
+	PagePortrait  = "portrait"
+	PageLandscape = "landscape"
+)
+
+// PageFormats are the export formats output.page.formats can override
+var PageFormats = []string{"docx", "html", "xlsx"}
+
+// checkPage reports an unknown paper size, orientation or format in
+// output.page
+func checkPage(p PageConfig) error {
+	if err := checkPageSetup("output.page", p); err != nil {
+		return err
+	}
+	for format, o := range p.Formats {
+		if !slices.Contains(PageFormats, strings.ToLower(format)) {
+			return fmt.Errorf("output.page.formats: %s has no pages (use %s)", format, strings.Join(PageFormats, ", "))
+		}
+		if err := checkPageSetup("output.page.formats."+format, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPageSetup reports an unknown size or orientation of the setup at key
+func checkPageSetup(key string, p PageConfig) error {
+	switch strings.ToLower(p.Size) {
+	case "", PageA4, PageLetter:
+	default:
+		return fmt.Errorf("%s.size: unknown value %s (use A4 or Letter)", key, p.Size)
+	}
+	switch strings.ToLower(p.Orientation) {
+	case "", PagePortrait, PageLandscape:
+	default:
+		return fmt.Errorf("%s.orientation: unknown value %s (use %s or %s)", key, p.Orientation, PagePortrait, PageLandscape)
+	}
+	return nil
+}
+
+// checkNotify reports a webhook or link URL that is not http(s) and a
+// negative timeout
+func checkNotify(n NotifyConfig) error {
+	urls := []struct{ key, value string }{
+		{"webhook.url", n.Webhook.URL},
+		{"slack.webhook_url", n.Slack.WebhookURL},
+		{"teams.webhook_url", n.Teams.WebhookURL},
+		{"link_base", n.LinkBase},
+	}
+	for _, u := range urls {
+		if u.value != "" && !httpURL(u.value) {
+			// The URL is not echoed: webhook URLs often carry a token
+			return fmt.Errorf("output.notify.%s is not an http(s) URL", u.key)
+		}
+	}
+	timeouts := []struct {
+		key   string
+		value int
+	}{
+		{"webhook", n.Webhook.Timeout},
+		{"slack", n.Slack.Timeout},
+		{"teams", n.Teams.Timeout},
+	}
+	for _, t := range timeouts {
+		if t.value < 0 {
+			return fmt.Errorf("output.notify.%s.timeout must not be negative", t.key)
+		}
+	}
+	return checkEmail(n.Email)
+}
+
+// EmailTLSModes are the values of output.notify.email.tls
+var EmailTLSModes = []string{"starttls", "tls", "none"}
+
+// RunFields are the placeholders accepted in output.notify.email.subject
+// and output.git.message
+var RunFields = []string{"db", "type", "profile", "status", "date", "time"}
+
+// checkEmail reports an email section without sender or recipients, with
+// an invalid address, TLS mode or subject placeholder, or with negative
+// limits
+func checkEmail(e EmailConfig) error {
+	if e.Host == "" {
+		return nil
+	}
+	if e.From == "" || len(e.To) == 0 {
+		return fmt.Errorf("output.notify.email needs from and to")
+	}
+	for _, addr := range append([]string{e.From}, e.To...) {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return fmt.Errorf("output.notify.email: invalid address %s", addr)
+		}
+	}
+	if e.TLS != "" && !slices.Contains(EmailTLSModes, strings.ToLower(e.TLS)) {
+		return fmt.Errorf("unknown output.notify.email.tls: %s (use %s)", e.TLS, strings.Join(EmailTLSModes, ", "))
+	}
+	if err := checkRunFields("output.notify.email.subject", e.Subject); err != nil {
+		return err
+	}
+	if e.Port < 0 || e.Port > 65535 {
+		return fmt.Errorf("output.notify.email: invalid port %d", e.Port)
+	}
+	if e.MaxAttachmentMB < 0 || e.Timeout < 0 {
+		return fmt.Errorf("output.notify.email: max_attachment_mb and timeout must not be negative")
+	}
+	return nil
+}
+
+// checkRunFields reports placeholders in the text of key that are not
+// RunFields
+func checkRunFields(key, text string) error {
+	for _, m := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		if !slices.Contains(RunFields, m[1]) {
+			return fmt.Errorf("%s: unknown placeholder {%s} (use %s)", key, m[1], "{"+strings.Join(RunFields, "}, {")+"}")
+		}
+	}
+	return nil
+}
+
+// UploadProviders are the values of output.upload.provider
+var UploadProviders = []string{"s3", "gcs", "azure"}
+
+// maxPresign is the longest validity of pre-signed URLs in seconds (7 days)
+const maxPresign = 7 * 24 * 60 * 60
+
+// checkUpload reports an unknown provider, a missing bucket or account and
+// a presign validity out of range
+func checkUpload(u UploadConfig) error {
+	if u.Provider == "" {
+		return nil
+	}
+	provider := strings.ToLower(u.Provider)
+	if !slices.Contains(UploadProviders, provider) {
+		return fmt.Errorf("unknown output.upload.provider: %s (use %s)", u.Provider, strings.Join(UploadProviders, ", "))
+	}
+	if u.Bucket == "" {
+		return fmt.Errorf("output.upload.bucket is required")
+	}
+	if provider == "azure" && u.Account == "" {
+		return fmt.Errorf("output.upload.account is required for azure")
+	}
+	if u.Endpoint != "" && !httpURL(u.Endpoint) {
+		return fmt.Errorf("output.upload.endpoint is not an http(s) URL: %s", u.Endpoint)
+	}
+	if u.Presign < 0 || u.Presign > maxPresign {
+		return fmt.Errorf("output.upload.presign must be between 0 and %d seconds", maxPresign)
+	}
+	return nil
+}
+
+// checkGit reports an unknown placeholder in the commit message, an
+// invalid author and a path leaving the working copy
+func checkGit(g GitConfig) error {
+	if g.Repo == "" {
+		return nil
+	}
+	if err := checkRunFields("output.git.message", g.Message); err != nil {
+		return err
+	}
+	if g.Author != "" {
+		if _, err := mail.ParseAddress(g.Author); err != nil {
+			return fmt.Errorf("output.git.author must look like \"Name <email>\": %s", g.Author)
+		}
+	}
+	if p := filepath.ToSlash(filepath.Clean(g.Path)); filepath.IsAbs(g.Path) || p == ".." || strings.HasPrefix(p, "../") {
+		return fmt.Errorf("output.git.path must be inside the repository: %s", g.Path)
+	}
+	return nil
+}
+
+// httpURL reports whether s is an absolute http(s) URL
+func httpURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// Merge replaces the channels that o configures, and the link base when
+// o sets one
+func (n *NotifyConfig) Merge(o NotifyConfig) {
+	if o.Webhook.URL != "" {
+		n.Webhook = o.Webhook
+	}
+	if o.Slack.WebhookURL != "" {
+		n.Slack = o.Slack
+	}
+	if o.Teams.WebhookURL != "" {
+		n.Teams = o.Teams
+	}
+	if o.Email.Host != "" {
+		n.Email = o.Email
+	}
+	if o.LinkBase != "" {
+		n.LinkBase = o.LinkBase
+	}
+}
+
+// checkTimezone reports a time zone the host cannot load
+func checkTimezone(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("output.timezone: unknown time zone %s (use an IANA name such as Asia/Seoul, UTC or Local)", name)
+	}
+	return nil
+}
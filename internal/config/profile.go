@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// UseProfile merges the named profile over the database and credentials
+// sections. An empty name falls back to POCKETDOC_PROFILE and then to the
+// file's profile setting; with none of them set nothing changes.
+func (c *Config) UseProfile(name string) error {
+	if name == "" {
+		name = os.Getenv(EnvPrefix + "PROFILE")
+	}
+	if name == "" {
+		name = c.Profile
+	}
+	if name == "" {
+		return nil
+	}
+
+	p, ok := c.Profiles[name]
+	if !ok {
+		return fmt.Errorf("%w: %s (defined: %s)", ErrUnknownProfile, name, strings.Join(c.ProfileNames(), ", "))
+	}
+
+	c.Database.Merge(p.Database)
+	if p.Credentials.Provider != "" {
+		c.Credentials = p.Credentials
+	}
+	c.Output.Notify.Merge(p.Notify)
+	c.Profile = name
+	return nil
+}
+
+// ProfileNames returns the defined profile names, sorted
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sample value defaults used when the samples settings are zero
+const (
+	DefaultSampleValues = 3
+	DefaultSampleLength = 20
+)
+
+// Sample modes
+const (
+	SampleMask     = "mask"     // keep the first two characters, star the rest
+	SampleTruncate = "truncate" // keep up to max_length characters
+)
+
+// DefaultDenyClassifications are never sampled when deny_classifications
+// is empty
+var DefaultDenyClassifications = []string{"RESTRICTED", "CONFIDENTIAL"}
+
+// SampleConfig enables example values in the documentation. Sampling reads
+// table data, so it is off unless enabled explicitly.
+type SampleConfig struct {
+	Enabled             bool     `mapstructure:"enabled" yaml:"enabled"`
+	MaxValues           int      `mapstructure:"max_values" yaml:"max_values"`                     // per column; 0 = 3
+	MaxLength           int      `mapstructure:"max_length" yaml:"max_length"`                     // characters per value; 0 = 20
+	Mode                string   `mapstructure:"mode" yaml:"mode"`                                 // mask (default), truncate
+	DenyClassifications []string `mapstructure:"deny_classifications" yaml:"deny_classifications"` // never sampled; empty = RESTRICTED, CONFIDENTIAL
+}
+
+// Values returns the number of values sampled per column
+func (s SampleConfig) Values() int {
+	if s.MaxValues <= 0 {
+		return DefaultSampleValues
+	}
+	return s.MaxValues
+}
+
+// Length returns the maximum characters kept per value
+func (s SampleConfig) Length() int {
+	if s.MaxLength <= 0 {
+		return DefaultSampleLength
+	}
+	return s.MaxLength
+}
+
+// Masked reports whether values are masked rather than only truncated
+func (s SampleConfig) Masked() bool {
+	return !strings.EqualFold(s.Mode, SampleTruncate)
+}
+
+// Denied reports whether columns with the classification must not be sampled
+func (s SampleConfig) Denied(classification string) bool {
+	deny := s.DenyClassifications
+	if len(deny) == 0 {
+		deny = DefaultDenyClassifications
+	}
+	for _, d := range deny {
+		if strings.EqualFold(d, classification) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSamples reports an unknown mode or negative limits
+func checkSamples(s SampleConfig) error {
+	switch strings.ToLower(s.Mode) {
+	case "", SampleMask, SampleTruncate:
+	default:
+		return fmt.Errorf("extract.samples.mode: unknown mode %q (use %s or %s)", s.Mode, SampleMask, SampleTruncate)
+	}
+	if s.MaxValues < 0 || s.MaxLength < 0 {
+		return fmt.Errorf("extract.samples: max_values and max_length must not be negative")
+	}
+	return nil
+}
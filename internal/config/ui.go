@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// DefaultListen is the preview server address when ui.listen is
+// empty: loopback only, so the preview is not exposed to the network by
+// accident
+const DefaultListen = "127.0.0.1:8080"
+
+// DefaultHSTSMaxAge is the Strict-Transport-Security max-age (one year)
+// sent by the preview server over TLS
+const DefaultHSTSMaxAge = 31536000
+
+// UIConfig configures the preview web server
+type UIConfig struct {
+	Listen     string `mapstructure:"listen" yaml:"listen"`             // host:port; 0.0.0.0:8080 for all interfaces
+	TLSCert    string `mapstructure:"tls_cert" yaml:"tls_cert"`         // PEM certificate (chain); serves HTTPS with tls_key
+	TLSKey     string `mapstructure:"tls_key" yaml:"tls_key"`           // PEM private key
+	HSTSMaxAge int    `mapstructure:"hsts_max_age" yaml:"hsts_max_age"` // seconds (TLS only); 0 = one year, -1 disables HSTS
+	Auth       UIAuth `mapstructure:"auth" yaml:"auth"`                 // credentials required by every endpoint
+	PageSize   int    `mapstructure:"page_size" yaml:"page_size"`       // table sections per preview page; 0 = 50, -1 = all on one page
+}
+
+// UIAuth protects every preview and export endpoint: username and password
+// enable HTTP basic auth, token enables bearer tokens; both may be set
+type UIAuth struct {
+	Username string `mapstructure:"username" yaml:"username"`
+	Password string `mapstructure:"password" yaml:"password"`
+	Token    string `mapstructure:"token" yaml:"token"`
+}
+
+// Enabled reports whether any credentials are configured
+func (a UIAuth) Enabled() bool {
+	return a.Username != "" || a.Token != ""
+}
+
+// TLS reports whether the preview is served over HTTPS
+func (u UIConfig) TLS() bool {
+	return u.TLSCert != "" && u.TLSKey != ""
+}
+
+// HSTS returns the Strict-Transport-Security max-age in seconds, or 0 when
+// HSTS is disabled
+func (u UIConfig) HSTS() int {
+	switch {
+	case u.HSTSMaxAge < 0:
+		return 0
+	case u.HSTSMaxAge == 0:
+		return DefaultHSTSMaxAge
+	}
+	return u.HSTSMaxAge
+}
+
+// Address returns the listen address; port, when not empty, replaces the
+// configured port (the -http-port flag)
+func (u UIConfig) Address(port string) string {
+	addr := u.Listen
+	if addr == "" {
+		addr = DefaultListen
+	}
+	if port == "" {
+		return addr
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = ""
+	}
+	return net.JoinHostPort(host, strings.TrimPrefix(port, ":"))
+}
+
+// checkUI reports an unparsable listen address, half a TLS key pair, an
+// invalid page size or basic auth without both user name and password
+func checkUI(u UIConfig) error {
+	if u.Listen != "" {
+		if _, _, err := net.SplitHostPort(u.Listen); err != nil {
+			return fmt.Errorf("ui.listen: %w", err)
+		}
+	}
+	if (u.TLSCert == "") != (u.TLSKey == "") {
+		return fmt.Errorf("ui: tls_cert and tls_key must be set together")
+	}
+	if u.PageSize < -1 {
+		return fmt.Errorf("ui.page_size: must be -1 (one page) or more, got %d", u.PageSize)
+	}
+	if (u.Auth.Username == "") != (u.Auth.Password == "") {
+		return fmt.Errorf("ui.auth: username and password must be set together")
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package coverage
+
+import (
+	"pocket-doc/internal/i18n"
+	"pocket-doc/internal/model"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Stats counts commented tables and columns
+type Stats struct {
+	Tables           int `json:"tables"`
+	CommentedTables  int `json:"commentedTables"`
+	Columns          int `json:"columns"`
+	CommentedColumns int `json:"commentedColumns"`
+}
+
+// TablePercent returns the share of tables with a comment (100 when there are none)
+func (s Stats) TablePercent() float64 {
+	return percent(s.CommentedTables, s.Tables)
+}
+
+// ColumnPercent returns the share of columns with a comment (100 when there are none)
+func (s Stats) ColumnPercent() float64 {
+	return percent(s.CommentedColumns, s.Columns)
+}
+
+// SchemaStats is the coverage of one schema/owner
+type SchemaStats struct {
+	Owner string `json:"owner"`
+	Stats
+}
+
+// Missing is a table (Column empty) or column without a comment
+type Missing struct {
+	Owner  string `json:"owner,omitempty"`
+	Table  string `json:"table"`
+	Column string `json:"column,omitempty"`
+}
+
+// Report is the comment coverage of a schema
+type Report struct {
+	Total   Stats         `json:"total"`
+	Schemas []SchemaStats `json:"schemas"`
+	Missing []Missing     `json:"missing,omitempty"`
+}
+
+// Compute builds the coverage report for the tables of schema. Schemas are
+// listed in the order their first table appears.
+func Compute(schema *model.Schema) *Report {
+	r := &Report{}
+	index := make(map[string]int)
+
+	for _, table := range schema.Tables {
+		i, ok := index[table.Owner]
+		if !ok {
+			i = len(r.Schemas)
+			index[table.Owner] = i
+			r.Schemas = append(r.Schemas, SchemaStats{Owner: table.Owner})
+		}
+		s := &r.Schemas[i].Stats
+
+		s.Tables++
+		if hasComment(table.Comment) {
+			s.CommentedTables++
+		} else {
+			r.Missing = append(r.Missing, Missing{Owner: table.Owner, Table: table.Name})
+		}
+
+		for _, col := range table.Columns {
+			s.Columns++
+			if hasComment(col.Comment) {
+				s.CommentedColumns++
+			} else {
+				r.Missing = append(r.Missing, Missing{Owner: table.Owner, Table: table.Name, Column: col.Name})
+			}
+		}
+	}
+
+	for _, s := range r.Schemas {
+		r.Total.Tables += s.Tables
+		r.Total.CommentedTables += s.CommentedTables
+		r.Total.Columns += s.Columns
+		r.Total.CommentedColumns += s.CommentedColumns
+	}
+	return r
+}
+
+// WriteText writes the report as plain text (CLI output and "coverage" format)
+func WriteText(w io.Writer, r *Report, msg *i18n.Bundle) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s\n\n", msg.T("section.coverage"))
+	fmt.Fprintf(&b, "%-24s %18s %18s\n", msg.T("label.schema"), msg.T("section.tables"), msg.T("label.columns"))
+	for _, s := range r.Schemas {
+		writeStatsLine(&b, schemaName(s.Owner, msg), s.Stats)
+	}
+	writeStatsLine(&b, msg.T("label.total"), r.Total)
+
+	if len(r.Missing) > 0 {
+		fmt.Fprintf(&b, "\n%s (%d)\n", msg.T("section.missing_comments"), len(r.Missing))
+		for _, m := range r.Missing {
+			fmt.Fprintf(&b, "  - %s\n", m.Path())
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Path returns the dotted object name ("owner.table.column")
+func (m Missing) Path() string {
+	parts := make([]string, 0, 3)
+	for _, p := range []string{m.Owner, m.Table, m.Column} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// writeStatsLine writes "name  commented/total (pct%)" for tables and columns
+func writeStatsLine(b *strings.Builder, name string, s Stats) {
+	fmt.Fprintf(b, "%-24s %18s %18s\n", name,
+		fmt.Sprintf("%d/%d (%.1f%%)", s.CommentedTables, s.Tables, s.TablePercent()),
+		fmt.Sprintf("%d/%d (%.1f%%)", s.CommentedColumns, s.Columns, s.ColumnPercent()))
+}
+
+// schemaName returns owner, or the default-schema label when empty
+func schemaName(owner string, msg *i18n.Bundle) string {
+	if owner == "" {
+		return msg.T("ui.default_schema")
+	}
+	return owner
+}
+
+// hasComment reports whether a comment has non-blank text
+func hasComment(comment string) bool {
+	return strings.TrimSpace(comment) != ""
+}
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(n) * 100 / float64(total)
+}
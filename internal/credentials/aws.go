@@ -0,0 +1,152 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// awsSecrets reads a secret from AWS Secrets Manager with a SigV4-signed
+// GetSecretValue call. Credentials come from the standard environment
+// variables (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN).
+type awsSecrets struct {
+	cfg          Config
+	region       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	endpoint     string
+}
+
+func newAWS(cfg Config) (Provider, error) {
+	a := &awsSecrets{
+		cfg:          cfg,
+		region:       cfg.Region,
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		endpoint:     cfg.Address,
+	}
+	if a.region == "" {
+		a.region = os.Getenv("AWS_REGION")
+	}
+	if a.region == "" {
+		a.region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if a.region == "" {
+		return nil, errors.New("region or AWS_REGION is required")
+	}
+	if a.accessKey == "" || a.secretKey == "" {
+		return nil, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+	if a.endpoint == "" {
+		a.endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", a.region)
+	}
+	return a, nil
+}
+
+func (a *awsSecrets) Fetch(ctx context.Context) (*Credentials, error) {
+	body, err := json.Marshal(map[string]string{"SecretId": a.cfg.Secret})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(a.endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	a.sign(req, body, time.Now().UTC())
+
+	var resp struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := doJSON(req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.SecretString == "" {
+		return nil, fmt.Errorf("secret %s has no SecretString (binary secrets are not supported)", a.cfg.Secret)
+	}
+	return fromString(a.cfg, resp.SecretString)
+}
+
+// sign adds the AWS Signature Version 4 headers for the secretsmanager service
+func (a *awsSecrets) sign(req *http.Request, body []byte, now time.Time) {
+	const service = "secretsmanager"
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if a.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.sessionToken)
+	}
+
+	headers := map[string]string{
+		"content-type": req.Header.Get("Content-Type"),
+		"host":         req.URL.Host,
+		"x-amz-date":   amzDate,
+		"x-amz-target": req.Header.Get("X-Amz-Target"),
+	}
+	if a.sessionToken != "" {
+		headers["x-amz-security-token"] = a.sessionToken
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		"/",
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		hashHex(body),
+	}, "\n")
+
+	scope := strings.Join([]string{date, a.region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+a.secretKey), date)
+	key = hmacSHA256(key, a.region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		a.accessKey, scope, signedHeaders, signature))
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
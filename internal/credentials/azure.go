@@ -0,0 +1,106 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// azureKeyVault reads a secret from Azure Key Vault. A service principal is
+// used when AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_CLIENT_SECRET are set,
+// otherwise the managed identity of the host.
+type azureKeyVault struct {
+	cfg          Config
+	vaultURL     string
+	tenantID     string
+	clientID     string
+	clientSecret string
+}
+
+const keyVaultScope = "https://vault.azure.net"
+
+func newAzure(cfg Config) (Provider, error) {
+	z := &azureKeyVault{
+		cfg:          cfg,
+		vaultURL:     cfg.Address,
+		tenantID:     os.Getenv("AZURE_TENANT_ID"),
+		clientID:     os.Getenv("AZURE_CLIENT_ID"),
+		clientSecret: os.Getenv("AZURE_CLIENT_SECRET"),
+	}
+	if z.vaultURL == "" {
+		return nil, errors.New("address (https://<vault>.vault.azure.net) is required")
+	}
+	return z, nil
+}
+
+func (z *azureKeyVault) Fetch(ctx context.Context) (*Credentials, error) {
+	token, err := z.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secretURL := strings.TrimRight(z.vaultURL, "/") + "/secrets/" + url.PathEscape(z.cfg.Secret) + "?api-version=7.4"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, secretURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var resp struct {
+		Value string `json:"value"`
+	}
+	if err := doJSON(req, &resp); err != nil {
+		return nil, err
+	}
+	return fromString(z.cfg, resp.Value)
+}
+
+// token obtains an access token for Key Vault
+func (z *azureKeyVault) token(ctx context.Context) (string, error) {
+	var req *http.Request
+	var err error
+
+	if z.clientSecret != "" {
+		if z.tenantID == "" || z.clientID == "" {
+			return "", errors.New("AZURE_TENANT_ID and AZURE_CLIENT_ID must be set with AZURE_CLIENT_SECRET")
+		}
+		form := url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {z.clientID},
+			"client_secret": {z.clientSecret},
+			"scope":         {keyVaultScope + "/.default"},
+		}
+		tokenURL := "https://login.microsoftonline.com/" + url.PathEscape(z.tenantID) + "/oauth2/v2.0/token"
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		// Managed identity via the instance metadata service
+		query := url.Values{"api-version": {"2018-02-01"}, "resource": {keyVaultScope}}
+		if z.clientID != "" {
+			query.Set("client_id", z.clientID)
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet,
+			"http://169.254.169.254/metadata/identity/oauth2/token?"+query.Encode(), nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Metadata", "true")
+	}
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doJSON(req, &resp); err != nil {
+		return "", err
+	}
+	if resp.AccessToken == "" {
+		return "", errors.New("empty access token")
+	}
+	return resp.AccessToken, nil
+}
@@ -0,0 +1,156 @@
+// Package credentials fetches database credentials from secret stores
+// (HashiCorp Vault, AWS Secrets Manager, Azure Key Vault) so they never have
+// to be written to the configuration file.
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Config selects a provider and the secret to read
+type Config struct {
+	Provider    string // vault, aws, azure
+	Address     string // Vault address or Key Vault URL
+	Secret      string // Vault path, AWS secret ID or Key Vault secret name
+	Region      string // AWS region
+	UsernameKey string // key of the user name in a JSON secret (default "username")
+	PasswordKey string // key of the password in a JSON secret (default "password")
+}
+
+// Credentials are the values read from the secret store. Username is empty
+// when the secret holds only a password.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// Provider reads credentials from a secret store
+type Provider interface {
+	Fetch(ctx context.Context) (*Credentials, error)
+}
+
+// Factory creates a provider from the configuration
+type Factory func(cfg Config) (Provider, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+func init() {
+	Register("vault", newVault)
+	Register("aws", newAWS)
+	Register("azure", newAzure)
+}
+
+// Register makes a credentials provider selectable by name. Like
+// database/sql.Register, it panics if factory is nil or the name is taken.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if factory == nil {
+		panic("credentials: Register factory is nil for " + name)
+	}
+	name = strings.ToLower(name)
+	if _, dup := registry[name]; dup {
+		panic("credentials: Register called twice for provider " + name)
+	}
+	registry[name] = factory
+}
+
+// Providers returns the registered provider names, sorted
+func Providers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Fetch reads the credentials with the configured provider
+func Fetch(ctx context.Context, cfg Config) (*Credentials, error) {
+	registryMu.RLock()
+	factory, ok := registry[strings.ToLower(cfg.Provider)]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported credentials provider: %s (supported: %s)",
+			cfg.Provider, strings.Join(Providers(), ", "))
+	}
+	if cfg.Secret == "" {
+		return nil, fmt.Errorf("credentials provider %s: secret is required", cfg.Provider)
+	}
+
+	p, err := factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("credentials provider %s: %w", cfg.Provider, err)
+	}
+	creds, err := p.Fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("credentials provider %s: %w", cfg.Provider, err)
+	}
+	return creds, nil
+}
+
+// fromValues picks the user name and password out of a key/value secret
+func fromValues(cfg Config, values map[string]interface{}) (*Credentials, error) {
+	userKey, passKey := cfg.UsernameKey, cfg.PasswordKey
+	if userKey == "" {
+		userKey = "username"
+	}
+	if passKey == "" {
+		passKey = "password"
+	}
+
+	password, ok := values[passKey].(string)
+	if !ok {
+		return nil, fmt.Errorf("secret %s has no string key %q", cfg.Secret, passKey)
+	}
+	username, _ := values[userKey].(string)
+	return &Credentials{Username: username, Password: password}, nil
+}
+
+// fromString reads a secret value that is either a JSON object with
+// user name and password keys or the password itself
+func fromString(cfg Config, value string) (*Credentials, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), "{") {
+		var values map[string]interface{}
+		if err := json.Unmarshal([]byte(value), &values); err == nil {
+			return fromValues(cfg, values)
+		}
+	}
+	return &Credentials{Password: value}, nil
+}
+
+// doJSON sends req and decodes a JSON response into out
+func doJSON(req *http.Request, out interface{}) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Redacted(), resp.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,338 @@
+package credentials
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// redirect sends every request of the default client to srv, so providers
+// with fixed endpoints (Azure token endpoints) can be served by a test server
+func redirect(t *testing.T, srv *httptest.Server) {
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.Header.Set("X-Original-Host", req.URL.Host)
+		req.URL.Scheme = target.Scheme
+		req.URL.Host = target.Host
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	t.Cleanup(func() { http.DefaultClient.Transport = transport })
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestFromValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		values  map[string]interface{}
+		want    Credentials
+		wantErr string
+	}{
+		{"default keys", Config{}, map[string]interface{}{"username": "app", "password": "s3cret"}, Credentials{"app", "s3cret"}, ""},
+		{"password only", Config{}, map[string]interface{}{"password": "s3cret"}, Credentials{"", "s3cret"}, ""},
+		{"custom keys", Config{UsernameKey: "user", PasswordKey: "pass"}, map[string]interface{}{"user": "app", "pass": "s3cret", "password": "other"}, Credentials{"app", "s3cret"}, ""},
+		{"custom key missing", Config{Secret: "db/app", PasswordKey: "pass"}, map[string]interface{}{"password": "s3cret"}, Credentials{}, `secret db/app has no string key "pass"`},
+		{"non-string password", Config{Secret: "db/app"}, map[string]interface{}{"password": 1234.0}, Credentials{}, `secret db/app has no string key "password"`},
+		{"non-string user name ignored", Config{}, map[string]interface{}{"username": true, "password": "s3cret"}, Credentials{"", "s3cret"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fromValues(tt.cfg, tt.values)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Errorf("credentials = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromString(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Credentials
+	}{
+		{`{"username": "app", "password": "s3cret"}`, Credentials{"app", "s3cret"}},
+		{` {"password": "s3cret"}`, Credentials{"", "s3cret"}},
+		{"s3cret", Credentials{"", "s3cret"}},
+		{"{not json", Credentials{"", "{not json"}},
+	}
+	for _, tt := range tests {
+		got, err := fromString(Config{}, tt.value)
+		if err != nil {
+			t.Fatalf("fromString(%q): %v", tt.value, err)
+		}
+		if *got != tt.want {
+			t.Errorf("fromString(%q) = %+v, want %+v", tt.value, *got, tt.want)
+		}
+	}
+}
+
+func TestVault(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		status  int
+		body    string
+		want    Credentials
+		wantErr string
+	}{
+		{"kv v1", "secret/pocket-doc", http.StatusOK,
+			`{"data": {"username": "app", "password": "s3cret"}}`, Credentials{"app", "s3cret"}, ""},
+		{"kv v2", "secret/data/pocket-doc", http.StatusOK,
+			`{"data": {"data": {"username": "app", "password": "s3cret"}, "metadata": {"version": 3}}}`, Credentials{"app", "s3cret"}, ""},
+		{"kv v1 with a data key", "secret/pocket-doc", http.StatusOK,
+			`{"data": {"data": {"username": "other"}, "password": "s3cret"}}`, Credentials{"", "s3cret"}, ""},
+		{"forbidden", "secret/pocket-doc", http.StatusForbidden,
+			`{"errors": ["permission denied"]}`, Credentials{}, `403 Forbidden: {"errors": ["permission denied"]}`},
+		{"not found", "secret/missing", http.StatusNotFound,
+			`{"errors": []}`, Credentials{}, "404 Not Found"},
+		{"malformed", "secret/pocket-doc", http.StatusOK,
+			`<html>`, Credentials{}, "failed to parse response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/"+tt.secret {
+					t.Errorf("path = %s, want /v1/%s", r.URL.Path, tt.secret)
+				}
+				if got := r.Header.Get("X-Vault-Token"); got != "t0ken" {
+					t.Errorf("X-Vault-Token = %q", got)
+				}
+				if got := r.Header.Get("X-Vault-Namespace"); got != "team" {
+					t.Errorf("X-Vault-Namespace = %q", got)
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+			t.Setenv("VAULT_ADDR", srv.URL+"/")
+			t.Setenv("VAULT_TOKEN", "t0ken")
+			t.Setenv("VAULT_NAMESPACE", "team")
+
+			got, err := Fetch(context.Background(), Config{Provider: "vault", Secret: "/" + tt.secret})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Errorf("credentials = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVaultEnvironment(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		addr    string
+		token   string
+		wantErr string
+	}{
+		{"address missing", Config{Secret: "secret/pocket-doc"}, "", "t0ken", "credentials provider vault: address or VAULT_ADDR is required"},
+		{"token missing", Config{Secret: "secret/pocket-doc"}, "http://127.0.0.1:8200", "", "credentials provider vault: VAULT_TOKEN is not set"},
+		{"token missing with configured address", Config{Secret: "secret/pocket-doc", Address: "http://127.0.0.1:8200"}, "", "", "credentials provider vault: VAULT_TOKEN is not set"},
+		{"secret missing", Config{}, "http://127.0.0.1:8200", "t0ken", "credentials provider vault: secret is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VAULT_ADDR", tt.addr)
+			t.Setenv("VAULT_TOKEN", tt.token)
+			cfg := tt.cfg
+			cfg.Provider = "vault"
+			_, err := Fetch(context.Background(), cfg)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAWS(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    Credentials
+		wantErr string
+	}{
+		{"json secret", http.StatusOK, `{"SecretString": "{\"user\": \"app\", \"pass\": \"s3cret\"}"}`, Credentials{"app", "s3cret"}, ""},
+		{"plain secret", http.StatusOK, `{"SecretString": "s3cret"}`, Credentials{"", "s3cret"}, ""},
+		{"binary secret", http.StatusOK, `{"SecretBinary": "czNjcmV0"}`, Credentials{}, "secret prod/app has no SecretString"},
+		{"access denied", http.StatusBadRequest, `{"__type": "AccessDeniedException"}`, Credentials{}, `400 Bad Request: {"__type": "AccessDeniedException"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/" {
+					t.Errorf("request = %s %s", r.Method, r.URL.Path)
+				}
+				if got := r.Header.Get("X-Amz-Target"); got != "secretsmanager.GetSecretValue" {
+					t.Errorf("X-Amz-Target = %q", got)
+				}
+				auth := r.Header.Get("Authorization")
+				if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") ||
+					!strings.Contains(auth, "/eu-west-1/secretsmanager/aws4_request") ||
+					!strings.Contains(auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target") {
+					t.Errorf("Authorization = %q", auth)
+				}
+				if got := r.Header.Get("X-Amz-Security-Token"); got != "session" {
+					t.Errorf("X-Amz-Security-Token = %q", got)
+				}
+				body, _ := io.ReadAll(r.Body)
+				if string(body) != `{"SecretId":"prod/app"}` {
+					t.Errorf("body = %s", body)
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+			t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+			t.Setenv("AWS_SECRET_ACCESS_KEY", "wJalrXUtnFEMI")
+			t.Setenv("AWS_SESSION_TOKEN", "session")
+			t.Setenv("AWS_REGION", "")
+			t.Setenv("AWS_DEFAULT_REGION", "eu-west-1")
+
+			got, err := Fetch(context.Background(), Config{
+				Provider: "aws", Address: srv.URL, Secret: "prod/app",
+				UsernameKey: "user", PasswordKey: "pass",
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Errorf("credentials = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAWSEnvironment(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "wJalrXUtnFEMI")
+	_, err := Fetch(context.Background(), Config{Provider: "aws", Secret: "prod/app"})
+	if err == nil || err.Error() != "credentials provider aws: region or AWS_REGION is required" {
+		t.Errorf("error without region = %v", err)
+	}
+
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	_, err = Fetch(context.Background(), Config{Provider: "aws", Secret: "prod/app", Region: "eu-west-1"})
+	if err == nil || err.Error() != "credentials provider aws: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set" {
+		t.Errorf("error without keys = %v", err)
+	}
+}
+
+func TestAzure(t *testing.T) {
+	tests := []struct {
+		name         string
+		tenant       string
+		client       string
+		clientSecret string
+		tokenStatus  int
+		secretStatus int
+		want         Credentials
+		wantErr      string
+	}{
+		{"service principal", "contoso", "app-id", "app-secret", http.StatusOK, http.StatusOK, Credentials{"app", "s3cret"}, ""},
+		{"managed identity", "", "", "", http.StatusOK, http.StatusOK, Credentials{"app", "s3cret"}, ""},
+		{"service principal without tenant", "", "app-id", "app-secret", http.StatusOK, http.StatusOK, Credentials{},
+			"AZURE_TENANT_ID and AZURE_CLIENT_ID must be set with AZURE_CLIENT_SECRET"},
+		{"token refused", "contoso", "app-id", "app-secret", http.StatusUnauthorized, http.StatusOK, Credentials{}, "401 Unauthorized"},
+		{"secret forbidden", "contoso", "app-id", "app-secret", http.StatusOK, http.StatusForbidden, Credentials{}, "403 Forbidden"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch host := r.Header.Get("X-Original-Host"); {
+				case host == "login.microsoftonline.com":
+					if r.URL.Path != "/"+tt.tenant+"/oauth2/v2.0/token" {
+						t.Errorf("token path = %s", r.URL.Path)
+					}
+					r.ParseForm()
+					if r.PostForm.Get("client_secret") != tt.clientSecret || r.PostForm.Get("scope") != keyVaultScope+"/.default" {
+						t.Errorf("token form = %v", r.PostForm)
+					}
+					w.WriteHeader(tt.tokenStatus)
+					io.WriteString(w, `{"access_token": "t0ken"}`)
+				case host == "169.254.169.254":
+					if r.Header.Get("Metadata") != "true" || r.URL.Query().Get("resource") != keyVaultScope {
+						t.Errorf("metadata request = %s %v", r.URL, r.Header)
+					}
+					w.WriteHeader(tt.tokenStatus)
+					io.WriteString(w, `{"access_token": "t0ken"}`)
+				case host == "vault.example.net":
+					if r.URL.Path != "/secrets/db app" || r.URL.Query().Get("api-version") != "7.4" {
+						t.Errorf("secret request = %s", r.URL)
+					}
+					if got := r.Header.Get("Authorization"); got != "Bearer t0ken" {
+						t.Errorf("Authorization = %q", got)
+					}
+					w.WriteHeader(tt.secretStatus)
+					io.WriteString(w, `{"value": "{\"username\": \"app\", \"password\": \"s3cret\"}"}`)
+				default:
+					t.Errorf("unexpected request to %s", host)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer srv.Close()
+			redirect(t, srv)
+			t.Setenv("AZURE_TENANT_ID", tt.tenant)
+			t.Setenv("AZURE_CLIENT_ID", tt.client)
+			t.Setenv("AZURE_CLIENT_SECRET", tt.clientSecret)
+
+			got, err := Fetch(context.Background(), Config{Provider: "azure", Address: "https://vault.example.net/", Secret: "db app"})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Errorf("credentials = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAzureEnvironment(t *testing.T) {
+	_, err := Fetch(context.Background(), Config{Provider: "azure", Secret: "db"})
+	if err == nil || err.Error() != "credentials provider azure: address (https://<vault>.vault.azure.net) is required" {
+		t.Errorf("error without address = %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// vault reads a KV (v1 or v2) secret over the Vault HTTP API. The token comes
+// from VAULT_TOKEN; the address from the config or VAULT_ADDR.
+type vault struct {
+	cfg       Config
+	address   string
+	token     string
+	namespace string
+}
+
+func newVault(cfg Config) (Provider, error) {
+	v := &vault{
+		cfg:       cfg,
+		address:   cfg.Address,
+		token:     os.Getenv("VAULT_TOKEN"),
+		namespace: os.Getenv("VAULT_NAMESPACE"),
+	}
+	if v.address == "" {
+		v.address = os.Getenv("VAULT_ADDR")
+	}
+	if v.address == "" {
+		return nil, errors.New("address or VAULT_ADDR is required")
+	}
+	if v.token == "" {
+		return nil, errors.New("VAULT_TOKEN is not set")
+	}
+	return v, nil
+}
+
+// Fetch reads <address>/v1/<secret>. For KV v2 the secret path includes the
+// "data/" segment, e.g. secret/data/pocket-doc.
+func (v *vault) Fetch(ctx context.Context) (*Credentials, error) {
+	url := strings.TrimRight(v.address, "/") + "/v1/" + strings.TrimLeft(v.cfg.Secret, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+
+	var resp struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := doJSON(req, &resp); err != nil {
+		return nil, err
+	}
+
+	// KV v2 nests the values under data.data next to data.metadata
+	values := resp.Data
+	if inner, ok := values["data"].(map[string]interface{}); ok {
+		if _, v2 := values["metadata"]; v2 {
+			values = inner
+		}
+	}
+	return fromValues(v.cfg, values)
+}
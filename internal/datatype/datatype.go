@@ -0,0 +1,262 @@
+// Package datatype maps the column types of the supported databases
+// (VARCHAR2(50), nvarchar(100), character varying, NUMBER(10), ...) to a
+// canonical type, so columns can be compared across databases and exported
+// to generic formats. The vendor type stays in model.Column.DataType.
+package datatype
+
+import (
+	"pocket-doc/internal/model"
+	"strconv"
+	"strings"
+)
+
+// Kind is the broad category of a canonical type
+type Kind string
+
+const (
+	KindString   Kind = "string"
+	KindInteger  Kind = "integer"
+	KindNumber   Kind = "number" // decimal and floating point
+	KindBoolean  Kind = "boolean"
+	KindTemporal Kind = "temporal"
+	KindBinary   Kind = "binary"
+	KindOther    Kind = "other" // json, xml, uuid, spatial, enums, user-defined types
+)
+
+// Type is a canonical column type
+type Type struct {
+	// Name is one of char, varchar, text, smallint, integer, bigint,
+	// decimal, real, double, boolean, date, time, timestamp, timestamptz,
+	// interval, binary, varbinary, blob, json, xml and uuid; other types
+	// keep their lower-cased vendor name
+	Name      string
+	Kind      Kind
+	Length    int  // characters of char/varchar, bytes of binary/varbinary; 0 if not declared
+	Precision int  // digits of decimal; 0 if not declared
+	Scale     int  // decimal digits after the point
+	Array     bool // PostgreSQL array of Name
+	Raw       string
+}
+
+// String renders the type as "varchar(50)", "decimal(10,2)" or "integer[]"
+func (t Type) String() string {
+	s := t.Name
+	switch {
+	case t.Name == "decimal" && t.Precision > 0 && t.Scale > 0:
+		s += "(" + strconv.Itoa(t.Precision) + "," + strconv.Itoa(t.Scale) + ")"
+	case t.Name == "decimal" && t.Precision > 0:
+		s += "(" + strconv.Itoa(t.Precision) + ")"
+	case t.Length > 0:
+		s += "(" + strconv.Itoa(t.Length) + ")"
+	}
+	if t.Array {
+		s += "[]"
+	}
+	return s
+}
+
+// Apply sets CanonicalType on every table and view column of schema, using
+// its DatabaseType; run it before databases are combined
+func Apply(schema *model.Schema) {
+	for i := range schema.Tables {
+		apply(schema.DatabaseType, schema.Tables[i].Columns)
+	}
+	for i := range schema.Views {
+		apply(schema.DatabaseType, schema.Views[i].Columns)
+	}
+}
+
+func apply(dbType string, columns []model.Column) {
+	for i := range columns {
+		if columns[i].DataType != "" {
+			columns[i].CanonicalType = Of(dbType, columns[i]).String()
+		}
+	}
+}
+
+// Of returns the canonical type of a column extracted from a database of
+// dbType ("Oracle", "postgresql", "mssql", ...). Lengths, precision and
+// scale written in the type ("character varying(100)") take precedence
+// over the column's fields.
+func Of(dbType string, c model.Column) Type {
+	return Parse(dbType, c.DataType, c.Length, c.Precision, c.Scale)
+}
+
+// Parse returns the canonical type of a vendor type name with the length,
+// precision and scale the catalog reported for it
+func Parse(dbType, raw string, length, precision, scale int) Type {
+	db := database(dbType)
+	base, args, array := split(raw)
+	if len(args) > 0 {
+		length, precision, scale = args[0], args[0], 0
+		if len(args) > 1 {
+			scale = args[1]
+		}
+	}
+	if db == mssql && (base == "nchar" || base == "nvarchar") && length > 0 {
+		length /= 2 // sys.columns.max_length is in bytes
+	}
+
+	t := Type{Raw: raw, Array: array}
+	switch base {
+	case "char", "character", "nchar", "bpchar", "national character":
+		t.Name, t.Kind, t.Length = "char", KindString, length
+	case "varchar", "varchar2", "nvarchar", "nvarchar2", "character varying", "national character varying":
+		t.Name, t.Kind, t.Length = "varchar", KindString, length
+		if length <= 0 { // unbounded (PostgreSQL) or MAX (SQL Server)
+			t.Name, t.Length = "text", 0
+		}
+	case "text", "ntext", "clob", "nclob", "long", "tinytext", "mediumtext", "longtext", "citext":
+		t.Name, t.Kind = "text", KindString
+
+	case "tinyint", "smallint", "int2", "smallserial":
+		t.Name, t.Kind = "smallint", KindInteger
+	case "int", "integer", "int4", "mediumint", "serial":
+		t.Name, t.Kind = "integer", KindInteger
+	case "bigint", "int8", "bigserial":
+		t.Name, t.Kind = "bigint", KindInteger
+	case "number":
+		t = number(t, precision, scale)
+	case "decimal", "numeric", "dec":
+		t.Name, t.Kind, t.Precision, t.Scale = "decimal", KindNumber, precision, scale
+	case "money":
+		t.Name, t.Kind, t.Precision, t.Scale = "decimal", KindNumber, 19, 4
+	case "smallmoney":
+		t.Name, t.Kind, t.Precision, t.Scale = "decimal", KindNumber, 10, 4
+	case "real", "float4", "binary_float":
+		t.Name, t.Kind = "real", KindNumber
+	case "double", "double precision", "float8", "binary_double":
+		t.Name, t.Kind = "double", KindNumber
+	case "float":
+		// MySQL FLOAT is single precision; elsewhere FLOAT(n) has n
+		// binary digits, up to 24 fit a real
+		t.Name, t.Kind = "double", KindNumber
+		if db == mysql || precision > 0 && precision <= 24 {
+			t.Name = "real"
+		}
+
+	case "boolean", "bool":
+		t.Name, t.Kind = "boolean", KindBoolean
+	case "bit":
+		// SQL Server BIT and BIT(1) are flags; longer MySQL and PostgreSQL BITs are bit strings
+		t.Name, t.Kind = "boolean", KindBoolean
+		if db != mssql && precision > 1 {
+			t.Name, t.Kind, t.Length = "binary", KindBinary, (precision+7)/8
+		}
+
+	case "date":
+		t.Name, t.Kind = "date", KindTemporal
+		if db == oracle { // Oracle DATE has a time of day
+			t.Name = "timestamp"
+		}
+	case "time", "time without time zone", "time with time zone", "timetz":
+		t.Name, t.Kind = "time", KindTemporal
+	case "datetime", "datetime2", "smalldatetime", "timestamp without time zone":
+		t.Name, t.Kind = "timestamp", KindTemporal
+	case "timestamp":
+		t.Name, t.Kind = "timestamp", KindTemporal
+		if db == mssql { // TIMESTAMP is a synonym of ROWVERSION
+			t.Name, t.Kind, t.Length = "binary", KindBinary, 8
+		}
+	case "rowversion":
+		t.Name, t.Kind, t.Length = "binary", KindBinary, 8
+	case "timestamp with time zone", "timestamptz", "datetimeoffset", "timestamp with local time zone":
+		t.Name, t.Kind = "timestamptz", KindTemporal
+
+	case "binary":
+		t.Name, t.Kind, t.Length = "binary", KindBinary, length
+	case "varbinary", "raw":
+		t.Name, t.Kind, t.Length = "varbinary", KindBinary, length
+		if length <= 0 {
+			t.Name, t.Length = "blob", 0
+		}
+	case "bytea", "blob", "tinyblob", "mediumblob", "longblob", "image", "long raw", "bfile":
+		t.Name, t.Kind = "blob", KindBinary
+
+	case "json", "jsonb":
+		t.Name, t.Kind = "json", KindOther
+	case "xml", "xmltype":
+		t.Name, t.Kind = "xml", KindOther
+	case "uuid", "uniqueidentifier":
+		t.Name, t.Kind = "uuid", KindOther
+
+	default:
+		t.Name, t.Kind = base, KindOther
+		if strings.HasPrefix(base, "interval") {
+			t.Name, t.Kind = "interval", KindTemporal
+		}
+	}
+	return t
+}
+
+// number maps Oracle NUMBER: integers by the digits they hold, decimals
+// with their precision and scale, NUMBER without precision as decimal
+func number(t Type, precision, scale int) Type {
+	t.Kind = KindInteger
+	switch {
+	case precision <= 0 || scale != 0 || precision > 18:
+		t.Name, t.Kind, t.Precision, t.Scale = "decimal", KindNumber, precision, scale
+	case precision <= 4:
+		t.Name = "smallint"
+	case precision <= 9:
+		t.Name = "integer"
+	default:
+		t.Name = "bigint"
+	}
+	return t
+}
+
+// Database types with vendor-specific mappings
+const (
+	oracle = "oracle"
+	mysql  = "mysql"
+	mssql  = "mssql"
+)
+
+// database returns the canonical database type name for dbType
+func database(dbType string) string {
+	switch db := strings.ToLower(strings.TrimSpace(dbType)); db {
+	case "sqlserver":
+		return mssql
+	default:
+		return db
+	}
+}
+
+// split lower-cases a vendor type and separates the parenthesized
+// arguments and the array suffix: "TIMESTAMP(6) WITH TIME ZONE" gives
+// "timestamp with time zone" and [6], "numeric(10,2)[]" gives "numeric",
+// [10 2] and true. Non-numeric arguments (MAX, enum values) are dropped.
+func split(raw string) (base string, args []int, array bool) {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	for strings.HasSuffix(s, "[]") {
+		s, array = strings.TrimSpace(strings.TrimSuffix(s, "[]")), true
+	}
+	if strings.HasPrefix(s, "_") { // PostgreSQL internal array names (_int4)
+		s, array = s[1:], true
+	}
+
+	var b strings.Builder
+	for {
+		open := strings.IndexByte(s, '(')
+		if open < 0 {
+			b.WriteString(s)
+			break
+		}
+		end := strings.IndexByte(s[open:], ')')
+		if end < 0 {
+			b.WriteString(s[:open])
+			break
+		}
+		b.WriteString(s[:open])
+		if args == nil {
+			for _, arg := range strings.Split(s[open+1:open+end], ",") {
+				if n, err := strconv.Atoi(strings.TrimSpace(arg)); err == nil {
+					args = append(args, n)
+				}
+			}
+		}
+		s = s[open+end+1:]
+	}
+	return strings.Join(strings.Fields(b.String()), " "), args, array
+}
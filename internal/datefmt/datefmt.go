@@ -0,0 +1,58 @@
+// Package datefmt formats the timestamps shown in documents (the
+// extraction time, ...) in a configured time zone and layout, so a
+// document generated on a CI runner in UTC shows the times and date
+// convention of the organization reading it.
+package datefmt
+
+import (
+	"strings"
+	"time"
+	_ "time/tzdata" // IANA zones on hosts without a zoneinfo database (Windows, scratch images)
+)
+
+// Config selects how timestamps are shown
+type Config struct {
+	Location *time.Location // time zone; nil = as extracted
+	Layout   string         // Go layout; empty = the default of the format
+}
+
+// In returns t in the configured time zone
+func (c Config) In(t time.Time) time.Time {
+	if c.Location == nil {
+		return t
+	}
+	return t.In(c.Location)
+}
+
+// Format returns t in the configured time zone and layout; def is the
+// layout of the document format, used when none is configured
+func (c Config) Format(t time.Time, def string) string {
+	layout := c.Layout
+	if layout == "" {
+		layout = def
+	}
+	return c.In(t).Format(layout)
+}
+
+// tokens maps the date format tokens to Go layout elements; YYYY is listed
+// before YY so the longer token wins
+var tokens = strings.NewReplacer(
+	"YYYY", "2006", "YY", "06", "MM", "01", "DD", "02",
+	"HH", "15", "mm", "04", "ss", "05",
+)
+
+// Layout converts a date format written with the tokens YYYY, YY, MM, DD,
+// HH, mm and ss ("YYYY/MM/DD HH:mm") to a Go layout. Go layouts
+// ("2006-01-02 15:04 MST") contain no tokens and are returned unchanged.
+func Layout(format string) string {
+	return tokens.Replace(format)
+}
+
+// LoadLocation returns the time zone with an IANA name ("Asia/Seoul"),
+// "UTC" or "Local"; an empty name returns nil (as extracted)
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, nil
+	}
+	return time.LoadLocation(name)
+}
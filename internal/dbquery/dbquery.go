@@ -0,0 +1,130 @@
+// Package dbquery runs the catalog queries of the extractors with a time
+// limit per query (extract.query_timeout) and turns their failures into
+// typed errors with remediation hints, and reports them to Hooks. The
+// limit covers executing the query and reading its rows, so the context of
+// a query lives until its rows are closed or its row is scanned.
+package dbquery
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Options apply to every query of an extractor
+type Options struct {
+	Timeout time.Duration // limit of each query (0 = none)
+
+	// Classify maps a driver error of query to a typed error
+	// (PermissionDeniedError, UnsupportedVersionError), or returns it as is
+	Classify func(query string, err error) error
+
+	Hooks Hooks // observe each query (nil = none)
+}
+
+// limit is the time limit of one query
+type limit struct {
+	parent context.Context // of the caller, e.g. bounded by extract.timeout
+	ctx    context.Context
+	cancel context.CancelFunc
+	query  string
+	opts   Options
+	start  time.Time
+}
+
+func newLimit(parent context.Context, query string, opts Options) limit {
+	l := limit{parent: parent, query: query, opts: opts, start: time.Now()}
+	if opts.Hooks != nil {
+		opts.Hooks.OnQueryStart(parent, query)
+	}
+	if opts.Timeout > 0 {
+		l.ctx, l.cancel = context.WithTimeout(parent, opts.Timeout)
+	} else {
+		l.ctx, l.cancel = context.WithCancel(parent)
+	}
+	return l
+}
+
+// wrap returns a TimeoutError when the query exceeded its time limit, as
+// opposed to the caller's context ending, and classifies other errors
+func (l limit) wrap(err error) error {
+	switch {
+	case err == nil || errors.Is(err, sql.ErrNoRows) || l.parent.Err() != nil:
+		return err
+	case errors.Is(l.ctx.Err(), context.DeadlineExceeded):
+		return &TimeoutError{Phase: "query", Limit: "extract.query_timeout", Timeout: l.opts.Timeout, Err: err}
+	case l.opts.Classify != nil:
+		return l.opts.Classify(l.query, err)
+	}
+	return err
+}
+
+// end releases the time limit and reports the end of the query with err
+func (l limit) end(err error) {
+	l.cancel()
+	if l.opts.Hooks != nil {
+		l.opts.Hooks.OnQueryEnd(l.parent, l.query, time.Since(l.start), err)
+	}
+}
+
+// Rows is *sql.Rows releasing the time limit of its query on Close
+type Rows struct {
+	*sql.Rows
+	limit  limit
+	closed bool
+}
+
+// Close closes the rows and releases the time limit
+func (r *Rows) Close() error {
+	err := r.Rows.Close()
+	if !r.closed {
+		r.closed = true
+		r.limit.end(r.Err())
+	}
+	return err
+}
+
+// Err returns the error of the iteration
+func (r *Rows) Err() error {
+	return r.limit.wrap(r.Rows.Err())
+}
+
+// Row is *sql.Row releasing the time limit of its query on Scan
+type Row struct {
+	*sql.Row
+	limit limit
+}
+
+// Scan copies the columns of the row and releases the time limit
+func (r *Row) Scan(dest ...any) error {
+	err := r.limit.wrap(r.Row.Scan(dest...))
+	r.limit.end(err)
+	return err
+}
+
+// Err returns the error of running the query
+func (r *Row) Err() error {
+	return r.limit.wrap(r.Row.Err())
+}
+
+// Query runs a query on db with opts. Its time limit runs until the rows
+// are closed, so callers read a list of objects and close its rows before
+// querying the details of each object; otherwise the limit would cover all
+// the nested queries.
+func Query(ctx context.Context, db *sql.DB, opts Options, query string, args ...any) (*Rows, error) {
+	l := newLimit(ctx, query, opts)
+	rows, err := db.QueryContext(l.ctx, query, args...)
+	if err != nil {
+		err = l.wrap(err)
+		l.end(err)
+		return nil, err
+	}
+	return &Rows{Rows: rows, limit: l}, nil
+}
+
+// QueryRow runs a single-row query on db with opts
+func QueryRow(ctx context.Context, db *sql.DB, opts Options, query string, args ...any) *Row {
+	l := newLimit(ctx, query, opts)
+	return &Row{Row: db.QueryRowContext(l.ctx, query, args...), limit: l}
+}
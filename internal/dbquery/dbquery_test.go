@@ -0,0 +1,236 @@
+package dbquery
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"pocket-doc/internal/model"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowDriver answers "SELECT 1" at once, fails queries of ALL_ARGUMENTS as
+// Oracle does for users without access and blocks any other query until
+// its context ends
+type slowDriver struct{}
+
+func init() {
+	sql.Register("pocketdoc-slow", slowDriver{})
+}
+
+func (slowDriver) Open(string) (driver.Conn, error) { return slowConn{}, nil }
+
+type slowConn struct{}
+
+func (slowConn) Prepare(string) (driver.Stmt, error) { return nil, fmt.Errorf("not supported") }
+func (slowConn) Close() error                        { return nil }
+func (slowConn) Begin() (driver.Tx, error)           { return nil, fmt.Errorf("not supported") }
+
+func (slowConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if strings.Contains(strings.ToUpper(query), "ALL_ARGUMENTS") {
+		return nil, fmt.Errorf("ORA-00942: table or view does not exist")
+	}
+	if query != "SELECT 1" {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &oneRow{}, nil
+}
+
+type oneRow struct{ done bool }
+
+func (r *oneRow) Columns() []string { return []string{"n"} }
+func (r *oneRow) Close() error      { return nil }
+func (r *oneRow) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+// TestQueryTimeout validates catalog queries are bounded by the query time limit only
+func TestQueryTimeout(t *testing.T) {
+	db, err := sql.Open("pocketdoc-slow", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	var n int
+	if err := QueryRow(ctx, db, Options{Timeout: time.Second}, "SELECT 1").Scan(&n); err != nil || n != 1 {
+		t.Fatalf("QueryRow() = %d, %v", n, err)
+	}
+	rows, err := Query(ctx, db, Options{Timeout: time.Second}, "SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+	}
+	if err := errors.Join(rows.Err(), rows.Close()); err != nil {
+		t.Errorf("rows: %v", err)
+	}
+
+	_, err = Query(ctx, db, Options{Timeout: 20 * time.Millisecond}, "SELECT slow")
+	var timeout *TimeoutError
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.As(err, &timeout) || !strings.Contains(err.Error(), "extract.query_timeout") {
+		t.Errorf("slow query error = %v", err)
+	}
+	if hint := Hint(err); !strings.Contains(hint, "raise extract.query_timeout") {
+		t.Errorf("slow query hint = %q", hint)
+	}
+	// The caller's deadline is reported as such
+	callerCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	err = QueryRow(callerCtx, db, Options{Timeout: time.Minute}, "SELECT slow").Scan(&n)
+	if !errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "extract.query_timeout") {
+		t.Errorf("caller deadline error = %v", err)
+	}
+}
+
+// TestQueryErrorHints validates driver errors are classified into typed errors with remediation hints
+func TestQueryErrorHints(t *testing.T) {
+	db, err := sql.Open("pocketdoc-slow", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	catalogs := []string{"ALL_TAB_COLUMNS", "ALL_ARGUMENTS"}
+	opts := Options{Classify: func(query string, err error) error {
+		if strings.Contains(err.Error(), "ORA-00942") {
+			return &PermissionDeniedError{DBType: "oracle", Catalog: CatalogOf(query, err, catalogs), User: "APP", Err: err}
+		}
+		return err
+	}}
+	_, err = Query(context.Background(), db, opts, "SELECT argument_name FROM sys.all_arguments WHERE owner = :1", "HR")
+	var denied *PermissionDeniedError
+	if !errors.As(err, &denied) || denied.Catalog != "ALL_ARGUMENTS" || !strings.Contains(err.Error(), "ORA-00942") {
+		t.Fatalf("denied error = %v", err)
+	}
+	if hint := Hint(err); hint != "GRANT SELECT ON ALL_ARGUMENTS TO APP (or GRANT SELECT_CATALOG_ROLE TO APP)" {
+		t.Errorf("denied hint = %q", hint)
+	}
+	// The hint survives wrapping by callers
+	if hint := Hint(fmt.Errorf("failed to get routines: %w", err)); hint == "" {
+		t.Error("hint lost by wrapping")
+	}
+
+	if hint := PrivilegeHint("mssql", "", "app"); hint != "GRANT VIEW DEFINITION TO app" {
+		t.Errorf("mssql hint = %q", hint)
+	}
+	if catalog := CatalogOf("SELECT 1 FROM all_tab_columns_ext", nil, catalogs); catalog != "" {
+		t.Errorf("CatalogOf() matched a longer name: %q", catalog)
+	}
+	// Drivers naming the object decide among the catalogs a query joins
+	for _, tt := range []struct {
+		catalogs []string
+		query    string
+		err      string
+		want     string
+	}{
+		{[]string{"INFORMATION_SCHEMA.TABLES", "INFORMATION_SCHEMA.ROUTINES"},
+			"SELECT r.ROUTINE_NAME FROM INFORMATION_SCHEMA.TABLES t JOIN INFORMATION_SCHEMA.ROUTINES r ON 1=1",
+			"Error 1142 (42000): SELECT command denied to user 'app'@'%' for table 'ROUTINES'", "INFORMATION_SCHEMA.ROUTINES"},
+		{[]string{"pg_catalog.pg_class", "pg_catalog.pg_authid"},
+			"SELECT c.relname FROM pg_class c JOIN pg_authid a ON a.oid = c.relowner",
+			"pq: permission denied for table pg_authid", "pg_catalog.pg_authid"},
+		{[]string{"sys.objects", "sys.sql_modules"},
+			"SELECT o.name FROM sys.objects o JOIN sys.sql_modules m ON m.object_id = o.object_id",
+			"mssql: The SELECT permission was denied on the object 'sql_modules', database 'mssqlsystemresource', schema 'sys'.", "sys.sql_modules"},
+		{[]string{"ALL_TAB_COLUMNS", "ALL_COL_COMMENTS"},
+			"SELECT c.COLUMN_NAME FROM ALL_TAB_COLUMNS c JOIN ALL_COL_COMMENTS cc ON 1=1",
+			"ORA-00942: table or view does not exist", "ALL_TAB_COLUMNS"},
+	} {
+		if got := CatalogOf(tt.query, fmt.Errorf("%s", tt.err), tt.catalogs); got != tt.want {
+			t.Errorf("CatalogOf(%q) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+	if hint := Hint(fmt.Errorf("plain")); hint != "" {
+		t.Errorf("plain error hint = %q", hint)
+	}
+	old := &UnsupportedVersionError{DBType: "postgresql", Err: fmt.Errorf("column does not exist")}
+	if hint := Hint(old); hint != "pocket-doc needs PostgreSQL 11 or later" {
+		t.Errorf("version hint = %q", hint)
+	}
+}
+
+// recordingHooks records the queries and objects reported to it
+type recordingHooks struct {
+	NopHooks
+	events []string
+}
+
+func (h *recordingHooks) OnQueryStart(_ context.Context, query string) {
+	h.events = append(h.events, "start "+query)
+}
+
+func (h *recordingHooks) OnQueryEnd(_ context.Context, query string, elapsed time.Duration, err error) {
+	h.events = append(h.events, fmt.Sprintf("end %s %v", query, err != nil))
+}
+
+// TestQueryHooks validates hooks see each query once, when its rows are done, and the extracted objects
+func TestQueryHooks(t *testing.T) {
+	db, err := sql.Open("pocketdoc-slow", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	hooks := &recordingHooks{}
+	opts := Options{Hooks: hooks}
+
+	rows, err := Query(ctx, db, opts, "SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks.events) != 1 {
+		t.Errorf("query ended before its rows were read: %v", hooks.events)
+	}
+	for rows.Next() {
+	}
+	rows.Close()
+	rows.Close()
+	var n int
+	if err := QueryRow(ctx, db, opts, "SELECT 1").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Query(ctx, db, opts, "SELECT * FROM ALL_ARGUMENTS"); err == nil {
+		t.Fatal("denied query succeeded")
+	}
+	want := []string{"start SELECT 1", "end SELECT 1 false", "start SELECT 1", "end SELECT 1 false",
+		"start SELECT * FROM ALL_ARGUMENTS", "end SELECT * FROM ALL_ARGUMENTS true"}
+	if !slices.Equal(hooks.events, want) {
+		t.Errorf("events = %q", hooks.events)
+	}
+
+	var objects []string
+	schema := &model.Schema{
+		Tables: []model.Table{{Owner: "HR", Name: "EMP", Indexes: []model.Index{{Owner: "HR", Name: "EMP_PK"}}}},
+		Views:  []model.View{{Owner: "HR", Name: "EMP_V"}},
+	}
+	ReportObjects(ctx, objectHooks(func(kind, owner, name string) {
+		objects = append(objects, kind+" "+model.QualifiedName(owner, name))
+	}), schema)
+	if want := []string{"table HR.EMP", "index HR.EMP_PK", "view HR.EMP_V"}; !slices.Equal(objects, want) {
+		t.Errorf("objects = %q", objects)
+	}
+	ReportObjects(ctx, nil, schema)
+}
+
+// objectHooks reports extracted objects to a function
+type objectHooks func(kind, owner, name string)
+
+func (objectHooks) OnQueryStart(context.Context, string)                     {}
+func (objectHooks) OnQueryEnd(context.Context, string, time.Duration, error) {}
+
+func (f objectHooks) OnObjectExtracted(_ context.Context, kind, owner, name string) {
+	f(kind, owner, name)
+}
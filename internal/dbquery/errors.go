@@ -0,0 +1,176 @@
+package dbquery
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PermissionDeniedError reports a catalog view the connected user may not
+// read
+type PermissionDeniedError struct {
+	DBType  string // canonical database type (oracle, postgresql, mysql, mssql)
+	Catalog string // as listed by the extractor's RequiredCatalogs, "" when unknown
+	User    string
+	Err     error
+}
+
+func (e *PermissionDeniedError) Error() string {
+	if e.Catalog == "" {
+		return fmt.Sprintf("permission denied reading the catalog: %v", e.Err)
+	}
+	return fmt.Sprintf("permission denied reading %s: %v", e.Catalog, e.Err)
+}
+
+func (e *PermissionDeniedError) Unwrap() error { return e.Err }
+
+// Hint tells how to grant the missing privilege
+func (e *PermissionDeniedError) Hint() string {
+	return PrivilegeHint(e.DBType, e.Catalog, e.User)
+}
+
+// UnsupportedVersionError reports a catalog view or column the database
+// server lacks, as older versions do
+type UnsupportedVersionError struct {
+	DBType  string
+	Catalog string // "" when unknown
+	Err     error
+}
+
+func (e *UnsupportedVersionError) Error() string {
+	if e.Catalog == "" {
+		return fmt.Sprintf("unsupported database version: %v", e.Err)
+	}
+	return fmt.Sprintf("unsupported database version: cannot read %s: %v", e.Catalog, e.Err)
+}
+
+func (e *UnsupportedVersionError) Unwrap() error { return e.Err }
+
+// Hint names the oldest supported server version
+func (e *UnsupportedVersionError) Hint() string {
+	if v, ok := minimumVersions[e.DBType]; ok {
+		return "pocket-doc needs " + v.name + " or later"
+	}
+	return ""
+}
+
+// TimeoutError reports a phase of the extraction that exceeded its
+// configured time limit
+type TimeoutError struct {
+	Phase   string // connecting, query, extraction
+	Limit   string // the setting, e.g. extract.query_timeout
+	Timeout time.Duration
+	Err     error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s exceeded %s (%s): %v", e.Phase, e.Limit, e.Timeout, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+// Hint suggests raising the limit or reading less
+func (e *TimeoutError) Hint() string {
+	if e.Phase == "connecting" {
+		return "check host, port, firewall rules and credentials, or raise " + e.Limit
+	}
+	return "raise " + e.Limit + " or narrow database.schema_filter (see extract -plan)"
+}
+
+// Hint returns the remediation hint of the first error in err's chain
+// that has one, or ""
+func Hint(err error) string {
+	var hinted interface{ Hint() string }
+	if errors.As(err, &hinted) {
+		return hinted.Hint()
+	}
+	return ""
+}
+
+// privilegeHints tell how to grant catalog access per database type; %[1]s
+// is the user, %[2]s the catalog
+var privilegeHints = map[string]string{
+	"oracle":     "GRANT SELECT_CATALOG_ROLE TO %[1]s (or SELECT on each view listed)",
+	"mysql":      "GRANT SELECT, SHOW VIEW ON <schema>.* TO %[1]s",
+	"postgresql": "GRANT CONNECT ON DATABASE <db> TO %[1]s; GRANT USAGE ON SCHEMA <schema> TO %[1]s",
+	"mssql":      "GRANT VIEW DEFINITION TO %[1]s",
+}
+
+// catalogHints replace privilegeHints when the catalog is known
+var catalogHints = map[string]string{
+	"oracle": "GRANT SELECT ON %[2]s TO %[1]s (or GRANT SELECT_CATALOG_ROLE TO %[1]s)",
+}
+
+// minimumVersions are the oldest server versions the extractors support,
+// by the major and minor version the servers report
+var minimumVersions = map[string]struct {
+	name         string
+	major, minor int
+}{
+	"oracle":     {"Oracle 11g", 11, 0},
+	"mysql":      {"MySQL 5.6", 5, 6},
+	"postgresql": {"PostgreSQL 11", 11, 0},
+	"mssql":      {"SQL Server 2012", 11, 0},
+}
+
+// versionNumber finds the first major.minor number of a version banner:
+// "Release 19.0.0.0.0", "PostgreSQL 14.5 on x86_64", "8.0.32", and the
+// product version after the year of "Microsoft SQL Server 2019 ... - 15.0.4261.1"
+var versionNumber = regexp.MustCompile(`(\d+)\.(\d+)`)
+
+// CheckVersion returns an UnsupportedVersionError when version, as
+// reported by a dbType server, is older than the extractors support.
+// Versions it cannot parse pass.
+func CheckVersion(dbType, version string) error {
+	min, ok := minimumVersions[dbType]
+	m := versionNumber.FindStringSubmatch(version)
+	if !ok || m == nil {
+		return nil
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	if major > min.major || major == min.major && minor >= min.minor {
+		return nil
+	}
+	return &UnsupportedVersionError{DBType: dbType, Err: fmt.Errorf("server version %s.%s is older than %s", m[1], m[2], min.name)}
+}
+
+// PrivilegeHint tells how to grant user read access to catalog (or to
+// the catalog views in general when catalog is "") on dbType
+func PrivilegeHint(dbType, catalog, user string) string {
+	hint, ok := catalogHints[dbType]
+	if !ok || catalog == "" {
+		if hint, ok = privilegeHints[dbType]; !ok {
+			return ""
+		}
+	}
+	return fmt.Sprintf(hint, user, catalog)
+}
+
+// CatalogOf returns which of catalogs a failed query could not read, or
+// "". Drivers that name the object in the error (MySQL, PostgreSQL, SQL
+// Server) decide it; otherwise (Oracle) it is the first of catalogs the
+// query reads, so a query joining several may name the wrong one. Names
+// match "pg_catalog.pg_class" on "pg_class" too.
+func CatalogOf(query string, err error, catalogs []string) string {
+	if err != nil {
+		if catalog := catalogIn(err.Error(), catalogs); catalog != "" {
+			return catalog
+		}
+	}
+	return catalogIn(query, catalogs)
+}
+
+// catalogIn returns the first of catalogs named in text, or ""
+func catalogIn(text string, catalogs []string) string {
+	for _, catalog := range catalogs {
+		name := catalog[strings.LastIndex(catalog, ".")+1:]
+		if regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(name) + `\b`).MatchString(text) {
+			return catalog
+		}
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package dbquery
+
+import (
+	"context"
+	"pocket-doc/internal/model"
+	"time"
+)
+
+// Hooks observe the catalog queries and extracted objects of an extractor,
+// e.g. to trace where an extraction spends its time or audit which catalog
+// queries ran. Multi-database runs extract concurrently, so hooks must be
+// safe for concurrent use. Embed NopHooks to implement only some methods.
+type Hooks interface {
+	// OnQueryStart is called before a catalog query runs
+	OnQueryStart(ctx context.Context, query string)
+
+	// OnQueryEnd is called with the time since the query started and its
+	// error once its rows are closed, so elapsed includes reading them. A
+	// single-row query (QueryRow) ends when its row is scanned; one whose
+	// row is never scanned is not reported.
+	OnQueryEnd(ctx context.Context, query string, elapsed time.Duration, err error)
+
+	// OnObjectExtracted is called for each object of the extracted schema
+	// once the whole schema has been extracted (not while its queries run),
+	// in schema order; kind is table, index, view, routine, sequence,
+	// trigger or synonym. A failed extraction reports no objects.
+	OnObjectExtracted(ctx context.Context, kind, owner, name string)
+}
+
+// NopHooks ignore everything
+type NopHooks struct{}
+
+func (NopHooks) OnQueryStart(context.Context, string)                      {}
+func (NopHooks) OnQueryEnd(context.Context, string, time.Duration, error)  {}
+func (NopHooks) OnObjectExtracted(context.Context, string, string, string) {}
+
+// ReportObjects passes the objects of schema to hooks.OnObjectExtracted
+// (nothing when hooks is nil); extractors call it at the end of
+// ExtractSchema
+func ReportObjects(ctx context.Context, hooks Hooks, schema *model.Schema) {
+	if hooks == nil {
+		return
+	}
+	for _, t := range schema.Tables {
+		hooks.OnObjectExtracted(ctx, "table", t.Owner, t.Name)
+		for _, idx := range t.Indexes {
+			hooks.OnObjectExtracted(ctx, "index", idx.Owner, idx.Name)
+		}
+	}
+	for _, v := range schema.Views {
+		hooks.OnObjectExtracted(ctx, "view", v.Owner, v.Name)
+	}
+	for _, r := range schema.Routines {
+		hooks.OnObjectExtracted(ctx, "routine", r.Owner, r.Name)
+	}
+	for _, s := range schema.Sequences {
+		hooks.OnObjectExtracted(ctx, "sequence", s.Owner, s.Name)
+	}
+	for _, t := range schema.Triggers {
+		hooks.OnObjectExtracted(ctx, "trigger", t.Owner, t.Name)
+	}
+	for _, s := range schema.Synonyms {
+		hooks.OnObjectExtracted(ctx, "synonym", s.Owner, s.Name)
+	}
+}
@@ -0,0 +1,258 @@
+// Package dbt reads the artifacts of a dbt project: manifest.json (models,
+// seeds, snapshots and sources with their descriptions, written by every
+// dbt command) and catalog.json (column types and database comments,
+// written by "dbt docs generate"). The artifacts either describe extracted
+// tables (Apply) or replace the database connection altogether (Schema and
+// the "dbt" database type).
+package dbt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"pocket-doc/internal/model"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Manifest is the part of manifest.json pocket-doc reads
+type Manifest struct {
+	Metadata struct {
+		DbtVersion  string    `json:"dbt_version"`
+		AdapterType string    `json:"adapter_type"`
+		ProjectName string    `json:"project_name"`
+		GeneratedAt time.Time `json:"generated_at"`
+	} `json:"metadata"`
+	Nodes   map[string]Node `json:"nodes"`
+	Sources map[string]Node `json:"sources"`
+}
+
+// Node is a model, seed, snapshot, source or test of the manifest
+type Node struct {
+	ResourceType string            `json:"resource_type"`
+	Name         string            `json:"name"`
+	Alias        string            `json:"alias"`      // relation name of models (default Name)
+	Identifier   string            `json:"identifier"` // relation name of sources (default Name)
+	Database     string            `json:"database"`
+	Schema       string            `json:"schema"`
+	Description  string            `json:"description"`
+	Columns      map[string]Column `json:"columns"`
+	Config       struct {
+		Materialized string `json:"materialized"`
+	} `json:"config"`
+	Meta map[string]any `json:"meta"`
+
+	// Generic tests: the test name, the tested node and column
+	TestMetadata struct {
+		Name string `json:"name"`
+	} `json:"test_metadata"`
+	AttachedNode string `json:"attached_node"`
+	ColumnName   string `json:"column_name"`
+}
+
+// Column is a documented column of a manifest node
+type Column struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	DataType    string `json:"data_type"`
+	Constraints []struct {
+		Type string `json:"type"` // not_null, unique, primary_key, foreign_key, check
+	} `json:"constraints"`
+}
+
+// Catalog is the part of catalog.json pocket-doc reads
+type Catalog struct {
+	Metadata struct {
+		GeneratedAt time.Time `json:"generated_at"`
+	} `json:"metadata"`
+	Nodes   map[string]CatalogTable `json:"nodes"`
+	Sources map[string]CatalogTable `json:"sources"`
+}
+
+// CatalogTable is a relation as the database describes it
+type CatalogTable struct {
+	Metadata struct {
+		Type     string `json:"type"` // BASE TABLE, VIEW, ...
+		Database string `json:"database"`
+		Schema   string `json:"schema"`
+		Name     string `json:"name"`
+		Comment  string `json:"comment"`
+	} `json:"metadata"`
+	Columns map[string]CatalogColumn `json:"columns"`
+}
+
+// CatalogColumn is a column as the database describes it
+type CatalogColumn struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Index   int    `json:"index"`
+	Comment string `json:"comment"`
+}
+
+// Artifacts are a manifest and, when generated, a catalog
+type Artifacts struct {
+	Manifest *Manifest
+	Catalog  *Catalog // nil without catalog.json
+}
+
+// LoadManifest reads a manifest.json
+func LoadManifest(path string) (*Manifest, error) {
+	var m Manifest
+	if err := readJSON(path, &m); err != nil {
+		return nil, fmt.Errorf("failed to read dbt manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// Load reads manifest.json and, when present, catalog.json from a dbt
+// target directory
+func Load(dir string) (*Artifacts, error) {
+	m, err := LoadManifest(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		return nil, err
+	}
+	a := &Artifacts{Manifest: m}
+	var c Catalog
+	switch err := readJSON(filepath.Join(dir, "catalog.json"), &c); {
+	case err == nil:
+		a.Catalog = &c
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("failed to read dbt catalog: %w", err)
+	}
+	return a, nil
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// relation is a documented relation of the manifest
+type relation struct {
+	id   string
+	node Node
+}
+
+// relations returns the models, seeds, snapshots and sources that exist in
+// the database (ephemeral models do not), ordered by schema and name
+func (m *Manifest) relations() []relation {
+	var out []relation
+	for id, n := range m.Nodes {
+		switch n.ResourceType {
+		case "model", "seed", "snapshot":
+			if n.Config.Materialized != "ephemeral" {
+				out = append(out, relation{id, n})
+			}
+		}
+	}
+	for id, n := range m.Sources {
+		out = append(out, relation{id, n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i].node, out[j].node
+		if a.Schema != b.Schema {
+			return a.Schema < b.Schema
+		}
+		return a.RelationName() < b.RelationName()
+	})
+	return out
+}
+
+// RelationName returns the name of the node in the database
+func (n Node) RelationName() string {
+	switch {
+	case n.Alias != "":
+		return n.Alias
+	case n.Identifier != "":
+		return n.Identifier
+	}
+	return n.Name
+}
+
+// tests returns the columns of each node with a not_null and with a unique
+// generic test
+func (m *Manifest) tests() (notNull, unique map[string]bool) {
+	notNull, unique = make(map[string]bool), make(map[string]bool)
+	for _, n := range m.Nodes {
+		if n.ResourceType != "test" || n.AttachedNode == "" || n.ColumnName == "" {
+			continue
+		}
+		key := n.AttachedNode + "\x00" + strings.ToLower(n.ColumnName)
+		switch n.TestMetadata.Name {
+		case "not_null":
+			notNull[key] = true
+		case "unique":
+			unique[key] = true
+		}
+	}
+	return notNull, unique
+}
+
+// Apply fills the comments of tables, views and columns that the database
+// has no comment for with the dbt descriptions of the same relation
+// (schema and name, case-insensitive) and returns the number of described
+// tables and views. Comments from the database are kept.
+func (m *Manifest) Apply(schema *model.Schema) int {
+	described := 0
+	for _, r := range m.relations() {
+		n := r.node
+		name := n.RelationName()
+		matched := false
+		for i := range schema.Tables {
+			t := &schema.Tables[i]
+			if same(t.Owner, t.Name, n.Schema, name) {
+				t.Comment = fill(t.Comment, n.Description)
+				describeColumns(t.Columns, n.Columns)
+				matched = true
+			}
+		}
+		for i := range schema.Views {
+			v := &schema.Views[i]
+			if same(v.Owner, v.Name, n.Schema, name) {
+				v.Comment = fill(v.Comment, n.Description)
+				describeColumns(v.Columns, n.Columns)
+				matched = true
+			}
+		}
+		if matched && (n.Description != "" || len(n.Columns) > 0) {
+			described++
+		}
+	}
+	return described
+}
+
+// same reports whether a table is the relation schema.name; tables without
+// owner match on the name
+func same(owner, table, schema, name string) bool {
+	if !strings.EqualFold(table, name) {
+		return false
+	}
+	return owner == "" || strings.EqualFold(owner, schema) ||
+		strings.HasSuffix(strings.ToLower(owner), "."+strings.ToLower(schema)) // combined: "profile.SCHEMA"
+}
+
+func describeColumns(columns []model.Column, docs map[string]Column) {
+	for i := range columns {
+		for name, doc := range docs {
+			if strings.EqualFold(columns[i].Name, name) {
+				columns[i].Comment = fill(columns[i].Comment, doc.Description)
+			}
+		}
+	}
+}
+
+func fill(comment, description string) string {
+	if comment != "" {
+		return comment
+	}
+	return strings.TrimSpace(description)
+}
@@ -0,0 +1,98 @@
+package dbt
+
+import (
+	"context"
+	"errors"
+	"pocket-doc/internal/model"
+)
+
+// DefaultTarget is the artifact directory of a dbt project
+const DefaultTarget = "target"
+
+// Extractor documents a dbt project from its artifacts instead of a
+// database connection ("dbt" database type). Connect reads the artifacts;
+// routines, sequences, triggers and synonyms are not part of them.
+type Extractor struct {
+	dir       string
+	schemas   []string
+	artifacts *Artifacts
+}
+
+// NewExtractor returns an extractor of the artifacts in the dbt target
+// directory dir (default "target"), limited to schemas when not empty
+func NewExtractor(dir string, schemas []string) *Extractor {
+	if dir == "" {
+		dir = DefaultTarget
+	}
+	return &Extractor{dir: dir, schemas: schemas}
+}
+
+// Connect reads the artifacts
+func (e *Extractor) Connect(ctx context.Context) error {
+	a, err := Load(e.dir)
+	if err != nil {
+		return err
+	}
+	e.artifacts = a
+	return nil
+}
+
+// Close does nothing; the artifacts are read on Connect
+func (e *Extractor) Close() error {
+	return nil
+}
+
+// GetDatabaseInfo returns the project name and dbt version
+func (e *Extractor) GetDatabaseInfo(ctx context.Context) (name, version string, err error) {
+	s, err := e.schema()
+	if err != nil {
+		return "", "", err
+	}
+	return s.DatabaseName, s.Version, nil
+}
+
+// GetTables returns the relations materialized as tables
+func (e *Extractor) GetTables(ctx context.Context) ([]model.Table, error) {
+	s, err := e.schema()
+	if err != nil {
+		return nil, err
+	}
+	return s.Tables, nil
+}
+
+// GetViews returns the relations materialized as views
+func (e *Extractor) GetViews(ctx context.Context) ([]model.View, error) {
+	s, err := e.schema()
+	if err != nil {
+		return nil, err
+	}
+	return s.Views, nil
+}
+
+func (e *Extractor) GetRoutines(ctx context.Context) ([]model.Routine, error) {
+	return []model.Routine{}, nil
+}
+
+func (e *Extractor) GetSequences(ctx context.Context) ([]model.Sequence, error) {
+	return []model.Sequence{}, nil
+}
+
+func (e *Extractor) GetTriggers(ctx context.Context) ([]model.Trigger, error) {
+	return []model.Trigger{}, nil
+}
+
+func (e *Extractor) GetSynonyms(ctx context.Context) ([]model.Synonym, error) {
+	return []model.Synonym{}, nil
+}
+
+// ExtractSchema builds the schema from the artifacts
+func (e *Extractor) ExtractSchema(ctx context.Context) (*model.Schema, error) {
+	return e.schema()
+}
+
+func (e *Extractor) schema() (*model.Schema, error) {
+	if e.artifacts == nil {
+		return nil, errors.New("dbt: artifacts not loaded (call Connect first)")
+	}
+	return e.artifacts.Schema(e.schemas), nil
+}
@@ -0,0 +1,149 @@
+package dbt
+
+import (
+	"sort"
+	"strings"
+
+	"pocket-doc/internal/model"
+)
+
+// Schema builds the schema documented by the artifacts: a table or view
+// per relation with the column types of the catalog (or the data_type of
+// the manifest without catalog) and the dbt descriptions, falling back to
+// the database comments. not_null and unique tests and constraints mark
+// columns, primary_key constraints the key. schemas, when not empty,
+// limits the relations to these schemas (case-insensitive).
+func (a *Artifacts) Schema(schemas []string) *model.Schema {
+	m := a.Manifest
+	s := &model.Schema{
+		DatabaseName: m.Metadata.ProjectName,
+		DatabaseType: m.Metadata.AdapterType,
+		Version:      "dbt " + m.Metadata.DbtVersion,
+		ExtractedAt:  m.Metadata.GeneratedAt,
+	}
+	if a.Catalog != nil && a.Catalog.Metadata.GeneratedAt.After(s.ExtractedAt) {
+		s.ExtractedAt = a.Catalog.Metadata.GeneratedAt
+	}
+
+	notNull, unique := m.tests()
+	for _, r := range m.relations() {
+		n := r.node
+		if len(schemas) > 0 && !containsFold(schemas, n.Schema) {
+			continue
+		}
+		if s.DatabaseName == "" {
+			s.DatabaseName = n.Database
+		}
+		var ct *CatalogTable
+		if a.Catalog != nil {
+			if t, ok := a.Catalog.Nodes[r.id]; ok {
+				ct = &t
+			} else if t, ok := a.Catalog.Sources[r.id]; ok {
+				ct = &t
+			}
+		}
+
+		comment := strings.TrimSpace(n.Description)
+		relType := strings.ToUpper(n.Config.Materialized)
+		if ct != nil {
+			comment = fill(comment, ct.Metadata.Comment)
+			relType = strings.ToUpper(ct.Metadata.Type)
+		}
+		columns := columns(r.id, n, ct, notNull, unique)
+
+		switch {
+		case strings.Contains(relType, "VIEW"):
+			v := model.View{Name: n.RelationName(), Owner: n.Schema, Type: "VIEW", Comment: comment, Columns: columns}
+			if strings.Contains(relType, "MATERIALIZED") {
+				v.Type = "MATERIALIZED VIEW"
+			}
+			v.Team = team(n)
+			s.Views = append(s.Views, v)
+		default:
+			s.Tables = append(s.Tables, model.Table{Name: n.RelationName(), Owner: n.Schema, Type: "TABLE", Comment: comment, Columns: columns, Team: team(n)})
+		}
+	}
+	return s
+}
+
+// columns merges the catalog and manifest columns of a relation: catalog
+// columns in table order, then documented columns the catalog lacks
+func columns(id string, n Node, ct *CatalogTable, notNull, unique map[string]bool) []model.Column {
+	docs := make(map[string]Column, len(n.Columns))
+	for name, c := range n.Columns {
+		docs[strings.ToLower(name)] = c
+	}
+
+	var out []model.Column
+	if ct != nil {
+		for _, c := range ct.Columns {
+			out = append(out, model.Column{Name: c.Name, Position: c.Index, DataType: c.Type, Comment: strings.TrimSpace(c.Comment)})
+		}
+		sort.Slice(out, func(i, j int) bool { return out[i].Position < out[j].Position })
+	}
+	seen := make(map[string]bool, len(out))
+	for _, c := range out {
+		seen[strings.ToLower(c.Name)] = true
+	}
+	var extra []string
+	for key := range docs {
+		if !seen[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	for _, key := range extra {
+		d := docs[key]
+		out = append(out, model.Column{Name: d.Name, Position: len(out) + 1, DataType: d.DataType})
+	}
+
+	for i := range out {
+		c := &out[i]
+		key := strings.ToLower(c.Name)
+		c.Nullable = true
+		d, ok := docs[key]
+		if !ok {
+			continue
+		}
+		if desc := strings.TrimSpace(d.Description); desc != "" {
+			c.Comment = desc
+		}
+		if c.DataType == "" {
+			c.DataType = d.DataType
+		}
+		for _, con := range d.Constraints {
+			switch con.Type {
+			case "not_null":
+				c.Nullable = false
+			case "unique":
+				c.IsUnique = true
+			case "primary_key":
+				c.IsPrimaryKey, c.Nullable = true, false
+			}
+		}
+		if notNull[id+"\x00"+key] {
+			c.Nullable = false
+		}
+		if unique[id+"\x00"+key] {
+			c.IsUnique = true
+		}
+	}
+	return out
+}
+
+// team returns the owner from the meta of a node, if any
+func team(n Node) string {
+	if owner, ok := n.Meta["owner"].(string); ok {
+		return owner
+	}
+	return ""
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"pocket-doc/internal/exporter/docx"
+	"pocket-doc/internal/i18n"
+	"strings"
+)
+
+// ChangelogSection is one group of release-note entries (added, removed, changed)
+type ChangelogSection struct {
+	Type    ChangeType
+	Title   string
+	Entries []string
+}
+
+// Changelog renders the changes as human-readable sentences ("Column X added
+// to table Y"), grouped by change type. quote formats object names and
+// values, e.g. with Markdown backticks.
+func Changelog(r *Result, msg *i18n.Bundle, quote func(string) string) []ChangelogSection {
+	sections := []ChangelogSection{
+		{Type: Added, Title: msg.T("diff.added")},
+		{Type: Removed, Title: msg.T("diff.removed")},
+		{Type: Changed, Title: msg.T("diff.changed")},
+	}
+
+	for _, c := range r.Changes {
+		for i := range sections {
+			if sections[i].Type == c.Type {
+				sections[i].Entries = append(sections[i].Entries, changelogEntry(c, msg, quote))
+			}
+		}
+	}
+
+	out := sections[:0]
+	for _, s := range sections {
+		if len(s.Entries) > 0 {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// changelogEntry renders a single change as a sentence
+func changelogEntry(c Change, msg *i18n.Bundle, quote func(string) string) string {
+	key := fmt.Sprintf("changelog.%s_%s", c.Object, c.Type)
+	table := quote(qualified(c.Owner, c.Table))
+
+	value := func(v string) string {
+		if v == "" {
+			return msg.T("changelog.empty")
+		}
+		return quote(v)
+	}
+
+	switch {
+	case c.Type == Changed && c.Name == "":
+		return msg.T(key, table, msg.T("field."+c.Field), value(c.Old), value(c.New))
+	case c.Type == Changed:
+		return msg.T(key, quote(c.Name), table, msg.T("field."+c.Field), value(c.Old), value(c.New))
+	case c.Name == "":
+		return msg.T(key, table)
+	}
+	return msg.T(key, quote(c.Name), table)
+}
+
+// WriteMarkdown writes the changelog as Markdown release notes
+func WriteMarkdown(w io.Writer, r *Result, msg *i18n.Bundle) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", msg.T("section.changelog"))
+	fmt.Fprintf(&b, "_%s → %s_\n\n", snapshotLabel(r.Old), snapshotLabel(r.New))
+
+	sections := Changelog(r, msg, func(s string) string { return "`" + s + "`" })
+	if len(sections) == 0 {
+		fmt.Fprintf(&b, "%s\n", msg.T("diff.no_changes"))
+	}
+	for _, s := range sections {
+		fmt.Fprintf(&b, "## %s\n\n", s.Title)
+		for _, entry := range s.Entries {
+			fmt.Fprintf(&b, "- %s\n", entry)
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteDOCX writes the changelog as a Word document
+func WriteDOCX(w io.Writer, r *Result, msg *i18n.Bundle) error {
+	paragraphs := []docx.Paragraph{
+		{Text: msg.T("section.changelog"), Style: "Title"},
+		{Text: fmt.Sprintf("%s → %s", snapshotLabel(r.Old), snapshotLabel(r.New)), Style: "Normal"},
+	}
+
+	sections := Changelog(r, msg, func(s string) string { return "'" + s + "'" })
+	if len(sections) == 0 {
+		paragraphs = append(paragraphs, docx.Paragraph{Text: msg.T("diff.no_changes"), Style: "Normal"})
+	}
+	for _, s := range sections {
+		paragraphs = append(paragraphs, docx.Paragraph{Text: s.Title, Style: "Heading1"})
+		for _, entry := range s.Entries {
+			paragraphs = append(paragraphs, docx.Paragraph{Text: entry, Style: "ListBullet"})
+		}
+	}
+
+	return docx.WriteParagraphs(w, docx.Config{Language: msg.Language()}, paragraphs)
+}
+
+// qualified returns "owner.name", or name when owner is empty
+func qualified(owner, name string) string {
+	if owner == "" {
+		return name
+	}
+	return owner + "." + name
+}
@@ -0,0 +1,313 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"pocket-doc/internal/datatype"
+	"pocket-doc/internal/model"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangeType classifies a change between two snapshots
+type ChangeType string
+
+const (
+	Added   ChangeType = "added"
+	Removed ChangeType = "removed"
+	Changed ChangeType = "changed"
+)
+
+// Object types a change can refer to
+const (
+	ObjectTable  = "table"
+	ObjectView   = "view"
+	ObjectColumn = "column"
+	ObjectIndex  = "index"
+)
+
+// Change is a single difference. Table is the owning table or view; Name is
+// the column or index name (empty for table/view changes). Field, Old and
+// New are set for Changed entries only.
+type Change struct {
+	Type   ChangeType `json:"type"`
+	Object string     `json:"object"`
+	Owner  string     `json:"owner,omitempty"`
+	Table  string     `json:"table"`
+	Name   string     `json:"name,omitempty"`
+	Field  string     `json:"field,omitempty"` // dataType, nullable, default, comment, primaryKey, columns, unique
+	Old    string     `json:"old,omitempty"`
+	New    string     `json:"new,omitempty"`
+}
+
+// Path returns the dotted object name ("owner.table.column")
+func (c Change) Path() string {
+	parts := make([]string, 0, 3)
+	for _, p := range []string{c.Owner, c.Table, c.Name} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// Result lists the changes from Old to New, ordered by table, object and name
+type Result struct {
+	Old     Snapshot `json:"old"`
+	New     Snapshot `json:"new"`
+	Changes []Change `json:"changes"`
+
+	// database types of the schemas, set when they differ: data types are
+	// then compared by their canonical type (see package datatype)
+	oldDB, newDB string
+}
+
+// Snapshot identifies a compared schema
+type Snapshot struct {
+	DatabaseName string `json:"databaseName"`
+	ExtractedAt  string `json:"extractedAt"`
+}
+
+// Count returns the number of changes of the given type
+func (r *Result) Count(t ChangeType) int {
+	n := 0
+	for _, c := range r.Changes {
+		if c.Type == t {
+			n++
+		}
+	}
+	return n
+}
+
+// LoadSnapshot reads a schema snapshot written by the "json" export format
+func LoadSnapshot(path string) (*model.Schema, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	defer f.Close()
+
+	schema, err := ReadSnapshot(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return schema, nil
+}
+
+// ReadSnapshot decodes a schema snapshot, e.g. one uploaded to the preview
+func ReadSnapshot(r io.Reader) (*model.Schema, error) {
+	var schema model.Schema
+	if err := json.NewDecoder(r).Decode(&schema); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	return &schema, nil
+}
+
+// Compare returns the differences between two schemas. Objects are matched
+// by owner and name, case-insensitively. Schemas of different database
+// types (e.g. before and after a migration) report a data type change only
+// when the canonical types differ, so VARCHAR2(50) and character
+// varying(50) are the same.
+func Compare(old, new *model.Schema) *Result {
+	r := &Result{
+		Old: snapshotOf(old),
+		New: snapshotOf(new),
+	}
+	if !strings.EqualFold(old.DatabaseType, new.DatabaseType) {
+		r.oldDB, r.newDB = old.DatabaseType, new.DatabaseType
+	}
+
+	oldTables, newTables := tableMap(old), tableMap(new)
+	for key, nt := range newTables {
+		ot, ok := oldTables[key]
+		if !ok {
+			r.add(Change{Type: Added, Object: nt.object, Owner: nt.owner, Table: nt.name})
+			continue
+		}
+		r.compareTable(ot, nt)
+	}
+	for key, ot := range oldTables {
+		if _, ok := newTables[key]; !ok {
+			r.add(Change{Type: Removed, Object: ot.object, Owner: ot.owner, Table: ot.name})
+		}
+	}
+
+	sort.SliceStable(r.Changes, func(i, j int) bool {
+		a, b := r.Changes[i], r.Changes[j]
+		if ka, kb := objectKey(a.Owner, a.Table), objectKey(b.Owner, b.Table); ka != kb {
+			return ka < kb
+		}
+		if ra, rb := objectRank(a.Object), objectRank(b.Object); ra != rb {
+			return ra < rb
+		}
+		return strings.ToUpper(a.Name) < strings.ToUpper(b.Name)
+	})
+	return r
+}
+
+// compareTable records comment, column and index changes of a matched table/view
+func (r *Result) compareTable(ot, nt tableInfo) {
+	base := Change{Object: nt.object, Owner: nt.owner, Table: nt.name}
+	r.field(base, "comment", ot.comment, nt.comment)
+
+	oldCols, newCols := columnMap(ot.columns), columnMap(nt.columns)
+	for _, nc := range nt.columns {
+		col := Change{Object: ObjectColumn, Owner: nt.owner, Table: nt.name, Name: nc.Name}
+		oc, ok := oldCols[strings.ToUpper(nc.Name)]
+		if !ok {
+			col.Type = Added
+			r.add(col)
+			continue
+		}
+		oldType, newType := r.columnTypes(oc, nc)
+		r.field(col, "dataType", oldType, newType)
+		r.field(col, "nullable", strconv.FormatBool(oc.Nullable), strconv.FormatBool(nc.Nullable))
+		r.field(col, "default", oc.DefaultValue, nc.DefaultValue)
+		r.field(col, "primaryKey", strconv.FormatBool(oc.IsPrimaryKey), strconv.FormatBool(nc.IsPrimaryKey))
+		r.field(col, "comment", oc.Comment, nc.Comment)
+	}
+	for _, oc := range ot.columns {
+		if _, ok := newCols[strings.ToUpper(oc.Name)]; !ok {
+			r.add(Change{Type: Removed, Object: ObjectColumn, Owner: ot.owner, Table: ot.name, Name: oc.Name})
+		}
+	}
+
+	oldIdx, newIdx := indexMap(ot.indexes), indexMap(nt.indexes)
+	for key, ni := range newIdx {
+		idx := Change{Object: ObjectIndex, Owner: nt.owner, Table: nt.name, Name: ni.Name}
+		oi, ok := oldIdx[key]
+		if !ok {
+			idx.Type = Added
+			r.add(idx)
+			continue
+		}
+		r.field(idx, "columns", strings.Join(oi.Columns, ", "), strings.Join(ni.Columns, ", "))
+		r.field(idx, "unique", strconv.FormatBool(oi.IsUnique), strconv.FormatBool(ni.IsUnique))
+	}
+	for key, oi := range oldIdx {
+		if _, ok := newIdx[key]; !ok {
+			r.add(Change{Type: Removed, Object: ObjectIndex, Owner: ot.owner, Table: ot.name, Name: oi.Name})
+		}
+	}
+}
+
+// columnTypes returns the types of a matched column to compare: the vendor
+// types, which are equal when the canonical types of columns of different
+// database types are
+func (r *Result) columnTypes(oc, nc model.Column) (old, new string) {
+	old, new = columnType(oc), columnType(nc)
+	if r.oldDB != "" && datatype.Of(r.oldDB, oc).String() == datatype.Of(r.newDB, nc).String() {
+		return new, new
+	}
+	return old, new
+}
+
+// field records a Changed entry when the values differ
+func (r *Result) field(base Change, field, old, new string) {
+	if old == new {
+		return
+	}
+	base.Type = Changed
+	base.Field = field
+	base.Old = old
+	base.New = new
+	r.add(base)
+}
+
+func (r *Result) add(c Change) {
+	r.Changes = append(r.Changes, c)
+}
+
+// tableInfo is the comparable part of a table or view
+type tableInfo struct {
+	object  string
+	owner   string
+	name    string
+	comment string
+	columns []model.Column
+	indexes []model.Index
+}
+
+// tableMap indexes tables and views by upper-cased "owner.name"; indexes
+// from schema.Indexes are attached to their table
+func tableMap(schema *model.Schema) map[string]tableInfo {
+	m := make(map[string]tableInfo)
+	for _, t := range schema.Tables {
+		m[objectKey(t.Owner, t.Name)] = tableInfo{
+			object: ObjectTable, owner: t.Owner, name: t.Name, comment: t.Comment,
+			columns: t.Columns, indexes: t.Indexes,
+		}
+	}
+	for _, v := range schema.Views {
+		m[objectKey(v.Owner, v.Name)] = tableInfo{
+			object: ObjectView, owner: v.Owner, name: v.Name, comment: v.Comment, columns: v.Columns,
+		}
+	}
+	for _, idx := range schema.Indexes {
+		key := objectKey(idx.Owner, idx.TableName)
+		if t, ok := m[key]; ok {
+			t.indexes = append(t.indexes, idx)
+			m[key] = t
+		}
+	}
+	return m
+}
+
+func columnMap(cols []model.Column) map[string]model.Column {
+	m := make(map[string]model.Column, len(cols))
+	for _, c := range cols {
+		m[strings.ToUpper(c.Name)] = c
+	}
+	return m
+}
+
+// indexMap indexes by upper-cased name; the same index may be listed both
+// per table and schema-wide, so duplicates collapse
+func indexMap(indexes []model.Index) map[string]model.Index {
+	m := make(map[string]model.Index, len(indexes))
+	for _, idx := range indexes {
+		m[strings.ToUpper(idx.Name)] = idx
+	}
+	return m
+}
+
+// columnType renders a column type with its length or precision/scale
+func columnType(c model.Column) string {
+	switch {
+	case strings.Contains(c.DataType, "("):
+		return c.DataType
+	case c.Precision > 0 && c.Scale > 0:
+		return fmt.Sprintf("%s(%d,%d)", c.DataType, c.Precision, c.Scale)
+	case c.Precision > 0:
+		return fmt.Sprintf("%s(%d)", c.DataType, c.Precision)
+	case c.Length > 0:
+		return fmt.Sprintf("%s(%d)", c.DataType, c.Length)
+	}
+	return c.DataType
+}
+
+func objectKey(owner, name string) string {
+	return strings.ToUpper(owner) + "." + strings.ToUpper(name)
+}
+
+// objectRank orders table-level changes before columns, then indexes
+func objectRank(object string) int {
+	switch object {
+	case ObjectColumn:
+		return 1
+	case ObjectIndex:
+		return 2
+	}
+	return 0
+}
+
+func snapshotOf(schema *model.Schema) Snapshot {
+	s := Snapshot{DatabaseName: schema.DatabaseName}
+	if !schema.ExtractedAt.IsZero() {
+		s.ExtractedAt = schema.ExtractedAt.Format("2006-01-02 15:04:05")
+	}
+	return s
+}
@@ -0,0 +1,151 @@
+package diff
+
+import (
+	"pocket-doc/internal/combine"
+	"pocket-doc/internal/model"
+	"strings"
+)
+
+// Consolidate documents the same schema extracted from several
+// environments (e.g. prod and staging) as one: tables and views are
+// matched by owner and name, keep the definition of the first environment
+// that has them, and carry an EnvDiff for every environment where they,
+// their columns or their indexes differ from it. Objects missing in the
+// first environment follow its objects, in the order of the first
+// environment that has them. Routines, sequences and the other object
+// lists are those of the first environment.
+func Consolidate(parts []combine.Part) *model.Schema {
+	first := parts[0].Schema
+	merged := *first
+	merged.Tables = append([]model.Table(nil), first.Tables...)
+	merged.Views = append([]model.View(nil), first.Views...)
+	merged.Indexes = append([]model.Index(nil), first.Indexes...)
+	merged.Sources, merged.Environments = nil, nil
+
+	seen := make(map[string]bool)
+	for key := range tableMap(first) {
+		seen[key] = true
+	}
+	for _, p := range parts {
+		merged.Sources = append(merged.Sources, combine.SourceOf(p))
+		merged.Environments = append(merged.Environments, p.Name)
+		if p.Schema == first {
+			continue
+		}
+		for _, t := range p.Schema.Tables {
+			if key := objectKey(t.Owner, t.Name); !seen[key] {
+				seen[key] = true
+				merged.Tables = append(merged.Tables, t)
+				merged.Indexes = append(merged.Indexes, indexesOf(p.Schema, t)...)
+			}
+		}
+		for _, v := range p.Schema.Views {
+			if key := objectKey(v.Owner, v.Name); !seen[key] {
+				seen[key] = true
+				merged.Views = append(merged.Views, v)
+			}
+		}
+	}
+
+	// Objects keep their slices of the extracted schemas; the differences
+	// are recorded on copies
+	for i := range merged.Tables {
+		t := &merged.Tables[i]
+		t.Columns = append([]model.Column(nil), t.Columns...)
+		t.EnvDiffs = nil
+	}
+	for i := range merged.Views {
+		v := &merged.Views[i]
+		v.Columns = append([]model.Column(nil), v.Columns...)
+		v.EnvDiffs = nil
+	}
+
+	tables := make(map[string]*model.Table)
+	for i := range merged.Tables {
+		t := &merged.Tables[i]
+		tables[objectKey(t.Owner, t.Name)] = t
+	}
+	views := make(map[string]*model.View)
+	for i := range merged.Views {
+		v := &merged.Views[i]
+		views[objectKey(v.Owner, v.Name)] = v
+	}
+
+	for _, p := range parts {
+		for _, c := range Compare(&merged, p.Schema).Changes {
+			key := objectKey(c.Owner, c.Table)
+			d := model.EnvDiff{Source: p.Name, Missing: c.Type == Removed, Field: c.Field, Value: c.New}
+			if t, ok := tables[key]; ok {
+				switch c.Object {
+				case ObjectColumn:
+					if col := findColumn(t.Columns, c.Name); col != nil {
+						col.EnvDiffs = append(col.EnvDiffs, d)
+						continue
+					}
+					d.Object, d.Name = c.Object, c.Name
+				case ObjectIndex:
+					d.Object, d.Name = c.Object, c.Name
+				}
+				t.EnvDiffs = append(t.EnvDiffs, d)
+			} else if v, ok := views[key]; ok {
+				if c.Object == ObjectColumn {
+					if col := findColumn(v.Columns, c.Name); col != nil {
+						col.EnvDiffs = append(col.EnvDiffs, d)
+						continue
+					}
+					d.Object, d.Name = c.Object, c.Name
+				}
+				v.EnvDiffs = append(v.EnvDiffs, d)
+			}
+		}
+	}
+	return &merged
+}
+
+// Flagged counts the tables and views of a consolidated schema that differ
+// between its environments, their columns included
+func Flagged(schema *model.Schema) int {
+	differs := func(own []model.EnvDiff, cols []model.Column) bool {
+		if len(own) > 0 {
+			return true
+		}
+		for _, c := range cols {
+			if len(c.EnvDiffs) > 0 {
+				return true
+			}
+		}
+		return false
+	}
+	n := 0
+	for _, t := range schema.Tables {
+		if differs(t.EnvDiffs, t.Columns) {
+			n++
+		}
+	}
+	for _, v := range schema.Views {
+		if differs(v.EnvDiffs, v.Columns) {
+			n++
+		}
+	}
+	return n
+}
+
+// indexesOf returns the schema-wide indexes of t
+func indexesOf(schema *model.Schema, t model.Table) []model.Index {
+	var out []model.Index
+	for _, idx := range schema.Indexes {
+		if objectKey(idx.Owner, idx.TableName) == objectKey(t.Owner, t.Name) {
+			out = append(out, idx)
+		}
+	}
+	return out
+}
+
+func findColumn(cols []model.Column, name string) *model.Column {
+	for i := range cols {
+		if strings.EqualFold(cols[i].Name, name) {
+			return &cols[i]
+		}
+	}
+	return nil
+}
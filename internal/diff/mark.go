@@ -0,0 +1,76 @@
+package diff
+
+import (
+	"pocket-doc/internal/model"
+	"strings"
+)
+
+// Mark compares schema with an older snapshot and records the changes on
+// the tables of schema, so documents can show what moved since: Change of
+// new tables and of tables with any comment, column or index change,
+// Change of new and changed columns, and the columns of the older snapshot
+// no longer present in RemovedColumns. Views are not marked. It returns the
+// number of tables marked.
+func Mark(old, schema *model.Schema) int {
+	since := old.ExtractedAt
+	schema.ChangedSince = &since
+
+	r := Compare(old, schema)
+	oldTables := tableMap(old)
+	byTable := make(map[string][]Change)
+	for _, c := range r.Changes {
+		key := objectKey(c.Owner, c.Table)
+		byTable[key] = append(byTable[key], c)
+	}
+
+	marked := 0
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		t.Change, t.RemovedColumns = "", nil
+		for j := range t.Columns {
+			t.Columns[j].Change = ""
+		}
+
+		key := objectKey(t.Owner, t.Name)
+		changes := byTable[key]
+		if len(changes) == 0 {
+			continue
+		}
+		marked++
+		t.Change = model.ChangeChanged
+		removed := make(map[string]bool)
+		for _, c := range changes {
+			switch {
+			case c.Object == ObjectTable && c.Type == Added:
+				t.Change = model.ChangeAdded
+			case c.Object == ObjectColumn && c.Type == Removed:
+				removed[strings.ToUpper(c.Name)] = true
+			case c.Object == ObjectColumn:
+				markColumn(t, c)
+			}
+		}
+		// Removed columns keep the order and definition of the older snapshot
+		for _, oc := range oldTables[key].columns {
+			if removed[strings.ToUpper(oc.Name)] {
+				t.RemovedColumns = append(t.RemovedColumns, oc)
+			}
+		}
+	}
+	return marked
+}
+
+// markColumn marks the column of t that c added or changed
+func markColumn(t *model.Table, c Change) {
+	for j := range t.Columns {
+		col := &t.Columns[j]
+		if !strings.EqualFold(col.Name, c.Name) {
+			continue
+		}
+		switch {
+		case c.Type == Added:
+			col.Change = model.ChangeAdded
+		case col.Change == "":
+			col.Change = model.ChangeChanged
+		}
+	}
+}
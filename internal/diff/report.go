@@ -0,0 +1,218 @@
+package diff
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"pocket-doc/internal/i18n"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Formats lists the report formats accepted by Write. "markdown" and
+// "docx" render the changes as a changelog (release notes).
+var Formats = []string{"text", "html", "xlsx", "markdown", "docx"}
+
+// FileExtension returns the file extension for a report format
+func FileExtension(format string) string {
+	switch format {
+	case "text":
+		return ".txt"
+	case "markdown":
+		return ".md"
+	}
+	return "." + format
+}
+
+// Write renders r in the given format (see Formats)
+func Write(w io.Writer, r *Result, format string, msg *i18n.Bundle) error {
+	switch format {
+	case "text":
+		return WriteText(w, r, msg)
+	case "html":
+		return WriteHTML(w, r, msg)
+	case "xlsx":
+		return WriteXLSX(w, r, msg)
+	case "markdown":
+		return WriteMarkdown(w, r, msg)
+	case "docx":
+		return WriteDOCX(w, r, msg)
+	}
+	return fmt.Errorf("unsupported diff format: %s (supported: %s)", format, strings.Join(Formats, ", "))
+}
+
+// WriteText writes one line per change, prefixed with +, - or ~
+func WriteText(w io.Writer, r *Result, msg *i18n.Bundle) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s: %s → %s\n", msg.T("section.schema_changes"), snapshotLabel(r.Old), snapshotLabel(r.New))
+	fmt.Fprintf(&b, "%s %d, %s %d, %s %d\n\n",
+		msg.T("diff.added"), r.Count(Added), msg.T("diff.removed"), r.Count(Removed), msg.T("diff.changed"), r.Count(Changed))
+
+	if len(r.Changes) == 0 {
+		fmt.Fprintln(&b, msg.T("diff.no_changes"))
+	}
+	for _, c := range r.Changes {
+		switch c.Type {
+		case Added:
+			fmt.Fprintf(&b, "+ %-6s %s\n", c.Object, c.Path())
+		case Removed:
+			fmt.Fprintf(&b, "- %-6s %s\n", c.Object, c.Path())
+		default:
+			fmt.Fprintf(&b, "~ %-6s %s %s: %q → %q\n", c.Object, c.Path(), c.Field, c.Old, c.New)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteHTML writes a standalone HTML page with a table of changes
+func WriteHTML(w io.Writer, r *Result, msg *i18n.Bundle) error {
+	tmpl, err := template.New("diff").Funcs(template.FuncMap{
+		"t":        msg.T,
+		"snapshot": snapshotLabel,
+	}).Parse(htmlTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse diff template: %w", err)
+	}
+
+	data := struct {
+		*Result
+		Lang                    string
+		Added, Removed, Changed int
+	}{r, msg.Language(), r.Count(Added), r.Count(Removed), r.Count(Changed)}
+	return tmpl.Execute(w, data)
+}
+
+// WriteXLSX writes a workbook with a "Changes" sheet; changes beyond
+// Excel's row limit continue on "Changes (2)", ...
+func WriteXLSX(w io.Writer, r *Result, msg *i18n.Bundle) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := "Changes"
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return fmt.Errorf("failed to create sheet %s: %w", sheet, err)
+	}
+
+	f.SetCellValue(sheet, "A1", fmt.Sprintf("%s: %s → %s", msg.T("section.schema_changes"), snapshotLabel(r.Old), snapshotLabel(r.New)))
+	f.MergeCell(sheet, "A1", "H1")
+
+	headers := []interface{}{
+		msg.T("label.change"), msg.T("label.object_type"), msg.T("label.owner"), msg.T("label.table"),
+		msg.T("label.name"), msg.T("label.field"), msg.T("label.old_value"), msg.T("label.new_value"),
+	}
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true, Size: 11},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#D9D9D9"}, Pattern: 1},
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+	})
+	// header writes the column labels to row of sheet and sets the widths
+	header := func(sheet string, row int) error {
+		first, _ := excelize.CoordinatesToCellName(1, row)
+		last, _ := excelize.CoordinatesToCellName(len(headers), row)
+		if err := f.SetSheetRow(sheet, first, &headers); err != nil {
+			return err
+		}
+		f.SetCellStyle(sheet, first, last, headerStyle)
+		f.SetColWidth(sheet, "A", "B", 12)
+		f.SetColWidth(sheet, "C", "E", 20)
+		f.SetColWidth(sheet, "F", "F", 14)
+		f.SetColWidth(sheet, "G", "H", 30)
+		return nil
+	}
+	if err := header(sheet, 3); err != nil {
+		return fmt.Errorf("failed to write sheet %s: %w", sheet, err)
+	}
+
+	row, part := 4, 1
+	for _, c := range r.Changes {
+		if row > excelize.TotalRows {
+			part++
+			sheet = fmt.Sprintf("Changes (%d)", part)
+			if _, err := f.NewSheet(sheet); err != nil {
+				return fmt.Errorf("failed to create sheet %s: %w", sheet, err)
+			}
+			if err := header(sheet, 1); err != nil {
+				return fmt.Errorf("failed to write sheet %s: %w", sheet, err)
+			}
+			row = 2
+		}
+		values := []interface{}{msg.T("diff." + string(c.Type)), c.Object, c.Owner, c.Table, c.Name, c.Field, c.Old, c.New}
+		cell, _ := excelize.CoordinatesToCellName(1, row)
+		if err := f.SetSheetRow(sheet, cell, &values); err != nil {
+			return fmt.Errorf("failed to write sheet %s: %w", sheet, err)
+		}
+		row++
+	}
+
+	return f.Write(w)
+}
+
+// snapshotLabel returns "name (extracted at)" for report headers
+func snapshotLabel(s Snapshot) string {
+	if s.ExtractedAt == "" {
+		return s.DatabaseName
+	}
+	return fmt.Sprintf("%s (%s)", s.DatabaseName, s.ExtractedAt)
+}
+
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+    <meta charset="UTF-8">
+    <title>{{t "section.schema_changes"}}</title>
+    <style>
+        * {
+            font-family: 'Malgun Gothic', 'Apple SD Gothic Neo', 'Noto Sans KR',
+                         -apple-system, BlinkMacSystemFont, 'Segoe UI', Arial, sans-serif;
+        }
+        body { margin: 20px; color: #333; }
+        h1 { color: #2c3e50; border-bottom: 3px solid #3498db; padding-bottom: 10px; }
+        table { width: 100%; border-collapse: collapse; font-size: 13px; }
+        th { background: #D9D9D9; border: 1px solid #bdc3c7; padding: 8px; text-align: left; }
+        td { border: 1px solid #ecf0f1; padding: 6px 8px; }
+        tr.added td:first-child { color: #27ae60; font-weight: bold; }
+        tr.removed td:first-child { color: #c0392b; font-weight: bold; }
+        tr.changed td:first-child { color: #e67e22; font-weight: bold; }
+        code { background: #f4f4f4; padding: 1px 4px; }
+        @media print { thead { display: table-header-group; } tr { page-break-inside: avoid; } }
+    </style>
+</head>
+<body>
+    <h1>{{t "section.schema_changes"}}</h1>
+    <p>{{snapshot .Old}} → {{snapshot .New}}</p>
+    <p>{{t "diff.added"}}: {{.Added}} · {{t "diff.removed"}}: {{.Removed}} · {{t "diff.changed"}}: {{.Changed}}</p>
+    {{if .Changes}}
+    <table>
+        <thead>
+            <tr>
+                <th>{{t "label.change"}}</th>
+                <th>{{t "label.object_type"}}</th>
+                <th>{{t "label.object"}}</th>
+                <th>{{t "label.field"}}</th>
+                <th>{{t "label.old_value"}}</th>
+                <th>{{t "label.new_value"}}</th>
+            </tr>
+        </thead>
+        <tbody>
+            {{range .Changes}}
+            <tr class="{{.Type}}">
+                <td>{{t (printf "diff.%s" .Type)}}</td>
+                <td>{{.Object}}</td>
+                <td>{{.Path}}</td>
+                <td>{{.Field}}</td>
+                <td>{{if .Old}}<code>{{.Old}}</code>{{end}}</td>
+                <td>{{if .New}}<code>{{.New}}</code>{{end}}</td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+    {{else}}
+    <p>{{t "diff.no_changes"}}</p>
+    {{end}}
+</body>
+</html>
+`
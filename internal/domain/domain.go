@@ -0,0 +1,68 @@
+// Package domain assigns tables and views to business domains (billing,
+// HR, audit, ...) by name rules, so documents can be selected, split or
+// organized by the part of the business they serve.
+package domain
+
+import (
+	"path"
+	"pocket-doc/internal/model"
+	"strings"
+)
+
+// Rule assigns the tables and views matching any of its patterns to the
+// domain Name. A pattern is a glob (*, ?, [...]) on "OWNER.NAME" when it
+// contains a dot and on the bare name otherwise, case-insensitively:
+// "BILLING.*" matches every object of the BILLING schema, "*_INVOICE*"
+// matching objects of any schema.
+type Rule struct {
+	Name  string
+	Match []string
+}
+
+// Rules are tried in order; the first match sets the domain. Patterns are
+// checked when the configuration is loaded (output.domains); an invalid
+// one matches nothing.
+type Rules []Rule
+
+// Apply sets the domain of the tables and views that have none (e.g. from
+// an annotations file) to that of the first matching rule, and returns
+// the number of objects assigned
+func (r Rules) Apply(schema *model.Schema) int {
+	n := 0
+	assign := func(domain *string, owner, name string) {
+		if *domain != "" {
+			return
+		}
+		if d := r.Of(owner, name); d != "" {
+			*domain = d
+			n++
+		}
+	}
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		assign(&t.Domain, t.Owner, t.Name)
+	}
+	for i := range schema.Views {
+		v := &schema.Views[i]
+		assign(&v.Domain, v.Owner, v.Name)
+	}
+	return n
+}
+
+// Of returns the domain of the first rule matching owner.name, or ""
+func (r Rules) Of(owner, name string) string {
+	qualified := strings.ToUpper(model.QualifiedName(owner, name))
+	bare := strings.ToUpper(name)
+	for _, rule := range r {
+		for _, p := range rule.Match {
+			target := bare
+			if strings.Contains(p, ".") {
+				target = qualified
+			}
+			if ok, _ := path.Match(strings.ToUpper(p), target); ok {
+				return rule.Name
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,497 @@
+// Package erd lays out the foreign key graph of a schema as an entity
+// relationship diagram and renders it as SVG or PNG in process, without
+// Graphviz or any other external tool, so air-gapped installations get
+// diagrams too. The layout is layered like dagre's: referenced tables sit
+// above the tables referencing them, edges spanning several rows bend
+// through reserved slots in the rows between, and the rows are reordered
+// to reduce crossings.
+package erd
+
+import (
+	"fmt"
+	"pocket-doc/internal/model"
+	"sort"
+	"unicode"
+)
+
+// Layout metrics in pixels (SVG user units)
+const (
+	fontSize   = 12.0
+	lineHeight = 18.0
+	padX       = 8.0
+	keyWidth   = 34.0 // gutter of the "PK FK" marks
+	minWidth   = 120.0
+	maxWidth   = 320.0
+	gapX       = 40.0 // between boxes of a row
+	bendGap    = 16.0 // around the bend points of long edges
+	gapY       = 70.0 // between rows
+	margin     = 20.0
+	loopSize   = 18.0 // self-reference loop
+
+	maxPerRow = 8  // boxes per row; wider ranks wrap
+	maxLines  = 12 // key columns listed per box
+	sweeps    = 8  // crossing-reduction passes
+)
+
+// Point is a position in the diagram
+type Point struct {
+	X, Y float64
+}
+
+// Line is a column listed in a table box
+type Line struct {
+	Key      string // "PK", "FK", "PK FK", or "" for the "+N" line
+	Name     string
+	DataType string
+}
+
+// Node is the box of a table: its name above the primary and foreign key
+// columns, then "+N" for the other columns
+type Node struct {
+	Table      *model.Table
+	Title      string
+	Lines      []Line
+	X, Y, W, H float64
+}
+
+// Edge is the foreign key of Child referencing Parent, drawn as a polyline
+// from Child to Parent. Composite keys and several foreign keys between
+// the same tables share an edge.
+type Edge struct {
+	Child, Parent *Node
+	Columns       []string // "CHILD_COLUMN → PARENT_COLUMN"
+	Points        []Point
+}
+
+// Diagram is a laid out entity relationship diagram
+type Diagram struct {
+	Width, Height float64
+	Nodes         []*Node
+	Edges         []*Edge
+}
+
+// Empty reports whether the schema has no foreign keys between its tables
+func (d *Diagram) Empty() bool {
+	return len(d.Nodes) == 0
+}
+
+// Layout places the tables of schema that take part in a foreign key
+// between documented tables; tables without any (see the relationships
+// report) are left out. namer renders the box titles.
+func Layout(schema *model.Schema, namer model.Namer) *Diagram {
+	g := model.NewGraph(schema)
+	d := &Diagram{}
+
+	nodes := make(map[*model.Table]*Node)
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		if len(g.Parents(t)) == 0 && len(g.Children(t)) == 0 {
+			continue
+		}
+		n := newNode(t, namer.Name(t.Owner, t.Name))
+		nodes[t] = n
+		d.Nodes = append(d.Nodes, n)
+	}
+	if d.Empty() {
+		return d
+	}
+
+	type pair struct{ child, parent *Node }
+	byPair := make(map[pair]*Edge)
+	for _, r := range g.Relationships() {
+		if r.Parent == nil {
+			continue
+		}
+		p := pair{nodes[r.Child], nodes[r.Parent]}
+		e, ok := byPair[p]
+		if !ok {
+			e = &Edge{Child: p.child, Parent: p.parent}
+			byPair[p] = e
+			d.Edges = append(d.Edges, e)
+		}
+		e.Columns = append(e.Columns, r.Column+" → "+r.ParentColumn)
+	}
+
+	l := newLayout(d)
+	l.order()
+	l.place()
+	l.route()
+	return d
+}
+
+// newNode sizes the box of t
+func newNode(t *model.Table, title string) *Node {
+	n := &Node{Table: t, Title: title}
+	others := 0
+	for _, c := range t.Columns {
+		var key string
+		switch {
+		case c.IsPrimaryKey && c.FKTargetTable != "":
+			key = "PK FK"
+		case c.IsPrimaryKey:
+			key = "PK"
+		case c.FKTargetTable != "":
+			key = "FK"
+		default:
+			others++
+			continue
+		}
+		if len(n.Lines) == maxLines {
+			others++
+			continue
+		}
+		n.Lines = append(n.Lines, Line{Key: key, Name: c.Name, DataType: c.DataType})
+	}
+	if others > 0 {
+		n.Lines = append(n.Lines, Line{Name: fmt.Sprintf("+%d", others)})
+	}
+
+	w := textWidth(n.Title, fontSize) + 2*padX
+	for _, line := range n.Lines {
+		w = max(w, keyWidth+textWidth(line.Name+"  "+line.DataType, fontSize)+2*padX)
+	}
+	n.W = min(max(w, minWidth), maxWidth)
+	n.H = lineHeight * float64(len(n.Lines)+1)
+	if len(n.Lines) > 0 {
+		n.H += 4
+	}
+	return n
+}
+
+// textWidth estimates the width of text at size: East Asian wide
+// characters take a full em, others 0.6 em
+func textWidth(text string, size float64) float64 {
+	w := 0.0
+	for _, r := range text {
+		w += runeWidth(r) * size
+	}
+	return w
+}
+
+func runeWidth(r rune) float64 {
+	if isWide(r) {
+		return 1
+	}
+	return 0.6
+}
+
+// isWide reports whether r is an East Asian wide or fullwidth character
+func isWide(r rune) bool {
+	return unicode.In(r, unicode.Hangul, unicode.Han, unicode.Hiragana, unicode.Katakana) ||
+		(r >= 0x3000 && r <= 0x303F) || (r >= 0xFF00 && r <= 0xFF60) || (r >= 0xFFE0 && r <= 0xFFE6)
+}
+
+// fit shortens text with "…" to at most width at size
+func fit(text string, width, size float64) string {
+	if textWidth(text, size) <= width {
+		return text
+	}
+	w := textWidth("…", size)
+	for i, r := range text {
+		w += runeWidth(r) * size
+		if w > width {
+			return text[:i] + "…"
+		}
+	}
+	return text
+}
+
+// vertex is a box or a bend point of a long edge in a row
+type vertex struct {
+	node  *Node // nil for a bend point
+	row   int
+	x, w  float64
+	above []*vertex // neighbors in the row above
+	below []*vertex // neighbors in the row below
+}
+
+func (v *vertex) center() float64 {
+	return v.x + v.w/2
+}
+
+// layout is the working state of Layout
+type layout struct {
+	d      *Diagram
+	rows   [][]*vertex
+	vertex map[*Node]*vertex
+	bends  map[*Edge][]*vertex // from the child's row towards the parent's
+	rowY   []float64
+	rowH   []float64
+}
+
+// newLayout ranks the boxes into rows and adds the bend points of the
+// edges spanning several rows
+func newLayout(d *Diagram) *layout {
+	l := &layout{d: d, vertex: make(map[*Node]*vertex), bends: make(map[*Edge][]*vertex)}
+
+	parents := make(map[*Node][]*Node)
+	for _, e := range d.Edges {
+		if e.Child != e.Parent {
+			parents[e.Child] = append(parents[e.Child], e.Parent)
+		}
+	}
+	// Longest path from the tables referencing nothing; edges closing a
+	// cycle are ignored, so a cycle ranks like a chain
+	rank := make(map[*Node]int)
+	state := make(map[*Node]int) // 1 = on the path, 2 = ranked
+	var visit func(n *Node) int
+	visit = func(n *Node) int {
+		if state[n] == 2 {
+			return rank[n]
+		}
+		state[n] = 1
+		r := 0
+		for _, p := range parents[n] {
+			if state[p] != 1 {
+				r = max(r, visit(p)+1)
+			}
+		}
+		state[n], rank[n] = 2, r
+		return r
+	}
+	ranks := 0
+	for _, n := range d.Nodes {
+		ranks = max(ranks, visit(n)+1)
+	}
+
+	// A row per rank, wrapped after maxPerRow boxes
+	for r := 0; r < ranks; r++ {
+		var row []*vertex
+		for _, n := range d.Nodes {
+			if rank[n] != r {
+				continue
+			}
+			if len(row) == maxPerRow {
+				l.rows = append(l.rows, row)
+				row = nil
+			}
+			v := &vertex{node: n, row: len(l.rows), w: n.W}
+			l.vertex[n] = v
+			row = append(row, v)
+		}
+		l.rows = append(l.rows, row)
+	}
+
+	for _, e := range d.Edges {
+		from, to := l.vertex[e.Child], l.vertex[e.Parent]
+		if from == to {
+			continue
+		}
+		step := 1
+		if to.row < from.row {
+			step = -1
+		}
+		prev := from
+		for r := from.row + step; r != to.row; r += step {
+			bend := &vertex{row: r, w: 0}
+			l.rows[r] = append(l.rows[r], bend)
+			l.bends[e] = append(l.bends[e], bend)
+			link(prev, bend)
+			prev = bend
+		}
+		if prev.row != to.row {
+			link(prev, to)
+		}
+	}
+	return l
+}
+
+// link connects vertices of adjacent rows
+func link(a, b *vertex) {
+	if a.row > b.row {
+		a, b = b, a
+	}
+	a.below = append(a.below, b)
+	b.above = append(b.above, a)
+}
+
+// order reorders the rows by the barycenter of their neighbors, sweeping
+// down and up
+func (l *layout) order() {
+	position := func(row []*vertex) map[*vertex]float64 {
+		pos := make(map[*vertex]float64, len(row))
+		for i, v := range row {
+			pos[v] = float64(i)
+		}
+		return pos
+	}
+	sortRow := func(row []*vertex, neighbors func(*vertex) []*vertex, pos map[*vertex]float64) {
+		own := position(row)
+		key := make(map[*vertex]float64, len(row))
+		for _, v := range row {
+			key[v] = own[v]
+			if ns := neighbors(v); len(ns) > 0 {
+				sum := 0.0
+				for _, n := range ns {
+					sum += pos[n]
+				}
+				key[v] = sum / float64(len(ns))
+			}
+		}
+		sort.SliceStable(row, func(i, j int) bool { return key[row[i]] < key[row[j]] })
+	}
+
+	for s := 0; s < sweeps; s++ {
+		if s%2 == 0 {
+			for r := 1; r < len(l.rows); r++ {
+				sortRow(l.rows[r], func(v *vertex) []*vertex { return v.above }, position(l.rows[r-1]))
+			}
+		} else {
+			for r := len(l.rows) - 2; r >= 0; r-- {
+				sortRow(l.rows[r], func(v *vertex) []*vertex { return v.below }, position(l.rows[r+1]))
+			}
+		}
+	}
+}
+
+// place assigns the coordinates: rows top to bottom, boxes packed left to
+// right, then pulled towards the centers of their neighbors
+func (l *layout) place() {
+	gap := func(a, b *vertex) float64 {
+		if a.node == nil || b.node == nil {
+			return bendGap
+		}
+		return gapX
+	}
+	pack := func(row []*vertex, want func(*vertex) float64) {
+		x := margin
+		for i, v := range row {
+			if i > 0 {
+				x = row[i-1].x + row[i-1].w + gap(row[i-1], v)
+			}
+			v.x = max(x, want(v))
+		}
+	}
+	pull := func(row []*vertex, neighbors func(*vertex) []*vertex) {
+		pack(row, func(v *vertex) float64 {
+			ns := neighbors(v)
+			if len(ns) == 0 {
+				return v.x
+			}
+			sum := 0.0
+			for _, n := range ns {
+				sum += n.center()
+			}
+			return sum/float64(len(ns)) - v.w/2
+		})
+	}
+
+	for _, row := range l.rows {
+		pack(row, func(*vertex) float64 { return 0 })
+	}
+	for s := 0; s < 4; s++ {
+		for r := 1; r < len(l.rows); r++ {
+			pull(l.rows[r], func(v *vertex) []*vertex { return v.above })
+		}
+		for r := len(l.rows) - 2; r >= 0; r-- {
+			pull(l.rows[r], func(v *vertex) []*vertex { return v.below })
+		}
+	}
+
+	// Shift everything back to the margin
+	left := -1.0
+	for _, row := range l.rows {
+		if len(row) > 0 && (left < 0 || row[0].x < left) {
+			left = row[0].x
+		}
+	}
+	y := margin
+	for _, row := range l.rows {
+		h := 0.0
+		for _, v := range row {
+			v.x += margin - left
+			if v.node != nil {
+				v.node.X, v.node.Y = v.x, y
+				h = max(h, v.node.H)
+			}
+			l.d.Width = max(l.d.Width, v.x+v.w+loopSize+margin)
+		}
+		l.rowY, l.rowH = append(l.rowY, y), append(l.rowH, h)
+		y += h + gapY
+	}
+	l.d.Height = y - gapY + margin
+}
+
+// side of a box where an edge ends
+type side int
+
+const (
+	top side = iota
+	bottom
+)
+
+// port is the end of an edge on a box, spread along its side by the x of
+// the next point
+type port struct {
+	point  *Point
+	toward float64
+}
+
+// route computes the edge polylines
+func (l *layout) route() {
+	ports := make(map[*Node]map[side][]port)
+	attach := func(n *Node, s side, p *Point, toward float64) {
+		if ports[n] == nil {
+			ports[n] = make(map[side][]port)
+		}
+		ports[n][s] = append(ports[n][s], port{p, toward})
+	}
+
+	for _, e := range l.d.Edges {
+		c, p := e.Child, e.Parent
+		cv, pv := l.vertex[c], l.vertex[p]
+		switch {
+		case c == p:
+			x, y := c.X+c.W, c.Y+lineHeight/2
+			e.Points = []Point{{x, y}, {x + loopSize, y}, {x + loopSize, y + loopSize}, {x, y + loopSize}}
+			continue
+		case cv.row == pv.row:
+			y := c.Y + lineHeight/2
+			if c.X < p.X {
+				e.Points = []Point{{c.X + c.W, y}, {p.X, p.Y + lineHeight/2}}
+			} else {
+				e.Points = []Point{{c.X, y}, {p.X + p.W, p.Y + lineHeight/2}}
+			}
+			continue
+		}
+
+		// Child below its parent, or above it for an edge closing a cycle
+		down := cv.row > pv.row
+		e.Points = []Point{{X: c.X + c.W/2}}
+		if down {
+			e.Points[0].Y = c.Y
+		} else {
+			e.Points[0].Y = c.Y + c.H
+		}
+		for _, b := range l.bends[e] {
+			y0, y1 := l.rowY[b.row], l.rowY[b.row]+l.rowH[b.row]
+			if down {
+				y0, y1 = y1, y0
+			}
+			e.Points = append(e.Points, Point{b.x, y0}, Point{b.x, y1})
+		}
+		end := Point{X: p.X + p.W/2, Y: p.Y}
+		if down {
+			end.Y = p.Y + p.H
+		}
+		e.Points = append(e.Points, end)
+
+		last := len(e.Points) - 1
+		childSide, parentSide := top, bottom
+		if !down {
+			childSide, parentSide = bottom, top
+		}
+		attach(c, childSide, &e.Points[0], e.Points[1].X)
+		attach(p, parentSide, &e.Points[last], e.Points[last-1].X)
+	}
+
+	// Spread the ends along each side, in the order of where they lead
+	for n, sides := range ports {
+		for _, list := range sides {
+			sort.SliceStable(list, func(i, j int) bool { return list[i].toward < list[j].toward })
+			for i, pt := range list {
+				pt.point.X = n.X + n.W*float64(i+1)/float64(len(list)+1)
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package erd
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// PNG writes the diagram as a PNG image, for viewers without SVG support
+// (e.g. the fallback picture of Word documents). Text is drawn with a
+// built-in bitmap font covering ASCII; other characters (Hangul, kanji,
+// accented letters, ...) show as boxes.
+func (d *Diagram) PNG(w io.Writer) error {
+	img := image.NewRGBA(image.Rect(0, 0, int(math.Ceil(d.Width)), int(math.Ceil(d.Height))))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	c := &canvas{img: img}
+
+	edge := rgb(colorEdge)
+	for _, e := range d.Edges {
+		for i := 1; i < len(e.Points); i++ {
+			c.line(e.Points[i-1], e.Points[i], edge)
+		}
+		if n := len(e.Points); n > 1 {
+			c.arrow(e.Points[n-2], e.Points[n-1], edge)
+		}
+	}
+
+	for _, n := range d.Nodes {
+		c.fill(n.X, n.Y, n.W, n.H, color.White)
+		c.fill(n.X, n.Y, n.W, lineHeight, rgb(colorHeader))
+		c.frame(n.X, n.Y, n.W, n.H, rgb(colorBorder))
+
+		title := fit(n.Title, n.W-2*padX, fontSize)
+		c.text(n.X+padX, n.Y+lineHeight-5, title, rgb(colorTitle))
+		c.text(n.X+padX+1, n.Y+lineHeight-5, title, rgb(colorTitle)) // bold
+		for i, line := range n.Lines {
+			y := n.Y + lineHeight*float64(i+2) - 3
+			if line.Key != "" {
+				c.text(n.X+padX, y, line.Key, rgb(colorKey))
+			}
+			name := fit(line.Name, n.W-keyWidth-2*padX, fontSize)
+			c.text(n.X+padX+keyWidth, y, name, rgb(colorText))
+			if line.DataType != "" {
+				x := n.X + padX + keyWidth + textWidth(name+"  ", fontSize)
+				if room := n.X + n.W - padX - x; room > fontSize {
+					c.text(x, y, fit(line.DataType, room, fontSize), rgb(colorMuted))
+				}
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// canvas draws on an image with pixel precision
+type canvas struct {
+	img *image.RGBA
+}
+
+// fill paints a rectangle
+func (c *canvas) fill(x, y, w, h float64, col color.Color) {
+	r := image.Rect(int(x), int(y), int(x+w), int(y+h))
+	draw.Draw(c.img, r, image.NewUniform(col), image.Point{}, draw.Src)
+}
+
+// frame outlines a rectangle
+func (c *canvas) frame(x, y, w, h float64, col color.Color) {
+	c.line(Point{x, y}, Point{x + w - 1, y}, col)
+	c.line(Point{x + w - 1, y}, Point{x + w - 1, y + h - 1}, col)
+	c.line(Point{x + w - 1, y + h - 1}, Point{x, y + h - 1}, col)
+	c.line(Point{x, y + h - 1}, Point{x, y}, col)
+}
+
+// line draws a one pixel line from a to b
+func (c *canvas) line(a, b Point, col color.Color) {
+	steps := int(math.Max(math.Abs(b.X-a.X), math.Abs(b.Y-a.Y)))
+	for i := 0; i <= steps; i++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+		c.img.Set(int(math.Round(a.X+(b.X-a.X)*t)), int(math.Round(a.Y+(b.Y-a.Y)*t)), col)
+	}
+}
+
+// arrow draws the head of the line from a to b at b
+func (c *canvas) arrow(a, b Point, col color.Color) {
+	angle := math.Atan2(b.Y-a.Y, b.X-a.X)
+	for _, da := range []float64{math.Pi - 0.45, math.Pi + 0.45} {
+		c.line(b, Point{b.X + 8*math.Cos(angle+da), b.Y + 8*math.Sin(angle+da)}, col)
+	}
+}
+
+// text draws s with its baseline at y; characters the bitmap font lacks
+// are drawn as boxes as wide as the layout measured them
+func (c *canvas) text(x, y float64, s string, col color.Color) {
+	face := basicfont.Face7x13
+	dr := &font.Drawer{Dst: c.img, Src: image.NewUniform(col), Face: face}
+	for _, r := range s {
+		width := runeWidth(r) * fontSize
+		switch {
+		case r >= ' ' && r <= '~':
+			dr.Dot = fixed.P(int(x), int(y))
+			dr.DrawString(string(r))
+		case r == '…':
+			for dx := 1.0; dx < width; dx += 2 {
+				c.img.Set(int(x+dx), int(y)-1, col)
+			}
+		default:
+			c.frame(x+1, y-9, width-2, 10, col)
+		}
+		x += width
+	}
+}
+
+// rgb parses a "#rrggbb" color
+func rgb(hex string) color.RGBA {
+	v, _ := strconv.ParseUint(hex[1:], 16, 32)
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+}
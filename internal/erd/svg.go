@@ -0,0 +1,103 @@
+package erd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Colors shared by the SVG and PNG renderings
+const (
+	colorBorder = "#95a5a6"
+	colorHeader = "#34495e"
+	colorTitle  = "#ffffff"
+	colorText   = "#333333"
+	colorMuted  = "#7f8c8d"
+	colorKey    = "#2980b9"
+	colorEdge   = "#7f8c8d"
+)
+
+// SVG writes the diagram as a standalone SVG document. font is the CSS
+// font-family list of the text (see fonts.Config.CSS); the viewer's fonts
+// render every script, so prefer SVG over PNG where it is supported.
+func (d *Diagram) SVG(w io.Writer, font string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s" font-size="%s">`+"\n",
+		num(d.Width), num(d.Height), num(d.Width), num(d.Height), num(fontSize))
+	b.WriteString("<style>")
+	escape(&b, fmt.Sprintf("text{font-family:%s;fill:%s}.t{font-weight:bold;fill:%s}.k{font-size:10px;fill:%s}.m{fill:%s}",
+		font, colorText, colorTitle, colorKey, colorMuted))
+	b.WriteString("</style>\n")
+	fmt.Fprintf(&b, `<defs><marker id="erd-arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto">`+
+		`<path d="M0,0L10,5L0,10z" fill="%s"/></marker></defs>`+"\n", colorEdge)
+	b.WriteString(`<rect width="100%" height="100%" fill="#ffffff"/>` + "\n")
+
+	// Edges below the boxes they end at
+	for _, e := range d.Edges {
+		b.WriteString(`<path d="`)
+		for i, p := range e.Points {
+			if i == 0 {
+				b.WriteString("M")
+			} else {
+				b.WriteString("L")
+			}
+			b.WriteString(num(p.X) + "," + num(p.Y))
+		}
+		fmt.Fprintf(&b, `" fill="none" stroke="%s" stroke-width="1.2" marker-end="url(#erd-arrow)"><title>`, colorEdge)
+		escape(&b, fmt.Sprintf("%s → %s: %s", e.Child.Title, e.Parent.Title, strings.Join(e.Columns, ", ")))
+		b.WriteString("</title></path>\n")
+	}
+
+	for _, n := range d.Nodes {
+		b.WriteString("<g><title>")
+		escape(&b, n.Title)
+		b.WriteString("</title>")
+		fmt.Fprintf(&b, `<rect x="%s" y="%s" width="%s" height="%s" rx="3" fill="#ffffff" stroke="%s"/>`,
+			num(n.X), num(n.Y), num(n.W), num(n.H), colorBorder)
+		fmt.Fprintf(&b, `<rect x="%s" y="%s" width="%s" height="%s" rx="3" fill="%s"/>`,
+			num(n.X), num(n.Y), num(n.W), num(lineHeight), colorHeader)
+		text(&b, "t", n.X+padX, n.Y+lineHeight-5, fit(n.Title, n.W-2*padX, fontSize))
+		for i, line := range n.Lines {
+			y := n.Y + lineHeight*float64(i+2) - 3
+			if line.Key != "" {
+				text(&b, "k", n.X+padX, y, line.Key)
+			}
+			name := fit(line.Name, n.W-keyWidth-2*padX, fontSize)
+			text(&b, "", n.X+padX+keyWidth, y, name)
+			if line.DataType != "" {
+				x := n.X + padX + keyWidth + textWidth(name+"  ", fontSize)
+				if room := n.X + n.W - padX - x; room > fontSize {
+					text(&b, "m", x, y, fit(line.DataType, room, fontSize))
+				}
+			}
+		}
+		b.WriteString("</g>\n")
+	}
+	b.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// text writes a <text> element of class at x, y (baseline)
+func text(b *strings.Builder, class string, x, y float64, s string) {
+	b.WriteString("<text")
+	if class != "" {
+		fmt.Fprintf(b, ` class="%s"`, class)
+	}
+	fmt.Fprintf(b, ` x="%s" y="%s">`, num(x), num(y))
+	escape(b, s)
+	b.WriteString("</text>")
+}
+
+// escape writes s escaped for XML text and attribute values
+func escape(b *strings.Builder, s string) {
+	// EscapeText only fails when the writer does
+	_ = xml.EscapeText(b, []byte(s))
+}
+
+// num formats a coordinate with at most one decimal
+func num(f float64) string {
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", f), ".0")
+}
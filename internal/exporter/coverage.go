@@ -0,0 +1,42 @@
+package exporter
+
+import (
+	"context"
+	"pocket-doc/internal/coverage"
+	"pocket-doc/internal/i18n"
+	"pocket-doc/internal/model"
+	"io"
+)
+
+// coverageExporter writes the comment coverage report as plain text
+type coverageExporter struct {
+	msg *i18n.Bundle
+}
+
+// newCoverage builds the built-in comment coverage report exporter
+func newCoverage(cfg Config) (Exporter, error) {
+	return &coverageExporter{msg: i18n.New(cfg.Language)}, nil
+}
+
+// Export writes per-schema coverage and the list of uncommented tables/columns
+func (e *coverageExporter) Export(ctx context.Context, schema *model.Schema, w io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return coverage.WriteText(w, coverage.Compute(schema), e.msg)
+}
+
+// Format returns the format name
+func (e *coverageExporter) Format() string {
+	return "coverage"
+}
+
+// MimeType returns the MIME type
+func (e *coverageExporter) MimeType() string {
+	return "text/plain; charset=utf-8"
+}
+
+// FileExtension returns the file extension
+func (e *coverageExporter) FileExtension() string {
+	return ".txt"
+}
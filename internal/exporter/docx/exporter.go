@@ -1,371 +1,917 @@
-﻿package docx
-
-import (
-	"archive/zip"
-	"pocket-doc/internal/model"
-	"fmt"
-	"io"
-	"strings"
-	"time"
-)
-
-// Config holds configuration for Word export
-type Config struct {
-	Language         string
-	IncludeTOC       bool
-	IncludeCoverPage bool
-	CompanyName      string
-	ProjectName      string
-	Author           string
-	ExcludeTypes     []string
-	ColorScheme      string
-}
-
-// Exporter implements Word (.docx) export functionality
-type Exporter struct {
-	config Config
-}
-
-// NewExporter creates a new Word exporter
-func NewExporter(cfg Config) *Exporter {
-	return &Exporter{config: cfg}
-}
-
-// Format returns the format name
-func (e *Exporter) Format() string {
-	return "docx"
-}
-
-// MimeType returns the MIME type
-func (e *Exporter) MimeType() string {
-	return "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
-}
-
-// FileExtension returns the file extension
-func (e *Exporter) FileExtension() string {
-	return ".docx"
-}
-
-// Export generates a valid .docx file (OOXML format)
-// Creates a minimal but valid ZIP-based Word document
-func (e *Exporter) Export(schema *model.Schema, w io.Writer) error {
-	// Create ZIP writer
-	zipWriter := zip.NewWriter(w)
-	defer zipWriter.Close()
-
-	// 1. [Content_Types].xml
-	if err := e.writeContentTypes(zipWriter); err != nil {
-		return err
-	}
-
-	// 2. _rels/.rels
-	if err := e.writeRels(zipWriter); err != nil {
-		return err
-	}
-
-	// 3. word/_rels/document.xml.rels
-	if err := e.writeDocumentRels(zipWriter); err != nil {
-		return err
-	}
-
-	// 4. word/document.xml (main content)
-	if err := e.writeDocument(zipWriter, schema); err != nil {
-		return err
-	}
-
-	// 5. word/styles.xml
-	if err := e.writeStyles(zipWriter); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// writeContentTypes creates [Content_Types].xml
-func (e *Exporter) writeContentTypes(zw *zip.Writer) error {
-	f, err := zw.Create("[Content_Types].xml")
-	if err != nil {
-		return err
-	}
-
-	content := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
-<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
-	<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
-	<Default Extension="xml" ContentType="application/xml"/>
-	<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
-	<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>
-</Types>`
-
-	_, err = f.Write([]byte(content))
-	return err
-}
-
-// writeRels creates _rels/.rels
-func (e *Exporter) writeRels(zw *zip.Writer) error {
-	f, err := zw.Create("_rels/.rels")
-	if err != nil {
-		return err
-	}
-
-	content := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
-<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
-	<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
-</Relationships>`
-
-	_, err = f.Write([]byte(content))
-	return err
-}
-
-// writeDocumentRels creates word/_rels/document.xml.rels
-func (e *Exporter) writeDocumentRels(zw *zip.Writer) error {
-	f, err := zw.Create("word/_rels/document.xml.rels")
-	if err != nil {
-		return err
-	}
-
-	content := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
-<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
-	<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
-</Relationships>`
-
-	_, err = f.Write([]byte(content))
-	return err
-}
-
-// writeDocument creates word/document.xml with schema content
-func (e *Exporter) writeDocument(zw *zip.Writer, schema *model.Schema) error {
-	f, err := zw.Create("word/document.xml")
-	if err != nil {
-		return err
-	}
-
-	var body strings.Builder
-
-	// Title
-	body.WriteString(e.paragraph(fmt.Sprintf("%s - 데이터베이스 스키마 문서", schema.DatabaseName), "Title"))
-	body.WriteString(e.paragraph("", "Normal"))
-
-	// Overview
-	body.WriteString(e.paragraph("개요", "Heading1"))
-	body.WriteString(e.paragraph(fmt.Sprintf("데이터베이스 유형: %s", schema.DatabaseType), "Normal"))
-	body.WriteString(e.paragraph(fmt.Sprintf("버전: %s", schema.Version), "Normal"))
-	body.WriteString(e.paragraph(fmt.Sprintf("추출 시간: %s", schema.ExtractedAt.Format(time.RFC3339)), "Normal"))
-	body.WriteString(e.paragraph("", "Normal"))
-
-	// Summary
-	body.WriteString(e.paragraph("객체 통계", "Heading2"))
-	body.WriteString(e.paragraph(fmt.Sprintf("• 테이블: %d", len(schema.Tables)), "Normal"))
-	body.WriteString(e.paragraph(fmt.Sprintf("• 뷰: %d", len(schema.Views)), "Normal"))
-	body.WriteString(e.paragraph(fmt.Sprintf("• 프로시저/함수: %d", len(schema.Routines)), "Normal"))
-	body.WriteString(e.paragraph(fmt.Sprintf("• 시퀀스: %d", len(schema.Sequences)), "Normal"))
-	body.WriteString(e.paragraph(fmt.Sprintf("• 트리거: %d", len(schema.Triggers)), "Normal"))
-	body.WriteString(e.paragraph(fmt.Sprintf("• 동의어: %d", len(schema.Synonyms)), "Normal"))
-	body.WriteString(e.paragraph("", "Normal"))
-
-	// Tables
-	if len(schema.Tables) > 0 {
-		body.WriteString(e.paragraph("테이블 목록", "Heading1"))
-		for _, table := range schema.Tables {
-			body.WriteString(e.paragraph(fmt.Sprintf("테이블: %s", table.Name), "Heading2"))
-			if table.Comment != "" {
-				body.WriteString(e.paragraph(table.Comment, "Normal"))
-			}
-			body.WriteString(e.paragraph(fmt.Sprintf("소유자: %s, 행 수: %d", table.Owner, table.RowCount), "Normal"))
-
-			// Columns
-			if len(table.Columns) > 0 {
-				body.WriteString(e.paragraph("컬럼:", "Heading3"))
-				for _, col := range table.Columns {
-					constraints := ""
-					if col.IsPrimaryKey {
-						constraints += "[PK] "
-					}
-					if col.IsForeignKey {
-						constraints += "[FK] "
-					}
-					if col.IsUnique {
-						constraints += "[UK] "
-					}
-
-					colInfo := fmt.Sprintf("  • %s (%s) %s", col.Name, col.DataType, constraints)
-					if col.Comment != "" {
-						colInfo += fmt.Sprintf(" - %s", col.Comment)
-					}
-					body.WriteString(e.paragraph(colInfo, "Normal"))
-				}
-			}
-			body.WriteString(e.paragraph("", "Normal"))
-		}
-	}
-
-	// Routines (NO source code - SECURITY)
-	if len(schema.Routines) > 0 {
-		body.WriteString(e.paragraph("프로시저 / 함수", "Heading1"))
-		body.WriteString(e.paragraph("⚠️ 보안: 프로시저 본문은 제외되었습니다 (서명만 표시)", "Normal"))
-		body.WriteString(e.paragraph("", "Normal"))
-
-		for _, routine := range schema.Routines {
-			body.WriteString(e.paragraph(fmt.Sprintf("%s: %s", routine.Type, routine.Name), "Heading2"))
-			body.WriteString(e.paragraph(routine.Signature, "Normal"))
-			if routine.Comment != "" {
-				body.WriteString(e.paragraph(routine.Comment, "Normal"))
-			}
-			body.WriteString(e.paragraph("", "Normal"))
-		}
-	}
-
-	// Triggers (NO definition - SECURITY)
-	if len(schema.Triggers) > 0 {
-		body.WriteString(e.paragraph("트리거", "Heading1"))
-		body.WriteString(e.paragraph("⚠️ 보안: 트리거 정의는 제외되었습니다 (메타데이터만 표시)", "Normal"))
-		body.WriteString(e.paragraph("", "Normal"))
-
-		for _, trg := range schema.Triggers {
-			body.WriteString(e.paragraph(fmt.Sprintf("트리거: %s", trg.Name), "Heading2"))
-			body.WriteString(e.paragraph(fmt.Sprintf("대상 테이블: %s", trg.TargetTable), "Normal"))
-			body.WriteString(e.paragraph(fmt.Sprintf("시점: %s, 이벤트: %s, 상태: %s", trg.Timing, trg.Event, trg.Status), "Normal"))
-			if trg.Comment != "" {
-				body.WriteString(e.paragraph(trg.Comment, "Normal"))
-			}
-			body.WriteString(e.paragraph("", "Normal"))
-		}
-	}
-
-	// Sequences
-	if len(schema.Sequences) > 0 {
-		body.WriteString(e.paragraph("시퀀스", "Heading1"))
-		for _, seq := range schema.Sequences {
-			body.WriteString(e.paragraph(fmt.Sprintf("시퀀스: %s", seq.Name), "Heading2"))
-			body.WriteString(e.paragraph(fmt.Sprintf("범위: %d ~ %d, 증가: %d, 현재: %d",
-				seq.MinValue, seq.MaxValue, seq.Increment, seq.LastNumber), "Normal"))
-			if seq.Comment != "" {
-				body.WriteString(e.paragraph(seq.Comment, "Normal"))
-			}
-			body.WriteString(e.paragraph("", "Normal"))
-		}
-	}
-
-	// Footer
-	body.WriteString(e.paragraph("", "Normal"))
-	body.WriteString(e.paragraph("──────────────────────────────────────", "Normal"))
-	body.WriteString(e.paragraph("생성: pocket-doc Tool", "Normal"))
-
-	content := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
-<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
-	<w:body>
-%s
-		<w:sectPr>
-			<w:pgSz w:w="11906" w:h="16838"/>
-			<w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440"/>
-		</w:sectPr>
-	</w:body>
-</w:document>`, body.String())
-
-	_, err = f.Write([]byte(content))
-	return err
-}
-
-// paragraph creates a Word paragraph with specified style
-func (e *Exporter) paragraph(text, style string) string {
-	// Escape XML special characters
-	text = strings.ReplaceAll(text, "&", "&amp;")
-	text = strings.ReplaceAll(text, "<", "&lt;")
-	text = strings.ReplaceAll(text, ">", "&gt;")
-	text = strings.ReplaceAll(text, "\"", "&quot;")
-
-	return fmt.Sprintf(`		<w:p>
-			<w:pPr>
-				<w:pStyle w:val="%s"/>
-			</w:pPr>
-			<w:r>
-				<w:t xml:space="preserve">%s</w:t>
-			</w:r>
-		</w:p>
-`, style, text)
-}
-
-// writeStyles creates word/styles.xml with Korean font support
-func (e *Exporter) writeStyles(zw *zip.Writer) error {
-	f, err := zw.Create("word/styles.xml")
-	if err != nil {
-		return err
-	}
-
-	// CRITICAL: Korean font support - Malgun Gothic
-	content := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
-<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
-	<w:docDefaults>
-		<w:rPrDefault>
-			<w:rPr>
-				<w:rFonts w:ascii="Malgun Gothic" w:hAnsi="Malgun Gothic" w:eastAsia="Malgun Gothic" w:cs="Malgun Gothic"/>
-				<w:sz w:val="22"/>
-				<w:szCs w:val="22"/>
-			</w:rPr>
-		</w:rPrDefault>
-	</w:docDefaults>
-	<w:style w:type="paragraph" w:styleId="Normal">
-		<w:name w:val="Normal"/>
-		<w:qFormat/>
-		<w:rPr>
-			<w:rFonts w:ascii="Malgun Gothic" w:hAnsi="Malgun Gothic" w:eastAsia="Malgun Gothic"/>
-			<w:sz w:val="22"/>
-		</w:rPr>
-	</w:style>
-	<w:style w:type="paragraph" w:styleId="Title">
-		<w:name w:val="Title"/>
-		<w:basedOn w:val="Normal"/>
-		<w:qFormat/>
-		<w:rPr>
-			<w:rFonts w:ascii="Malgun Gothic" w:hAnsi="Malgun Gothic" w:eastAsia="Malgun Gothic"/>
-			<w:b/>
-			<w:sz w:val="56"/>
-			<w:color w:val="2E74B5"/>
-		</w:rPr>
-	</w:style>
-	<w:style w:type="paragraph" w:styleId="Heading1">
-		<w:name w:val="Heading 1"/>
-		<w:basedOn w:val="Normal"/>
-		<w:qFormat/>
-		<w:rPr>
-			<w:rFonts w:ascii="Malgun Gothic" w:hAnsi="Malgun Gothic" w:eastAsia="Malgun Gothic"/>
-			<w:b/>
-			<w:sz w:val="32"/>
-			<w:color w:val="2E74B5"/>
-		</w:rPr>
-		<w:pPr>
-			<w:spacing w:before="480" w:after="240"/>
-		</w:pPr>
-	</w:style>
-	<w:style w:type="paragraph" w:styleId="Heading2">
-		<w:name w:val="Heading 2"/>
-		<w:basedOn w:val="Normal"/>
-		<w:qFormat/>
-		<w:rPr>
-			<w:rFonts w:ascii="Malgun Gothic" w:hAnsi="Malgun Gothic" w:eastAsia="Malgun Gothic"/>
-			<w:b/>
-			<w:sz w:val="28"/>
-			<w:color w:val="2E74B5"/>
-		</w:rPr>
-		<w:pPr>
-			<w:spacing w:before="360" w:after="180"/>
-		</w:pPr>
-	</w:style>
-	<w:style w:type="paragraph" w:styleId="Heading3">
-		<w:name w:val="Heading 3"/>
-		<w:basedOn w:val="Normal"/>
-		<w:qFormat/>
-		<w:rPr>
-			<w:rFonts w:ascii="Malgun Gothic" w:hAnsi="Malgun Gothic" w:eastAsia="Malgun Gothic"/>
-			<w:b/>
-			<w:sz w:val="24"/>
-			<w:color w:val="1F4D78"/>
-		</w:rPr>
-		<w:pPr>
-			<w:spacing w:before="240" w:after="120"/>
-		</w:pPr>
-	</w:style>
-</w:styles>`
-
-	_, err = f.Write([]byte(content))
-	return err
-}
+﻿package docx
+
+import (
+	"archive/zip"
+	"context"
+	"pocket-doc/internal/cleanup"
+	"pocket-doc/internal/combine"
+	"pocket-doc/internal/coverage"
+	"pocket-doc/internal/datefmt"
+	"pocket-doc/internal/erd"
+	"pocket-doc/internal/fonts"
+	"pocket-doc/internal/i18n"
+	"pocket-doc/internal/impact"
+	"pocket-doc/internal/model"
+	"pocket-doc/internal/naming"
+	"pocket-doc/internal/page"
+	"pocket-doc/internal/verify"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds configuration for Word export
+type Config struct {
+	Language         string
+	IncludeTOC       bool
+	IncludeCoverPage bool
+	CompanyName      string
+	ProjectName      string
+	Author           string
+	ExcludeTypes     []string
+	ColorScheme      string
+	Classification   string         // Confidentiality label rendered as page header and watermark
+	IncludeCoverage  bool           // Comment coverage chapter
+	IncludeQuality   bool           // Data quality appendix (see package verify)
+	IncludeCleanup   bool           // Cleanup appendix (see package cleanup)
+	IncludeNaming    bool           // Naming conventions appendix (see package naming)
+	IncludeImpact    bool           // Views × base tables matrix (see package impact)
+	LineageDepth     int            // Levels of foreign key parents and children listed per table (0 = none)
+	IncludeERD       bool           // Entity relationship diagram of the foreign keys (see package erd)
+	HideSingleSchema bool           // Object names without owner when there is only one
+	Font             fonts.Config   // Style fonts (empty = defaults of Language)
+	GroupBySchema    bool           // A chapter per schema
+	GroupByDomain    bool           // A chapter per business domain
+	Dates            datefmt.Config // Time zone and layout of timestamps (empty = as extracted, RFC 3339)
+	Page             page.Setup     // Paper size and orientation (empty = A4 portrait)
+}
+
+// Exporter implements Word (.docx) export functionality
+type Exporter struct {
+	config Config
+	msg    *i18n.Bundle
+}
+
+// NewExporter creates a new Word exporter
+func NewExporter(cfg Config) *Exporter {
+	msg := i18n.New(cfg.Language)
+	cfg.Font = cfg.Font.Resolve(msg.Language())
+	return &Exporter{config: cfg, msg: msg}
+}
+
+// Format returns the format name
+func (e *Exporter) Format() string {
+	return "docx"
+}
+
+// MimeType returns the MIME type
+func (e *Exporter) MimeType() string {
+	return "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+}
+
+// FileExtension returns the file extension
+func (e *Exporter) FileExtension() string {
+	return ".docx"
+}
+
+// Export generates a valid .docx file (OOXML format)
+// Creates a minimal but valid ZIP-based Word document
+func (e *Exporter) Export(ctx context.Context, schema *model.Schema, w io.Writer) error {
+	body, pictures, err := e.documentBody(schema)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return e.writePackage(w, body, pictures...)
+}
+
+// Paragraph is a styled paragraph for WriteParagraphs
+type Paragraph struct {
+	Text  string
+	Style string // Title, Heading1 to Heading4, Normal or ListBullet
+}
+
+// WriteParagraphs writes a .docx containing the given paragraphs, with the
+// same styles (Korean fonts) and classification header as schema exports
+func WriteParagraphs(w io.Writer, cfg Config, paragraphs []Paragraph) error {
+	e := NewExporter(cfg)
+
+	var body content
+	for _, p := range paragraphs {
+		body.add(e.paragraph(p.Text, p.Style))
+	}
+	return e.writePackage(w, body)
+}
+
+// writePackage writes the OOXML parts around the given document body and
+// the pictures it shows
+func (e *Exporter) writePackage(w io.Writer, body content, pictures ...picture) error {
+	// Create ZIP writer
+	zipWriter := zip.NewWriter(w)
+	defer zipWriter.Close()
+
+	// 1. [Content_Types].xml
+	if err := e.writeContentTypes(zipWriter, len(pictures) > 0); err != nil {
+		return err
+	}
+
+	// 2. _rels/.rels
+	if err := e.writeRels(zipWriter); err != nil {
+		return err
+	}
+
+	// 3. word/_rels/document.xml.rels
+	if err := e.writeDocumentRels(zipWriter, len(pictures)); err != nil {
+		return err
+	}
+
+	// 4. word/document.xml (main content)
+	if err := e.writeDocument(zipWriter, body); err != nil {
+		return err
+	}
+
+	// 5. word/styles.xml
+	if err := e.writeStyles(zipWriter); err != nil {
+		return err
+	}
+
+	// 6. word/numbering.xml (bullet lists)
+	if err := e.writeNumbering(zipWriter); err != nil {
+		return err
+	}
+
+	// 7. word/header1.xml (classification header + watermark)
+	if e.config.Classification != "" {
+		if err := e.writeHeader(zipWriter); err != nil {
+			return err
+		}
+	}
+
+	// 8. word/media (diagrams)
+	return writeMedia(zipWriter, pictures)
+}
+
+// writeContentTypes creates [Content_Types].xml; images adds the types
+// of the pictures
+func (e *Exporter) writeContentTypes(zw *zip.Writer, images bool) error {
+	override := func(part, contentType string) node {
+		return el("Override").with("PartName", part, "ContentType", contentType)
+	}
+	types := el("Types",
+		el("Default").with("Extension", "rels", "ContentType", "application/vnd.openxmlformats-package.relationships+xml"),
+		el("Default").with("Extension", "xml", "ContentType", "application/xml"),
+		override("/word/document.xml", "application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"),
+		override("/word/styles.xml", "application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"),
+		override("/word/numbering.xml", "application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml"),
+	).with("xmlns", nsTypes)
+	if e.config.Classification != "" {
+		types.children = append(types.children,
+			override("/word/header1.xml", "application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"))
+	}
+	if images {
+		types.children = append(types.children,
+			el("Default").with("Extension", "png", "ContentType", "image/png"),
+			el("Default").with("Extension", "svg", "ContentType", "image/svg+xml"))
+	}
+	return writePart(zw, "[Content_Types].xml", types)
+}
+
+// writeRels creates _rels/.rels
+func (e *Exporter) writeRels(zw *zip.Writer) error {
+	return writePart(zw, "_rels/.rels", el("Relationships",
+		el("Relationship").with("Id", "rId1", "Type", relDocument, "Target", "word/document.xml"),
+	).with("xmlns", nsRels))
+}
+
+// writeDocumentRels creates word/_rels/document.xml.rels, with the
+// relationships of the given number of pictures
+func (e *Exporter) writeDocumentRels(zw *zip.Writer, pictures int) error {
+	rels := el("Relationships",
+		el("Relationship").with("Id", "rId1", "Type", relStyles, "Target", "styles.xml"),
+		el("Relationship").with("Id", "rId3", "Type", relNumbering, "Target", "numbering.xml"),
+	).with("xmlns", nsRels)
+	if e.config.Classification != "" {
+		rels.children = append(rels.children,
+			el("Relationship").with("Id", "rId2", "Type", relHeader, "Target", "header1.xml"))
+	}
+	for n := 1; n <= pictures; n++ {
+		pngID, svgID := relIDs(n)
+		rels.children = append(rels.children,
+			el("Relationship").with("Id", pngID, "Type", relImage, "Target", fmt.Sprintf("media/image%d.png", n)),
+			el("Relationship").with("Id", svgID, "Type", relImage, "Target", fmt.Sprintf("media/image%d.svg", n)))
+	}
+	return writePart(zw, "word/_rels/document.xml.rels", rels)
+}
+
+// documentBody renders the schema content as WordprocessingML paragraphs
+// and the pictures they show
+func (e *Exporter) documentBody(schema *model.Schema) (content, []picture, error) {
+	var body content
+	var pictures []picture
+	namer := model.NewNamer(schema, e.config.HideSingleSchema)
+	links := newLinker(schema)
+
+	// Title
+	body.add(e.paragraph(e.msg.T("doc.title", schema.DatabaseName), "Title"))
+	body.add(e.paragraph("", "Normal"))
+
+	// Overview
+	body.add(e.paragraph(e.msg.T("section.overview"), "Heading1"))
+	body.add(e.paragraph(fmt.Sprintf("%s: %s", e.msg.T("label.database_type"), schema.DatabaseType), "Normal"))
+	body.add(e.paragraph(fmt.Sprintf("%s: %s", e.msg.T("label.version"), schema.Version), "Normal"))
+	body.add(e.paragraph(fmt.Sprintf("%s: %s", e.msg.T("label.extracted_at"), e.config.Dates.Format(schema.ExtractedAt, time.RFC3339)), "Normal"))
+	body.add(e.paragraph("", "Normal"))
+
+	// Summary
+	body.add(e.paragraph(e.msg.T("section.statistics"), "Heading2"))
+	body.add(e.paragraph(fmt.Sprintf("%s: %d", e.msg.T("label.total_tables"), len(schema.Tables)), "ListBullet"))
+	body.add(e.paragraph(fmt.Sprintf("%s: %d", e.msg.T("label.total_views"), len(schema.Views)), "ListBullet"))
+	body.add(e.paragraph(fmt.Sprintf("%s: %d", e.msg.T("label.total_routines"), len(schema.Routines)), "ListBullet"))
+	body.add(e.paragraph(fmt.Sprintf("%s: %d", e.msg.T("label.total_sequences"), len(schema.Sequences)), "ListBullet"))
+	body.add(e.paragraph(fmt.Sprintf("%s: %d", e.msg.T("label.total_triggers"), len(schema.Triggers)), "ListBullet"))
+	body.add(e.paragraph(fmt.Sprintf("%s: %d", e.msg.T("label.total_synonyms"), len(schema.Synonyms)), "ListBullet"))
+	body.add(e.paragraph("", "Normal"))
+
+	// Databases of a combined multi-database document
+	if len(schema.Sources) > 0 {
+		body.add(e.paragraph(e.msg.T("section.databases"), "Heading2"))
+		for _, src := range schema.Sources {
+			body.add(e.paragraph(fmt.Sprintf("%s: %s (%s %s) - %s %d, %s %d, %s %d",
+				src.Name, src.DatabaseName, src.DatabaseType, src.Version,
+				e.msg.T("label.total_tables"), src.Tables, e.msg.T("label.total_views"), src.Views,
+				e.msg.T("label.total_routines"), src.Routines), "ListBullet"))
+		}
+		if envs := schema.Environments; len(envs) > 1 {
+			body.add(e.paragraph(e.msg.T("env.reference", envs[0], strings.Join(envs[1:], ", ")), "Normal"))
+		}
+		body.add(e.paragraph("", "Normal"))
+	}
+
+	// Identical tenant schemas documented once
+	if len(schema.Tenants) > 0 {
+		body.add(e.paragraph(e.msg.T("section.tenants"), "Heading2"))
+		body.add(e.paragraph(e.msg.T("tenants.intro"), "Normal"))
+		for _, p := range schema.Tenants {
+			body.add(e.paragraph(fmt.Sprintf("%s: %s %s, %s %d - %s", p.Pattern,
+				e.msg.T("label.documented_schema"), p.Schema, e.msg.T("label.schema_count"), len(p.Instances),
+				strings.Join(p.Instances, ", ")), "ListBullet"))
+		}
+		body.add(e.paragraph("", "Normal"))
+	}
+
+	// Entity relationship diagram
+	if e.config.IncludeERD {
+		if d := erd.Layout(schema, namer); !d.Empty() {
+			p, err := diagramPicture(d, e.config.Font.CSS())
+			if err != nil {
+				return nil, nil, err
+			}
+			pictures = append(pictures, p)
+			body.add(e.paragraph(e.msg.T("section.erd"), "Heading1"))
+			body.add(e.drawing(len(pictures), p, e.msg.T("section.erd")))
+			body.add(e.paragraph("", "Normal"))
+		}
+	}
+
+	// Views × base tables
+	if e.config.IncludeImpact {
+		if m := impact.Build(schema); !m.Empty() {
+			body.add(e.paragraph(e.msg.T("section.impact"), "Heading1"))
+			body.add(e.paragraph(e.msg.T("impact.intro"), "Normal"))
+			e.impactTables(&body, m, namer)
+			body.add(e.paragraph("", "Normal"))
+		}
+	}
+
+	if e.config.GroupBySchema || e.config.GroupByDomain {
+		// A chapter per schema (domain) with its object sections
+		split, label := combine.SplitBySchema, e.msg.T("label.schema")
+		if e.config.GroupByDomain {
+			split, label = combine.SplitByDomain, e.msg.T("label.domain")
+		}
+		for _, part := range split(schema) {
+			body.add(e.paragraph(fmt.Sprintf("%s: %s", label, part.Name), "Heading1"))
+			e.objectSections(&body, part.Schema, namer, links, 2)
+		}
+	} else {
+		e.objectSections(&body, schema, namer, links, 1)
+	}
+
+	// Comment coverage
+	if e.config.IncludeCoverage {
+		report := coverage.Compute(schema)
+		body.add(e.paragraph(e.msg.T("section.coverage"), "Heading1"))
+		for _, s := range report.Schemas {
+			owner := s.Owner
+			if owner == "" {
+				owner = e.msg.T("ui.default_schema")
+			}
+			body.add(e.paragraph(fmt.Sprintf("%s — %s: %d/%d (%.1f%%), %s: %d/%d (%.1f%%)", owner,
+				e.msg.T("section.tables"), s.CommentedTables, s.Tables, s.TablePercent(),
+				e.msg.T("label.columns"), s.CommentedColumns, s.Columns, s.ColumnPercent()), "ListBullet"))
+		}
+		body.add(e.paragraph(fmt.Sprintf("%s — %s: %.1f%%, %s: %.1f%%", e.msg.T("label.total"),
+			e.msg.T("section.tables"), report.Total.TablePercent(),
+			e.msg.T("label.columns"), report.Total.ColumnPercent()), "ListBullet"))
+
+		if len(report.Missing) > 0 {
+			body.add(e.paragraph(e.msg.T("section.missing_comments"), "Heading2"))
+			for _, m := range report.Missing {
+				body.add(e.paragraph(m.Path(), "ListBullet"))
+			}
+		}
+		body.add(e.paragraph("", "Normal"))
+	}
+
+	// Data quality
+	if e.config.IncludeQuality {
+		report := verify.Check(schema)
+		body.add(e.paragraph(e.msg.T("section.quality"), "Heading1"))
+		if len(report.Findings) == 0 {
+			body.add(e.paragraph(e.msg.T("quality.none"), "Normal"))
+		}
+		for _, check := range verify.Checks {
+			findings := report.ByCheck(check)
+			if len(findings) == 0 {
+				continue
+			}
+			body.add(e.paragraph(e.msg.T("quality."+check), "Heading2"))
+			for _, f := range findings {
+				body.add(e.paragraph(fmt.Sprintf("%s: %s", namer.Name(f.Owner, f.Object), f.Detail), "ListBullet"))
+			}
+		}
+		body.add(e.paragraph("", "Normal"))
+	}
+
+	// Cleanup candidates
+	if e.config.IncludeCleanup {
+		report := cleanup.Check(schema)
+		body.add(e.paragraph(e.msg.T("section.cleanup"), "Heading1"))
+		if len(report.Findings) == 0 {
+			body.add(e.paragraph(e.msg.T("cleanup.none"), "Normal"))
+		}
+		for _, check := range cleanup.Checks {
+			findings := report.ByCheck(check)
+			if len(findings) == 0 {
+				continue
+			}
+			body.add(e.paragraph(e.msg.T("cleanup."+check), "Heading2"))
+			for _, f := range findings {
+				line := namer.Name(f.Owner, f.Object)
+				if f.Detail != "" {
+					line += ": " + f.Detail
+				}
+				body.add(e.paragraph(line, "ListBullet"))
+			}
+		}
+		body.add(e.paragraph("", "Normal"))
+	}
+
+	// Naming conventions
+	if e.config.IncludeNaming {
+		dict := naming.Analyze(schema)
+		body.add(e.paragraph(e.msg.T("section.naming"), "Heading1"))
+		if len(dict.Affixes) == 0 {
+			body.add(e.paragraph(e.msg.T("naming.none"), "Normal"))
+		} else {
+			body.add(e.paragraph(e.msg.T("naming.intro"), "Normal"))
+			rows := make([][]string, len(dict.Affixes))
+			for i, a := range dict.Affixes {
+				affix, meaning := fmt.Sprintf("%s (%s)", a.Text, e.msg.T("naming.suffix")), ""
+				if a.Prefix {
+					affix = fmt.Sprintf("%s (%s)", a.Text, e.msg.T("naming.prefix"))
+				}
+				if a.Meaning != "" {
+					meaning = e.msg.T("naming.meaning." + a.Meaning)
+				}
+				rows[i] = []string{affix, strconv.Itoa(a.Columns), strconv.Itoa(a.Tables), meaning, strings.Join(a.Examples, ", ")}
+			}
+			body.add(e.table([]string{e.msg.T("label.affix"), e.msg.T("label.columns"), e.msg.T("label.table_count"),
+				e.msg.T("label.meaning"), e.msg.T("label.examples")}, rows))
+		}
+		body.add(e.paragraph("", "Normal"))
+	}
+
+	// Migration history
+	if len(schema.Migrations) > 0 {
+		body.add(e.paragraph(e.msg.T("section.migrations"), "Heading1"))
+		rows := make([][]string, len(schema.Migrations))
+		for i, m := range schema.Migrations {
+			status := e.msg.T("migration.success")
+			if !m.Success {
+				status = e.msg.T("migration.failed")
+			}
+			rows[i] = []string{m.Tool, m.Version, m.Description, m.Script, m.AppliedBy, m.AppliedAt, status}
+		}
+		body.add(e.table([]string{e.msg.T("label.tool"), e.msg.T("label.version"), e.msg.T("label.description"),
+			e.msg.T("label.script"), e.msg.T("label.applied_by"), e.msg.T("label.applied_at"), e.msg.T("label.status")}, rows))
+		body.add(e.paragraph("", "Normal"))
+	}
+
+	// Glossary
+	if len(schema.Glossary) > 0 {
+		body.add(e.paragraph(e.msg.T("section.glossary"), "Heading1"))
+		for _, term := range schema.Glossary {
+			body.add(e.paragraph(term.Term, "Heading3"))
+			body.add(e.paragraph(term.Definition, "Normal"))
+		}
+		body.add(e.paragraph("", "Normal"))
+	}
+
+	// Footer
+	body.add(e.paragraph("", "Normal"))
+	body.add(e.paragraph("──────────────────────────────────────", "Normal"))
+	body.add(e.paragraph(e.msg.T("doc.generated_by"), "Normal"))
+
+	return body, pictures, nil
+}
+
+// objectSections adds the table, routine, trigger and sequence sections of
+// schema, with section headings at level (1 = Heading1) and the objects
+// below them, bookmarked by links
+func (e *Exporter) objectSections(body *content, schema *model.Schema, namer model.Namer, links *linker, level int) {
+	// Tables
+	if len(schema.Tables) > 0 {
+		body.add(e.paragraph(e.msg.T("section.tables"), headingStyle(level)))
+		for _, table := range schema.Tables {
+			body.add(links.heading(fmt.Sprintf("%s: %s", e.msg.T("object.table"), namer.Name(table.Owner, table.Name)), headingStyle(level+1),
+				"table", table.Owner, table.Name))
+			if table.Change != "" && schema.ChangedSince != nil {
+				body.add(para("Normal", run(e.msg.T("history.table_"+table.Change, e.changedSince(schema)), el("w:b"), val("w:highlight", "yellow"))))
+			}
+			for _, note := range combine.EnvNotes(table.EnvDiffs, e.msg) {
+				body.add(para("ListBullet", run(note, el("w:b"), val("w:color", envColor))))
+			}
+			if table.Comment != "" {
+				body.add(e.paragraph(table.Comment, "Normal"))
+			}
+			body.add(e.paragraph(fmt.Sprintf("%s: %s, %s: %d",
+				e.msg.T("label.owner"), table.Owner, e.msg.T("label.row_count"), table.RowCount), "Normal"))
+			if len(table.Terms) > 0 {
+				body.add(e.paragraph(fmt.Sprintf("%s: %s", e.msg.T("label.terms"), strings.Join(table.Terms, ", ")), "Normal"))
+			}
+			if table.Team != "" {
+				body.add(e.paragraph(fmt.Sprintf("%s: %s", e.msg.T("label.team"), table.Team), "Normal"))
+			}
+			if table.Domain != "" {
+				body.add(e.paragraph(fmt.Sprintf("%s: %s", e.msg.T("label.domain"), table.Domain), "Normal"))
+			}
+			if len(table.ReferencedBy) > 0 {
+				refs := make([]string, len(table.ReferencedBy))
+				for i, r := range table.ReferencedBy {
+					refs[i] = fmt.Sprintf("%s (%s)", r, r.Type)
+				}
+				body.add(e.paragraph(fmt.Sprintf("%s: %s", e.msg.T("label.used_by"), strings.Join(refs, ", ")), "Normal"))
+			}
+			e.lineage(body, links, &table, namer)
+			if table.Classification != "" {
+				body.add(e.paragraph(fmt.Sprintf("%s: %s", e.msg.T("label.classification"), table.Classification), "Normal"))
+			}
+			if table.Note != "" {
+				body.add(e.paragraph(fmt.Sprintf("%s: %s", e.msg.T("label.note"), table.Note), "Normal"))
+			}
+
+			// Columns
+			if len(table.Columns) > 0 {
+				body.add(e.paragraph(e.msg.T("section.columns")+":", headingStyle(level+2)))
+				for _, col := range table.Columns {
+					constraints := ""
+					if col.IsPrimaryKey {
+						constraints += "[PK] "
+					}
+					if col.IsForeignKey {
+						constraints += "[FK] "
+					}
+					if col.IsUnique {
+						constraints += "[UK] "
+					}
+
+					colInfo := fmt.Sprintf("%s (%s) %s", col.Name, col.DataType, constraints)
+					if col.Comment != "" {
+						colInfo += fmt.Sprintf(" - %s", col.Comment)
+					}
+					if len(col.Terms) > 0 {
+						colInfo += fmt.Sprintf(" [%s: %s]", e.msg.T("label.terms"), strings.Join(col.Terms, ", "))
+					}
+					if col.Classification != "" {
+						colInfo += fmt.Sprintf(" [%s: %s]", e.msg.T("label.classification"), col.Classification)
+					}
+					if col.Note != "" {
+						colInfo += fmt.Sprintf(" (%s: %s)", e.msg.T("label.note"), col.Note)
+					}
+					if len(col.Examples) > 0 {
+						colInfo += fmt.Sprintf(" (%s: %s)", e.msg.T("label.examples"), strings.Join(col.Examples, ", "))
+					}
+					// New and changed columns are highlighted
+					var props []node
+					if col.Change != "" {
+						colInfo += fmt.Sprintf(" [%s]", e.msg.T("history."+col.Change))
+						props = append(props, val("w:highlight", "yellow"))
+					}
+					// and differences between environments colored
+					if notes := combine.EnvNotes(col.EnvDiffs, e.msg); len(notes) > 0 {
+						colInfo += fmt.Sprintf(" [%s]", strings.Join(notes, "; "))
+						props = append(props, val("w:color", envColor))
+					}
+					p := para("ListBullet", run(colInfo, props...))
+					if r, ok := links.parent(table, col.Name); ok {
+						target := namer.Name(r.Parent.Owner, r.Parent.Name)
+						if r.ParentColumn != "" {
+							target += "." + r.ParentColumn
+						}
+						p.children = append(p.children, run(" → "), links.link(target, "table", r.Parent.Owner, r.Parent.Name))
+					}
+					body.add(p)
+				}
+			}
+			if len(table.RemovedColumns) > 0 {
+				body.add(e.paragraph(e.msg.T("history.removed_columns")+":", "Normal"))
+				for _, col := range table.RemovedColumns {
+					body.add(para("ListBullet", run(fmt.Sprintf("%s (%s)", col.Name, col.DataType), el("w:strike"))))
+				}
+			}
+			body.add(e.paragraph("", "Normal"))
+		}
+	}
+
+	// Routines (NO source code - SECURITY)
+	if len(schema.Routines) > 0 {
+		body.add(e.paragraph(e.msg.T("section.routines"), headingStyle(level)))
+		body.add(e.paragraph(e.msg.T("note.routine_security"), "Normal"))
+		body.add(e.paragraph("", "Normal"))
+
+		for _, routine := range schema.Routines {
+			body.add(links.heading(fmt.Sprintf("%s: %s", routine.Type, namer.Name(routine.Owner, routine.Name)), headingStyle(level+1),
+				"routine", routine.Owner, routine.Name))
+			body.add(e.paragraph(routine.Signature, "Normal"))
+			if routine.Comment != "" {
+				body.add(e.paragraph(routine.Comment, "Normal"))
+			}
+			if len(routine.Touches) > 0 {
+				tables := make([]string, len(routine.Touches))
+				for i, t := range routine.Touches {
+					tables[i] = namer.Name(t.Owner, t.Name)
+				}
+				body.add(e.paragraph(fmt.Sprintf("%s: %s", e.msg.T("label.touches"), strings.Join(tables, ", ")), "Normal"))
+			}
+			if len(routine.Arguments) > 0 {
+				rows := make([][]string, len(routine.Arguments))
+				for i, arg := range routine.Arguments {
+					rows[i] = []string{arg.Name, arg.Mode, arg.DataType, arg.DefaultValue, arg.Comment}
+				}
+				body.add(e.table([]string{e.msg.T("label.name"), e.msg.T("label.mode"),
+					e.msg.T("label.data_type"), e.msg.T("label.default"), e.msg.T("label.comment")}, rows))
+			}
+			body.add(e.paragraph("", "Normal"))
+		}
+	}
+
+	// Triggers (NO definition - SECURITY)
+	if len(schema.Triggers) > 0 {
+		body.add(e.paragraph(e.msg.T("section.triggers"), headingStyle(level)))
+		body.add(e.paragraph(e.msg.T("note.trigger_security"), "Normal"))
+		body.add(e.paragraph("", "Normal"))
+
+		for _, trg := range schema.Triggers {
+			body.add(links.heading(fmt.Sprintf("%s: %s", e.msg.T("object.trigger"), namer.Name(trg.Owner, trg.Name)), headingStyle(level+1),
+				"trigger", trg.Owner, trg.Name))
+			target := namer.Name(trg.Owner, trg.TargetTable)
+			if trg.TargetType == "DATABASE" {
+				target = e.msg.T("label.database_ddl")
+			}
+			body.add(e.paragraph(fmt.Sprintf("%s: %s", e.msg.T("label.target_table"), target), "Normal"))
+			body.add(e.paragraph(fmt.Sprintf("%s: %s, %s: %s, %s: %s",
+				e.msg.T("label.timing"), trg.Timing, e.msg.T("label.event"), trg.Event,
+				e.msg.T("label.status"), trg.Status), "Normal"))
+			if trg.Function != nil {
+				body.add(e.paragraph(fmt.Sprintf("%s: %s", e.msg.T("label.function"), trg.Function), "Normal"))
+			}
+			if trg.Comment != "" {
+				body.add(e.paragraph(trg.Comment, "Normal"))
+			}
+			body.add(e.paragraph("", "Normal"))
+		}
+	}
+
+	// Sequences
+	if len(schema.Sequences) > 0 {
+		body.add(e.paragraph(e.msg.T("section.sequences"), headingStyle(level)))
+		for _, seq := range schema.Sequences {
+			body.add(links.heading(fmt.Sprintf("%s: %s", e.msg.T("object.sequence"), namer.Name(seq.Owner, seq.Name)), headingStyle(level+1),
+				"sequence", seq.Owner, seq.Name))
+			body.add(e.paragraph(fmt.Sprintf("%s: %d ~ %d, %s: %d, %s: %d",
+				e.msg.T("label.range"), seq.MinValue, seq.MaxValue,
+				e.msg.T("label.increment"), seq.Increment,
+				e.msg.T("label.current"), seq.LastNumber), "Normal"))
+			if seq.Comment != "" {
+				body.add(e.paragraph(seq.Comment, "Normal"))
+			}
+			body.add(e.paragraph("", "Normal"))
+		}
+	}
+}
+
+// envColor is the text color of differences between environments
+const envColor = "D35400"
+
+// maxBookmark is the longest bookmark name Word accepts
+const maxBookmark = 40
+
+// linker bookmarks the headings of objects and links foreign key columns
+// to the heading of their parent table. Bookmark names are the object ids
+// of model.Anchors, unique regardless of case as Word requires.
+type linker struct {
+	anchors *model.Anchors
+	graph   *model.Graph
+	next    int // w:id of the next bookmark
+}
+
+// newLinker returns the linker of the objects of schema
+func newLinker(schema *model.Schema) *linker {
+	return &linker{anchors: model.NewAnchors(schema, maxBookmark, true), graph: model.NewGraph(schema)}
+}
+
+// heading returns a heading paragraph in style bookmarked as the object
+// kind owner.name
+func (l *linker) heading(text, style, kind, owner, name string) node {
+	id := strconv.Itoa(l.next)
+	l.next++
+	return para(style,
+		el("w:bookmarkStart").with("w:id", id, "w:name", l.anchors.Next(kind, owner, name)),
+		run(text),
+		el("w:bookmarkEnd").with("w:id", id),
+	)
+}
+
+// link returns a hyperlink with text to the bookmark of the object kind
+// owner.name
+func (l *linker) link(text, kind, owner, name string) node {
+	return el("w:hyperlink", run(text, val("w:rStyle", "Hyperlink"))).
+		with("w:anchor", l.anchors.ID(kind, owner, name), "w:history", "1")
+}
+
+// parent returns the foreign key of a column of table when its parent
+// table is documented
+func (l *linker) parent(table model.Table, column string) (model.Relationship, bool) {
+	r, ok := l.graph.ForeignKey(table.Owner, table.Name, column)
+	return r, ok && r.Parent != nil
+}
+
+// lineage adds the foreign key parents and children of table, a bullet
+// per level linking to their headings (Config.LineageDepth)
+func (e *Exporter) lineage(body *content, links *linker, table *model.Table, namer model.Namer) {
+	t := links.graph.Table(table.Owner, table.Name)
+	if t == nil || e.config.LineageDepth <= 0 {
+		return
+	}
+	up, down := links.graph.Upstream(t, e.config.LineageDepth), links.graph.Downstream(t, e.config.LineageDepth)
+	if len(up) == 0 && len(down) == 0 {
+		return
+	}
+	body.add(e.paragraph(e.msg.T("label.lineage")+":", "Normal"))
+	level := func(label string, tables []*model.Table) {
+		children := []node{run(label + ": ")}
+		for i, other := range tables {
+			if i > 0 {
+				children = append(children, run(", "))
+			}
+			children = append(children, links.link(namer.Name(other.Owner, other.Name), "table", other.Owner, other.Name))
+		}
+		body.add(para("ListBullet", children...))
+	}
+	for i, tables := range up {
+		level("↑ "+e.msg.T("lineage.upstream", i+1), tables)
+	}
+	for i, tables := range down {
+		level("↓ "+e.msg.T("lineage.downstream", i+1), tables)
+	}
+}
+
+// changedSince returns the date of the snapshot the change marks of schema
+// compare against
+func (e *Exporter) changedSince(schema *model.Schema) string {
+	return e.config.Dates.Format(*schema.ChangedSince, "2006-01-02")
+}
+
+// headingStyle returns the paragraph style of a heading level
+func headingStyle(level int) string {
+	return fmt.Sprintf("Heading%d", level)
+}
+
+// writeDocument creates word/document.xml with the given body
+func (e *Exporter) writeDocument(zw *zip.Writer, body content) error {
+	sectPr := el("w:sectPr")
+	if e.config.Classification != "" {
+		sectPr.children = append(sectPr.children, el("w:headerReference").with("w:type", "default", "r:id", "rId2"))
+	}
+	sectPr.children = append(sectPr.children,
+		e.pageSize(),
+		el("w:pgMar").with("w:top", "1440", "w:right", "1440", "w:bottom", "1440", "w:left", "1440"),
+	)
+
+	return writePart(zw, "word/document.xml", el("w:document",
+		el("w:body", append(body, sectPr)...),
+	).with("xmlns:w", nsW, "xmlns:r", nsR))
+}
+
+// pageSize returns the w:pgSz of the configured paper; landscape pages
+// swap width and height and say so in w:orient
+func (e *Exporter) pageSize() node {
+	w, h := e.config.Page.Twips()
+	pgSz := el("w:pgSz").with("w:w", strconv.Itoa(w), "w:h", strconv.Itoa(h))
+	if e.config.Page.IsLandscape() {
+		pgSz = pgSz.with("w:orient", page.Landscape)
+	}
+	return pgSz
+}
+
+// watermarkFormulas are the formulas of Word's text watermark shape type
+var watermarkFormulas = []string{
+	"sum #0 0 10800", "prod #0 2 1", "sum 21600 0 @1", "sum 0 0 @2", "sum 21600 0 @3",
+	"if @0 @3 0", "if @0 21600 @1", "if @0 0 @2", "if @0 @4 21600", "mid @5 @6",
+	"mid @8 @5", "mid @7 @8", "mid @6 @7", "sum @6 0 @5",
+}
+
+// writeHeader creates word/header1.xml with the classification label as a
+// centered page header and a diagonal text watermark (VML, as Word emits it)
+func (e *Exporter) writeHeader(zw *zip.Writer) error {
+	label := e.config.Classification
+
+	formulas := make([]node, len(watermarkFormulas))
+	for i, eqn := range watermarkFormulas {
+		formulas[i] = el("v:f").with("eqn", eqn)
+	}
+	shapeType := el("v:shapetype",
+		el("v:formulas", formulas...),
+		el("v:path").with("textpathok", "t", "o:connecttype", "custom"),
+		el("v:textpath").with("on", "t", "fitshape", "t"),
+		el("o:lock").with("v:ext", "edit", "text", "t", "shapetype", "t"),
+	).with("id", "_x0000_t136", "coordsize", "21600,21600", "o:spt", "136", "adj", "10800",
+		"path", "m@7,l@8,m@5,21600l@6,21600e")
+	shape := el("v:shape",
+		el("v:fill").with("opacity", ".5"),
+		el("v:textpath").with("style", `font-family:"`+fonts.Clean(e.config.Font.EastAsian)+`";font-size:1pt`, "string", label),
+	).with("id", "ClassificationWatermark", "type", "#_x0000_t136",
+		"style", "position:absolute;margin-left:0;margin-top:0;width:468pt;height:117pt;rotation:315;z-index:-251654144;mso-position-horizontal:center;mso-position-horizontal-relative:margin;mso-position-vertical:center;mso-position-vertical-relative:margin",
+		"o:allowincell", "f", "fillcolor", "silver", "stroked", "f")
+
+	return writePart(zw, "word/header1.xml", el("w:hdr",
+		el("w:p",
+			el("w:pPr", val("w:jc", "center")),
+			run(label, el("w:b"), val("w:color", "C0392B")),
+			el("w:r", el("w:pict", shapeType, shape)),
+		),
+	).with("xmlns:w", nsW, "xmlns:v", nsV, "xmlns:o", nsO))
+}
+
+// paragraph creates a Word paragraph with specified style
+func (e *Exporter) paragraph(text, style string) node {
+	return para(style, run(text))
+}
+
+// table creates a bordered Word table with a bold header row
+func (e *Exporter) table(header []string, rows [][]string) node {
+	return grid(header, rows)
+}
+
+// impactColumns is the number of tables per impact matrix table, which
+// fit a portrait page
+const impactColumns = 8
+
+// impactTables adds the impact matrix as tables of impactColumns base
+// tables each, the views as rows and the number of views using each table
+// in a last row
+func (e *Exporter) impactTables(body *content, m *impact.Matrix, namer model.Namer) {
+	for first := 0; first < len(m.Tables); first += impactColumns {
+		last := min(first+impactColumns, len(m.Tables))
+		header := []string{e.msg.T("label.view")}
+		total := []string{e.msg.T("impact.views")}
+		for _, c := range m.Tables[first:last] {
+			header = append(header, namer.Name(c.Table.Owner, c.Table.Name))
+			total = append(total, strconv.Itoa(c.Views))
+		}
+		var rows [][]string
+		for _, r := range m.Rows {
+			row := []string{namer.Name(r.View.Owner, r.View.Name)}
+			used := false
+			for _, uses := range r.Uses[first:last] {
+				cell := ""
+				if uses {
+					cell, used = "●", true
+				}
+				row = append(row, cell)
+			}
+			// Views using none of these tables are left out of this part
+			if used {
+				rows = append(rows, row)
+			}
+		}
+		body.add(e.table(header, append(rows, total)))
+		body.add(e.paragraph("", "Normal"))
+	}
+}
+
+// bulletNumID is the numbering instance of the ListBullet style
+const bulletNumID = "1"
+
+// writeNumbering creates word/numbering.xml with the bullet list used by
+// the ListBullet paragraph style
+func (e *Exporter) writeNumbering(zw *zip.Writer) error {
+	return writePart(zw, "word/numbering.xml", el("w:numbering",
+		el("w:abstractNum",
+			el("w:lvl",
+				val("w:start", "1"),
+				val("w:numFmt", "bullet"),
+				val("w:lvlText", "•"),
+				val("w:lvlJc", "left"),
+				el("w:pPr", el("w:ind").with("w:left", "720", "w:hanging", "360")),
+			).with("w:ilvl", "0"),
+		).with("w:abstractNumId", "0"),
+		el("w:num", val("w:abstractNumId", "0")).with("w:numId", bulletNumID),
+	).with("xmlns:w", nsW))
+}
+
+// paragraphStyle is a paragraph style of styles.xml
+type paragraphStyle struct {
+	id, name string
+	size     string // half-points
+	bold     bool
+	color    string
+	before   string // spacing in twentieths of a point; "" for none
+	after    string
+	bullet   bool // numbered with the bullet list
+}
+
+// paragraphStyles are the styles paragraphs refer to (Paragraph.Style)
+var paragraphStyles = []paragraphStyle{
+	{id: "Normal", name: "Normal", size: "22"},
+	{id: "Title", name: "Title", size: "56", bold: true, color: "2E74B5"},
+	{id: "Heading1", name: "Heading 1", size: "32", bold: true, color: "2E74B5", before: "480", after: "240"},
+	{id: "Heading2", name: "Heading 2", size: "28", bold: true, color: "2E74B5", before: "360", after: "180"},
+	{id: "Heading3", name: "Heading 3", size: "24", bold: true, color: "1F4D78", before: "240", after: "120"},
+	{id: "Heading4", name: "Heading 4", size: "22", bold: true, color: "1F4D78", before: "200", after: "80"},
+	{id: "ListBullet", name: "List Bullet", size: "22", bullet: true},
+}
+
+// writeStyles creates word/styles.xml with the configured fonts
+func (e *Exporter) writeStyles(zw *zip.Writer) error {
+	// CRITICAL: Korean font support - the East Asian font (Malgun Gothic
+	// unless configured) applies to Hangul, kana and ideographs
+	latin, eastAsian := fonts.Clean(e.config.Font.Latin), fonts.Clean(e.config.Font.EastAsian)
+	runFonts := func() node {
+		return el("w:rFonts").with("w:ascii", latin, "w:hAnsi", latin, "w:eastAsia", eastAsian)
+	}
+
+	styles := el("w:styles",
+		el("w:docDefaults",
+			el("w:rPrDefault",
+				el("w:rPr",
+					runFonts().with("w:cs", latin),
+					val("w:sz", "22"),
+					val("w:szCs", "22"),
+				),
+			),
+		),
+	).with("xmlns:w", nsW)
+
+	for _, s := range paragraphStyles {
+		style := el("w:style", val("w:name", s.name)).with("w:type", "paragraph", "w:styleId", s.id)
+		if s.id != "Normal" {
+			style.children = append(style.children, val("w:basedOn", "Normal"))
+		}
+		style.children = append(style.children, el("w:qFormat"))
+
+		rPr := el("w:rPr", runFonts())
+		if s.bold {
+			rPr.children = append(rPr.children, el("w:b"))
+		}
+		if s.color != "" {
+			rPr.children = append(rPr.children, val("w:color", s.color))
+		}
+		rPr.children = append(rPr.children, val("w:sz", s.size))
+
+		pPr := el("w:pPr")
+		if s.bullet {
+			pPr.children = append(pPr.children, el("w:numPr", val("w:ilvl", "0"), val("w:numId", bulletNumID)))
+		}
+		if s.before != "" {
+			pPr.children = append(pPr.children, el("w:spacing").with("w:before", s.before, "w:after", s.after))
+		}
+		if len(pPr.children) > 0 {
+			style.children = append(style.children, pPr)
+		}
+		style.children = append(style.children, rPr)
+		styles.children = append(styles.children, style)
+	}
+
+	// Character style of the links to bookmarked objects
+	styles.children = append(styles.children, el("w:style",
+		val("w:name", "Hyperlink"),
+		el("w:rPr", val("w:color", "0563C1"), val("w:u", "single")),
+	).with("w:type", "character", "w:styleId", "Hyperlink"))
+	return writePart(zw, "word/styles.xml", styles)
+}
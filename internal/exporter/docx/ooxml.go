@@ -0,0 +1,174 @@
+package docx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// OOXML namespaces and relationship types used by the generated parts
+const (
+	nsW          = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	nsR          = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	nsV          = "urn:schemas-microsoft-com:vml"
+	nsO          = "urn:schemas-microsoft-com:office:office"
+	nsTypes      = "http://schemas.openxmlformats.org/package/2006/content-types"
+	nsRels       = "http://schemas.openxmlformats.org/package/2006/relationships"
+	relDocument  = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument"
+	relStyles    = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles"
+	relNumbering = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering"
+	relHeader    = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/header"
+)
+
+// xmlDeclaration starts every part
+const xmlDeclaration = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+// node is an XML element of a package part. Parts are built as trees and
+// serialized by write, so every text and attribute value is escaped the
+// same way: markup characters become entities and characters XML 1.0 does
+// not allow (control characters in comments, ...) become U+FFFD.
+type node struct {
+	name     string
+	attrs    []string // name, value pairs
+	text     string   // character data, written before the children
+	children []node
+}
+
+// el returns the element name with children
+func el(name string, children ...node) node {
+	return node{name: name, children: children}
+}
+
+// textEl returns the element name holding text
+func textEl(name, text string) node {
+	return node{name: name, text: text}
+}
+
+// val returns the common <name w:val="value"/> element
+func val(name, value string) node {
+	return el(name).with("w:val", value)
+}
+
+// with returns n with the attributes given as name, value pairs added
+func (n node) with(pairs ...string) node {
+	n.attrs = append(append([]string(nil), n.attrs...), pairs...)
+	return n
+}
+
+// write serializes n to b
+func (n node) write(b *strings.Builder) {
+	b.WriteByte('<')
+	b.WriteString(n.name)
+	for i := 0; i+1 < len(n.attrs); i += 2 {
+		b.WriteByte(' ')
+		b.WriteString(n.attrs[i])
+		b.WriteString(`="`)
+		escapeTo(b, n.attrs[i+1])
+		b.WriteByte('"')
+	}
+	if n.text == "" && len(n.children) == 0 {
+		b.WriteString("/>")
+		return
+	}
+	b.WriteByte('>')
+	escapeTo(b, n.text)
+	for _, c := range n.children {
+		c.write(b)
+	}
+	b.WriteString("</")
+	b.WriteString(n.name)
+	b.WriteByte('>')
+}
+
+// escapeTo writes s escaped for text and attribute values
+func escapeTo(b *strings.Builder, s string) {
+	// EscapeText only fails when the writer does
+	_ = xml.EscapeText(b, []byte(s))
+}
+
+// writePart writes a package part with root as its document element
+func writePart(zw *zip.Writer, name string, root node) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	b.WriteString(xmlDeclaration)
+	root.write(&b)
+	_, err = io.WriteString(f, b.String())
+	return err
+}
+
+// run returns a text run; line breaks become <w:br/> and tabs <w:tab/>,
+// which Word would otherwise show as spaces
+func run(text string, props ...node) node {
+	r := el("w:r")
+	if len(props) > 0 {
+		r.children = append(r.children, el("w:rPr", props...))
+	}
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if i > 0 {
+			r.children = append(r.children, el("w:br"))
+		}
+		for j, part := range strings.Split(line, "\t") {
+			if j > 0 {
+				r.children = append(r.children, el("w:tab"))
+			}
+			if part != "" {
+				r.children = append(r.children, textEl("w:t", part).with("xml:space", "preserve"))
+			}
+		}
+	}
+	return r
+}
+
+// para returns a paragraph in style with the given runs
+func para(style string, runs ...node) node {
+	p := el("w:p")
+	if style != "" {
+		p.children = append(p.children, el("w:pPr", val("w:pStyle", style)))
+	}
+	p.children = append(p.children, runs...)
+	return p
+}
+
+// grid returns a bordered table filling the page width: a bold header row,
+// then rows of plain text cells
+func grid(header []string, rows [][]string) node {
+	border := func(side string) node {
+		return val(side, "single").with("w:sz", "4", "w:color", "BFBFBF")
+	}
+	cols := make([]node, len(header))
+	for i := range cols {
+		cols[i] = el("w:gridCol")
+	}
+	tbl := el("w:tbl",
+		el("w:tblPr",
+			el("w:tblW").with("w:w", "5000", "w:type", "pct"),
+			el("w:tblBorders", border("w:top"), border("w:left"), border("w:bottom"),
+				border("w:right"), border("w:insideH"), border("w:insideV")),
+		),
+		el("w:tblGrid", cols...),
+	)
+	row := func(cells []string, props ...node) node {
+		tr := el("w:tr")
+		for _, cell := range cells {
+			tr.children = append(tr.children, el("w:tc", para("", run(cell, props...))))
+		}
+		return tr
+	}
+	tbl.children = append(tbl.children, row(header, el("w:b")))
+	for _, cells := range rows {
+		tbl.children = append(tbl.children, row(cells))
+	}
+	return tbl
+}
+
+// content collects the paragraphs and tables of a document body
+type content []node
+
+// add appends a paragraph or table
+func (c *content) add(n node) {
+	*c = append(*c, n)
+}
@@ -0,0 +1,105 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"pocket-doc/internal/erd"
+	"strconv"
+)
+
+// DrawingML namespaces and the extension of SVG pictures (Word 2016 and
+// later show the SVG and older versions the PNG)
+const (
+	nsWP       = "http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"
+	nsA        = "http://schemas.openxmlformats.org/drawingml/2006/main"
+	nsPic      = "http://schemas.openxmlformats.org/drawingml/2006/picture"
+	nsSVG      = "http://schemas.microsoft.com/office/drawing/2016/SVG/main"
+	svgBlipExt = "{96DAC541-7B7A-43D3-8B79-37D633B846F1}"
+	relImage   = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/image"
+
+	emuPerPixel = 9525 // at 96 dpi
+	emuPerTwip  = 635
+	pageMargin  = 1440 // twips on every side, see writeDocument
+)
+
+// picture is an image of the document body, stored as
+// word/media/image<n>.png and .svg
+type picture struct {
+	png, svg      []byte
+	width, height float64 // pixels
+}
+
+// diagramPicture renders an entity relationship diagram
+func diagramPicture(d *erd.Diagram, font string) (picture, error) {
+	var png, svg bytes.Buffer
+	if err := d.PNG(&png); err != nil {
+		return picture{}, fmt.Errorf("failed to render diagram: %w", err)
+	}
+	if err := d.SVG(&svg, font); err != nil {
+		return picture{}, fmt.Errorf("failed to render diagram: %w", err)
+	}
+	return picture{png: png.Bytes(), svg: svg.Bytes(), width: d.Width, height: d.Height}, nil
+}
+
+// relIDs returns the relationship ids of the nth picture (from 1)
+func relIDs(n int) (png, svg string) {
+	return "rIdImg" + strconv.Itoa(n), "rIdSvg" + strconv.Itoa(n)
+}
+
+// drawing returns the centered paragraph showing the nth picture (from
+// 1), scaled down to fit within the page margins
+func (e *Exporter) drawing(n int, p picture, name string) node {
+	w, h := e.config.Page.Twips()
+	maxCX, maxCY := float64((w-2*pageMargin)*emuPerTwip), float64((h-2*pageMargin)*emuPerTwip)
+	cx, cy := p.width*emuPerPixel, p.height*emuPerPixel
+	scale := min(1, maxCX/cx, maxCY/cy)
+	ext := func(name string) node {
+		return el(name).with("cx", strconv.Itoa(int(cx*scale)), "cy", strconv.Itoa(int(cy*scale)))
+	}
+
+	pngID, svgID := relIDs(n)
+	blip := el("a:blip",
+		el("a:extLst",
+			el("a:ext", el("asvg:svgBlip").with("xmlns:asvg", nsSVG, "r:embed", svgID)).with("uri", svgBlipExt),
+		),
+	).with("r:embed", pngID)
+	pic := el("pic:pic",
+		el("pic:nvPicPr", el("pic:cNvPr").with("id", "0", "name", fmt.Sprintf("image%d.png", n)), el("pic:cNvPicPr")),
+		el("pic:blipFill", blip, el("a:stretch", el("a:fillRect"))),
+		el("pic:spPr",
+			el("a:xfrm", el("a:off").with("x", "0", "y", "0"), ext("a:ext")),
+			el("a:prstGeom", el("a:avLst")).with("prst", "rect"),
+		),
+	).with("xmlns:pic", nsPic)
+	inline := el("wp:inline",
+		ext("wp:extent"),
+		el("wp:docPr").with("id", strconv.Itoa(n), "name", name),
+		el("a:graphic", el("a:graphicData", pic).with("uri", nsPic)).with("xmlns:a", nsA),
+	).with("distT", "0", "distB", "0", "distL", "0", "distR", "0", "xmlns:wp", nsWP)
+
+	return el("w:p",
+		el("w:pPr", val("w:jc", "center")),
+		el("w:r", el("w:drawing", inline)),
+	)
+}
+
+// writeMedia stores the pictures as word/media/image<n>.png and .svg
+func writeMedia(zw *zip.Writer, pictures []picture) error {
+	for i, p := range pictures {
+		files := []struct {
+			ext  string
+			data []byte
+		}{{"png", p.png}, {"svg", p.svg}}
+		for _, file := range files {
+			f, err := zw.Create(fmt.Sprintf("word/media/image%d.%s", i+1, file.ext))
+			if err != nil {
+				return err
+			}
+			if _, err := f.Write(file.data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"pocket-doc/internal/erd"
+	"pocket-doc/internal/fonts"
+	"pocket-doc/internal/i18n"
+	"pocket-doc/internal/model"
+	"io"
+)
+
+// erdExporter writes the entity relationship diagram of the foreign keys
+// as an SVG document or a PNG image (see package erd)
+type erdExporter struct {
+	font             fonts.Config
+	hideSingleSchema bool
+	png              bool
+}
+
+// newERD builds the built-in SVG diagram exporter
+func newERD(cfg Config) (Exporter, error) {
+	return newERDExporter(cfg, false), nil
+}
+
+// newERDPNG builds the built-in PNG diagram exporter
+func newERDPNG(cfg Config) (Exporter, error) {
+	return newERDExporter(cfg, true), nil
+}
+
+func newERDExporter(cfg Config, png bool) *erdExporter {
+	return &erdExporter{
+		font:             cfg.Font.Resolve(i18n.New(cfg.Language).Language()),
+		hideSingleSchema: cfg.HideSingleSchema,
+		png:              png,
+	}
+}
+
+// Export lays out and renders the diagram; it fails when no documented
+// tables are related by a foreign key
+func (e *erdExporter) Export(ctx context.Context, schema *model.Schema, w io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	d := erd.Layout(schema, model.NewNamer(schema, e.hideSingleSchema))
+	if d.Empty() {
+		return errors.New("no foreign keys between the documented tables to draw")
+	}
+	if e.png {
+		return d.PNG(w)
+	}
+	return d.SVG(w, e.font.CSS())
+}
+
+// Format returns the format name
+func (e *erdExporter) Format() string {
+	if e.png {
+		return "erd-png"
+	}
+	return "erd"
+}
+
+// MimeType returns the MIME type
+func (e *erdExporter) MimeType() string {
+	if e.png {
+		return "image/png"
+	}
+	return "image/svg+xml"
+}
+
+// FileExtension returns the file extension
+func (e *erdExporter) FileExtension() string {
+	if e.png {
+		return ".png"
+	}
+	return ".svg"
+}
@@ -1,50 +1,183 @@
-﻿package exporter
-
-import (
-	"pocket-doc/internal/exporter/docx"
-	"pocket-doc/internal/exporter/html"
-	"pocket-doc/internal/exporter/xlsx"
-	"fmt"
-	"strings"
-)
-
-// NewExporter creates an exporter for the specified format
-// Use format-specific config structs (xlsx.Config or docx.Config)
-func NewExporter(format string, cfg Config) (Exporter, error) {
-	format = strings.ToLower(strings.TrimSpace(format))
-
-	switch format {
-	case "xlsx", "excel":
-		xlsxCfg := xlsx.Config{
-			Language:     cfg.Language,
-			ExcludeTypes: cfg.ExcludeTypes,
-			ColorScheme:  cfg.ColorScheme,
-		}
-		return xlsx.NewExporter(xlsxCfg), nil
-	case "docx", "word":
-		docxCfg := docx.Config{
-			Language:         cfg.Language,
-			IncludeTOC:       cfg.IncludeTOC,
-			IncludeCoverPage: cfg.IncludeCoverPage,
-			CompanyName:      cfg.CompanyName,
-			ProjectName:      cfg.ProjectName,
-			Author:           cfg.Author,
-			ExcludeTypes:     cfg.ExcludeTypes,
-			ColorScheme:      cfg.ColorScheme,
-		}
-		return docx.NewExporter(docxCfg), nil
-	case "html":
-		htmlCfg := html.Config{
-			Language: cfg.Language,
-			Title:    "Schema Documentation",
-		}
-		return html.NewExporter(htmlCfg), nil
-	default:
-		return nil, fmt.Errorf("unsupported export format: %s (supported: xlsx, docx, html)", format)
-	}
-}
-
-// GetSupportedFormats returns a list of supported export formats
-func GetSupportedFormats() []string {
-	return []string{"xlsx", "docx", "html"}
-}
+﻿package exporter
+
+import (
+	"pocket-doc/internal/exporter/docx"
+	"pocket-doc/internal/exporter/html"
+	"pocket-doc/internal/exporter/xlsx"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Factory creates an exporter from the common exporter configuration
+type Factory func(cfg Config) (Exporter, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+	formats    []string // canonical format names in registration order
+)
+
+func init() {
+	Register("xlsx", newXLSX, "excel")
+	Register("docx", newDOCX, "word")
+	Register("html", newHTML)
+	Register("coverage", newCoverage)
+	Register("lint", newLint)
+	Register("pii", newPII)
+	Register("relationships", newRelationships)
+	Register("erd", newERD, "svg")
+	Register("erd-png", newERDPNG, "png")
+	Register("json", newJSON, "snapshot")
+}
+
+// Register makes an export format available to NewExporter under name and
+// any aliases. Names are case-insensitive. Like database/sql.Register, it
+// panics if factory is nil or a name is already registered, so it is meant
+// to be called from init functions.
+func Register(name string, factory Factory, aliases ...string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if factory == nil {
+		panic("exporter: Register factory is nil for " + name)
+	}
+
+	for _, n := range append([]string{name}, aliases...) {
+		n = normalizeFormat(n)
+		if _, dup := registry[n]; dup {
+			panic("exporter: Register called twice for format " + n)
+		}
+		registry[n] = factory
+	}
+	formats = append(formats, normalizeFormat(name))
+}
+
+// NewExporter creates an exporter for the specified format
+// Use format-specific config structs (xlsx.Config or docx.Config)
+func NewExporter(format string, cfg Config) (Exporter, error) {
+	format = normalizeFormat(format)
+
+	registryMu.RLock()
+	factory, ok := registry[format]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported export format: %s (supported: %s)",
+			format, strings.Join(GetSupportedFormats(), ", "))
+	}
+
+	exp, err := factory(cfg)
+	if err != nil {
+		return nil, err
+	}
+	exp, err = withSorting(exp, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return withLogging(exp, cfg.Logger), nil
+}
+
+// GetSupportedFormats returns a list of supported export formats
+func GetSupportedFormats() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return append([]string(nil), formats...)
+}
+
+// IsSupported reports whether format (or one of its aliases) is registered
+func IsSupported(format string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	_, ok := registry[normalizeFormat(format)]
+	return ok
+}
+
+// normalizeFormat lower-cases and trims a format name
+func normalizeFormat(format string) string {
+	return strings.ToLower(strings.TrimSpace(format))
+}
+
+// newXLSX builds the built-in Excel exporter
+func newXLSX(cfg Config) (Exporter, error) {
+	xlsxCfg := xlsx.Config{
+		Language:         cfg.Language,
+		ExcludeTypes:     cfg.ExcludeTypes,
+		ColorScheme:      cfg.ColorScheme,
+		Classification:   cfg.Classification,
+		IncludeCoverage:  cfg.IncludeCoverage,
+		IncludeQuality:   cfg.IncludeQuality,
+		IncludeCleanup:   cfg.IncludeCleanup,
+		IncludeNaming:    cfg.IncludeNaming,
+		IncludeImpact:    cfg.IncludeImpact,
+		HideSingleSchema: cfg.HideSingleSchema,
+		MinColumnWidth:   cfg.MinColumnWidth,
+		MaxColumnWidth:   cfg.MaxColumnWidth,
+		GroupBySchema:    cfg.Grouped(),
+		Dates:            cfg.Dates,
+		Page:             cfg.Page.For("xlsx"),
+	}
+	return xlsx.NewExporter(xlsxCfg), nil
+}
+
+// newDOCX builds the built-in Word exporter
+func newDOCX(cfg Config) (Exporter, error) {
+	docxCfg := docx.Config{
+		Language:         cfg.Language,
+		IncludeTOC:       cfg.IncludeTOC,
+		IncludeCoverPage: cfg.IncludeCoverPage,
+		CompanyName:      cfg.CompanyName,
+		ProjectName:      cfg.ProjectName,
+		Author:           cfg.Author,
+		ExcludeTypes:     cfg.ExcludeTypes,
+		ColorScheme:      cfg.ColorScheme,
+		Classification:   cfg.Classification,
+		IncludeCoverage:  cfg.IncludeCoverage,
+		IncludeQuality:   cfg.IncludeQuality,
+		IncludeCleanup:   cfg.IncludeCleanup,
+		IncludeNaming:    cfg.IncludeNaming,
+		IncludeImpact:    cfg.IncludeImpact,
+		LineageDepth:     cfg.LineageDepth,
+		IncludeERD:       cfg.IncludeERD,
+		HideSingleSchema: cfg.HideSingleSchema,
+		Font:             cfg.Font,
+		GroupBySchema:    cfg.Grouped(),
+		GroupByDomain:    cfg.GroupedByDomain(),
+		Dates:            cfg.Dates,
+		Page:             cfg.Page.For("docx"),
+	}
+	return docx.NewExporter(docxCfg), nil
+}
+
+// newHTML builds the built-in HTML exporter
+func newHTML(cfg Config) (Exporter, error) {
+	htmlCfg := html.Config{
+		Language:         cfg.Language,
+		Title:            "Schema Documentation",
+		IncludeCoverPage: cfg.IncludeCoverPage,
+		IncludeTOC:       cfg.IncludeTOC,
+		CompanyName:      cfg.CompanyName,
+		ProjectName:      cfg.ProjectName,
+		Author:           cfg.Author,
+		TemplatePath:     cfg.Template,
+		CSSPath:          cfg.CSSFile,
+		PageBreak:        cfg.PageBreak,
+		Classification:   cfg.Classification,
+		IncludeCoverage:  cfg.IncludeCoverage,
+		IncludeQuality:   cfg.IncludeQuality,
+		IncludeCleanup:   cfg.IncludeCleanup,
+		IncludeNaming:    cfg.IncludeNaming,
+		IncludeImpact:    cfg.IncludeImpact,
+		LineageDepth:     cfg.LineageDepth,
+		IncludeERD:       cfg.IncludeERD,
+		HideSingleSchema: cfg.HideSingleSchema,
+		Font:             cfg.Font,
+		GroupBySchema:    cfg.Grouped(),
+		GroupByDomain:    cfg.GroupedByDomain(),
+		Dates:            cfg.Dates,
+		Page:             cfg.Page.For("html"),
+	}
+	return html.NewExporter(htmlCfg), nil
+}
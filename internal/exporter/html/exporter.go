@@ -1,397 +1,1431 @@
-﻿package html
-
-import (
-	"pocket-doc/internal/model"
-	"html/template"
-	"io"
-)
-
-// Config holds configuration for HTML export
-type Config struct {
-	Language string
-	Title    string
-}
-
-// Exporter implements HTML export functionality
-type Exporter struct {
-	config Config
-}
-
-// NewExporter creates a new HTML exporter
-func NewExporter(cfg Config) *Exporter {
-	return &Exporter{config: cfg}
-}
-
-// Format returns the format name
-func (e *Exporter) Format() string {
-	return "html"
-}
-
-// MimeType returns the MIME type
-func (e *Exporter) MimeType() string {
-	return "text/html; charset=utf-8"
-}
-
-// FileExtension returns the file extension
-func (e *Exporter) FileExtension() string {
-	return ".html"
-}
-
-// Export generates an HTML document with print-optimized CSS
-// CRITICAL RULE #3: Korean fonts FIRST + @media print rules
-func (e *Exporter) Export(schema *model.Schema, w io.Writer) error {
-	tmpl := template.Must(template.New("schema").Parse(htmlTemplate))
-	return tmpl.Execute(w, schema)
-}
-
-// htmlTemplate with Korean font support and print CSS (CRITICAL RULES)
-const htmlTemplate = `<!DOCTYPE html>
-<html lang="ko">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>{{.DatabaseName}} - 스키마 문서</title>
-    <style>
-        /* CRITICAL RULE #3: Korean Fonts FIRST */
-        * {
-            font-family: 'Malgun Gothic', 'Apple SD Gothic Neo', 'Noto Sans KR', 
-                         -apple-system, BlinkMacSystemFont, 'Segoe UI', 
-                         Arial, sans-serif;
-            box-sizing: border-box;
-        }
-
-        body {
-            margin: 0;
-            padding: 20px;
-            background: #f5f5f5;
-            color: #333;
-            line-height: 1.6;
-        }
-
-        .container {
-            max-width: 1200px;
-            margin: 0 auto;
-            background: white;
-            padding: 40px;
-            box-shadow: 0 2px 10px rgba(0,0,0,0.1);
-        }
-
-        h1 {
-            color: #2c3e50;
-            border-bottom: 3px solid #3498db;
-            padding-bottom: 10px;
-            margin-bottom: 30px;
-        }
-
-        h2 {
-            color: #34495e;
-            border-bottom: 2px solid #95a5a6;
-            padding-bottom: 8px;
-            margin-top: 40px;
-            margin-bottom: 20px;
-        }
-
-        h3 {
-            color: #7f8c8d;
-            margin-top: 30px;
-            margin-bottom: 15px;
-        }
-
-        table {
-            width: 100%;
-            border-collapse: collapse;
-            margin-bottom: 30px;
-            background: white;
-        }
-
-        th {
-            background: #D9D9D9;
-            color: #333;
-            font-weight: bold;
-            text-align: left;
-            padding: 12px;
-            border: 1px solid #bdc3c7;
-        }
-
-        td {
-            padding: 10px 12px;
-            border: 1px solid #ecf0f1;
-        }
-
-        tr:nth-child(even) {
-            background: #f9f9f9;
-        }
-
-        tr:hover {
-            background: #e8f4f8;
-        }
-
-        .badge {
-            display: inline-block;
-            padding: 3px 8px;
-            border-radius: 3px;
-            font-size: 11px;
-            font-weight: bold;
-        }
-
-        .badge-pk { background: #2ecc71; color: white; }
-        .badge-fk { background: #3498db; color: white; }
-        .badge-uk { background: #f39c12; color: white; }
-
-        .summary {
-            display: grid;
-            grid-template-columns: repeat(auto-fit, minmax(200px, 1fr));
-            gap: 15px;
-            margin-bottom: 30px;
-        }
-
-        .summary-card {
-            background: #ecf0f1;
-            padding: 15px;
-            border-radius: 5px;
-            border-left: 4px solid #3498db;
-        }
-
-        .summary-card h3 {
-            margin: 0 0 5px 0;
-            font-size: 14px;
-            color: #7f8c8d;
-        }
-
-        .summary-card .value {
-            font-size: 24px;
-            font-weight: bold;
-            color: #2c3e50;
-        }
-
-        /* CRITICAL RULE #3: @media print CSS */
-        @media print {
-            @page {
-                size: A4;
-                margin: 2cm;
-            }
-
-            body {
-                background: white;
-                padding: 0;
-            }
-
-            .container {
-                box-shadow: none;
-                padding: 0;
-            }
-
-            /* Page breaks for major sections */
-            h1, h2 {
-                page-break-before: always;
-            }
-
-            h1:first-of-type, h2:first-of-type {
-                page-break-before: avoid;
-            }
-
-            /* Keep headings with content */
-            h3, h4 {
-                page-break-after: avoid;
-            }
-
-            /* Avoid breaking tables */
-            table {
-                page-break-inside: avoid;
-            }
-
-            tr {
-                page-break-inside: avoid;
-            }
-
-            /* Hide interactive elements */
-            .no-print {
-                display: none;
-            }
-        }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <h1>{{.DatabaseName}} - 데이터베이스 스키마 문서</h1>
-
-        <div class="summary">
-            <div class="summary-card">
-                <h3>데이터베이스 유형</h3>
-                <div class="value">{{.DatabaseType}}</div>
-            </div>
-            <div class="summary-card">
-                <h3>버전</h3>
-                <div class="value">{{.Version}}</div>
-            </div>
-            <div class="summary-card">
-                <h3>테이블 수</h3>
-                <div class="value">{{len .Tables}}</div>
-            </div>
-            <div class="summary-card">
-                <h3>뷰 수</h3>
-                <div class="value">{{len .Views}}</div>
-            </div>
-            <div class="summary-card">
-                <h3>프로시저/함수 수</h3>
-                <div class="value">{{len .Routines}}</div>
-            </div>
-            <div class="summary-card">
-                <h3>트리거 수</h3>
-                <div class="value">{{len .Triggers}}</div>
-            </div>
-        </div>
-
-        {{if .Tables}}
-        <h2>📋 테이블 목록</h2>
-        <table>
-            <thead>
-                <tr>
-                    <th>이름</th>
-                    <th>소유자</th>
-                    <th>행 수</th>
-                    <th>설명</th>
-                </tr>
-            </thead>
-            <tbody>
-                {{range .Tables}}
-                <tr>
-                    <td><strong>{{.Name}}</strong></td>
-                    <td>{{.Owner}}</td>
-                    <td>{{.RowCount}}</td>
-                    <td>{{.Comment}}</td>
-                </tr>
-                {{end}}
-            </tbody>
-        </table>
-
-        {{range .Tables}}
-        <h3>테이블: {{.Name}}</h3>
-        {{if .Comment}}<p><em>{{.Comment}}</em></p>{{end}}
-        
-        <table>
-            <thead>
-                <tr>
-                    <th>컬럼명</th>
-                    <th>데이터타입</th>
-                    <th>NULL허용</th>
-                    <th>제약조건</th>
-                    <th>기본값</th>
-                    <th>설명</th>
-                </tr>
-            </thead>
-            <tbody>
-                {{range .Columns}}
-                <tr>
-                    <td><strong>{{.Name}}</strong></td>
-                    <td>{{.DataType}}</td>
-                    <td>{{if .Nullable}}YES{{else}}NO{{end}}</td>
-                    <td>
-                        {{if .IsPrimaryKey}}<span class="badge badge-pk">PK</span>{{end}}
-                        {{if .IsForeignKey}}<span class="badge badge-fk">FK</span>{{end}}
-                        {{if .IsUnique}}<span class="badge badge-uk">UK</span>{{end}}
-                    </td>
-                    <td>{{.DefaultValue}}</td>
-                    <td>{{.Comment}}</td>
-                </tr>
-                {{end}}
-            </tbody>
-        </table>
-        {{end}}
-        {{end}}
-
-        {{if .Routines}}
-        <h2>⚙️ 프로시저 / 함수</h2>
-        <table>
-            <thead>
-                <tr>
-                    <th>이름</th>
-                    <th>유형</th>
-                    <th>서명</th>
-                    <th>설명</th>
-                </tr>
-            </thead>
-            <tbody>
-                {{range .Routines}}
-                <tr>
-                    <td><strong>{{.Name}}</strong></td>
-                    <td>{{.Type}}</td>
-                    <td><code>{{.Signature}}</code></td>
-                    <td>{{.Comment}}</td>
-                </tr>
-                {{end}}
-            </tbody>
-        </table>
-        <p style="color: #7f8c8d; font-size: 12px;">
-            ⚠️ 보안: 프로시저 본문은 제외되었습니다 (서명만 표시)
-        </p>
-        {{end}}
-
-        {{if .Triggers}}
-        <h2>🔔 트리거</h2>
-        <table>
-            <thead>
-                <tr>
-                    <th>이름</th>
-                    <th>대상 테이블</th>
-                    <th>시점</th>
-                    <th>이벤트</th>
-                    <th>상태</th>
-                    <th>설명</th>
-                </tr>
-            </thead>
-            <tbody>
-                {{range .Triggers}}
-                <tr>
-                    <td><strong>{{.Name}}</strong></td>
-                    <td>{{.TargetTable}}</td>
-                    <td>{{.Timing}}</td>
-                    <td>{{.Event}}</td>
-                    <td>{{.Status}}</td>
-                    <td>{{.Comment}}</td>
-                </tr>
-                {{end}}
-            </tbody>
-        </table>
-        <p style="color: #7f8c8d; font-size: 12px;">
-            ⚠️ 보안: 트리거 정의는 제외되었습니다 (메타데이터만 표시)
-        </p>
-        {{end}}
-
-        {{if .Sequences}}
-        <h2>🔢 시퀀스</h2>
-        <table>
-            <thead>
-                <tr>
-                    <th>이름</th>
-                    <th>최소값</th>
-                    <th>최대값</th>
-                    <th>증가값</th>
-                    <th>현재값</th>
-                    <th>설명</th>
-                </tr>
-            </thead>
-            <tbody>
-                {{range .Sequences}}
-                <tr>
-                    <td><strong>{{.Name}}</strong></td>
-                    <td>{{.MinValue}}</td>
-                    <td>{{.MaxValue}}</td>
-                    <td>{{.Increment}}</td>
-                    <td>{{.LastNumber}}</td>
-                    <td>{{.Comment}}</td>
-                </tr>
-                {{end}}
-            </tbody>
-        </table>
-        {{end}}
-
-        <hr style="margin: 40px 0; border: none; border-top: 2px solid #ecf0f1;">
-        <p style="text-align: center; color: #95a5a6; font-size: 12px;">
-            생성 시간: {{.ExtractedAt.Format "2006-01-02 15:04:05"}} | 
-            pocket-doc Tool
-        </p>
-    </div>
-</body>
-</html>
-`
+﻿package html
+
+import (
+	"context"
+	"pocket-doc/internal/cleanup"
+	"pocket-doc/internal/combine"
+	"pocket-doc/internal/coverage"
+	"pocket-doc/internal/datefmt"
+	"pocket-doc/internal/erd"
+	"pocket-doc/internal/fonts"
+	"pocket-doc/internal/i18n"
+	"pocket-doc/internal/impact"
+	"pocket-doc/internal/model"
+	"pocket-doc/internal/naming"
+	"pocket-doc/internal/page"
+	"pocket-doc/internal/verify"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+)
+
+// Config holds configuration for HTML export
+type Config struct {
+	Language         string
+	Title            string
+	IncludeCoverPage bool // Print cover page with the project, company and author
+	IncludeTOC       bool // Printed table of contents after the cover page
+	CompanyName      string
+	ProjectName      string
+	Author           string
+	TemplatePath     string         // Custom template replacing the embedded one (see templateData)
+	CSSPath          string         // Extra stylesheet appended after the built-in styles
+	PageBreak        string         // Print page breaks: avoid (default), table, none
+	Classification   string         // Confidentiality banner text (empty = no banner)
+	IncludeCoverage  bool           // Comment coverage section
+	IncludeQuality   bool           // Data quality appendix (see package verify)
+	IncludeCleanup   bool           // Cleanup appendix (see package cleanup)
+	IncludeNaming    bool           // Naming conventions appendix (see package naming)
+	IncludeImpact    bool           // Views × base tables matrix (see package impact)
+	LineageDepth     int            // Levels of foreign key parents and children listed per table (0 = none)
+	IncludeERD       bool           // Entity relationship diagram of the foreign keys (see package erd)
+	HideSingleSchema bool           // Object names without owner when there is only one
+	GroupBySchema    bool           // A chapter per schema
+	GroupByDomain    bool           // A chapter per business domain
+	Font             fonts.Config   // Font stack (empty = defaults of Language)
+	Dates            datefmt.Config // Time zone and layout of timestamps (empty = as extracted, 2006-01-02 15:04:05)
+	Page             page.Setup     // Printed paper size and orientation (empty = A4 portrait)
+}
+
+// expandThreshold is the object count up to which column grids start expanded
+const expandThreshold = 50
+
+// Exporter implements HTML export functionality
+type Exporter struct {
+	config Config
+	msg    *i18n.Bundle
+}
+
+// NewExporter creates a new HTML exporter
+func NewExporter(cfg Config) *Exporter {
+	msg := i18n.New(cfg.Language)
+	cfg.Font = cfg.Font.Resolve(msg.Language())
+	return &Exporter{config: cfg, msg: msg}
+}
+
+// Format returns the format name
+func (e *Exporter) Format() string {
+	return "html"
+}
+
+// MimeType returns the MIME type
+func (e *Exporter) MimeType() string {
+	return "text/html; charset=utf-8"
+}
+
+// FileExtension returns the file extension
+func (e *Exporter) FileExtension() string {
+	return ".html"
+}
+
+// Export generates an HTML document with print-optimized CSS
+// CRITICAL RULE #3: Korean fonts FIRST + @media print rules
+func (e *Exporter) Export(ctx context.Context, schema *model.Schema, w io.Writer) error {
+	anchors := model.NewAnchors(schema, 0, false)
+	tmpl, err := e.loadTemplate(anchors, model.NewGraph(schema))
+	if err != nil {
+		return err
+	}
+
+	data := templateData{
+		Schema:         schema,
+		Lang:           e.msg.Language(),
+		TOC:            buildTOC(schema, anchors),
+		PageBreak:      e.pageBreakMode(),
+		Expanded:       len(schema.Tables)+len(schema.Views) <= expandThreshold,
+		Classification: e.config.Classification,
+		Namer:          model.NewNamer(schema, e.config.HideSingleSchema),
+		FontFamily:     template.CSS(e.config.Font.CSS()),
+		PageSize:       template.CSS(e.config.Page.CSS()),
+		GeneratedAt:    e.config.Dates.Format(schema.ExtractedAt, "2006-01-02 15:04:05"),
+		anchors:        anchors,
+	}
+	if e.config.IncludeCoverage {
+		data.Coverage = coverage.Compute(schema)
+	}
+	if e.config.IncludeQuality {
+		data.Quality = verify.Check(schema)
+	}
+	if e.config.IncludeCleanup {
+		data.Cleanup = cleanup.Check(schema)
+	}
+	if e.config.IncludeNaming {
+		data.Naming = naming.Analyze(schema)
+	}
+	if e.config.IncludeImpact {
+		if m := impact.Build(schema); !m.Empty() {
+			data.Impact = m
+		}
+	}
+	if e.config.IncludeERD {
+		if d := erd.Layout(schema, data.Namer); !d.Empty() {
+			var svg strings.Builder
+			if err := d.SVG(&svg, e.config.Font.CSS()); err != nil {
+				return err
+			}
+			data.ERD = template.HTML(svg.String())
+		}
+	}
+	if schema.ChangedSince != nil {
+		data.ChangedSince = e.config.Dates.Format(*schema.ChangedSince, "2006-01-02")
+	}
+	if envs := schema.Environments; len(envs) > 1 {
+		data.EnvReference = e.msg.T("env.reference", envs[0], strings.Join(envs[1:], ", "))
+	}
+	if e.config.IncludeCoverPage {
+		data.Cover = &coverPage{Company: e.config.CompanyName, Project: e.config.ProjectName, Author: e.config.Author}
+	}
+	if e.config.GroupBySchema || e.config.GroupByDomain {
+		split, kind := combine.SplitBySchema, "schema"
+		if e.config.GroupByDomain {
+			split, kind = combine.SplitByDomain, "domain"
+		}
+		for _, part := range split(schema) {
+			chapter := data
+			chapter.Schema, chapter.Chapter, chapter.ChapterKind = part.Schema, part.Name, kind
+			data.Chapters = append(data.Chapters, chapter)
+		}
+	}
+	if e.config.IncludeTOC {
+		data.Contents = buildContents(data, e.msg, e.config.IncludeCoverage)
+	}
+
+	if e.config.CSSPath != "" {
+		css, err := os.ReadFile(e.config.CSSPath)
+		if err != nil {
+			return fmt.Errorf("failed to read custom CSS: %w", err)
+		}
+		data.CustomCSS = template.CSS(css)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return tmpl.Execute(w, data)
+}
+
+// pageBreakMode normalizes the configured print page-break mode
+func (e *Exporter) pageBreakMode() string {
+	switch e.config.PageBreak {
+	case "table", "none":
+		return e.config.PageBreak
+	default:
+		return "avoid"
+	}
+}
+
+// loadTemplate parses the custom template if configured, otherwise the
+// embedded one, with the object ids of anchors and the foreign keys of graph
+func (e *Exporter) loadTemplate(anchors *model.Anchors, graph *model.Graph) (*template.Template, error) {
+	src := htmlTemplate
+	if e.config.TemplatePath != "" {
+		content, err := os.ReadFile(e.config.TemplatePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read html template: %w", err)
+		}
+		src = string(content)
+	}
+
+	tmpl, err := template.New("schema").Funcs(template.FuncMap{
+		"anchor": anchors.ID,
+		"id":     anchors.Next,
+		"fk": func(table model.Table, column string) *model.Relationship {
+			if r, ok := graph.ForeignKey(table.Owner, table.Name, column); ok && r.Parent != nil {
+				return &r
+			}
+			return nil
+		},
+		"env": func(diffs []model.EnvDiff) []string { return combine.EnvNotes(diffs, e.msg) },
+		"lineage": func(table model.Table) *lineage {
+			return newLineage(graph, graph.Table(table.Owner, table.Name), e.config.LineageDepth)
+		},
+		"t": e.msg.T,
+	}).Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse html template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// lineage is the foreign key lineage of a table (Config.LineageDepth)
+type lineage struct {
+	Upstream   []lineageLevel // tables it references, nearest first
+	Downstream []lineageLevel // tables referencing it
+}
+
+// lineageLevel holds the tables at a distance of Level foreign keys
+type lineageLevel struct {
+	Level  int
+	Tables []*model.Table
+}
+
+// newLineage returns the lineage of t up to depth levels, or nil when t
+// has no parents or children or depth is 0
+func newLineage(graph *model.Graph, t *model.Table, depth int) *lineage {
+	if t == nil || depth <= 0 {
+		return nil
+	}
+	levels := func(tables [][]*model.Table) []lineageLevel {
+		out := make([]lineageLevel, len(tables))
+		for i, level := range tables {
+			out[i] = lineageLevel{Level: i + 1, Tables: level}
+		}
+		return out
+	}
+	l := &lineage{Upstream: levels(graph.Upstream(t, depth)), Downstream: levels(graph.Downstream(t, depth))}
+	if len(l.Upstream) == 0 && len(l.Downstream) == 0 {
+		return nil
+	}
+	return l
+}
+
+// htmlTemplate with Korean font support and print CSS (CRITICAL RULES)
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{t "doc.title" .DatabaseName}}</title>
+    <style>
+        /* CRITICAL RULE #3: Korean Fonts FIRST (the East Asian font of Config.Font) */
+        * {
+            font-family: {{.FontFamily}};
+            box-sizing: border-box;
+        }
+
+        /* Theme colors (light by default, dark via OS preference or toggle) */
+        :root {
+            --bg: #f5f5f5;
+            --surface: white;
+            --text: #333;
+            --heading: #2c3e50;
+            --heading-sub: #34495e;
+            --muted: #7f8c8d;
+            --th-bg: #D9D9D9;
+            --th-border: #bdc3c7;
+            --td-border: #ecf0f1;
+            --row-alt: #f9f9f9;
+            --row-hover: #e8f4f8;
+            --card-bg: #ecf0f1;
+        }
+
+        :root[data-theme="dark"] {
+            --bg: #1e1f22;
+            --surface: #2b2d31;
+            --text: #dcdde1;
+            --heading: #e8eaed;
+            --heading-sub: #c8ccd0;
+            --muted: #9aa0a6;
+            --th-bg: #3a3d42;
+            --th-border: #4a4e54;
+            --td-border: #3a3d42;
+            --row-alt: #313338;
+            --row-hover: #2f3d4a;
+            --card-bg: #35373c;
+        }
+
+        @media (prefers-color-scheme: dark) {
+            :root:not([data-theme="light"]) {
+                --bg: #1e1f22;
+                --surface: #2b2d31;
+                --text: #dcdde1;
+                --heading: #e8eaed;
+                --heading-sub: #c8ccd0;
+                --muted: #9aa0a6;
+                --th-bg: #3a3d42;
+                --th-border: #4a4e54;
+                --td-border: #3a3d42;
+                --row-alt: #313338;
+                --row-hover: #2f3d4a;
+                --card-bg: #35373c;
+            }
+        }
+
+        body {
+            margin: 0;
+            padding: 20px;
+            background: var(--bg);
+            color: var(--text);
+            line-height: 1.6;
+        }
+
+        .container {
+            max-width: 1200px;
+            margin: 0 auto 0 300px;
+            background: var(--surface);
+            padding: 40px;
+            box-shadow: 0 2px 10px rgba(0,0,0,0.1);
+            counter-reset: section;
+        }
+
+        /* Sidebar navigation (schemas → tables/views/routines) */
+        .sidebar {
+            position: fixed;
+            top: 0;
+            left: 0;
+            bottom: 0;
+            width: 280px;
+            overflow-y: auto;
+            background: #2c3e50;
+            color: #ecf0f1;
+            padding: 20px;
+            font-size: 13px;
+        }
+
+        .sidebar a {
+            color: #ecf0f1;
+            text-decoration: none;
+        }
+
+        .sidebar a:hover {
+            text-decoration: underline;
+        }
+
+        .sidebar .toc-schema {
+            font-weight: bold;
+            font-size: 14px;
+            margin: 16px 0 6px 0;
+            border-bottom: 1px solid #34495e;
+        }
+
+        .sidebar .toc-category {
+            color: #95a5a6;
+            font-size: 11px;
+            text-transform: uppercase;
+            margin: 8px 0 4px 0;
+        }
+
+        .sidebar ul {
+            list-style: none;
+            margin: 0;
+            padding: 0 0 0 10px;
+        }
+
+        .sidebar li {
+            padding: 2px 0;
+            white-space: nowrap;
+            overflow: hidden;
+            text-overflow: ellipsis;
+        }
+
+        .search-box {
+            width: 100%;
+            margin: 12px 0 4px 0;
+            padding: 6px 8px;
+            border: none;
+            border-radius: 4px;
+            font-size: 13px;
+        }
+
+        .back-to-top {
+            position: fixed;
+            right: 20px;
+            bottom: 20px;
+            background: #3498db;
+            color: white;
+            padding: 8px 12px;
+            border-radius: 4px;
+            text-decoration: none;
+            font-size: 13px;
+        }
+
+        /* Numbered section headings */
+        h2.section {
+            counter-reset: subsection;
+        }
+
+        h2.section::before {
+            counter-increment: section;
+            content: counter(section) ". ";
+        }
+
+        h3.section::before {
+            counter-increment: subsection;
+            content: counter(section) "." counter(subsection) " ";
+        }
+
+        h1 {
+            color: var(--heading);
+            border-bottom: 3px solid #3498db;
+            padding-bottom: 10px;
+            margin-bottom: 30px;
+        }
+
+        h2 {
+            color: var(--heading-sub);
+            border-bottom: 2px solid #95a5a6;
+            padding-bottom: 8px;
+            margin-top: 40px;
+            margin-bottom: 20px;
+        }
+
+        h3 {
+            color: var(--muted);
+            margin-top: 30px;
+            margin-bottom: 15px;
+        }
+
+        table {
+            width: 100%;
+            border-collapse: collapse;
+            margin-bottom: 30px;
+            background: var(--surface);
+        }
+
+        th {
+            background: var(--th-bg);
+            color: var(--text);
+            font-weight: bold;
+            text-align: left;
+            padding: 12px;
+            border: 1px solid var(--th-border);
+        }
+
+        td {
+            padding: 10px 12px;
+            border: 1px solid var(--td-border);
+        }
+
+        tr:nth-child(even) {
+            background: var(--row-alt);
+        }
+
+        tr:hover {
+            background: var(--row-hover);
+        }
+
+        .badge {
+            display: inline-block;
+            padding: 3px 8px;
+            border-radius: 3px;
+            font-size: 11px;
+            font-weight: bold;
+        }
+
+        .badge-pk { background: #2ecc71; color: white; }
+        .badge-fk { background: #3498db; color: white; }
+        .badge-uk { background: #f39c12; color: white; }
+        .badge-term { background: #8e44ad; color: white; text-decoration: none; font-weight: normal; }
+        .badge-class { background: #c0392b; color: white; }
+        a.fk { display: block; font-size: 0.9em; white-space: nowrap; }
+        .badge-change { background: #f1c40f; color: #333; }
+        tr.change-added td, tr.change-changed td { background: rgba(241, 196, 15, 0.2); }
+        p.removed del { color: var(--muted); margin-right: 8px; }
+        ul.env-diffs { margin: 8px 0; padding: 8px 8px 8px 28px; background: rgba(230, 126, 34, 0.12); border-left: 3px solid #e67e22; }
+        tr.env-differs td { background: rgba(230, 126, 34, 0.12); }
+        .env-diff { color: #d35400; font-size: 0.9em; }
+        .note { color: #7f8c8d; font-size: 0.9em; }
+        .lineage { margin: 8px 0; font-size: 0.9em; }
+        .lineage p { margin: 0; }
+        .lineage ul { margin: 4px 0; padding-left: 24px; list-style: none; }
+        table.arguments { margin: 8px 0 0; font-size: 0.9em; }
+        table.arguments th, table.arguments td { padding: 4px 8px; }
+        .erd { overflow: auto; margin-bottom: 30px; border: 1px solid var(--td-border); }
+        .erd svg { display: block; }
+        .impact { overflow: auto; margin-bottom: 30px; }
+        table.impact-matrix th.vertical { vertical-align: bottom; }
+        table.impact-matrix th.vertical span { writing-mode: vertical-rl; transform: rotate(180deg); white-space: nowrap; }
+        table.impact-matrix td.uses { text-align: center; color: #2980b9; }
+        table.impact-matrix tr.total td { font-weight: bold; text-align: center; }
+        table.impact-matrix tr.total td:first-child { text-align: left; }
+
+        .summary {
+            display: grid;
+            grid-template-columns: repeat(auto-fit, minmax(200px, 1fr));
+            gap: 15px;
+            margin-bottom: 30px;
+        }
+
+        .summary-card {
+            background: var(--card-bg);
+            padding: 15px;
+            border-radius: 5px;
+            border-left: 4px solid #3498db;
+        }
+
+        .summary-card h3 {
+            margin: 0 0 5px 0;
+            font-size: 14px;
+            color: var(--muted);
+        }
+
+        .summary-card .value {
+            font-size: 24px;
+            font-weight: bold;
+            color: var(--heading);
+        }
+
+        details.columns > summary {
+            cursor: pointer;
+            color: var(--muted);
+            font-size: 13px;
+            margin-bottom: 10px;
+        }
+
+        .theme-toggle {
+            position: fixed;
+            top: 20px;
+            right: 20px;
+            background: var(--card-bg);
+            color: var(--text);
+            border: 1px solid var(--th-border);
+            border-radius: 4px;
+            padding: 6px 10px;
+            cursor: pointer;
+            font-size: 13px;
+        }
+
+        /* Confidentiality banner (output.classification) */
+        .classification {
+            background: #c0392b;
+            color: white;
+            text-align: center;
+            font-weight: bold;
+            letter-spacing: 0.1em;
+            padding: 6px 12px;
+            margin: 0 0 20px;
+            -webkit-print-color-adjust: exact;
+            print-color-adjust: exact;
+        }
+
+        /* Cover page (output.include_cover_page), one A4 page when printed */
+        .cover {
+            display: flex;
+            flex-direction: column;
+            justify-content: center;
+            min-height: 60vh;
+            margin-bottom: 40px;
+            border-bottom: 3px solid #3498db;
+        }
+
+        .cover .cover-company {
+            color: var(--muted);
+            font-size: 18px;
+            letter-spacing: 0.05em;
+        }
+
+        .cover .cover-title {
+            color: var(--heading);
+            font-size: 32px;
+            font-weight: bold;
+            margin: 16px 0;
+        }
+
+        .cover .cover-project {
+            color: var(--heading-sub);
+            font-size: 22px;
+        }
+
+        .cover table {
+            width: auto;
+            margin-top: 40px;
+        }
+
+        /* Table of contents (output.include_toc) */
+        .contents ol {
+            list-style: none;
+            margin: 0;
+            padding: 0;
+        }
+
+        .contents li a {
+            color: var(--text);
+            text-decoration: none;
+        }
+
+        .contents li.level-1 {
+            font-weight: bold;
+            margin-top: 8px;
+        }
+
+        .contents li.level-2 {
+            padding-left: 24px;
+        }
+
+        .contents li.level-3 {
+            padding-left: 48px;
+        }
+
+        /* CRITICAL RULE #3: @media print CSS */
+        @media print {
+            @page {
+                size: {{.PageSize}};
+                margin: 2cm;
+            }
+
+            /* Always print with the light palette */
+            :root, :root[data-theme="dark"] {
+                --bg: white;
+                --surface: white;
+                --text: #333;
+                --heading: #2c3e50;
+                --heading-sub: #34495e;
+                --muted: #7f8c8d;
+                --th-bg: #D9D9D9;
+                --th-border: #bdc3c7;
+                --td-border: #ecf0f1;
+                --row-alt: #f9f9f9;
+                --row-hover: white;
+                --card-bg: #ecf0f1;
+            }
+
+            body {
+                background: white;
+                padding: 0;
+            }
+
+            .container {
+                box-shadow: none;
+                padding: 0;
+                margin: 0;
+            }
+
+            /* Page breaks for major sections */
+            h1, h2 {
+                page-break-before: always;
+            }
+
+            h1:first-of-type, h2:first-of-type {
+                page-break-before: avoid;
+            }
+
+            /* The cover fills the first page, the contents start the next */
+            .cover {
+                min-height: 25.7cm;
+                margin: 0;
+                border: none;
+                page-break-after: always;
+            }
+
+            .contents {
+                page-break-after: always;
+            }
+
+            /* Page numbers, where the print engine supports target-counter */
+            .contents li a::after {
+                content: leader('.') target-counter(attr(href), page);
+            }
+
+            /* Keep headings with content */
+            h3, h4 {
+                page-break-after: avoid;
+            }
+
+            /* Repeat column headers when a table spans pages */
+            thead {
+                display: table-header-group;
+            }
+
+            tr {
+                page-break-inside: avoid;
+            }
+
+            /* page_break: avoid - keep each table on one page where possible */
+            .page-break-avoid table {
+                page-break-inside: avoid;
+            }
+
+            /* page_break: table - start every table/view on a new page */
+            .page-break-table div.object {
+                page-break-before: always;
+            }
+
+            /* Shrink the diagram to the page width */
+            .erd { overflow: visible; border: none; }
+            .erd svg { max-width: 100%; height: auto; }
+
+            /* Hide interactive elements */
+            .no-print {
+                display: none;
+            }
+
+            /* Collapsed column grids are opened by the beforeprint handler */
+            details.columns > summary {
+                display: none;
+            }
+
+            /* Print the full document regardless of the search filter */
+            div.object {
+                display: block !important;
+            }
+
+            tr.object, tr[data-search], tr[data-target] {
+                display: table-row !important;
+            }
+        }
+    </style>
+    {{if .CustomCSS}}<style>{{.CustomCSS}}</style>{{end}}
+</head>
+<body id="top" class="page-break-{{.PageBreak}}">
+    <nav class="sidebar no-print">
+        <a href="#top"><strong>{{.DatabaseName}}</strong></a>
+        <input type="search" id="search-input" class="search-box" placeholder="{{t "ui.search_placeholder"}}" autocomplete="off">
+        {{range .TOC}}
+        <div class="toc-schema">{{if .Name}}{{.Name}}{{else}}{{t "ui.default_schema"}}{{end}}</div>
+        {{if .Tables}}
+        <div class="toc-category">{{t "section.tables"}}</div>
+        <ul>
+            {{range .Tables}}<li data-target="{{.Anchor}}"><a href="#{{.Anchor}}" title="{{.Name}}">{{.Name}}</a></li>
+            {{end}}
+        </ul>
+        {{end}}
+        {{if .Views}}
+        <div class="toc-category">{{t "section.views"}}</div>
+        <ul>
+            {{range .Views}}<li data-target="{{.Anchor}}"><a href="#{{.Anchor}}" title="{{.Name}}">{{.Name}}</a></li>
+            {{end}}
+        </ul>
+        {{end}}
+        {{if .Routines}}
+        <div class="toc-category">{{t "section.routines"}}</div>
+        <ul>
+            {{range .Routines}}<li data-target="{{.Anchor}}"><a href="#{{.Anchor}}" title="{{.Name}}">{{.Name}}</a></li>
+            {{end}}
+        </ul>
+        {{end}}
+        {{end}}
+        {{if .Tenants}}
+        <div class="toc-category"><a href="#section-tenants">{{t "section.tenants"}}</a></div>
+        {{end}}
+        {{if .ERD}}
+        <div class="toc-category"><a href="#section-erd">{{t "section.erd"}}</a></div>
+        {{end}}
+        {{if .Impact}}
+        <div class="toc-category"><a href="#section-impact">{{t "section.impact"}}</a></div>
+        {{end}}
+        {{if .Coverage}}
+        <div class="toc-category"><a href="#section-coverage">{{t "section.coverage"}}</a></div>
+        {{end}}
+        {{if .Migrations}}
+        <div class="toc-category"><a href="#section-migrations">{{t "section.migrations"}}</a></div>
+        {{end}}
+        {{if .Glossary}}
+        <div class="toc-category"><a href="#section-glossary">{{t "section.glossary"}}</a></div>
+        {{end}}
+    </nav>
+    <a href="#top" class="back-to-top no-print">{{t "ui.back_to_top"}}</a>
+    <button type="button" class="theme-toggle no-print" id="theme-toggle" title="{{t "ui.theme_title"}}">{{t "ui.theme"}}</button>
+
+    <div class="container">
+        {{with .Cover}}
+        <section class="cover">
+            {{with .Company}}<div class="cover-company">{{.}}</div>{{end}}
+            <div class="cover-title">{{t "doc.title" $.DatabaseName}}</div>
+            {{with .Project}}<div class="cover-project">{{.}}</div>{{end}}
+            <table>
+                <tr><th>{{t "label.database_type"}}</th><td>{{$.DatabaseType}} {{$.Version}}</td></tr>
+                <tr><th>{{t "doc.generated_at"}}</th><td>{{$.GeneratedAt}}</td></tr>
+                {{with .Author}}<tr><th>{{t "label.author"}}</th><td>{{.}}</td></tr>{{end}}
+            </table>
+            {{if $.Classification}}<div class="classification">{{$.Classification}}</div>{{end}}
+        </section>
+        {{end}}
+        {{if .Contents}}
+        <nav class="contents" id="section-contents">
+            <h2>{{t "section.contents"}}</h2>
+            <ol>
+                {{range .Contents}}<li class="level-{{.Level}}"><a href="#{{.Anchor}}">{{with .Number}}{{.}}. {{end}}{{.Title}}</a></li>
+                {{end}}
+            </ol>
+        </nav>
+        {{end}}
+        {{if .Classification}}<div class="classification">{{.Classification}}</div>{{end}}
+        <h1>{{t "doc.title" .DatabaseName}}</h1>
+
+        <div class="summary">
+            <div class="summary-card">
+                <h3>{{t "label.database_type"}}</h3>
+                <div class="value">{{.DatabaseType}}</div>
+            </div>
+            <div class="summary-card">
+                <h3>{{t "label.version"}}</h3>
+                <div class="value">{{.Version}}</div>
+            </div>
+            <div class="summary-card">
+                <h3>{{t "label.total_tables"}}</h3>
+                <div class="value">{{len .Tables}}</div>
+            </div>
+            <div class="summary-card">
+                <h3>{{t "label.total_views"}}</h3>
+                <div class="value">{{len .Views}}</div>
+            </div>
+            <div class="summary-card">
+                <h3>{{t "label.total_routines"}}</h3>
+                <div class="value">{{len .Routines}}</div>
+            </div>
+            <div class="summary-card">
+                <h3>{{t "label.total_triggers"}}</h3>
+                <div class="value">{{len .Triggers}}</div>
+            </div>
+        </div>
+
+        {{if .Sources}}
+        <h2 class="section" id="section-databases">🗄️ {{t "section.databases"}}</h2>
+        <table>
+            <thead>
+                <tr>
+                    <th>{{t "label.name"}}</th>
+                    <th>{{t "label.database_name"}}</th>
+                    <th>{{t "label.database_type"}}</th>
+                    <th>{{t "label.version"}}</th>
+                    <th>{{t "label.total_tables"}}</th>
+                    <th>{{t "label.total_views"}}</th>
+                    <th>{{t "label.total_routines"}}</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Sources}}
+                <tr>
+                    <td><strong>{{.Name}}</strong></td>
+                    <td>{{.DatabaseName}}</td>
+                    <td>{{.DatabaseType}}</td>
+                    <td>{{.Version}}</td>
+                    <td>{{.Tables}}</td>
+                    <td>{{.Views}}</td>
+                    <td>{{.Routines}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        {{with .EnvReference}}<p class="note">{{.}}</p>{{end}}
+        {{end}}
+
+        {{if .Tenants}}
+        <h2 class="section" id="section-tenants">🏘️ {{t "section.tenants"}}</h2>
+        <p>{{t "tenants.intro"}}</p>
+        <table>
+            <thead>
+                <tr>
+                    <th>{{t "label.pattern"}}</th>
+                    <th>{{t "label.documented_schema"}}</th>
+                    <th>{{t "label.schema_count"}}</th>
+                    <th>{{t "label.instances"}}</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Tenants}}
+                <tr>
+                    <td><strong>{{.Pattern}}</strong></td>
+                    <td>{{.Schema}}</td>
+                    <td>{{len .Instances}}</td>
+                    <td>{{range $i, $s := .Instances}}{{if $i}}, {{end}}{{$s}}{{end}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        {{end}}
+
+        {{if .ERD}}
+        <h2 class="section" id="section-erd">🔗 {{t "section.erd"}}</h2>
+        <div class="erd">{{.ERD}}</div>
+        {{end}}
+
+        {{with .Impact}}
+        <h2 class="section" id="section-impact">🎯 {{t "section.impact"}}</h2>
+        <p>{{t "impact.intro"}}</p>
+        <div class="impact">
+        <table class="impact-matrix">
+            <thead>
+                <tr>
+                    <th>{{t "label.view"}}</th>
+                    {{range .Tables}}<th class="vertical"><span>{{$.Namer.Name .Table.Owner .Table.Name}}</span></th>{{end}}
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Rows}}
+                <tr>
+                    <td><strong>{{$.Namer.Name .View.Owner .View.Name}}</strong></td>
+                    {{range .Uses}}<td class="{{if .}}uses{{end}}">{{if .}}●{{end}}</td>{{end}}
+                </tr>
+                {{end}}
+                <tr class="total">
+                    <td>{{t "impact.views"}}</td>
+                    {{range .Tables}}<td>{{.Views}}</td>{{end}}
+                </tr>
+            </tbody>
+        </table>
+        </div>
+        {{end}}
+
+        {{range .Chapters}}
+        <h1 class="chapter" id="{{anchor .ChapterKind "" .Chapter}}">{{t (print "label." .ChapterKind)}}: {{.Chapter}}</h1>
+        {{template "objects" .}}
+        {{else}}
+        {{template "objects" .}}
+        {{end}}
+
+        {{with .Coverage}}
+        <h2 class="section" id="section-coverage">📝 {{t "section.coverage"}}</h2>
+        <table>
+            <thead>
+                <tr>
+                    <th>{{t "label.schema"}}</th>
+                    <th>{{t "label.total_tables"}}</th>
+                    <th>{{t "label.table_coverage"}}</th>
+                    <th>{{t "label.columns"}}</th>
+                    <th>{{t "label.column_coverage"}}</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Schemas}}
+                <tr>
+                    <td><strong>{{if .Owner}}{{.Owner}}{{else}}{{t "ui.default_schema"}}{{end}}</strong></td>
+                    <td>{{.Tables}}</td>
+                    <td>{{.CommentedTables}} ({{printf "%.1f" .TablePercent}}%)</td>
+                    <td>{{.Columns}}</td>
+                    <td>{{.CommentedColumns}} ({{printf "%.1f" .ColumnPercent}}%)</td>
+                </tr>
+                {{end}}
+                <tr>
+                    <td><strong>{{t "label.total"}}</strong></td>
+                    <td>{{.Total.Tables}}</td>
+                    <td>{{.Total.CommentedTables}} ({{printf "%.1f" .Total.TablePercent}}%)</td>
+                    <td>{{.Total.Columns}}</td>
+                    <td>{{.Total.CommentedColumns}} ({{printf "%.1f" .Total.ColumnPercent}}%)</td>
+                </tr>
+            </tbody>
+        </table>
+        {{if .Missing}}
+        <details class="columns">
+        <summary>{{t "section.missing_comments"}} ({{len .Missing}})</summary>
+        <ul>
+            {{range .Missing}}<li>{{.Path}}</li>
+            {{end}}
+        </ul>
+        </details>
+        {{end}}
+        {{end}}
+
+        {{with .Quality}}
+        <h2 class="section" id="section-quality">🔍 {{t "section.quality"}}</h2>
+        {{if .Findings}}
+        <table>
+            <thead>
+                <tr>
+                    <th>{{t "label.check"}}</th>
+                    <th>{{t "label.object"}}</th>
+                    <th>{{t "label.detail"}}</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Findings}}
+                <tr>
+                    <td>{{t (printf "quality.%s" .Check)}}</td>
+                    <td><strong>{{$.Namer.Name .Owner .Object}}</strong></td>
+                    <td>{{.Detail}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        {{else}}
+        <p>{{t "quality.none"}}</p>
+        {{end}}
+        {{end}}
+
+        {{with .Cleanup}}
+        <h2 class="section" id="section-cleanup">🧹 {{t "section.cleanup"}}</h2>
+        {{if .Findings}}
+        <table>
+            <thead>
+                <tr>
+                    <th>{{t "label.check"}}</th>
+                    <th>{{t "label.object"}}</th>
+                    <th>{{t "label.detail"}}</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Findings}}
+                <tr>
+                    <td>{{t (printf "cleanup.%s" .Check)}}</td>
+                    <td><strong>{{$.Namer.Name .Owner .Object}}</strong></td>
+                    <td>{{.Detail}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        {{else}}
+        <p>{{t "cleanup.none"}}</p>
+        {{end}}
+        {{end}}
+
+        {{with .Naming}}
+        <h2 class="section" id="section-naming">🔤 {{t "section.naming"}}</h2>
+        {{if .Affixes}}
+        <p>{{t "naming.intro"}}</p>
+        <table>
+            <thead>
+                <tr>
+                    <th>{{t "label.affix"}}</th>
+                    <th>{{t "label.columns"}}</th>
+                    <th>{{t "label.table_count"}}</th>
+                    <th>{{t "label.meaning"}}</th>
+                    <th>{{t "label.examples"}}</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Affixes}}
+                <tr>
+                    <td><code>{{.Text}}</code> ({{if .Prefix}}{{t "naming.prefix"}}{{else}}{{t "naming.suffix"}}{{end}})</td>
+                    <td>{{.Columns}}</td>
+                    <td>{{.Tables}}</td>
+                    <td>{{with .Meaning}}{{t (printf "naming.meaning.%s" .)}}{{end}}</td>
+                    <td>{{range $i, $e := .Examples}}{{if $i}}, {{end}}{{$e}}{{end}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        {{else}}
+        <p>{{t "naming.none"}}</p>
+        {{end}}
+        {{end}}
+
+        {{if .Migrations}}
+        <h2 class="section" id="section-migrations">🧬 {{t "section.migrations"}}</h2>
+        <table>
+            <thead>
+                <tr>
+                    <th>{{t "label.tool"}}</th>
+                    <th>{{t "label.version"}}</th>
+                    <th>{{t "label.description"}}</th>
+                    <th>{{t "label.script"}}</th>
+                    <th>{{t "label.type"}}</th>
+                    <th>{{t "label.applied_by"}}</th>
+                    <th>{{t "label.applied_at"}}</th>
+                    <th>{{t "label.status"}}</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Migrations}}
+                <tr>
+                    <td>{{.Tool}}{{with .Owner}} ({{.}}){{end}}</td>
+                    <td><strong>{{.Version}}</strong></td>
+                    <td>{{.Description}}</td>
+                    <td>{{.Script}}</td>
+                    <td>{{.Type}}</td>
+                    <td>{{.AppliedBy}}</td>
+                    <td>{{.AppliedAt}}</td>
+                    <td>{{if .Success}}{{t "migration.success"}}{{else}}<strong>{{t "migration.failed"}}</strong>{{end}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        {{end}}
+
+        {{if .Glossary}}
+        <h2 class="section" id="section-glossary">📖 {{t "section.glossary"}}</h2>
+        <table>
+            <thead>
+                <tr>
+                    <th>{{t "label.term"}}</th>
+                    <th>{{t "label.definition"}}</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Glossary}}
+                <tr class="object" id="{{id "term" "" .Term}}" data-search="{{.Term}} {{.Definition}}">
+                    <td><strong>{{.Term}}</strong></td>
+                    <td>{{.Definition}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        {{end}}
+
+        <hr style="margin: 40px 0; border: none; border-top: 2px solid #ecf0f1;">
+        <p style="text-align: center; color: #95a5a6; font-size: 12px;">
+            {{t "doc.generated_at"}}: {{.GeneratedAt}} |
+            {{t "doc.generated_by"}}
+        </p>
+        {{if .Classification}}<div class="classification">{{.Classification}}</div>{{end}}
+    </div>
+
+    <script>
+        // Theme toggle: explicit choice is remembered and overrides the OS preference
+        (function () {
+            var root = document.documentElement;
+            var saved = null;
+            try { saved = localStorage.getItem('pocket-doc-theme'); } catch (e) {}
+            if (saved) {
+                root.setAttribute('data-theme', saved);
+            }
+
+            var button = document.getElementById('theme-toggle');
+            button.addEventListener('click', function () {
+                var current = root.getAttribute('data-theme');
+                if (!current) {
+                    current = window.matchMedia('(prefers-color-scheme: dark)').matches ? 'dark' : 'light';
+                }
+                var next = current === 'dark' ? 'light' : 'dark';
+                root.setAttribute('data-theme', next);
+                try { localStorage.setItem('pocket-doc-theme', next); } catch (e) {}
+            });
+        })();
+
+        // Client-side filter: an object stays visible when its own name/comment
+        // matches, or when at least one of its columns matches (only those rows shown)
+        (function () {
+            var input = document.getElementById('search-input');
+            if (!input) {
+                return;
+            }
+
+            function matches(el, q) {
+                return (el.getAttribute('data-search') || '').toLowerCase().indexOf(q) !== -1;
+            }
+
+            input.addEventListener('input', function () {
+                var q = input.value.trim().toLowerCase();
+
+                document.querySelectorAll('.object').forEach(function (obj) {
+                    var self = !q || matches(obj, q);
+                    var anyRow = false;
+                    obj.querySelectorAll('tr[data-search]').forEach(function (row) {
+                        var show = self || matches(row, q);
+                        row.style.display = show ? '' : 'none';
+                        anyRow = anyRow || show;
+                    });
+                    obj.style.display = (self || anyRow) ? '' : 'none';
+
+                    var details = obj.querySelector('details');
+                    if (details && q && (self || anyRow)) {
+                        details.open = true;
+                    }
+                });
+
+                // Sidebar links and summary rows follow their target object
+                document.querySelectorAll('[data-target]').forEach(function (el) {
+                    var target = document.getElementById(el.getAttribute('data-target'));
+                    el.style.display = (!target || target.style.display !== 'none') ? '' : 'none';
+                });
+            });
+        })();
+
+        // Collapsible column grids: open on anchor navigation, expand all for print
+        (function () {
+            function openTarget() {
+                var target = document.getElementById(decodeURIComponent(location.hash.slice(1)));
+                var details = target && target.querySelector('details');
+                if (details) {
+                    details.open = true;
+                }
+            }
+            window.addEventListener('hashchange', openTarget);
+            openTarget();
+
+            var closed = [];
+            window.addEventListener('beforeprint', function () {
+                closed = [];
+                document.querySelectorAll('details.columns').forEach(function (d) {
+                    if (!d.open) {
+                        closed.push(d);
+                        d.open = true;
+                    }
+                });
+            });
+            window.addEventListener('afterprint', function () {
+                closed.forEach(function (d) {
+                    d.open = false;
+                });
+            });
+        })();
+    </script>
+</body>
+</html>
+{{- define "objects"}}
+        {{if .Tables}}
+        <h2 class="section" id="{{.Section "tables"}}">📋 {{t "section.tables"}}</h2>
+        <table>
+            <thead>
+                <tr>
+                    <th>{{t "label.name"}}</th>
+                    <th>{{t "label.owner"}}</th>
+                    <th>{{t "label.row_count"}}</th>
+                    <th>{{t "label.comment"}}</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Tables}}
+                <tr data-target="{{anchor "table" .Owner .Name}}">
+                    <td><strong><a href="#{{anchor "table" .Owner .Name}}">{{.Name}}</a></strong></td>
+                    <td>{{.Owner}}</td>
+                    <td>{{.RowCount}}</td>
+                    <td>{{.Comment}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+
+        {{range $table := .Tables}}
+        <div class="object" id="{{id "table" .Owner .Name}}" data-search="{{.Name}} {{.Comment}}">
+        <h3 class="section">{{t "object.table"}}: {{$.Namer.Name .Owner .Name}}</h3>
+        {{if and .Change $.ChangedSince}}<p><span class="badge badge-change">{{t (print "history.table_" .Change) $.ChangedSince}}</span></p>{{end}}
+        {{with .EnvDiffs}}<ul class="env-diffs">{{range env .}}<li>{{.}}</li>{{end}}</ul>{{end}}
+        {{if .Comment}}<p><em>{{.Comment}}</em></p>{{end}}
+        {{if .Terms}}<p>{{t "label.terms"}}:{{range .Terms}} <a class="badge badge-term" href="#{{anchor "term" "" .}}">{{.}}</a>{{end}}</p>{{end}}
+        {{if or .Team .Classification .Domain}}<p>{{if .Classification}}<span class="badge badge-class">{{.Classification}}</span> {{end}}{{if .Team}}{{t "label.team"}}: {{.Team}} {{end}}{{if .Domain}}{{t "label.domain"}}: {{.Domain}}{{end}}</p>{{end}}
+        {{if .Note}}<p class="note">📝 {{.Note}}</p>{{end}}
+        {{if .ReferencedBy}}<p>{{t "label.used_by"}}: {{range $i, $r := .ReferencedBy}}{{if $i}}, {{end}}{{$r}} ({{$r.Type}}){{end}}</p>{{end}}
+        {{with lineage $table}}
+        <div class="lineage">
+            <p><strong>{{t "label.lineage"}}</strong></p>
+            <ul>
+                {{range .Upstream}}<li>↑ {{t "lineage.upstream" .Level}}: {{range $i, $p := .Tables}}{{if $i}}, {{end}}<a href="#{{anchor "table" $p.Owner $p.Name}}">{{$.Namer.Name $p.Owner $p.Name}}</a>{{end}}</li>{{end}}
+                {{range .Downstream}}<li>↓ {{t "lineage.downstream" .Level}}: {{range $i, $c := .Tables}}{{if $i}}, {{end}}<a href="#{{anchor "table" $c.Owner $c.Name}}">{{$.Namer.Name $c.Owner $c.Name}}</a>{{end}}</li>{{end}}
+            </ul>
+        </div>
+        {{end}}
+
+        <details class="columns"{{if $.Expanded}} open{{end}}>
+        <summary>{{t "ui.column_count" (len .Columns)}}</summary>
+        <table>
+            <thead>
+                <tr>
+                    <th>{{t "label.column_name"}}</th>
+                    <th>{{t "label.data_type"}}</th>
+                    <th>{{t "label.nullable"}}</th>
+                    <th>{{t "label.constraints"}}</th>
+                    <th>{{t "label.default"}}</th>
+                    <th>{{t "label.comment"}}</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Columns}}
+                <tr data-search="{{.Name}} {{.Comment}}"{{with .Change}} class="change-{{.}}"{{else}}{{if .EnvDiffs}} class="env-differs"{{end}}{{end}}>
+                    <td><strong>{{.Name}}</strong>{{with .Change}} <span class="badge badge-change">{{t (print "history." .)}}</span>{{end}}</td>
+                    <td>{{.DataType}}</td>
+                    <td>{{if .Nullable}}YES{{else}}NO{{end}}</td>
+                    <td>
+                        {{if .IsPrimaryKey}}<span class="badge badge-pk">PK</span>{{end}}
+                        {{if .IsForeignKey}}<span class="badge badge-fk">FK</span>{{end}}
+                        {{if .IsUnique}}<span class="badge badge-uk">UK</span>{{end}}
+                        {{with fk $table .Name}}<a class="fk" href="#{{anchor "table" .Parent.Owner .Parent.Name}}">→ {{$.Namer.Name .Parent.Owner .Parent.Name}}{{with .ParentColumn}}.{{.}}{{end}}</a>{{end}}
+                    </td>
+                    <td>{{.DefaultValue}}</td>
+                    <td>{{.Comment}}{{range .Terms}} <a class="badge badge-term" href="#{{anchor "term" "" .}}">{{.}}</a>{{end}}{{if .Classification}} <span class="badge badge-class"{{if .PII}} title="{{range $i, $tag := .PII}}{{if $i}}, {{end}}{{$tag}}{{end}}"{{end}}>{{.Classification}}</span>{{end}}{{if .Note}}<div class="note">📝 {{.Note}}</div>{{end}}{{if .Examples}}<div class="note">{{t "label.examples"}}:{{range .Examples}} <code>{{.}}</code>{{end}}</div>{{end}}{{range env .EnvDiffs}}<div class="env-diff">⚠️ {{.}}</div>{{end}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </details>
+        {{if .RemovedColumns}}<p class="removed">{{t "history.removed_columns"}}:{{range .RemovedColumns}} <del>{{.Name}} ({{.DataType}})</del>{{end}}</p>{{end}}
+        </div>
+        {{end}}
+        {{end}}
+
+        {{if .Views}}
+        <h2 class="section" id="{{.Section "views"}}">👁️ {{t "section.views"}}</h2>
+        <table>
+            <thead>
+                <tr>
+                    <th>{{t "label.name"}}</th>
+                    <th>{{t "label.owner"}}</th>
+                    <th>{{t "label.type"}}</th>
+                    <th>{{t "label.comment"}}</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Views}}
+                <tr data-target="{{anchor "view" .Owner .Name}}">
+                    <td><strong><a href="#{{anchor "view" .Owner .Name}}">{{.Name}}</a></strong></td>
+                    <td>{{.Owner}}</td>
+                    <td>{{.Type}}</td>
+                    <td>{{.Comment}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+
+        {{range .Views}}
+        <div class="object" id="{{id "view" .Owner .Name}}" data-search="{{.Name}} {{.Comment}}">
+        <h3 class="section">{{t "object.view"}}: {{$.Namer.Name .Owner .Name}}</h3>
+        {{with .EnvDiffs}}<ul class="env-diffs">{{range env .}}<li>{{.}}</li>{{end}}</ul>{{end}}
+        {{if .Comment}}<p><em>{{.Comment}}</em></p>{{end}}
+        {{if .Terms}}<p>{{t "label.terms"}}:{{range .Terms}} <a class="badge badge-term" href="#{{anchor "term" "" .}}">{{.}}</a>{{end}}</p>{{end}}
+        {{if or .Team .Classification .Domain}}<p>{{if .Classification}}<span class="badge badge-class">{{.Classification}}</span> {{end}}{{if .Team}}{{t "label.team"}}: {{.Team}} {{end}}{{if .Domain}}{{t "label.domain"}}: {{.Domain}}{{end}}</p>{{end}}
+        {{if .Note}}<p class="note">📝 {{.Note}}</p>{{end}}
+
+        <details class="columns"{{if $.Expanded}} open{{end}}>
+        <summary>{{t "ui.column_count" (len .Columns)}}</summary>
+        <table>
+            <thead>
+                <tr>
+                    <th>{{t "label.column_name"}}</th>
+                    <th>{{t "label.data_type"}}</th>
+                    <th>{{t "label.nullable"}}</th>
+                    <th>{{t "label.comment"}}</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Columns}}
+                <tr data-search="{{.Name}} {{.Comment}}"{{if .EnvDiffs}} class="env-differs"{{end}}>
+                    <td><strong>{{.Name}}</strong></td>
+                    <td>{{.DataType}}</td>
+                    <td>{{if .Nullable}}YES{{else}}NO{{end}}</td>
+                    <td>{{.Comment}}{{range .Terms}} <a class="badge badge-term" href="#{{anchor "term" "" .}}">{{.}}</a>{{end}}{{if .Classification}} <span class="badge badge-class"{{if .PII}} title="{{range $i, $tag := .PII}}{{if $i}}, {{end}}{{$tag}}{{end}}"{{end}}>{{.Classification}}</span>{{end}}{{if .Note}}<div class="note">📝 {{.Note}}</div>{{end}}{{if .Examples}}<div class="note">{{t "label.examples"}}:{{range .Examples}} <code>{{.}}</code>{{end}}</div>{{end}}{{range env .EnvDiffs}}<div class="env-diff">⚠️ {{.}}</div>{{end}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        </details>
+        </div>
+        {{end}}
+        <p style="color: #7f8c8d; font-size: 12px;">
+            {{t "note.view_security"}}
+        </p>
+        {{end}}
+
+        {{if .Routines}}
+        <h2 class="section" id="{{.Section "routines"}}">⚙️ {{t "section.routines"}}</h2>
+        <table>
+            <thead>
+                <tr>
+                    <th>{{t "label.name"}}</th>
+                    <th>{{t "label.type"}}</th>
+                    <th>{{t "label.signature"}}</th>
+                    <th>{{t "label.touches"}}</th>
+                    <th>{{t "label.comment"}}</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range $r := .Routines}}
+                <tr class="object" id="{{id "routine" .Owner .Name}}" data-search="{{.Name}} {{.Comment}}">
+                    <td><strong>{{$.Namer.Name .Owner .Name}}</strong></td>
+                    <td>{{.Type}}</td>
+                    <td><code>{{.Signature}}</code>
+                        {{if .Arguments}}<table class="arguments">
+                            <tr><th>{{t "label.name"}}</th><th>{{t "label.mode"}}</th><th>{{t "label.data_type"}}</th><th>{{t "label.default"}}</th><th>{{t "label.comment"}}</th></tr>
+                            {{range .Arguments}}<tr><td>{{.Name}}</td><td>{{.Mode}}</td><td>{{.DataType}}</td><td>{{.DefaultValue}}</td><td>{{.Comment}}</td></tr>
+                            {{end}}</table>{{end}}
+                    </td>
+                    <td>{{range $i, $t := .Touches}}{{if $i}}, {{end}}{{$.Namer.Name $t.Owner $t.Name}}{{end}}</td>
+                    <td>{{.Comment}}{{range $.Triggers}}{{if and .Function (eq .Function.Owner $r.Owner) (eq .Function.Name $r.Name)}}<div class="note">{{t "object.trigger"}}: {{$.Namer.Name .Owner .Name}} ({{$.Namer.Name .Owner .TargetTable}})</div>{{end}}{{end}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        <p style="color: #7f8c8d; font-size: 12px;">
+            {{t "note.routine_security"}}
+        </p>
+        {{end}}
+
+        {{if .Triggers}}
+        <h2 class="section" id="{{.Section "triggers"}}">🔔 {{t "section.triggers"}}</h2>
+        <table>
+            <thead>
+                <tr>
+                    <th>{{t "label.name"}}</th>
+                    <th>{{t "label.target_table"}}</th>
+                    <th>{{t "label.timing"}}</th>
+                    <th>{{t "label.event"}}</th>
+                    <th>{{t "label.status"}}</th>
+                    <th>{{t "label.comment"}}</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Triggers}}
+                <tr>
+                    <td><strong>{{$.Namer.Name .Owner .Name}}</strong>{{with .Function}}<div class="note">{{t "label.function"}}: <a href="#{{anchor "routine" .Owner .Name}}">{{.}}</a></div>{{end}}</td>
+                    <td>{{if eq .TargetType "DATABASE"}}<em>{{t "label.database_ddl"}}</em>{{else}}{{$.Namer.Name .Owner .TargetTable}}{{end}}</td>
+                    <td>{{.Timing}}</td>
+                    <td>{{.Event}}</td>
+                    <td>{{.Status}}</td>
+                    <td>{{.Comment}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        <p style="color: #7f8c8d; font-size: 12px;">
+            {{t "note.trigger_security"}}
+        </p>
+        {{end}}
+
+        {{if .Sequences}}
+        <h2 class="section" id="{{.Section "sequences"}}">🔢 {{t "section.sequences"}}</h2>
+        <table>
+            <thead>
+                <tr>
+                    <th>{{t "label.name"}}</th>
+                    <th>{{t "label.min"}}</th>
+                    <th>{{t "label.max"}}</th>
+                    <th>{{t "label.increment"}}</th>
+                    <th>{{t "label.current"}}</th>
+                    <th>{{t "label.comment"}}</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Sequences}}
+                <tr>
+                    <td><strong>{{$.Namer.Name .Owner .Name}}</strong></td>
+                    <td>{{.MinValue}}</td>
+                    <td>{{.MaxValue}}</td>
+                    <td>{{.Increment}}</td>
+                    <td>{{.LastNumber}}</td>
+                    <td>{{.Comment}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        {{end}}
+{{end}}`
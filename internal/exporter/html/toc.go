@@ -0,0 +1,252 @@
+package html
+
+import (
+	"html/template"
+	"pocket-doc/internal/cleanup"
+	"pocket-doc/internal/coverage"
+	"pocket-doc/internal/i18n"
+	"pocket-doc/internal/impact"
+	"pocket-doc/internal/model"
+	"pocket-doc/internal/naming"
+	"pocket-doc/internal/verify"
+	"strconv"
+)
+
+// templateData is the data contract for the HTML template, including
+// custom templates configured via Config.TemplatePath:
+//
+//   - all model.Schema fields directly ({{.DatabaseName}}, {{range .Tables}}, ...)
+//   - .Lang: resolved language tag (for <html lang>)
+//   - .TOC: objects grouped by owner, each group with .Name and
+//     .Tables/.Views/.Routines entries of {.Name, .Anchor}
+//   - .CustomCSS: contents of Config.CSSPath (empty if not set)
+//   - .PageBreak: print page-break mode ("avoid", "table" or "none")
+//   - .Expanded: whether per-table column grids start expanded (small schemas)
+//   - .Classification: confidentiality label (empty if not set)
+//   - .Coverage: comment coverage report (nil unless Config.IncludeCoverage)
+//   - .Quality: data quality findings, each with .Check, .Owner, .Object
+//     and .Detail (nil unless Config.IncludeQuality)
+//   - .Cleanup: cleanup candidates, with the fields of .Quality (nil unless
+//     Config.IncludeCleanup)
+//   - .Naming: column prefixes and suffixes in .Affixes, each with .Text,
+//     .Prefix, .Columns, .Tables, .Meaning (an ID, see package naming) and
+//     .Examples (nil unless Config.IncludeNaming)
+//   - .ERD: inline SVG entity relationship diagram of the foreign keys
+//     (empty unless Config.IncludeERD and the tables have foreign keys)
+//   - .Impact: views × base tables matrix, .Tables each with .Table and
+//     .Views (the number of views using it), .Rows each with .View and
+//     .Uses, a bool per table (nil unless Config.IncludeImpact and a view
+//     uses a table)
+//   - .Namer: {{$.Namer.Name .Owner .Name}} displays an object name,
+//     owner-qualified unless Config.HideSingleSchema applies
+//   - .FontFamily: CSS font-family stack of Config.Font
+//   - .PageSize: size of the print @page rule ("A4", "letter landscape")
+//     from Config.Page
+//   - .GeneratedAt: .ExtractedAt in the time zone and layout of
+//     Config.Dates
+//   - .ChangedSince: date of the snapshot the .Change marks of tables and
+//     columns compare against (empty unless the schema was compared, see
+//     diff.Mark)
+//   - .EnvReference: the environment whose definitions a schema consolidated
+//     across environments documents, with the others (empty unless
+//     consolidated, see diff.Consolidate)
+//   - .Cover: company, project and author of the cover page (nil unless
+//     Config.IncludeCoverPage)
+//   - .Contents: numbered sections and table/view entries of the printed
+//     table of contents, each with .Number, .Title, .Anchor and .Level
+//     (empty unless Config.IncludeTOC); chapters have no .Number
+//   - .Chapters: with Config.GroupBySchema (or GroupByDomain), a copy of
+//     the data per schema (domain) holding its objects, its name in
+//     .Chapter and "schema" ("domain") in .ChapterKind; the "objects"
+//     template renders the object sections of the document or a chapter
+//   - .Section: {{.Section "tables"}} returns the id of a section heading
+//
+// Template functions: {{anchor "table" .Owner .Name}} returns the id to link
+// to an object ("table", "view", "routine" or "term") and {{id "table" .Owner
+// .Name}} the id of the element defining it, unique even for overloaded
+// routines (see model.Anchors); {{fk $table .Name}} returns the foreign key
+// relationship of a column whose parent table is documented, or nil;
+// {{env .EnvDiffs}} describes the environment differences of a table, view
+// or column, one sentence each; {{lineage $table}} returns the foreign key
+// lineage of a table, .Upstream and .Downstream levels each with .Level
+// and .Tables, or nil (always unless Config.LineageDepth); {{t "label.name"}} translates a message key (see internal/i18n), with
+// optional format args.
+type templateData struct {
+	*model.Schema
+	Lang           string
+	TOC            []tocGroup
+	CustomCSS      template.CSS
+	PageBreak      string
+	Expanded       bool
+	Classification string
+	Coverage       *coverage.Report
+	Quality        *verify.Report
+	Cleanup        *cleanup.Report
+	Naming         *naming.Dictionary
+	ERD            template.HTML
+	Impact         *impact.Matrix
+	Namer          model.Namer
+	FontFamily     template.CSS
+	PageSize       template.CSS
+	GeneratedAt    string
+	ChangedSince   string
+	EnvReference   string
+	Cover          *coverPage
+	Contents       []contentsEntry
+	Chapter        string
+	ChapterKind    string
+	Chapters       []templateData
+
+	anchors *model.Anchors
+}
+
+// coverPage holds the cover page fields besides the title and database
+type coverPage struct {
+	Company string
+	Project string
+	Author  string
+}
+
+// contentsEntry is a line of the printed table of contents: a chapter, a
+// numbered section or a table or view within it, one level deeper each
+type contentsEntry struct {
+	Number string
+	Title  string
+	Anchor string
+	Level  int
+}
+
+// tocGroup lists the objects of one schema/owner in the sidebar
+type tocGroup struct {
+	Name     string
+	Tables   []tocEntry
+	Views    []tocEntry
+	Routines []tocEntry
+}
+
+// tocEntry is a single sidebar link
+type tocEntry struct {
+	Name   string
+	Anchor string
+}
+
+// buildTOC groups tables, views and routines by owner, preserving the
+// order in which owners first appear in the schema
+func buildTOC(schema *model.Schema, anchors *model.Anchors) []tocGroup {
+	var groups []tocGroup
+	index := make(map[string]int)
+
+	group := func(owner string) *tocGroup {
+		i, ok := index[owner]
+		if !ok {
+			i = len(groups)
+			index[owner] = i
+			groups = append(groups, tocGroup{Name: owner})
+		}
+		return &groups[i]
+	}
+
+	for _, t := range schema.Tables {
+		g := group(t.Owner)
+		g.Tables = append(g.Tables, tocEntry{Name: t.Name, Anchor: anchors.ID("table", t.Owner, t.Name)})
+	}
+	for _, v := range schema.Views {
+		g := group(v.Owner)
+		g.Views = append(g.Views, tocEntry{Name: v.Name, Anchor: anchors.ID("view", v.Owner, v.Name)})
+	}
+	for _, r := range schema.Routines {
+		g := group(r.Owner)
+		g.Routines = append(g.Routines, tocEntry{Name: r.Name, Anchor: anchors.ID("routine", r.Owner, r.Name)})
+	}
+
+	return groups
+}
+
+// buildContents lists the sections of the embedded template in document
+// order, numbered as the section headings are, with the tables and views
+// under their sections and, when grouped by schema, the sections under
+// their chapters
+func buildContents(data templateData, msg *i18n.Bundle, withCoverage bool) []contentsEntry {
+	var entries []contentsEntry
+	sections := 0
+	section := func(key, anchor string, level int) {
+		sections++
+		entries = append(entries, contentsEntry{Number: strconv.Itoa(sections), Title: msg.T(key), Anchor: anchor, Level: level})
+	}
+	object := func(kind, owner, name string, i, level int) {
+		entries = append(entries, contentsEntry{
+			Number: strconv.Itoa(sections) + "." + strconv.Itoa(i+1),
+			Title:  msg.T("object."+kind) + ": " + data.Namer.Name(owner, name),
+			Anchor: data.anchors.ID(kind, owner, name),
+			Level:  level,
+		})
+	}
+	objects := func(d templateData, level int) {
+		if len(d.Tables) > 0 {
+			section("section.tables", d.Section("tables"), level)
+			for i, t := range d.Tables {
+				object("table", t.Owner, t.Name, i, level+1)
+			}
+		}
+		if len(d.Views) > 0 {
+			section("section.views", d.Section("views"), level)
+			for i, v := range d.Views {
+				object("view", v.Owner, v.Name, i, level+1)
+			}
+		}
+		if len(d.Routines) > 0 {
+			section("section.routines", d.Section("routines"), level)
+		}
+		if len(d.Triggers) > 0 {
+			section("section.triggers", d.Section("triggers"), level)
+		}
+		if len(d.Sequences) > 0 {
+			section("section.sequences", d.Section("sequences"), level)
+		}
+	}
+
+	if len(data.Sources) > 0 {
+		section("section.databases", "section-databases", 1)
+	}
+	if len(data.Tenants) > 0 {
+		section("section.tenants", "section-tenants", 1)
+	}
+	if data.ERD != "" {
+		section("section.erd", "section-erd", 1)
+	}
+	if data.Impact != nil {
+		section("section.impact", "section-impact", 1)
+	}
+	if len(data.Chapters) == 0 {
+		objects(data, 1)
+	}
+	for _, c := range data.Chapters {
+		entries = append(entries, contentsEntry{Title: msg.T("label."+c.ChapterKind) + ": " + c.Chapter, Anchor: model.Anchor(c.ChapterKind, "", c.Chapter), Level: 1})
+		objects(c, 2)
+	}
+	if withCoverage {
+		section("section.coverage", "section-coverage", 1)
+	}
+	if data.Quality != nil {
+		section("section.quality", "section-quality", 1)
+	}
+	if data.Cleanup != nil {
+		section("section.cleanup", "section-cleanup", 1)
+	}
+	if data.Naming != nil {
+		section("section.naming", "section-naming", 1)
+	}
+	if len(data.Migrations) > 0 {
+		section("section.migrations", "section-migrations", 1)
+	}
+	if len(data.Glossary) > 0 {
+		section("section.glossary", "section-glossary", 1)
+	}
+	return entries
+}
+
+// Section returns the element id of a section ("tables", "views", ...),
+// qualified with the chapter when grouped
+func (d templateData) Section(name string) string {
+	return model.Anchor("section", d.Chapter, name)
+}
@@ -1,48 +1,138 @@
-﻿package exporter
-
-import (
-	"pocket-doc/internal/model"
-	"io"
-)
-
-// Exporter defines the interface for exporting schema to various formats
-type Exporter interface {
-	// Export writes the schema to the provided writer in the specific format
-	Export(schema *model.Schema, w io.Writer) error
-
-	// Format returns the format name (e.g., "xlsx", "docx", "html", "pdf")
-	Format() string
-
-	// MimeType returns the MIME type for HTTP response headers
-	MimeType() string
-
-	// FileExtension returns the file extension (e.g., ".xlsx", ".docx")
-	FileExtension() string
-}
-
-// Config holds common configuration for all exporters
-type Config struct {
-	// Language for templates (en, ko)
-	Language string
-
-	// IncludeTOC enables Table of Contents generation
-	IncludeTOC bool
-
-	// IncludeCoverPage adds a cover page (for Word/PDF)
-	IncludeCoverPage bool
-
-	// CompanyName for cover page
-	CompanyName string
-
-	// ProjectName for cover page
-	ProjectName string
-
-	// Author name
-	Author string
-
-	// ExcludeTypes allows skipping certain object types
-	ExcludeTypes []string
-
-	// ColorScheme for Excel/Word styling ("default", "professional", "minimal")
-	ColorScheme string
-}
+﻿package exporter
+
+import (
+	"context"
+	"pocket-doc/internal/fonts"
+	"pocket-doc/internal/datefmt"
+	"pocket-doc/internal/lint"
+	"pocket-doc/internal/model"
+	"pocket-doc/internal/page"
+	"io"
+	"log/slog"
+)
+
+// Exporter defines the interface for exporting schema to various formats
+type Exporter interface {
+	// Export writes the schema to the provided writer in the specific format.
+	// It stops with ctx.Err() when ctx is canceled.
+	Export(ctx context.Context, schema *model.Schema, w io.Writer) error
+
+	// Format returns the format name (e.g., "xlsx", "docx", "html", "pdf")
+	Format() string
+
+	// MimeType returns the MIME type for HTTP response headers
+	MimeType() string
+
+	// FileExtension returns the file extension (e.g., ".xlsx", ".docx")
+	FileExtension() string
+}
+
+// Config holds common configuration for all exporters
+type Config struct {
+	// Language for templates (en, ko)
+	Language string
+
+	// IncludeTOC enables Table of Contents generation
+	IncludeTOC bool
+
+	// IncludeCoverPage adds a cover page (HTML, Word/PDF)
+	IncludeCoverPage bool
+
+	// CompanyName for cover page
+	CompanyName string
+
+	// ProjectName for cover page
+	ProjectName string
+
+	// Author name
+	Author string
+
+	// ExcludeTypes allows skipping certain object types
+	ExcludeTypes []string
+
+	// ColorScheme for Excel/Word styling ("default", "professional", "minimal")
+	ColorScheme string
+
+	// Template is a custom HTML template path overriding the embedded one
+	Template string
+
+	// CSSFile is an extra stylesheet appended to the HTML output
+	CSSFile string
+
+	// PageBreak controls HTML print page breaks ("avoid", "table", "none")
+	PageBreak string
+
+	// SortBy orders objects in every format ("catalog", "name", "schema", "rows")
+	SortBy string
+
+	// RawOrder keeps objects, columns and other lists in the order the
+	// extractor returned them instead of normalizing it (see Normalize)
+	RawOrder bool
+
+	// GroupBy organizes documents by schema ("schema"): a chapter per
+	// schema in Word and HTML, grouping rows in Excel; or by business
+	// domain ("domain"): a chapter per domain in Word and HTML; "" mixes
+	// schemas
+	GroupBy string
+
+	// Classification is a confidentiality label (e.g. "INTERNAL", "대외비") shown as
+	// a watermark/header in Word, a banner in HTML and a header row in Excel
+	Classification string
+
+	// IncludeCoverage adds a comment coverage section to the Word/HTML/Excel output
+	IncludeCoverage bool
+
+	// IncludeQuality adds a data quality appendix (see package verify) to
+	// the Word/HTML/Excel output
+	IncludeQuality bool
+
+	// IncludeCleanup adds an appendix of cleanup candidates (see package
+	// cleanup) to the Word/HTML/Excel output
+	IncludeCleanup bool
+
+	// IncludeNaming adds a dictionary of the column prefixes and suffixes
+	// (see package naming) to the Word/HTML/Excel output
+	IncludeNaming bool
+
+	// IncludeImpact adds a matrix of the views using each base table (see
+	// package impact) to the Word/HTML/Excel output
+	IncludeImpact bool
+
+	// LineageDepth lists, under each table of Word and HTML documents, the
+	// tables it references and that reference it through foreign keys, up
+	// to this many levels (0 = none)
+	LineageDepth int
+
+	// IncludeERD adds an entity relationship diagram of the foreign keys,
+	// laid out and rendered in process (see package erd), to Word and HTML
+	IncludeERD bool
+
+	// HideSingleSchema shows object names without their owner when every
+	// documented object belongs to the same schema; names are otherwise
+	// owner-qualified ("HR.EMPLOYEES") so objects of different schemas
+	// cannot be confused
+	HideSingleSchema bool
+
+	// MinColumnWidth and MaxColumnWidth bound the Excel column widths,
+	// which fit the longest value of each column (0 = 8 and 60 characters)
+	MinColumnWidth float64
+	MaxColumnWidth float64
+
+	// Font selects the Word and HTML fonts (empty = defaults of Language)
+	Font fonts.Config
+
+	// Dates sets the time zone and layout of timestamps such as the
+	// extraction time (empty = as extracted, in each format's layout)
+	Dates datefmt.Config
+
+	// Page sets the paper size and orientation of Word, printed HTML and
+	// Excel, with overrides per format (empty = A4 portrait; Excel keeps
+	// the printer default)
+	Page page.Config
+
+	// Lint configures the rules of the "lint" report format
+	Lint lint.Config
+
+	// Logger receives debug-level export timing (nil = slog.Default())
+	Logger *slog.Logger
+}
@@ -0,0 +1,42 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"pocket-doc/internal/model"
+	"io"
+)
+
+// jsonExporter writes the schema as indented JSON, the snapshot format read
+// by the diff command
+type jsonExporter struct{}
+
+// newJSON builds the built-in JSON snapshot exporter
+func newJSON(cfg Config) (Exporter, error) {
+	return jsonExporter{}, nil
+}
+
+// Export writes the schema model as JSON
+func (jsonExporter) Export(ctx context.Context, schema *model.Schema, w io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(schema)
+}
+
+// Format returns the format name
+func (jsonExporter) Format() string {
+	return "json"
+}
+
+// MimeType returns the MIME type
+func (jsonExporter) MimeType() string {
+	return "application/json"
+}
+
+// FileExtension returns the file extension
+func (jsonExporter) FileExtension() string {
+	return ".json"
+}
@@ -0,0 +1,44 @@
+package exporter
+
+import (
+	"context"
+	"pocket-doc/internal/lint"
+	"pocket-doc/internal/model"
+	"io"
+)
+
+// lintExporter writes the schema lint findings as plain text
+type lintExporter struct {
+	rules lint.Config
+}
+
+// newLint builds the built-in lint report exporter
+func newLint(cfg Config) (Exporter, error) {
+	if err := cfg.Lint.Validate(); err != nil {
+		return nil, err
+	}
+	return &lintExporter{rules: cfg.Lint}, nil
+}
+
+// Export runs the configured lint rules and writes the findings
+func (e *lintExporter) Export(ctx context.Context, schema *model.Schema, w io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return lint.WriteText(w, lint.Run(schema, e.rules))
+}
+
+// Format returns the format name
+func (e *lintExporter) Format() string {
+	return "lint"
+}
+
+// MimeType returns the MIME type
+func (e *lintExporter) MimeType() string {
+	return "text/plain; charset=utf-8"
+}
+
+// FileExtension returns the file extension
+func (e *lintExporter) FileExtension() string {
+	return ".txt"
+}
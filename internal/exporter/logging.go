@@ -0,0 +1,42 @@
+package exporter
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"pocket-doc/internal/model"
+	"time"
+)
+
+// loggingExporter logs the duration of every export at debug level
+type loggingExporter struct {
+	Exporter
+	log *slog.Logger
+}
+
+// withLogging wraps exp so exports are timed on logger (nil = slog.Default())
+func withLogging(exp Exporter, logger *slog.Logger) Exporter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return loggingExporter{Exporter: exp, log: logger}
+}
+
+func (e loggingExporter) Export(ctx context.Context, schema *model.Schema, w io.Writer) error {
+	start := time.Now()
+	err := e.Exporter.Export(ctx, schema, w)
+
+	if e.log.Enabled(ctx, slog.LevelDebug) {
+		attrs := []any{
+			slog.String("format", e.Format()),
+			slog.Int("tables", len(schema.Tables)),
+			slog.Int("views", len(schema.Views)),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		e.log.DebugContext(ctx, "export", attrs...)
+	}
+	return err
+}
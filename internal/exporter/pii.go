@@ -0,0 +1,43 @@
+package exporter
+
+import (
+	"context"
+	"pocket-doc/internal/i18n"
+	"pocket-doc/internal/model"
+	"pocket-doc/internal/pii"
+	"io"
+)
+
+// piiExporter writes the inventory of sensitive columns as CSV
+type piiExporter struct {
+	msg *i18n.Bundle
+}
+
+// newPII builds the built-in PII inventory exporter
+func newPII(cfg Config) (Exporter, error) {
+	return &piiExporter{msg: i18n.New(cfg.Language)}, nil
+}
+
+// Export writes every column tagged by a PII rule or classified by an
+// annotation
+func (e *piiExporter) Export(ctx context.Context, schema *model.Schema, w io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return pii.WriteCSV(w, pii.Inventory(schema), e.msg)
+}
+
+// Format returns the format name
+func (e *piiExporter) Format() string {
+	return "pii"
+}
+
+// MimeType returns the MIME type
+func (e *piiExporter) MimeType() string {
+	return "text/csv; charset=utf-8"
+}
+
+// FileExtension returns the file extension
+func (e *piiExporter) FileExtension() string {
+	return ".csv"
+}
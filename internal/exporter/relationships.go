@@ -0,0 +1,105 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"pocket-doc/internal/i18n"
+	"pocket-doc/internal/model"
+	"io"
+	"strings"
+)
+
+// relationshipsExporter writes the parent and child tables of every table
+// as plain text, followed by the tables without relationships, circular
+// references and foreign keys to tables outside the document
+type relationshipsExporter struct {
+	msg *i18n.Bundle
+}
+
+// newRelationships builds the built-in relationships report exporter
+func newRelationships(cfg Config) (Exporter, error) {
+	return &relationshipsExporter{msg: i18n.New(cfg.Language)}, nil
+}
+
+// Export writes the relationship report of the schema's foreign keys
+func (e *relationshipsExporter) Export(ctx context.Context, schema *model.Schema, w io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	graph := model.NewGraph(schema)
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s\n", e.msg.T("section.relationships"))
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		parents, children := graph.Outbound(t), graph.Inbound(t)
+		if len(parents) == 0 && len(children) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s\n", qualifiedName(t))
+		if len(parents) > 0 {
+			fmt.Fprintf(&b, "  %s:\n", e.msg.T("label.parent_tables"))
+			for _, r := range parents {
+				parent := r.ParentName
+				if r.Parent != nil {
+					parent = qualifiedName(r.Parent)
+				}
+				fmt.Fprintf(&b, "    - %s (%s → %s)\n", parent, r.Column, r.ParentColumn)
+			}
+		}
+		if len(children) > 0 {
+			fmt.Fprintf(&b, "  %s:\n", e.msg.T("label.child_tables"))
+			for _, r := range children {
+				fmt.Fprintf(&b, "    - %s (%s → %s)\n", qualifiedName(r.Child), r.Column, r.ParentColumn)
+			}
+		}
+	}
+
+	if orphans := graph.Orphans(); len(orphans) > 0 {
+		fmt.Fprintf(&b, "\n%s (%d)\n", e.msg.T("section.orphan_tables"), len(orphans))
+		for _, t := range orphans {
+			fmt.Fprintf(&b, "  - %s\n", qualifiedName(t))
+		}
+	}
+
+	if cycles := graph.Cycles(); len(cycles) > 0 {
+		fmt.Fprintf(&b, "\n%s (%d)\n", e.msg.T("section.fk_cycles"), len(cycles))
+		for _, cycle := range cycles {
+			names := make([]string, len(cycle))
+			for i, t := range cycle {
+				names[i] = qualifiedName(t)
+			}
+			fmt.Fprintf(&b, "  - %s\n", strings.Join(names, ", "))
+		}
+	}
+
+	if unresolved := graph.Unresolved(); len(unresolved) > 0 {
+		fmt.Fprintf(&b, "\n%s (%d)\n", e.msg.T("section.unresolved_fks"), len(unresolved))
+		for _, r := range unresolved {
+			fmt.Fprintf(&b, "  - %s.%s → %s.%s\n", qualifiedName(r.Child), r.Column, r.ParentName, r.ParentColumn)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// qualifiedName returns "owner.table", or the table name without an owner
+func qualifiedName(t *model.Table) string {
+	return model.QualifiedName(t.Owner, t.Name)
+}
+
+// Format returns the format name
+func (e *relationshipsExporter) Format() string {
+	return "relationships"
+}
+
+// MimeType returns the MIME type
+func (e *relationshipsExporter) MimeType() string {
+	return "text/plain; charset=utf-8"
+}
+
+// FileExtension returns the file extension
+func (e *relationshipsExporter) FileExtension() string {
+	return ".txt"
+}
@@ -0,0 +1,307 @@
+package exporter
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"pocket-doc/internal/model"
+	"slices"
+	"strings"
+)
+
+// Sort orders for Config.SortBy
+const (
+	SortCatalog  = "catalog" // normalized order, see Normalize (default)
+	SortName     = "name"    // object name
+	SortOwner    = "schema"  // schema/owner, then name
+	SortRowCount = "rows"    // tables by row count descending, other objects by name
+)
+
+// Groupings for Config.GroupBy
+const (
+	GroupSchema = "schema" // objects by schema/owner
+	GroupDomain = "domain" // chapters per business domain (see combine.SplitByDomain)
+)
+
+// validGrouping reports whether group is a supported Config.GroupBy value
+func validGrouping(group string) bool {
+	return group == "" || group == GroupSchema || group == GroupDomain
+}
+
+// Grouped reports whether cfg organizes documents by schema
+func (cfg Config) Grouped() bool {
+	return strings.EqualFold(strings.TrimSpace(cfg.GroupBy), GroupSchema)
+}
+
+// GroupedByDomain reports whether cfg has a chapter per business domain
+func (cfg Config) GroupedByDomain() bool {
+	return strings.EqualFold(strings.TrimSpace(cfg.GroupBy), GroupDomain)
+}
+
+// validSortOrder reports whether order is a supported sort order
+func validSortOrder(order string) bool {
+	switch order {
+	case "", SortCatalog, SortName, SortOwner, SortRowCount:
+		return true
+	}
+	return false
+}
+
+// SortSchema returns a copy of schema with all object lists ordered by the
+// given sort order. The input schema is not modified; columns keep their
+// ordinal position order.
+func SortSchema(schema *model.Schema, order string) *model.Schema {
+	order = strings.ToLower(strings.TrimSpace(order))
+	if schema == nil || order == "" || order == SortCatalog {
+		return schema
+	}
+
+	sorted := *schema
+	sorted.Tables = sortedCopy(schema.Tables, byKey(order, func(t model.Table) sortKey {
+		return sortKey{owner: t.Owner, name: t.Name, rows: t.RowCount}
+	}))
+	sorted.Views = sortedCopy(schema.Views, byKey(order, func(v model.View) sortKey {
+		return sortKey{owner: v.Owner, name: v.Name}
+	}))
+	sorted.Routines = sortedCopy(schema.Routines, byKey(order, func(r model.Routine) sortKey {
+		return sortKey{owner: r.Owner, name: r.Name}
+	}))
+	sorted.Sequences = sortedCopy(schema.Sequences, byKey(order, func(s model.Sequence) sortKey {
+		return sortKey{owner: s.Owner, name: s.Name}
+	}))
+	sorted.Triggers = sortedCopy(schema.Triggers, byKey(order, func(t model.Trigger) sortKey {
+		return sortKey{owner: t.Owner, name: t.Name}
+	}))
+	sorted.Synonyms = sortedCopy(schema.Synonyms, byKey(order, func(s model.Synonym) sortKey {
+		return sortKey{owner: s.Owner, name: s.Name}
+	}))
+	sorted.Indexes = sortedCopy(schema.Indexes, byKey(order, func(i model.Index) sortKey {
+		return sortKey{owner: i.Owner, name: i.Name}
+	}))
+	return &sorted
+}
+
+// OrderSchema returns schema in the object order of cfg: normalized unless
+// cfg.RawOrder is set, then sorted by cfg.SortBy and, when cfg is grouped
+// by schema, with the objects of each owner together
+func OrderSchema(schema *model.Schema, cfg Config) *model.Schema {
+	if !cfg.RawOrder {
+		schema = Normalize(schema)
+	}
+	schema = SortSchema(schema, cfg.SortBy)
+	if cfg.Grouped() {
+		schema = groupByOwner(schema)
+	}
+	return schema
+}
+
+// groupByOwner returns a copy of schema with the objects of each owner
+// next to each other, owners by name, keeping their order within an owner
+func groupByOwner(schema *model.Schema) *model.Schema {
+	if schema == nil {
+		return nil
+	}
+	g := *schema
+	g.Tables = sortedCopy(schema.Tables, func(a, b model.Table) int { return strings.Compare(a.Owner, b.Owner) })
+	g.Views = sortedCopy(schema.Views, func(a, b model.View) int { return strings.Compare(a.Owner, b.Owner) })
+	g.Routines = sortedCopy(schema.Routines, func(a, b model.Routine) int { return strings.Compare(a.Owner, b.Owner) })
+	g.Sequences = sortedCopy(schema.Sequences, func(a, b model.Sequence) int { return strings.Compare(a.Owner, b.Owner) })
+	g.Triggers = sortedCopy(schema.Triggers, func(a, b model.Trigger) int { return strings.Compare(a.Owner, b.Owner) })
+	g.Synonyms = sortedCopy(schema.Synonyms, func(a, b model.Synonym) int { return strings.Compare(a.Owner, b.Owner) })
+	g.Indexes = sortedCopy(schema.Indexes, func(a, b model.Index) int { return strings.Compare(a.Owner, b.Owner) })
+	return &g
+}
+
+// Normalize returns a copy of schema with every list in a deterministic
+// order, so that documents of an unchanged database are identical between
+// runs whatever order the catalog queries and map iteration produced:
+// objects by schema and name, columns and routine arguments by position,
+// table indexes by name, and terms, PII rules, examples and glossary terms
+// alphabetically. Index columns keep their key order, and the databases of
+// a combined schema the configured order. The input schema is not
+// modified; lists already in order are shared with it rather than copied,
+// so normalizing the usual, catalog-ordered schema costs no memory.
+func Normalize(schema *model.Schema) *model.Schema {
+	if schema == nil {
+		return nil
+	}
+
+	n := *schema
+	n.Tables = sortedCopy(schema.Tables, func(a, b model.Table) int {
+		return compareNames(a.Owner, a.Name, b.Owner, b.Name)
+	})
+	n.Tables = normalizeEach(n.Tables, schema.Tables, func(t *model.Table) bool {
+		var changed bool
+		sortInto(&changed, &t.Columns, normalizeColumns(t.Columns))
+		sortInto(&changed, &t.Indexes, sortedCopy(t.Indexes, compareIndexes))
+		sortInto(&changed, &t.Terms, sortedCopy(t.Terms, strings.Compare))
+		sortInto(&changed, &t.ReferencedBy, sortedCopy(t.ReferencedBy, func(a, b model.ObjectRef) int {
+			return cmp.Or(strings.Compare(a.Type, b.Type), compareNames(a.Owner, a.Name, b.Owner, b.Name))
+		}))
+		return changed
+	})
+	n.Views = sortedCopy(schema.Views, func(a, b model.View) int {
+		return compareNames(a.Owner, a.Name, b.Owner, b.Name)
+	})
+	n.Views = normalizeEach(n.Views, schema.Views, func(v *model.View) bool {
+		var changed bool
+		sortInto(&changed, &v.Columns, normalizeColumns(v.Columns))
+		sortInto(&changed, &v.Terms, sortedCopy(v.Terms, strings.Compare))
+		return changed
+	})
+	n.Routines = sortedCopy(schema.Routines, func(a, b model.Routine) int {
+		// Overloads share a name
+		return cmp.Or(compareNames(a.Owner, a.Name, b.Owner, b.Name), strings.Compare(a.Signature, b.Signature))
+	})
+	n.Routines = normalizeEach(n.Routines, schema.Routines, func(r *model.Routine) bool {
+		var changed bool
+		sortInto(&changed, &r.Arguments, sortedCopy(r.Arguments, func(a, b model.RoutineArgument) int {
+			return cmp.Or(cmp.Compare(a.Position, b.Position), strings.Compare(a.Name, b.Name))
+		}))
+		sortInto(&changed, &r.Touches, sortedCopy(r.Touches, func(a, b model.ObjectRef) int {
+			return compareNames(a.Owner, a.Name, b.Owner, b.Name)
+		}))
+		return changed
+	})
+	n.Sequences = sortedCopy(schema.Sequences, func(a, b model.Sequence) int {
+		return compareNames(a.Owner, a.Name, b.Owner, b.Name)
+	})
+	n.Triggers = sortedCopy(schema.Triggers, func(a, b model.Trigger) int {
+		return cmp.Or(compareNames(a.Owner, a.Name, b.Owner, b.Name), strings.Compare(a.TargetTable, b.TargetTable))
+	})
+	n.Synonyms = sortedCopy(schema.Synonyms, func(a, b model.Synonym) int {
+		return compareNames(a.Owner, a.Name, b.Owner, b.Name)
+	})
+	n.Indexes = sortedCopy(schema.Indexes, compareIndexes)
+	n.Glossary = sortedCopy(schema.Glossary, func(a, b model.GlossaryTerm) int {
+		return strings.Compare(a.Term, b.Term)
+	})
+	return &n
+}
+
+// normalizeColumns returns columns ordered by position, with their string
+// lists sorted; columns already in order are returned as is
+func normalizeColumns(columns []model.Column) []model.Column {
+	out := sortedCopy(columns, func(a, b model.Column) int {
+		return cmp.Or(cmp.Compare(a.Position, b.Position), strings.Compare(a.Name, b.Name))
+	})
+	return normalizeEach(out, columns, func(c *model.Column) bool {
+		var changed bool
+		sortInto(&changed, &c.Terms, sortedCopy(c.Terms, strings.Compare))
+		sortInto(&changed, &c.PII, sortedCopy(c.PII, strings.Compare))
+		sortInto(&changed, &c.Examples, sortedCopy(c.Examples, strings.Compare))
+		return changed
+	})
+}
+
+// normalizeEach applies normalize to a copy of each item of items and
+// returns items with the changed ones replaced. items is copied before the
+// first replacement when it still shares its array with orig, the input
+// list, which is never modified.
+func normalizeEach[T any](items, orig []T, normalize func(*T) bool) []T {
+	shared := sameSlice(items, orig)
+	for i := range items {
+		item := items[i]
+		if !normalize(&item) {
+			continue
+		}
+		if shared {
+			items = slices.Clone(items)
+			shared = false
+		}
+		items[i] = item
+	}
+	return items
+}
+
+// sortInto stores sorted in *list and sets *changed when it is a new list
+func sortInto[T any](changed *bool, list *[]T, sorted []T) {
+	if !sameSlice(*list, sorted) {
+		*list = sorted
+		*changed = true
+	}
+}
+
+// sameSlice reports whether a and b are the same list
+func sameSlice[T any](a, b []T) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
+
+// compareIndexes orders indexes by schema, name and table
+func compareIndexes(a, b model.Index) int {
+	return cmp.Or(compareNames(a.Owner, a.Name, b.Owner, b.Name), strings.Compare(a.TableName, b.TableName))
+}
+
+// compareNames orders objects by owner and name, case-insensitively with
+// the exact spelling breaking ties, so the order is total
+func compareNames(ownerA, nameA, ownerB, nameB string) int {
+	return cmp.Or(
+		strings.Compare(strings.ToLower(ownerA), strings.ToLower(ownerB)),
+		strings.Compare(strings.ToLower(nameA), strings.ToLower(nameB)),
+		strings.Compare(ownerA, ownerB),
+		strings.Compare(nameA, nameB),
+	)
+}
+
+// sortedCopy returns items when they are already in order and otherwise
+// a stably sorted copy; items itself is never modified
+func sortedCopy[T any](items []T, compare func(a, b T) int) []T {
+	if slices.IsSortedFunc(items, compare) {
+		return items
+	}
+	out := slices.Clone(items)
+	slices.SortStableFunc(out, compare)
+	return out
+}
+
+// sortKey holds the attributes objects are ordered by
+type sortKey struct {
+	owner string
+	name  string
+	rows  int64
+}
+
+// byKey returns the comparison of objects by their sort key in the given
+// sort order, names breaking ties
+func byKey[T any](order string, key func(T) sortKey) func(a, b T) int {
+	return func(a, b T) int {
+		ka, kb := key(a), key(b)
+		var c int
+		switch order {
+		case SortOwner:
+			c = strings.Compare(strings.ToLower(ka.owner), strings.ToLower(kb.owner))
+		case SortRowCount:
+			c = cmp.Compare(kb.rows, ka.rows)
+		}
+		return cmp.Or(c, strings.Compare(strings.ToLower(ka.name), strings.ToLower(kb.name)))
+	}
+}
+
+// sortingExporter applies the configured object order before delegating
+type sortingExporter struct {
+	Exporter
+	cfg Config
+}
+
+// Export orders a copy of the schema and exports it with the wrapped exporter
+func (s sortingExporter) Export(ctx context.Context, schema *model.Schema, w io.Writer) error {
+	return s.Exporter.Export(ctx, OrderSchema(schema, s.cfg), w)
+}
+
+// withSorting wraps exp so every format sees the same object order
+func withSorting(exp Exporter, cfg Config) (Exporter, error) {
+	order := strings.ToLower(strings.TrimSpace(cfg.SortBy))
+	if !validSortOrder(order) {
+		return nil, fmt.Errorf("unsupported sort order: %s (supported: %s, %s, %s, %s)",
+			order, SortCatalog, SortName, SortOwner, SortRowCount)
+	}
+	group := strings.ToLower(strings.TrimSpace(cfg.GroupBy))
+	if !validGrouping(group) {
+		return nil, fmt.Errorf("unsupported grouping: %s (supported: %s, %s)", group, GroupSchema, GroupDomain)
+	}
+	if cfg.RawOrder && (order == "" || order == SortCatalog) && group == "" {
+		return exp, nil
+	}
+	return sortingExporter{Exporter: exp, cfg: Config{SortBy: order, RawOrder: cfg.RawOrder, GroupBy: group}}, nil
+}
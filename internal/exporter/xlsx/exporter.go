@@ -1,416 +1,1208 @@
-﻿package xlsx
-
-import (
-	"pocket-doc/internal/model"
-	"fmt"
-	"io"
-	"time"
-
-	"github.com/xuri/excelize/v2"
-)
-
-// Config holds configuration for Excel export
-type Config struct {
-	Language     string
-	ExcludeTypes []string
-	ColorScheme  string
-}
-
-// Exporter implements Excel (.xlsx) export functionality
-type Exporter struct {
-	config Config
-}
-
-// NewExporter creates a new Excel exporter
-func NewExporter(cfg Config) *Exporter {
-	return &Exporter{config: cfg}
-}
-
-// Format returns the format name
-func (e *Exporter) Format() string {
-	return "xlsx"
-}
-
-// MimeType returns the MIME type
-func (e *Exporter) MimeType() string {
-	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
-}
-
-// FileExtension returns the file extension
-func (e *Exporter) FileExtension() string {
-	return ".xlsx"
-}
-
-// Export generates an Excel file with 4 sheets (CRITICAL RULE #2)
-func (e *Exporter) Export(schema *model.Schema, w io.Writer) error {
-	f := excelize.NewFile()
-	defer func() {
-		if err := f.Close(); err != nil {
-			fmt.Printf("Error closing Excel file: %v\n", err)
-		}
-	}()
-
-	// CRITICAL RULE #2: 4 Sheets - Overview, Tables, Columns, Objects
-	sheets := []string{"Overview", "Tables", "Columns", "Objects"}
-
-	// Delete default Sheet1 and create our sheets
-	f.DeleteSheet("Sheet1")
-	for _, sheetName := range sheets {
-		_, err := f.NewSheet(sheetName)
-		if err != nil {
-			return fmt.Errorf("failed to create sheet %s: %w", sheetName, err)
-		}
-	}
-
-	// Set Overview as active sheet
-	f.SetActiveSheet(0)
-
-	// Generate content for each sheet
-	if err := e.writeOverview(f, schema); err != nil {
-		return fmt.Errorf("failed to write overview: %w", err)
-	}
-
-	if err := e.writeTables(f, schema); err != nil {
-		return fmt.Errorf("failed to write tables: %w", err)
-	}
-
-	if err := e.writeColumns(f, schema); err != nil {
-		return fmt.Errorf("failed to write columns: %w", err)
-	}
-
-	if err := e.writeObjects(f, schema); err != nil {
-		return fmt.Errorf("failed to write objects: %w", err)
-	}
-
-	// Write to output
-	return f.Write(w)
-}
-
-// writeOverview creates the database summary sheet
-func (e *Exporter) writeOverview(f *excelize.File, schema *model.Schema) error {
-	sheet := "Overview"
-
-	// Headers
-	headers := []string{"항목", "값"}
-	if e.config.Language == "en" {
-		headers = []string{"Item", "Value"}
-	}
-
-	// Write headers
-	for i, header := range headers {
-		cell := fmt.Sprintf("%c1", 'A'+i)
-		f.SetCellValue(sheet, cell, header)
-	}
-
-	// Apply header style (CRITICAL RULE #2: Gray Header)
-	headerStyle := e.getHeaderStyle(f)
-	f.SetCellStyle(sheet, "A1", fmt.Sprintf("%c1", 'A'+len(headers)-1), headerStyle)
-
-	// Write data
-	row := 2
-	data := [][]interface{}{
-		{"데이터베이스 이름", schema.DatabaseName},
-		{"데이터베이스 유형", schema.DatabaseType},
-		{"버전", schema.Version},
-		{"추출 시간", schema.ExtractedAt.Format(time.RFC3339)},
-		{"총 테이블 수", len(schema.Tables)},
-		{"총 뷰 수", len(schema.Views)},
-		{"총 프로시저/함수 수", len(schema.Routines)},
-		{"총 시퀀스 수", len(schema.Sequences)},
-		{"총 트리거 수", len(schema.Triggers)},
-		{"총 동의어 수", len(schema.Synonyms)},
-		{"총 인덱스 수", len(schema.Indexes)},
-	}
-
-	if e.config.Language == "en" {
-		data = [][]interface{}{
-			{"Database Name", schema.DatabaseName},
-			{"Database Type", schema.DatabaseType},
-			{"Version", schema.Version},
-			{"Extracted At", schema.ExtractedAt.Format(time.RFC3339)},
-			{"Total Tables", len(schema.Tables)},
-			{"Total Views", len(schema.Views)},
-			{"Total Routines", len(schema.Routines)},
-			{"Total Sequences", len(schema.Sequences)},
-			{"Total Triggers", len(schema.Triggers)},
-			{"Total Synonyms", len(schema.Synonyms)},
-			{"Total Indexes", len(schema.Indexes)},
-		}
-	}
-
-	for _, rowData := range data {
-		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), rowData[0])
-		f.SetCellValue(sheet, fmt.Sprintf("B%d", row), rowData[1])
-		row++
-	}
-
-	// Auto-fit columns
-	f.SetColWidth(sheet, "A", "A", 25)
-	f.SetColWidth(sheet, "B", "B", 30)
-
-	return nil
-}
-
-// writeTables creates the tables sheet
-func (e *Exporter) writeTables(f *excelize.File, schema *model.Schema) error {
-	sheet := "Tables"
-
-	// Headers
-	headers := []string{"이름", "소유자", "유형", "컬럼 수", "인덱스 수", "행 수", "설명"}
-	if e.config.Language == "en" {
-		headers = []string{"Name", "Owner", "Type", "Column Count", "Index Count", "Row Count", "Comment"}
-	}
-
-	for i, header := range headers {
-		cell := fmt.Sprintf("%c1", 'A'+i)
-		f.SetCellValue(sheet, cell, header)
-	}
-
-	headerStyle := e.getHeaderStyle(f)
-	f.SetCellStyle(sheet, "A1", fmt.Sprintf("%c1", 'A'+len(headers)-1), headerStyle)
-
-	// Data
-	row := 2
-	for _, table := range schema.Tables {
-		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), table.Name)
-		f.SetCellValue(sheet, fmt.Sprintf("B%d", row), table.Owner)
-		f.SetCellValue(sheet, fmt.Sprintf("C%d", row), table.Type)
-		f.SetCellValue(sheet, fmt.Sprintf("D%d", row), len(table.Columns))
-		f.SetCellValue(sheet, fmt.Sprintf("E%d", row), len(table.Indexes))
-		f.SetCellValue(sheet, fmt.Sprintf("F%d", row), table.RowCount)
-		f.SetCellValue(sheet, fmt.Sprintf("G%d", row), table.Comment)
-		row++
-	}
-
-	// Auto-fit
-	f.SetColWidth(sheet, "A", "A", 25)
-	f.SetColWidth(sheet, "B", "B", 15)
-	f.SetColWidth(sheet, "C", "C", 15)
-	f.SetColWidth(sheet, "D", "D", 12)
-	f.SetColWidth(sheet, "E", "E", 12)
-	f.SetColWidth(sheet, "F", "F", 12)
-	f.SetColWidth(sheet, "G", "G", 40)
-
-	return nil
-}
-
-// writeColumns creates the columns detail sheet
-func (e *Exporter) writeColumns(f *excelize.File, schema *model.Schema) error {
-	sheet := "Columns"
-
-	headers := []string{"테이블", "컬럼명", "순서", "데이터타입", "NULL허용", "PK", "FK", "UK", "기본값", "설명"}
-	if e.config.Language == "en" {
-		headers = []string{"Table", "Column Name", "Position", "Data Type", "Nullable", "PK", "FK", "UK", "Default", "Comment"}
-	}
-
-	for i, header := range headers {
-		cell := fmt.Sprintf("%c1", 'A'+i)
-		f.SetCellValue(sheet, cell, header)
-	}
-
-	headerStyle := e.getHeaderStyle(f)
-	f.SetCellStyle(sheet, "A1", "J1", headerStyle)
-
-	row := 2
-	for _, table := range schema.Tables {
-		for _, col := range table.Columns {
-			f.SetCellValue(sheet, fmt.Sprintf("A%d", row), table.Name)
-			f.SetCellValue(sheet, fmt.Sprintf("B%d", row), col.Name)
-			f.SetCellValue(sheet, fmt.Sprintf("C%d", row), col.Position)
-			f.SetCellValue(sheet, fmt.Sprintf("D%d", row), col.DataType)
-			f.SetCellValue(sheet, fmt.Sprintf("E%d", row), boolToYN(col.Nullable))
-			f.SetCellValue(sheet, fmt.Sprintf("F%d", row), boolToYN(col.IsPrimaryKey))
-			f.SetCellValue(sheet, fmt.Sprintf("G%d", row), boolToYN(col.IsForeignKey))
-			f.SetCellValue(sheet, fmt.Sprintf("H%d", row), boolToYN(col.IsUnique))
-			f.SetCellValue(sheet, fmt.Sprintf("I%d", row), col.DefaultValue)
-			f.SetCellValue(sheet, fmt.Sprintf("J%d", row), col.Comment)
-			row++
-		}
-	}
-
-	// Auto-fit
-	f.SetColWidth(sheet, "A", "A", 20)
-	f.SetColWidth(sheet, "B", "B", 20)
-	f.SetColWidth(sheet, "C", "C", 8)
-	f.SetColWidth(sheet, "D", "D", 15)
-	f.SetColWidth(sheet, "E", "E", 8)
-	f.SetColWidth(sheet, "F", "F", 6)
-	f.SetColWidth(sheet, "G", "G", 6)
-	f.SetColWidth(sheet, "H", "H", 6)
-	f.SetColWidth(sheet, "I", "I", 15)
-	f.SetColWidth(sheet, "J", "J", 40)
-
-	return nil
-}
-
-// writeObjects creates the combined objects sheet (Routines, Sequences, Triggers, Synonyms)
-func (e *Exporter) writeObjects(f *excelize.File, schema *model.Schema) error {
-	sheet := "Objects"
-	row := 1
-
-	// Routines section (NO source code - SECURITY)
-	if len(schema.Routines) > 0 {
-		// Section header
-		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), "프로시저/함수")
-		if e.config.Language == "en" {
-			f.SetCellValue(sheet, fmt.Sprintf("A%d", row), "ROUTINES")
-		}
-		f.MergeCell(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("G%d", row))
-		row++
-
-		headers := []string{"이름", "소유자", "유형", "서명", "반환타입", "언어", "설명"}
-		if e.config.Language == "en" {
-			headers = []string{"Name", "Owner", "Type", "Signature", "Return Type", "Language", "Comment"}
-		}
-		for i, h := range headers {
-			f.SetCellValue(sheet, fmt.Sprintf("%c%d", 'A'+i, row), h)
-		}
-		headerStyle := e.getHeaderStyle(f)
-		f.SetCellStyle(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("G%d", row), headerStyle)
-		row++
-
-		for _, routine := range schema.Routines {
-			f.SetCellValue(sheet, fmt.Sprintf("A%d", row), routine.Name)
-			f.SetCellValue(sheet, fmt.Sprintf("B%d", row), routine.Owner)
-			f.SetCellValue(sheet, fmt.Sprintf("C%d", row), routine.Type)
-			f.SetCellValue(sheet, fmt.Sprintf("D%d", row), routine.Signature)
-			f.SetCellValue(sheet, fmt.Sprintf("E%d", row), routine.ReturnType)
-			f.SetCellValue(sheet, fmt.Sprintf("F%d", row), routine.Language)
-			f.SetCellValue(sheet, fmt.Sprintf("G%d", row), routine.Comment)
-			row++
-		}
-		row++ // Blank row
-	}
-
-	// Sequences section
-	if len(schema.Sequences) > 0 {
-		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), "시퀀스")
-		if e.config.Language == "en" {
-			f.SetCellValue(sheet, fmt.Sprintf("A%d", row), "SEQUENCES")
-		}
-		f.MergeCell(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("G%d", row))
-		row++
-
-		headers := []string{"이름", "최소값", "최대값", "증가값", "현재값", "순환", "설명"}
-		if e.config.Language == "en" {
-			headers = []string{"Name", "Min", "Max", "Increment", "Current", "Cyclic", "Comment"}
-		}
-		for i, h := range headers {
-			f.SetCellValue(sheet, fmt.Sprintf("%c%d", 'A'+i, row), h)
-		}
-		headerStyle := e.getHeaderStyle(f)
-		f.SetCellStyle(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("G%d", row), headerStyle)
-		row++
-
-		for _, seq := range schema.Sequences {
-			f.SetCellValue(sheet, fmt.Sprintf("A%d", row), seq.Name)
-			f.SetCellValue(sheet, fmt.Sprintf("B%d", row), seq.MinValue)
-			f.SetCellValue(sheet, fmt.Sprintf("C%d", row), seq.MaxValue)
-			f.SetCellValue(sheet, fmt.Sprintf("D%d", row), seq.Increment)
-			f.SetCellValue(sheet, fmt.Sprintf("E%d", row), seq.LastNumber)
-			f.SetCellValue(sheet, fmt.Sprintf("F%d", row), boolToYN(seq.IsCyclic))
-			f.SetCellValue(sheet, fmt.Sprintf("G%d", row), seq.Comment)
-			row++
-		}
-		row++
-	}
-
-	// Triggers section (NO trigger body - SECURITY)
-	if len(schema.Triggers) > 0 {
-		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), "트리거")
-		if e.config.Language == "en" {
-			f.SetCellValue(sheet, fmt.Sprintf("A%d", row), "TRIGGERS")
-		}
-		f.MergeCell(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("G%d", row))
-		row++
-
-		headers := []string{"이름", "테이블", "시점", "이벤트", "레벨", "상태", "설명"}
-		if e.config.Language == "en" {
-			headers = []string{"Name", "Table", "Timing", "Event", "Level", "Status", "Comment"}
-		}
-		for i, h := range headers {
-			f.SetCellValue(sheet, fmt.Sprintf("%c%d", 'A'+i, row), h)
-		}
-		headerStyle := e.getHeaderStyle(f)
-		f.SetCellStyle(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("G%d", row), headerStyle)
-		row++
-
-		for _, trg := range schema.Triggers {
-			f.SetCellValue(sheet, fmt.Sprintf("A%d", row), trg.Name)
-			f.SetCellValue(sheet, fmt.Sprintf("B%d", row), trg.TargetTable)
-			f.SetCellValue(sheet, fmt.Sprintf("C%d", row), trg.Timing)
-			f.SetCellValue(sheet, fmt.Sprintf("D%d", row), trg.Event)
-			f.SetCellValue(sheet, fmt.Sprintf("E%d", row), trg.Level)
-			f.SetCellValue(sheet, fmt.Sprintf("F%d", row), trg.Status)
-			f.SetCellValue(sheet, fmt.Sprintf("G%d", row), trg.Comment)
-			row++
-		}
-		row++
-	}
-
-	// Synonyms section
-	if len(schema.Synonyms) > 0 {
-		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), "동의어")
-		if e.config.Language == "en" {
-			f.SetCellValue(sheet, fmt.Sprintf("A%d", row), "SYNONYMS")
-		}
-		f.MergeCell(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("E%d", row))
-		row++
-
-		headers := []string{"이름", "대상", "소유자", "유형", "설명"}
-		if e.config.Language == "en" {
-			headers = []string{"Name", "Target", "Owner", "Type", "Comment"}
-		}
-		for i, h := range headers {
-			f.SetCellValue(sheet, fmt.Sprintf("%c%d", 'A'+i, row), h)
-		}
-		headerStyle := e.getHeaderStyle(f)
-		f.SetCellStyle(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("E%d", row), headerStyle)
-		row++
-
-		for _, syn := range schema.Synonyms {
-			f.SetCellValue(sheet, fmt.Sprintf("A%d", row), syn.Name)
-			f.SetCellValue(sheet, fmt.Sprintf("B%d", row), syn.TargetObject)
-			f.SetCellValue(sheet, fmt.Sprintf("C%d", row), syn.TargetOwner)
-			f.SetCellValue(sheet, fmt.Sprintf("D%d", row), syn.TargetType)
-			f.SetCellValue(sheet, fmt.Sprintf("E%d", row), syn.Comment)
-			row++
-		}
-	}
-
-	// Auto-fit
-	f.SetColWidth(sheet, "A", "A", 25)
-	f.SetColWidth(sheet, "B", "B", 20)
-	f.SetColWidth(sheet, "D", "D", 50)
-	f.SetColWidth(sheet, "G", "G", 40)
-
-	return nil
-}
-
-// getHeaderStyle returns the gray header style (CRITICAL RULE #2)
-func (e *Exporter) getHeaderStyle(f *excelize.File) int {
-	style, _ := f.NewStyle(&excelize.Style{
-		Font: &excelize.Font{Bold: true, Size: 11},
-		Fill: excelize.Fill{
-			Type:    "pattern",
-			Color:   []string{"#D9D9D9"}, // Gray background
-			Pattern: 1,
-		},
-		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
-		Border: []excelize.Border{
-			{Type: "top", Color: "000000", Style: 1},
-			{Type: "bottom", Color: "000000", Style: 1},
-			{Type: "left", Color: "000000", Style: 1},
-			{Type: "right", Color: "000000", Style: 1},
-		},
-	})
-	return style
-}
-
-// boolToYN converts bool to Y/N string
-func boolToYN(b bool) string {
-	if b {
-		return "Y"
-	}
-	return "N"
-}
+﻿package xlsx
+
+import (
+	"context"
+	"pocket-doc/internal/cleanup"
+	"pocket-doc/internal/combine"
+	"pocket-doc/internal/coverage"
+	"pocket-doc/internal/datefmt"
+	"pocket-doc/internal/i18n"
+	"pocket-doc/internal/impact"
+	"pocket-doc/internal/model"
+	"pocket-doc/internal/naming"
+	"pocket-doc/internal/page"
+	"pocket-doc/internal/verify"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/width"
+)
+
+// Config holds configuration for Excel export
+type Config struct {
+	Language         string
+	ExcludeTypes     []string
+	ColorScheme      string
+	Classification   string         // Confidentiality label inserted as row 1 of every sheet
+	IncludeCoverage  bool           // Comment coverage rows in Overview and section in Objects
+	IncludeQuality   bool           // Data quality section in Objects (see package verify)
+	IncludeCleanup   bool           // Cleanup section in Objects (see package cleanup)
+	IncludeNaming    bool           // Naming conventions section in Objects (see package naming)
+	IncludeImpact    bool           // Views × base tables section in Objects (see package impact)
+	HideSingleSchema bool           // Object names without owner when there is only one
+	MinColumnWidth   float64        // Bounds of content-sized column widths, in characters
+	MaxColumnWidth   float64        // (0 = DefaultMinColumnWidth, DefaultMaxColumnWidth)
+	GroupBySchema    bool           // A grouping row before the objects of each schema
+	Dates            datefmt.Config // Time zone and layout of timestamps (empty = as extracted, RFC 3339)
+	Page             page.Setup     // Printed paper size and orientation (empty = printer default)
+}
+
+// Default bounds of the column widths, which fit the longest value of each
+// column
+const (
+	DefaultMinColumnWidth = 8
+	DefaultMaxColumnWidth = 60
+)
+
+// Exporter implements Excel (.xlsx) export functionality
+type Exporter struct {
+	config  Config
+	msg     *i18n.Bundle
+	maxRows int // rows per sheet before a continuation sheet (excelize.TotalRows)
+}
+
+// NewExporter creates a new Excel exporter
+func NewExporter(cfg Config) *Exporter {
+	return &Exporter{config: cfg, msg: i18n.New(cfg.Language), maxRows: excelize.TotalRows}
+}
+
+// Format returns the format name
+func (e *Exporter) Format() string {
+	return "xlsx"
+}
+
+// MimeType returns the MIME type
+func (e *Exporter) MimeType() string {
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+// FileExtension returns the file extension
+func (e *Exporter) FileExtension() string {
+	return ".xlsx"
+}
+
+// Export generates an Excel file with 4 sheets (CRITICAL RULE #2). Sheets
+// are written with excelize's stream writer: rows go to a temporary file
+// once the buffer exceeds excelize.StreamChunkSize and strings are stored
+// inline, so memory stays flat however many columns the schema has. A
+// sheet reaching Excel's row limit continues on "Columns (2)", ... added
+// after the four sheets, the only case with more than 4 sheets (sheets
+// written by a stream writer cannot be moved).
+func (e *Exporter) Export(ctx context.Context, schema *model.Schema, w io.Writer) error {
+	f := excelize.NewFile()
+	defer func() {
+		if err := f.Close(); err != nil {
+			fmt.Printf("Error closing Excel file: %v\n", err)
+		}
+	}()
+
+	// CRITICAL RULE #2: 4 Sheets - Overview, Tables, Columns, Objects
+	sheets := []string{"Overview", "Tables", "Columns", "Objects"}
+
+	// Create our sheets, then delete the default Sheet1 (the last sheet of a
+	// workbook cannot be deleted)
+	for _, sheetName := range sheets {
+		_, err := f.NewSheet(sheetName)
+		if err != nil {
+			return fmt.Errorf("failed to create sheet %s: %w", sheetName, err)
+		}
+	}
+	if err := f.DeleteSheet("Sheet1"); err != nil {
+		return fmt.Errorf("failed to delete default sheet: %w", err)
+	}
+
+	// Set Overview as active sheet
+	f.SetActiveSheet(0)
+
+	headerStyle, err := e.headerStyle(f)
+	if err != nil {
+		return fmt.Errorf("failed to create header style: %w", err)
+	}
+	changedStyle, removedStyle, err := e.changeStyles(f)
+	if err != nil {
+		return fmt.Errorf("failed to create change styles: %w", err)
+	}
+
+	// Generate content for each sheet, stopping between sheets on
+	// cancellation. The stream writer needs column widths before the first
+	// row, so each sheet is measured by a dry run first.
+	steps := []struct {
+		sheet string
+		width int // widest row, for the classification banner
+		write func(*sheetWriter, *model.Schema) error
+	}{
+		{"Overview", overviewWidth(schema), e.writeOverview},
+		{"Tables", 11 + changeColumns(schema), e.writeTables},
+		{"Columns", 14 + changeColumns(schema), e.writeColumns},
+		{"Objects", e.objectsWidth(schema), e.writeObjects},
+	}
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		m := &sheetWriter{ctx: ctx, measuring: true, widestContent: e.widestContent()}
+		if err := step.write(m, schema); err != nil {
+			return fmt.Errorf("failed to write %s: %w", strings.ToLower(step.sheet), err)
+		}
+		widths := e.columnWidths(m.contentWidths)
+
+		s := &sheetWriter{ctx: ctx, f: f, maxRows: e.maxRows, headerStyle: headerStyle, changedStyle: changedStyle, removedStyle: removedStyle,
+			start: func(s *sheetWriter) error {
+				if err := s.widths(widths...); err != nil {
+					return err
+				}
+				if err := e.setPage(f, s.name()); err != nil {
+					return fmt.Errorf("failed to set page layout: %w", err)
+				}
+				if err := e.writeClassification(f, s, s.name(), step.width); err != nil {
+					return fmt.Errorf("failed to write classification: %w", err)
+				}
+				return nil
+			},
+		}
+		if err := s.open(step.sheet); err != nil {
+			return fmt.Errorf("failed to write %s: %w", strings.ToLower(step.sheet), err)
+		}
+		if err := step.write(s, schema); err != nil {
+			return fmt.Errorf("failed to write %s: %w", strings.ToLower(step.sheet), err)
+		}
+		if err := s.sw.Flush(); err != nil {
+			return fmt.Errorf("failed to write %s: %w", strings.ToLower(step.sheet), err)
+		}
+	}
+
+	// Write to output
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.Write(w)
+}
+
+// sheetWriter streams the rows of one sheet from the top down; column
+// widths must be set before the first row. Rows past Excel's limit go to a
+// continuation sheet, which starts over with start and the last header.
+type sheetWriter struct {
+	ctx          context.Context
+	f            *excelize.File
+	sw           *excelize.StreamWriter
+	sheet        string // name of the first sheet
+	part         int    // 1 for the first sheet, 2 for "Columns (2)", ...
+	row          int    // next row to write
+	maxRows      int    // rows of each sheet
+	headerStyle  int
+	changedStyle int                      // rows of new and changed columns
+	removedStyle int                      // rows of removed columns
+	start        func(*sheetWriter) error // widths and banner of each sheet
+	lastHeader   []interface{}            // repeated on continuation sheets
+
+	// A measuring writer writes nothing; it keeps the running maximum
+	// display width of each column, except in merged rows, up to
+	// widestContent, past which a column cannot grow
+	measuring     bool
+	merged        bool
+	contentWidths []float64
+	widestContent float64
+}
+
+// open starts writing the first sheet
+func (s *sheetWriter) open(sheet string) error {
+	s.sheet, s.part = sheet, 1
+	return s.begin()
+}
+
+// name returns the name of the sheet being written
+func (s *sheetWriter) name() string {
+	if s.part == 1 {
+		return s.sheet
+	}
+	return fmt.Sprintf("%s (%d)", s.sheet, s.part)
+}
+
+// begin opens a stream writer on the current sheet and writes its start
+func (s *sheetWriter) begin() error {
+	sw, err := s.f.NewStreamWriter(s.name())
+	if err != nil {
+		return err
+	}
+	s.sw, s.row = sw, 1
+	if s.start == nil {
+		return nil
+	}
+	return s.start(s)
+}
+
+// ensureRoom continues on a new sheet when the current sheet is full
+func (s *sheetWriter) ensureRoom() error {
+	if s.row <= s.maxRows {
+		return nil
+	}
+	if err := s.sw.Flush(); err != nil {
+		return err
+	}
+	s.part++
+	if _, err := s.f.NewSheet(s.name()); err != nil {
+		return err
+	}
+	if err := s.begin(); err != nil {
+		return err
+	}
+	if s.lastHeader == nil {
+		return nil
+	}
+	return s.add(s.lastHeader...)
+}
+
+// widths sets the width of columns A, B, ...; 0 keeps the default
+func (s *sheetWriter) widths(widths ...float64) error {
+	for i, width := range widths {
+		if width == 0 {
+			continue
+		}
+		if err := s.sw.SetColWidth(i+1, i+1, width); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cancelCheckRows is how often add checks for cancellation
+const cancelCheckRows = 1000
+
+// add writes values from column A of the next row
+func (s *sheetWriter) add(values ...interface{}) error {
+	if s.row%cancelCheckRows == 0 {
+		if err := s.ctx.Err(); err != nil {
+			return err
+		}
+	}
+	if s.measuring {
+		if !s.merged {
+			s.measure(values)
+		}
+		s.merged = false
+		s.row++
+		return nil
+	}
+	if err := s.ensureRoom(); err != nil {
+		return err
+	}
+	cell, err := excelize.CoordinatesToCellName(1, s.row)
+	if err != nil {
+		return err
+	}
+	s.row++
+	return s.sw.SetRow(cell, values)
+}
+
+// measure widens the recorded column widths to fit values. Strings and
+// numbers are measured in place, so measuring a sheet allocates nothing
+// per cell.
+func (s *sheetWriter) measure(values []interface{}) {
+	for len(s.contentWidths) < len(values) {
+		s.contentWidths = append(s.contentWidths, 0)
+	}
+	for i, v := range values {
+		if cell, ok := v.(excelize.Cell); ok {
+			v = cell.Value
+		}
+		if v == nil || s.contentWidths[i] >= s.widestContent {
+			continue
+		}
+		var w float64
+		switch v := v.(type) {
+		case string:
+			w = displayWidth(v, s.widestContent)
+		case int:
+			w = float64(digits(int64(v)))
+		case int64:
+			w = float64(digits(v))
+		default:
+			w = displayWidth(fmt.Sprint(v), s.widestContent)
+		}
+		s.contentWidths[i] = max(s.contentWidths[i], w)
+	}
+}
+
+// digits is the number of characters of n in decimal
+func digits(n int64) int {
+	d := 1
+	if n < 0 {
+		d++
+	}
+	for n <= -10 || n >= 10 {
+		n /= 10
+		d++
+	}
+	return d
+}
+
+// displayWidth is the width of text in Excel character units: East Asian
+// wide and fullwidth characters (Hangul, kanji, kana, ...) take two, and
+// the longest line of multi-line text counts. Counting stops at limit.
+func displayWidth(text string, limit float64) float64 {
+	longest, line := 0, 0
+	for _, r := range text {
+		if r == '\n' {
+			line = 0
+			continue
+		}
+		switch width.LookupRune(r).Kind() {
+		case width.EastAsianWide, width.EastAsianFullwidth:
+			line += 2
+		default:
+			line++
+		}
+		longest = max(longest, line)
+		if float64(longest) >= limit {
+			break
+		}
+	}
+	return float64(longest)
+}
+
+// columnWidths returns the widths of columns A, B, ... fitting the measured
+// content plus padding, within the configured bounds
+func (e *Exporter) columnWidths(content []float64) []float64 {
+	lo, hi := e.widthBounds()
+	widths := make([]float64, len(content))
+	for i, w := range content {
+		widths[i] = min(max(w+2, lo), hi)
+	}
+	return widths
+}
+
+// widthBounds returns the configured bounds of the column widths
+func (e *Exporter) widthBounds() (lo, hi float64) {
+	lo, hi = e.config.MinColumnWidth, e.config.MaxColumnWidth
+	if lo <= 0 {
+		lo = DefaultMinColumnWidth
+	}
+	if hi <= 0 {
+		hi = DefaultMaxColumnWidth
+	}
+	return lo, max(hi, lo)
+}
+
+// widestContent is the content width at which a column reaches the
+// maximum width
+func (e *Exporter) widestContent() float64 {
+	_, hi := e.widthBounds()
+	return hi - 2
+}
+
+// header writes a row of labels in the gray header style (CRITICAL RULE #2)
+func (s *sheetWriter) header(labels []string) error {
+	values := make([]interface{}, len(labels))
+	for i, label := range labels {
+		values[i] = excelize.Cell{StyleID: s.headerStyle, Value: label}
+	}
+	s.lastHeader = values
+	return s.add(values...)
+}
+
+// section writes a section title merged over columns A to lastCol
+func (s *sheetWriter) section(title string, lastCol int) error {
+	if err := s.merge(1, lastCol); err != nil {
+		return err
+	}
+	return s.add(title)
+}
+
+// merge merges columns first to last of the next row
+func (s *sheetWriter) merge(first, last int) error {
+	if s.measuring {
+		s.merged = true
+		return nil
+	}
+	if err := s.ensureRoom(); err != nil {
+		return err
+	}
+	from, err := excelize.CoordinatesToCellName(first, s.row)
+	if err != nil {
+		return err
+	}
+	to, err := excelize.CoordinatesToCellName(last, s.row)
+	if err != nil {
+		return err
+	}
+	return s.sw.MergeCell(from, to)
+}
+
+// groupRows returns a function to call before each object; when grouping
+// by schema it writes a merged "Schema: HR" row over columns A to lastCol
+// ahead of the first object of each owner (the objects of an owner are
+// adjacent, see exporter.OrderSchema)
+func (e *Exporter) groupRows(s *sheetWriter, schema *model.Schema, lastCol int) func(owner string) error {
+	if !e.config.GroupBySchema {
+		return func(string) error { return nil }
+	}
+	started, current := false, ""
+	return func(owner string) error {
+		if started && owner == current {
+			return nil
+		}
+		started, current = true, owner
+		if owner == "" {
+			owner = schema.DatabaseName
+		}
+		return s.section(fmt.Sprintf("%s: %s", e.msg.T("label.schema"), owner), lastCol)
+	}
+}
+
+// overviewWidth is the widest row of the Overview sheet
+func overviewWidth(schema *model.Schema) int {
+	if len(schema.Sources) > 0 {
+		return 7
+	}
+	if len(schema.Tenants) > 0 {
+		return 4
+	}
+	return 2
+}
+
+// writeOverview creates the database summary sheet
+func (e *Exporter) writeOverview(s *sheetWriter, schema *model.Schema) error {
+	if err := s.header(e.labels("label.item", "label.value")); err != nil {
+		return err
+	}
+
+	data := [][]interface{}{
+		{e.msg.T("label.database_name"), schema.DatabaseName},
+		{e.msg.T("label.database_type"), schema.DatabaseType},
+		{e.msg.T("label.version"), schema.Version},
+		{e.msg.T("label.extracted_at"), e.config.Dates.Format(schema.ExtractedAt, time.RFC3339)},
+		{e.msg.T("label.total_tables"), len(schema.Tables)},
+		{e.msg.T("label.total_views"), len(schema.Views)},
+		{e.msg.T("label.total_routines"), len(schema.Routines)},
+		{e.msg.T("label.total_sequences"), len(schema.Sequences)},
+		{e.msg.T("label.total_triggers"), len(schema.Triggers)},
+		{e.msg.T("label.total_synonyms"), len(schema.Synonyms)},
+		{e.msg.T("label.total_indexes"), len(schema.Indexes)},
+	}
+	if schema.ChangedSince != nil {
+		data = append(data, []interface{}{e.msg.T("label.changed_since"), e.config.Dates.Format(*schema.ChangedSince, time.RFC3339)})
+	}
+	if envs := schema.Environments; len(envs) > 1 {
+		data = append(data, []interface{}{e.msg.T("label.environments"), e.msg.T("env.reference", envs[0], strings.Join(envs[1:], ", "))})
+	}
+	if e.config.IncludeCoverage {
+		total := coverage.Compute(schema).Total
+		data = append(data,
+			[]interface{}{e.msg.T("label.table_coverage"), fmt.Sprintf("%.1f%%", total.TablePercent())},
+			[]interface{}{e.msg.T("label.column_coverage"), fmt.Sprintf("%.1f%%", total.ColumnPercent())},
+		)
+	}
+	for _, rowData := range data {
+		if err := s.add(rowData...); err != nil {
+			return err
+		}
+	}
+
+	// Databases of a combined multi-database document
+	if len(schema.Sources) > 0 {
+		s.row++
+		if err := s.add(e.msg.T("section.databases")); err != nil {
+			return err
+		}
+		if err := s.header(e.labels("label.name", "label.database_name", "label.database_type", "label.version",
+			"label.total_tables", "label.total_views", "label.total_routines")); err != nil {
+			return err
+		}
+		for _, src := range schema.Sources {
+			if err := s.add(src.Name, src.DatabaseName, src.DatabaseType, src.Version, src.Tables, src.Views, src.Routines); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Identical tenant schemas documented once
+	if len(schema.Tenants) > 0 {
+		s.row++
+		if err := s.add(e.msg.T("section.tenants")); err != nil {
+			return err
+		}
+		if err := s.header(e.labels("label.pattern", "label.documented_schema", "label.schema_count", "label.instances")); err != nil {
+			return err
+		}
+		for _, p := range schema.Tenants {
+			if err := s.add(p.Pattern, p.Schema, len(p.Instances), strings.Join(p.Instances, ", ")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeTables creates the tables sheet
+func (e *Exporter) writeTables(s *sheetWriter, schema *model.Schema) error {
+	keys := []string{"label.name", "label.owner", "label.type", "label.column_count",
+		"label.index_count", "label.row_count", "label.comment", "label.team", "label.classification", "label.note",
+		"label.used_by"}
+	domains := hasDomains(schema)
+	if domains {
+		keys = append(keys, "label.domain")
+	}
+	if schema.ChangedSince != nil {
+		keys = append(keys, "label.change")
+	}
+	if schema.Environments != nil {
+		keys = append(keys, "label.env_diffs")
+	}
+	if err := s.header(e.labels(keys...)); err != nil {
+		return err
+	}
+
+	width := len(keys)
+	group := e.groupRows(s, schema, width)
+	for _, table := range schema.Tables {
+		if err := group(table.Owner); err != nil {
+			return err
+		}
+		values := []interface{}{table.Name, table.Owner, table.Type, len(table.Columns), len(table.Indexes),
+			table.RowCount, table.Comment, table.Team, table.Classification, table.Note, usedBy(table.ReferencedBy)}
+		if domains {
+			values = append(values, table.Domain)
+		}
+		if schema.ChangedSince != nil {
+			values = append(values, e.change(table.Change))
+		}
+		if schema.Environments != nil {
+			values = append(values, e.envNotes(table.EnvDiffs))
+		}
+		if err := s.add(values...); err != nil {
+			return err
+		}
+	}
+
+	// Views share the sheet, flagged by their type (VIEW, MATERIALIZED VIEW)
+	group = e.groupRows(s, schema, width)
+	for _, view := range schema.Views {
+		if err := group(view.Owner); err != nil {
+			return err
+		}
+		viewType := view.Type
+		if viewType == "" {
+			viewType = "VIEW"
+		}
+		values := []interface{}{view.Name, view.Owner, viewType, len(view.Columns), 0,
+			"", view.Comment, view.Team, view.Classification, view.Note}
+		if domains {
+			values = append(values, "", view.Domain)
+		}
+		if schema.Environments != nil {
+			values = append(values, make([]interface{}, width-len(values)-1)...)
+			values = append(values, e.envNotes(view.EnvDiffs))
+		}
+		if err := s.add(values...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasDomains reports whether any table or view belongs to a business domain
+func hasDomains(schema *model.Schema) bool {
+	for _, t := range schema.Tables {
+		if t.Domain != "" {
+			return true
+		}
+	}
+	for _, v := range schema.Views {
+		if v.Domain != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// usedBy lists the objects referencing a table as "HR.V_EMP (VIEW)"
+func usedBy(refs []model.ObjectRef) string {
+	names := make([]string, len(refs))
+	for i, r := range refs {
+		names[i] = fmt.Sprintf("%s (%s)", r, r.Type)
+	}
+	return strings.Join(names, ", ")
+}
+
+// writeColumns creates the columns detail sheet. Tables and views are
+// named with their owner, as tables of different schemas can share a name.
+func (e *Exporter) writeColumns(s *sheetWriter, schema *model.Schema) error {
+	keys := []string{"label.table", "label.column_name", "label.position", "label.data_type",
+		"label.nullable", "PK", "FK", "UK", "label.default", "label.comment", "label.terms",
+		"label.classification", "label.note", "label.examples"}
+	if schema.ChangedSince != nil {
+		keys = append(keys, "label.change")
+	}
+	if schema.Environments != nil {
+		keys = append(keys, "label.env_diffs")
+	}
+	if err := s.header(e.labels(keys...)); err != nil {
+		return err
+	}
+
+	namer := model.NewNamer(schema, e.config.HideSingleSchema)
+	column := func(object string, col model.Column, change string, style int) error {
+		values := []interface{}{object, col.Name, col.Position, col.DataType,
+			boolToYN(col.Nullable), boolToYN(col.IsPrimaryKey), boolToYN(col.IsForeignKey), boolToYN(col.IsUnique),
+			col.DefaultValue, col.Comment, strings.Join(col.Terms, ", "),
+			col.Classification, col.Note, strings.Join(col.Examples, ", ")}
+		if schema.ChangedSince != nil {
+			values = append(values, e.change(change))
+		}
+		if schema.Environments != nil {
+			values = append(values, e.envNotes(col.EnvDiffs))
+		}
+		if change != "" {
+			for i, v := range values {
+				values[i] = excelize.Cell{StyleID: style, Value: v}
+			}
+		}
+		return s.add(values...)
+	}
+	width := len(keys)
+	group := e.groupRows(s, schema, width)
+	for _, table := range schema.Tables {
+		if err := group(table.Owner); err != nil {
+			return err
+		}
+		object := namer.Name(table.Owner, table.Name)
+		for _, col := range table.Columns {
+			if err := column(object, col, col.Change, s.changedStyle); err != nil {
+				return err
+			}
+		}
+		// Removed columns follow, struck through
+		for _, col := range table.RemovedColumns {
+			if err := column(object, col, "removed", s.removedStyle); err != nil {
+				return err
+			}
+		}
+	}
+
+	// View columns follow the table columns
+	group = e.groupRows(s, schema, width)
+	for _, view := range schema.Views {
+		if err := group(view.Owner); err != nil {
+			return err
+		}
+		for _, col := range view.Columns {
+			if err := column(namer.Name(view.Owner, view.Name), col, "", 0); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// objectsWidth is the widest row of the Objects sheet
+func (e *Exporter) objectsWidth(schema *model.Schema) int {
+	width := 1
+	switch {
+	case len(schema.Routines) > 0, len(schema.Triggers) > 0, len(schema.Migrations) > 0:
+		width = 8
+	case len(schema.Sequences) > 0, len(collectIndexes(schema)) > 0, e.config.IncludeNaming:
+		width = 7
+	case len(schema.Synonyms) > 0, e.config.IncludeCoverage, e.config.IncludeQuality, e.config.IncludeCleanup:
+		width = 5
+	case len(schema.Glossary) > 0:
+		width = 2
+	}
+	if e.config.IncludeImpact {
+		width = max(width, len(impact.Build(schema).Tables)+1)
+	}
+	return width
+}
+
+// writeObjects creates the combined objects sheet (Routines, Sequences, Triggers, Synonyms, Indexes,
+// Migration history)
+func (e *Exporter) writeObjects(s *sheetWriter, schema *model.Schema) error {
+	namer := model.NewNamer(schema, e.config.HideSingleSchema)
+
+	// Routines section (NO source code - SECURITY)
+	if len(schema.Routines) > 0 {
+		if err := s.section(strings.ToUpper(e.msg.T("section.routines")), 8); err != nil {
+			return err
+		}
+		if err := s.header(e.labels("label.name", "label.owner", "label.type", "label.signature",
+			"label.return_type", "label.language", "label.touches", "label.comment")); err != nil {
+			return err
+		}
+		group := e.groupRows(s, schema, 8)
+		for _, routine := range schema.Routines {
+			if err := group(routine.Owner); err != nil {
+				return err
+			}
+			tables := make([]string, len(routine.Touches))
+			for i, t := range routine.Touches {
+				tables[i] = namer.Name(t.Owner, t.Name)
+			}
+			if err := s.add(routine.Name, routine.Owner, routine.Type, routine.Signature,
+				routine.ReturnType, routine.Language, strings.Join(tables, ", "), routine.Comment); err != nil {
+				return err
+			}
+		}
+		s.row++ // Blank row
+	}
+
+	// Sequences section
+	if len(schema.Sequences) > 0 {
+		if err := s.section(strings.ToUpper(e.msg.T("section.sequences")), 7); err != nil {
+			return err
+		}
+		if err := s.header(e.labels("label.name", "label.min", "label.max", "label.increment",
+			"label.current", "label.cyclic", "label.comment")); err != nil {
+			return err
+		}
+		group := e.groupRows(s, schema, 7)
+		for _, seq := range schema.Sequences {
+			if err := group(seq.Owner); err != nil {
+				return err
+			}
+			if err := s.add(namer.Name(seq.Owner, seq.Name), seq.MinValue, seq.MaxValue, seq.Increment,
+				seq.LastNumber, boolToYN(seq.IsCyclic), seq.Comment); err != nil {
+				return err
+			}
+		}
+		s.row++
+	}
+
+	// Triggers section (NO trigger body - SECURITY)
+	if len(schema.Triggers) > 0 {
+		if err := s.section(strings.ToUpper(e.msg.T("section.triggers")), 8); err != nil {
+			return err
+		}
+		if err := s.header(e.labels("label.name", "label.table", "label.timing", "label.event",
+			"label.level", "label.status", "label.comment", "label.function")); err != nil {
+			return err
+		}
+		group := e.groupRows(s, schema, 8)
+		for _, trg := range schema.Triggers {
+			if err := group(trg.Owner); err != nil {
+				return err
+			}
+			target, function := namer.Name(trg.Owner, trg.TargetTable), ""
+			if trg.TargetType == "DATABASE" {
+				target = e.msg.T("label.database_ddl")
+			}
+			if trg.Function != nil {
+				function = trg.Function.String()
+			}
+			if err := s.add(namer.Name(trg.Owner, trg.Name), target, trg.Timing, trg.Event,
+				trg.Level, trg.Status, trg.Comment, function); err != nil {
+				return err
+			}
+		}
+		s.row++
+	}
+
+	// Synonyms section
+	if len(schema.Synonyms) > 0 {
+		if err := s.section(strings.ToUpper(e.msg.T("section.synonyms")), 5); err != nil {
+			return err
+		}
+		if err := s.header(e.labels("label.name", "label.target", "label.owner", "label.type", "label.comment")); err != nil {
+			return err
+		}
+		group := e.groupRows(s, schema, 5)
+		for _, syn := range schema.Synonyms {
+			if err := group(syn.Owner); err != nil {
+				return err
+			}
+			if err := s.add(syn.Name, syn.TargetObject, syn.TargetOwner, syn.TargetType, syn.Comment); err != nil {
+				return err
+			}
+		}
+		s.row++
+	}
+
+	// Indexes section
+	if indexes := collectIndexes(schema); len(indexes) > 0 {
+		if err := s.section(strings.ToUpper(e.msg.T("section.indexes")), 7); err != nil {
+			return err
+		}
+		if err := s.header(e.labels("label.name", "label.table", "label.owner", "label.columns",
+			"label.type", "label.unique", "label.comment")); err != nil {
+			return err
+		}
+		group := e.groupRows(s, schema, 7)
+		for _, idx := range indexes {
+			if err := group(idx.Owner); err != nil {
+				return err
+			}
+			if err := s.add(idx.Name, idx.TableName, idx.Owner, strings.Join(idx.Columns, ", "),
+				idx.Type, boolToYN(idx.IsUnique), idx.Comment); err != nil {
+				return err
+			}
+		}
+		s.row++
+	}
+
+	// Migration history section
+	if len(schema.Migrations) > 0 {
+		if err := s.section(strings.ToUpper(e.msg.T("section.migrations")), 8); err != nil {
+			return err
+		}
+		if err := s.header(e.labels("label.tool", "label.version", "label.description", "label.script",
+			"label.type", "label.applied_by", "label.applied_at", "label.status")); err != nil {
+			return err
+		}
+		group := e.groupRows(s, schema, 8)
+		for _, m := range schema.Migrations {
+			if err := group(m.Owner); err != nil {
+				return err
+			}
+			if err := s.add(m.Tool, m.Version, m.Description, m.Script, m.Type, m.AppliedBy, m.AppliedAt,
+				e.migrationStatus(m)); err != nil {
+				return err
+			}
+		}
+		s.row++
+	}
+
+	// Glossary section
+	if len(schema.Glossary) > 0 {
+		if err := s.section(strings.ToUpper(e.msg.T("section.glossary")), 4); err != nil {
+			return err
+		}
+		if err := s.header(e.labels("label.term", "label.definition")); err != nil {
+			return err
+		}
+		for _, term := range schema.Glossary {
+			if err := s.merge(2, 4); err != nil {
+				return err
+			}
+			if err := s.add(term.Term, term.Definition); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Comment coverage section
+	if e.config.IncludeCoverage {
+		if err := e.writeCoverage(s, coverage.Compute(schema)); err != nil {
+			return err
+		}
+	}
+
+	// Data quality section
+	if e.config.IncludeQuality {
+		if err := e.writeQuality(s, verify.Check(schema)); err != nil {
+			return err
+		}
+	}
+
+	// Cleanup section
+	if e.config.IncludeCleanup {
+		if err := e.writeCleanup(s, cleanup.Check(schema)); err != nil {
+			return err
+		}
+	}
+
+	// Naming conventions section
+	if e.config.IncludeNaming {
+		if err := e.writeNaming(s, naming.Analyze(schema)); err != nil {
+			return err
+		}
+	}
+
+	// Views × base tables section
+	if e.config.IncludeImpact {
+		if m := impact.Build(schema); !m.Empty() {
+			return e.writeImpact(s, m, namer)
+		}
+	}
+	return nil
+}
+
+// migrationStatus returns the translated outcome of a migration
+func (e *Exporter) migrationStatus(m model.Migration) string {
+	if m.Success {
+		return e.msg.T("migration.success")
+	}
+	return e.msg.T("migration.failed")
+}
+
+// writeCoverage writes per-schema comment coverage and the uncommented
+// tables/columns
+func (e *Exporter) writeCoverage(s *sheetWriter, report *coverage.Report) error {
+	if err := s.section(strings.ToUpper(e.msg.T("section.coverage")), 7); err != nil {
+		return err
+	}
+	if err := s.header(e.labels("label.schema", "label.total_tables", "label.table_coverage",
+		"label.columns", "label.column_coverage")); err != nil {
+		return err
+	}
+
+	stats := append([]coverage.SchemaStats(nil), report.Schemas...)
+	stats = append(stats, coverage.SchemaStats{Owner: e.msg.T("label.total"), Stats: report.Total})
+	for _, st := range stats {
+		if err := s.add(st.Owner, st.Tables, fmt.Sprintf("%d (%.1f%%)", st.CommentedTables, st.TablePercent()),
+			st.Columns, fmt.Sprintf("%d (%.1f%%)", st.CommentedColumns, st.ColumnPercent())); err != nil {
+			return err
+		}
+	}
+	s.row++
+
+	if len(report.Missing) > 0 {
+		if err := s.section(strings.ToUpper(e.msg.T("section.missing_comments")), 7); err != nil {
+			return err
+		}
+		if err := s.header(e.labels("label.schema", "label.table", "label.column")); err != nil {
+			return err
+		}
+		for _, m := range report.Missing {
+			if err := s.add(m.Owner, m.Table, m.Column); err != nil {
+				return err
+			}
+		}
+		s.row++
+	}
+	return nil
+}
+
+// writeQuality writes the data quality findings, or a note that there are
+// none
+func (e *Exporter) writeQuality(s *sheetWriter, report *verify.Report) error {
+	if err := s.section(strings.ToUpper(e.msg.T("section.quality")), 7); err != nil {
+		return err
+	}
+	if len(report.Findings) == 0 {
+		if err := s.add(e.msg.T("quality.none")); err != nil {
+			return err
+		}
+		s.row++
+		return nil
+	}
+	if err := s.header(e.labels("label.check", "label.schema", "label.object", "label.detail")); err != nil {
+		return err
+	}
+	for _, f := range report.Findings {
+		if err := s.add(e.msg.T("quality."+f.Check), f.Owner, f.Object, f.Detail); err != nil {
+			return err
+		}
+	}
+	s.row++
+	return nil
+}
+
+// writeCleanup writes the cleanup candidates, or a note that there are
+// none
+func (e *Exporter) writeCleanup(s *sheetWriter, report *cleanup.Report) error {
+	if err := s.section(strings.ToUpper(e.msg.T("section.cleanup")), 7); err != nil {
+		return err
+	}
+	if len(report.Findings) == 0 {
+		if err := s.add(e.msg.T("cleanup.none")); err != nil {
+			return err
+		}
+		s.row++
+		return nil
+	}
+	if err := s.header(e.labels("label.check", "label.schema", "label.object", "label.detail")); err != nil {
+		return err
+	}
+	for _, f := range report.Findings {
+		if err := s.add(e.msg.T("cleanup."+f.Check), f.Owner, f.Object, f.Detail); err != nil {
+			return err
+		}
+	}
+	s.row++
+	return nil
+}
+
+// writeNaming writes the column prefixes and suffixes, or a note that no
+// columns of several tables share one
+func (e *Exporter) writeNaming(s *sheetWriter, dict *naming.Dictionary) error {
+	if err := s.section(strings.ToUpper(e.msg.T("section.naming")), 7); err != nil {
+		return err
+	}
+	if len(dict.Affixes) == 0 {
+		if err := s.add(e.msg.T("naming.none")); err != nil {
+			return err
+		}
+		s.row++
+		return nil
+	}
+	if err := s.header(e.labels("label.affix", "label.position", "label.columns", "label.table_count",
+		"label.meaning", "label.examples")); err != nil {
+		return err
+	}
+	for _, a := range dict.Affixes {
+		position, meaning := e.msg.T("naming.suffix"), ""
+		if a.Prefix {
+			position = e.msg.T("naming.prefix")
+		}
+		if a.Meaning != "" {
+			meaning = e.msg.T("naming.meaning." + a.Meaning)
+		}
+		if err := s.add(a.Text, position, a.Columns, a.Tables, meaning, strings.Join(a.Examples, ", ")); err != nil {
+			return err
+		}
+	}
+	s.row++
+	return nil
+}
+
+// writeImpact writes the impact matrix, a view per row and a base table per
+// column, with the number of views using each table in a last row
+func (e *Exporter) writeImpact(s *sheetWriter, m *impact.Matrix, namer model.Namer) error {
+	if err := s.section(strings.ToUpper(e.msg.T("section.impact")), len(m.Tables)+1); err != nil {
+		return err
+	}
+	header := []string{e.msg.T("label.view")}
+	total := []interface{}{e.msg.T("impact.views")}
+	for _, c := range m.Tables {
+		header = append(header, namer.Name(c.Table.Owner, c.Table.Name))
+		total = append(total, c.Views)
+	}
+	if err := s.header(header); err != nil {
+		return err
+	}
+	for _, r := range m.Rows {
+		values := []interface{}{namer.Name(r.View.Owner, r.View.Name)}
+		for _, uses := range r.Uses {
+			if uses {
+				values = append(values, "●")
+			} else {
+				values = append(values, nil)
+			}
+		}
+		if err := s.add(values...); err != nil {
+			return err
+		}
+	}
+	if err := s.add(total...); err != nil {
+		return err
+	}
+	s.row++
+	return nil
+}
+
+// writeClassification writes the confidentiality label as a merged first
+// row over the sheet's width and repeats it in the printed page header
+func (e *Exporter) writeClassification(f *excelize.File, s *sheetWriter, sheet string, width int) error {
+	if e.config.Classification == "" {
+		return nil
+	}
+
+	style, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Size: 12, Color: "#FFFFFF"},
+		Fill: excelize.Fill{
+			Type:    "pattern",
+			Color:   []string{"#C0392B"},
+			Pattern: 1,
+		},
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+	})
+	if err != nil {
+		return err
+	}
+
+	if width > 1 {
+		if err := s.merge(1, width); err != nil {
+			return err
+		}
+	}
+	values := make([]interface{}, width)
+	for i := range values {
+		values[i] = excelize.Cell{StyleID: style}
+	}
+	values[0] = excelize.Cell{StyleID: style, Value: e.config.Classification}
+	if err := s.add(values...); err != nil {
+		return err
+	}
+
+	header := "&C&\"-,Bold\"" + strings.ReplaceAll(e.config.Classification, "&", "&&")
+	return f.SetHeaderFooter(sheet, &excelize.HeaderFooterOptions{
+		OddHeader: header,
+	})
+}
+
+// setPage sets the printed paper of sheet when one is configured; sheets
+// otherwise print on the printer's default paper
+func (e *Exporter) setPage(f *excelize.File, sheet string) error {
+	if e.config.Page.IsZero() {
+		return nil
+	}
+	size, orientation := e.config.Page.ExcelPaper(), e.config.Page.ExcelOrientation()
+	return f.SetPageLayout(sheet, &excelize.PageLayoutOptions{Size: &size, Orientation: &orientation})
+}
+
+// changeColumns is the number of Change columns of the Tables and Columns
+// sheets: one when schema was compared with an older snapshot
+func changeColumns(schema *model.Schema) int {
+	if schema.ChangedSince != nil {
+		return 1
+	}
+	return 0
+}
+
+// change translates a change mark ("added", "changed", "removed")
+func (e *Exporter) change(mark string) string {
+	if mark == "" {
+		return ""
+	}
+	return e.msg.T("history." + mark)
+}
+
+// envNotes describes the differences of an object between environments
+func (e *Exporter) envNotes(diffs []model.EnvDiff) string {
+	return strings.Join(combine.EnvNotes(diffs, e.msg), "; ")
+}
+
+// changeStyles creates the styles of new and changed column rows (yellow
+// fill) and of removed column rows (gray, struck through)
+func (e *Exporter) changeStyles(f *excelize.File) (changed, removed int, err error) {
+	changed, err = f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#FFF2CC"}, Pattern: 1},
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	removed, err = f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Strike: true, Color: "#7F7F7F"},
+	})
+	return changed, removed, err
+}
+
+// headerStyle creates the gray header style (CRITICAL RULE #2)
+func (e *Exporter) headerStyle(f *excelize.File) (int, error) {
+	return f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Size: 11},
+		Fill: excelize.Fill{
+			Type:    "pattern",
+			Color:   []string{"#D9D9D9"}, // Gray background
+			Pattern: 1,
+		},
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+		Border: []excelize.Border{
+			{Type: "top", Color: "000000", Style: 1},
+			{Type: "bottom", Color: "000000", Style: 1},
+			{Type: "left", Color: "000000", Style: 1},
+			{Type: "right", Color: "000000", Style: 1},
+		},
+	})
+}
+
+// labels translates message keys; keys without a translation (e.g. "PK") are kept as-is
+func (e *Exporter) labels(keys ...string) []string {
+	out := make([]string, len(keys))
+	for i, key := range keys {
+		out[i] = e.msg.T(key)
+	}
+	return out
+}
+
+// collectIndexes returns the schema-level index list, falling back to the
+// per-table indexes when the extractor did not aggregate them
+func collectIndexes(schema *model.Schema) []model.Index {
+	if len(schema.Indexes) > 0 {
+		return schema.Indexes
+	}
+	var indexes []model.Index
+	for _, table := range schema.Tables {
+		indexes = append(indexes, table.Indexes...)
+	}
+	return indexes
+}
+
+// boolToYN converts bool to Y/N string
+func boolToYN(b bool) string {
+	if b {
+		return "Y"
+	}
+	return "N"
+}
@@ -0,0 +1,67 @@
+package xlsx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"pocket-doc/internal/model"
+	"slices"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// TestContinuationSheets validates rows past the sheet limit continue on
+// "Columns (2)", ... after the four sheets, each starting with the header
+// row, and that no row is lost
+func TestContinuationSheets(t *testing.T) {
+	const columns = 45
+	table := model.Table{Owner: "HR", Name: "EMP", Type: "TABLE"}
+	for i := 1; i <= columns; i++ {
+		table.Columns = append(table.Columns, model.Column{Name: fmt.Sprintf("COL_%02d", i), Position: i, DataType: "NUMBER"})
+	}
+	schema := &model.Schema{DatabaseName: "HR", DatabaseType: "oracle", Tables: []model.Table{table}}
+
+	e := NewExporter(Config{Language: "en"})
+	e.maxRows = 20
+	var buf bytes.Buffer
+	if err := e.Export(context.Background(), schema, &buf); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	want := []string{"Overview", "Tables", "Columns", "Objects", "Columns (2)", "Columns (3)"}
+	if sheets := f.GetSheetList(); !slices.Equal(sheets, want) {
+		t.Fatalf("sheets = %v, want %v", sheets, want)
+	}
+	header, err := f.GetRows("Columns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, sheet := range want[2:] {
+		if sheet == "Objects" {
+			continue
+		}
+		rows, err := f.GetRows(sheet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) > e.maxRows {
+			t.Errorf("%s: %d rows, limit %d", sheet, len(rows), e.maxRows)
+		}
+		if !slices.Equal(rows[0], header[0]) {
+			t.Errorf("%s: first row %v, want the header %v", sheet, rows[0], header[0])
+		}
+		for _, row := range rows[1:] {
+			names = append(names, row[1])
+		}
+	}
+	if len(names) != columns || names[0] != "COL_01" || names[columns-1] != "COL_45" {
+		t.Errorf("column rows = %v, want COL_01 to COL_45", names)
+	}
+}
@@ -0,0 +1,19 @@
+package extractor
+
+// CacheReporter is implemented by extractors that reuse the tables of
+// previous runs (extract.cache)
+type CacheReporter interface {
+	CacheStats() (hits, misses int)
+}
+
+// CacheStats returns the number of tables an extraction took from the
+// cache and read from the database. ok is false when the extractor does
+// not cache.
+func CacheStats(ext DBExtractor) (hits, misses int, ok bool) {
+	reporter, ok := ext.(CacheReporter)
+	if !ok {
+		return 0, 0, false
+	}
+	hits, misses = reporter.CacheStats()
+	return hits, misses, true
+}
@@ -0,0 +1,95 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pocket-doc/internal/dbquery"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// stubPreflight reads the catalogs not in denied, of a server of the given version
+type stubPreflight struct {
+	DBExtractor
+	version string
+	denied  map[string]error
+	skipped []string
+}
+
+func (s *stubPreflight) RequiredCatalogs() []string {
+	return []string{"ALL_TABLES", "ALL_TAB_COLUMNS", "ALL_VIEWS", "ALL_PROCEDURES", "ALL_ARGUMENTS", "ALL_TRIGGERS"}
+}
+
+func (s *stubPreflight) ProbeCatalog(ctx context.Context, catalog string) error {
+	return s.denied[catalog]
+}
+
+func (s *stubPreflight) SectionCatalogs() map[string][]string {
+	return map[string][]string{"views": {"ALL_VIEWS"}, "routines": {"ALL_PROCEDURES", "ALL_ARGUMENTS"}, "triggers": {"ALL_TRIGGERS"}}
+}
+
+func (s *stubPreflight) SkipSections(sections []string) { s.skipped = sections }
+
+func (s *stubPreflight) CheckVersion(ctx context.Context) (string, error) {
+	return s.version, dbquery.CheckVersion("oracle", s.version)
+}
+
+// TestPreflight validates unreadable optional sections are skipped, and unreadable tables or old servers fail
+func TestPreflight(t *testing.T) {
+	ctx := context.Background()
+	denied := func(catalog string) error {
+		return &dbquery.PermissionDeniedError{DBType: "oracle", Catalog: catalog, User: "APP", Err: fmt.Errorf("ORA-00942")}
+	}
+
+	ext := &stubPreflight{version: "Oracle Database 19c Enterprise Edition Release 19.0.0.0.0", denied: map[string]error{"ALL_ARGUMENTS": denied("ALL_ARGUMENTS")}}
+	p, ok, err := RunPreflight(ctx, ext)
+	if !ok || err != nil {
+		t.Fatalf("RunPreflight() = %v, %v", ok, err)
+	}
+	if !slices.Equal(ext.skipped, []string{"routines"}) || !slices.Equal(p.Skipped(), ext.skipped) {
+		t.Errorf("skipped = %v, %v", ext.skipped, p.Skipped())
+	}
+	if len(p.Sections) != 3 || p.Sections[1].Section != "routines" || p.Sections[1].Missing[0].Catalog != "ALL_ARGUMENTS" {
+		t.Errorf("sections = %+v", p.Sections)
+	}
+
+	ext = &stubPreflight{version: "19.0", denied: map[string]error{"ALL_TAB_COLUMNS": denied("ALL_TAB_COLUMNS")}}
+	if _, _, err := RunPreflight(ctx, ext); !strings.Contains(err.Error(), "ALL_TAB_COLUMNS") || ext.skipped != nil {
+		t.Errorf("unreadable table catalog: %v, skipped %v", err, ext.skipped)
+	}
+	// A probe failing for another reason than access is not skipped over
+	ext = &stubPreflight{version: "19.0", denied: map[string]error{"ALL_VIEWS": fmt.Errorf("connection reset")}}
+	if _, _, err := RunPreflight(ctx, ext); err == nil || ext.skipped != nil {
+		t.Errorf("lost connection: %v, skipped %v", err, ext.skipped)
+	}
+
+	ext = &stubPreflight{version: "Oracle Database 10g Release 10.2.0.4.0"}
+	var unsupported *dbquery.UnsupportedVersionError
+	if _, _, err := RunPreflight(ctx, ext); !errors.As(err, &unsupported) || dbquery.Hint(err) != "pocket-doc needs Oracle 11g or later" {
+		t.Errorf("old server: %v", err)
+	}
+	for version, ok := range map[string]bool{
+		"Microsoft SQL Server 2019 (RTM-CU18) (KB5017593) - 15.0.4261.1 (X64)": true,
+		"Microsoft SQL Server 2008 R2 (SP3) - 10.50.6000.34 (X64)":             false,
+		"Microsoft SQL Azure (RTM) - 12.0.2000.8":                              true,
+	} {
+		if err := dbquery.CheckVersion("mssql", version); (err == nil) != ok {
+			t.Errorf("CheckVersion(%q) = %v", version, err)
+		}
+	}
+	if err := dbquery.CheckVersion("mysql", "5.5.62-log"); err == nil {
+		t.Error("MySQL 5.5 supported")
+	}
+	if err := dbquery.CheckVersion("mysql", "5.6.51-log"); err != nil {
+		t.Errorf("MySQL 5.6: %v", err)
+	}
+	if err := dbquery.CheckVersion("postgresql", "PostgreSQL 16.2 on x86_64-pc-linux-gnu"); err != nil {
+		t.Errorf("PostgreSQL 16: %v", err)
+	}
+
+	if _, ok, _ := RunPreflight(ctx, struct{ DBExtractor }{}); ok {
+		t.Error("extractor without probing is checked")
+	}
+}
@@ -1,106 +1,209 @@
-﻿package extractor
-
-import (
-	"context"
-	"pocket-doc/internal/extractor/mssql"
-	"pocket-doc/internal/extractor/mysql"
-	"pocket-doc/internal/extractor/oracle"
-	"pocket-doc/internal/extractor/postgres"
-	"pocket-doc/internal/model"
-	"fmt"
-	"strings"
-)
-
-// DBExtractor is the unified interface for all database extractors
-type DBExtractor interface {
-	Connect(ctx context.Context) error
-	Close() error
-	GetDatabaseInfo(ctx context.Context) (name, version string, err error)
-	GetTables(ctx context.Context) ([]model.Table, error)
-	GetViews(ctx context.Context) ([]model.View, error)
-	GetRoutines(ctx context.Context) ([]model.Routine, error)
-	GetSequences(ctx context.Context) ([]model.Sequence, error)
-	GetTriggers(ctx context.Context) ([]model.Trigger, error)
-	GetSynonyms(ctx context.Context) ([]model.Synonym, error)
-	ExtractSchema(ctx context.Context) (*model.Schema, error)
-}
-
-// NewDBExtractor creates a database extractor based on type
-func NewDBExtractor(dbType string, config Config) (DBExtractor, error) {
-	dbType = strings.ToLower(strings.TrimSpace(dbType))
-
-	switch dbType {
-	case "oracle":
-		cfg := oracle.Config{
-			Host:         config.Host,
-			Port:         config.Port,
-			ServiceName:  config.Database,
-			Username:     config.Username,
-			Password:     config.Password,
-			SchemaFilter: config.SchemaFilter,
-		}
-		return oracle.NewExtractor(cfg)
-
-	case "mysql":
-		cfg := mysql.Config{
-			Host:         config.Host,
-			Port:         config.Port,
-			Database:     config.Database,
-			Username:     config.Username,
-			Password:     config.Password,
-			SchemaFilter: config.SchemaFilter,
-		}
-		return mysql.NewExtractor(cfg)
-
-	case "postgresql", "postgres", "pg":
-		sslMode := config.SSLMode
-		if sslMode == "" {
-			sslMode = "disable"
-		}
-		cfg := postgres.Config{
-			Host:         config.Host,
-			Port:         config.Port,
-			Database:     config.Database,
-			Username:     config.Username,
-			Password:     config.Password,
-			SSLMode:      sslMode,
-			SchemaFilter: config.SchemaFilter,
-		}
-		return postgres.NewExtractor(cfg)
-
-	case "mssql", "sqlserver":
-		encrypt := "disable"
-		if config.SSLMode == "require" || config.SSLMode == "true" {
-			encrypt = "true"
-		}
-		cfg := mssql.Config{
-			Host:         config.Host,
-			Port:         config.Port,
-			Database:     config.Database,
-			Username:     config.Username,
-			Password:     config.Password,
-			Encrypt:      encrypt,
-			SchemaFilter: config.SchemaFilter,
-		}
-		return mssql.NewExtractor(cfg)
-
-	default:
-		return nil, fmt.Errorf("unsupported database type: %s (supported: oracle, mysql, postgresql, mssql)", dbType)
-	}
-}
-
-// GetSupportedDatabases returns list of supported database types
-func GetSupportedDatabases() []string {
-	return []string{"oracle", "mysql", "postgresql", "mssql"}
-}
-
-// Config holds unified database configuration
-type Config struct {
-	Host         string
-	Port         int
-	Database     string
-	Username     string
-	Password     string
-	SSLMode      string
-	SchemaFilter []string
-}
+﻿package extractor
+
+import (
+	"context"
+	"pocket-doc/internal/cache"
+	"pocket-doc/internal/dbquery"
+	"pocket-doc/internal/dbt"
+	"pocket-doc/internal/extractor/mssql"
+	"pocket-doc/internal/extractor/mysql"
+	"pocket-doc/internal/extractor/oracle"
+	"pocket-doc/internal/extractor/postgres"
+	"pocket-doc/internal/model"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DBExtractor is the unified interface for all database extractors
+type DBExtractor interface {
+	Connect(ctx context.Context) error
+	Close() error
+	GetDatabaseInfo(ctx context.Context) (name, version string, err error)
+	GetTables(ctx context.Context) ([]model.Table, error)
+	GetViews(ctx context.Context) ([]model.View, error)
+	GetRoutines(ctx context.Context) ([]model.Routine, error)
+	GetSequences(ctx context.Context) ([]model.Sequence, error)
+	GetTriggers(ctx context.Context) ([]model.Trigger, error)
+	GetSynonyms(ctx context.Context) ([]model.Synonym, error)
+	ExtractSchema(ctx context.Context) (*model.Schema, error)
+}
+
+// Factory creates a database extractor from the unified configuration
+type Factory func(config Config) (DBExtractor, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+	dbTypes    []string // canonical type names in registration order
+)
+
+func init() {
+	Register("oracle", newOracle)
+	Register("mysql", newMySQL)
+	Register("postgresql", newPostgres, "postgres", "pg")
+	Register("mssql", newMSSQL, "sqlserver")
+	Register("dbt", newDbt)
+}
+
+// Register makes a database extractor selectable by the config type string
+// under name and any aliases. Names are case-insensitive. Like
+// database/sql.Register, it panics if factory is nil or a name is already
+// registered, so it is meant to be called from init functions.
+func Register(name string, factory Factory, aliases ...string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if factory == nil {
+		panic("extractor: Register factory is nil for " + name)
+	}
+
+	for _, n := range append([]string{name}, aliases...) {
+		n = normalizeType(n)
+		if _, dup := registry[n]; dup {
+			panic("extractor: Register called twice for database type " + n)
+		}
+		registry[n] = factory
+	}
+	dbTypes = append(dbTypes, normalizeType(name))
+}
+
+// NewDBExtractor creates a database extractor based on type
+func NewDBExtractor(dbType string, config Config) (DBExtractor, error) {
+	dbType = normalizeType(dbType)
+
+	registryMu.RLock()
+	factory, ok := registry[dbType]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported database type: %s (supported: %s)",
+			dbType, strings.Join(GetSupportedDatabases(), ", "))
+	}
+	return factory(config)
+}
+
+// GetSupportedDatabases returns list of supported database types
+func GetSupportedDatabases() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return append([]string(nil), dbTypes...)
+}
+
+// normalizeType lower-cases and trims a database type name
+func normalizeType(dbType string) string {
+	return strings.ToLower(strings.TrimSpace(dbType))
+}
+
+// newOracle builds the built-in Oracle extractor
+func newOracle(config Config) (DBExtractor, error) {
+	cfg := oracle.Config{
+		Host:          config.Host,
+		Port:          config.Port,
+		ServiceName:   config.Database,
+		Username:      config.Username,
+		Password:      config.Password,
+		SchemaFilter:  config.SchemaFilter,
+		ExcludeSystem: config.ExcludeSystem,
+		Logger:        config.Logger,
+		QueryTimeout:  config.QueryTimeout,
+		Hooks:         config.Hooks,
+		Cache:         config.Cache,
+	}
+	return oracle.NewExtractor(cfg)
+}
+
+// newMySQL builds the built-in MySQL extractor
+func newMySQL(config Config) (DBExtractor, error) {
+	cfg := mysql.Config{
+		Host:         config.Host,
+		Port:         config.Port,
+		Database:     config.Database,
+		Username:     config.Username,
+		Password:     config.Password,
+		SchemaFilter: config.SchemaFilter,
+		Logger:       config.Logger,
+		QueryTimeout: config.QueryTimeout,
+		Hooks:        config.Hooks,
+	}
+	return mysql.NewExtractor(cfg)
+}
+
+// newPostgres builds the built-in PostgreSQL extractor
+func newPostgres(config Config) (DBExtractor, error) {
+	sslMode := config.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	cfg := postgres.Config{
+		Host:          config.Host,
+		Port:          config.Port,
+		Database:      config.Database,
+		Username:      config.Username,
+		Password:      config.Password,
+		SSLMode:       sslMode,
+		SchemaFilter:  config.SchemaFilter,
+		ExcludeSystem: config.ExcludeSystem,
+		Logger:        config.Logger,
+		QueryTimeout:  config.QueryTimeout,
+		Hooks:         config.Hooks,
+	}
+	return postgres.NewExtractor(cfg)
+}
+
+// newMSSQL builds the built-in SQL Server extractor
+func newMSSQL(config Config) (DBExtractor, error) {
+	encrypt := "disable"
+	if config.SSLMode == "require" || config.SSLMode == "true" {
+		encrypt = "true"
+	}
+	cfg := mssql.Config{
+		Host:          config.Host,
+		Port:          config.Port,
+		Database:      config.Database,
+		Username:      config.Username,
+		Password:      config.Password,
+		Encrypt:       encrypt,
+		SchemaFilter:  config.SchemaFilter,
+		ExcludeSystem: config.ExcludeSystem,
+		Logger:        config.Logger,
+		QueryTimeout:  config.QueryTimeout,
+		Hooks:         config.Hooks,
+		Cache:         config.Cache,
+	}
+	if v, ok := config.Options["ddl_triggers"]; ok {
+		ddl, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ddl_triggers option %q: %w", v, err)
+		}
+		cfg.DDLTriggers = ddl
+	}
+	return mssql.NewExtractor(cfg)
+}
+
+// newDbt builds the extractor of dbt artifacts; database names the target
+// directory holding manifest.json and catalog.json
+func newDbt(config Config) (DBExtractor, error) {
+	return dbt.NewExtractor(config.Database, config.SchemaFilter), nil
+}
+
+// Config holds unified database configuration
+type Config struct {
+	Host          string
+	Port          int
+	Database      string
+	Username      string
+	Password      string
+	SSLMode       string
+	SchemaFilter  []string
+	ExcludeSystem bool              // skip system and built-in objects the schema filter does not exclude
+	Options       map[string]string // driver-specific options for registered extractors
+	Logger        *slog.Logger      // debug-level query timing (nil = slog.Default())
+	QueryTimeout  time.Duration     // limit of each catalog query (0 = none)
+	Cache         *cache.Cache      // per-object results by last DDL time (nil = no caching)
+	Hooks         dbquery.Hooks     // observe catalog queries and extracted objects (nil = none)
+}
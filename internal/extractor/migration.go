@@ -0,0 +1,144 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"pocket-doc/internal/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RowReader is implemented by extractors that can read the rows of a
+// table (all built-in extractors do). ReadRows returns the values of
+// columns as database/sql scans them into any, ordered by orderBy.
+type RowReader interface {
+	ReadRows(ctx context.Context, owner, table string, columns []string, orderBy string) ([][]any, error)
+}
+
+// historyTable describes the history table of a migration tool
+type historyTable struct {
+	tool    string
+	table   string
+	orderBy string
+	// version, description, script, type, applied by, applied at and a
+	// success flag (Flyway) or comments (Liquibase)
+	columns []string
+}
+
+var historyTables = []historyTable{
+	{model.MigrationFlyway, "flyway_schema_history", "installed_rank",
+		[]string{"version", "description", "script", "type", "installed_by", "installed_on", "success"}},
+	{model.MigrationLiquibase, "databasechangelog", "orderexecuted",
+		[]string{"id", "description", "filename", "exectype", "author", "dateexecuted", "comments"}},
+}
+
+// ReadMigrations detects the history tables of Flyway and Liquibase among
+// the extracted tables and sets the applied migrations on schema. History
+// tables that cannot be read (e.g. for lack of SELECT privilege) are
+// skipped. It returns the number of migrations; ok is false when the
+// extractor cannot read rows.
+func ReadMigrations(ctx context.Context, ext DBExtractor, schema *model.Schema, logger *slog.Logger) (n int, ok bool, err error) {
+	reader, ok := ext.(RowReader)
+	if !ok {
+		return 0, false, nil
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	schema.Migrations = nil
+	for _, t := range schema.Tables {
+		for _, h := range historyTables {
+			if !strings.EqualFold(t.Name, h.table) {
+				continue
+			}
+			// Column names as created, e.g. upper-case on Oracle
+			columns, found := columnNames(t, append([]string{h.orderBy}, h.columns...))
+			if !found {
+				continue
+			}
+			rows, err := reader.ReadRows(ctx, t.Owner, t.Name, columns[1:], columns[0])
+			if err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return n, true, ctxErr
+				}
+				logger.Debug("migration history not readable", "table", model.QualifiedName(t.Owner, t.Name), "error", err)
+				continue
+			}
+			for _, row := range rows {
+				schema.Migrations = append(schema.Migrations, migration(h.tool, t.Owner, row))
+			}
+			n += len(rows)
+		}
+	}
+	return n, true, nil
+}
+
+// columnNames returns the names of wanted in table t; found is false when
+// t lacks one of them, i.e. is not a history table
+func columnNames(t model.Table, wanted []string) (names []string, found bool) {
+	for _, w := range wanted {
+		found = false
+		for _, c := range t.Columns {
+			if strings.EqualFold(c.Name, w) {
+				names = append(names, c.Name)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return names, true
+}
+
+// migration converts a history row in historyTable column order
+func migration(tool, owner string, row []any) model.Migration {
+	m := model.Migration{
+		Tool:        tool,
+		Owner:       owner,
+		Version:     text(row[0]),
+		Description: text(row[1]),
+		Script:      text(row[2]),
+		Type:        text(row[3]),
+		AppliedBy:   text(row[4]),
+		AppliedAt:   text(row[5]),
+	}
+	switch tool {
+	case model.MigrationFlyway:
+		m.Success = truthy(text(row[6]))
+	case model.MigrationLiquibase:
+		if comments := text(row[6]); comments != "" {
+			m.Description = comments
+		}
+		m.Success = !strings.EqualFold(m.Type, "FAILED")
+	}
+	return m
+}
+
+// text formats a scanned value
+func text(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return strings.TrimSpace(string(v))
+	case string:
+		return strings.TrimSpace(v)
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05")
+	}
+	return fmt.Sprint(v)
+}
+
+// truthy reads a boolean stored as boolean, bit or number
+func truthy(s string) bool {
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	return err == nil && n != 0
+}
@@ -0,0 +1,28 @@
+package mssql
+
+import (
+	"errors"
+	"pocket-doc/internal/dbquery"
+)
+
+// queryOptions bounds, classifies and reports the catalog queries
+func (e *Extractor) queryOptions() dbquery.Options {
+	return dbquery.Options{Timeout: e.config.QueryTimeout, Classify: e.classify, Hooks: e.config.Hooks}
+}
+
+// classify maps the SQL Server error numbers of a failed catalog query to
+// typed errors: permission denied, or an invalid object or column name of
+// an older server
+func (e *Extractor) classify(query string, err error) error {
+	var sqlErr interface{ SQLErrorNumber() int32 }
+	if !errors.As(err, &sqlErr) {
+		return err
+	}
+	switch sqlErr.SQLErrorNumber() {
+	case 229, 230, 262:
+		return &dbquery.PermissionDeniedError{DBType: "mssql", Catalog: dbquery.CatalogOf(query, err, requiredCatalogs), User: e.config.Username, Err: err}
+	case 207, 208:
+		return &dbquery.UnsupportedVersionError{DBType: "mssql", Catalog: dbquery.CatalogOf(query, err, requiredCatalogs), Err: err}
+	}
+	return err
+}
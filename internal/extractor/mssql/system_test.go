@@ -0,0 +1,29 @@
+package mssql
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSystemCondition validates objects shipped by SQL Server or created by
+// its diagram designer are excluded when ExcludeSystem is set, while user
+// objects (is_ms_shipped = 0, without the tools property) are kept
+func TestSystemCondition(t *testing.T) {
+	e := &Extractor{config: Config{ExcludeSystem: true}}
+	got := e.systemCondition("t")
+	for _, want := range []string{
+		" AND t.is_ms_shipped = 0",
+		"tool.major_id = t.object_id AND tool.minor_id = 0",
+		"tool.name = 'microsoft_database_tools_support'",
+		"AND NOT EXISTS",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("condition lacks %q:\n%s", want, got)
+		}
+	}
+
+	e = &Extractor{}
+	if got := e.systemCondition("t"); got != "" {
+		t.Errorf("condition without ExcludeSystem = %q", got)
+	}
+}
@@ -0,0 +1,29 @@
+package mysql
+
+import (
+	"errors"
+	"pocket-doc/internal/dbquery"
+
+	driver "github.com/go-sql-driver/mysql"
+)
+
+// queryOptions bounds, classifies and reports the catalog queries
+func (e *Extractor) queryOptions() dbquery.Options {
+	return dbquery.Options{Timeout: e.config.QueryTimeout, Classify: e.classify, Hooks: e.config.Hooks}
+}
+
+// classify maps the MySQL error numbers of a failed catalog query to typed
+// errors: access denied, or an unknown table or column of an older server
+func (e *Extractor) classify(query string, err error) error {
+	var myErr *driver.MySQLError
+	if !errors.As(err, &myErr) {
+		return err
+	}
+	switch myErr.Number {
+	case 1044, 1142, 1143, 1227:
+		return &dbquery.PermissionDeniedError{DBType: "mysql", Catalog: dbquery.CatalogOf(query, err, requiredCatalogs), User: e.config.Username, Err: err}
+	case 1054, 1146:
+		return &dbquery.UnsupportedVersionError{DBType: "mysql", Catalog: dbquery.CatalogOf(query, err, requiredCatalogs), Err: err}
+	}
+	return err
+}
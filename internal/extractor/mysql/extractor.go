@@ -1,599 +1,831 @@
-﻿package mysql
-
-import (
-	"context"
-	"database/sql"
-	"pocket-doc/internal/model"
-	"fmt"
-	"strings"
-	"time"
-
-	_ "github.com/go-sql-driver/mysql"
-)
-
-// Extractor implements MySQL database metadata extraction
-type Extractor struct {
-	db           *sql.DB
-	config       Config
-	schemaFilter []string
-}
-
-// Config holds MySQL-specific configuration
-type Config struct {
-	Host         string
-	Port         int
-	Database     string
-	Username     string
-	Password     string
-	SchemaFilter []string // Filter by SCHEMA
-}
-
-// NewExtractor creates a new MySQL extractor
-func NewExtractor(cfg Config) (*Extractor, error) {
-	// Build MySQL DSN: user:password@tcp(host:port)/dbname
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true",
-		cfg.Username, cfg.Password, cfg.Host, cfg.Port, cfg.Database)
-
-	db, err := sql.Open("mysql", dsn)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open mysql connection: %w", err)
-	}
-
-	schemas := cfg.SchemaFilter
-	if len(schemas) == 0 {
-		schemas = []string{cfg.Database} // Default to connected database
-	}
-
-	return &Extractor{
-		db:           db,
-		config:       cfg,
-		schemaFilter: schemas,
-	}, nil
-}
-
-// Connect establishes connection
-func (e *Extractor) Connect(ctx context.Context) error {
-	return e.db.PingContext(ctx)
-}
-
-// Close releases resources
-func (e *Extractor) Close() error {
-	if e.db != nil {
-		return e.db.Close()
-	}
-	return nil
-}
-
-// GetDatabaseInfo retrieves database information
-func (e *Extractor) GetDatabaseInfo(ctx context.Context) (name, version string, err error) {
-	err = e.db.QueryRowContext(ctx, "SELECT DATABASE(), VERSION()").Scan(&name, &version)
-	return
-}
-
-// GetTables extracts tables with COMMENTS from INFORMATION_SCHEMA (CRITICAL RULE #1)
-func (e *Extractor) GetTables(ctx context.Context) ([]model.Table, error) {
-	query := `
-		SELECT 
-			TABLE_SCHEMA,
-			TABLE_NAME,
-			ENGINE,
-			TABLE_ROWS,
-			IFNULL(TABLE_COMMENT, '') as TABLE_COMMENT,
-			CREATE_TIME,
-			UPDATE_TIME
-		FROM INFORMATION_SCHEMA.TABLES
-		WHERE TABLE_TYPE = 'BASE TABLE'
-	`
-
-	// CRITICAL RULE #2: Schema filtering
-	if len(e.schemaFilter) > 0 {
-		placeholders := make([]string, len(e.schemaFilter))
-		for i := range e.schemaFilter {
-			placeholders[i] = "?"
-		}
-		query += fmt.Sprintf(" AND TABLE_SCHEMA IN (%s)", strings.Join(placeholders, ","))
-	}
-
-	query += " ORDER BY TABLE_SCHEMA, TABLE_NAME"
-
-	var args []interface{}
-	for _, schema := range e.schemaFilter {
-		args = append(args, schema)
-	}
-
-	rows, err := e.db.QueryContext(ctx, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query tables: %w", err)
-	}
-	defer rows.Close()
-
-	var tables []model.Table
-	for rows.Next() {
-		var t model.Table
-		var rowCount sql.NullInt64
-		var engine sql.NullString
-		var createTime, updateTime sql.NullTime
-
-		err := rows.Scan(
-			&t.Owner, &t.Name, &engine, &rowCount, &t.Comment,
-			&createTime, &updateTime,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		if engine.Valid {
-			t.Type = engine.String
-		}
-		if rowCount.Valid {
-			t.RowCount = rowCount.Int64
-		}
-		if createTime.Valid {
-			t.CreatedAt = createTime.Time.Format("2006-01-02 15:04:05")
-		}
-		if updateTime.Valid {
-			t.ModifiedAt = updateTime.Time.Format("2006-01-02 15:04:05")
-		}
-
-		// Fetch columns
-		t.Columns, err = e.getColumnsForTable(ctx, t.Owner, t.Name)
-		if err != nil {
-			return nil, err
-		}
-
-		// Fetch indexes
-		t.Indexes, err = e.getIndexesForTable(ctx, t.Owner, t.Name)
-		if err != nil {
-			return nil, err
-		}
-
-		tables = append(tables, t)
-	}
-
-	return tables, rows.Err()
-}
-
-// getColumnsForTable retrieves columns with COLUMN_COMMENT (CRITICAL RULE #1)
-func (e *Extractor) getColumnsForTable(ctx context.Context, schema, tableName string) ([]model.Column, error) {
-	query := `
-		SELECT 
-			COLUMN_NAME,
-			ORDINAL_POSITION,
-			DATA_TYPE,
-			IFNULL(CHARACTER_MAXIMUM_LENGTH, 0),
-			IFNULL(NUMERIC_PRECISION, 0),
-			IFNULL(NUMERIC_SCALE, 0),
-			IS_NULLABLE,
-			IFNULL(COLUMN_DEFAULT, ''),
-			IFNULL(COLUMN_COMMENT, ''),
-			COLUMN_KEY,
-			EXTRA
-		FROM INFORMATION_SCHEMA.COLUMNS
-		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
-		ORDER BY ORDINAL_POSITION
-	`
-
-	rows, err := e.db.QueryContext(ctx, query, schema, tableName)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var columns []model.Column
-	for rows.Next() {
-		var col model.Column
-		var nullable, columnKey, extra string
-
-		err := rows.Scan(
-			&col.Name, &col.Position, &col.DataType, &col.Length,
-			&col.Precision, &col.Scale, &nullable, &col.DefaultValue,
-			&col.Comment, &columnKey, &extra,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		col.Nullable = (nullable == "YES")
-		col.IsPrimaryKey = (columnKey == "PRI")
-		col.IsForeignKey = (columnKey == "MUL" || columnKey == "FOR")
-		col.IsUnique = (columnKey == "UNI")
-		col.IsAutoIncrement = strings.Contains(extra, "auto_increment")
-
-		// Get FK target info if applicable
-		if col.IsForeignKey {
-			fkInfo, err := e.getForeignKeyTarget(ctx, schema, tableName, col.Name)
-			if err == nil && fkInfo != nil {
-				col.FKTargetTable = fkInfo["table"]
-				col.FKTargetColumn = fkInfo["column"]
-			}
-		}
-
-		columns = append(columns, col)
-	}
-
-	return columns, rows.Err()
-}
-
-// getForeignKeyTarget retrieves FK target information
-func (e *Extractor) getForeignKeyTarget(ctx context.Context, schema, table, column string) (map[string]string, error) {
-	query := `
-		SELECT 
-			REFERENCED_TABLE_NAME,
-			REFERENCED_COLUMN_NAME
-		FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE
-		WHERE TABLE_SCHEMA = ? 
-		AND TABLE_NAME = ? 
-		AND COLUMN_NAME = ?
-		AND REFERENCED_TABLE_NAME IS NOT NULL
-		LIMIT 1
-	`
-
-	var refTable, refColumn sql.NullString
-	err := e.db.QueryRowContext(ctx, query, schema, table, column).Scan(&refTable, &refColumn)
-	if err != nil {
-		return nil, err
-	}
-
-	if refTable.Valid && refColumn.Valid {
-		return map[string]string{
-			"table":  refTable.String,
-			"column": refColumn.String,
-		}, nil
-	}
-
-	return nil, nil
-}
-
-// getIndexesForTable retrieves indexes
-func (e *Extractor) getIndexesForTable(ctx context.Context, schema, tableName string) ([]model.Index, error) {
-	query := `
-		SELECT DISTINCT
-			INDEX_NAME,
-			INDEX_TYPE,
-			NON_UNIQUE
-		FROM INFORMATION_SCHEMA.STATISTICS
-		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
-		ORDER BY INDEX_NAME
-	`
-
-	rows, err := e.db.QueryContext(ctx, query, schema, tableName)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var indexes []model.Index
-	for rows.Next() {
-		var idx model.Index
-		var nonUnique int
-
-		err := rows.Scan(&idx.Name, &idx.Type, &nonUnique)
-		if err != nil {
-			return nil, err
-		}
-
-		idx.TableName = tableName
-		idx.Owner = schema
-		idx.IsUnique = (nonUnique == 0)
-		idx.IsPrimary = (idx.Name == "PRIMARY")
-		idx.IsEnabled = true
-		idx.Comment = ""
-
-		// Fetch columns
-		idx.Columns, err = e.getIndexColumns(ctx, schema, tableName, idx.Name)
-		if err != nil {
-			return nil, err
-		}
-
-		indexes = append(indexes, idx)
-	}
-
-	return indexes, rows.Err()
-}
-
-// getIndexColumns retrieves columns for an index
-func (e *Extractor) getIndexColumns(ctx context.Context, schema, table, indexName string) ([]string, error) {
-	query := `
-		SELECT COLUMN_NAME
-		FROM INFORMATION_SCHEMA.STATISTICS
-		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND INDEX_NAME = ?
-		ORDER BY SEQ_IN_INDEX
-	`
-
-	rows, err := e.db.QueryContext(ctx, query, schema, table, indexName)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var columns []string
-	for rows.Next() {
-		var col string
-		if err := rows.Scan(&col); err != nil {
-			return nil, err
-		}
-		columns = append(columns, col)
-	}
-
-	return columns, rows.Err()
-}
-
-// GetViews extracts views with COMMENTS (NO definition - security!)
-func (e *Extractor) GetViews(ctx context.Context) ([]model.View, error) {
-	query := `
-		SELECT 
-			TABLE_SCHEMA,
-			TABLE_NAME,
-			IFNULL(TABLE_COMMENT, ''),
-			IS_UPDATABLE
-		FROM INFORMATION_SCHEMA.VIEWS
-		WHERE 1=1
-	`
-
-	if len(e.schemaFilter) > 0 {
-		placeholders := make([]string, len(e.schemaFilter))
-		for i := range e.schemaFilter {
-			placeholders[i] = "?"
-		}
-		query += fmt.Sprintf(" AND TABLE_SCHEMA IN (%s)", strings.Join(placeholders, ","))
-	}
-
-	var args []interface{}
-	for _, schema := range e.schemaFilter {
-		args = append(args, schema)
-	}
-
-	rows, err := e.db.QueryContext(ctx, query, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var views []model.View
-	for rows.Next() {
-		var v model.View
-		var updatable string
-
-		err := rows.Scan(&v.Owner, &v.Name, &v.Comment, &updatable)
-		if err != nil {
-			return nil, err
-		}
-
-		v.Type = "VIEW"
-		v.IsUpdatable = (updatable == "YES")
-
-		// Fetch columns
-		v.Columns, err = e.getColumnsForTable(ctx, v.Owner, v.Name)
-		if err != nil {
-			return nil, err
-		}
-
-		views = append(views, v)
-	}
-
-	return views, rows.Err()
-}
-
-// GetRoutines extracts procedures/functions with COMMENTS (NO source - security!)
-func (e *Extractor) GetRoutines(ctx context.Context) ([]model.Routine, error) {
-	query := `
-		SELECT 
-			ROUTINE_SCHEMA,
-			ROUTINE_NAME,
-			ROUTINE_TYPE,
-			IFNULL(ROUTINE_COMMENT, ''),
-			IFNULL(DTD_IDENTIFIER, '')
-		FROM INFORMATION_SCHEMA.ROUTINES
-		WHERE 1=1
-	`
-
-	if len(e.schemaFilter) > 0 {
-		placeholders := make([]string, len(e.schemaFilter))
-		for i := range e.schemaFilter {
-			placeholders[i] = "?"
-		}
-		query += fmt.Sprintf(" AND ROUTINE_SCHEMA IN (%s)", strings.Join(placeholders, ","))
-	}
-
-	var args []interface{}
-	for _, schema := range e.schemaFilter {
-		args = append(args, schema)
-	}
-
-	rows, err := e.db.QueryContext(ctx, query, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var routines []model.Routine
-	for rows.Next() {
-		var r model.Routine
-		var returnType string
-
-		err := rows.Scan(&r.Owner, &r.Name, &r.Type, &r.Comment, &returnType)
-		if err != nil {
-			return nil, err
-		}
-
-		if r.Type == "FUNCTION" {
-			r.ReturnType = returnType
-		}
-		r.Language = "SQL"
-
-		// Fetch parameters
-		r.Arguments, err = e.getRoutineParameters(ctx, r.Owner, r.Name)
-		if err != nil {
-			return nil, err
-		}
-
-		// Build signature
-		r.Signature = e.buildSignature(r.Name, r.Arguments, r.Type)
-
-		routines = append(routines, r)
-	}
-
-	return routines, rows.Err()
-}
-
-// getRoutineParameters retrieves parameters
-func (e *Extractor) getRoutineParameters(ctx context.Context, schema, routineName string) ([]model.RoutineArgument, error) {
-	query := `
-		SELECT 
-			PARAMETER_NAME,
-			ORDINAL_POSITION,
-			PARAMETER_MODE,
-			DATA_TYPE
-		FROM INFORMATION_SCHEMA.PARAMETERS
-		WHERE SPECIFIC_SCHEMA = ? AND SPECIFIC_NAME = ?
-		AND PARAMETER_NAME IS NOT NULL
-		ORDER BY ORDINAL_POSITION
-	`
-
-	rows, err := e.db.QueryContext(ctx, query, schema, routineName)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var args []model.RoutineArgument
-	for rows.Next() {
-		var arg model.RoutineArgument
-
-		err := rows.Scan(&arg.Name, &arg.Position, &arg.Mode, &arg.DataType)
-		if err != nil {
-			return nil, err
-		}
-
-		args = append(args, arg)
-	}
-
-	return args, rows.Err()
-}
-
-// buildSignature creates routine signature
-func (e *Extractor) buildSignature(name string, args []model.RoutineArgument, routineType string) string {
-	argStrs := make([]string, len(args))
-	for i, arg := range args {
-		argStrs[i] = fmt.Sprintf("%s %s %s", arg.Mode, arg.Name, arg.DataType)
-	}
-
-	return fmt.Sprintf("%s %s(%s)", routineType, name, strings.Join(argStrs, ", "))
-}
-
-// GetSequences - MySQL doesn't have sequences (use AUTO_INCREMENT)
-func (e *Extractor) GetSequences(ctx context.Context) ([]model.Sequence, error) {
-	return []model.Sequence{}, nil
-}
-
-// GetTriggers extracts triggers with COMMENTS (NO body - security!)
-func (e *Extractor) GetTriggers(ctx context.Context) ([]model.Trigger, error) {
-	query := `
-		SELECT 
-			TRIGGER_SCHEMA,
-			TRIGGER_NAME,
-			EVENT_OBJECT_SCHEMA,
-			EVENT_OBJECT_TABLE,
-			ACTION_TIMING,
-			EVENT_MANIPULATION,
-			'ENABLED' as STATUS
-		FROM INFORMATION_SCHEMA.TRIGGERS
-		WHERE 1=1
-	`
-
-	if len(e.schemaFilter) > 0 {
-		placeholders := make([]string, len(e.schemaFilter))
-		for i := range e.schemaFilter {
-			placeholders[i] = "?"
-		}
-		query += fmt.Sprintf(" AND TRIGGER_SCHEMA IN (%s)", strings.Join(placeholders, ","))
-	}
-
-	var args []interface{}
-	for _, schema := range e.schemaFilter {
-		args = append(args, schema)
-	}
-
-	rows, err := e.db.QueryContext(ctx, query, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var triggers []model.Trigger
-	for rows.Next() {
-		var trg model.Trigger
-		var objectSchema string
-
-		err := rows.Scan(
-			&trg.Owner, &trg.Name, &objectSchema, &trg.TargetTable,
-			&trg.Timing, &trg.Event, &trg.Status,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		trg.TargetType = "TABLE"
-		trg.Level = "ROW" // MySQL triggers are row-level
-		trg.Comment = ""
-
-		triggers = append(triggers, trg)
-	}
-
-	return triggers, rows.Err()
-}
-
-// GetSynonyms - MySQL doesn't have synonyms
-func (e *Extractor) GetSynonyms(ctx context.Context) ([]model.Synonym, error) {
-	return []model.Synonym{}, nil
-}
-
-// ExtractSchema performs complete extraction
-func (e *Extractor) ExtractSchema(ctx context.Context) (*model.Schema, error) {
-	schema := &model.Schema{
-		ExtractedAt: time.Now(),
-	}
-
-	var err error
-	schema.DatabaseName, schema.Version, err = e.GetDatabaseInfo(ctx)
-	if err != nil {
-		return nil, err
-	}
-	schema.DatabaseType = "MySQL"
-
-	schema.Tables, err = e.GetTables(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	schema.Views, err = e.GetViews(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	schema.Routines, err = e.GetRoutines(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	schema.Sequences, err = e.GetSequences(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	schema.Triggers, err = e.GetTriggers(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	schema.Synonyms, err = e.GetSynonyms(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	for _, table := range schema.Tables {
-		schema.Indexes = append(schema.Indexes, table.Indexes...)
-	}
-
-	return schema, nil
-}
+﻿package mysql
+
+import (
+	"context"
+	"database/sql"
+	"pocket-doc/internal/dbquery"
+	"pocket-doc/internal/logging"
+	"pocket-doc/internal/model"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// Extractor implements MySQL database metadata extraction
+type Extractor struct {
+	db           *sql.DB
+	config       Config
+	schemaFilter []string
+	log          *slog.Logger
+	skip         map[string]bool // sections left out (see SkipSections)
+	features     features        // catalog differences of the server version
+}
+
+// Config holds MySQL-specific configuration
+type Config struct {
+	Host         string
+	Port         int
+	Database     string
+	Username     string
+	Password     string
+	SchemaFilter []string      // Filter by SCHEMA
+	Logger       *slog.Logger  // debug-level query timing (nil = slog.Default())
+	QueryTimeout time.Duration // limit of each catalog query (0 = none)
+	Hooks        dbquery.Hooks // observe queries and extracted objects (nil = none)
+}
+
+// NewExtractor creates a new MySQL extractor
+func NewExtractor(cfg Config) (*Extractor, error) {
+	// Build MySQL DSN: user:password@tcp(host:port)/dbname
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true",
+		cfg.Username, cfg.Password, cfg.Host, cfg.Port, cfg.Database)
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mysql connection: %w", err)
+	}
+
+	schemas := cfg.SchemaFilter
+	if len(schemas) == 0 {
+		schemas = []string{cfg.Database} // Default to connected database
+	}
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Extractor{
+		db:           db,
+		config:       cfg,
+		schemaFilter: schemas,
+		log:          logger,
+	}, nil
+}
+
+// Connect establishes connection and detects the server version
+func (e *Extractor) Connect(ctx context.Context) error {
+	if err := e.db.PingContext(ctx); err != nil {
+		return err
+	}
+	e.detectFeatures(ctx)
+	return nil
+}
+
+// Close releases resources
+func (e *Extractor) Close() error {
+	if e.db != nil {
+		return e.db.Close()
+	}
+	return nil
+}
+
+// query runs a catalog query, logging its SQL and duration at debug level
+func (e *Extractor) query(ctx context.Context, query string, args ...interface{}) (*dbquery.Rows, error) {
+	start := time.Now()
+	rows, err := dbquery.Query(ctx, e.db, e.queryOptions(), query, args...)
+	logging.Query(ctx, e.log, query, start, err)
+	return rows, err
+}
+
+// queryRow runs a single-row catalog query, logging it like query
+func (e *Extractor) queryRow(ctx context.Context, query string, args ...interface{}) *dbquery.Row {
+	start := time.Now()
+	row := dbquery.QueryRow(ctx, e.db, e.queryOptions(), query, args...)
+	logging.Query(ctx, e.log, query, start, row.Err())
+	return row
+}
+
+// requiredCatalogs are the catalog views read during extraction
+var requiredCatalogs = []string{
+	"INFORMATION_SCHEMA.TABLES", "INFORMATION_SCHEMA.COLUMNS", "INFORMATION_SCHEMA.KEY_COLUMN_USAGE",
+	"INFORMATION_SCHEMA.STATISTICS", "INFORMATION_SCHEMA.VIEWS", "INFORMATION_SCHEMA.ROUTINES",
+	"INFORMATION_SCHEMA.PARAMETERS", "INFORMATION_SCHEMA.TRIGGERS",
+}
+
+// RequiredCatalogs lists the catalog views the connected user must be able to read
+func (e *Extractor) RequiredCatalogs() []string {
+	return requiredCatalogs
+}
+
+// ProbeCatalog checks that the connected user can read a catalog view
+func (e *Extractor) ProbeCatalog(ctx context.Context, catalog string) error {
+	var one int
+	err := e.queryRow(ctx, "SELECT 1 FROM "+catalog+" WHERE 1 = 0").Scan(&one)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	return err
+}
+
+// CountObjects counts the objects ExtractSchema reads, one catalog query
+// per type
+func (e *Extractor) CountObjects(ctx context.Context) (map[string]int, error) {
+	counts := []struct {
+		kind, query, schema string
+	}{
+		{"schemas", "SELECT COUNT(*) FROM INFORMATION_SCHEMA.SCHEMATA WHERE 1=1", "SCHEMA_NAME"},
+		{"tables", "SELECT COUNT(*) FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_TYPE = 'BASE TABLE'", "TABLE_SCHEMA"},
+		{"columns", "SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLUMNS WHERE 1=1", "TABLE_SCHEMA"},
+		{"indexes", "SELECT COUNT(DISTINCT TABLE_SCHEMA, TABLE_NAME, INDEX_NAME) FROM INFORMATION_SCHEMA.STATISTICS WHERE 1=1", "TABLE_SCHEMA"},
+		{"views", "SELECT COUNT(*) FROM INFORMATION_SCHEMA.VIEWS WHERE 1=1", "TABLE_SCHEMA"},
+		{"routines", "SELECT COUNT(*) FROM INFORMATION_SCHEMA.ROUTINES WHERE 1=1", "ROUTINE_SCHEMA"},
+		{"triggers", "SELECT COUNT(*) FROM INFORMATION_SCHEMA.TRIGGERS WHERE 1=1", "TRIGGER_SCHEMA"},
+	}
+
+	placeholders := make([]string, len(e.schemaFilter))
+	var args []interface{}
+	for i, schema := range e.schemaFilter {
+		placeholders[i] = "?"
+		args = append(args, schema)
+	}
+
+	result := make(map[string]int, len(counts))
+	for _, c := range counts {
+		query := c.query
+		if len(e.schemaFilter) > 0 {
+			query += fmt.Sprintf(" AND %s IN (%s)", c.schema, strings.Join(placeholders, ","))
+		}
+		var n int
+		if err := e.queryRow(ctx, query, args...).Scan(&n); err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", c.kind, err)
+		}
+		result[c.kind] = n
+	}
+	return result, nil
+}
+
+// SampleValues reads up to limit non-null values of a column as text for
+// example values; it reads table data, so it only runs when enabled
+func (e *Extractor) SampleValues(ctx context.Context, owner, table, column string, limit int) ([]string, error) {
+	query := fmt.Sprintf("SELECT CAST(%[1]s AS CHAR) FROM %[2]s.%[3]s WHERE %[1]s IS NOT NULL LIMIT ?",
+		quoteIdent(column), quoteIdent(owner), quoteIdent(table))
+	rows, err := e.query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var v sql.NullString
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		if v.Valid {
+			values = append(values, v.String)
+		}
+	}
+	return values, rows.Err()
+}
+
+// ReadRows reads columns of every row of a table, ordered by orderBy (the
+// migration history, see extractor.ReadMigrations)
+func (e *Extractor) ReadRows(ctx context.Context, owner, table string, columns []string, orderBy string) ([][]any, error) {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = quoteIdent(c)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s.%s ORDER BY %s",
+		strings.Join(quoted, ", "), quoteIdent(owner), quoteIdent(table), quoteIdent(orderBy))
+	rows, err := e.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out [][]any
+	for rows.Next() {
+		values := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		out = append(out, values)
+	}
+	return out, rows.Err()
+}
+
+// quoteIdent backquotes a MySQL identifier
+func quoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// GetDatabaseInfo retrieves database information
+func (e *Extractor) GetDatabaseInfo(ctx context.Context) (name, version string, err error) {
+	err = e.queryRow(ctx, "SELECT DATABASE(), VERSION()").Scan(&name, &version)
+	return
+}
+
+// GetTables extracts tables with COMMENTS from INFORMATION_SCHEMA (CRITICAL RULE #1)
+func (e *Extractor) GetTables(ctx context.Context) ([]model.Table, error) {
+	query := `
+		SELECT 
+			TABLE_SCHEMA,
+			TABLE_NAME,
+			ENGINE,
+			TABLE_ROWS,
+			IFNULL(TABLE_COMMENT, '') as TABLE_COMMENT,
+			CREATE_TIME,
+			UPDATE_TIME
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE TABLE_TYPE = 'BASE TABLE'
+	`
+
+	// CRITICAL RULE #2: Schema filtering
+	if len(e.schemaFilter) > 0 {
+		placeholders := make([]string, len(e.schemaFilter))
+		for i := range e.schemaFilter {
+			placeholders[i] = "?"
+		}
+		query += fmt.Sprintf(" AND TABLE_SCHEMA IN (%s)", strings.Join(placeholders, ","))
+	}
+
+	query += " ORDER BY TABLE_SCHEMA, TABLE_NAME"
+
+	var args []interface{}
+	for _, schema := range e.schemaFilter {
+		args = append(args, schema)
+	}
+
+	rows, err := e.query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []model.Table
+	for rows.Next() {
+		var t model.Table
+		var rowCount sql.NullInt64
+		var engine sql.NullString
+		var createTime, updateTime sql.NullTime
+
+		err := rows.Scan(
+			&t.Owner, &t.Name, &engine, &rowCount, &t.Comment,
+			&createTime, &updateTime,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if engine.Valid {
+			t.Type = engine.String
+		}
+		if rowCount.Valid {
+			t.RowCount = rowCount.Int64
+		}
+		if createTime.Valid {
+			t.CreatedAt = createTime.Time.Format("2006-01-02 15:04:05")
+		}
+		if updateTime.Valid {
+			t.ModifiedAt = updateTime.Time.Format("2006-01-02 15:04:05")
+		}
+
+		tables = append(tables, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// Release the table list, and its query timeout, before the queries
+	// of each table
+	rows.Close()
+
+	for i := range tables {
+		t := &tables[i]
+
+		// Fetch columns
+		t.Columns, err = e.getColumnsForTable(ctx, t.Owner, t.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		// Fetch indexes
+		t.Indexes, err = e.getIndexesForTable(ctx, t.Owner, t.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// INFORMATION_SCHEMA only lists what the user may access, so missing
+	// privileges show as missing tables rather than errors
+	if len(tables) == 0 && e.features.roles {
+		e.log.Warn("no tables visible; privileges granted through a role only apply while it is active",
+			"hint", "SET DEFAULT ROLE ALL TO "+e.config.Username)
+	}
+	return tables, nil
+}
+
+// getColumnsForTable retrieves columns with COLUMN_COMMENT (CRITICAL RULE #1)
+func (e *Extractor) getColumnsForTable(ctx context.Context, schema, tableName string) ([]model.Column, error) {
+	generation := "''"
+	if e.features.generatedColumns {
+		generation = "IFNULL(GENERATION_EXPRESSION, '')"
+	}
+	// An empty string default reads as '' to tell it from no default
+	query := `
+		SELECT 
+			COLUMN_NAME,
+			ORDINAL_POSITION,
+			DATA_TYPE,
+			IFNULL(CHARACTER_MAXIMUM_LENGTH, 0),
+			IFNULL(NUMERIC_PRECISION, 0),
+			IFNULL(NUMERIC_SCALE, 0),
+			IS_NULLABLE,
+			CASE WHEN COLUMN_DEFAULT = '' THEN '''''' ELSE IFNULL(COLUMN_DEFAULT, '') END,
+			IFNULL(COLUMN_COMMENT, ''),
+			COLUMN_KEY,
+			EXTRA,
+			COLUMN_TYPE,
+			IFNULL(CHARACTER_SET_NAME, ''),
+			IFNULL(COLLATION_NAME, ''),
+			` + generation + `
+		FROM INFORMATION_SCHEMA.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION
+	`
+
+	rows, err := e.query(ctx, query, schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []model.Column
+	for rows.Next() {
+		var col model.Column
+		var nullable, columnKey, extra string
+
+		err := rows.Scan(
+			&col.Name, &col.Position, &col.DataType, &col.Length,
+			&col.Precision, &col.Scale, &nullable, &col.DefaultValue,
+			&col.Comment, &columnKey, &extra,
+			&col.ColumnType, &col.CharacterSet, &col.Collation, &col.Generated,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		col.Nullable = (nullable == "YES")
+		col.IsPrimaryKey = (columnKey == "PRI")
+		col.IsForeignKey = (columnKey == "MUL" || columnKey == "FOR")
+		col.IsUnique = (columnKey == "UNI")
+		col.IsAutoIncrement = strings.Contains(extra, "auto_increment")
+		col.Extra = extra
+
+		// Get FK target info if applicable
+		if col.IsForeignKey {
+			fkInfo, err := e.getForeignKeyTarget(ctx, schema, tableName, col.Name)
+			if err == nil && fkInfo != nil {
+				col.FKTargetTable = fkInfo["table"]
+				col.FKTargetColumn = fkInfo["column"]
+			}
+		}
+
+		columns = append(columns, col)
+	}
+
+	return columns, rows.Err()
+}
+
+// getForeignKeyTarget retrieves FK target information
+func (e *Extractor) getForeignKeyTarget(ctx context.Context, schema, table, column string) (map[string]string, error) {
+	query := `
+		SELECT 
+			REFERENCED_TABLE_NAME,
+			REFERENCED_COLUMN_NAME
+		FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = ? 
+		AND TABLE_NAME = ? 
+		AND COLUMN_NAME = ?
+		AND REFERENCED_TABLE_NAME IS NOT NULL
+		LIMIT 1
+	`
+
+	var refTable, refColumn sql.NullString
+	err := e.queryRow(ctx, query, schema, table, column).Scan(&refTable, &refColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	if refTable.Valid && refColumn.Valid {
+		return map[string]string{
+			"table":  refTable.String,
+			"column": refColumn.String,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// getIndexesForTable retrieves indexes
+func (e *Extractor) getIndexesForTable(ctx context.Context, schema, tableName string) ([]model.Index, error) {
+	visible := "'YES'"
+	if e.features.invisibleIndexes {
+		visible = "IS_VISIBLE"
+	}
+	query := `
+		SELECT DISTINCT
+			INDEX_NAME,
+			INDEX_TYPE,
+			NON_UNIQUE,
+			` + visible + `
+		FROM INFORMATION_SCHEMA.STATISTICS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+		ORDER BY INDEX_NAME
+	`
+
+	rows, err := e.query(ctx, query, schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexes []model.Index
+	for rows.Next() {
+		var idx model.Index
+		var nonUnique int
+		var visible string
+
+		err := rows.Scan(&idx.Name, &idx.Type, &nonUnique, &visible)
+		if err != nil {
+			return nil, err
+		}
+
+		idx.TableName = tableName
+		idx.Owner = schema
+		idx.IsUnique = (nonUnique == 0)
+		idx.IsPrimary = (idx.Name == "PRIMARY")
+		idx.IsEnabled = (visible == "YES") // invisible indexes are ignored by the optimizer
+		idx.Comment = ""
+
+		// Fetch columns
+		idx.Columns, err = e.getIndexColumns(ctx, schema, tableName, idx.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		indexes = append(indexes, idx)
+	}
+
+	return indexes, rows.Err()
+}
+
+// getIndexColumns retrieves columns for an index
+func (e *Extractor) getIndexColumns(ctx context.Context, schema, table, indexName string) ([]string, error) {
+	column := "COLUMN_NAME"
+	if e.features.expressionIndexes {
+		column = "IFNULL(COLUMN_NAME, CONCAT('(', EXPRESSION, ')'))"
+	}
+	query := `
+		SELECT ` + column + `
+		FROM INFORMATION_SCHEMA.STATISTICS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND INDEX_NAME = ?
+		ORDER BY SEQ_IN_INDEX
+	`
+
+	rows, err := e.query(ctx, query, schema, table, indexName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var col string
+		if err := rows.Scan(&col); err != nil {
+			return nil, err
+		}
+		columns = append(columns, col)
+	}
+
+	return columns, rows.Err()
+}
+
+// GetViews extracts views with COMMENTS (NO definition - security!)
+func (e *Extractor) GetViews(ctx context.Context) ([]model.View, error) {
+	if e.skip["views"] {
+		return nil, nil
+	}
+	query := `
+		SELECT 
+			TABLE_SCHEMA,
+			TABLE_NAME,
+			IFNULL(TABLE_COMMENT, ''),
+			IS_UPDATABLE
+		FROM INFORMATION_SCHEMA.VIEWS
+		WHERE 1=1
+	`
+
+	if len(e.schemaFilter) > 0 {
+		placeholders := make([]string, len(e.schemaFilter))
+		for i := range e.schemaFilter {
+			placeholders[i] = "?"
+		}
+		query += fmt.Sprintf(" AND TABLE_SCHEMA IN (%s)", strings.Join(placeholders, ","))
+	}
+
+	var args []interface{}
+	for _, schema := range e.schemaFilter {
+		args = append(args, schema)
+	}
+
+	rows, err := e.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var views []model.View
+	for rows.Next() {
+		var v model.View
+		var updatable string
+
+		err := rows.Scan(&v.Owner, &v.Name, &v.Comment, &updatable)
+		if err != nil {
+			return nil, err
+		}
+
+		v.Type = "VIEW"
+		v.IsUpdatable = (updatable == "YES")
+
+		views = append(views, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// Release the view list, and its query timeout, before the queries
+	// of each view
+	rows.Close()
+
+	for i := range views {
+		v := &views[i]
+
+		// Fetch columns
+		v.Columns, err = e.getColumnsForTable(ctx, v.Owner, v.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return views, nil
+}
+
+// GetRoutines extracts procedures/functions with COMMENTS (NO source - security!)
+func (e *Extractor) GetRoutines(ctx context.Context) ([]model.Routine, error) {
+	if e.skip["routines"] {
+		return nil, nil
+	}
+	query := `
+		SELECT 
+			ROUTINE_SCHEMA,
+			ROUTINE_NAME,
+			ROUTINE_TYPE,
+			IFNULL(ROUTINE_COMMENT, ''),
+			IFNULL(DTD_IDENTIFIER, '')
+		FROM INFORMATION_SCHEMA.ROUTINES
+		WHERE 1=1
+	`
+
+	if len(e.schemaFilter) > 0 {
+		placeholders := make([]string, len(e.schemaFilter))
+		for i := range e.schemaFilter {
+			placeholders[i] = "?"
+		}
+		query += fmt.Sprintf(" AND ROUTINE_SCHEMA IN (%s)", strings.Join(placeholders, ","))
+	}
+
+	var args []interface{}
+	for _, schema := range e.schemaFilter {
+		args = append(args, schema)
+	}
+
+	rows, err := e.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var routines []model.Routine
+	for rows.Next() {
+		var r model.Routine
+		var returnType string
+
+		err := rows.Scan(&r.Owner, &r.Name, &r.Type, &r.Comment, &returnType)
+		if err != nil {
+			return nil, err
+		}
+
+		if r.Type == "FUNCTION" {
+			r.ReturnType = returnType
+		}
+		r.Language = "SQL"
+
+		routines = append(routines, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// Release the routine list, and its query timeout, before the queries
+	// of each routine
+	rows.Close()
+
+	for i := range routines {
+		r := &routines[i]
+
+		// Fetch parameters
+		r.Arguments, err = e.getRoutineParameters(ctx, r.Owner, r.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		// Build signature
+		r.Signature = e.buildSignature(r.Name, r.Arguments, r.Type)
+	}
+
+	return routines, nil
+}
+
+// getRoutineParameters retrieves parameters
+func (e *Extractor) getRoutineParameters(ctx context.Context, schema, routineName string) ([]model.RoutineArgument, error) {
+	query := `
+		SELECT 
+			PARAMETER_NAME,
+			ORDINAL_POSITION,
+			PARAMETER_MODE,
+			DATA_TYPE
+		FROM INFORMATION_SCHEMA.PARAMETERS
+		WHERE SPECIFIC_SCHEMA = ? AND SPECIFIC_NAME = ?
+		AND PARAMETER_NAME IS NOT NULL
+		ORDER BY ORDINAL_POSITION
+	`
+
+	rows, err := e.query(ctx, query, schema, routineName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var args []model.RoutineArgument
+	for rows.Next() {
+		var arg model.RoutineArgument
+
+		err := rows.Scan(&arg.Name, &arg.Position, &arg.Mode, &arg.DataType)
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, arg)
+	}
+
+	return args, rows.Err()
+}
+
+// buildSignature creates routine signature
+func (e *Extractor) buildSignature(name string, args []model.RoutineArgument, routineType string) string {
+	argStrs := make([]string, len(args))
+	for i, arg := range args {
+		argStrs[i] = fmt.Sprintf("%s %s %s", arg.Mode, arg.Name, arg.DataType)
+	}
+
+	return fmt.Sprintf("%s %s(%s)", routineType, name, strings.Join(argStrs, ", "))
+}
+
+// GetSequences - MySQL doesn't have sequences (use AUTO_INCREMENT)
+func (e *Extractor) GetSequences(ctx context.Context) ([]model.Sequence, error) {
+	return []model.Sequence{}, nil
+}
+
+// GetTriggers extracts triggers with COMMENTS (NO body - security!)
+func (e *Extractor) GetTriggers(ctx context.Context) ([]model.Trigger, error) {
+	if e.skip["triggers"] {
+		return nil, nil
+	}
+	query := `
+		SELECT 
+			TRIGGER_SCHEMA,
+			TRIGGER_NAME,
+			EVENT_OBJECT_SCHEMA,
+			EVENT_OBJECT_TABLE,
+			ACTION_TIMING,
+			EVENT_MANIPULATION,
+			'ENABLED' as STATUS
+		FROM INFORMATION_SCHEMA.TRIGGERS
+		WHERE 1=1
+	`
+
+	if len(e.schemaFilter) > 0 {
+		placeholders := make([]string, len(e.schemaFilter))
+		for i := range e.schemaFilter {
+			placeholders[i] = "?"
+		}
+		query += fmt.Sprintf(" AND TRIGGER_SCHEMA IN (%s)", strings.Join(placeholders, ","))
+	}
+
+	var args []interface{}
+	for _, schema := range e.schemaFilter {
+		args = append(args, schema)
+	}
+
+	rows, err := e.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var triggers []model.Trigger
+	for rows.Next() {
+		var trg model.Trigger
+		var objectSchema string
+
+		err := rows.Scan(
+			&trg.Owner, &trg.Name, &objectSchema, &trg.TargetTable,
+			&trg.Timing, &trg.Event, &trg.Status,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		trg.TargetType = "TABLE"
+		trg.Level = "ROW" // MySQL triggers are row-level
+		trg.Comment = ""
+
+		triggers = append(triggers, trg)
+	}
+
+	return triggers, rows.Err()
+}
+
+// GetSynonyms - MySQL doesn't have synonyms
+func (e *Extractor) GetSynonyms(ctx context.Context) ([]model.Synonym, error) {
+	return []model.Synonym{}, nil
+}
+
+// ExtractSchema performs complete extraction
+func (e *Extractor) ExtractSchema(ctx context.Context) (*model.Schema, error) {
+	schema := &model.Schema{
+		ExtractedAt: time.Now(),
+	}
+
+	var err error
+	schema.DatabaseName, schema.Version, err = e.GetDatabaseInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	schema.DatabaseType = "MySQL"
+
+	schema.Tables, err = e.GetTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schema.Views, err = e.GetViews(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schema.Routines, err = e.GetRoutines(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schema.Sequences, err = e.GetSequences(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schema.Triggers, err = e.GetTriggers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schema.Synonyms, err = e.GetSynonyms(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// MySQL has no dependency catalog for views and routines; tables are
+	// cross-referenced with their triggers only
+	var deps []model.Dependency
+	for _, trg := range schema.Triggers {
+		deps = append(deps, model.Dependency{
+			Object:     model.ObjectRef{Type: "TRIGGER", Owner: trg.Owner, Name: trg.Name},
+			TableOwner: trg.Owner,
+			Table:      trg.TargetTable,
+		})
+	}
+	model.ApplyDependencies(schema, deps)
+
+	for _, table := range schema.Tables {
+		schema.Indexes = append(schema.Indexes, table.Indexes...)
+	}
+	dbquery.ReportObjects(ctx, e.config.Hooks, schema)
+
+	return schema, nil
+}
@@ -0,0 +1,71 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// features are the catalog differences between the MySQL versions the
+// extractor supports (5.6 to 8.x). They are detected on connecting; when
+// the version cannot be read, or the server is MariaDB (whose
+// INFORMATION_SCHEMA follows 5.7), all are off.
+type features struct {
+	// expressionIndexes: functional key parts (8.0.13) have a NULL
+	// STATISTICS.COLUMN_NAME and their expression in STATISTICS.EXPRESSION
+	expressionIndexes bool
+
+	// invisibleIndexes: STATISTICS.IS_VISIBLE (8.0)
+	invisibleIndexes bool
+
+	// roles: privileges granted through roles (8.0) only apply once the
+	// role is active, by default only for default roles
+	roles bool
+
+	// generatedColumns: COLUMNS.GENERATION_EXPRESSION (5.7)
+	generatedColumns bool
+}
+
+// mysqlVersion finds major.minor.patch of VERSION(), e.g. "8.0.32-log"
+var mysqlVersion = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)`)
+
+// featuresFor returns the features of a VERSION() string
+func featuresFor(version string) features {
+	var f features
+	m := mysqlVersion.FindStringSubmatch(version)
+	if m == nil || strings.Contains(strings.ToLower(version), "mariadb") {
+		return f
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	patch, _ := strconv.Atoi(m[3])
+	atLeast := func(ma, mi, pa int) bool {
+		if major != ma {
+			return major > ma
+		}
+		if minor != mi {
+			return minor > mi
+		}
+		return patch >= pa
+	}
+	f.expressionIndexes = atLeast(8, 0, 13)
+	f.invisibleIndexes = atLeast(8, 0, 0)
+	f.roles = atLeast(8, 0, 0)
+	f.generatedColumns = atLeast(5, 7, 0)
+	return f
+}
+
+// detectFeatures selects the queries for the version of the connected
+// server
+func (e *Extractor) detectFeatures(ctx context.Context) {
+	_, version, err := e.GetDatabaseInfo(ctx)
+	if err != nil {
+		e.features = featuresFor("")
+		e.log.Debug("mysql version not detected", "error", err)
+		return
+	}
+	e.features = featuresFor(version)
+	e.log.Debug("mysql version detected", "version", version,
+		"expression_indexes", e.features.expressionIndexes, "invisible_indexes", e.features.invisibleIndexes)
+}
@@ -0,0 +1,134 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFeaturesFor(t *testing.T) {
+	tests := []struct {
+		version string
+		want    features
+	}{
+		{"5.6.51", features{}},
+		{"5.6.51-log", features{}},
+		{"5.7.44", features{generatedColumns: true}},
+		{"5.7.44-0ubuntu0.18.04.1", features{generatedColumns: true}},
+		{"8.0.0-dmr", features{invisibleIndexes: true, roles: true, generatedColumns: true}},
+		{"8.0.12", features{invisibleIndexes: true, roles: true, generatedColumns: true}},
+		{"8.0.13", features{expressionIndexes: true, invisibleIndexes: true, roles: true, generatedColumns: true}},
+		{"8.0.36-28", features{expressionIndexes: true, invisibleIndexes: true, roles: true, generatedColumns: true}},
+		{"8.4.0", features{expressionIndexes: true, invisibleIndexes: true, roles: true, generatedColumns: true}},
+		{"9.1.0-commercial", features{expressionIndexes: true, invisibleIndexes: true, roles: true, generatedColumns: true}},
+		// MariaDB reports 5.5.5- before its own version to old clients
+		{"10.11.6-MariaDB-0+deb12u1", features{}},
+		{"5.5.5-10.6.16-MariaDB-log", features{}},
+		{"11.4.2-MariaDB", features{}},
+		{"", features{}},
+		{"8.0", features{}},
+	}
+	for _, tt := range tests {
+		if got := featuresFor(tt.version); got != tt.want {
+			t.Errorf("featuresFor(%q) = %+v, want %+v", tt.version, got, tt.want)
+		}
+	}
+}
+
+// versionConn answers VERSION() with version and every other query with
+// no rows, recording the SQL it receives
+type versionConn struct {
+	mu      sync.Mutex
+	version string
+	queries []string
+}
+
+func (c *versionConn) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c *versionConn) Driver() driver.Driver                        { return nil }
+func (c *versionConn) Prepare(string) (driver.Stmt, error)          { return nil, errors.New("not supported") }
+func (c *versionConn) Close() error                                 { return nil }
+func (c *versionConn) Begin() (driver.Tx, error)                    { return nil, errors.New("not supported") }
+
+func (c *versionConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.mu.Lock()
+	c.queries = append(c.queries, query)
+	c.mu.Unlock()
+	if strings.Contains(query, "VERSION()") {
+		return &versionRows{values: [][]driver.Value{{"shop", c.version}}}, nil
+	}
+	return &versionRows{}, nil
+}
+
+// last returns the last recorded query
+func (c *versionConn) last() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.queries[len(c.queries)-1]
+}
+
+type versionRows struct {
+	values [][]driver.Value
+}
+
+func (r *versionRows) Columns() []string { return []string{"A", "B"} }
+func (r *versionRows) Close() error      { return nil }
+
+func (r *versionRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// TestDetectFeatures validates the catalog columns read for the version of
+// the connected server
+func TestDetectFeatures(t *testing.T) {
+	tests := []struct {
+		version     string
+		visible     bool // STATISTICS.IS_VISIBLE
+		expressions bool // STATISTICS.EXPRESSION
+		generated   bool // COLUMNS.GENERATION_EXPRESSION
+	}{
+		{"5.6.51", false, false, false},
+		{"5.7.44", false, false, true},
+		{"8.0.12", true, false, true},
+		{"8.0.13", true, true, true},
+		{"10.11.6-MariaDB", false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			ctx := context.Background()
+			conn := &versionConn{version: tt.version}
+			e := &Extractor{db: sql.OpenDB(conn), log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+			defer e.Close()
+			e.detectFeatures(ctx)
+
+			if _, err := e.getIndexesForTable(ctx, "shop", "orders"); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(conn.last(), "IS_VISIBLE"); got != tt.visible {
+				t.Errorf("index visibility read = %v, want %v:\n%s", got, tt.visible, conn.last())
+			}
+			if _, err := e.getIndexColumns(ctx, "shop", "orders", "idx_total"); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(conn.last(), "EXPRESSION"); got != tt.expressions {
+				t.Errorf("index expressions read = %v, want %v:\n%s", got, tt.expressions, conn.last())
+			}
+			if _, err := e.getColumnsForTable(ctx, "shop", "orders"); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(conn.last(), "GENERATION_EXPRESSION"); got != tt.generated {
+				t.Errorf("generation expressions read = %v, want %v:\n%s", got, tt.generated, conn.last())
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package mysql
+
+import (
+	"context"
+	"pocket-doc/internal/dbquery"
+)
+
+// sectionCatalogs are the catalogs of requiredCatalogs each optional
+// section reads besides those of tables
+var sectionCatalogs = map[string][]string{
+	"views":    {"INFORMATION_SCHEMA.VIEWS"},
+	"routines": {"INFORMATION_SCHEMA.ROUTINES", "INFORMATION_SCHEMA.PARAMETERS"},
+	"triggers": {"INFORMATION_SCHEMA.TRIGGERS"},
+}
+
+// SectionCatalogs returns the catalogs each optional section reads
+func (e *Extractor) SectionCatalogs() map[string][]string {
+	return sectionCatalogs
+}
+
+// SkipSections makes ExtractSchema leave out sections, e.g. those whose
+// catalogs the user cannot read
+func (e *Extractor) SkipSections(sections []string) {
+	e.skip = make(map[string]bool, len(sections))
+	for _, section := range sections {
+		e.skip[section] = true
+	}
+}
+
+// CheckVersion returns the server version, failing when it is older than
+// the extractor supports
+func (e *Extractor) CheckVersion(ctx context.Context) (string, error) {
+	_, version, err := e.GetDatabaseInfo(ctx)
+	if err != nil {
+		return "", err
+	}
+	return version, dbquery.CheckVersion("mysql", version)
+}
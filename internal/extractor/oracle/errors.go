@@ -0,0 +1,25 @@
+package oracle
+
+import (
+	"pocket-doc/internal/dbquery"
+	"strings"
+)
+
+// queryOptions bounds, classifies and reports the catalog queries
+func (e *Extractor) queryOptions() dbquery.Options {
+	return dbquery.Options{Timeout: e.config.QueryTimeout, Classify: e.classify, Hooks: e.config.Hooks}
+}
+
+// classify maps the Oracle errors of a catalog query to typed errors. A
+// catalog view the user may not read does not exist for it (ORA-00942);
+// a missing column (ORA-00904) means an older server.
+func (e *Extractor) classify(query string, err error) error {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "ORA-00942"), strings.Contains(msg, "ORA-01031"):
+		return &dbquery.PermissionDeniedError{DBType: "oracle", Catalog: dbquery.CatalogOf(query, err, requiredCatalogs), User: e.config.Username, Err: err}
+	case strings.Contains(msg, "ORA-00904"):
+		return &dbquery.UnsupportedVersionError{DBType: "oracle", Catalog: dbquery.CatalogOf(query, err, requiredCatalogs), Err: err}
+	}
+	return err
+}
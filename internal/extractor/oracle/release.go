@@ -0,0 +1,66 @@
+package oracle
+
+import "context"
+
+// release is a family of Oracle releases sharing the catalog columns the
+// extractor reads. Queries of newer families would fail on older servers
+// with ORA-00904 (invalid identifier), so the family is detected on
+// connecting and the oldest one is used when it cannot be.
+type release struct {
+	name  string
+	major int // oldest major release of the family
+
+	// identityColumn tells identity columns of ALL_TAB_COLUMNS c apart
+	identityColumn string
+
+	// sequenceFilter leaves out the sequences generating identity columns
+	// (ISEQ$$_...), which are documented through their columns
+	sequenceFilter string
+
+	annotations bool // ALL_ANNOTATIONS_USAGE (see applyAnnotations)
+}
+
+// releases are the families, newest first
+var releases = []release{
+	{
+		name:           "23ai",
+		major:          23,
+		identityColumn: "c.IDENTITY_COLUMN",
+		sequenceFilter: " AND (SEQUENCE_OWNER, SEQUENCE_NAME) NOT IN (SELECT OWNER, SEQUENCE_NAME FROM ALL_TAB_IDENTITY_COLS)",
+		annotations:    true,
+	},
+	{
+		name:           "12c",
+		major:          12,
+		identityColumn: "c.IDENTITY_COLUMN",
+		sequenceFilter: " AND (SEQUENCE_OWNER, SEQUENCE_NAME) NOT IN (SELECT OWNER, SEQUENCE_NAME FROM ALL_TAB_IDENTITY_COLS)",
+	},
+	{
+		name:           "11g",
+		identityColumn: "'NO'",
+	},
+}
+
+// releaseFor returns the family of a major release (0 = unknown, the
+// oldest family)
+func releaseFor(major int) release {
+	for _, r := range releases {
+		if major >= r.major {
+			return r
+		}
+	}
+	return releases[len(releases)-1]
+}
+
+// detectRelease selects the queries for the release of the connected
+// server from its V$VERSION banner
+func (e *Extractor) detectRelease(ctx context.Context) {
+	_, banner, err := e.GetDatabaseInfo(ctx)
+	if err != nil {
+		e.release = releaseFor(0)
+		e.log.Debug("oracle release not detected", "queries", e.release.name, "error", err)
+		return
+	}
+	e.release = releaseFor(releaseMajor(banner))
+	e.log.Debug("oracle release detected", "banner", banner, "queries", e.release.name)
+}
@@ -0,0 +1,178 @@
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"pocket-doc/internal/model"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// catalogConn answers V$VERSION with banner (or fails it with versionErr)
+// and every other query with no rows, recording the SQL and bind arguments
+// it receives
+type catalogConn struct {
+	mu         sync.Mutex
+	banner     string
+	versionErr error
+	queries    []string
+	args       [][]driver.Value
+}
+
+func (c *catalogConn) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c *catalogConn) Driver() driver.Driver                        { return nil }
+func (c *catalogConn) Prepare(string) (driver.Stmt, error)          { return nil, errors.New("not supported") }
+func (c *catalogConn) Close() error                                 { return nil }
+func (c *catalogConn) Begin() (driver.Tx, error)                    { return nil, errors.New("not supported") }
+
+func (c *catalogConn) QueryContext(_ context.Context, query string, named []driver.NamedValue) (driver.Rows, error) {
+	args := make([]driver.Value, len(named))
+	for i, arg := range named {
+		args[i] = arg.Value
+	}
+	c.mu.Lock()
+	c.queries = append(c.queries, query)
+	c.args = append(c.args, args)
+	c.mu.Unlock()
+	if strings.Contains(query, "V$VERSION") {
+		if c.versionErr != nil {
+			return nil, c.versionErr
+		}
+		return &catalogRows{values: [][]driver.Value{{"FREE", c.banner}}}, nil
+	}
+	return &catalogRows{}, nil
+}
+
+// sent returns the recorded query reading from catalog
+func (c *catalogConn) sent(catalog string) string {
+	q, _ := c.sentWith(catalog)
+	return q
+}
+
+// sentWith returns the recorded query reading from catalog and its bind
+// arguments
+func (c *catalogConn) sentWith(catalog string) (string, []driver.Value) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, q := range c.queries {
+		if strings.Contains(q, catalog) {
+			return q, c.args[i]
+		}
+	}
+	return "", nil
+}
+
+type catalogRows struct {
+	values [][]driver.Value
+}
+
+func (r *catalogRows) Columns() []string { return []string{"A", "B"} }
+func (r *catalogRows) Close() error      { return nil }
+
+func (r *catalogRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// newTestExtractor returns an extractor querying conn
+func newTestExtractor(conn *catalogConn) *Extractor {
+	return &Extractor{
+		db:      sql.OpenDB(conn),
+		log:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		release: releaseFor(0),
+	}
+}
+
+func TestReleaseMajor(t *testing.T) {
+	tests := []struct {
+		banner string
+		want   int
+	}{
+		{"Oracle Database 11g Enterprise Edition Release 11.2.0.4.0 - 64bit Production", 11},
+		{"Oracle Database 12c Enterprise Edition Release 12.1.0.2.0 - 64bit Production", 12},
+		{"Oracle Database 19c Enterprise Edition Release 19.0.0.0.0 - Production", 19},
+		{"Oracle Database 23ai Free Release 23.0.0.0.0 - Develop, Learn, and Run for Free", 23},
+		{"Oracle Database 23ai Free", 0},
+		{"Release notes", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := releaseMajor(tt.banner); got != tt.want {
+			t.Errorf("releaseMajor(%q) = %d, want %d", tt.banner, got, tt.want)
+		}
+	}
+}
+
+func TestReleaseFor(t *testing.T) {
+	for major, want := range map[int]string{0: "11g", 10: "11g", 11: "11g", 12: "12c", 18: "12c", 19: "12c", 21: "12c", 23: "23ai", 26: "23ai"} {
+		if got := releaseFor(major).name; got != want {
+			t.Errorf("releaseFor(%d) = %s, want %s", major, got, want)
+		}
+	}
+}
+
+// TestDetectRelease validates the catalog queries selected for each
+// banner: identity columns and their sequences from 12c, annotations from
+// 23ai, and the oldest queries when the banner cannot be read
+func TestDetectRelease(t *testing.T) {
+	tests := []struct {
+		name        string
+		banner      string
+		versionErr  error
+		release     string
+		identity    string
+		sequences   bool // identity sequences left out
+		annotations bool
+	}{
+		{"11.2", "Oracle Database 11g Enterprise Edition Release 11.2.0.4.0 - 64bit Production", nil, "11g", "'NO' as IDENTITY_COLUMN", false, false},
+		{"12.1", "Oracle Database 12c Enterprise Edition Release 12.1.0.2.0 - 64bit Production", nil, "12c", "c.IDENTITY_COLUMN as IDENTITY_COLUMN", true, false},
+		{"19c", "Oracle Database 19c Enterprise Edition Release 19.0.0.0.0 - Production", nil, "12c", "c.IDENTITY_COLUMN as IDENTITY_COLUMN", true, false},
+		{"23ai", "Oracle Database 23ai Free Release 23.0.0.0.0 - Develop, Learn, and Run for Free", nil, "23ai", "c.IDENTITY_COLUMN as IDENTITY_COLUMN", true, true},
+		{"unparseable", "Oracle Database", nil, "11g", "'NO' as IDENTITY_COLUMN", false, false},
+		{"unreadable", "", errors.New("ORA-00942: table or view does not exist"), "11g", "'NO' as IDENTITY_COLUMN", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			conn := &catalogConn{banner: tt.banner, versionErr: tt.versionErr}
+			e := newTestExtractor(conn)
+			defer e.Close()
+
+			e.detectRelease(ctx)
+			if e.release.name != tt.release {
+				t.Fatalf("release = %s, want %s", e.release.name, tt.release)
+			}
+
+			if _, err := e.getColumnsForTable(ctx, "HR", "EMP"); err != nil {
+				t.Fatal(err)
+			}
+			if q := conn.sent("ALL_TAB_COLUMNS"); !strings.Contains(q, tt.identity) {
+				t.Errorf("columns query lacks %s:\n%s", tt.identity, q)
+			}
+
+			if _, err := e.GetSequences(ctx); err != nil {
+				t.Fatal(err)
+			}
+			q := conn.sent("ALL_SEQUENCES")
+			if q == "" || strings.Contains(q, "ALL_TAB_IDENTITY_COLS") != tt.sequences {
+				t.Errorf("sequences query (identity sequences left out: %v):\n%s", tt.sequences, q)
+			}
+
+			if err := e.applyAnnotations(ctx, &model.Schema{}); err != nil {
+				t.Fatal(err)
+			}
+			if got := conn.sent("ALL_ANNOTATIONS_USAGE") != ""; got != tt.annotations {
+				t.Errorf("annotations read = %v, want %v", got, tt.annotations)
+			}
+		})
+	}
+}
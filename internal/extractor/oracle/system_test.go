@@ -0,0 +1,96 @@
+package oracle
+
+import (
+	"context"
+	"database/sql/driver"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestExcludeSystem validates the Oracle-maintained owners are excluded
+// from every section without a schema filter when ExcludeSystem is set,
+// and that the owners of application schemas are kept
+func TestExcludeSystem(t *testing.T) {
+	catalogs := []string{"FROM ALL_TABLES", "FROM ALL_VIEWS", "FROM ALL_PROCEDURES", "FROM ALL_SEQUENCES", "FROM ALL_TRIGGERS", "FROM ALL_SYNONYMS"}
+	tests := []struct {
+		name    string
+		filter  []string
+		exclude bool
+		want    string         // owner predicate
+		args    []driver.Value // nil: the system owners
+	}{
+		{"excluded", nil, true, "NOT IN (:1,:2,", nil},
+		{"not excluded", nil, false, "", []driver.Value{}},
+		{"schema filter", []string{"HR", "SYS"}, true, "IN (:1,:2)", []driver.Value{"HR", "SYS"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			conn := &catalogConn{}
+			e := newTestExtractor(conn)
+			defer e.Close()
+			e.config.ExcludeSystem = tt.exclude
+			e.schemaFilter = tt.filter
+
+			if _, err := e.GetTables(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := e.GetViews(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := e.GetRoutines(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := e.GetSequences(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := e.GetTriggers(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := e.GetSynonyms(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, catalog := range catalogs {
+				q, args := conn.sentWith(catalog)
+				if q == "" {
+					t.Errorf("%s not queried", catalog)
+					continue
+				}
+				if tt.want == "" {
+					if strings.Contains(q, "OWNER IN") || strings.Contains(q, "OWNER NOT IN") || len(args) > 0 {
+						t.Errorf("%s: owner predicate without exclusion or filter:\n%s", catalog, q)
+					}
+					continue
+				}
+				if !strings.Contains(q, tt.want) {
+					t.Errorf("%s: query lacks %s:\n%s", catalog, tt.want, q)
+				}
+				if tt.args != nil {
+					if !slices.Equal(args, tt.args) {
+						t.Errorf("%s: arguments = %v, want %v", catalog, args, tt.args)
+					}
+					continue
+				}
+
+				// Oracle's own schemas are excluded, APEX_* through a LIKE
+				// that escapes the underscore
+				for _, owner := range []string{"SYS", "SYSTEM", "PUBLIC", "XDB", "MDSYS", "CTXSYS", "DBSNMP", "OUTLN"} {
+					if !slices.Contains(args, driver.Value(owner)) {
+						t.Errorf("%s: %s is not excluded", catalog, owner)
+					}
+				}
+				if !strings.Contains(q, `NOT LIKE 'APEX\_%' ESCAPE '\'`) {
+					t.Errorf("%s: APEX schemas are not excluded:\n%s", catalog, q)
+				}
+				// Application schemas, including names close to system ones
+				for _, owner := range []string{"HR", "SALES", "APP", "SYSADM", "APEXAPP", "SYS_APP"} {
+					if slices.Contains(args, driver.Value(owner)) {
+						t.Errorf("%s: application schema %s is excluded", catalog, owner)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package extractor
+
+import (
+	"context"
+	"time"
+)
+
+// Object types counted by ObjectCounter, in report order
+var PlanTypes = []string{"schemas", "tables", "columns", "indexes", "views", "routines", "sequences", "triggers", "synonyms"}
+
+// ObjectCounter is implemented by extractors that can count the objects
+// an extraction reads (all built-in extractors do). CountObjects runs one
+// catalog query per type of PlanTypes it supports, with the schema filter
+// of the extraction; columns are those of tables and views.
+type ObjectCounter interface {
+	CountObjects(ctx context.Context) (map[string]int, error)
+}
+
+// fixedQueries is the number of schema-wide catalog queries of an
+// extraction (database info, tables, views, routines, sequences,
+// triggers, synonyms, annotations, dependencies, invalid objects)
+const fixedQueries = 10
+
+// Plan is the result of a pre-scan: the objects an extraction would read
+// and an estimate of the time it would take
+type Plan struct {
+	Counts   map[string]int
+	Queries  int           // estimated catalog queries of the extraction
+	PerQuery time.Duration // measured average of the counting queries
+	Estimate time.Duration
+}
+
+// PlanExtraction counts the objects of the filtered schemas and estimates
+// the extraction time. Extractors read the columns and indexes of each
+// table, the columns of each view and the arguments of each routine with
+// queries of their own, so the estimate is the number of such queries
+// times the average duration of the counting queries. ok is false when
+// the extractor cannot count objects.
+func PlanExtraction(ctx context.Context, ext DBExtractor) (plan *Plan, ok bool, err error) {
+	counter, ok := ext.(ObjectCounter)
+	if !ok {
+		return nil, false, nil
+	}
+	start := time.Now()
+	counts, err := counter.CountObjects(ctx)
+	if err != nil {
+		return nil, true, err
+	}
+	return newPlan(counts, time.Since(start)), true, nil
+}
+
+// newPlan estimates the extraction time from counts, which took elapsed
+// to read
+func newPlan(counts map[string]int, elapsed time.Duration) *Plan {
+	p := &Plan{Counts: counts}
+	if len(counts) > 0 {
+		p.PerQuery = elapsed / time.Duration(len(counts))
+	}
+	p.Queries = fixedQueries + 2*counts["tables"] + counts["indexes"] + counts["views"] + counts["routines"]
+	p.Estimate = time.Duration(p.Queries) * p.PerQuery
+	return p
+}
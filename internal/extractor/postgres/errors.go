@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"errors"
+	"pocket-doc/internal/dbquery"
+
+	"github.com/lib/pq"
+)
+
+// queryOptions bounds, classifies and reports the catalog queries
+func (e *Extractor) queryOptions() dbquery.Options {
+	return dbquery.Options{Timeout: e.config.QueryTimeout, Classify: e.classify, Hooks: e.config.Hooks}
+}
+
+// classify maps the SQLSTATE of a failed catalog query to typed errors:
+// insufficient_privilege, or an undefined catalog, column or function of
+// an older server
+func (e *Extractor) classify(query string, err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch pqErr.Code {
+	case "42501":
+		return &dbquery.PermissionDeniedError{DBType: "postgresql", Catalog: dbquery.CatalogOf(query, err, requiredCatalogs), User: e.config.Username, Err: err}
+	case "42P01", "42703", "42883":
+		return &dbquery.UnsupportedVersionError{DBType: "postgresql", Catalog: dbquery.CatalogOf(query, err, requiredCatalogs), Err: err}
+	}
+	return err
+}
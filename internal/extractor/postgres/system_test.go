@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestExtensionCondition validates objects created by extensions are
+// excluded when ExcludeSystem is set, while the objects of user schemas
+// (without an extension dependency) are kept
+func TestExtensionCondition(t *testing.T) {
+	e := &Extractor{config: Config{ExcludeSystem: true}}
+	tests := []struct {
+		catalog, oid string
+		want         string
+	}{
+		{"pg_class", "c.oid", "ext.classid = 'pg_class'::regclass AND ext.objid = c.oid AND ext.deptype = 'e'"},
+		{"pg_proc", "p.oid", "ext.classid = 'pg_proc'::regclass AND ext.objid = p.oid AND ext.deptype = 'e'"},
+	}
+	for _, tt := range tests {
+		got := e.extensionCondition(tt.catalog, tt.oid)
+		if !strings.HasPrefix(got, " AND NOT EXISTS (") || !strings.Contains(got, tt.want) {
+			t.Errorf("extensionCondition(%s, %s) =\n%s", tt.catalog, tt.oid, got)
+		}
+	}
+
+	e = &Extractor{}
+	if got := e.extensionCondition("pg_class", "c.oid"); got != "" {
+		t.Errorf("condition without ExcludeSystem = %q", got)
+	}
+}
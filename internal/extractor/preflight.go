@@ -0,0 +1,122 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"pocket-doc/internal/dbquery"
+)
+
+// Sections are the optional parts of a schema, in extraction order. An
+// extraction without access to the catalogs of a section leaves it out
+// instead of failing; tables and their columns, constraints and indexes
+// are always required.
+var Sections = []string{"views", "routines", "sequences", "triggers", "synonyms", "dependencies"}
+
+// SectionSkipper is implemented by extractors that can leave out sections
+// (all built-in database extractors do)
+type SectionSkipper interface {
+	CatalogProber
+
+	// SectionCatalogs returns the catalogs each section reads besides
+	// those of tables; sections the database lacks are absent
+	SectionCatalogs() map[string][]string
+
+	// SkipSections makes ExtractSchema leave out sections
+	SkipSections(sections []string)
+}
+
+// VersionChecker is implemented by extractors that know the oldest server
+// version they support (all built-in database extractors do)
+type VersionChecker interface {
+	// CheckVersion returns the server version, and a
+	// *dbquery.UnsupportedVersionError when it is too old
+	CheckVersion(ctx context.Context) (version string, err error)
+}
+
+// Capability is the pre-flight result of one section
+type Capability struct {
+	Section string
+	Missing []CatalogCheck // unreadable catalogs; the section is skipped when any
+}
+
+// Preflight is the result of checking an extractor's access before
+// extraction
+type Preflight struct {
+	Version  string
+	Catalogs []CatalogCheck
+	Sections []Capability // in Sections order, for the sections the database has
+}
+
+// Skipped returns the sections left out of the extraction
+func (p *Preflight) Skipped() []string {
+	var skipped []string
+	for _, c := range p.Sections {
+		if len(c.Missing) > 0 {
+			skipped = append(skipped, c.Section)
+		}
+	}
+	return skipped
+}
+
+// RunPreflight checks the server version and probes every catalog the
+// extractor reads, then makes it skip the sections with unreadable
+// catalogs. It fails on a server that is too old, an unreadable catalog of
+// tables, or a probe failing for another reason than access (a timeout or
+// lost connection). ok is false when the extractor does not support
+// probing.
+func RunPreflight(ctx context.Context, ext DBExtractor) (p *Preflight, ok bool, err error) {
+	prober, ok := ext.(CatalogProber)
+	if !ok {
+		return nil, false, nil
+	}
+	p = &Preflight{}
+	if checker, ok := ext.(VersionChecker); ok {
+		if p.Version, err = checker.CheckVersion(ctx); err != nil {
+			return p, true, err
+		}
+	}
+
+	unreadable := map[string]CatalogCheck{}
+	for _, catalog := range prober.RequiredCatalogs() {
+		check := CatalogCheck{Catalog: catalog, Err: prober.ProbeCatalog(ctx, catalog)}
+		p.Catalogs = append(p.Catalogs, check)
+		if check.Err == nil {
+			continue
+		}
+		var denied *dbquery.PermissionDeniedError
+		var unsupported *dbquery.UnsupportedVersionError
+		if !errors.As(check.Err, &denied) && !errors.As(check.Err, &unsupported) {
+			return p, true, check.Err
+		}
+		unreadable[catalog] = check
+	}
+
+	skipper, ok := ext.(SectionSkipper)
+	optional := map[string]bool{}
+	if ok {
+		sectionCatalogs := skipper.SectionCatalogs()
+		for _, section := range Sections {
+			catalogs, has := sectionCatalogs[section]
+			if !has {
+				continue
+			}
+			c := Capability{Section: section}
+			for _, catalog := range catalogs {
+				optional[catalog] = true
+				if check, ok := unreadable[catalog]; ok {
+					c.Missing = append(c.Missing, check)
+				}
+			}
+			p.Sections = append(p.Sections, c)
+		}
+	}
+	for _, check := range p.Catalogs {
+		if _, bad := unreadable[check.Catalog]; bad && !optional[check.Catalog] {
+			return p, true, check.Err
+		}
+	}
+	if skipper != nil {
+		skipper.SkipSections(p.Skipped())
+	}
+	return p, true, nil
+}
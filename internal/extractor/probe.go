@@ -0,0 +1,38 @@
+package extractor
+
+import "context"
+
+// CatalogProber is implemented by extractors that can check catalog read
+// access before extraction (all built-in extractors do)
+type CatalogProber interface {
+	RequiredCatalogs() []string
+	ProbeCatalog(ctx context.Context, catalog string) error
+}
+
+// CatalogCheck is the result of probing one catalog view
+type CatalogCheck struct {
+	Catalog string
+	Err     error // nil when readable
+}
+
+// ProbeCatalogs checks every catalog the extractor reads. ok is false when
+// the extractor does not support probing.
+func ProbeCatalogs(ctx context.Context, ext DBExtractor) (checks []CatalogCheck, ok bool) {
+	prober, ok := ext.(CatalogProber)
+	if !ok {
+		return nil, false
+	}
+	for _, catalog := range prober.RequiredCatalogs() {
+		checks = append(checks, CatalogCheck{Catalog: catalog, Err: prober.ProbeCatalog(ctx, catalog)})
+	}
+	return checks, true
+}
+
+// IsSupported reports whether dbType (or one of its aliases) is registered
+func IsSupported(dbType string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	_, ok := registry[normalizeType(dbType)]
+	return ok
+}
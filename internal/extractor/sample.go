@@ -0,0 +1,127 @@
+package extractor
+
+import (
+	"context"
+	"pocket-doc/internal/model"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValueSampler is implemented by extractors that can read example values
+// of a column (all built-in extractors do). SampleValues returns up to
+// limit non-null values, converted to text.
+type ValueSampler interface {
+	SampleValues(ctx context.Context, owner, table, column string, limit int) ([]string, error)
+}
+
+// SampleOptions control SampleColumns
+type SampleOptions struct {
+	MaxValues int  // distinct values kept per column
+	MaxLength int  // characters kept per value
+	Mask      bool // keep the first two characters and star the rest
+	// Skip reports columns that must not be sampled, e.g. by classification
+	Skip   func(t *model.Table, c *model.Column) bool
+	Logger *slog.Logger // debug-level per-column failures (nil = slog.Default())
+}
+
+// unsampledTypes are data types whose values are not useful as examples
+// or expensive to read
+var unsampledTypes = []string{"LOB", "BLOB", "CLOB", "LONG", "RAW", "BINARY", "BYTEA", "IMAGE", "XML", "JSON", "GEOMETRY", "GEOGRAPHY"}
+
+// SampleColumns sets Examples on the table columns of schema. Columns that
+// cannot be read (e.g. for lack of SELECT privilege) are skipped. It
+// returns the number of sampled columns; ok is false when the extractor
+// does not support sampling.
+func SampleColumns(ctx context.Context, ext DBExtractor, schema *model.Schema, opts SampleOptions) (sampled int, ok bool, err error) {
+	sampler, ok := ext.(ValueSampler)
+	if !ok {
+		return 0, false, nil
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		for j := range t.Columns {
+			if err := ctx.Err(); err != nil {
+				return sampled, true, err
+			}
+			c := &t.Columns[j]
+			c.Examples = nil
+			if !sampleable(c.DataType) || (opts.Skip != nil && opts.Skip(t, c)) {
+				continue
+			}
+
+			// Read extra rows so that repeated values still fill MaxValues
+			values, err := sampler.SampleValues(ctx, t.Owner, t.Name, c.Name, opts.MaxValues*4)
+			if err != nil {
+				logger.Debug("sampling failed", "table", t.Name, "column", c.Name, "error", err)
+				continue
+			}
+			c.Examples = examples(values, opts)
+			if len(c.Examples) > 0 {
+				sampled++
+			}
+		}
+	}
+	return sampled, true, nil
+}
+
+// sampleable reports whether values of dataType are sampled
+func sampleable(dataType string) bool {
+	upper := strings.ToUpper(dataType)
+	for _, t := range unsampledTypes {
+		if strings.Contains(upper, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// examples masks or truncates values and keeps the first MaxValues
+// distinct results
+func examples(values []string, opts SampleOptions) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if opts.Mask {
+			v = mask(v)
+		}
+		v = truncate(v, opts.MaxLength)
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+		if len(out) == opts.MaxValues {
+			break
+		}
+	}
+	return out
+}
+
+// mask keeps the first two characters of s (one for short values) and
+// replaces the rest with stars
+func mask(s string) string {
+	runes := []rune(s)
+	keep := 2
+	if len(runes) <= 3 {
+		keep = 1
+	}
+	return string(runes[:keep]) + strings.Repeat("*", len(runes)-keep)
+}
+
+// truncate cuts s to max characters, marking the cut with an ellipsis
+func truncate(s string, max int) string {
+	if max <= 0 || utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max]) + "…"
+}
@@ -0,0 +1,118 @@
+// Package fonts selects the fonts of the document formats: the font runs
+// of Word styles and the font-family stack of HTML pages (and a PDF
+// exporter). Korean, Japanese and Chinese text need an East Asian font the
+// reader has installed, so the defaults follow the document language and
+// HTML pages fall back to fonts shipped with macOS and Linux.
+package fonts
+
+import "strings"
+
+// Config names the fonts of a document; empty fields take the defaults of
+// the document language (see Resolve)
+type Config struct {
+	Latin     string   // Latin script text
+	EastAsian string   // Hangul, kana and CJK ideographs
+	Fallback  []string // CSS families tried after them, ending in a generic family
+}
+
+// defaults are the fonts of each language tag (lower-cased) or base
+// language; other languages use Korean fonts, which also cover Latin text
+// (CRITICAL RULE #3)
+var defaults = map[string]Config{
+	"ko": {
+		EastAsian: "Malgun Gothic",
+		Fallback:  []string{"Apple SD Gothic Neo", "Noto Sans KR", "-apple-system", "BlinkMacSystemFont", "Segoe UI", "Arial", "sans-serif"},
+	},
+	"ja": {
+		EastAsian: "Yu Gothic",
+		Fallback:  []string{"Meiryo", "Hiragino Sans", "Noto Sans JP", "-apple-system", "BlinkMacSystemFont", "Segoe UI", "Arial", "sans-serif"},
+	},
+	"zh": {
+		EastAsian: "Microsoft YaHei",
+		Fallback:  []string{"PingFang SC", "Noto Sans SC", "-apple-system", "BlinkMacSystemFont", "Segoe UI", "Arial", "sans-serif"},
+	},
+	"zh-tw": {
+		EastAsian: "Microsoft JhengHei",
+		Fallback:  []string{"PingFang TC", "Noto Sans TC", "-apple-system", "BlinkMacSystemFont", "Segoe UI", "Arial", "sans-serif"},
+	},
+}
+
+// Resolve returns c with the defaults of language filled in. The Latin
+// font defaults to the East Asian one, as East Asian fonts include Latin
+// glyphs and mixed text then renders in a single typeface.
+func (c Config) Resolve(language string) Config {
+	language = strings.ReplaceAll(strings.ToLower(language), "_", "-")
+	d, ok := defaults[language]
+	if !ok {
+		base, _, _ := strings.Cut(language, "-")
+		if d, ok = defaults[base]; !ok {
+			d = defaults["ko"]
+		}
+	}
+	if c.EastAsian == "" {
+		c.EastAsian = d.EastAsian
+	}
+	if c.Latin == "" {
+		c.Latin = c.EastAsian
+	}
+	if len(c.Fallback) == 0 {
+		c.Fallback = d.Fallback
+	}
+	return c
+}
+
+// Families returns the font stack of a resolved configuration: the East
+// Asian font first, then the Latin font and the fallbacks, each once
+func (c Config) Families() []string {
+	var families []string
+	for _, name := range append([]string{c.EastAsian, c.Latin}, c.Fallback...) {
+		name = Clean(name)
+		if name == "" || contains(families, name) {
+			continue
+		}
+		families = append(families, name)
+	}
+	return families
+}
+
+// CSS returns the font stack as a CSS font-family value; family names are
+// quoted, generic families and system keywords are not
+func (c Config) CSS() string {
+	families := c.Families()
+	for i, name := range families {
+		if !generic[strings.ToLower(name)] {
+			families[i] = "'" + name + "'"
+		}
+	}
+	return strings.Join(families, ", ")
+}
+
+// generic are the CSS generic families and system font keywords
+var generic = map[string]bool{
+	"serif": true, "sans-serif": true, "monospace": true, "cursive": true, "fantasy": true,
+	"system-ui": true, "ui-sans-serif": true, "ui-serif": true, "ui-monospace": true,
+	"-apple-system": true, "blinkmacsystemfont": true,
+}
+
+// Clean returns a font name without the characters that could end a CSS
+// string or an XML attribute (quotes, backslashes, semicolons, braces,
+// angle brackets, control characters), trimmed
+func Clean(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r < ' ', r == 0x7f, strings.ContainsRune(`'"\;{}<>`, r):
+			return -1
+		}
+		return r
+	}, name)
+	return strings.TrimSpace(name)
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+// Package gitpub publishes documents to a git repository for docs-as-code
+// workflows: the files are copied into a working copy, committed when they
+// changed and optionally pushed. It runs the git command line, so the
+// credentials and signing settings of the host apply.
+package gitpub
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Config selects the working copy and what happens to it
+type Config struct {
+	Repo   string // working copy
+	Path   string // directory in the working copy (empty = its root)
+	Push   bool
+	Remote string // default origin
+	Branch string // remote branch (default the current branch)
+	Author string // "Name <email>" (default the git configuration)
+}
+
+// Result describes a publication
+type Result struct {
+	Files  []string // paths in the working copy, relative to its root
+	Commit string   // hash of the new commit, empty when nothing changed
+	Pushed bool
+}
+
+// Publish copies files into the working copy and commits them with message
+func Publish(ctx context.Context, cfg Config, files []string, message string) (*Result, error) {
+	if _, err := git(ctx, cfg.Repo, "rev-parse", "--show-toplevel"); err != nil {
+		return nil, fmt.Errorf("git: %s is not a git working copy: %w", cfg.Repo, err)
+	}
+	dir := filepath.Join(cfg.Repo, filepath.FromSlash(cfg.Path))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("git: %w", err)
+	}
+
+	r := &Result{}
+	for _, file := range files {
+		rel := filepath.ToSlash(filepath.Join(cfg.Path, filepath.Base(file)))
+		if err := copyFile(file, filepath.Join(cfg.Repo, filepath.FromSlash(rel))); err != nil {
+			return nil, fmt.Errorf("git: %w", err)
+		}
+		r.Files = append(r.Files, rel)
+	}
+	if _, err := git(ctx, cfg.Repo, append([]string{"add", "--"}, r.Files...)...); err != nil {
+		return nil, err
+	}
+	// Nothing staged: the documents did not change since the last commit
+	if _, err := git(ctx, cfg.Repo, append([]string{"diff", "--cached", "--quiet", "--"}, r.Files...)...); err == nil {
+		return r, nil
+	}
+
+	var args []string
+	if cfg.Author != "" {
+		a, err := mail.ParseAddress(cfg.Author)
+		if err != nil {
+			return nil, fmt.Errorf("git: invalid author %s", cfg.Author)
+		}
+		args = append(args, "-c", "user.name="+a.Name, "-c", "user.email="+a.Address)
+	}
+	args = append(args, "commit", "--quiet", "-m", message, "--")
+	if _, err := git(ctx, cfg.Repo, append(args, r.Files...)...); err != nil {
+		return nil, err
+	}
+	hash, err := git(ctx, cfg.Repo, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	r.Commit = hash
+
+	if cfg.Push {
+		remote := cfg.Remote
+		if remote == "" {
+			remote = "origin"
+		}
+		ref := "HEAD"
+		if cfg.Branch != "" {
+			ref = "HEAD:" + cfg.Branch
+		}
+		if _, err := git(ctx, cfg.Repo, "push", "--quiet", remote, ref); err != nil {
+			return r, err
+		}
+		r.Pushed = true
+	}
+	return r, nil
+}
+
+// git runs a git command in repo and returns its trimmed output; errors
+// carry what git printed to stderr
+func git(ctx context.Context, repo string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", repo}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	// Never wait for a password prompt
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if err := cmd.Run(); err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) && stderr.Len() > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
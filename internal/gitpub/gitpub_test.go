@@ -0,0 +1,62 @@
+package gitpub
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"pocket-doc/internal/notify"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGitPublish tests committing and pushing documents to a repository
+func TestGitPublish(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	work := filepath.Join(dir, "work")
+	for _, args := range [][]string{{"init", "--quiet", "--bare", remote}, {"init", "--quiet", work}} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if out, err := exec.Command("git", "-C", work, "remote", "add", "origin", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git remote add: %v\n%s", err, out)
+	}
+
+	doc := filepath.Join(dir, "shop.html")
+	os.WriteFile(doc, []byte("<html>v1</html>"), 0644)
+	cfg := Config{Repo: work, Path: "docs/db", Push: true, Branch: "docs", Author: "Docs Bot <docs@example.com>"}
+	run := &notify.Run{StartedAt: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Databases: []notify.Database{{DatabaseName: "shop"}}}
+	message := notify.Expand("Update {db} documentation ({date})", run)
+
+	res, err := Publish(context.Background(), cfg, []string{doc}, message)
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if res.Commit == "" || !res.Pushed || !slices.Equal(res.Files, []string{"docs/db/shop.html"}) {
+		t.Errorf("Result = %+v", res)
+	}
+	out, err := exec.Command("git", "--git-dir", remote, "log", "-1", "--format=%s|%an|%ae", "docs").Output()
+	if err != nil {
+		t.Fatalf("git log: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "Update shop documentation (2026-03-02)|Docs Bot|docs@example.com" {
+		t.Errorf("Pushed commit = %q", got)
+	}
+
+	// Unchanged documents make no commit
+	res, err = Publish(context.Background(), cfg, []string{doc}, message)
+	if err != nil || res.Commit != "" {
+		t.Errorf("Second publish = %+v, %v", res, err)
+	}
+
+	if _, err := Publish(context.Background(), Config{Repo: dir}, []string{doc}, message); err == nil {
+		t.Error("Expected an error for a directory that is not a working copy")
+	}
+}
@@ -0,0 +1,107 @@
+package glossary
+
+import (
+	"pocket-doc/internal/model"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Glossary maps business terms to definitions and links them to schema objects.
+//
+// File format (YAML):
+//
+//	terms:
+//	  사원번호: "사원을 식별하는 고유 번호"
+//	  Customer: "A party that purchases goods or services"
+//	tables:
+//	  HR.사원: [사원]            # [owner.]table
+//	columns:
+//	  HR.사원.사원번호: [사원번호] # [owner.]table.column
+//
+// Object names are matched case-insensitively; the owner may be omitted to
+// match the table in any schema.
+type Glossary struct {
+	Terms   map[string]string   `yaml:"terms"`
+	Tables  map[string][]string `yaml:"tables"`
+	Columns map[string][]string `yaml:"columns"`
+}
+
+// Load reads and validates a glossary file
+func Load(path string) (*Glossary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read glossary file: %w", err)
+	}
+
+	var g Glossary
+	if err := yaml.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("failed to parse glossary file: %w", err)
+	}
+
+	if err := g.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid glossary file %s: %w", path, err)
+	}
+	return &g, nil
+}
+
+// Validate checks that every mapping refers to a defined term
+func (g *Glossary) Validate() error {
+	for _, mappings := range []map[string][]string{g.Tables, g.Columns} {
+		for object, terms := range mappings {
+			for _, term := range terms {
+				if _, ok := g.Terms[term]; !ok {
+					return fmt.Errorf("%s: undefined term %q", object, term)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Apply links terms to the tables, views and columns of schema and sets
+// schema.Glossary to the full term list, sorted by term
+func (g *Glossary) Apply(schema *model.Schema) {
+	schema.Glossary = make([]model.GlossaryTerm, 0, len(g.Terms))
+	for term, definition := range g.Terms {
+		schema.Glossary = append(schema.Glossary, model.GlossaryTerm{Term: term, Definition: definition})
+	}
+	sort.Slice(schema.Glossary, func(i, j int) bool {
+		return schema.Glossary[i].Term < schema.Glossary[j].Term
+	})
+
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		t.Terms = g.lookup(g.Tables, t.Owner, t.Name)
+		for j := range t.Columns {
+			t.Columns[j].Terms = g.lookup(g.Columns, t.Owner, t.Name+"."+t.Columns[j].Name)
+		}
+	}
+	for i := range schema.Views {
+		v := &schema.Views[i]
+		v.Terms = g.lookup(g.Tables, v.Owner, v.Name)
+		for j := range v.Columns {
+			v.Columns[j].Terms = g.lookup(g.Columns, v.Owner, v.Name+"."+v.Columns[j].Name)
+		}
+	}
+}
+
+// lookup returns the terms mapped to "owner.name" or, failing that, "name"
+func (g *Glossary) lookup(mappings map[string][]string, owner, name string) []string {
+	var qualified, bare []string
+	for key, terms := range mappings {
+		switch {
+		case owner != "" && strings.EqualFold(key, owner+"."+name):
+			qualified = terms
+		case strings.EqualFold(key, name):
+			bare = terms
+		}
+	}
+	if qualified != nil {
+		return qualified
+	}
+	return bare
+}
@@ -0,0 +1,174 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Built-in message catalogs, one JSON object (key -> message) per language
+//
+//go:embed locales/*.json
+var localeFS embed.FS
+
+const (
+	// DefaultLanguage is used when no language is configured
+	DefaultLanguage = "ko"
+
+	// FallbackLanguage supplies messages missing from the selected catalog
+	FallbackLanguage = "en"
+)
+
+var (
+	mu       sync.RWMutex
+	catalogs = make(map[string]map[string]string)
+)
+
+func init() {
+	entries, err := localeFS.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: failed to read embedded locales: %v", err))
+	}
+	for _, entry := range entries {
+		data, err := localeFS.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: failed to read %s: %v", entry.Name(), err))
+		}
+		if err := loadJSON(strings.TrimSuffix(entry.Name(), ".json"), data); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Register adds messages for a language, overriding existing keys.
+// Use it to add a new language or to customize built-in wording.
+func Register(lang string, messages map[string]string) {
+	lang = normalize(lang)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// Copy-on-write so Bundles created earlier keep a consistent view
+	merged := make(map[string]string, len(catalogs[lang])+len(messages))
+	for k, v := range catalogs[lang] {
+		merged[k] = v
+	}
+	for k, v := range messages {
+		merged[k] = v
+	}
+	catalogs[lang] = merged
+}
+
+// LoadFile registers a JSON catalog file; the language is taken from the
+// file name (e.g. "pt-BR.json")
+func LoadFile(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read locale file: %w", err)
+	}
+	return loadJSON(strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)), data)
+}
+
+// LoadDir registers every *.json catalog in dir
+func LoadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("failed to list locale directory: %w", err)
+	}
+	for _, file := range files {
+		if err := LoadFile(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Languages returns the available language codes, sorted
+func Languages() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// loadJSON parses a catalog and registers it under lang
+func loadJSON(lang string, data []byte) error {
+	var messages map[string]string
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return fmt.Errorf("failed to parse locale %s: %w", lang, err)
+	}
+	Register(lang, messages)
+	return nil
+}
+
+// normalize lower-cases a language tag and uses '-' as separator ("zh_CN" -> "zh-cn")
+func normalize(lang string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(lang)), "_", "-")
+}
+
+// Bundle translates message keys for one language
+type Bundle struct {
+	lang  string
+	chain []map[string]string
+}
+
+// New returns a Bundle for lang. Lookups try the exact language, then its
+// base language ("zh-CN" -> "zh"), then FallbackLanguage. An empty lang
+// selects DefaultLanguage.
+func New(lang string) *Bundle {
+	lang = normalize(lang)
+	if lang == "" {
+		lang = DefaultLanguage
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	b := &Bundle{lang: lang}
+	seen := make(map[string]bool)
+	candidates := []string{lang}
+	if base, _, ok := strings.Cut(lang, "-"); ok {
+		candidates = append(candidates, base)
+	}
+	candidates = append(candidates, FallbackLanguage)
+
+	for _, c := range candidates {
+		if messages, ok := catalogs[c]; ok && !seen[c] {
+			seen[c] = true
+			b.chain = append(b.chain, messages)
+		}
+	}
+	return b
+}
+
+// Language returns the language tag the bundle was created for
+func (b *Bundle) Language() string {
+	return b.lang
+}
+
+// T returns the message for key, formatted with args when given.
+// Unknown keys are returned as-is so missing translations stay visible.
+func (b *Bundle) T(key string, args ...interface{}) string {
+	msg := key
+	for _, messages := range b.chain {
+		if m, ok := messages[key]; ok {
+			msg = m
+			break
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
@@ -0,0 +1,75 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadOverrides applies a YAML label override file to lang.
+//
+// Each entry maps either a message key or the label text as currently
+// rendered (in lang or English) to the replacement text:
+//
+//	label.comment: "비고"
+//	Tables: "엔터티 목록"
+//
+// Text matches are case-insensitive and replace every key showing that text.
+func LoadOverrides(file, lang string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read label file: %w", err)
+	}
+
+	var labels map[string]string
+	if err := yaml.Unmarshal(data, &labels); err != nil {
+		return fmt.Errorf("failed to parse label file: %w", err)
+	}
+
+	messages, err := resolveOverrides(labels, lang)
+	if err != nil {
+		return fmt.Errorf("invalid label file %s: %w", file, err)
+	}
+	if lang == "" {
+		lang = DefaultLanguage
+	}
+	Register(lang, messages)
+	return nil
+}
+
+// resolveOverrides maps override entries onto message keys
+func resolveOverrides(labels map[string]string, lang string) (map[string]string, error) {
+	b := New(lang)
+	en := New(FallbackLanguage)
+
+	mu.RLock()
+	keys := make(map[string]bool)
+	for _, messages := range catalogs {
+		for k := range messages {
+			keys[k] = true
+		}
+	}
+	mu.RUnlock()
+
+	messages := make(map[string]string, len(labels))
+	for label, text := range labels {
+		if keys[label] {
+			messages[label] = text
+			continue
+		}
+
+		matched := false
+		for k := range keys {
+			if strings.EqualFold(b.T(k), label) || strings.EqualFold(en.T(k), label) {
+				messages[k] = text
+				matched = true
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("unknown label %q", label)
+		}
+	}
+	return messages, nil
+}
@@ -0,0 +1,82 @@
+// Package impact arranges the catalog dependencies of views on tables
+// (model.Table.ReferencedBy) as a matrix of views by base tables, so the
+// views affected by a change to a table can be read off its column. The
+// matrix forms the impact section of the documents
+// (output.include_impact_matrix).
+package impact
+
+import (
+	"pocket-doc/internal/model"
+	"sort"
+)
+
+// Matrix holds the views using at least one documented table (rows) and
+// the tables used by at least one view (columns)
+type Matrix struct {
+	Tables []Column `json:"tables"`
+	Rows   []Row    `json:"rows"`
+}
+
+// Column is a base table with the number of views using it
+type Column struct {
+	Table model.ObjectRef `json:"table"`
+	Views int             `json:"views"`
+}
+
+// Row is a view and, per column of the matrix, whether it uses the table
+type Row struct {
+	View model.ObjectRef `json:"view"`
+	Uses []bool          `json:"uses"`
+}
+
+// Build returns the matrix of the views and materialized views referencing
+// the tables of schema. Tables keep the order of the schema, views are
+// ordered by owner and name.
+func Build(schema *model.Schema) *Matrix {
+	m := &Matrix{}
+	rows := make(map[model.ObjectRef]*Row)
+	var views []model.ObjectRef
+	for _, t := range schema.Tables {
+		col := -1
+		for _, ref := range t.ReferencedBy {
+			if ref.Type != "VIEW" && ref.Type != "MATERIALIZED VIEW" {
+				continue
+			}
+			if col < 0 {
+				col = len(m.Tables)
+				m.Tables = append(m.Tables, Column{Table: model.ObjectRef{Type: "TABLE", Owner: t.Owner, Name: t.Name}})
+			}
+			m.Tables[col].Views++
+			r, ok := rows[ref]
+			if !ok {
+				r = &Row{View: ref}
+				rows[ref] = r
+				views = append(views, ref)
+			}
+			for len(r.Uses) <= col {
+				r.Uses = append(r.Uses, false)
+			}
+			r.Uses[col] = true
+		}
+	}
+
+	sort.Slice(views, func(i, j int) bool {
+		if views[i].Owner != views[j].Owner {
+			return views[i].Owner < views[j].Owner
+		}
+		return views[i].Name < views[j].Name
+	})
+	for _, v := range views {
+		r := rows[v]
+		for len(r.Uses) < len(m.Tables) {
+			r.Uses = append(r.Uses, false)
+		}
+		m.Rows = append(m.Rows, *r)
+	}
+	return m
+}
+
+// Empty reports whether no view uses a documented table
+func (m *Matrix) Empty() bool {
+	return len(m.Rows) == 0
+}
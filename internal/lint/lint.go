@@ -0,0 +1,280 @@
+package lint
+
+import (
+	"pocket-doc/internal/model"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Rule IDs, usable as keys of Config.Severity
+const (
+	RuleTablePrefix  = "table-prefix"  // table name lacks an allowed prefix
+	RuleViewPrefix   = "view-prefix"   // view name lacks an allowed prefix
+	RuleNameCase     = "name-case"     // table/column name not in the configured case
+	RuleNameLength   = "name-length"   // identifier longer than MaxNameLength
+	RuleMissingPK    = "missing-pk"    // table without a primary key
+	RuleFKIndex      = "fk-index"      // FK column not leading any index
+	RuleNullableFK   = "nullable-fk"   // FK column allows NULL
+	RuleReservedWord = "reserved-word" // identifier is an SQL reserved word
+)
+
+// Severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityOff     = "off"
+)
+
+// defaultSeverity applies to rules not listed in Config.Severity
+var defaultSeverity = map[string]string{
+	RuleTablePrefix:  SeverityWarning,
+	RuleViewPrefix:   SeverityWarning,
+	RuleNameCase:     SeverityWarning,
+	RuleNameLength:   SeverityWarning,
+	RuleMissingPK:    SeverityError,
+	RuleFKIndex:      SeverityWarning,
+	RuleNullableFK:   SeverityWarning,
+	RuleReservedWord: SeverityError,
+}
+
+// Config selects and parameterizes lint rules. Naming rules only run when
+// their parameter is set; structural rules (missing-pk, fk-index,
+// nullable-fk, reserved-word) run unless their severity is "off".
+type Config struct {
+	TablePrefixes []string          // e.g. ["TB_", "T_"]
+	ViewPrefixes  []string          // e.g. ["V_", "VW_"]
+	NameCase      string            // "upper", "lower" or "" (any)
+	MaxNameLength int               // 0 = no limit
+	ReservedWords []string          // extra words for reserved-word
+	Severity      map[string]string // rule ID -> error, warning, off
+}
+
+// Finding is a single rule violation
+type Finding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Object   string `json:"object"` // owner.table[.column]
+	Message  string `json:"message"`
+}
+
+// Result holds the findings of a lint run, ordered by object then rule
+type Result struct {
+	Findings []Finding `json:"findings"`
+}
+
+// Errors returns the number of error-severity findings
+func (r *Result) Errors() int {
+	return r.count(SeverityError)
+}
+
+// Warnings returns the number of warning-severity findings
+func (r *Result) Warnings() int {
+	return r.count(SeverityWarning)
+}
+
+func (r *Result) count(severity string) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Validate checks rule IDs, severities and the name case
+func (c Config) Validate() error {
+	for rule, severity := range c.Severity {
+		if _, ok := defaultSeverity[rule]; !ok {
+			return fmt.Errorf("unknown lint rule: %s", rule)
+		}
+		switch severity {
+		case SeverityError, SeverityWarning, SeverityOff:
+		default:
+			return fmt.Errorf("invalid severity for %s: %s (use error, warning or off)", rule, severity)
+		}
+	}
+	switch strings.ToLower(c.NameCase) {
+	case "", "upper", "lower":
+	default:
+		return fmt.Errorf("invalid name case: %s (use upper or lower)", c.NameCase)
+	}
+	return nil
+}
+
+// Run checks the tables and views of schema against the configured rules
+func Run(schema *model.Schema, cfg Config) *Result {
+	l := &linter{cfg: cfg, reserved: make(map[string]bool)}
+	for _, w := range reservedWords {
+		l.reserved[w] = true
+	}
+	for _, w := range cfg.ReservedWords {
+		l.reserved[strings.ToUpper(w)] = true
+	}
+
+	for _, table := range schema.Tables {
+		l.checkTable(schema, table)
+	}
+	for _, view := range schema.Views {
+		obj := objectPath(view.Owner, view.Name)
+		if len(cfg.ViewPrefixes) > 0 && !hasPrefix(view.Name, cfg.ViewPrefixes) {
+			l.report(RuleViewPrefix, obj, "view name should start with one of %s", strings.Join(cfg.ViewPrefixes, ", "))
+		}
+		l.checkName(obj, view.Name)
+	}
+
+	sort.SliceStable(l.result.Findings, func(i, j int) bool {
+		a, b := l.result.Findings[i], l.result.Findings[j]
+		if a.Object != b.Object {
+			return a.Object < b.Object
+		}
+		return a.Rule < b.Rule
+	})
+	return &l.result
+}
+
+// linter accumulates findings for one run
+type linter struct {
+	cfg      Config
+	reserved map[string]bool
+	result   Result
+}
+
+func (l *linter) checkTable(schema *model.Schema, table model.Table) {
+	obj := objectPath(table.Owner, table.Name)
+
+	if len(l.cfg.TablePrefixes) > 0 && !hasPrefix(table.Name, l.cfg.TablePrefixes) {
+		l.report(RuleTablePrefix, obj, "table name should start with one of %s", strings.Join(l.cfg.TablePrefixes, ", "))
+	}
+	l.checkName(obj, table.Name)
+
+	hasPK := false
+	leading := leadingIndexColumns(schema, table)
+	for _, col := range table.Columns {
+		colObj := obj + "." + col.Name
+		l.checkName(colObj, col.Name)
+
+		if col.IsPrimaryKey {
+			hasPK = true
+		}
+		if col.IsForeignKey {
+			if !leading[strings.ToUpper(col.Name)] {
+				l.report(RuleFKIndex, colObj, "foreign key column is not the leading column of any index")
+			}
+			if col.Nullable {
+				l.report(RuleNullableFK, colObj, "foreign key column allows NULL")
+			}
+		}
+	}
+
+	if !hasPK {
+		for _, idx := range table.Indexes {
+			if idx.IsPrimary {
+				hasPK = true
+			}
+		}
+	}
+	if !hasPK {
+		l.report(RuleMissingPK, obj, "table has no primary key")
+	}
+}
+
+// checkName applies the case, length and reserved-word rules to an identifier
+func (l *linter) checkName(obj, name string) {
+	switch strings.ToLower(l.cfg.NameCase) {
+	case "upper":
+		if name != strings.ToUpper(name) {
+			l.report(RuleNameCase, obj, "name %q should be upper case", name)
+		}
+	case "lower":
+		if name != strings.ToLower(name) {
+			l.report(RuleNameCase, obj, "name %q should be lower case", name)
+		}
+	}
+
+	if l.cfg.MaxNameLength > 0 && len([]rune(name)) > l.cfg.MaxNameLength {
+		l.report(RuleNameLength, obj, "name %q exceeds %d characters", name, l.cfg.MaxNameLength)
+	}
+
+	if l.reserved[strings.ToUpper(name)] {
+		l.report(RuleReservedWord, obj, "name %q is an SQL reserved word", name)
+	}
+}
+
+// report records a finding unless the rule is switched off
+func (l *linter) report(rule, obj, format string, args ...interface{}) {
+	severity := defaultSeverity[rule]
+	if s, ok := l.cfg.Severity[rule]; ok {
+		severity = s
+	}
+	if severity == SeverityOff {
+		return
+	}
+	l.result.Findings = append(l.result.Findings, Finding{
+		Rule:     rule,
+		Severity: severity,
+		Object:   obj,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// WriteText writes the findings and a summary line as plain text
+func WriteText(w io.Writer, r *Result) error {
+	var b strings.Builder
+	for _, f := range r.Findings {
+		fmt.Fprintf(&b, "%-7s %-14s %s: %s\n", strings.ToUpper(f.Severity), f.Rule, f.Object, f.Message)
+	}
+	fmt.Fprintf(&b, "\n%d error(s), %d warning(s)\n", r.Errors(), r.Warnings())
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// leadingIndexColumns returns the upper-cased first column of every index on table
+func leadingIndexColumns(schema *model.Schema, table model.Table) map[string]bool {
+	leading := make(map[string]bool)
+	add := func(idx model.Index) {
+		if len(idx.Columns) > 0 {
+			leading[strings.ToUpper(idx.Columns[0])] = true
+		}
+	}
+	for _, idx := range table.Indexes {
+		add(idx)
+	}
+	for _, idx := range schema.Indexes {
+		if strings.EqualFold(idx.TableName, table.Name) && (idx.Owner == "" || strings.EqualFold(idx.Owner, table.Owner)) {
+			add(idx)
+		}
+	}
+	return leading
+}
+
+func hasPrefix(name string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(strings.ToUpper(name), strings.ToUpper(p)) {
+			return true
+		}
+	}
+	return false
+}
+
+func objectPath(owner, name string) string {
+	if owner == "" {
+		return name
+	}
+	return owner + "." + name
+}
+
+// reservedWords are reserved in most of the supported databases
+var reservedWords = []string{
+	"ADD", "ALL", "ALTER", "AND", "ANY", "AS", "ASC", "BETWEEN", "BY", "CASE",
+	"CHECK", "COLUMN", "CONSTRAINT", "CREATE", "CROSS", "CURRENT", "DATE",
+	"DEFAULT", "DELETE", "DESC", "DISTINCT", "DROP", "ELSE", "END", "EXISTS",
+	"FOR", "FOREIGN", "FROM", "FULL", "GRANT", "GROUP", "HAVING", "IN", "INDEX",
+	"INNER", "INSERT", "INTO", "IS", "JOIN", "KEY", "LEFT", "LEVEL", "LIKE",
+	"NOT", "NULL", "OF", "ON", "OR", "ORDER", "OUTER", "PRIMARY", "REFERENCES",
+	"RIGHT", "ROW", "ROWS", "SELECT", "SET", "SIZE", "TABLE", "THEN", "TO",
+	"UNION", "UNIQUE", "UPDATE", "USER", "VALUES", "VIEW", "WHEN", "WHERE", "WITH",
+}
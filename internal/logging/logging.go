@@ -0,0 +1,176 @@
+// Package logging configures the process-wide slog logger from the logging
+// section of the configuration. The standard log package is routed through
+// the same handler, so existing log.Printf output honors the level, format
+// and file as well.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Formats
+const (
+	FormatText    = "text"    // slog key=value lines
+	FormatJSON    = "json"    // one JSON object per line, for log aggregation
+	FormatConsole = "console" // classic "date time message" lines (default)
+)
+
+// ParseLevel converts debug, info, warn or error (default info) to a slog level
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("invalid log level: %s (use debug, info, warn or error)", level)
+}
+
+// New creates a logger writing to w. format is text, json or console
+// (empty = console).
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "", FormatConsole:
+		return slog.New(&consoleHandler{w: w, level: lvl, mu: &sync.Mutex{}}), nil
+	}
+	return nil, fmt.Errorf("invalid log format: %s (use %s, %s or %s)", format, FormatConsole, FormatText, FormatJSON)
+}
+
+// Setup installs a logger as the slog default (and thus behind the standard
+// log package). Logs go to file when set (appended), otherwise to stderr.
+// The returned function closes the log file.
+func Setup(level, format, file string) (func() error, error) {
+	var w io.Writer = os.Stderr
+	closeFn := func() error { return nil }
+	if file != "" {
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		w, closeFn = f, f.Close
+	}
+
+	logger, err := New(w, level, format)
+	if err != nil {
+		closeFn()
+		return nil, err
+	}
+	slog.SetDefault(logger)
+	return closeFn, nil
+}
+
+// Query logs a database query at debug level with its duration and error.
+// Whitespace in the SQL is collapsed to keep one query per line.
+func Query(ctx context.Context, l *slog.Logger, query string, start time.Time, err error) {
+	if !l.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := []any{
+		slog.Duration("duration", time.Since(start)),
+		slog.String("sql", strings.Join(strings.Fields(query), " ")),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	l.DebugContext(ctx, "query", attrs...)
+}
+
+// consoleHandler writes "2006/01/02 15:04:05 [LEVEL ]message key=value"
+// lines like the standard log package; info records carry no level prefix
+type consoleHandler struct {
+	w      io.Writer
+	level  slog.Level
+	attrs  []slog.Attr
+	prefix string // group prefix for attribute keys
+	mu     *sync.Mutex
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString(r.Time.Format("2006/01/02 15:04:05 "))
+	if r.Level != slog.LevelInfo {
+		b.WriteString(r.Level.String())
+		b.WriteByte(' ')
+	}
+	b.WriteString(r.Message)
+	for _, a := range h.attrs {
+		writeAttr(&b, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(&b, h.prefix, a)
+		return true
+	})
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		a.Key = h.prefix + a.Key
+		c.attrs = append(c.attrs, a)
+	}
+	return &c
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.prefix = h.prefix + name + "."
+	return &c
+}
+
+// writeAttr appends " key=value", flattening groups
+func writeAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if a.Key != "" {
+			groupPrefix += a.Key + "."
+		}
+		for _, g := range a.Value.Group() {
+			writeAttr(b, groupPrefix, g)
+		}
+		return
+	}
+	value := a.Value.String()
+	if strings.ContainsAny(value, " =\"") {
+		value = fmt.Sprintf("%q", value)
+	}
+	fmt.Fprintf(b, " %s%s=%s", prefix, a.Key, value)
+}
@@ -0,0 +1,136 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Anchor builds the id of an object from its kind, owner and name, e.g.
+// "table-HR-EMPLOYEES". Letters and digits (including Korean) are kept;
+// anything else becomes '_' so the id is safe in URLs and Word bookmark
+// names. Different names can give the same id; Anchors makes them unique.
+func Anchor(kind, owner, name string) string {
+	var b strings.Builder
+	b.WriteString(kind)
+	for _, part := range []string{owner, name} {
+		if part == "" {
+			continue
+		}
+		b.WriteByte('-')
+		for _, r := range part {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+				b.WriteRune(r)
+			} else {
+				b.WriteByte('_')
+			}
+		}
+	}
+	return b.String()
+}
+
+// Anchors assigns the objects of a document unique ids for HTML anchors
+// and Word bookmarks. Ids are Anchor ids, so they stay the same from one
+// export to the next; objects whose ids clash ("ORDER ITEMS" and
+// ORDER_ITEMS, overloaded routines) get a numeric suffix in schema order.
+type Anchors struct {
+	limit int  // maximum id length in characters (0 = none)
+	fold  bool // ids differing only in case clash
+	ids   map[string][]string
+	next  map[string]int
+	taken map[string]bool
+}
+
+// NewAnchors assigns ids to the tables, views, routines, triggers,
+// sequences and glossary terms of schema. limit caps the id length in
+// characters (0 = no limit) and fold makes ids unique regardless of case,
+// as Word bookmark names (at most 40 characters) require.
+func NewAnchors(schema *Schema, limit int, fold bool) *Anchors {
+	a := &Anchors{
+		limit: limit,
+		fold:  fold,
+		ids:   make(map[string][]string),
+		next:  make(map[string]int),
+		taken: make(map[string]bool),
+	}
+	for _, t := range schema.Tables {
+		a.add("table", t.Owner, t.Name)
+	}
+	for _, v := range schema.Views {
+		a.add("view", v.Owner, v.Name)
+	}
+	for _, r := range schema.Routines {
+		a.add("routine", r.Owner, r.Name)
+	}
+	for _, t := range schema.Triggers {
+		a.add("trigger", t.Owner, t.Name)
+	}
+	for _, s := range schema.Sequences {
+		a.add("sequence", s.Owner, s.Name)
+	}
+	for _, g := range schema.Glossary {
+		a.add("term", "", g.Term)
+	}
+	return a
+}
+
+// ID returns the id to link to the object kind owner.name, the first one
+// when several objects share the name; an object that is not in the
+// schema gets an id on first use
+func (a *Anchors) ID(kind, owner, name string) string {
+	if ids := a.ids[anchorKey(kind, owner, name)]; len(ids) > 0 {
+		return ids[0]
+	}
+	return a.add(kind, owner, name)
+}
+
+// Next returns the id of the element defining the object kind owner.name:
+// each call returns the id of the next object with that name, so
+// overloaded routines written in schema order each get their own
+func (a *Anchors) Next(kind, owner, name string) string {
+	key := anchorKey(kind, owner, name)
+	n := a.next[key]
+	a.next[key]++
+	if ids := a.ids[key]; n < len(ids) {
+		return ids[n]
+	}
+	return a.add(kind, owner, name)
+}
+
+// add assigns the next object kind owner.name an id that is not taken
+func (a *Anchors) add(kind, owner, name string) string {
+	base := Anchor(kind, owner, name)
+	id := a.truncate(base, 0)
+	for n := 2; a.taken[a.uniqueKey(id)]; n++ {
+		suffix := "_" + strconv.Itoa(n)
+		id = a.truncate(base, len(suffix)) + suffix
+	}
+	a.taken[a.uniqueKey(id)] = true
+	key := anchorKey(kind, owner, name)
+	a.ids[key] = append(a.ids[key], id)
+	return id
+}
+
+// truncate cuts id to the length limit, leaving room for reserve more
+// characters
+func (a *Anchors) truncate(id string, reserve int) string {
+	if a.limit <= 0 {
+		return id
+	}
+	if r := []rune(id); len(r) > a.limit-reserve {
+		return string(r[:a.limit-reserve])
+	}
+	return id
+}
+
+// uniqueKey returns the form of id that must be unique
+func (a *Anchors) uniqueKey(id string) string {
+	if a.fold {
+		return strings.ToLower(id)
+	}
+	return id
+}
+
+func anchorKey(kind, owner, name string) string {
+	return kind + "\x00" + owner + "\x00" + name
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// Dependency is a catalog dependency of an object on a table, read from
+// ALL_DEPENDENCIES, pg_depend or sys.sql_expression_dependencies. Only
+// names are kept, never the referencing source.
+type Dependency struct {
+	Object     ObjectRef // referencing view, routine or trigger
+	TableOwner string
+	Table      string
+}
+
+// ApplyDependencies sets ReferencedBy on the tables of schema from deps,
+// and Touches on the procedures and functions (every overload of a name)
+// from the other side of the same dependencies. Each object is listed once
+// per table or routine, ordered by type, owner and name; dependencies on
+// tables outside the schema and of a table on itself are ignored.
+func ApplyDependencies(schema *Schema, deps []Dependency) {
+	g := NewGraph(schema)
+	routines := make(map[string][]*Routine)
+	for i := range schema.Routines {
+		r := &schema.Routines[i]
+		key := QualifiedName(r.Owner, r.Name)
+		routines[key] = append(routines[key], r)
+	}
+	for _, d := range deps {
+		t := g.Table(d.TableOwner, d.Table)
+		if t == nil || d.Object.Owner == t.Owner && d.Object.Name == t.Name {
+			continue
+		}
+		if !containsRef(t.ReferencedBy, d.Object) {
+			t.ReferencedBy = append(t.ReferencedBy, d.Object)
+		}
+		if d.Object.Type != "PROCEDURE" && d.Object.Type != "FUNCTION" {
+			continue
+		}
+		table := ObjectRef{Type: "TABLE", Owner: t.Owner, Name: t.Name}
+		for _, r := range routines[d.Object.String()] {
+			if !containsRef(r.Touches, table) {
+				r.Touches = append(r.Touches, table)
+			}
+		}
+	}
+	for i := range schema.Tables {
+		sortRefs(schema.Tables[i].ReferencedBy)
+	}
+	for i := range schema.Routines {
+		sortRefs(schema.Routines[i].Touches)
+	}
+}
+
+// sortRefs orders refs by type, owner and name
+func sortRefs(refs []ObjectRef) {
+	sort.Slice(refs, func(a, b int) bool {
+		if refs[a].Type != refs[b].Type {
+			return refs[a].Type < refs[b].Type
+		}
+		if refs[a].Owner != refs[b].Owner {
+			return refs[a].Owner < refs[b].Owner
+		}
+		return refs[a].Name < refs[b].Name
+	})
+}
+
+// String returns "owner.name", or the name of an object without owner
+func (r ObjectRef) String() string {
+	return QualifiedName(r.Owner, r.Name)
+}
+
+func containsRef(refs []ObjectRef, ref ObjectRef) bool {
+	for _, r := range refs {
+		if strings.EqualFold(r.Type, ref.Type) && r.Owner == ref.Owner && r.Name == ref.Name {
+			return true
+		}
+	}
+	return false
+}
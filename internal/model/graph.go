@@ -0,0 +1,289 @@
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// Relationship is a foreign key column of Child referencing Parent
+type Relationship struct {
+	Child        *Table
+	Column       string // referencing column of Child
+	Parent       *Table // nil when the referenced table is not in the schema
+	ParentName   string // referenced table as extracted (Column.FKTargetTable)
+	ParentColumn string // referenced column
+}
+
+// Graph is the foreign key graph of the tables of a schema. Tables are
+// referenced by pointer into Schema.Tables, so the schema must not be
+// modified while the graph is in use.
+type Graph struct {
+	schema   *Schema
+	edges    []Relationship
+	outbound map[*Table][]Relationship
+	inbound  map[*Table][]Relationship
+	byKey    map[string]*Table // by tableKey(owner, name)
+	byName   map[string]*Table // first table of each lower-cased name
+}
+
+// NewGraph builds the relationship graph of schema from the foreign key
+// metadata of its table columns. A referenced table given as
+// "owner.table" (PostgreSQL, SQL Server) is looked up in that schema; a
+// bare name (Oracle, MySQL) in the schema of the referencing table first,
+// then anywhere. Names match case-insensitively.
+func NewGraph(schema *Schema) *Graph {
+	g := &Graph{
+		schema:   schema,
+		outbound: make(map[*Table][]Relationship),
+		inbound:  make(map[*Table][]Relationship),
+		byKey:    make(map[string]*Table, len(schema.Tables)),
+		byName:   make(map[string]*Table, len(schema.Tables)),
+	}
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		if _, dup := g.byKey[tableKey(t.Owner, t.Name)]; !dup {
+			g.byKey[tableKey(t.Owner, t.Name)] = t
+		}
+		if _, dup := g.byName[strings.ToLower(t.Name)]; !dup {
+			g.byName[strings.ToLower(t.Name)] = t
+		}
+	}
+
+	for i := range schema.Tables {
+		child := &schema.Tables[i]
+		for _, c := range child.Columns {
+			if c.FKTargetTable == "" {
+				continue
+			}
+			r := Relationship{
+				Child:        child,
+				Column:       c.Name,
+				Parent:       g.resolve(child.Owner, c.FKTargetTable),
+				ParentName:   c.FKTargetTable,
+				ParentColumn: c.FKTargetColumn,
+			}
+			g.edges = append(g.edges, r)
+			g.outbound[child] = append(g.outbound[child], r)
+			if r.Parent != nil {
+				g.inbound[r.Parent] = append(g.inbound[r.Parent], r)
+			}
+		}
+	}
+	return g
+}
+
+// Table returns the table with the given owner and name (matched
+// case-insensitively), or nil
+func (g *Graph) Table(owner, name string) *Table {
+	return g.byKey[tableKey(owner, name)]
+}
+
+func tableKey(owner, name string) string {
+	return strings.ToLower(owner) + "\x00" + strings.ToLower(name)
+}
+
+// resolve returns the table a foreign key of a table owned by owner
+// points to, or nil
+func (g *Graph) resolve(owner, target string) *Table {
+	if i := strings.LastIndexByte(target, '.'); i > 0 {
+		schema, name := target[:i], target[i+1:]
+		if t := g.Table(schema, name); t != nil {
+			return t
+		}
+		// Combined schemas prefix owners with the source ("crm.HR")
+		if src, _, ok := strings.Cut(owner, "."); ok {
+			if t := g.Table(src+"."+schema, name); t != nil {
+				return t
+			}
+		}
+		target = name
+	}
+	if t := g.Table(owner, target); t != nil {
+		return t
+	}
+	return g.byName[strings.ToLower(target)]
+}
+
+// Relationships returns every foreign key column, in table and column order
+func (g *Graph) Relationships() []Relationship {
+	return g.edges
+}
+
+// Outbound returns the foreign keys of t
+func (g *Graph) Outbound(t *Table) []Relationship {
+	return g.outbound[t]
+}
+
+// ForeignKey returns the foreign key of column of the table owner.table
+func (g *Graph) ForeignKey(owner, table, column string) (Relationship, bool) {
+	for _, r := range g.outbound[g.Table(owner, table)] {
+		if r.Column == column {
+			return r, true
+		}
+	}
+	return Relationship{}, false
+}
+
+// Inbound returns the foreign keys of other tables (or t itself) pointing at t
+func (g *Graph) Inbound(t *Table) []Relationship {
+	return g.inbound[t]
+}
+
+// Parents returns the documented tables t references, each once
+func (g *Graph) Parents(t *Table) []*Table {
+	var parents []*Table
+	for _, r := range g.outbound[t] {
+		if r.Parent != nil && !containsTable(parents, r.Parent) {
+			parents = append(parents, r.Parent)
+		}
+	}
+	return parents
+}
+
+// Children returns the tables referencing t, each once
+func (g *Graph) Children(t *Table) []*Table {
+	var children []*Table
+	for _, r := range g.inbound[t] {
+		if !containsTable(children, r.Child) {
+			children = append(children, r.Child)
+		}
+	}
+	return children
+}
+
+// Upstream returns the documented tables t references directly or through
+// other tables, by distance: its parents, their parents and so on, up to
+// depth levels. Each table is listed once, at its nearest level, and t
+// itself never, so cycles end the walk.
+func (g *Graph) Upstream(t *Table, depth int) [][]*Table {
+	return g.levels(t, depth, g.Parents)
+}
+
+// Downstream returns the tables referencing t directly or through other
+// tables, by distance, like Upstream
+func (g *Graph) Downstream(t *Table, depth int) [][]*Table {
+	return g.levels(t, depth, g.Children)
+}
+
+// levels walks the graph breadth-first from t along next
+func (g *Graph) levels(t *Table, depth int, next func(*Table) []*Table) [][]*Table {
+	seen := map[*Table]bool{t: true}
+	var levels [][]*Table
+	for frontier := []*Table{t}; len(levels) < depth; {
+		var level []*Table
+		for _, f := range frontier {
+			for _, n := range next(f) {
+				if !seen[n] {
+					seen[n] = true
+					level = append(level, n)
+				}
+			}
+		}
+		if len(level) == 0 {
+			break
+		}
+		levels = append(levels, level)
+		frontier = level
+	}
+	return levels
+}
+
+// Orphans returns the tables without foreign keys in or out
+func (g *Graph) Orphans() []*Table {
+	var orphans []*Table
+	for i := range g.schema.Tables {
+		t := &g.schema.Tables[i]
+		if len(g.outbound[t]) == 0 && len(g.inbound[t]) == 0 {
+			orphans = append(orphans, t)
+		}
+	}
+	return orphans
+}
+
+// Unresolved returns the foreign keys whose referenced table is not in the
+// schema, e.g. because it is outside the schema filter
+func (g *Graph) Unresolved() []Relationship {
+	var unresolved []Relationship
+	for _, r := range g.edges {
+		if r.Parent == nil {
+			unresolved = append(unresolved, r)
+		}
+	}
+	return unresolved
+}
+
+// Cycles returns the groups of tables that reference each other directly
+// or indirectly, including self-referencing tables (e.g. a manager column),
+// in schema order. Such tables cannot be loaded one after another without
+// deferring a constraint.
+func (g *Graph) Cycles() [][]*Table {
+	// Tarjan's strongly connected components
+	index := make(map[*Table]int)
+	low := make(map[*Table]int)
+	onStack := make(map[*Table]bool)
+	var stack []*Table
+	var components [][]*Table
+
+	var visit func(t *Table)
+	visit = func(t *Table) {
+		index[t] = len(index)
+		low[t] = index[t]
+		stack = append(stack, t)
+		onStack[t] = true
+
+		for _, p := range g.Parents(t) {
+			if _, seen := index[p]; !seen {
+				visit(p)
+				low[t] = min(low[t], low[p])
+			} else if onStack[p] {
+				low[t] = min(low[t], index[p])
+			}
+		}
+
+		if low[t] != index[t] {
+			return
+		}
+		var component []*Table
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == t {
+				break
+			}
+		}
+		if len(component) > 1 || containsTable(g.Parents(t), t) {
+			components = append(components, component)
+		}
+	}
+
+	for i := range g.schema.Tables {
+		t := &g.schema.Tables[i]
+		if _, seen := index[t]; !seen {
+			visit(t)
+		}
+	}
+
+	// Report members in schema order, components by their first member
+	position := make(map[*Table]int, len(g.schema.Tables))
+	for i := range g.schema.Tables {
+		position[&g.schema.Tables[i]] = i
+	}
+	for _, c := range components {
+		sort.Slice(c, func(i, j int) bool { return position[c[i]] < position[c[j]] })
+	}
+	sort.Slice(components, func(i, j int) bool {
+		return position[components[i][0]] < position[components[j][0]]
+	})
+	return components
+}
+
+func containsTable(tables []*Table, t *Table) bool {
+	for _, other := range tables {
+		if other == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package model
+
+import "sort"
+
+// QualifiedName returns "owner.name", or name for an object without owner
+func QualifiedName(owner, name string) string {
+	if owner == "" {
+		return name
+	}
+	return owner + "." + name
+}
+
+// Owners returns the distinct owners of the tables, views and routines of
+// the schema, sorted
+func (s *Schema) Owners() []string {
+	seen := make(map[string]bool)
+	add := func(owner string) {
+		if owner != "" {
+			seen[owner] = true
+		}
+	}
+	for _, t := range s.Tables {
+		add(t.Owner)
+	}
+	for _, v := range s.Views {
+		add(v.Owner)
+	}
+	for _, r := range s.Routines {
+		add(r.Owner)
+	}
+	owners := make([]string, 0, len(seen))
+	for owner := range seen {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+	return owners
+}
+
+// Namer renders object names for display. Objects of different schemas can
+// share a name, so names are owner-qualified unless the schema has at most
+// one owner and bare names were asked for.
+type Namer struct {
+	bare bool
+}
+
+// NewNamer returns the namer of schema; hideSingleSchema drops the owner
+// when every object belongs to the same one
+func NewNamer(schema *Schema, hideSingleSchema bool) Namer {
+	return Namer{bare: hideSingleSchema && len(schema.Owners()) <= 1}
+}
+
+// Name returns the display name of the object owner.name
+func (n Namer) Name(owner, name string) string {
+	if n.bare {
+		return name
+	}
+	return QualifiedName(owner, name)
+}
@@ -1,154 +1,288 @@
-package model
-
-import "time"
-
-// Schema represents the complete database schema metadata
-// It serves as the root container for all database objects
-type Schema struct {
-	DatabaseName string     `json:"databaseName"`
-	DatabaseType string     `json:"databaseType"` // e.g., "oracle", "postgresql", "mysql"
-	Version      string     `json:"version"`
-	ExtractedAt  time.Time  `json:"extractedAt"`
-	Comment      string     `json:"comment,omitempty"`
-	Tables       []Table    `json:"tables,omitempty"`
-	Views        []View     `json:"views,omitempty"`
-	Routines     []Routine  `json:"routines,omitempty"`
-	Sequences    []Sequence `json:"sequences,omitempty"`
-	Triggers     []Trigger  `json:"triggers,omitempty"`
-	Synonyms     []Synonym  `json:"synonyms,omitempty"`
-	Indexes      []Index    `json:"indexes,omitempty"`
-}
-
-// Table represents a database table with its metadata
-type Table struct {
-	Name       string   `json:"name"`
-	Owner      string   `json:"owner,omitempty"`
-	Type       string   `json:"type"` // e.g., "TABLE", "PARTITIONED"
-	Comment    string   `json:"comment,omitempty"`
-	Columns    []Column `json:"columns"`
-	Indexes    []Index  `json:"indexes,omitempty"`
-	RowCount   int64    `json:"rowCount,omitempty"`
-	CreatedAt  string   `json:"createdAt,omitempty"`
-	ModifiedAt string   `json:"modifiedAt,omitempty"`
-}
-
-// View represents a database view with its metadata
-type View struct {
-	Name       string   `json:"name"`
-	Owner      string   `json:"owner,omitempty"`
-	Type       string   `json:"type"` // e.g., "VIEW", "MATERIALIZED VIEW"
-	Comment    string   `json:"comment,omitempty"`
-	Columns    []Column `json:"columns"`
-	IsUpdatable bool    `json:"isUpdatable"`
-	CreatedAt  string   `json:"createdAt,omitempty"`
-	ModifiedAt string   `json:"modifiedAt,omitempty"`
-}
-
-// Column represents a table or view column with comprehensive metadata
-type Column struct {
-	Name         string `json:"name"`
-	Position     int    `json:"position"`
-	DataType     string `json:"dataType"`
-	Length       int    `json:"length,omitempty"`
-	Precision    int    `json:"precision,omitempty"`
-	Scale        int    `json:"scale,omitempty"`
-	Nullable     bool   `json:"nullable"`
-	DefaultValue string `json:"defaultValue,omitempty"`
-	Comment      string `json:"comment,omitempty"`
-	
-	// Constraints
-	IsPrimaryKey   bool   `json:"isPrimaryKey"`
-	IsForeignKey   bool   `json:"isForeignKey"`
-	IsUnique       bool   `json:"isUnique"`
-	FKTargetTable  string `json:"fkTargetTable,omitempty"`
-	FKTargetColumn string `json:"fkTargetColumn,omitempty"`
-	
-	// Additional metadata
-	IsAutoIncrement bool   `json:"isAutoIncrement"`
-	CharacterSet    string `json:"characterSet,omitempty"`
-	Collation       string `json:"collation,omitempty"`
-}
-
-// Routine represents a stored procedure or function
-// CRITICAL: NO source code/definition field - metadata only
-type Routine struct {
-	Name       string           `json:"name"`
-	Owner      string           `json:"owner,omitempty"`
-	Type       string           `json:"type"` // "PROCEDURE" or "FUNCTION"
-	Comment    string           `json:"comment,omitempty"`
-	Signature  string           `json:"signature"` // Full signature without body
-	Arguments  []RoutineArgument `json:"arguments,omitempty"`
-	ReturnType string           `json:"returnType,omitempty"` // For functions
-	Language   string           `json:"language,omitempty"`  // e.g., "SQL", "PLSQL"
-	IsDeterministic bool        `json:"isDeterministic"`
-	SecurityType    string      `json:"securityType,omitempty"` // DEFINER/INVOKER
-	CreatedAt  string           `json:"createdAt,omitempty"`
-	ModifiedAt string           `json:"modifiedAt,omitempty"`
-}
-
-// RoutineArgument represents a parameter of a stored procedure or function
-type RoutineArgument struct {
-	Name         string `json:"name"`
-	Position     int    `json:"position"`
-	Mode         string `json:"mode"` // IN, OUT, INOUT
-	DataType     string `json:"dataType"`
-	DefaultValue string `json:"defaultValue,omitempty"`
-	Comment      string `json:"comment,omitempty"`
-}
-
-// Index represents a database index
-type Index struct {
-	Name       string   `json:"name"`
-	TableName  string   `json:"tableName"`
-	Owner      string   `json:"owner,omitempty"`
-	Type       string   `json:"type"` // e.g., "BTREE", "HASH", "BITMAP"
-	Columns    []string `json:"columns"`
-	IsUnique   bool     `json:"isUnique"`
-	IsPrimary  bool     `json:"isPrimary"`
-	IsEnabled  bool     `json:"isEnabled"`
-	Comment    string   `json:"comment,omitempty"`
-	CreatedAt  string   `json:"createdAt,omitempty"`
-}
-
-// Sequence represents a database sequence
-type Sequence struct {
-	Name        string `json:"name"`
-	Owner       string `json:"owner,omitempty"`
-	MinValue    int64  `json:"minValue"`
-	MaxValue    int64  `json:"maxValue"`
-	Increment   int64  `json:"increment"`
-	LastNumber  int64  `json:"lastNumber"`
-	CacheSize   int    `json:"cacheSize,omitempty"`
-	IsCyclic    bool   `json:"isCyclic"`
-	IsOrdered   bool   `json:"isOrdered"`
-	Comment     string `json:"comment,omitempty"`
-	CreatedAt   string `json:"createdAt,omitempty"`
-}
-
-// Trigger represents a database trigger
-// CRITICAL: NO trigger body/source code - metadata only
-type Trigger struct {
-	Name        string `json:"name"`
-	Owner       string `json:"owner,omitempty"`
-	TargetTable string `json:"targetTable"`
-	TargetType  string `json:"targetType"` // TABLE, VIEW
-	Timing      string `json:"timing"`     // BEFORE, AFTER, INSTEAD OF
-	Event       string `json:"event"`      // INSERT, UPDATE, DELETE
-	Level       string `json:"level"`      // ROW, STATEMENT
-	Status      string `json:"status"`     // ENABLED, DISABLED
-	Comment     string `json:"comment,omitempty"`
-	CreatedAt   string `json:"createdAt,omitempty"`
-	ModifiedAt  string `json:"modifiedAt,omitempty"`
-}
-
-// Synonym represents a database synonym (alias)
-type Synonym struct {
-	Name         string `json:"name"`
-	Owner        string `json:"owner,omitempty"`
-	TargetObject string `json:"targetObject"`
-	TargetOwner  string `json:"targetOwner,omitempty"`
-	TargetType   string `json:"targetType,omitempty"` // TABLE, VIEW, PROCEDURE, etc.
-	IsPublic     bool   `json:"isPublic"`
-	Comment      string `json:"comment,omitempty"`
-	CreatedAt    string `json:"createdAt,omitempty"`
-}
+package model
+
+import "time"
+
+// Schema represents the complete database schema metadata
+// It serves as the root container for all database objects
+type Schema struct {
+	DatabaseName string     `json:"databaseName"`
+	DatabaseType string     `json:"databaseType"` // e.g., "oracle", "postgresql", "mysql"
+	Version      string     `json:"version"`
+	ExtractedAt  time.Time  `json:"extractedAt"`
+	Comment      string     `json:"comment,omitempty"`
+	Tables       []Table    `json:"tables,omitempty"`
+	Views        []View     `json:"views,omitempty"`
+	Routines     []Routine  `json:"routines,omitempty"`
+	Sequences    []Sequence `json:"sequences,omitempty"`
+	Triggers     []Trigger  `json:"triggers,omitempty"`
+	Synonyms     []Synonym  `json:"synonyms,omitempty"`
+	Indexes      []Index    `json:"indexes,omitempty"`
+	Glossary     []GlossaryTerm `json:"glossary,omitempty"`
+	Sources      []Source   `json:"sources,omitempty"` // Databases of a combined multi-database schema
+	Migrations   []Migration `json:"migrations,omitempty"` // Applied Flyway/Liquibase migrations, in the order applied
+	InvalidObjects []ObjectRef `json:"invalidObjects,omitempty"` // Objects that failed to compile (Oracle ALL_OBJECTS.STATUS)
+	Skipped      []string   `json:"skipped,omitempty"` // Sections left out for lack of catalog access (views, routines, ...)
+
+	// Databases (profile names) of a schema consolidated across
+	// environments: objects are documented as defined in the first one and
+	// carry EnvDiffs where the others differ
+	Environments []string `json:"environments,omitempty"`
+
+	// Groups of identical per-tenant schemas documented through their
+	// first schema only (see package tenant)
+	Tenants []TenantPattern `json:"tenants,omitempty"`
+
+	// Extraction time of the older snapshot the Change fields of tables and
+	// columns compare against (nil = not compared)
+	ChangedSince *time.Time `json:"changedSince,omitempty"`
+}
+
+// Change marks of tables and columns compared with an older snapshot
+const (
+	ChangeAdded   = "added"   // not in the older snapshot
+	ChangeChanged = "changed" // definition differs from the older snapshot
+)
+
+// Source describes one database merged into a combined schema. Objects of
+// that database carry Name as the first part of their Owner ("Name.OWNER").
+type Source struct {
+	Name         string `json:"name"`
+	DatabaseName string `json:"databaseName"`
+	DatabaseType string `json:"databaseType"`
+	Version      string `json:"version"`
+	Tables       int    `json:"tables"`
+	Views        int    `json:"views"`
+	Routines     int    `json:"routines"`
+}
+
+// TenantPattern is a group of schemas with the same structure, e.g. one
+// per customer. Only the objects of Schema are documented.
+type TenantPattern struct {
+	Pattern   string   `json:"pattern,omitempty"` // common prefix of the names and "*", e.g. "tenant_*"
+	Schema    string   `json:"schema"`            // the documented schema, first of Instances
+	Instances []string `json:"instances"`         // every schema of the group, sorted by name
+}
+
+// EnvDiff is a difference of a table, view or column in one environment of
+// a consolidated schema from its documented definition. Object and Name
+// name the column or index of a table the difference is about, when it is
+// not the object carrying it.
+type EnvDiff struct {
+	Source  string `json:"source"`            // environment (profile) name
+	Missing bool   `json:"missing,omitempty"` // the object is missing in Source; otherwise it exists there only, or differs
+	Object  string `json:"object,omitempty"`  // "column", "index"
+	Name    string `json:"name,omitempty"`
+	Field   string `json:"field,omitempty"` // comment, dataType, nullable, default, primaryKey, columns, unique
+	Value   string `json:"value,omitempty"` // value of Field in Source
+}
+
+// Migration tools whose history tables are read
+const (
+	MigrationFlyway    = "flyway"
+	MigrationLiquibase = "liquibase"
+)
+
+// Migration is a schema migration recorded in the history table of a
+// migration tool (flyway_schema_history, DATABASECHANGELOG)
+type Migration struct {
+	Tool        string `json:"tool"`                  // MigrationFlyway, MigrationLiquibase
+	Owner       string `json:"owner,omitempty"`       // schema of the history table
+	Version     string `json:"version,omitempty"`     // Flyway version (empty for repeatable migrations), Liquibase changeset ID
+	Description string `json:"description,omitempty"`
+	Script      string `json:"script,omitempty"`      // Flyway script, Liquibase changelog file
+	Type        string `json:"type,omitempty"`        // Flyway SQL, JDBC, BASELINE...; Liquibase EXECUTED, RERAN, MARK_RAN...
+	AppliedBy   string `json:"appliedBy,omitempty"`   // Flyway installed_by, Liquibase changeset author
+	AppliedAt   string `json:"appliedAt,omitempty"`   // "2006-01-02 15:04:05"
+	Success     bool   `json:"success"`
+}
+
+// Table represents a database table with its metadata
+type Table struct {
+	Name       string   `json:"name"`
+	Owner      string   `json:"owner,omitempty"`
+	Type       string   `json:"type"` // e.g., "TABLE", "PARTITIONED"
+	Comment    string   `json:"comment,omitempty"`
+	Columns    []Column `json:"columns"`
+	Indexes    []Index  `json:"indexes,omitempty"`
+	RowCount   int64    `json:"rowCount,omitempty"`
+	CreatedAt  string   `json:"createdAt,omitempty"`
+	ModifiedAt string   `json:"modifiedAt,omitempty"`
+	Terms      []string `json:"terms,omitempty"` // Linked glossary terms
+	ReferencedBy []ObjectRef `json:"referencedBy,omitempty"` // Views, routines and triggers using the table
+
+	// Changes since Schema.ChangedSince
+	Change         string   `json:"change,omitempty"`         // ChangeAdded, ChangeChanged
+	RemovedColumns []Column `json:"removedColumns,omitempty"` // columns of the older snapshot no longer present
+
+	EnvDiffs []EnvDiff `json:"envDiffs,omitempty"` // Differences in other environments (see Schema.Environments)
+
+	// Annotations from a sidecar file
+	Note           string `json:"note,omitempty"`
+	Team           string `json:"team,omitempty"` // Owning team
+	Classification string `json:"classification,omitempty"`
+	Domain         string `json:"domain,omitempty"` // Business domain, from the sidecar file or output.domains
+}
+
+// ObjectRef names a database object, e.g. one that references a table
+type ObjectRef struct {
+	Type  string `json:"type"` // VIEW, MATERIALIZED VIEW, PROCEDURE, FUNCTION, PACKAGE, TRIGGER, TABLE
+	Owner string `json:"owner,omitempty"`
+	Name  string `json:"name"`
+}
+
+// View represents a database view with its metadata
+type View struct {
+	Name       string   `json:"name"`
+	Owner      string   `json:"owner,omitempty"`
+	Type       string   `json:"type"` // e.g., "VIEW", "MATERIALIZED VIEW"
+	Comment    string   `json:"comment,omitempty"`
+	Columns    []Column `json:"columns"`
+	IsUpdatable bool    `json:"isUpdatable"`
+	CreatedAt  string   `json:"createdAt,omitempty"`
+	ModifiedAt string   `json:"modifiedAt,omitempty"`
+	Terms      []string `json:"terms,omitempty"` // Linked glossary terms
+	EnvDiffs   []EnvDiff `json:"envDiffs,omitempty"` // Differences in other environments (see Schema.Environments)
+
+	// Annotations from a sidecar file
+	Note           string `json:"note,omitempty"`
+	Team           string `json:"team,omitempty"` // Owning team
+	Classification string `json:"classification,omitempty"`
+	Domain         string `json:"domain,omitempty"` // Business domain, from the sidecar file or output.domains
+}
+
+// Column represents a table or view column with comprehensive metadata
+type Column struct {
+	Name         string `json:"name"`
+	Position     int    `json:"position"`
+	DataType     string `json:"dataType"`
+	CanonicalType string `json:"canonicalType,omitempty"` // portable type, e.g. "varchar(50)" (see package datatype)
+	Length       int    `json:"length,omitempty"`
+	Precision    int    `json:"precision,omitempty"`
+	Scale        int    `json:"scale,omitempty"`
+	Nullable     bool   `json:"nullable"`
+	DefaultValue string `json:"defaultValue,omitempty"`
+	Comment      string `json:"comment,omitempty"`
+	
+	// Constraints
+	IsPrimaryKey   bool   `json:"isPrimaryKey"`
+	IsForeignKey   bool   `json:"isForeignKey"`
+	IsUnique       bool   `json:"isUnique"`
+	FKTargetTable  string `json:"fkTargetTable,omitempty"`
+	FKTargetColumn string `json:"fkTargetColumn,omitempty"`
+	
+	// Additional metadata
+	IsAutoIncrement bool   `json:"isAutoIncrement"`
+	CharacterSet    string `json:"characterSet,omitempty"`
+	Collation       string `json:"collation,omitempty"`
+	ColumnType      string `json:"columnType,omitempty"` // Type as declared (MySQL COLUMN_TYPE, e.g. "int(10) unsigned", "enum('a','b')")
+	Extra           string `json:"extra,omitempty"`      // Further attributes (MySQL EXTRA, e.g. "on update CURRENT_TIMESTAMP")
+	Generated       string `json:"generated,omitempty"`  // Expression of a generated column
+
+	// Business vocabulary
+	Terms []string `json:"terms,omitempty"` // Linked glossary terms
+
+	// Annotations from a sidecar file
+	Note           string `json:"note,omitempty"`
+	Classification string `json:"classification,omitempty"`
+
+	PII []string `json:"pii,omitempty"` // Matching PII rule names
+
+	Examples []string `json:"examples,omitempty"` // Masked sample values (opt-in)
+
+	Change string `json:"change,omitempty"` // ChangeAdded, ChangeChanged since Schema.ChangedSince
+
+	EnvDiffs []EnvDiff `json:"envDiffs,omitempty"` // Differences in other environments (see Schema.Environments)
+}
+
+// Routine represents a stored procedure or function
+// CRITICAL: NO source code/definition field - metadata only
+type Routine struct {
+	Name       string           `json:"name"`
+	Owner      string           `json:"owner,omitempty"`
+	Type       string           `json:"type"` // "PROCEDURE" or "FUNCTION"
+	Comment    string           `json:"comment,omitempty"`
+	Signature  string           `json:"signature"` // Full signature without body
+	Arguments  []RoutineArgument `json:"arguments,omitempty"`
+	ReturnType string           `json:"returnType,omitempty"` // For functions
+	Language   string           `json:"language,omitempty"`  // e.g., "SQL", "PLSQL"
+	IsDeterministic bool        `json:"isDeterministic"`
+	SecurityType    string      `json:"securityType,omitempty"` // DEFINER/INVOKER
+	Touches         []ObjectRef `json:"touches,omitempty"`      // Tables the routine references, from the dependency catalog
+	CreatedAt  string           `json:"createdAt,omitempty"`
+	ModifiedAt string           `json:"modifiedAt,omitempty"`
+}
+
+// RoutineArgument represents a parameter of a stored procedure or function
+type RoutineArgument struct {
+	Name         string `json:"name"`
+	Position     int    `json:"position"`
+	Mode         string `json:"mode"` // IN, OUT, INOUT
+	DataType     string `json:"dataType"`
+	DefaultValue string `json:"defaultValue,omitempty"`
+	Comment      string `json:"comment,omitempty"`
+}
+
+// Index represents a database index
+type Index struct {
+	Name       string   `json:"name"`
+	TableName  string   `json:"tableName"`
+	Owner      string   `json:"owner,omitempty"`
+	Type       string   `json:"type"` // e.g., "BTREE", "HASH", "BITMAP"
+	Columns    []string `json:"columns"`
+	IsUnique   bool     `json:"isUnique"`
+	IsPrimary  bool     `json:"isPrimary"`
+	IsEnabled  bool     `json:"isEnabled"` // false when disabled, unusable or invalid
+	Comment    string   `json:"comment,omitempty"`
+	CreatedAt  string   `json:"createdAt,omitempty"`
+}
+
+// Sequence represents a database sequence
+type Sequence struct {
+	Name        string `json:"name"`
+	Owner       string `json:"owner,omitempty"`
+	MinValue    int64  `json:"minValue"`
+	MaxValue    int64  `json:"maxValue"`
+	Increment   int64  `json:"increment"`
+	LastNumber  int64  `json:"lastNumber"`
+	CacheSize   int    `json:"cacheSize,omitempty"`
+	IsCyclic    bool   `json:"isCyclic"`
+	IsOrdered   bool   `json:"isOrdered"`
+	Comment     string `json:"comment,omitempty"`
+	CreatedAt   string `json:"createdAt,omitempty"`
+}
+
+// Trigger represents a database trigger
+// CRITICAL: NO trigger body/source code - metadata only
+type Trigger struct {
+	Name        string `json:"name"`
+	Owner       string `json:"owner,omitempty"`
+	TargetTable string `json:"targetTable"`
+	TargetType  string `json:"targetType"` // TABLE, VIEW
+	Timing      string `json:"timing"`     // BEFORE, AFTER, INSTEAD OF
+	Event       string `json:"event"`      // INSERT, UPDATE, DELETE
+	Level       string `json:"level"`      // ROW, STATEMENT
+	Status      string `json:"status"`     // ENABLED, DISABLED
+	Function    *ObjectRef `json:"function,omitempty"` // Routine the trigger executes (PostgreSQL)
+	Comment     string `json:"comment,omitempty"`
+	CreatedAt   string `json:"createdAt,omitempty"`
+	ModifiedAt  string `json:"modifiedAt,omitempty"`
+}
+
+// Synonym represents a database synonym (alias)
+type Synonym struct {
+	Name         string `json:"name"`
+	Owner        string `json:"owner,omitempty"`
+	TargetObject string `json:"targetObject"`
+	TargetOwner  string `json:"targetOwner,omitempty"`
+	TargetType   string `json:"targetType,omitempty"` // TABLE, VIEW, PROCEDURE, etc.
+	IsPublic     bool   `json:"isPublic"`
+	Comment      string `json:"comment,omitempty"`
+	CreatedAt    string `json:"createdAt,omitempty"`
+}
+
+// GlossaryTerm is a business term with its definition, linked from tables
+// and columns via their Terms field
+type GlossaryTerm struct {
+	Term       string `json:"term"`
+	Definition string `json:"definition"`
+}
@@ -0,0 +1,183 @@
+// Package naming derives the naming conventions of a schema from its
+// column names: the prefixes and suffixes (IS_, _YN, _DT, _CD, ...) that
+// columns of several tables share, how often each occurs, what it most
+// likely means and example columns. The dictionary forms an optional
+// appendix for new team members (output.include_naming).
+package naming
+
+import (
+	"pocket-doc/internal/datatype"
+	"pocket-doc/internal/model"
+	"sort"
+	"strings"
+)
+
+// Thresholds of Analyze
+const (
+	MinColumns  = 3 // columns sharing an affix
+	MinTables   = 2 // tables they belong to, so table abbreviations (EMP_) are left out
+	MaxExamples = 3 // example columns per affix
+)
+
+// Meaning IDs; documents translate them (naming.meaning.<id>)
+const (
+	MeaningFlag        = "flag"
+	MeaningDate        = "date"
+	MeaningDateTime    = "datetime"
+	MeaningTime        = "time"
+	MeaningCode        = "code"
+	MeaningName        = "name"
+	MeaningNumber      = "number"
+	MeaningID          = "id"
+	MeaningAmount      = "amount"
+	MeaningQuantity    = "quantity"
+	MeaningCount       = "count"
+	MeaningRate        = "rate"
+	MeaningText        = "text"
+	MeaningDescription = "description"
+	MeaningStatus      = "status"
+	MeaningType        = "type"
+	MeaningSequence    = "sequence"
+	MeaningAddress     = "address"
+	MeaningUser        = "user"
+)
+
+// Meanings lists the meaning IDs
+var Meanings = []string{MeaningFlag, MeaningDate, MeaningDateTime, MeaningTime, MeaningCode, MeaningName,
+	MeaningNumber, MeaningID, MeaningAmount, MeaningQuantity, MeaningCount, MeaningRate, MeaningText,
+	MeaningDescription, MeaningStatus, MeaningType, MeaningSequence, MeaningAddress, MeaningUser}
+
+// suffixes and prefixes map the common abbreviations, upper-case and
+// without the underscore, to their meaning
+var (
+	suffixes = map[string]string{
+		"YN": MeaningFlag, "FLAG": MeaningFlag, "FLG": MeaningFlag,
+		"DT": MeaningDate, "DATE": MeaningDate, "YMD": MeaningDate, "DAY": MeaningDate,
+		"DTM": MeaningDateTime, "DTTM": MeaningDateTime, "TS": MeaningDateTime, "AT": MeaningDateTime,
+		"TM": MeaningTime, "TIME": MeaningTime,
+		"CD": MeaningCode, "CODE": MeaningCode,
+		"NM": MeaningName, "NAME": MeaningName,
+		"NO": MeaningNumber, "NUM": MeaningNumber, "NBR": MeaningNumber,
+		"ID": MeaningID, "KEY": MeaningID, "UUID": MeaningID,
+		"AMT": MeaningAmount, "AMOUNT": MeaningAmount, "PRICE": MeaningAmount, "PRC": MeaningAmount,
+		"QTY": MeaningQuantity,
+		"CNT": MeaningCount, "COUNT": MeaningCount,
+		"RT": MeaningRate, "RATE": MeaningRate, "PCT": MeaningRate, "RATIO": MeaningRate,
+		"TXT": MeaningText, "TEXT": MeaningText, "CN": MeaningText, "CONT": MeaningText,
+		"DESC": MeaningDescription, "DSC": MeaningDescription, "RMK": MeaningDescription, "MEMO": MeaningDescription, "NOTE": MeaningDescription,
+		"STS": MeaningStatus, "STAT": MeaningStatus, "STATUS": MeaningStatus,
+		"TP": MeaningType, "TYPE": MeaningType, "TYP": MeaningType, "GB": MeaningType, "DIV": MeaningType, "CLS": MeaningType,
+		"SEQ": MeaningSequence, "SN": MeaningSequence,
+		"ADDR": MeaningAddress,
+		"BY": MeaningUser, "USER": MeaningUser, "USR": MeaningUser,
+	}
+	prefixes = map[string]string{
+		"IS": MeaningFlag, "HAS": MeaningFlag, "CAN": MeaningFlag, "USE": MeaningFlag,
+		"NUM": MeaningCount, "CNT": MeaningCount,
+		"DT": MeaningDate, "YMD": MeaningDate,
+	}
+)
+
+// Affix is a prefix ("IS_") or suffix ("_YN") shared by column names
+type Affix struct {
+	Text     string        `json:"text"`              // with the underscore, in the case of its first column
+	Prefix   bool          `json:"prefix"`            // false for a suffix
+	Columns  int           `json:"columns"`           // number of columns
+	Tables   int           `json:"tables"`            // number of tables they belong to
+	Meaning  string        `json:"meaning,omitempty"` // meaning ID, "" when it cannot be inferred
+	Kind     datatype.Kind `json:"kind"`              // the most frequent type kind of the columns
+	Examples []string      `json:"examples"`          // up to MaxExamples "TABLE.COLUMN"
+}
+
+// Dictionary holds the affixes of a schema, most frequent first
+type Dictionary struct {
+	Affixes []Affix `json:"affixes"`
+}
+
+// Analyze builds the dictionary from the table columns of schema. A
+// meaning comes from the common abbreviations (YN: flag, CD: code, ...),
+// or, for other affixes, from the type of the columns when nearly all of
+// them are dates or booleans.
+func Analyze(schema *model.Schema) *Dictionary {
+	type tally struct {
+		affix  Affix
+		tables map[string]bool
+		kinds  map[datatype.Kind]int
+	}
+	byKey := make(map[string]*tally)
+	count := func(key, text string, prefix bool, t *model.Table, c model.Column) {
+		a, ok := byKey[key]
+		if !ok {
+			a = &tally{affix: Affix{Text: text, Prefix: prefix}, tables: make(map[string]bool), kinds: make(map[datatype.Kind]int)}
+			byKey[key] = a
+		}
+		a.affix.Columns++
+		a.tables[model.QualifiedName(t.Owner, t.Name)] = true
+		a.kinds[datatype.Of(schema.DatabaseType, c).Kind]++
+		if len(a.affix.Examples) < MaxExamples {
+			a.affix.Examples = append(a.affix.Examples, t.Name+"."+c.Name)
+		}
+	}
+
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		for _, c := range t.Columns {
+			first, last := strings.Index(c.Name, "_"), strings.LastIndex(c.Name, "_")
+			if first <= 0 || last == len(c.Name)-1 {
+				continue
+			}
+			count("P"+strings.ToUpper(c.Name[:first]), c.Name[:first+1], true, t, c)
+			count("S"+strings.ToUpper(c.Name[last+1:]), c.Name[last:], false, t, c)
+		}
+	}
+
+	d := &Dictionary{}
+	for _, a := range byKey {
+		if a.affix.Columns < MinColumns || len(a.tables) < MinTables {
+			continue
+		}
+		a.affix.Tables = len(a.tables)
+		for kind, n := range a.kinds {
+			if n > a.kinds[a.affix.Kind] || n == a.kinds[a.affix.Kind] && kind < a.affix.Kind {
+				a.affix.Kind = kind
+			}
+		}
+		a.affix.Meaning = meaning(a.affix, a.kinds[a.affix.Kind])
+		d.Affixes = append(d.Affixes, a.affix)
+	}
+	sort.Slice(d.Affixes, func(i, j int) bool {
+		a, b := d.Affixes[i], d.Affixes[j]
+		if a.Columns != b.Columns {
+			return a.Columns > b.Columns
+		}
+		if a.Prefix != b.Prefix {
+			return !a.Prefix
+		}
+		return strings.ToUpper(a.Text) < strings.ToUpper(b.Text)
+	})
+	return d
+}
+
+// meaning infers the meaning of a, whose most frequent kind covers n of
+// its columns
+func meaning(a Affix, n int) string {
+	token := strings.ToUpper(strings.Trim(a.Text, "_"))
+	known := suffixes
+	if a.Prefix {
+		known = prefixes
+	}
+	if m, ok := known[token]; ok {
+		return m
+	}
+	// Nearly all columns of one kind (9 in 10)
+	if n*10 < a.Columns*9 {
+		return ""
+	}
+	switch a.Kind {
+	case datatype.KindTemporal:
+		return MeaningDate
+	case datatype.KindBoolean:
+		return MeaningFlag
+	}
+	return ""
+}
@@ -0,0 +1,162 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Slack posts a summary message to a Slack incoming webhook
+type Slack struct {
+	URL      string
+	LinkBase string        // URL the output directory is published at (empty = documents are named, not linked)
+	Timeout  time.Duration // 0 = DefaultTimeout
+}
+
+// Send posts the message for run to Slack
+func (s Slack) Send(ctx context.Context, run *Run) error {
+	m := newMessage(run, s.LinkBase)
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%s*\n", slackEscape(m.title))
+	for _, line := range m.databases {
+		fmt.Fprintf(&b, "• %s\n", slackEscape(line))
+	}
+	if len(m.documents) > 0 {
+		docs := make([]string, len(m.documents))
+		for i, d := range m.documents {
+			docs[i] = slackEscape(d.Name)
+			if d.URL != "" {
+				docs[i] = fmt.Sprintf("<%s|%s>", d.URL, docs[i])
+			}
+		}
+		fmt.Fprintf(&b, "Documents: %s\n", strings.Join(docs, ", "))
+	}
+	fmt.Fprintf(&b, "_%s_", slackEscape(m.footer))
+
+	payload := map[string]string{"text": b.String()}
+	return post(ctx, "slack", s.URL, nil, s.Timeout, run.Version, payload)
+}
+
+// Teams posts a summary card to a Microsoft Teams incoming webhook
+// (connector or Workflows)
+type Teams struct {
+	URL      string
+	LinkBase string        // URL the output directory is published at (empty = documents are named, not linked)
+	Timeout  time.Duration // 0 = DefaultTimeout
+}
+
+// Send posts the card for run to Teams
+func (t Teams) Send(ctx context.Context, run *Run) error {
+	m := newMessage(run, t.LinkBase)
+	text := func(s string, props map[string]any) map[string]any {
+		block := map[string]any{"type": "TextBlock", "text": s, "wrap": true}
+		for k, v := range props {
+			block[k] = v
+		}
+		return block
+	}
+
+	body := []any{text(m.title, map[string]any{"weight": "bolder", "size": "medium"})}
+	for _, line := range m.databases {
+		body = append(body, text("- "+line, map[string]any{"spacing": "none"}))
+	}
+	if len(m.documents) > 0 {
+		docs := make([]string, len(m.documents))
+		for i, d := range m.documents {
+			docs[i] = d.Name
+			if d.URL != "" {
+				docs[i] = fmt.Sprintf("[%s](%s)", d.Name, d.URL)
+			}
+		}
+		body = append(body, text("Documents: "+strings.Join(docs, ", "), nil))
+	}
+	body = append(body, text(m.footer, map[string]any{"isSubtle": true}))
+
+	payload := map[string]any{
+		"type": "message",
+		"attachments": []any{map[string]any{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]any{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+	return post(ctx, "teams", t.URL, nil, t.Timeout, run.Version, payload)
+}
+
+// Link is a written document, with its URL when the output directory is
+// published
+type Link struct {
+	Name string
+	URL  string
+}
+
+// Links returns the documents of run, linked to their uploaded copy or
+// under base (the URL of the output directory) when it is set. Output to
+// stdout is left out.
+func Links(run *Run, base string) []Link {
+	var links []Link
+	for _, o := range run.Outputs {
+		if o.File == "-" {
+			continue
+		}
+		l := Link{Name: filepath.Base(o.File), URL: o.URL}
+		if l.URL == "" && base != "" {
+			l.URL = strings.TrimSuffix(base, "/") + "/" + url.PathEscape(l.Name)
+		}
+		links = append(links, l)
+	}
+	return links
+}
+
+// message is the text of a chat notification
+type message struct {
+	title     string
+	databases []string // one line per database
+	documents []Link
+	footer    string
+}
+
+// newMessage summarizes run: what was extracted from each database, the
+// documents written and the warnings
+func newMessage(run *Run, linkBase string) message {
+	m := message{title: "📚 Database documentation regenerated", documents: Links(run, linkBase)}
+	failed := 0
+	for _, db := range run.Databases {
+		name := db.DatabaseName
+		if name == "" {
+			name = db.Profile
+		}
+		if db.DatabaseType != "" {
+			name += " (" + db.DatabaseType + ")"
+		}
+		if db.Error != "" {
+			failed++
+			m.databases = append(m.databases, name+": extraction failed")
+			continue
+		}
+		m.databases = append(m.databases, fmt.Sprintf("%s: %d tables, %d columns, %d views, %d routines", name, db.Tables, db.Columns, db.Views, db.Routines))
+	}
+	if failed > 0 {
+		m.title += fmt.Sprintf(" (%d of %d databases failed)", failed, len(run.Databases))
+	}
+
+	m.footer = fmt.Sprintf("pocket-doc %s · %s", run.Version, (time.Duration(run.DurationMs) * time.Millisecond).Round(100*time.Millisecond))
+	if n := len(run.Warnings); n == 1 {
+		m.footer += " · 1 warning"
+	} else if n > 1 {
+		m.footer += fmt.Sprintf(" · %d warnings", n)
+	}
+	return m
+}
+
+// slackEscape escapes the characters Slack's mrkdwn treats as markup
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,264 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Connection security of Email
+const (
+	SecurityStartTLS = "starttls" // upgrade a plain connection (port 587)
+	SecurityTLS      = "tls"      // TLS from the start (port 465)
+	SecurityNone     = "none"     // plain text, for relays on the local network
+)
+
+// DefaultSubject is the subject of emails without one
+const DefaultSubject = "Database documentation: {db} ({date})"
+
+// DefaultMaxAttachment caps the size of an attached document; larger ones
+// are named (and linked when uploaded) instead, as mail servers reject
+// big messages
+const DefaultMaxAttachment = 20 << 20
+
+// Email sends the written documents as attachments to a distribution list
+// through an SMTP server
+type Email struct {
+	Host          string
+	Port          int    // 0 = 465 with SecurityTLS, 587 otherwise
+	Security      string // starttls (default), tls, none
+	Username      string // empty = no authentication
+	Password      string
+	From          string
+	To            []string
+	Subject       string        // with {db}, {date}, ... (see Fields); empty = DefaultSubject
+	Formats       []string      // formats to attach (empty = all)
+	MaxAttachment int64         // bytes (0 = DefaultMaxAttachment)
+	Timeout       time.Duration // 0 = DefaultTimeout
+}
+
+// Send mails the documents of run
+func (e Email) Send(ctx context.Context, run *Run) error {
+	msg, err := e.Message(run)
+	if err != nil {
+		return err
+	}
+	from, err := mail.ParseAddress(e.From)
+	if err != nil {
+		return fmt.Errorf("email: invalid sender %s", e.From)
+	}
+
+	timeout := e.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	c, err := e.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("email %s: %w", e.Host, err)
+	}
+	defer c.Close()
+	if err := e.deliver(c, from.Address, msg); err != nil {
+		return fmt.Errorf("email %s: %w", e.Host, err)
+	}
+	return nil
+}
+
+// dial connects to the server and secures the connection
+func (e Email) dial(ctx context.Context) (*smtp.Client, error) {
+	port := e.Port
+	if port == 0 {
+		port = 587
+		if strings.EqualFold(e.Security, SecurityTLS) {
+			port = 465
+		}
+	}
+	addr := net.JoinHostPort(e.Host, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: e.Host}
+
+	var conn net.Conn
+	var err error
+	if strings.EqualFold(e.Security, SecurityTLS) {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if e.Security == "" || strings.EqualFold(e.Security, SecurityStartTLS) {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			c.Close()
+			return nil, errors.New("server does not offer STARTTLS (set tls: none for a plain relay)")
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// deliver authenticates and sends msg to the recipients
+func (e Email) deliver(c *smtp.Client, from string, msg []byte) error {
+	if e.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.Username, e.Password, e.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, to := range e.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %s", to)
+		}
+		if err := c.Rcpt(addr.Address); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// Message builds the MIME message for run: the summary as text and the
+// documents as attachments
+func (e Email) Message(run *Run) ([]byte, error) {
+	m := newMessage(run, "")
+	limit := e.MaxAttachment
+	if limit <= 0 {
+		limit = DefaultMaxAttachment
+	}
+
+	var text strings.Builder
+	text.WriteString(m.title + "\n\n")
+	for _, line := range m.databases {
+		text.WriteString("- " + line + "\n")
+	}
+	var attach []Output
+	var skipped []string
+	for _, o := range run.Outputs {
+		wanted := func(f string) bool { return strings.EqualFold(f, o.Format) }
+		if o.File == "-" || (len(e.Formats) > 0 && !slices.ContainsFunc(e.Formats, wanted)) {
+			continue
+		}
+		if o.Bytes > limit {
+			note := fmt.Sprintf("%s (%d MB, not attached)", filepath.Base(o.File), (o.Bytes+(1<<20)-1)>>20)
+			if o.URL != "" {
+				note += ": " + o.URL
+			}
+			skipped = append(skipped, note)
+			continue
+		}
+		attach = append(attach, o)
+	}
+	if len(skipped) > 0 {
+		text.WriteString("\n" + strings.Join(skipped, "\n") + "\n")
+	}
+	text.WriteString("\n" + m.footer + "\n")
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	writeBase64(part, []byte(strings.ReplaceAll(text.String(), "\n", "\r\n")))
+	for _, o := range attach {
+		data, err := os.ReadFile(o.File)
+		if err != nil {
+			return nil, fmt.Errorf("email: %w", err)
+		}
+		name := filepath.Base(o.File)
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(attachmentType(name), map[string]string{"name": name})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": name})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		writeBase64(part, data)
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	subject := e.Subject
+	if subject == "" {
+		subject = DefaultSubject
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", Expand(subject, run)))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// attachmentType returns the media type of a document by its extension
+func attachmentType(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".docx":
+		return "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	case ".xlsx":
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	if t := mime.TypeByExtension(filepath.Ext(name)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// writeBase64 writes data base64-encoded in lines of 76 characters
+func writeBase64(w io.Writer, data []byte) {
+	enc := base64.StdEncoding.EncodeToString(data)
+	for len(enc) > 76 {
+		w.Write([]byte(enc[:76] + "\r\n"))
+		enc = enc[76:]
+	}
+	w.Write([]byte(enc + "\r\n"))
+}
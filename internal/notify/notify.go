@@ -0,0 +1,148 @@
+// Package notify tells other systems that a run finished: the run summary
+// (the document -summary-json prints) is posted as JSON to a webhook, so
+// internal automation can pick up the documents without polling.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Run is the summary of a finished command
+type Run struct {
+	Command    string     `json:"command"`
+	Version    string     `json:"version"`
+	Status     string     `json:"status"` // ok, partial, error
+	ExitCode   int        `json:"exitCode"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"startedAt"`
+	DurationMs int64      `json:"durationMs"`
+	Databases  []Database `json:"databases"`
+	Outputs    []Output   `json:"outputs"`
+	Warnings   []string   `json:"warnings"`
+}
+
+// Database is what was extracted from one database
+type Database struct {
+	Profile      string `json:"profile,omitempty"`
+	DatabaseName string `json:"databaseName,omitempty"`
+	DatabaseType string `json:"databaseType,omitempty"`
+	Tables       int    `json:"tables"`
+	Columns      int    `json:"columns"`
+	Views        int    `json:"views"`
+	Routines     int    `json:"routines"`
+	Sequences    int    `json:"sequences"`
+	Triggers     int    `json:"triggers"`
+	Synonyms     int    `json:"synonyms"`
+	Indexes      int    `json:"indexes"`
+	DurationMs   int64  `json:"durationMs"`
+	Error        string `json:"error,omitempty"`
+}
+
+// Output is a written document
+type Output struct {
+	File       string `json:"file"`
+	URL        string `json:"url,omitempty"` // where output.upload stored it
+	Format     string `json:"format"`
+	Bytes      int64  `json:"bytes"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// Fields are the placeholders of texts about a run, such as email
+// subjects and commit messages
+var Fields = []string{"db", "type", "profile", "status", "date", "time"}
+
+// Expand replaces the {field} placeholders of text (see Fields) with the
+// values of run
+func Expand(text string, run *Run) string {
+	var dbs, types, profiles []string
+	add := func(list *[]string, value string) {
+		if value != "" && !slices.Contains(*list, value) {
+			*list = append(*list, value)
+		}
+	}
+	for _, db := range run.Databases {
+		add(&dbs, db.DatabaseName)
+		add(&types, db.DatabaseType)
+		add(&profiles, db.Profile)
+	}
+	return strings.NewReplacer(
+		"{db}", strings.Join(dbs, ", "),
+		"{type}", strings.Join(types, ", "),
+		"{profile}", strings.Join(profiles, ", "),
+		"{status}", run.Status,
+		"{date}", run.StartedAt.Format("2006-01-02"),
+		"{time}", run.StartedAt.Format("15:04"),
+	).Replace(text)
+}
+
+// DefaultTimeout bounds a notification request when no timeout is
+// configured
+const DefaultTimeout = 10 * time.Second
+
+// Notifier announces a finished run
+type Notifier interface {
+	Send(ctx context.Context, run *Run) error
+}
+
+// Webhook posts runs as JSON to URL with the extra Headers (e.g. an
+// Authorization token). Any 2xx status is success.
+type Webhook struct {
+	URL     string
+	Headers map[string]string
+	Timeout time.Duration // 0 = DefaultTimeout
+}
+
+// Send posts run to the webhook
+func (w Webhook) Send(ctx context.Context, run *Run) error {
+	return post(ctx, "webhook", w.URL, w.Headers, w.Timeout, run.Version, run)
+}
+
+// post sends payload as JSON to target and fails on any status but 2xx.
+// name labels errors.
+func post(ctx context.Context, name, target string, headers map[string]string, timeout time.Duration, version string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s: invalid URL", name)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pocket-doc/"+version)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	// Errors name the host only, as webhook URLs often carry a token
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("%s %s: %w", name, req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s: %s", name, req.URL.Host, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
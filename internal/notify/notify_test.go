@@ -0,0 +1,251 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWebhookNotify tests posting the run summary to a webhook
+func TestWebhookNotify(t *testing.T) {
+	var got Run
+	var auth, contentType string
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, contentType = r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Bad payload: %v", err)
+		}
+		w.WriteHeader(status)
+		fmt.Fprint(w, "denied")
+	}))
+	defer srv.Close()
+
+	run := &Run{
+		Command:   "export",
+		Version:   "test",
+		Status:    "ok",
+		Databases: []Database{{DatabaseName: "shop", DatabaseType: "postgres", Tables: 12, Columns: 80}},
+		Outputs:   []Output{{File: "output/shop.html", Format: "html", Bytes: 1024}},
+		Warnings:  []string{"3 tables lack comments"},
+	}
+	hook := Webhook{URL: srv.URL + "/hook/secret-token", Headers: map[string]string{"Authorization": "Bearer x"}}
+	if err := hook.Send(context.Background(), run); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if auth != "Bearer x" || contentType != "application/json" {
+		t.Errorf("Headers = %q, %q", auth, contentType)
+	}
+	if len(got.Databases) != 1 || got.Databases[0].Tables != 12 || len(got.Outputs) != 1 || got.Outputs[0].File != "output/shop.html" || len(got.Warnings) != 1 {
+		t.Errorf("Payload = %+v", got)
+	}
+
+	// A non-2xx status fails without revealing the token in the URL
+	status = http.StatusForbidden
+	err := hook.Send(context.Background(), run)
+	if err == nil {
+		t.Fatal("Expected an error for 403")
+	}
+	if strings.Contains(err.Error(), "secret-token") || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Error = %v", err)
+	}
+
+	// Unreachable hosts fail the same way
+	srv.Close()
+	if err := hook.Send(context.Background(), run); err == nil || strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("Error for a closed server = %v", err)
+	}
+}
+
+// TestChatNotify tests the Slack and Teams summary messages
+func TestChatNotify(t *testing.T) {
+	bodies := make(map[string]string)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies[r.URL.Path] = string(b)
+	}))
+	defer srv.Close()
+
+	run := &Run{
+		Version:    "test",
+		Status:     "partial",
+		DurationMs: 2500,
+		Databases: []Database{
+			{DatabaseName: "shop", DatabaseType: "postgres", Tables: 12, Columns: 80, Views: 2},
+			{Profile: "dw", Error: "connection refused"},
+		},
+		Outputs:  []Output{{File: filepath.Join("output", "shop schema.html"), Format: "html"}, {File: "-", Format: "json"}},
+		Warnings: []string{"a", "b"},
+	}
+	base := "https://docs.example.com/db/"
+	ctx := context.Background()
+	if err := (Slack{URL: srv.URL + "/slack", LinkBase: base}).Send(ctx, run); err != nil {
+		t.Fatalf("Slack failed: %v", err)
+	}
+	if err := (Teams{URL: srv.URL + "/teams", LinkBase: base}).Send(ctx, run); err != nil {
+		t.Fatalf("Teams failed: %v", err)
+	}
+
+	var slack struct{ Text string }
+	if err := json.Unmarshal([]byte(bodies["/slack"]), &slack); err != nil {
+		t.Fatalf("Bad Slack payload: %v", err)
+	}
+	for _, want := range []string{
+		"(1 of 2 databases failed)",
+		"shop (postgres): 12 tables, 80 columns, 2 views",
+		"dw: extraction failed",
+		"<https://docs.example.com/db/shop%20schema.html|shop schema.html>",
+		"2 warnings",
+	} {
+		if !strings.Contains(slack.Text, want) {
+			t.Errorf("Slack message lacks %q:\n%s", want, slack.Text)
+		}
+	}
+	if strings.Contains(slack.Text, "connection refused") {
+		t.Error("Slack message should not carry extraction errors")
+	}
+
+	teams := bodies["/teams"]
+	for _, want := range []string{`"AdaptiveCard"`, "[shop schema.html](https://docs.example.com/db/shop%20schema.html)"} {
+		if !strings.Contains(teams, want) {
+			t.Errorf("Teams card lacks %q:\n%s", want, teams)
+		}
+	}
+
+	// Without a link base documents are named only
+	links := Links(run, "")
+	if len(links) != 1 || links[0].Name != "shop schema.html" || links[0].URL != "" {
+		t.Errorf("Links = %+v", links)
+	}
+}
+
+// TestEmailNotify tests mailing the documents through an SMTP relay
+func TestEmailNotify(t *testing.T) {
+	dir := t.TempDir()
+	small := filepath.Join(dir, "shop.xlsx")
+	big := filepath.Join(dir, "shop.html")
+	os.WriteFile(small, []byte("PK-xlsx"), 0644)
+	os.WriteFile(big, []byte("<html>"), 0644)
+	run := &Run{
+		Status:    "ok",
+		StartedAt: time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC),
+		Databases: []Database{{DatabaseName: "shop", DatabaseType: "mysql", Tables: 3}},
+		Outputs: []Output{
+			{File: small, Format: "xlsx", Bytes: 7},
+			{File: big, Format: "html", Bytes: 30 << 20, URL: "https://docs.example.com/shop.html"},
+			{File: "-", Format: "json"},
+		},
+	}
+
+	// A tiny SMTP relay that records the envelope and the message
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	var rcpt []string
+	var data strings.Builder
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		fmt.Fprint(conn, "220 relay\r\n")
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case inData && line == ".\r\n":
+				inData = false
+				fmt.Fprint(conn, "250 queued\r\n")
+			case inData:
+				data.WriteString(line)
+			case strings.HasPrefix(line, "EHLO"):
+				fmt.Fprint(conn, "250 relay\r\n")
+			case strings.HasPrefix(line, "RCPT TO:"):
+				rcpt = append(rcpt, strings.TrimSpace(line[8:]))
+				fmt.Fprint(conn, "250 ok\r\n")
+			case strings.HasPrefix(line, "DATA"):
+				inData = true
+				fmt.Fprint(conn, "354 go\r\n")
+			case strings.HasPrefix(line, "QUIT"):
+				fmt.Fprint(conn, "221 bye\r\n")
+				return
+			default:
+				fmt.Fprint(conn, "250 ok\r\n")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	portNum := 0
+	fmt.Sscan(port, &portNum)
+	e := Email{
+		Host:     host,
+		Port:     portNum,
+		Security: SecurityNone,
+		From:     "Docs <docs@example.com>",
+		To:       []string{"dba@example.com", "Team <team@example.com>"},
+		Subject:  "Schema {db} ({type}) {date}",
+	}
+	if err := e.Send(context.Background(), run); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	<-done
+	if !slices.Equal(rcpt, []string{"<dba@example.com>", "<team@example.com>"}) {
+		t.Errorf("Recipients = %v", rcpt)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(data.String()))
+	if err != nil {
+		t.Fatalf("Bad message: %v", err)
+	}
+	if subject := msg.Header.Get("Subject"); subject != "Schema shop (mysql) 2026-03-02" {
+		t.Errorf("Subject = %q", subject)
+	}
+	_, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	var attached []string
+	var text string
+	for {
+		p, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+		body, _ := io.ReadAll(base64.NewDecoder(base64.StdEncoding, p))
+		if p.FileName() != "" {
+			attached = append(attached, p.FileName())
+		} else {
+			text = string(body)
+		}
+	}
+	// The oversized HTML is linked instead of attached
+	if !slices.Equal(attached, []string{"shop.xlsx"}) {
+		t.Errorf("Attachments = %v", attached)
+	}
+	if !strings.Contains(text, "shop.html (30 MB, not attached): https://docs.example.com/shop.html") || !strings.Contains(text, "shop (mysql): 3 tables") {
+		t.Errorf("Text = %q", text)
+	}
+}
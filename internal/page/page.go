@@ -0,0 +1,103 @@
+// Package page sets the paper size and orientation of the printable
+// formats: the section of Word documents, the @page rule of HTML pages
+// (and the PDFs printed from them) and the page setup of Excel sheets.
+// Tables with many columns are unreadable on portrait A4, so each format
+// can be printed in landscape or on Letter paper.
+package page
+
+import "strings"
+
+// Paper sizes
+const (
+	A4     = "a4"
+	Letter = "letter"
+)
+
+// Orientations
+const (
+	Portrait  = "portrait"
+	Landscape = "landscape"
+)
+
+// Setup is the paper of a document; empty fields default to A4 portrait
+type Setup struct {
+	Size        string // A4, Letter
+	Orientation string // portrait, landscape
+}
+
+// Config is the paper of every document, with overrides per export format
+type Config struct {
+	Setup
+	Formats map[string]Setup // by format name (docx, html, xlsx)
+}
+
+// For returns the setup of format: the fields of its override, the
+// defaults for the others
+func (c Config) For(format string) Setup {
+	s := c.Setup
+	o := c.Formats[strings.ToLower(format)]
+	if o.Size != "" {
+		s.Size = o.Size
+	}
+	if o.Orientation != "" {
+		s.Orientation = o.Orientation
+	}
+	return s
+}
+
+// IsZero reports whether neither the size nor the orientation is set
+func (s Setup) IsZero() bool {
+	return s.Size == "" && s.Orientation == ""
+}
+
+// IsLetter reports whether the paper is Letter rather than A4
+func (s Setup) IsLetter() bool {
+	return strings.EqualFold(s.Size, Letter)
+}
+
+// IsLandscape reports whether pages are printed in landscape
+func (s Setup) IsLandscape() bool {
+	return strings.EqualFold(s.Orientation, Landscape)
+}
+
+// Twips returns the page width and height in twentieths of a point, as
+// Word's w:pgSz takes them
+func (s Setup) Twips() (width, height int) {
+	width, height = 11906, 16838 // 210 × 297 mm
+	if s.IsLetter() {
+		width, height = 12240, 15840 // 8.5 × 11 in
+	}
+	if s.IsLandscape() {
+		width, height = height, width
+	}
+	return width, height
+}
+
+// CSS returns the value of the size property of an @page rule, e.g.
+// "A4 landscape"
+func (s Setup) CSS() string {
+	size := "A4"
+	if s.IsLetter() {
+		size = "letter"
+	}
+	if s.IsLandscape() {
+		return size + " " + Landscape
+	}
+	return size
+}
+
+// ExcelPaper returns the paper size code of Excel's page setup
+func (s Setup) ExcelPaper() int {
+	if s.IsLetter() {
+		return 1
+	}
+	return 9
+}
+
+// ExcelOrientation returns the orientation of Excel's page setup
+func (s Setup) ExcelOrientation() string {
+	if s.IsLandscape() {
+		return Landscape
+	}
+	return Portrait
+}
@@ -0,0 +1,189 @@
+package pii
+
+import (
+	"encoding/csv"
+	"pocket-doc/internal/i18n"
+	"pocket-doc/internal/model"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rules tag columns holding personal or sensitive data by name or comment.
+//
+// File format (YAML):
+//
+//	rules:
+//	  - name: ssn
+//	    level: RESTRICTED
+//	    column: "ssn|social_sec|주민"  # regex on the column name
+//	    comment: "주민등록번호"         # regex on the column comment
+//	  - name: email
+//	    level: CONFIDENTIAL
+//	    column: "e_?mail"
+//
+// Patterns are case-insensitive and match anywhere in the text; a rule with
+// both patterns matches when either does. Rules are tried in order and the
+// first match sets the column classification, unless the column already has
+// one (e.g. from an annotations file).
+type Rules struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule is one tagging rule
+type Rule struct {
+	Name    string `yaml:"name"`
+	Level   string `yaml:"level"`
+	Column  string `yaml:"column"`
+	Comment string `yaml:"comment"`
+
+	column, comment *regexp.Regexp
+}
+
+// Load reads and validates a rules file
+func Load(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PII rules file: %w", err)
+	}
+
+	var r Rules
+	if err := yaml.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse PII rules file: %w", err)
+	}
+
+	if err := r.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid PII rules file %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// Validate checks every rule and compiles its patterns
+func (r *Rules) Validate() error {
+	seen := make(map[string]bool)
+	for i := range r.Rules {
+		rule := &r.Rules[i]
+		switch {
+		case rule.Name == "":
+			return fmt.Errorf("rule %d: name is required", i+1)
+		case seen[rule.Name]:
+			return fmt.Errorf("rule %s: duplicate name", rule.Name)
+		case rule.Level == "":
+			return fmt.Errorf("rule %s: level is required", rule.Name)
+		case rule.Column == "" && rule.Comment == "":
+			return fmt.Errorf("rule %s: column or comment pattern is required", rule.Name)
+		}
+		seen[rule.Name] = true
+
+		var err error
+		if rule.column, err = compile(rule.Column); err != nil {
+			return fmt.Errorf("rule %s: column: %w", rule.Name, err)
+		}
+		if rule.comment, err = compile(rule.Comment); err != nil {
+			return fmt.Errorf("rule %s: comment: %w", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+func compile(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// matches reports whether the rule applies to c
+func (rule *Rule) matches(c *model.Column) bool {
+	return (rule.column != nil && rule.column.MatchString(c.Name)) ||
+		(rule.comment != nil && c.Comment != "" && rule.comment.MatchString(c.Comment))
+}
+
+// Apply tags the table and view columns of schema with the names of the
+// matching rules and returns the number of tagged columns
+func (r *Rules) Apply(schema *model.Schema) int {
+	tagged := 0
+	tag := func(columns []model.Column) {
+		for j := range columns {
+			c := &columns[j]
+			c.PII = nil
+			for i := range r.Rules {
+				rule := &r.Rules[i]
+				if !rule.matches(c) {
+					continue
+				}
+				c.PII = append(c.PII, rule.Name)
+				if c.Classification == "" {
+					c.Classification = rule.Level
+				}
+			}
+			if len(c.PII) > 0 {
+				tagged++
+			}
+		}
+	}
+	for i := range schema.Tables {
+		tag(schema.Tables[i].Columns)
+	}
+	for i := range schema.Views {
+		tag(schema.Views[i].Columns)
+	}
+	return tagged
+}
+
+// Entry is one row of the PII inventory
+type Entry struct {
+	Owner          string
+	Object         string // table or view
+	ObjectType     string // TABLE or VIEW
+	Column         string
+	DataType       string
+	Classification string
+	Tags           []string
+	Comment        string
+}
+
+// Inventory lists the columns tagged by rules or classified by annotations
+func Inventory(schema *model.Schema) []Entry {
+	var entries []Entry
+	add := func(owner, object, objectType string, columns []model.Column) {
+		for _, c := range columns {
+			if len(c.PII) == 0 && c.Classification == "" {
+				continue
+			}
+			entries = append(entries, Entry{
+				Owner: owner, Object: object, ObjectType: objectType, Column: c.Name,
+				DataType: c.DataType, Classification: c.Classification, Tags: c.PII, Comment: c.Comment,
+			})
+		}
+	}
+	for _, t := range schema.Tables {
+		add(t.Owner, t.Name, "TABLE", t.Columns)
+	}
+	for _, v := range schema.Views {
+		add(v.Owner, v.Name, "VIEW", v.Columns)
+	}
+	return entries
+}
+
+// WriteCSV writes the inventory as CSV with a UTF-8 byte order mark, so
+// spreadsheet applications open non-ASCII names correctly
+func WriteCSV(w io.Writer, entries []Entry, msg *i18n.Bundle) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		msg.T("label.owner"), msg.T("label.object"), msg.T("label.object_type"), msg.T("label.column"),
+		msg.T("label.data_type"), msg.T("label.classification"), msg.T("label.pii_tags"), msg.T("label.comment"),
+	})
+	for _, e := range entries {
+		cw.Write([]string{e.Owner, e.Object, e.ObjectType, e.Column, e.DataType, e.Classification, strings.Join(e.Tags, ", "), e.Comment})
+	}
+	cw.Flush()
+	return cw.Error()
+}
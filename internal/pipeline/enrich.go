@@ -0,0 +1,169 @@
+package pipeline
+
+import (
+	"context"
+	"pocket-doc/internal/annotation"
+	"pocket-doc/internal/combine"
+	"pocket-doc/internal/config"
+	"pocket-doc/internal/dbt"
+	"pocket-doc/internal/diff"
+	"pocket-doc/internal/domain"
+	"pocket-doc/internal/extractor"
+	"pocket-doc/internal/glossary"
+	"pocket-doc/internal/model"
+	"pocket-doc/internal/pii"
+	"pocket-doc/internal/tenant"
+	"fmt"
+)
+
+// Enrichment reports what Enrich applied
+type Enrichment struct {
+	TenantSchemas int      // schemas left out as copies of another (output.tenants)
+	GlossaryTerms int      // glossary terms linked to the schema
+	DbtDescribed  int      // tables and views described by output.dbt_manifest
+	Annotated     int      // annotations that matched an object
+	Unmatched     []string // annotation keys that matched no table, view or column
+	Domains       int      // tables and views assigned a domain by output.domains
+	Deselected    int      // tables and views outside output.include_domains
+	PIIColumns    int      // columns tagged by PII rules
+	Changed       int      // tables changed since output.since_snapshot
+}
+
+// Enrich collapses identical tenant schemas (output.tenants), then applies
+// the business glossary, the dbt descriptions, the annotations file, the
+// domain rules and the PII rules of the output section to schema, in that
+// order, so annotated domains and classifications take precedence over
+// rules. With output.include_domains,
+// objects of other domains are then dropped (see combine.SelectDomains).
+// With output.since_snapshot, tables and columns changed since that
+// snapshot are marked (see diff.Mark).
+func Enrich(cfg *config.Config, schema *model.Schema) (Enrichment, error) {
+	var e Enrichment
+	if t := cfg.Output.Tenants; t.Collapse {
+		e.TenantSchemas = tenant.Collapse(schema, tenant.Options{MinSchemas: t.MinSchemas, Match: t.Match})
+	}
+	if cfg.Output.GlossaryFile != "" {
+		g, err := glossary.Load(cfg.Output.GlossaryFile)
+		if err != nil {
+			return e, fmt.Errorf("failed to load glossary: %w", err)
+		}
+		g.Apply(schema)
+		e.GlossaryTerms = len(schema.Glossary)
+	}
+	if cfg.Output.DbtManifest != "" {
+		m, err := dbt.LoadManifest(cfg.Output.DbtManifest)
+		if err != nil {
+			return e, err
+		}
+		e.DbtDescribed = m.Apply(schema)
+	}
+	if cfg.Output.AnnotationsFile != "" {
+		a, err := annotation.Load(cfg.Output.AnnotationsFile)
+		if err != nil {
+			return e, fmt.Errorf("failed to load annotations: %w", err)
+		}
+		e.Unmatched = a.Apply(schema)
+		e.Annotated = a.Len() - len(e.Unmatched)
+	}
+	if len(cfg.Output.Domains) > 0 {
+		rules := make(domain.Rules, len(cfg.Output.Domains))
+		for i, r := range cfg.Output.Domains {
+			rules[i] = domain.Rule{Name: r.Name, Match: r.Match}
+		}
+		e.Domains = rules.Apply(schema)
+	}
+	if len(cfg.Output.IncludeDomains) > 0 {
+		before := len(schema.Tables) + len(schema.Views)
+		*schema = *combine.SelectDomains(schema, cfg.Output.IncludeDomains)
+		e.Deselected = before - len(schema.Tables) - len(schema.Views)
+	}
+	if cfg.Output.PIIRulesFile != "" {
+		rules, err := pii.Load(cfg.Output.PIIRulesFile)
+		if err != nil {
+			return e, fmt.Errorf("failed to load PII rules: %w", err)
+		}
+		e.PIIColumns = rules.Apply(schema)
+	}
+	if cfg.Output.SinceSnapshot != "" {
+		old, err := diff.LoadSnapshot(cfg.Output.SinceSnapshot)
+		if err != nil {
+			return e, err
+		}
+		e.Changed = diff.Mark(old, schema)
+	}
+	return e, nil
+}
+
+// SelectTables keeps the tables of extract.table_filter, with
+// extract.include_related their foreign key parents and children, and the
+// objects depending on them (see combine.SelectTables). It returns the
+// number of tables kept and of those added as related; without a filter
+// schema is left as it is.
+func SelectTables(cfg *config.Config, schema *model.Schema) (kept, related int) {
+	if len(cfg.Extract.TableFilter) == 0 {
+		return len(schema.Tables), 0
+	}
+	selected, related := combine.SelectTables(schema, cfg.Extract.TableFilter, cfg.Extract.IncludeRelated)
+	*schema = *selected
+	return len(schema.Tables), related
+}
+
+// Sample fills example values when extract.samples.enabled is set, using a
+// connected extractor. Classifications from the annotations file and PII
+// rules are applied first, so columns with a denied classification are
+// never read. ok is false when the database type does not support
+// sampling.
+func Sample(ctx context.Context, cfg *config.Config, ext extractor.DBExtractor, schema *model.Schema) (sampled int, ok bool, err error) {
+	samples := cfg.Extract.Samples
+	if !samples.Enabled {
+		return 0, true, nil
+	}
+	if err := classify(cfg, schema); err != nil {
+		return 0, true, err
+	}
+
+	sampled, ok, err = extractor.SampleColumns(ctx, ext, schema, extractor.SampleOptions{
+		MaxValues: samples.Values(),
+		MaxLength: samples.Length(),
+		Mask:      samples.Masked(),
+		Skip: func(t *model.Table, c *model.Column) bool {
+			return samples.Denied(t.Classification) || samples.Denied(c.Classification)
+		},
+		Logger: logger(cfg),
+	})
+	if err != nil {
+		return sampled, ok, fmt.Errorf("failed to sample example values: %w", err)
+	}
+	return sampled, ok, nil
+}
+
+// Migrations reads the applied Flyway and Liquibase migrations from the
+// history tables among the extracted tables of a connected extractor,
+// unless extract.exclude_migrations is set. ok is false when the database
+// type cannot read them.
+func Migrations(ctx context.Context, cfg *config.Config, ext extractor.DBExtractor, schema *model.Schema) (n int, ok bool, err error) {
+	if cfg.Extract.ExcludeMigrations {
+		return 0, true, nil
+	}
+	return extractor.ReadMigrations(ctx, ext, schema, logger(cfg))
+}
+
+// classify applies the annotations and PII rules ahead of sampling; Enrich
+// applies them again, with the same result, once databases are combined
+func classify(cfg *config.Config, schema *model.Schema) error {
+	if cfg.Output.AnnotationsFile != "" {
+		a, err := annotation.Load(cfg.Output.AnnotationsFile)
+		if err != nil {
+			return fmt.Errorf("failed to load annotations: %w", err)
+		}
+		a.Apply(schema)
+	}
+	if cfg.Output.PIIRulesFile != "" {
+		rules, err := pii.Load(cfg.Output.PIIRulesFile)
+		if err != nil {
+			return fmt.Errorf("failed to load PII rules: %w", err)
+		}
+		rules.Apply(schema)
+	}
+	return nil
+}
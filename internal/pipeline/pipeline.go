@@ -0,0 +1,267 @@
+// Package pipeline runs the steps shared by the CLI and pkg/pocketdoc:
+// creating and connecting the extractor within the configured time limits,
+// enriching the extracted schema and converting the configuration sections
+// to the settings of the exporters, uploaders and catalogs.
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"pocket-doc/internal/cache"
+	"pocket-doc/internal/catalog"
+	"pocket-doc/internal/config"
+	"pocket-doc/internal/credentials"
+	"pocket-doc/internal/datefmt"
+	"pocket-doc/internal/dbquery"
+	"pocket-doc/internal/exporter"
+	"pocket-doc/internal/extractor"
+	"pocket-doc/internal/fonts"
+	"pocket-doc/internal/i18n"
+	"pocket-doc/internal/lint"
+	"pocket-doc/internal/page"
+	"pocket-doc/internal/upload"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// DefaultConnectTimeout bounds connecting when database.timeout is zero
+const DefaultConnectTimeout = 30 * time.Second
+
+// Connect connects a new extractor, bounded by database.timeout
+func Connect(ctx context.Context, cfg *config.Config, ext extractor.DBExtractor) error {
+	timeout := time.Duration(cfg.Database.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = DefaultConnectTimeout
+	}
+	connectCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := ext.Connect(connectCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(connectCtx.Err(), context.DeadlineExceeded) {
+		return &dbquery.TimeoutError{Phase: "connecting", Limit: "database.timeout", Timeout: timeout, Err: err}
+	}
+	return err
+}
+
+// ExtractContext returns ctx bounded by extract.timeout, the budget of
+// reading the catalog; without it the extraction runs until done or ctx
+// ends. Example values are read afterwards and only bound by the caller's
+// context.
+func ExtractContext(ctx context.Context, cfg *config.Config) (context.Context, context.CancelFunc) {
+	if cfg.Extract.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(cfg.Extract.Timeout)*time.Second)
+}
+
+// ExtractError names extract.timeout in err when the extraction context
+// ended for exceeding it, as opposed to ctx ending
+func ExtractError(ctx, extractCtx context.Context, cfg *config.Config, err error) error {
+	if err == nil || ctx.Err() != nil || !errors.Is(extractCtx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return &dbquery.TimeoutError{Phase: "extraction", Limit: "extract.timeout", Timeout: time.Duration(cfg.Extract.Timeout) * time.Second, Err: err}
+}
+
+// FetchCredentials replaces the database user name and password with the
+// values from the configured secret store, if any
+func FetchCredentials(ctx context.Context, cfg *config.Config) error {
+	cc := cfg.Credentials
+	if cc.Provider == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	creds, err := credentials.Fetch(ctx, credentials.Config{
+		Provider:    cc.Provider,
+		Address:     cc.Address,
+		Secret:      cc.Secret,
+		Region:      cc.Region,
+		UsernameKey: cc.UsernameKey,
+		PasswordKey: cc.PasswordKey,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch credentials: %w", err)
+	}
+
+	if creds.Username != "" {
+		cfg.Database.Username = creds.Username
+	}
+	cfg.Database.Password = creds.Password
+	return nil
+}
+
+// LoadMessages loads the extra message catalogs and label overrides of the
+// output section. Catalogs are process-wide.
+func LoadMessages(cfg *config.Config) error {
+	if cfg.Output.LocaleDir != "" {
+		if err := i18n.LoadDir(cfg.Output.LocaleDir); err != nil {
+			return fmt.Errorf("failed to load locales: %w", err)
+		}
+	}
+	if cfg.Output.LabelFile != "" {
+		if err := i18n.LoadOverrides(cfg.Output.LabelFile, cfg.Output.Language); err != nil {
+			return fmt.Errorf("failed to load label overrides: %w", err)
+		}
+	}
+	return nil
+}
+
+// NewExtractor creates the database extractor for the configured
+// connection without connecting. An Oracle connection without a schema
+// filter fails with config.ErrNoSchemaFilter unless
+// database.schema_default is set (see config.DatabaseConfig.Schemas).
+// With extract.cache.dir, the extractor reuses the tables of previous runs
+// that have not changed since (see internal/cache).
+func NewExtractor(cfg *config.Config) (extractor.DBExtractor, error) {
+	return NewExtractorWithHooks(cfg, nil)
+}
+
+// NewExtractorWithHooks is NewExtractor with hooks observing the catalog
+// queries and extracted objects of the extractor (nil = none)
+func NewExtractorWithHooks(cfg *config.Config, hooks dbquery.Hooks) (extractor.DBExtractor, error) {
+	schemas, err := cfg.Database.Schemas()
+	if err != nil {
+		return nil, err
+	}
+	var objectCache *cache.Cache
+	if dir := cfg.Extract.Cache.Dir; dir != "" {
+		db := cfg.Database
+		namespace := fmt.Sprintf("%s://%s@%s:%d/%s", strings.ToLower(db.Type), db.Username, db.Host, db.Port, db.Database)
+		if objectCache, err = cache.Open(dir, namespace, cfg.Extract.Cache.MaxAge()); err != nil {
+			return nil, err
+		}
+	}
+
+	extractorConfig := extractor.Config{
+		Host:          cfg.Database.Host,
+		Port:          cfg.Database.Port,
+		Database:      cfg.Database.Database,
+		Username:      cfg.Database.Username,
+		Password:      cfg.Database.Password,
+		SSLMode:       cfg.Database.SSLMode,
+		SchemaFilter:  schemas,
+		ExcludeSystem: cfg.Extract.SkipSystem(),
+		Options:       cfg.Database.Options,
+		Logger:        logger(cfg),
+		QueryTimeout:  time.Duration(cfg.Extract.QueryTimeout) * time.Second,
+		Cache:         objectCache,
+		Hooks:         hooks,
+	}
+
+	ext, err := extractor.NewDBExtractor(cfg.Database.Type, extractorConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create extractor: %w", err)
+	}
+	return ext, nil
+}
+
+// ExporterConfig builds the exporter configuration from the output and
+// lint sections
+func ExporterConfig(cfg *config.Config) exporter.Config {
+	return exporter.Config{
+		Language:         cfg.Output.Language,
+		IncludeTOC:       cfg.Output.IncludeTOC,
+		IncludeCoverPage: cfg.Output.IncludeCoverPage,
+		CompanyName:      cfg.Output.CompanyName,
+		ProjectName:      cfg.Output.ProjectName,
+		Author:           cfg.Output.Author,
+		ColorScheme:      cfg.Output.ColorScheme,
+		Template:         cfg.Output.Template,
+		CSSFile:          cfg.Output.CSSFile,
+		PageBreak:        cfg.Output.PageBreak,
+		SortBy:           cfg.Output.SortBy,
+		RawOrder:         cfg.Output.RawOrder,
+		GroupBy:          cfg.Output.GroupBy,
+		Classification:   cfg.Output.Classification,
+		IncludeCoverage:  cfg.Output.IncludeCoverage,
+		IncludeQuality:   cfg.Extract.Verify,
+		IncludeCleanup:   cfg.Output.IncludeCleanup,
+		IncludeNaming:    cfg.Output.IncludeNaming,
+		IncludeImpact:    cfg.Output.IncludeImpact,
+		LineageDepth:     cfg.Output.LineageDepth,
+		IncludeERD:       cfg.Output.IncludeERD,
+		HideSingleSchema: cfg.Output.HideSingleSchema,
+		MinColumnWidth:   cfg.Output.MinColumnWidth,
+		MaxColumnWidth:   cfg.Output.MaxColumnWidth,
+		Font: fonts.Config{
+			Latin:     cfg.Output.Font.Latin,
+			EastAsian: cfg.Output.Font.EastAsian,
+			Fallback:  cfg.Output.Font.Fallback,
+		},
+		Dates:  Dates(cfg),
+		Page:   Page(cfg.Output.Page),
+		Lint:   LintRules(cfg.Lint),
+		Logger: logger(cfg),
+	}
+}
+
+// Page converts the output.page section to the paper of the exporters
+func Page(cfg config.PageConfig) page.Config {
+	p := page.Config{Setup: page.Setup{Size: cfg.Size, Orientation: cfg.Orientation}}
+	for format, o := range cfg.Formats {
+		if p.Formats == nil {
+			p.Formats = make(map[string]page.Setup)
+		}
+		p.Formats[strings.ToLower(format)] = page.Setup{Size: o.Size, Orientation: o.Orientation}
+	}
+	return p
+}
+
+// Upload converts the output.upload section to the uploader settings
+func Upload(cfg config.UploadConfig) upload.Config {
+	return upload.Config{
+		Provider: cfg.Provider,
+		Bucket:   cfg.Bucket,
+		Prefix:   cfg.Prefix,
+		Region:   cfg.Region,
+		Account:  cfg.Account,
+		Endpoint: cfg.Endpoint,
+		Presign:  time.Duration(cfg.Presign) * time.Second,
+	}
+}
+
+// Catalog converts the catalog section to the catalog pusher settings
+func Catalog(cfg config.CatalogConfig) catalog.Config {
+	return catalog.Config{
+		Type:     cfg.Type,
+		URL:      cfg.URL,
+		Token:    cfg.Token,
+		Service:  cfg.Service,
+		Platform: cfg.Platform,
+		Env:      cfg.Env,
+		Database: cfg.Database,
+		Timeout:  time.Duration(cfg.Timeout) * time.Second,
+	}
+}
+
+// Dates returns the time zone and layout of document timestamps from the
+// output section; an unknown time zone (rejected by Validate) is ignored
+func Dates(cfg *config.Config) datefmt.Config {
+	loc, _ := datefmt.LoadLocation(cfg.Output.Timezone)
+	return datefmt.Config{Location: loc, Layout: datefmt.Layout(cfg.Output.DateFormat)}
+}
+
+// LintRules converts the lint section of the configuration to lint rules
+func LintRules(cfg config.LintConfig) lint.Config {
+	return lint.Config{
+		TablePrefixes: cfg.TablePrefixes,
+		ViewPrefixes:  cfg.ViewPrefixes,
+		NameCase:      cfg.NameCase,
+		MaxNameLength: cfg.MaxNameLength,
+		ReservedWords: cfg.ReservedWords,
+		Severity:      cfg.Rules,
+	}
+}
+
+// logger returns the default logger, tagged with the connection profile
+// when one is used
+func logger(cfg *config.Config) *slog.Logger {
+	if cfg.Profile == "" {
+		return slog.Default()
+	}
+	return slog.Default().With("profile", cfg.Profile)
+}
@@ -0,0 +1,202 @@
+// Package tenant collapses the structurally identical schemas of
+// multi-tenant databases (tenant_001 ... tenant_500): each group is
+// documented once, through its first schema, and lists the schemas it
+// stands for (output.tenants).
+package tenant
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"pocket-doc/internal/model"
+	"sort"
+	"strings"
+)
+
+// Options of Collapse
+type Options struct {
+	MinSchemas int    // smallest group collapsed (0 = 2)
+	Match      string // glob of the schema names considered, case-insensitive (empty = all)
+}
+
+// Collapse groups the schemas (owners) of schema whose tables, views,
+// routines, sequences, triggers, synonyms and indexes are the same apart
+// from their owner, comments and statistics. Of each group of at least
+// MinSchemas it keeps the objects of the first schema and drops those of
+// the others, and records the group in schema.Tenants. It returns the
+// number of schemas dropped.
+func Collapse(schema *model.Schema, opts Options) int {
+	minSchemas := opts.MinSchemas
+	if minSchemas < 2 {
+		minSchemas = 2
+	}
+
+	lines := make(map[string][]string)
+	for _, owner := range owners(schema) {
+		if opts.Match != "" {
+			if ok, _ := path.Match(strings.ToLower(opts.Match), strings.ToLower(owner)); !ok {
+				continue
+			}
+		}
+		lines[owner] = nil
+	}
+	describe(schema, func(owner, line string) {
+		if l, ok := lines[owner]; ok {
+			lines[owner] = append(l, line)
+		}
+	})
+
+	byShape := make(map[[sha256.Size]byte][]string)
+	var shapes [][sha256.Size]byte
+	for owner, l := range lines {
+		sort.Strings(l)
+		shape := sha256.Sum256([]byte(strings.Join(l, "\n")))
+		if _, ok := byShape[shape]; !ok {
+			shapes = append(shapes, shape)
+		}
+		byShape[shape] = append(byShape[shape], owner)
+	}
+
+	dropped := make(map[string]bool)
+	for _, shape := range shapes {
+		group := byShape[shape]
+		if len(group) < minSchemas {
+			continue
+		}
+		sort.Strings(group)
+		schema.Tenants = append(schema.Tenants, model.TenantPattern{Pattern: pattern(group), Schema: group[0], Instances: group})
+		for _, owner := range group[1:] {
+			dropped[owner] = true
+		}
+	}
+	sort.Slice(schema.Tenants, func(i, j int) bool { return schema.Tenants[i].Schema < schema.Tenants[j].Schema })
+	if len(dropped) > 0 {
+		drop(schema, dropped)
+	}
+	return len(dropped)
+}
+
+// owners returns the non-empty owners of the objects of schema
+func owners(schema *model.Schema) []string {
+	seen := make(map[string]bool)
+	var out []string
+	add := func(owner string) {
+		if owner != "" && !seen[owner] {
+			seen[owner] = true
+			out = append(out, owner)
+		}
+	}
+	for _, t := range schema.Tables {
+		add(t.Owner)
+	}
+	for _, v := range schema.Views {
+		add(v.Owner)
+	}
+	for _, r := range schema.Routines {
+		add(r.Owner)
+	}
+	for _, s := range schema.Sequences {
+		add(s.Owner)
+	}
+	for _, t := range schema.Triggers {
+		add(t.Owner)
+	}
+	for _, s := range schema.Synonyms {
+		add(s.Owner)
+	}
+	return out
+}
+
+// describe passes a line per object, column and index of schema to emit,
+// with references to the owner itself left unqualified so that the lines
+// of identical schemas are equal
+func describe(schema *model.Schema, emit func(owner, line string)) {
+	index := func(owner string, idx model.Index) {
+		emit(owner, fmt.Sprintf("index %q %q %q %q %v %v", idx.TableName, idx.Name, idx.Type, idx.Columns, idx.IsUnique, idx.IsPrimary))
+	}
+	for _, t := range schema.Tables {
+		bare := unqualify(t.Owner)
+		emit(t.Owner, fmt.Sprintf("table %q %q", t.Name, t.Type))
+		for i, c := range t.Columns {
+			emit(t.Owner, fmt.Sprintf("column %q %d %q %q %d %d %d %v %q %v %v %q %q %v",
+				t.Name, i, c.Name, c.DataType, c.Length, c.Precision, c.Scale, c.Nullable, bare(c.DefaultValue),
+				c.IsPrimaryKey, c.IsUnique, bare(c.FKTargetTable), c.FKTargetColumn, c.IsAutoIncrement))
+		}
+		for _, idx := range t.Indexes {
+			index(t.Owner, idx)
+		}
+	}
+	for _, idx := range schema.Indexes {
+		index(idx.Owner, idx)
+	}
+	for _, v := range schema.Views {
+		emit(v.Owner, fmt.Sprintf("view %q %q", v.Name, v.Type))
+		for i, c := range v.Columns {
+			emit(v.Owner, fmt.Sprintf("view column %q %d %q %q", v.Name, i, c.Name, c.DataType))
+		}
+	}
+	for _, r := range schema.Routines {
+		bare := unqualify(r.Owner)
+		emit(r.Owner, fmt.Sprintf("routine %q %q %q %q", r.Name, r.Type, bare(r.Signature), r.ReturnType))
+	}
+	for _, s := range schema.Sequences {
+		emit(s.Owner, fmt.Sprintf("sequence %q %d %d %d %v", s.Name, s.MinValue, s.MaxValue, s.Increment, s.IsCyclic))
+	}
+	for _, t := range schema.Triggers {
+		emit(t.Owner, fmt.Sprintf("trigger %q %q %q %q %q %q", t.Name, unqualify(t.Owner)(t.TargetTable), t.Timing, t.Event, t.Level, t.Status))
+	}
+	for _, s := range schema.Synonyms {
+		target := s.TargetOwner + "." + s.TargetObject
+		if s.TargetOwner == s.Owner {
+			target = s.TargetObject
+		}
+		emit(s.Owner, fmt.Sprintf("synonym %q %q %v", s.Name, target, s.IsPublic))
+	}
+}
+
+// unqualify returns a function removing the qualification with owner
+// ("TENANT_001." or "\"tenant_001\".") from names and expressions
+func unqualify(owner string) func(string) string {
+	r := strings.NewReplacer(owner+".", "", `"`+owner+`".`, "")
+	return r.Replace
+}
+
+// pattern returns the common prefix of the names followed by "*", or ""
+// when they have none. Trailing digits are left out, so that tenant_001
+// to tenant_009 make "tenant_*".
+func pattern(names []string) string {
+	prefix := names[0]
+	for _, n := range names[1:] {
+		for !strings.HasPrefix(n, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	prefix = strings.TrimRight(prefix, "0123456789")
+	if prefix == "" {
+		return ""
+	}
+	return prefix + "*"
+}
+
+// drop removes the objects owned by the dropped schemas
+func drop(schema *model.Schema, dropped map[string]bool) {
+	schema.Tables = keep(schema.Tables, func(t model.Table) bool { return !dropped[t.Owner] })
+	schema.Views = keep(schema.Views, func(v model.View) bool { return !dropped[v.Owner] })
+	schema.Routines = keep(schema.Routines, func(r model.Routine) bool { return !dropped[r.Owner] })
+	schema.Sequences = keep(schema.Sequences, func(s model.Sequence) bool { return !dropped[s.Owner] })
+	schema.Triggers = keep(schema.Triggers, func(t model.Trigger) bool { return !dropped[t.Owner] })
+	schema.Synonyms = keep(schema.Synonyms, func(s model.Synonym) bool { return !dropped[s.Owner] })
+	schema.Indexes = keep(schema.Indexes, func(i model.Index) bool { return !dropped[i.Owner] })
+	schema.InvalidObjects = keep(schema.InvalidObjects, func(o model.ObjectRef) bool { return !dropped[o.Owner] })
+}
+
+// keep returns the items for which ok is true, in their order
+func keep[T any](items []T, ok func(T) bool) []T {
+	out := items[:0]
+	for _, item := range items {
+		if ok(item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
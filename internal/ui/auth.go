@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Auth holds the credentials WithAuth requires; empty fields disable that
+// scheme
+type Auth struct {
+	Username string // HTTP basic auth, with Password
+	Password string
+	Token    string // Authorization: Bearer <token>
+}
+
+// publicPaths are served without credentials so that orchestrators can
+// probe the server; they reveal nothing about the schema
+var publicPaths = map[string]bool{"/healthz": true, "/readyz": true}
+
+// WithAuth rejects requests without valid basic auth or bearer token
+// credentials with 401 Unauthorized, except for the health probes; without
+// credentials it returns h unchanged
+func WithAuth(h http.Handler, auth Auth) http.Handler {
+	if auth.Username == "" && auth.Token == "" {
+		return h
+	}
+	challenge := `Bearer realm="pocket-doc"`
+	if auth.Username != "" {
+		// Lets browsers prompt for the user name and password
+		challenge = `Basic realm="pocket-doc", charset="UTF-8"`
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !publicPaths[r.URL.Path] && !auth.allows(r) {
+			w.Header().Set("WWW-Authenticate", challenge)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// allows reports whether r carries the configured credentials
+func (a Auth) allows(r *http.Request) bool {
+	if a.Username != "" {
+		if user, pass, ok := r.BasicAuth(); ok {
+			// Compare both so the timing does not reveal which one differs
+			userOK := secretEqual(user, a.Username)
+			passOK := secretEqual(pass, a.Password)
+			if userOK && passOK {
+				return true
+			}
+		}
+	}
+	if a.Token != "" {
+		const prefix = "Bearer "
+		h := r.Header.Get("Authorization")
+		if len(h) > len(prefix) && strings.EqualFold(h[:len(prefix)], prefix) &&
+			secretEqual(strings.TrimSpace(h[len(prefix):]), a.Token) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretEqual compares secrets in constant time
+func secretEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// CSRFHeader must accompany the requests changing the server state (POST,
+// PUT and DELETE under /api). Browsers send custom headers to another
+// origin only after a CORS preflight the server never grants, so a page of
+// another site cannot make the browser of a signed-in user post them.
+const CSRFHeader = "X-Requested-With"
+
+// sameOrigin rejects cross-site requests to h with 403 Forbidden: those
+// without CSRFHeader and those a browser marks as sent from another site
+// (Sec-Fetch-Site)
+func sameOrigin(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(CSRFHeader) == "" {
+			http.Error(w, "Forbidden: missing "+CSRFHeader+" header", http.StatusForbidden)
+			return
+		}
+		switch r.Header.Get("Sec-Fetch-Site") {
+		case "", "same-origin", "none":
+		default:
+			http.Error(w, "Forbidden: cross-site request", http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	}
+}
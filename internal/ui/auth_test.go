@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestWithAuth validates basic auth and bearer tokens are required by every
+// endpoint but the health probes, and that a server without credentials is
+// left open
+func TestWithAuth(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	basic := func(user, pass string) func(*http.Request) {
+		return func(r *http.Request) { r.SetBasicAuth(user, pass) }
+	}
+	bearer := func(value string) func(*http.Request) {
+		return func(r *http.Request) { r.Header.Set("Authorization", value) }
+	}
+	none := func(*http.Request) {}
+
+	both := Auth{Username: "docs", Password: "s3cret", Token: "t0ken"}
+	tests := []struct {
+		name      string
+		auth      Auth
+		path      string
+		set       func(*http.Request)
+		status    int
+		challenge string
+	}{
+		{"no credentials configured", Auth{}, "/export/excel", none, http.StatusNoContent, ""},
+		{"basic accepted", Auth{Username: "docs", Password: "s3cret"}, "/", basic("docs", "s3cret"), http.StatusNoContent, ""},
+		{"basic wrong password", Auth{Username: "docs", Password: "s3cret"}, "/", basic("docs", "guess"), http.StatusUnauthorized, `Basic realm="pocket-doc", charset="UTF-8"`},
+		{"basic wrong user", Auth{Username: "docs", Password: "s3cret"}, "/", basic("admin", "s3cret"), http.StatusUnauthorized, `Basic realm="pocket-doc", charset="UTF-8"`},
+		{"basic missing", Auth{Username: "docs", Password: "s3cret"}, "/export/excel", none, http.StatusUnauthorized, `Basic realm="pocket-doc", charset="UTF-8"`},
+		{"bearer accepted", Auth{Token: "t0ken"}, "/", bearer("Bearer t0ken"), http.StatusNoContent, ""},
+		{"bearer scheme case-insensitive", Auth{Token: "t0ken"}, "/", bearer("bearer t0ken"), http.StatusNoContent, ""},
+		{"bearer wrong token", Auth{Token: "t0ken"}, "/", bearer("Bearer t0ke"), http.StatusUnauthorized, `Bearer realm="pocket-doc"`},
+		{"bearer without scheme", Auth{Token: "t0ken"}, "/", bearer("t0ken"), http.StatusUnauthorized, `Bearer realm="pocket-doc"`},
+		{"basic sent to token-only server", Auth{Token: "t0ken"}, "/", basic("docs", "t0ken"), http.StatusUnauthorized, `Bearer realm="pocket-doc"`},
+		{"either scheme: basic", both, "/", basic("docs", "s3cret"), http.StatusNoContent, ""},
+		{"either scheme: bearer", both, "/", bearer("Bearer t0ken"), http.StatusNoContent, ""},
+		{"either scheme: missing", both, "/", none, http.StatusUnauthorized, `Basic realm="pocket-doc", charset="UTF-8"`},
+		{"health probe", both, "/healthz", none, http.StatusNoContent, ""},
+		{"readiness probe", both, "/readyz", none, http.StatusNoContent, ""},
+		{"metrics", both, "/metrics", none, http.StatusUnauthorized, `Basic realm="pocket-doc", charset="UTF-8"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			tt.set(r)
+			w := httptest.NewRecorder()
+			WithAuth(ok, tt.auth).ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if got := w.Header().Get("WWW-Authenticate"); got != tt.challenge {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, tt.challenge)
+			}
+		})
+	}
+}
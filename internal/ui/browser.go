@@ -0,0 +1,25 @@
+package ui
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+// OpenBrowser opens url in the default browser. It returns once the
+// browser launcher has started, without waiting for the browser.
+func OpenBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	case "darwin":
+		cmd = exec.Command("open", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"fmt"
+	"net/http"
+	"pocket-doc/internal/model"
+	"strings"
+	"time"
+)
+
+// servedSchema returns the schema being served with its version
+func (s *Server) servedSchema() (*model.Schema, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.schema, s.version
+}
+
+// cached returns the schema a page or download is built from, after
+// setting its validators: a weak ETag of the extraction time and refresh
+// version, and Last-Modified from the extraction time. Browsers must
+// revalidate, so a re-extract is seen at once. When the client's copy is
+// current it answers 304 Not Modified and returns ok false.
+func (s *Server) cached(w http.ResponseWriter, r *http.Request) (schema *model.Schema, ok bool) {
+	schema, version := s.servedSchema()
+	etag := fmt.Sprintf(`W/"%x-%d"`, schema.ExtractedAt.UnixNano(), version)
+
+	h := w.Header()
+	h.Set("ETag", etag)
+	h.Set("Cache-Control", "no-cache")
+	if !schema.ExtractedAt.IsZero() {
+		h.Set("Last-Modified", schema.ExtractedAt.UTC().Format(http.TimeFormat))
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return schema, true
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if etagMatch(inm, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return schema, false
+		}
+		return schema, true
+	}
+	if ims, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !schema.ExtractedAt.IsZero() &&
+		!schema.ExtractedAt.Truncate(time.Second).After(ims) {
+		w.WriteHeader(http.StatusNotModified)
+		return schema, false
+	}
+	return schema, true
+}
+
+// etagMatch reports whether an If-None-Match header lists etag, using the
+// weak comparison
+func etagMatch(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestCached validates pages carry validators of the served extraction and
+// answer 304 Not Modified while the client's copy is current
+func TestCached(t *testing.T) {
+	_, h := newTestServer(t)
+	acceptGzip := map[string]string{"Accept-Encoding": "gzip"}
+
+	w := serve(h, http.MethodGet, "/", "", acceptGzip)
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" || w.Header().Get("Cache-Control") != "no-cache" {
+		t.Fatalf("GET / = %d, ETag %q, Cache-Control %q", w.Code, etag, w.Header().Get("Cache-Control"))
+	}
+	if lm := w.Header().Get("Last-Modified"); lm != "Mon, 02 Mar 2026 09:30:00 GMT" {
+		t.Errorf("Last-Modified = %q", lm)
+	}
+
+	lastModified := testSchema().ExtractedAt.Format(http.TimeFormat)
+	tests := []struct {
+		name   string
+		header map[string]string
+		status int
+	}{
+		{"matching ETag", map[string]string{"If-None-Match": etag, "Accept-Encoding": "gzip"}, http.StatusNotModified},
+		{"strong form of the ETag", map[string]string{"If-None-Match": `"x", ` + etag[2:]}, http.StatusNotModified},
+		{"other ETag", map[string]string{"If-None-Match": `W/"0-0"`}, http.StatusOK},
+		{"other ETag wins over the date", map[string]string{"If-None-Match": `W/"0-0"`, "If-Modified-Since": lastModified}, http.StatusOK},
+		{"not modified since", map[string]string{"If-Modified-Since": lastModified}, http.StatusNotModified},
+		{"modified since", map[string]string{"If-Modified-Since": testSchema().ExtractedAt.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := serve(h, http.MethodGet, "/", "", tt.header)
+		if w.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.status)
+		}
+		if w.Code == http.StatusNotModified && (w.Body.Len() != 0 || w.Header().Get("Content-Encoding") != "") {
+			t.Errorf("%s: 304 with %d bytes, Content-Encoding %q", tt.name, w.Body.Len(), w.Header().Get("Content-Encoding"))
+		}
+	}
+
+	// A re-extract of the same extraction time is a new version
+	if w := serve(h, http.MethodPost, "/api/refresh", "", map[string]string{CSRFHeader: "pocket-doc"}); w.Code != http.StatusOK {
+		t.Fatalf("refresh: status %d", w.Code)
+	}
+	w = serve(h, http.MethodGet, "/", "", map[string]string{"If-None-Match": etag})
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("after a refresh: status %d, ETag %q", w.Code, w.Header().Get("ETag"))
+	}
+}
@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"pocket-doc/internal/comments"
+	"sort"
+	"sync"
+)
+
+// maxCommentEditSize bounds the request body of a comment edit
+const maxCommentEditSize = 64 << 10
+
+// commentKey identifies the table (column "") or column of a comment edit
+type commentKey struct {
+	owner, table, column string
+}
+
+// commentEdits holds the comment edits made in the preview. They are only
+// turned into a script; nothing is written to the database.
+type commentEdits struct {
+	mu    sync.Mutex
+	edits map[commentKey]comments.Edit
+}
+
+// list returns the edits ordered by table and column
+func (c *commentEdits) list() []comments.Edit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	list := make([]comments.Edit, 0, len(c.edits))
+	for _, e := range c.edits {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Owner != b.Owner {
+			return a.Owner < b.Owner
+		}
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		return a.Column < b.Column
+	})
+	return list
+}
+
+// handleComments answers GET /api/comments with the pending edits
+func (s *Server) handleComments(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.comments.list())
+}
+
+// handleEditComment records the edit in the request body. An edit that
+// restores the documented comment is dropped.
+func (s *Server) handleEditComment(w http.ResponseWriter, r *http.Request) {
+	var e comments.Edit
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxCommentEditSize)).Decode(&e); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid comment edit: %v", err), http.StatusBadRequest)
+		return
+	}
+	current, err := e.Current(s.current())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	key := commentKey{e.Owner, e.Table, e.Column}
+	s.comments.mu.Lock()
+	if e.Comment == current {
+		delete(s.comments.edits, key)
+	} else {
+		s.comments.edits[key] = e
+	}
+	s.comments.mu.Unlock()
+
+	writeJSON(w, s.comments.list())
+}
+
+// handleResetComments discards all pending edits
+func (s *Server) handleResetComments(w http.ResponseWriter, r *http.Request) {
+	s.comments.mu.Lock()
+	clear(s.comments.edits)
+	s.comments.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleCommentScript downloads the SQL script applying the pending edits
+func (s *Server) handleCommentScript(w http.ResponseWriter, r *http.Request) {
+	schema := s.current()
+	script, err := comments.Script(schema, s.comments.list())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/sql; charset=utf-8")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"%s_comments.sql\"", schema.DatabaseName))
+	fmt.Fprint(w, script)
+}
+
+// writeJSON answers with v encoded as JSON
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"fmt"
+	"net/http"
+	"pocket-doc/internal/diff"
+	"pocket-doc/internal/model"
+)
+
+// maxSnapshotSize bounds an uploaded comparison snapshot
+const maxSnapshotSize = 64 << 20
+
+// SetBaseline sets the snapshot the served schema is compared with on
+// /compare; nil clears it. A baseline can also be uploaded from the page.
+func (s *Server) SetBaseline(schema *model.Schema) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.baseline = schema
+}
+
+// comparison returns the baseline and the schema being served
+func (s *Server) comparison() (baseline, schema *model.Schema) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.baseline, s.schema
+}
+
+// comparePage is the data of the comparison page; Result is nil when no
+// baseline is loaded
+type comparePage struct {
+	Schema                  *model.Schema
+	Result                  *diff.Result
+	Objects                 []compareObject
+	Added, Removed, Changed int
+}
+
+// compareObject groups the changes of one table or view. Type is Added or
+// Removed when the object itself is, else Changed; Changes then lists the
+// column, index and attribute changes.
+type compareObject struct {
+	Type    diff.ChangeType
+	Object  string
+	Owner   string
+	Name    string
+	Path    string // detail page in the served schema; "" for views and removed tables
+	Changes []diff.Change
+}
+
+// newComparePage compares baseline with schema and groups the changes by
+// table, keeping the engine's order
+func newComparePage(baseline, schema *model.Schema) comparePage {
+	p := comparePage{Schema: schema}
+	if baseline == nil {
+		return p
+	}
+
+	p.Result = diff.Compare(baseline, schema)
+	p.Added, p.Removed, p.Changed = p.Result.Count(diff.Added), p.Result.Count(diff.Removed), p.Result.Count(diff.Changed)
+
+	index := make(map[string]int)
+	for _, c := range p.Result.Changes {
+		key := c.Owner + "\x00" + c.Table
+		i, ok := index[key]
+		if !ok {
+			i = len(p.Objects)
+			index[key] = i
+			p.Objects = append(p.Objects, compareObject{Type: diff.Changed, Object: diff.ObjectView, Owner: c.Owner, Name: c.Table})
+		}
+
+		o := &p.Objects[i]
+		if c.Name == "" && c.Type != diff.Changed {
+			o.Type, o.Object = c.Type, c.Object
+			continue
+		}
+		o.Changes = append(o.Changes, c)
+	}
+
+	// Names in changes come from the served schema unless the object was
+	// removed; column and index changes do not tell tables from views
+	for i := range p.Objects {
+		o := &p.Objects[i]
+		if o.Type == diff.Removed {
+			continue
+		}
+		if t := findTable(schema, o.Owner, o.Name); t != nil {
+			o.Object, o.Path = diff.ObjectTable, tablePath(*t)
+		}
+	}
+	return p
+}
+
+// handleCompare renders the comparison of the baseline snapshot with the
+// served schema, or the upload form when there is no baseline
+func (s *Server) handleCompare(w http.ResponseWriter, r *http.Request) {
+	baseline, schema := s.comparison()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.template.ExecuteTemplate(w, "compare.html", newComparePage(baseline, schema)); err != nil {
+		http.Error(w, fmt.Sprintf("Template error: %v", err), http.StatusInternalServerError)
+		return
+	}
+}
+
+// compareResult is the JSON answer of POST /api/compare
+type compareResult struct {
+	Baseline diff.Snapshot `json:"baseline"`
+	Added    int           `json:"added"`
+	Removed  int           `json:"removed"`
+	Changed  int           `json:"changed"`
+}
+
+// handleUploadBaseline loads the JSON snapshot in the request body as the
+// comparison baseline
+func (s *Server) handleUploadBaseline(w http.ResponseWriter, r *http.Request) {
+	baseline, err := diff.ReadSnapshot(http.MaxBytesReader(w, r.Body, maxSnapshotSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid snapshot: %v", err), http.StatusBadRequest)
+		return
+	}
+	s.SetBaseline(baseline)
+
+	p := newComparePage(baseline, s.current())
+	writeJSON(w, compareResult{Baseline: p.Result.Old, Added: p.Added, Removed: p.Removed, Changed: p.Changed})
+}
+
+// handleClearBaseline drops the comparison baseline
+func (s *Server) handleClearBaseline(w http.ResponseWriter, r *http.Request) {
+	s.SetBaseline(nil)
+	w.WriteHeader(http.StatusNoContent)
+}
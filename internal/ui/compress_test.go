@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWithGzip validates text responses are compressed for clients that
+// accept gzip, and that other responses pass through unchanged
+func TestWithGzip(t *testing.T) {
+	page := strings.Repeat("<p>orders</p>", 100)
+	h := WithGzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/xlsx":
+			w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		case "/304":
+			w.WriteHeader(http.StatusNotModified)
+			return
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		}
+		io.WriteString(w, page)
+	}))
+
+	tests := []struct {
+		name, method, path, accept string
+		gzipped                    bool
+	}{
+		{"page", http.MethodGet, "/", "gzip, deflate, br", true},
+		{"no Accept-Encoding", http.MethodGet, "/", "", false},
+		{"gzip refused", http.MethodGet, "/", "gzip;q=0, br", false},
+		{"other codings only", http.MethodGet, "/", "br, zstd", false},
+		{"zip archive", http.MethodGet, "/xlsx", "gzip", false},
+		{"HEAD", http.MethodHead, "/", "gzip", false},
+		{"not modified", http.MethodGet, "/304", "gzip", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept-Encoding", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if vary := w.Header().Get("Vary"); vary != "Accept-Encoding" {
+				t.Errorf("Vary = %q", vary)
+			}
+			if got := w.Header().Get("Content-Encoding") == "gzip"; got != tt.gzipped {
+				t.Fatalf("Content-Encoding = %q, want gzip %v", w.Header().Get("Content-Encoding"), tt.gzipped)
+			}
+			body := w.Body.String()
+			if tt.gzipped {
+				zr, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				b, err := io.ReadAll(zr)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(w.Body.Bytes()) >= len(page) {
+					t.Errorf("compressed body of %d bytes for %d", w.Body.Len(), len(page))
+				}
+				body = string(b)
+			}
+			switch {
+			case tt.path == "/304":
+				if body != "" {
+					t.Errorf("body = %q, want none", body)
+				}
+			case tt.method == http.MethodGet && body != page:
+				t.Errorf("body = %q, want the page", body)
+			}
+		})
+	}
+}
+
+// TestWithGzipEventStream validates server-sent events reach the client as
+// they are flushed, uncompressed
+func TestWithGzipEventStream(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(WithGzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: refreshed\n\n")
+		http.NewResponseController(w).Flush()
+		<-release
+	})))
+	defer srv.Close()
+	defer close(release)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if enc := resp.Header.Get("Content-Encoding"); enc != "" {
+		t.Errorf("Content-Encoding = %q", enc)
+	}
+
+	line := make(chan string, 1)
+	go func() {
+		l, _ := bufio.NewReader(resp.Body).ReadString('\n')
+		line <- l
+	}()
+	select {
+	case l := <-line:
+		if l != "data: refreshed\n" {
+			t.Errorf("event = %q", l)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event held back until the response ends")
+	}
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"pocket-doc/internal/model"
+)
+
+// noOwner stands for an empty owner in detail page URLs
+const noOwner = "-"
+
+// tablePath returns the detail page URL of a table: /tables/{owner}/{name}
+func tablePath(t model.Table) string {
+	owner := t.Owner
+	if owner == "" {
+		owner = noOwner
+	}
+	return "/tables/" + url.PathEscape(owner) + "/" + url.PathEscape(t.Name)
+}
+
+// tableDetail is the data of a table detail page
+type tableDetail struct {
+	Schema       *model.Schema
+	Table        *model.Table
+	References   []tableLink // foreign keys of this table
+	ReferencedBy []tableLink // foreign keys of other tables pointing here
+	Triggers     []model.Trigger
+}
+
+// tableLink is one foreign key column shown on a detail page
+type tableLink struct {
+	Path         string // detail page of the other table; "" when it is not documented
+	TableName    string // the other table
+	Column       string // referencing column
+	TargetColumn string // referenced column
+}
+
+// handleTable renders the detail page of one table
+func (s *Server) handleTable(w http.ResponseWriter, r *http.Request) {
+	owner, name := r.PathValue("owner"), r.PathValue("name")
+	if owner == noOwner {
+		owner = ""
+	}
+
+	schema, ok := s.cached(w, r)
+	if !ok {
+		return
+	}
+	t := findTable(schema, owner, name)
+	if t == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.template.ExecuteTemplate(w, "table.html", newTableDetail(schema, t)); err != nil {
+		http.Error(w, fmt.Sprintf("Template error: %v", err), http.StatusInternalServerError)
+		return
+	}
+}
+
+// findTable returns the table with the given owner and name, or nil
+func findTable(schema *model.Schema, owner, name string) *model.Table {
+	for i := range schema.Tables {
+		if t := &schema.Tables[i]; t.Owner == owner && t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// newTableDetail collects the foreign keys in and out of t and its triggers
+func newTableDetail(schema *model.Schema, t *model.Table) tableDetail {
+	d := tableDetail{Schema: schema, Table: t}
+	graph := model.NewGraph(schema)
+
+	for _, r := range graph.Outbound(t) {
+		link := tableLink{TableName: r.ParentName, Column: r.Column, TargetColumn: r.ParentColumn}
+		if r.Parent != nil {
+			link.Path = tablePath(*r.Parent)
+		}
+		d.References = append(d.References, link)
+	}
+
+	for _, r := range graph.Inbound(t) {
+		d.ReferencedBy = append(d.ReferencedBy, tableLink{
+			Path:         tablePath(*r.Child),
+			TableName:    r.Child.Name,
+			Column:       r.Column,
+			TargetColumn: r.ParentColumn,
+		})
+	}
+
+	for _, tr := range schema.Triggers {
+		if tr.TargetTable == t.Name && (tr.Owner == "" || tr.Owner == t.Owner) {
+			d.Triggers = append(d.Triggers, tr)
+		}
+	}
+	return d
+}
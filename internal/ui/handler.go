@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"net/http"
+	"pocket-doc/internal/exporter"
+	"pocket-doc/internal/model"
+	"strings"
+)
+
+// Options configure Handler
+type Options struct {
+	Config    exporter.Config
+	BasePath  string    // path the handler is mounted at, e.g. "/admin/schema"; "" for the root
+	Auth      Auth      // credentials for every page but the health probes; none by default
+	Refresher Refresher // enables the re-extract button and POST /api/refresh
+	PageSize  int       // table sections per preview page; 0 = DefaultPageSize, -1 = all
+}
+
+// Handler returns the schema browser for mounting in another Go service:
+//
+//	h, err := ui.Handler(schema, ui.Options{Config: cfg, BasePath: "/admin/schema"})
+//	mux.Handle("/admin/schema/", h)
+//
+// Use NewServer and Server.Handler instead to refresh the schema on a
+// schedule or end the live update streams on shutdown (Server.Close).
+func Handler(schema *model.Schema, opts Options) (http.Handler, error) {
+	s, err := NewServer(schema, opts.Config)
+	if err != nil {
+		return nil, err
+	}
+	s.SetBasePath(opts.BasePath)
+	s.SetPageSize(opts.PageSize)
+	if opts.Refresher != nil {
+		s.SetRefresher(opts.Refresher)
+	}
+	return s.Handler(opts.Auth), nil
+}
+
+// SetBasePath sets the path the server is mounted at ("/admin/schema");
+// links in pages and search results start with it. Call it before serving.
+func (s *Server) SetBasePath(path string) {
+	s.basePath = "/" + strings.Trim(path, "/")
+	if s.basePath == "/" {
+		s.basePath = ""
+	}
+}
+
+// Handler returns the routes of s with compression and auth, expecting
+// request paths under the base path
+func (s *Server) Handler(auth Auth) http.Handler {
+	mux := http.NewServeMux()
+	s.RegisterRoutes(mux)
+	h := WithAuth(WithGzip(mux), auth)
+	if s.basePath == "" {
+		return h
+	}
+
+	base := s.basePath
+	stripped := http.StripPrefix(base, h)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == base {
+			http.Redirect(w, r, base+"/", http.StatusMovedPermanently)
+			return
+		}
+		stripped.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// metrics counts extractions and exports for GET /metrics
+type metrics struct {
+	mu           sync.Mutex
+	extractions  map[string]int // by result: success, failure
+	lastDuration time.Duration  // of the last extraction, successful or not
+	lastSuccess  time.Time
+	exports      map[exportKey]int
+}
+
+// exportKey labels an export counter
+type exportKey struct {
+	format string
+	result string
+}
+
+func newMetrics() *metrics {
+	return &metrics{extractions: make(map[string]int), exports: make(map[exportKey]int)}
+}
+
+// result returns the metric label of err
+func result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+// ObserveExtraction records an extraction of the served schema that took d,
+// for extractions made outside the server (e.g. the initial one); refreshes
+// are recorded automatically
+func (s *Server) ObserveExtraction(d time.Duration, err error) {
+	m := s.metrics
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.extractions[result(err)]++
+	m.lastDuration = d
+	if err == nil {
+		m.lastSuccess = time.Now()
+	}
+}
+
+func (m *metrics) observeExport(format string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.exports[exportKey{format, result(err)}]++
+}
+
+// handleHealthz reports that the process is serving
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "ok")
+}
+
+// handleReadyz reports whether a schema is loaded; it fails once shutdown
+// has started so that load balancers stop sending requests
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	select {
+	case <-s.live.done:
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	default:
+	}
+	if s.current() == nil {
+		http.Error(w, "no schema loaded", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+// handleMetrics writes the metrics in the Prometheus text exposition format
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	schema := s.current()
+	m := s.metrics
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metric(w, "pocketdoc_extractions_total", "counter", "Schema extractions by result.")
+	for _, res := range []string{"success", "failure"} {
+		fmt.Fprintf(w, "pocketdoc_extractions_total{result=%q} %d\n", res, m.extractions[res])
+	}
+
+	metric(w, "pocketdoc_extraction_duration_seconds", "gauge", "Duration of the last extraction.")
+	fmt.Fprintf(w, "pocketdoc_extraction_duration_seconds %g\n", m.lastDuration.Seconds())
+
+	metric(w, "pocketdoc_last_success_timestamp_seconds", "gauge", "Unix time of the last successful extraction (0 if none).")
+	var last int64
+	if !m.lastSuccess.IsZero() {
+		last = m.lastSuccess.Unix()
+	}
+	fmt.Fprintf(w, "pocketdoc_last_success_timestamp_seconds %d\n", last)
+
+	if schema != nil {
+		metric(w, "pocketdoc_objects", "gauge", "Documented objects in the served schema by type.")
+		for _, o := range []struct {
+			kind  string
+			count int
+		}{
+			{"table", len(schema.Tables)},
+			{"view", len(schema.Views)},
+			{"routine", len(schema.Routines)},
+			{"sequence", len(schema.Sequences)},
+			{"trigger", len(schema.Triggers)},
+			{"synonym", len(schema.Synonyms)},
+		} {
+			fmt.Fprintf(w, "pocketdoc_objects{type=%q} %d\n", o.kind, o.count)
+		}
+	}
+
+	metric(w, "pocketdoc_exports_total", "counter", "Downloads from /export by format and result.")
+	keys := make([]exportKey, 0, len(m.exports))
+	for k := range m.exports {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].format != keys[j].format {
+			return keys[i].format < keys[j].format
+		}
+		return keys[i].result < keys[j].result
+	})
+	for _, k := range keys {
+		fmt.Fprintf(w, "pocketdoc_exports_total{format=%q,result=%q} %d\n", k.format, k.result, m.exports[k])
+	}
+}
+
+// metric writes the HELP and TYPE lines of a metric
+func metric(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
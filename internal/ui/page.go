@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"math"
+	"net/http"
+	"pocket-doc/internal/model"
+	"strconv"
+)
+
+// DefaultPageSize is the number of table sections on a preview page
+const DefaultPageSize = 50
+
+// SetPageSize sets how many table sections a preview page renders: 0 uses
+// DefaultPageSize, a negative size puts every table on one page. Call it
+// before serving.
+func (s *Server) SetPageSize(size int) {
+	if size == 0 {
+		size = DefaultPageSize
+	}
+	s.pageSize = size
+}
+
+// previewPage is one page of the preview. The sidebar lists every object;
+// the overview is rendered on the first page, the table sections of the
+// page in between, and routines and other objects on the last page, so a
+// page stays small however many tables the schema has.
+type previewPage struct {
+	*model.Schema
+	Page       int           // 1-based
+	Pages      int           // at least 1
+	PageTables []model.Table // table sections of this page
+	Namer      model.Namer   // display names, owner-qualified (see Config.HideSingleSchema)
+	size       int           // tables per page; <= 0 for all
+}
+
+// newPreviewPage returns page of schema; out of range pages are clamped,
+// so a reload after a refresh that removed tables still shows a page
+func newPreviewPage(schema *model.Schema, page, size int, namer model.Namer) previewPage {
+	p := previewPage{Schema: schema, Pages: 1, Namer: namer, size: size}
+	if size > 0 && len(schema.Tables) > size {
+		p.Pages = (len(schema.Tables) + size - 1) / size
+	}
+	p.Page = min(max(page, 1), p.Pages)
+
+	if p.Pages == 1 {
+		p.PageTables = schema.Tables
+		return p
+	}
+	first := (p.Page - 1) * size
+	p.PageTables = schema.Tables[first:min(first+size, len(schema.Tables))]
+	return p
+}
+
+// TablePage returns the page with the section of the i-th table
+func (p previewPage) TablePage(i int) int {
+	if p.Pages == 1 {
+		return 1
+	}
+	return i/p.size + 1
+}
+
+// First reports whether this is the first page (with the overview)
+func (p previewPage) First() bool { return p.Page == 1 }
+
+// Last reports whether this is the last page (with routines and other objects)
+func (p previewPage) Last() bool { return p.Page == p.Pages }
+
+// Prev returns the previous page number, or 0 on the first page
+func (p previewPage) Prev() int {
+	if p.First() {
+		return 0
+	}
+	return p.Page - 1
+}
+
+// Next returns the next page number, or 0 on the last page
+func (p previewPage) Next() int {
+	if p.Last() {
+		return 0
+	}
+	return p.Page + 1
+}
+
+// FirstTable and LastTable return the 1-based positions of the tables on
+// this page, for "tables 51-100 of 1200"
+func (p previewPage) FirstTable() int {
+	switch {
+	case len(p.PageTables) == 0:
+		return 0
+	case p.Pages == 1:
+		return 1
+	}
+	return (p.Page-1)*p.size + 1
+}
+
+func (p previewPage) LastTable() int {
+	return p.FirstTable() + len(p.PageTables) - 1
+}
+
+// sectionID returns the element id of the section of an object ("table",
+// "view" or "routine"). Ids are owner-qualified, as objects of different
+// schemas can share a name.
+func sectionID(kind, owner, name string) string {
+	return kind + "-" + model.QualifiedName(owner, name)
+}
+
+// lastPage is the page parameter "last", clamped to the last page; links
+// to routines use it as they do not know the page size
+const lastPage = math.MaxInt
+
+// pageParam returns the page query parameter, 1 when missing or invalid
+func pageParam(r *http.Request) int {
+	value := r.URL.Query().Get("page")
+	if value == "last" {
+		return lastPage
+	}
+	page, err := strconv.Atoi(value)
+	if err != nil {
+		return 1
+	}
+	return page
+}
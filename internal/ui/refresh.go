@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"pocket-doc/internal/exporter"
+	"pocket-doc/internal/model"
+	"sync"
+	"time"
+)
+
+// Refresher re-extracts the schema for POST /api/refresh
+type Refresher func(ctx context.Context) (*model.Schema, error)
+
+// SetRefresher enables POST /api/refresh, the re-extract button and
+// RefreshEvery; call it before serving
+func (s *Server) SetRefresher(r Refresher) {
+	s.refresh = r
+}
+
+// Close ends the /api/events streams so that a graceful shutdown does not
+// wait for open pages, and makes /readyz fail
+func (s *Server) Close() {
+	s.live.close()
+}
+
+// refreshResult is the JSON answer of POST /api/refresh
+type refreshResult struct {
+	Version     int       `json:"version"`
+	ExtractedAt time.Time `json:"extractedAt"`
+	Tables      int       `json:"tables"`
+	Views       int       `json:"views"`
+	Routines    int       `json:"routines"`
+}
+
+// errRefreshRunning is returned by reextract while another refresh runs
+var errRefreshRunning = errors.New("a refresh is already running")
+
+// reextract re-extracts the schema, swaps it in and tells open pages to
+// reload. Only one extraction runs at a time; others get errRefreshRunning.
+func (s *Server) reextract(ctx context.Context) (*model.Schema, int, error) {
+	if !s.refreshing.TryLock() {
+		return nil, 0, errRefreshRunning
+	}
+	defer s.refreshing.Unlock()
+
+	start := time.Now()
+	schema, err := s.refresh(ctx)
+	s.ObserveExtraction(time.Since(start), err)
+	if err != nil {
+		return nil, 0, err
+	}
+	schema = exporter.OrderSchema(schema, s.config)
+
+	s.mu.Lock()
+	s.schema = schema
+	s.version++
+	version := s.version
+	s.mu.Unlock()
+	s.live.publish(version)
+	return schema, version, nil
+}
+
+// RefreshEvery re-extracts the schema every interval until ctx is canceled,
+// for a preview that runs as a service. Failures are logged and the
+// previous schema stays in place.
+func (s *Server) RefreshEvery(ctx context.Context, interval time.Duration) {
+	if s.refresh == nil || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, _, err := s.reextract(ctx); err != nil && ctx.Err() == nil {
+				slog.Warn("scheduled refresh failed", "error", err)
+			}
+		}
+	}
+}
+
+// handleRefresh re-extracts the schema on request; 409 Conflict while
+// another refresh runs
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	if s.refresh == nil {
+		http.Error(w, "Refresh is not enabled", http.StatusNotFound)
+		return
+	}
+
+	schema, version, err := s.reextract(r.Context())
+	if errors.Is(err, errRefreshRunning) {
+		http.Error(w, "A refresh is already running", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Extraction failed: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	writeJSON(w, refreshResult{
+		Version:     version,
+		ExtractedAt: schema.ExtractedAt,
+		Tables:      len(schema.Tables),
+		Views:       len(schema.Views),
+		Routines:    len(schema.Routines),
+	})
+}
+
+// eventKeepAlive is the interval of comment lines that keep idle event
+// streams open through proxies
+const eventKeepAlive = 30 * time.Second
+
+// handleEvents streams server-sent events: a "refresh" event carrying the
+// new version after every refresh
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	ch, done := s.live.subscribe()
+	defer s.live.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(eventKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case version := <-ch:
+			fmt.Fprintf(w, "event: refresh\ndata: %d\n\n", version)
+		case <-ticker.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-r.Context().Done():
+			return
+		case <-done:
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// liveUpdates fans refresh versions out to the event streams
+type liveUpdates struct {
+	mu          sync.Mutex
+	subscribers map[chan int]struct{}
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+func newLiveUpdates() *liveUpdates {
+	return &liveUpdates{subscribers: make(map[chan int]struct{}), done: make(chan struct{})}
+}
+
+// subscribe returns a channel of versions and a channel closed by close
+func (l *liveUpdates) subscribe() (chan int, <-chan struct{}) {
+	ch := make(chan int, 1)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.subscribers[ch] = struct{}{}
+	return ch, l.done
+}
+
+func (l *liveUpdates) unsubscribe(ch chan int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.subscribers, ch)
+}
+
+// publish sends version to every subscriber without blocking; a slow
+// subscriber with an unread version keeps that one, which still triggers
+// its reload
+func (l *liveUpdates) publish(version int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for ch := range l.subscribers {
+		select {
+		case ch <- version:
+		default:
+		}
+	}
+}
+
+func (l *liveUpdates) close() {
+	l.closeOnce.Do(func() { close(l.done) })
+}
@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"net/http"
+	"net/url"
+	"pocket-doc/internal/model"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// searchLimit caps the results returned per group
+const searchLimit = 50
+
+// Search result kinds, in response order
+const (
+	kindTable   = "table"
+	kindColumn  = "column"
+	kindRoutine = "routine"
+)
+
+// searchResponse is the JSON answer of /api/search
+type searchResponse struct {
+	Query  string        `json:"query"`
+	Groups []searchGroup `json:"groups"`
+}
+
+// searchGroup holds the best matches of one kind; Total counts all of them
+type searchGroup struct {
+	Kind    string         `json:"kind"`
+	Total   int            `json:"total"`
+	Results []searchResult `json:"results"`
+}
+
+// searchResult is one matching object
+type searchResult struct {
+	Name    string `json:"name"`
+	Owner   string `json:"owner,omitempty"`
+	Table   string `json:"table,omitempty"`   // table of a column
+	Comment string `json:"comment,omitempty"` // comment of the object
+	URL     string `json:"url"`
+	rank    int
+}
+
+// handleSearch answers /api/search?q=... with matching tables, columns and
+// routines grouped by kind. Every word of the query must occur in the
+// object's name or comment, ignoring case.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	schema, ok := s.cached(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query().Get("q")
+	resp := searchResponse{Query: q, Groups: []searchGroup{}}
+	if terms := strings.Fields(normalize(q)); len(terms) > 0 {
+		resp.Groups = search(schema, terms)
+	}
+	for _, g := range resp.Groups {
+		for i := range g.Results {
+			g.Results[i].URL = s.basePath + g.Results[i].URL
+		}
+	}
+
+	writeJSON(w, resp)
+}
+
+// search matches normalized terms against the schema
+func search(schema *model.Schema, terms []string) []searchGroup {
+	var tables, columns, routines []searchResult
+
+	for _, t := range schema.Tables {
+		path := tablePath(t)
+		if rank, ok := match(terms, t.Name, t.Comment); ok {
+			tables = append(tables, searchResult{Name: t.Name, Owner: t.Owner, Comment: t.Comment, URL: path, rank: rank})
+		}
+		for _, c := range t.Columns {
+			if rank, ok := match(terms, c.Name, c.Comment); ok {
+				columns = append(columns, searchResult{
+					Name: c.Name, Owner: t.Owner, Table: t.Name, Comment: c.Comment,
+					URL: path + "#col-" + c.Name, rank: rank,
+				})
+			}
+		}
+	}
+	for _, rt := range schema.Routines {
+		if rank, ok := match(terms, rt.Name, rt.Comment); ok {
+			routines = append(routines, searchResult{Name: rt.Name, Owner: rt.Owner, Comment: rt.Comment, URL: "/?page=last#" + url.PathEscape(sectionID("routine", rt.Owner, rt.Name)), rank: rank})
+		}
+	}
+
+	groups := []searchGroup{}
+	for _, g := range []struct {
+		kind    string
+		results []searchResult
+	}{{kindTable, tables}, {kindColumn, columns}, {kindRoutine, routines}} {
+		if len(g.results) == 0 {
+			continue
+		}
+		sort.SliceStable(g.results, func(i, j int) bool { return g.results[i].rank < g.results[j].rank })
+		total := len(g.results)
+		if total > searchLimit {
+			g.results = g.results[:searchLimit]
+		}
+		groups = append(groups, searchGroup{Kind: g.kind, Total: total, Results: g.results})
+	}
+	return groups
+}
+
+// match reports whether every term occurs in name or comment, ranking
+// exact names first, then name prefixes, other name matches and comment
+// matches
+func match(terms []string, name, comment string) (int, bool) {
+	n := normalize(name)
+	c := normalize(comment)
+	inName := true
+	for _, term := range terms {
+		switch {
+		case strings.Contains(n, term):
+		case strings.Contains(c, term):
+			inName = false
+		default:
+			return 0, false
+		}
+	}
+
+	q := strings.Join(terms, " ")
+	switch {
+	case !inName:
+		return 3, true
+	case n == q:
+		return 0, true
+	case strings.HasPrefix(n, q):
+		return 1, true
+	}
+	return 2, true
+}
+
+// normalize lower-cases s in Unicode NFC form, so decomposed Hangul (as
+// typed on macOS) matches precomposed catalog text
+func normalize(s string) string {
+	return strings.ToLower(norm.NFC.String(s))
+}
@@ -1,101 +1,240 @@
-﻿package ui
-
-import (
-	"pocket-doc/internal/exporter"
-	"pocket-doc/internal/model"
-	"embed"
-	"fmt"
-	"html/template"
-	"net/http"
-)
-
-//go:embed templates/*
-var templates embed.FS
-
-// Server provides HTTP endpoints for preview and export
-type Server struct {
-	schema   *model.Schema
-	config   exporter.Config
-	template *template.Template
-}
-
-// NewServer creates a new UI server
-func NewServer(schema *model.Schema, cfg exporter.Config) (*Server, error) {
-	// Parse embedded templates
-	tmpl, err := template.ParseFS(templates, "templates/*.html")
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse templates: %w", err)
-	}
-
-	return &Server{
-		schema:   schema,
-		config:   cfg,
-		template: tmpl,
-	}, nil
-}
-
-// RegisterRoutes registers HTTP handlers
-func (s *Server) RegisterRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("/", s.handlePreview)
-	mux.HandleFunc("/export/excel", s.handleExportExcel)
-	mux.HandleFunc("/export/word", s.handleExportWord)
-}
-
-// handlePreview renders the interactive HTML preview
-func (s *Server) handlePreview(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-
-	if err := s.template.ExecuteTemplate(w, "preview.html", s.schema); err != nil {
-		http.Error(w, fmt.Sprintf("Template error: %v", err), http.StatusInternalServerError)
-		return
-	}
-}
-
-// handleExportExcel generates and downloads Excel file
-func (s *Server) handleExportExcel(w http.ResponseWriter, r *http.Request) {
-	exp, err := exporter.NewExporter("xlsx", s.config)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Exporter error: %v", err), http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", exp.MimeType())
-	w.Header().Set("Content-Disposition",
-		fmt.Sprintf("attachment; filename=\"%s_schema%s\"", s.schema.DatabaseName, exp.FileExtension()))
-
-	if err := exp.Export(s.schema, w); err != nil {
-		http.Error(w, fmt.Sprintf("Export error: %v", err), http.StatusInternalServerError)
-		return
-	}
-}
-
-// handleExportWord generates and downloads Word document
-func (s *Server) handleExportWord(w http.ResponseWriter, r *http.Request) {
-	exp, err := exporter.NewExporter("docx", s.config)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Exporter error: %v", err), http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", exp.MimeType())
-	w.Header().Set("Content-Disposition",
-		fmt.Sprintf("attachment; filename=\"%s_schema%s\"", s.schema.DatabaseName, exp.FileExtension()))
-
-	if err := exp.Export(s.schema, w); err != nil {
-		http.Error(w, fmt.Sprintf("Export error: %v", err), http.StatusInternalServerError)
-		return
-	}
-}
-
-// Start starts the HTTP server
-func (s *Server) Start(addr string) error {
-	mux := http.NewServeMux()
-	s.RegisterRoutes(mux)
-
-	fmt.Printf("🚀 Preview server starting at http://%s\n", addr)
-	fmt.Printf("📊 Database: %s (%s)\n", s.schema.DatabaseName, s.schema.DatabaseType)
-	fmt.Printf("📁 Tables: %d | Views: %d | Routines: %d\n",
-		len(s.schema.Tables), len(s.schema.Views), len(s.schema.Routines))
-
-	return http.ListenAndServe(addr, mux)
-}
+﻿package ui
+
+import (
+	"context"
+	"crypto/tls"
+	"pocket-doc/internal/comments"
+	"pocket-doc/internal/exporter"
+	"pocket-doc/internal/i18n"
+	"pocket-doc/internal/model"
+	"embed"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed templates/*
+var templates embed.FS
+
+// Server provides HTTP endpoints for preview and export
+type Server struct {
+	config   exporter.Config
+	template *template.Template
+	basePath string // URL prefix of every route, see SetBasePath
+	pageSize int    // table sections per preview page, see SetPageSize
+
+	mu       sync.RWMutex
+	schema   *model.Schema // replaced by a refresh; read with current
+	version  int           // incremented by every refresh
+	refresh  Refresher     // nil: POST /api/refresh is disabled
+	baseline *model.Schema // compared with schema on /compare; nil until set
+
+	refreshing sync.Mutex
+	live       *liveUpdates
+
+	comments commentEdits
+	metrics  *metrics
+}
+
+// NewServer creates a new UI server
+func NewServer(schema *model.Schema, cfg exporter.Config) (*Server, error) {
+	// Parse embedded templates with the message catalog for cfg.Language
+	msg := i18n.New(cfg.Language)
+	s := &Server{
+		schema:   exporter.OrderSchema(schema, cfg),
+		config:   cfg,
+		live:     newLiveUpdates(),
+		metrics:  newMetrics(),
+		pageSize: DefaultPageSize,
+	}
+	s.comments.edits = make(map[commentKey]comments.Edit)
+	tmpl, err := template.New("preview").Funcs(template.FuncMap{
+		"t":         msg.T,
+		"lang":      msg.Language,
+		"join":      strings.Join,
+		"tablePath": tablePath,
+		"section":   sectionID,
+		"fontFamily": func() template.CSS {
+			return template.CSS(cfg.Font.Resolve(msg.Language()).CSS())
+		},
+		"date": func(t time.Time) string {
+			return cfg.Dates.Format(t, "2006-01-02 15:04:05")
+		},
+		"base": func() string {
+			return s.basePath
+		},
+		"formats": exporter.GetSupportedFormats,
+		"canRefresh": func() bool {
+			return s.refresh != nil
+		},
+		"editable": func(owner, table, column, comment string) comments.Edit {
+			return comments.Edit{Owner: owner, Table: table, Column: column, Comment: comment}
+		},
+	}).ParseFS(templates, "templates/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse templates: %w", err)
+	}
+	s.template = tmpl
+	return s, nil
+}
+
+// current returns the schema being served
+func (s *Server) current() *model.Schema {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.schema
+}
+
+// RegisterRoutes registers HTTP handlers at the root of mux, without
+// compression or auth (see Handler). The routes changing the server state
+// require CSRFHeader.
+func (s *Server) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/{$}", s.handlePreview)
+	mux.HandleFunc("GET /tables/{owner}/{name}", s.handleTable)
+	mux.HandleFunc("GET /api/search", s.handleSearch)
+	mux.HandleFunc("/export/{format}", s.handleExport)
+	mux.HandleFunc("POST /api/refresh", sameOrigin(s.handleRefresh))
+	mux.HandleFunc("GET /api/events", s.handleEvents)
+	mux.HandleFunc("GET /api/comments", s.handleComments)
+	mux.HandleFunc("PUT /api/comments", sameOrigin(s.handleEditComment))
+	mux.HandleFunc("DELETE /api/comments", sameOrigin(s.handleResetComments))
+	mux.HandleFunc("GET /api/comments/script", s.handleCommentScript)
+	mux.HandleFunc("GET /compare", s.handleCompare)
+	mux.HandleFunc("POST /api/compare", sameOrigin(s.handleUploadBaseline))
+	mux.HandleFunc("DELETE /api/compare", sameOrigin(s.handleClearBaseline))
+	mux.HandleFunc("GET /healthz", s.handleHealthz)
+	mux.HandleFunc("GET /readyz", s.handleReadyz)
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+}
+
+// handlePreview renders a page of the interactive HTML preview (?page=N)
+func (s *Server) handlePreview(w http.ResponseWriter, r *http.Request) {
+	schema, ok := s.cached(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	page := newPreviewPage(schema, pageParam(r), s.pageSize, model.NewNamer(schema, s.config.HideSingleSchema))
+	if err := s.template.ExecuteTemplate(w, "preview.html", page); err != nil {
+		http.Error(w, fmt.Sprintf("Template error: %v", err), http.StatusInternalServerError)
+		return
+	}
+}
+
+// exportAliases maps the original download routes to export formats
+var exportAliases = map[string]string{
+	"excel": "xlsx",
+	"word":  "docx",
+}
+
+// handleExport generates and downloads the document in the format named
+// by the path (any registered exporter format, or excel/word)
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	format := r.PathValue("format")
+	if alias, ok := exportAliases[format]; ok {
+		format = alias
+	}
+	if !exporter.IsSupported(format) {
+		http.Error(w, fmt.Sprintf("Unsupported export format: %s", format), http.StatusNotFound)
+		return
+	}
+
+	exp, err := exporter.NewExporter(format, s.config)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Exporter error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	schema, ok := s.cached(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", exp.MimeType())
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"%s_schema%s\"", schema.DatabaseName, exp.FileExtension()))
+
+	err = exp.Export(r.Context(), schema, w)
+	s.metrics.observeExport(format, err)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Export error: %v", err), http.StatusInternalServerError)
+		return
+	}
+}
+
+// ShutdownTimeout bounds how long in-flight requests may run after shutdown starts
+const ShutdownTimeout = 10 * time.Second
+
+// Listen listens on addr. With certFile and keyFile (PEM) the listener
+// accepts TLS 1.2+ connections only.
+func Listen(addr, certFile, keyFile string) (net.Listener, error) {
+	var tlsConfig *tls.Config
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+	return ln, nil
+}
+
+// WithHSTS sends Strict-Transport-Security with maxAge seconds on every
+// response, so browsers keep using HTTPS for the host; maxAge 0 returns h
+// unchanged. Only use it for handlers served over TLS.
+func WithHSTS(h http.Handler, maxAge int) http.Handler {
+	if maxAge <= 0 {
+		return h
+	}
+	value := fmt.Sprintf("max-age=%d", maxAge)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Strict-Transport-Security", value)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// ListenAndServe serves handler on addr until ctx is canceled (see Serve)
+func ListenAndServe(ctx context.Context, addr string, handler http.Handler) error {
+	ln, err := Listen(addr, "", "")
+	if err != nil {
+		return err
+	}
+	return Serve(ctx, ln, handler)
+}
+
+// Serve serves handler on ln until ctx is canceled, then stops accepting
+// connections and lets in-flight requests finish (up to ShutdownTimeout)
+// before returning. Listening first lets callers learn the actual address
+// (e.g. for port 0) before serving.
+func Serve(ctx context.Context, ln net.Listener, handler http.Handler) error {
+	srv := &http.Server{Handler: handler}
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+		return fmt.Errorf("failed to shut down gracefully: %w", err)
+	}
+	return nil
+}
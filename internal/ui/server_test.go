@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pocket-doc/internal/comments"
+	"pocket-doc/internal/exporter"
+	"pocket-doc/internal/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSchema returns a schema of one commented table
+func testSchema() *model.Schema {
+	return &model.Schema{
+		DatabaseName: "shop",
+		DatabaseType: "postgresql",
+		ExtractedAt:  time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC),
+		Tables: []model.Table{{Owner: "public", Name: "orders", Comment: "Orders",
+			Columns: []model.Column{{Name: "id", DataType: "integer", Comment: "Order number"}}}},
+	}
+}
+
+// newTestServer returns the handler of a server of testSchema that
+// re-extracts the same schema
+func newTestServer(t *testing.T) (*Server, http.Handler) {
+	t.Helper()
+	s, err := NewServer(testSchema(), exporter.Config{Language: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetRefresher(func(ctx context.Context) (*model.Schema, error) { return testSchema(), nil })
+	return s, s.Handler(Auth{})
+}
+
+// serve answers a request to h with the given headers
+func serve(h http.Handler, method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// TestStateChangingRoutes validates the routes changing the server state
+// refuse requests without CSRFHeader or from another site, and work with it
+func TestStateChangingRoutes(t *testing.T) {
+	s, h := newTestServer(t)
+	edit := `{"owner":"public","table":"orders","column":"id","comment":"Order id"}`
+	routes := []struct {
+		method, path, body string
+		status             int
+	}{
+		{http.MethodPost, "/api/refresh", "", http.StatusOK},
+		{http.MethodPut, "/api/comments", edit, http.StatusOK},
+		{http.MethodDelete, "/api/comments", "", http.StatusNoContent},
+		{http.MethodPost, "/api/compare", `{"databaseName":"shop","tables":[]}`, http.StatusOK},
+		{http.MethodDelete, "/api/compare", "", http.StatusNoContent},
+	}
+	for _, rt := range routes {
+		name := rt.method + " " + rt.path
+		if w := serve(h, rt.method, rt.path, rt.body, nil); w.Code != http.StatusForbidden {
+			t.Errorf("%s without %s: status %d, want 403", name, CSRFHeader, w.Code)
+		}
+		crossSite := map[string]string{CSRFHeader: "x", "Sec-Fetch-Site": "cross-site"}
+		if w := serve(h, rt.method, rt.path, rt.body, crossSite); w.Code != http.StatusForbidden {
+			t.Errorf("%s from another site: status %d, want 403", name, w.Code)
+		}
+		sameSite := map[string]string{CSRFHeader: "pocket-doc", "Sec-Fetch-Site": "same-origin"}
+		if w := serve(h, rt.method, rt.path, rt.body, sameSite); w.Code != rt.status {
+			t.Errorf("%s: status %d, want %d: %s", name, w.Code, rt.status, w.Body)
+		}
+	}
+
+	// Reading needs no header
+	if w := serve(h, http.MethodGet, "/api/comments", "", nil); w.Code != http.StatusOK {
+		t.Errorf("GET /api/comments: status %d", w.Code)
+	}
+	if baseline, _ := s.comparison(); baseline != nil {
+		t.Error("baseline left after DELETE /api/compare")
+	}
+}
+
+// TestEditComment validates comment edits are recorded, reverted by the
+// current comment and bounded in size
+func TestEditComment(t *testing.T) {
+	s, h := newTestServer(t)
+	header := map[string]string{CSRFHeader: "pocket-doc"}
+	list := func(w *httptest.ResponseRecorder) []comments.Edit {
+		t.Helper()
+		var edits []comments.Edit
+		if err := json.Unmarshal(w.Body.Bytes(), &edits); err != nil {
+			t.Fatalf("bad edit list %q: %v", w.Body, err)
+		}
+		return edits
+	}
+
+	w := serve(h, http.MethodPut, "/api/comments", `{"owner":"public","table":"orders","comment":"Customer orders"}`, header)
+	if edits := list(w); len(edits) != 1 || edits[0].Comment != "Customer orders" || edits[0].Column != "" {
+		t.Errorf("edits = %+v", edits)
+	}
+	// Restoring the extracted comment drops the edit
+	w = serve(h, http.MethodPut, "/api/comments", `{"owner":"public","table":"orders","comment":"Orders"}`, header)
+	if edits := list(w); len(edits) != 0 {
+		t.Errorf("edits after restoring = %+v", edits)
+	}
+
+	if w := serve(h, http.MethodPut, "/api/comments", `{"owner":"public","table":"invoices","comment":"x"}`, header); w.Code != http.StatusNotFound {
+		t.Errorf("unknown table: status %d", w.Code)
+	}
+	big := `{"owner":"public","table":"orders","comment":"` + strings.Repeat("x", maxCommentEditSize) + `"}`
+	if w := serve(h, http.MethodPut, "/api/comments", big, header); w.Code != http.StatusBadRequest {
+		t.Errorf("oversized edit: status %d", w.Code)
+	}
+	if edits := s.comments.list(); len(edits) != 0 {
+		t.Errorf("oversized edit recorded: %+v", edits)
+	}
+}
@@ -0,0 +1,176 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// azureVersion is the Blob service version of requests and SAS tokens
+const azureVersion = "2022-11-02"
+
+// azure uploads block blobs to Azure Blob Storage. With the account key
+// (AZURE_STORAGE_KEY) each request carries a SAS signed for it and URLs
+// can be pre-signed; otherwise AZURE_STORAGE_SAS_TOKEN is appended, or an
+// Entra ID token is obtained for the service principal of
+// AZURE_TENANT_ID/AZURE_CLIENT_ID/AZURE_CLIENT_SECRET or the managed
+// identity of the host.
+type azure struct {
+	cfg      Config
+	endpoint string
+	key      []byte
+	sas      string
+}
+
+func newAzure(cfg Config) (Uploader, error) {
+	if cfg.Account == "" {
+		return nil, errors.New("upload: account is required for azure")
+	}
+	z := &azure{cfg: cfg, endpoint: strings.TrimRight(cfg.Endpoint, "/"), sas: strings.TrimPrefix(os.Getenv("AZURE_STORAGE_SAS_TOKEN"), "?")}
+	if z.endpoint == "" {
+		z.endpoint = "https://" + cfg.Account + ".blob.core.windows.net"
+	}
+	if key := os.Getenv("AZURE_STORAGE_KEY"); key != "" {
+		k, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, errors.New("upload: AZURE_STORAGE_KEY is not base64")
+		}
+		z.key = k
+	} else if cfg.Presign > 0 {
+		return nil, errors.New("upload: pre-signed Azure URLs need the account key (AZURE_STORAGE_KEY)")
+	}
+	return z, nil
+}
+
+func (z *azure) Upload(ctx context.Context, file string) (string, error) {
+	body, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	name := z.cfg.Name(file)
+	blobURL := z.endpoint + "/" + uriEncode(z.cfg.Bucket, false) + "/" + uriEncode(name, true)
+
+	target := blobURL
+	var token string
+	now := time.Now().UTC()
+	switch {
+	case z.key != nil:
+		target += "?" + z.signSAS(name, "cw", now.Add(time.Hour))
+	case z.sas != "":
+		target += "?" + z.sas
+	default:
+		if token, err = azureToken(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("upload: invalid endpoint %s", z.endpoint)
+	}
+	req.Header.Set("Content-Type", contentType(name))
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Header.Set("x-ms-version", azureVersion)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if err := send(req, name); err != nil {
+		return "", err
+	}
+
+	if z.cfg.Presign > 0 {
+		return blobURL + "?" + z.signSAS(name, "r", now.Add(z.cfg.Presign)), nil
+	}
+	return blobURL, nil
+}
+
+// signSAS returns the query of a service SAS granting permissions on blob
+// name until expiry
+func (z *azure) signSAS(name, permissions string, expiry time.Time) string {
+	se := expiry.UTC().Format("2006-01-02T15:04:05Z")
+	resource := "/blob/" + z.cfg.Account + "/" + z.cfg.Bucket + "/" + name
+	stringToSign := strings.Join([]string{
+		permissions, // sp
+		"",          // st
+		se,
+		resource,
+		"", // si
+		"", // sip
+		"", // spr
+		azureVersion,
+		"b",                // sr
+		"",                 // snapshot time
+		"",                 // encryption scope
+		"", "", "", "", "", // rscc, rscd, rsce, rscl, rsct
+	}, "\n")
+	mac := hmac.New(sha256.New, z.key)
+	mac.Write([]byte(stringToSign))
+
+	return url.Values{
+		"sv":  {azureVersion},
+		"sr":  {"b"},
+		"sp":  {permissions},
+		"se":  {se},
+		"sig": {base64.StdEncoding.EncodeToString(mac.Sum(nil))},
+	}.Encode()
+}
+
+// azureToken obtains an Entra ID access token for Azure Storage
+func azureToken(ctx context.Context) (string, error) {
+	const resource = "https://storage.azure.com"
+	tenantID, clientID, secret := os.Getenv("AZURE_TENANT_ID"), os.Getenv("AZURE_CLIENT_ID"), os.Getenv("AZURE_CLIENT_SECRET")
+
+	var req *http.Request
+	var err error
+	client := http.DefaultClient
+	if secret != "" {
+		if tenantID == "" || clientID == "" {
+			return "", errors.New("upload: AZURE_TENANT_ID and AZURE_CLIENT_ID must be set with AZURE_CLIENT_SECRET")
+		}
+		form := url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {clientID},
+			"client_secret": {secret},
+			"scope":         {resource + "/.default"},
+		}
+		tokenURL := "https://login.microsoftonline.com/" + url.PathEscape(tenantID) + "/oauth2/v2.0/token"
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		// Managed identity via the instance metadata service
+		query := url.Values{"api-version": {"2018-02-01"}, "resource": {resource}}
+		if clientID != "" {
+			query.Set("client_id", clientID)
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet,
+			"http://169.254.169.254/metadata/identity/oauth2/token?"+query.Encode(), nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Metadata", "true")
+		client = metadataClient
+	}
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := getJSON(client, req, &resp); err != nil {
+		return "", fmt.Errorf("upload: no Azure credentials (set AZURE_STORAGE_KEY, AZURE_STORAGE_SAS_TOKEN or a service principal, or run with a managed identity): %w", err)
+	}
+	if resp.AccessToken == "" {
+		return "", errors.New("upload: empty Azure access token")
+	}
+	return resp.AccessToken, nil
+}
@@ -0,0 +1,92 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// gcs uploads to Google Cloud Storage through its XML API. With an HMAC
+// key (GCS_HMAC_ACCESS_ID, GCS_HMAC_SECRET) requests are signed and URLs
+// can be pre-signed; otherwise the OAuth token of GOOGLE_OAUTH_ACCESS_TOKEN
+// or of the metadata server's service account is used.
+type gcs struct {
+	cfg      Config
+	endpoint string
+	hmac     *signer
+}
+
+func newGCS(cfg Config) (Uploader, error) {
+	g := &gcs{cfg: cfg, endpoint: strings.TrimRight(cfg.Endpoint, "/")}
+	if g.endpoint == "" {
+		g.endpoint = "https://storage.googleapis.com"
+	}
+	if id, secret := os.Getenv("GCS_HMAC_ACCESS_ID"), os.Getenv("GCS_HMAC_SECRET"); id != "" && secret != "" {
+		g.hmac = &signer{scheme: "GOOG4", region: "auto", service: "storage", accessKey: id, secretKey: secret}
+	} else if cfg.Presign > 0 {
+		return nil, errors.New("upload: pre-signed GCS URLs need an HMAC key (GCS_HMAC_ACCESS_ID, GCS_HMAC_SECRET)")
+	}
+	return g, nil
+}
+
+func (g *gcs) Upload(ctx context.Context, file string) (string, error) {
+	body, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	name := g.cfg.Name(file)
+	u, err := url.Parse(g.endpoint + "/" + uriEncode(g.cfg.Bucket, false) + "/" + uriEncode(name, true))
+	if err != nil {
+		return "", fmt.Errorf("upload: invalid endpoint: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType(name))
+	now := time.Now().UTC()
+	if g.hmac != nil {
+		g.hmac.sign(req, hashHex(body), now)
+	} else {
+		token, err := gcsToken(ctx)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if err := send(req, name); err != nil {
+		return "", err
+	}
+
+	if g.cfg.Presign > 0 {
+		return g.hmac.presign(u, g.cfg.Presign, now), nil
+	}
+	return u.String(), nil
+}
+
+// gcsToken returns an OAuth access token for Cloud Storage
+func gcsToken(ctx context.Context) (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		"http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	var resp struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := getJSON(metadataClient, req, &resp); err != nil {
+		return "", fmt.Errorf("upload: no GCS credentials (set GCS_HMAC_ACCESS_ID and GCS_HMAC_SECRET or GOOGLE_OAUTH_ACCESS_TOKEN, or run with a service account): %w", err)
+	}
+	return resp.AccessToken, nil
+}
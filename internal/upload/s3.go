@@ -0,0 +1,165 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// s3 uploads to Amazon S3 or an S3-compatible store (endpoint, path-style
+// URLs). Credentials come from AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY
+// (and AWS_SESSION_TOKEN), then from the container credentials of ECS,
+// then from the instance role (IMDSv2).
+type s3 struct {
+	cfg    Config
+	region string
+}
+
+func newS3(cfg Config) (Uploader, error) {
+	s := &s3{cfg: cfg, region: cfg.Region}
+	if s.region == "" {
+		s.region = os.Getenv("AWS_REGION")
+	}
+	if s.region == "" {
+		s.region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if s.region == "" {
+		return nil, errors.New("upload: region or AWS_REGION is required for s3")
+	}
+	return s, nil
+}
+
+// objectURL returns the URL of object name: virtual-hosted on AWS,
+// path-style on a custom endpoint
+func (s *s3) objectURL(name string) (*url.URL, error) {
+	if s.cfg.Endpoint != "" {
+		return url.Parse(strings.TrimRight(s.cfg.Endpoint, "/") + "/" + uriEncode(s.cfg.Bucket, false) + "/" + uriEncode(name, true))
+	}
+	return url.Parse(fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.cfg.Bucket, s.region, uriEncode(name, true)))
+}
+
+func (s *s3) Upload(ctx context.Context, file string) (string, error) {
+	body, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	name := s.cfg.Name(file)
+	u, err := s.objectURL(name)
+	if err != nil {
+		return "", fmt.Errorf("upload: invalid endpoint: %w", err)
+	}
+	creds, err := awsCredentials(ctx)
+	if err != nil {
+		return "", err
+	}
+	sg := signer{scheme: "AWS4", region: s.region, service: "s3", accessKey: creds.AccessKeyID, secretKey: creds.SecretAccessKey, token: creds.Token}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType(name))
+	now := time.Now().UTC()
+	sg.sign(req, hashHex(body), now)
+	if err := send(req, name); err != nil {
+		return "", err
+	}
+
+	if s.cfg.Presign > 0 {
+		return sg.presign(u, s.cfg.Presign, now), nil
+	}
+	return u.String(), nil
+}
+
+// awsCreds are the fields of AWS credential responses
+type awsCreds struct {
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	Token           string `json:"Token"`
+}
+
+// awsCredentials returns the credentials of the environment or the host
+func awsCredentials(ctx context.Context) (awsCreds, error) {
+	c := awsCreds{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		Token:           os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if c.AccessKeyID != "" && c.SecretAccessKey != "" {
+		return c, nil
+	}
+
+	// ECS task role
+	endpoint := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if rel := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); rel != "" {
+		endpoint = "http://169.254.170.2" + rel
+	}
+	if endpoint != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return c, err
+		}
+		if token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN"); token != "" {
+			req.Header.Set("Authorization", token)
+		}
+		if err := getJSON(metadataClient, req, &c); err != nil {
+			return c, fmt.Errorf("upload: container credentials: %w", err)
+		}
+		return c, nil
+	}
+
+	// EC2 instance role (IMDSv2)
+	const imds = "http://169.254.169.254/latest"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, imds+"/api/token", nil)
+	if err != nil {
+		return c, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "300")
+	token, err := metadataText(req)
+	if err != nil {
+		return c, fmt.Errorf("upload: no AWS credentials (set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY or run with an instance role): %w", err)
+	}
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, imds+"/meta-data/iam/security-credentials/", nil)
+	if err != nil {
+		return c, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token", token)
+	role, err := metadataText(req)
+	if err != nil {
+		return c, fmt.Errorf("upload: instance role: %w", err)
+	}
+	role, _, _ = strings.Cut(role, "\n")
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, imds+"/meta-data/iam/security-credentials/"+url.PathEscape(role), nil)
+	if err != nil {
+		return c, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token", token)
+	if err := getJSON(metadataClient, req, &c); err != nil {
+		return c, fmt.Errorf("upload: instance role credentials: %w", err)
+	}
+	return c, nil
+}
+
+// metadataText returns the plain text answer of a metadata service
+func metadataText(req *http.Request) (string, error) {
+	resp, err := metadataClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s %s: %s", req.Method, req.URL.Path, resp.Status)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
@@ -0,0 +1,154 @@
+package upload
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unsignedPayload is the payload hash of pre-signed URLs
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// signer signs requests with AWS Signature Version 4, or with its Google
+// Cloud Storage variant for HMAC keys (scheme GOOG4)
+type signer struct {
+	scheme    string // AWS4, GOOG4
+	region    string
+	service   string
+	accessKey string
+	secretKey string
+	token     string // session token (AWS)
+}
+
+// header returns the name of a scheme header, e.g. X-Amz-Date
+func (s signer) header(name string) string {
+	if s.scheme == "GOOG4" {
+		return "X-Goog-" + name
+	}
+	return "X-Amz-" + name
+}
+
+func (s signer) algorithm() string { return s.scheme + "-HMAC-SHA256" }
+
+func (s signer) scope(now time.Time) string {
+	return strings.Join([]string{now.Format("20060102"), s.region, s.service, strings.ToLower(s.scheme) + "_request"}, "/")
+}
+
+// sign adds the Authorization header for a request whose body hashes to
+// payloadHash
+func (s signer) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	req.Header.Set(s.header("Date"), amzDate)
+	req.Header.Set(s.header("Content-Sha256"), payloadHash)
+	if s.token != "" {
+		req.Header.Set(s.header("Security-Token"), s.token)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-amz-") || strings.HasPrefix(lower, "x-goog-") {
+			headers[lower] = strings.Join(values, ",")
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.algorithm(), s.accessKey, s.scope(now), signedHeaders, s.signature(canonicalRequest, now)))
+}
+
+// presign returns u as a URL that allows a GET for expires without
+// further credentials
+func (s signer) presign(u *url.URL, expires time.Duration, now time.Time) string {
+	query := u.Query()
+	query.Set(s.header("Algorithm"), s.algorithm())
+	query.Set(s.header("Credential"), s.accessKey+"/"+s.scope(now))
+	query.Set(s.header("Date"), now.Format("20060102T150405Z"))
+	query.Set(s.header("Expires"), strconv.Itoa(int(expires.Seconds())))
+	query.Set(s.header("SignedHeaders"), "host")
+	if s.token != "" {
+		query.Set(s.header("Security-Token"), s.token)
+	}
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+	signed := *u
+	signed.RawQuery = canonicalQuery(query) + "&" + s.header("Signature") + "=" + s.signature(canonicalRequest, now)
+	return signed.String()
+}
+
+// signature signs a canonical request
+func (s signer) signature(canonicalRequest string, now time.Time) string {
+	stringToSign := strings.Join([]string{
+		s.algorithm(),
+		now.Format("20060102T150405Z"),
+		s.scope(now),
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte(s.scheme+s.secretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s.service)
+	key = hmacSHA256(key, strings.ToLower(s.scheme)+"_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// canonicalQuery encodes query sorted by name, as SigV4 requires
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var parts []string
+	for _, name := range names {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, uriEncode(name, false)+"="+uriEncode(v, false))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,153 @@
+// Package upload copies written documents to object storage (Amazon S3,
+// Google Cloud Storage or Azure Blob Storage) and returns their URLs,
+// pre-signed when asked, so CI jobs need no separate upload step.
+// Credentials come from the environment or from the identity of the host
+// (instance role, metadata server, managed identity).
+package upload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Providers
+const (
+	ProviderS3    = "s3"
+	ProviderGCS   = "gcs"
+	ProviderAzure = "azure"
+)
+
+// Providers lists the supported providers
+var Providers = []string{ProviderS3, ProviderGCS, ProviderAzure}
+
+// MaxPresign is the longest validity of a pre-signed URL
+const MaxPresign = 7 * 24 * time.Hour
+
+// Config selects where documents are uploaded
+type Config struct {
+	Provider string        // s3, gcs, azure
+	Bucket   string        // bucket, or Azure container
+	Prefix   string        // object name prefix, e.g. docs/prod
+	Region   string        // S3 region (default AWS_REGION)
+	Account  string        // Azure storage account
+	Endpoint string        // S3-compatible, GCS or Azurite endpoint (default the provider's)
+	Presign  time.Duration // validity of the returned URLs (0 = plain object URLs)
+}
+
+// Uploader stores files as objects
+type Uploader interface {
+	// Upload stores file under its base name and returns the object URL
+	Upload(ctx context.Context, file string) (string, error)
+}
+
+// New returns the uploader of cfg.Provider
+func New(cfg Config) (Uploader, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("upload: bucket is required")
+	}
+	if cfg.Presign > MaxPresign {
+		return nil, fmt.Errorf("upload: pre-signed URLs are valid for at most %s", MaxPresign)
+	}
+	switch strings.ToLower(cfg.Provider) {
+	case ProviderS3:
+		return newS3(cfg)
+	case ProviderGCS:
+		return newGCS(cfg)
+	case ProviderAzure:
+		return newAzure(cfg)
+	default:
+		return nil, fmt.Errorf("upload: unknown provider %s (use %s)", cfg.Provider, strings.Join(Providers, ", "))
+	}
+}
+
+// Name returns the object name of file: its base name under the prefix
+func (c Config) Name(file string) string {
+	name := filepath.Base(file)
+	if prefix := strings.Trim(c.Prefix, "/"); prefix != "" {
+		return prefix + "/" + name
+	}
+	return name
+}
+
+// contentType returns the media type of a document by its extension
+func contentType(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".docx":
+		return "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	case ".xlsx":
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	if t := mime.TypeByExtension(filepath.Ext(name)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// send performs an upload request. Errors name the object, not the URL:
+// signed URLs carry credentials.
+func send(req *http.Request, name string) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("upload %s to %s: %w", name, req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("upload %s to %s: %s: %s", name, req.URL.Host, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// metadataClient talks to instance metadata services, which answer fast
+// or not at all (off the cloud)
+var metadataClient = &http.Client{Timeout: 3 * time.Second}
+
+// getJSON decodes the JSON response of a credential request
+func getJSON(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s", req.Method, req.URL.Redacted(), resp.Status)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// uriEncode percent-encodes s as SigV4 canonical requests require: all but
+// the unreserved characters, and '/' unless slash is false
+func uriEncode(s string, slash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', slash && c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
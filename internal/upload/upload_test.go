@@ -0,0 +1,101 @@
+package upload
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestUpload tests uploading documents to S3, GCS and Azure compatible
+// endpoints
+func TestUpload(t *testing.T) {
+	type request struct{ method, path, query, auth, contentType, body string }
+	var got []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got = append(got, request{r.Method, r.URL.EscapedPath(), r.URL.RawQuery, r.Header.Get("Authorization"), r.Header.Get("Content-Type"), string(b)})
+	}))
+	defer srv.Close()
+
+	file := filepath.Join(t.TempDir(), "shop schema.html")
+	if err := os.WriteFile(file, []byte("<html></html>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("GCS_HMAC_ACCESS_ID", "")
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "ya29.token")
+	t.Setenv("AZURE_STORAGE_KEY", "c2VjcmV0")
+	ctx := context.Background()
+
+	tests := []struct {
+		cfg      Config
+		path     string
+		auth     string
+		urlParts []string
+	}{
+		{
+			Config{Provider: "s3", Bucket: "docs", Prefix: "/prod/", Region: "eu-west-1", Endpoint: srv.URL, Presign: time.Hour},
+			"/docs/prod/shop%20schema.html",
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/",
+			[]string{srv.URL + "/docs/prod/shop%20schema.html?", "X-Amz-Expires=3600", "X-Amz-Signature="},
+		},
+		{
+			Config{Provider: "gcs", Bucket: "docs", Endpoint: srv.URL},
+			"/docs/shop%20schema.html",
+			"Bearer ya29.token",
+			[]string{srv.URL + "/docs/shop%20schema.html"},
+		},
+		{
+			Config{Provider: "azure", Bucket: "docs", Account: "acct", Endpoint: srv.URL, Presign: time.Hour},
+			"/docs/shop%20schema.html",
+			"",
+			[]string{srv.URL + "/docs/shop%20schema.html?", "sp=r", "sig="},
+		},
+	}
+	for _, tt := range tests {
+		got = nil
+		up, err := New(tt.cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.cfg.Provider, err)
+		}
+		url, err := up.Upload(ctx, file)
+		if err != nil {
+			t.Fatalf("%s: upload failed: %v", tt.cfg.Provider, err)
+		}
+		if len(got) != 1 {
+			t.Fatalf("%s: %d requests", tt.cfg.Provider, len(got))
+		}
+		r := got[0]
+		if r.method != http.MethodPut || r.path != tt.path || r.body != "<html></html>" || !strings.HasPrefix(r.contentType, "text/html") {
+			t.Errorf("%s: request = %+v", tt.cfg.Provider, r)
+		}
+		if !strings.HasPrefix(r.auth, tt.auth) {
+			t.Errorf("%s: Authorization = %q", tt.cfg.Provider, r.auth)
+		}
+		for _, part := range tt.urlParts {
+			if !strings.Contains(url, part) {
+				t.Errorf("%s: URL %s lacks %q", tt.cfg.Provider, url, part)
+			}
+		}
+	}
+	// Azure uploads with a SAS signed for writing
+	if q := got[0].query; !strings.Contains(q, "sp=cw") {
+		t.Errorf("Azure upload query = %q", q)
+	}
+
+	// Pre-signing needs key material the bearer token does not provide
+	if _, err := New(Config{Provider: "gcs", Bucket: "docs", Presign: time.Hour}); err == nil {
+		t.Error("Expected an error for pre-signed GCS URLs without an HMAC key")
+	}
+	if _, err := New(Config{Provider: "ftp", Bucket: "docs"}); err == nil {
+		t.Error("Expected an error for an unknown provider")
+	}
+}
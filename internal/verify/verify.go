@@ -0,0 +1,120 @@
+// Package verify cross-checks extracted metadata for inconsistencies the
+// catalog queries do not rule out: foreign keys pointing at tables or
+// columns that were not extracted, indexes on columns the table does not
+// have, sequences whose current value lies outside their range and
+// impossible row counts. The findings form the data quality appendix of
+// the documents (extract.verify).
+package verify
+
+import (
+	"pocket-doc/internal/model"
+	"fmt"
+	"strings"
+)
+
+// Check IDs
+const (
+	CheckOrphanFK      = "orphan-fk"      // FK target table not in the schema
+	CheckFKColumn      = "fk-column"      // FK target column missing from its table
+	CheckIndexColumn   = "index-column"   // index column missing from its table
+	CheckSequenceRange = "sequence-range" // current value outside min/max
+	CheckRowCount      = "row-count"      // negative row count
+)
+
+// Checks lists the check IDs in report order
+var Checks = []string{CheckOrphanFK, CheckFKColumn, CheckIndexColumn, CheckSequenceRange, CheckRowCount}
+
+// Finding is one inconsistency. Detail holds names and values only, so
+// documents can show it in any language.
+type Finding struct {
+	Check  string `json:"check"`
+	Owner  string `json:"owner,omitempty"`
+	Object string `json:"object"` // table[.column], index or sequence
+	Detail string `json:"detail"`
+}
+
+// Report holds the findings of a verification, ordered by check, then in
+// schema order
+type Report struct {
+	Findings []Finding `json:"findings"`
+}
+
+// ByCheck returns the findings of one check
+func (r *Report) ByCheck(check string) []Finding {
+	var out []Finding
+	for _, f := range r.Findings {
+		if f.Check == check {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Check verifies the tables and sequences of schema
+func Check(schema *model.Schema) *Report {
+	byCheck := make(map[string][]Finding)
+	add := func(check, owner, object, detail string) {
+		byCheck[check] = append(byCheck[check], Finding{Check: check, Owner: owner, Object: object, Detail: detail})
+	}
+
+	g := model.NewGraph(schema)
+	for _, r := range g.Relationships() {
+		object := r.Child.Name + "." + r.Column
+		switch {
+		case r.Parent == nil:
+			add(CheckOrphanFK, r.Child.Owner, object, "→ "+target(r.ParentName, r.ParentColumn))
+		case r.ParentColumn != "" && !hasColumn(r.Parent, r.ParentColumn):
+			add(CheckFKColumn, r.Child.Owner, object, "→ "+target(model.QualifiedName(r.Parent.Owner, r.Parent.Name), r.ParentColumn))
+		}
+	}
+
+	for i := range schema.Tables {
+		t := &schema.Tables[i]
+		for _, idx := range t.Indexes {
+			for _, col := range idx.Columns {
+				if !expression(col) && !hasColumn(t, col) {
+					add(CheckIndexColumn, t.Owner, idx.Name, fmt.Sprintf("%s.%s", t.Name, col))
+				}
+			}
+		}
+		if t.RowCount < 0 {
+			add(CheckRowCount, t.Owner, t.Name, fmt.Sprintf("%d", t.RowCount))
+		}
+	}
+
+	for _, seq := range schema.Sequences {
+		if seq.MinValue <= seq.MaxValue && (seq.LastNumber < seq.MinValue || seq.LastNumber > seq.MaxValue) {
+			add(CheckSequenceRange, seq.Owner, seq.Name, fmt.Sprintf("%d ∉ [%d, %d]", seq.LastNumber, seq.MinValue, seq.MaxValue))
+		}
+	}
+
+	r := &Report{}
+	for _, check := range Checks {
+		r.Findings = append(r.Findings, byCheck[check]...)
+	}
+	return r
+}
+
+// target returns "table.column", or the table when the column is unknown
+func target(table, column string) string {
+	if column == "" {
+		return table
+	}
+	return table + "." + column
+}
+
+// hasColumn reports whether t has a column named name (case-insensitive)
+func hasColumn(t *model.Table, name string) bool {
+	for _, c := range t.Columns {
+		if strings.EqualFold(c.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// expression reports whether an index column is an expression or a hidden
+// column behind one (Oracle SYS_NC00005$) rather than a table column
+func expression(col string) bool {
+	return strings.ContainsAny(col, "( ") || strings.HasPrefix(strings.ToUpper(col), "SYS_NC")
+}
@@ -0,0 +1,38 @@
+package pocketdoc
+
+import (
+	"context"
+	"net/http"
+	"pocket-doc/internal/pipeline"
+	"pocket-doc/internal/ui"
+)
+
+// HandlerOptions configure Handler
+type HandlerOptions struct {
+	BasePath string // path the handler is mounted at, e.g. "/admin/schema"
+	Username string // HTTP basic auth, with Password; empty disables it
+	Password string
+	Token    string // Authorization: Bearer token; empty disables it
+	// Refresh re-extracts the schema for the re-extract button, e.g.
+	// func(ctx context.Context) (*Schema, error) { return Extract(ctx, cfg) };
+	// nil disables the button
+	Refresh func(ctx context.Context) (*Schema, error)
+}
+
+// Handler returns the schema browser of the preview server as an
+// http.Handler for mounting in another service, paginated by ui.page_size:
+//
+//	h, err := pocketdoc.Handler(cfg, schema, pocketdoc.HandlerOptions{BasePath: "/admin/schema"})
+//	mux.Handle("/admin/schema/", h)
+func Handler(cfg *Config, schema *Schema, opts HandlerOptions) (http.Handler, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return ui.Handler(schema, ui.Options{
+		Config:    pipeline.ExporterConfig(cfg),
+		BasePath:  opts.BasePath,
+		Auth:      ui.Auth{Username: opts.Username, Password: opts.Password, Token: opts.Token},
+		Refresher: opts.Refresh,
+		PageSize:  cfg.UI.PageSize,
+	})
+}
@@ -0,0 +1,235 @@
+// Package pocketdoc is the library API of pocket-doc: extract the schema of
+// a database and export it in any registered format, from Go code rather
+// than the CLI.
+//
+//	cfg, err := pocketdoc.LoadConfig(ctx, "config.yaml", "")
+//	if err != nil {
+//		return err
+//	}
+//	schema, err := pocketdoc.Extract(ctx, cfg)
+//	if err != nil {
+//		return err
+//	}
+//	return pocketdoc.Export(ctx, cfg, schema, "xlsx", w)
+//
+// The metadata types are aliases of the ones the CLI uses, so snapshots
+// written by "pocket-doc snapshot" decode into Schema. Only the identifiers
+// of this package are a stable surface; the internal packages may change.
+package pocketdoc
+
+import (
+	"context"
+	"errors"
+	"pocket-doc/internal/config"
+	"pocket-doc/internal/datatype"
+	"pocket-doc/internal/dbquery"
+	"pocket-doc/internal/exporter"
+	"pocket-doc/internal/extractor"
+	"pocket-doc/internal/model"
+	"pocket-doc/internal/pipeline"
+	"fmt"
+	"io"
+)
+
+// Schema metadata types
+type (
+	Schema   = model.Schema
+	Table    = model.Table
+	View     = model.View
+	Column   = model.Column
+	Index    = model.Index
+	Routine  = model.Routine
+	Sequence = model.Sequence
+	Trigger  = model.Trigger
+	Synonym  = model.Synonym
+)
+
+// Config is the configuration file format of the CLI (config.yaml)
+type Config = config.Config
+
+// ConnectionError reports that the database could not be reached or its
+// catalog could not be read, as opposed to a configuration problem
+type ConnectionError struct {
+	Err error
+}
+
+func (e *ConnectionError) Error() string { return e.Err.Error() }
+func (e *ConnectionError) Unwrap() error { return e.Err }
+
+// PermissionDeniedError reports a catalog view the connected user may not
+// read; its Hint names the grant to run
+type PermissionDeniedError = dbquery.PermissionDeniedError
+
+// UnsupportedVersionError reports a catalog view or column the database
+// server lacks
+type UnsupportedVersionError = dbquery.UnsupportedVersionError
+
+// ErrNoSchemaFilter reports an Oracle connection without a schema filter
+// or database.schema_default, which would read every accessible schema
+var ErrNoSchemaFilter = config.ErrNoSchemaFilter
+
+// TimeoutError reports connecting, a catalog query or the whole extraction
+// exceeding its configured time limit
+type TimeoutError = dbquery.TimeoutError
+
+// Hooks observe the catalog queries and extracted objects of an
+// extraction, e.g. to trace its performance or audit the queries it ran.
+// Embed NopHooks to implement only some of the methods.
+type Hooks = dbquery.Hooks
+
+// NopHooks implement Hooks doing nothing
+type NopHooks = dbquery.NopHooks
+
+// Hint returns the remediation hint of err (e.g. "GRANT SELECT ON
+// ALL_ARGUMENTS TO APP"), or "" when it has none
+func Hint(err error) string {
+	return dbquery.Hint(err)
+}
+
+// DefaultConfig returns the configuration used without a config file
+func DefaultConfig() *Config {
+	return config.Default()
+}
+
+// LoadConfig reads a configuration file with the given connection profile
+// ("" for the default), applies the POCKETDOC_* environment overrides,
+// fetches credentials from the configured secret store and loads the
+// message catalogs it references
+func LoadConfig(ctx context.Context, path, profile string) (*Config, error) {
+	cfg, err := config.LoadProfile(path, profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := pipeline.FetchCredentials(ctx, cfg); err != nil {
+		return nil, err
+	}
+	if err := pipeline.LoadMessages(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Extract connects to the configured database and returns its schema with
+// canonical column types, example values (when extract.samples is enabled) and the glossary,
+// annotations and PII tags of the output section applied. A pre-flight
+// check leaves out the sections whose catalogs the user cannot read
+// (listed in Schema.Skipped) instead of failing midway. Connection and
+// catalog failures are returned as *ConnectionError.
+func Extract(ctx context.Context, cfg *Config) (*Schema, error) {
+	return ExtractWithHooks(ctx, cfg, nil)
+}
+
+// ExtractWithHooks is Extract reporting the catalog queries and extracted
+// objects to hooks
+func ExtractWithHooks(ctx context.Context, cfg *Config, hooks Hooks) (*Schema, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	ext, err := pipeline.NewExtractorWithHooks(cfg, hooks)
+	if err != nil {
+		return nil, err
+	}
+	defer ext.Close()
+
+	if err := pipeline.Connect(ctx, cfg, ext); err != nil {
+		return nil, &ConnectionError{fmt.Errorf("failed to connect to database: %w", err)}
+	}
+	extractCtx, cancel := pipeline.ExtractContext(ctx, cfg)
+	defer cancel()
+
+	preflight, _, err := extractor.RunPreflight(extractCtx, ext)
+	if err != nil {
+		return nil, &ConnectionError{fmt.Errorf("pre-flight check failed: %w", pipeline.ExtractError(ctx, extractCtx, cfg, err))}
+	}
+	schema, err := ext.ExtractSchema(extractCtx)
+	if err != nil {
+		return nil, &ConnectionError{fmt.Errorf("failed to extract schema: %w", pipeline.ExtractError(ctx, extractCtx, cfg, err))}
+	}
+	if preflight != nil {
+		schema.Skipped = preflight.Skipped()
+	}
+	datatype.Apply(schema)
+	pipeline.SelectTables(cfg, schema)
+	if _, _, err := pipeline.Migrations(extractCtx, cfg, ext, schema); err != nil {
+		return nil, &ConnectionError{fmt.Errorf("failed to read migration history: %w", pipeline.ExtractError(ctx, extractCtx, cfg, err))}
+	}
+
+	if _, _, err := pipeline.Sample(ctx, cfg, ext, schema); err != nil {
+		return nil, err
+	}
+	if _, err := pipeline.Enrich(cfg, schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// Extractor reads the schema of a database. Extractors are created
+// unconnected; Connect opens the connection and Close releases it.
+type Extractor interface {
+	Connect(ctx context.Context) error
+	Close() error
+	GetDatabaseInfo(ctx context.Context) (name, version string, err error)
+	GetTables(ctx context.Context) ([]Table, error)
+	GetViews(ctx context.Context) ([]View, error)
+	GetRoutines(ctx context.Context) ([]Routine, error)
+	GetSequences(ctx context.Context) ([]Sequence, error)
+	GetTriggers(ctx context.Context) ([]Trigger, error)
+	GetSynonyms(ctx context.Context) ([]Synonym, error)
+	ExtractSchema(ctx context.Context) (*Schema, error)
+}
+
+// NewExtractor creates the database extractor for the configured
+// connection without connecting. An Oracle connection without a schema
+// filter fails with ErrNoSchemaFilter unless database.schema_default is set.
+// With extract.cache.dir, the extractor reuses the tables of previous runs
+// that have not changed since.
+func NewExtractor(cfg *Config) (Extractor, error) {
+	return NewExtractorWithHooks(cfg, nil)
+}
+
+// NewExtractorWithHooks is NewExtractor with hooks observing the catalog
+// queries and extracted objects of the extractor (nil = none)
+func NewExtractorWithHooks(cfg *Config, hooks Hooks) (Extractor, error) {
+	ext, err := pipeline.NewExtractorWithHooks(cfg, hooks)
+	if err != nil {
+		return nil, err
+	}
+	return ext, nil
+}
+
+// Enrichment reports what Enrich applied
+type Enrichment = pipeline.Enrichment
+
+// Enrich collapses identical tenant schemas (output.tenants), then applies
+// the business glossary, the dbt descriptions, the annotations file, the
+// domain rules and the PII rules of the output section to schema, in that
+// order. With output.include_domains, objects of other domains are then
+// dropped; with output.since_snapshot, tables and columns changed since
+// that snapshot are marked.
+func Enrich(cfg *Config, schema *Schema) (Enrichment, error) {
+	return pipeline.Enrich(cfg, schema)
+}
+
+// Formats returns the names of the registered export formats
+func Formats() []string {
+	return exporter.GetSupportedFormats()
+}
+
+// Export writes schema to w in the named format (see Formats), laid out as
+// the output section of cfg describes; nil cfg uses the defaults
+func Export(ctx context.Context, cfg *Config, schema *Schema, format string, w io.Writer) error {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	if schema == nil {
+		return errors.New("no schema to export")
+	}
+	exp, err := exporter.NewExporter(format, pipeline.ExporterConfig(cfg))
+	if err != nil {
+		return fmt.Errorf("failed to create exporter: %w", err)
+	}
+	if err := exp.Export(ctx, schema, w); err != nil {
+		return fmt.Errorf("failed to export: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package pocketdoc
+
+import (
+	"errors"
+	"pocket-doc/internal/config"
+	"testing"
+)
+
+// TestNewExtractorSchemaGuard validates an Oracle extractor is refused
+// without a schema filter or schema_default, before any connection is
+// made, and that other databases are not affected
+func TestNewExtractorSchemaGuard(t *testing.T) {
+	tests := []struct {
+		name string
+		db   config.DatabaseConfig
+		err  error
+	}{
+		{"oracle without filter", config.DatabaseConfig{Type: "oracle", Host: "db.local", Port: 1521, Username: "hr"}, config.ErrNoSchemaFilter},
+		{"oracle user schema without user", config.DatabaseConfig{Type: "oracle", Host: "db.local", Port: 1521, SchemaDefault: config.SchemaDefaultUser}, config.ErrNoSchemaFilter},
+		{"oracle filter", config.DatabaseConfig{Type: "oracle", Host: "db.local", Port: 1521, Username: "hr", SchemaFilter: []string{"HR"}}, nil},
+		{"oracle user schema", config.DatabaseConfig{Type: "oracle", Host: "db.local", Port: 1521, Username: "hr", SchemaDefault: config.SchemaDefaultUser}, nil},
+		{"oracle all schemas", config.DatabaseConfig{Type: "oracle", Host: "db.local", Port: 1521, Username: "hr", SchemaDefault: config.SchemaDefaultAll}, nil},
+		{"postgres without filter", config.DatabaseConfig{Type: "postgresql", Host: "db.local", Port: 5432, Database: "shop", Username: "app"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Database = tt.db
+			ext, err := NewExtractor(cfg)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) || ext != nil {
+					t.Fatalf("NewExtractor = %v, %v, want %v", ext, err, tt.err)
+				}
+				return
+			}
+			// Creating the extractor may still fail for another reason
+			// (a driver left out of the build), never for the guard
+			if errors.Is(err, config.ErrNoSchemaFilter) {
+				t.Fatalf("NewExtractor refused: %v", err)
+			}
+			if ext != nil {
+				ext.Close()
+			}
+		})
+	}
+}
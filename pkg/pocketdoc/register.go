@@ -0,0 +1,120 @@
+package pocketdoc
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"pocket-doc/internal/exporter"
+	"pocket-doc/internal/extractor"
+	"time"
+)
+
+// Exporter writes a schema in one document format
+type Exporter interface {
+	Export(ctx context.Context, schema *Schema, w io.Writer) error
+	Format() string
+	MimeType() string
+	FileExtension() string
+}
+
+// ExporterConfig is the part of the output section a registered exporter
+// is created with
+type ExporterConfig struct {
+	Language         string   // language of the labels (en, ko)
+	IncludeTOC       bool     // add a table of contents
+	IncludeCoverPage bool     // add a cover page
+	CompanyName      string   // cover page company
+	ProjectName      string   // cover page project
+	Author           string   // cover page author
+	ExcludeTypes     []string // object types left out ("views", "routines", ...)
+	ColorScheme      string   // "default", "professional" or "minimal"
+	Classification   string   // confidentiality label, e.g. "INTERNAL"
+	HideSingleSchema bool     // drop the owner from names when every object shares it
+
+	// Logger receives the diagnostics of the exporter (nil = slog.Default())
+	Logger *slog.Logger
+}
+
+// ExporterFactory creates an exporter of a registered format
+type ExporterFactory func(cfg ExporterConfig) (Exporter, error)
+
+// RegisterExporter makes an export format selectable by name and any
+// aliases, in Export, Formats and the CLI's --format. Names are
+// case-insensitive. Like database/sql.Register, it panics if factory is
+// nil or a name is already registered, so it is meant to be called from
+// init functions. The objects passed to the exporter are sorted as
+// output.sort_by and output.group_by describe.
+func RegisterExporter(name string, factory ExporterFactory, aliases ...string) {
+	if factory == nil {
+		panic("pocketdoc: RegisterExporter factory is nil for " + name)
+	}
+	exporter.Register(name, func(cfg exporter.Config) (exporter.Exporter, error) {
+		exp, err := factory(ExporterConfig{
+			Language:         cfg.Language,
+			IncludeTOC:       cfg.IncludeTOC,
+			IncludeCoverPage: cfg.IncludeCoverPage,
+			CompanyName:      cfg.CompanyName,
+			ProjectName:      cfg.ProjectName,
+			Author:           cfg.Author,
+			ExcludeTypes:     cfg.ExcludeTypes,
+			ColorScheme:      cfg.ColorScheme,
+			Classification:   cfg.Classification,
+			HideSingleSchema: cfg.HideSingleSchema,
+			Logger:           cfg.Logger,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return exp, nil
+	}, aliases...)
+}
+
+// ExtractorConfig is the connection a registered extractor is created with
+type ExtractorConfig struct {
+	Host          string
+	Port          int
+	Database      string
+	Username      string
+	Password      string
+	SSLMode       string
+	SchemaFilter  []string
+	ExcludeSystem bool              // skip system and built-in objects the schema filter does not exclude
+	Options       map[string]string // database.options of the configuration
+	Logger        *slog.Logger      // debug-level query timing (nil = slog.Default())
+	QueryTimeout  time.Duration     // limit of each catalog query (0 = none)
+	Hooks         Hooks             // observe catalog queries and extracted objects (nil = none)
+}
+
+// ExtractorFactory creates an extractor of a registered database type
+type ExtractorFactory func(cfg ExtractorConfig) (Extractor, error)
+
+// RegisterExtractor makes a database type selectable by database.type
+// under name and any aliases, in Extract, NewExtractor and the CLI. Names
+// are case-insensitive. Like database/sql.Register, it panics if factory
+// is nil or a name is already registered, so it is meant to be called
+// from init functions.
+func RegisterExtractor(name string, factory ExtractorFactory, aliases ...string) {
+	if factory == nil {
+		panic("pocketdoc: RegisterExtractor factory is nil for " + name)
+	}
+	extractor.Register(name, func(cfg extractor.Config) (extractor.DBExtractor, error) {
+		ext, err := factory(ExtractorConfig{
+			Host:          cfg.Host,
+			Port:          cfg.Port,
+			Database:      cfg.Database,
+			Username:      cfg.Username,
+			Password:      cfg.Password,
+			SSLMode:       cfg.SSLMode,
+			SchemaFilter:  cfg.SchemaFilter,
+			ExcludeSystem: cfg.ExcludeSystem,
+			Options:       cfg.Options,
+			Logger:        cfg.Logger,
+			QueryTimeout:  cfg.QueryTimeout,
+			Hooks:         cfg.Hooks,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return ext, nil
+	}, aliases...)
+}
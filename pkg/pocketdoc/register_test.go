@@ -0,0 +1,167 @@
+package pocketdoc_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"pocket-doc/pkg/pocketdoc"
+	"slices"
+	"testing"
+)
+
+// tableList writes the table names of a schema, one per line
+type tableList struct {
+	cfg pocketdoc.ExporterConfig
+}
+
+func (e tableList) Export(ctx context.Context, schema *pocketdoc.Schema, w io.Writer) error {
+	fmt.Fprintf(w, "# %s\n", e.cfg.ProjectName)
+	for _, t := range schema.Tables {
+		fmt.Fprintln(w, t.Name)
+	}
+	return nil
+}
+
+func (tableList) Format() string        { return "tables" }
+func (tableList) MimeType() string      { return "text/plain" }
+func (tableList) FileExtension() string { return ".txt" }
+
+func init() {
+	pocketdoc.RegisterExporter("tables", func(cfg pocketdoc.ExporterConfig) (pocketdoc.Exporter, error) {
+		return tableList{cfg}, nil
+	}, "table-list")
+	pocketdoc.RegisterExporter("broken", func(cfg pocketdoc.ExporterConfig) (pocketdoc.Exporter, error) {
+		return nil, errors.New("not configured")
+	})
+}
+
+// TestRegisterExporter validates a format registered from outside the
+// module is listed and exported through, by name and alias, with the
+// output section of the configuration
+func TestRegisterExporter(t *testing.T) {
+	if !slices.Contains(pocketdoc.Formats(), "tables") {
+		t.Fatalf("Formats() = %v, want tables listed", pocketdoc.Formats())
+	}
+
+	cfg := pocketdoc.DefaultConfig()
+	cfg.Output.ProjectName = "Shop"
+	schema := &pocketdoc.Schema{Tables: []pocketdoc.Table{{Name: "ORDERS"}, {Name: "CUSTOMERS"}}}
+
+	for _, format := range []string{"tables", "TABLE-LIST"} {
+		var buf bytes.Buffer
+		if err := pocketdoc.Export(context.Background(), cfg, schema, format, &buf); err != nil {
+			t.Fatalf("Export(%s) error = %v", format, err)
+		}
+		// objects reach the exporter sorted as output.sort_by describes
+		if want := "# Shop\nCUSTOMERS\nORDERS\n"; buf.String() != want {
+			t.Errorf("Export(%s) = %q, want %q", format, buf.String(), want)
+		}
+	}
+
+	err := pocketdoc.Export(context.Background(), cfg, schema, "broken", io.Discard)
+	if err == nil || !bytes.Contains([]byte(err.Error()), []byte("not configured")) {
+		t.Errorf("Export(broken) error = %v, want the factory error", err)
+	}
+}
+
+// TestRegisterExporterDuplicate validates registering a taken name panics
+func TestRegisterExporterDuplicate(t *testing.T) {
+	for _, name := range []string{"xlsx", "Tables"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterExporter(%s) did not panic", name)
+				}
+			}()
+			pocketdoc.RegisterExporter(name, func(cfg pocketdoc.ExporterConfig) (pocketdoc.Exporter, error) {
+				return tableList{cfg}, nil
+			})
+		}()
+	}
+}
+
+// fixedDB is an extractor returning a fixed set of tables
+type fixedDB struct {
+	cfg       pocketdoc.ExtractorConfig
+	connected bool
+}
+
+func (d *fixedDB) Connect(ctx context.Context) error { d.connected = true; return nil }
+func (d *fixedDB) Close() error                      { return nil }
+
+func (d *fixedDB) GetDatabaseInfo(ctx context.Context) (string, string, error) {
+	return d.cfg.Database, "1.0", nil
+}
+
+func (d *fixedDB) GetTables(ctx context.Context) ([]pocketdoc.Table, error) {
+	var tables []pocketdoc.Table
+	for _, schema := range d.cfg.SchemaFilter {
+		tables = append(tables, pocketdoc.Table{Owner: schema, Name: "EVENTS"})
+	}
+	return tables, nil
+}
+
+func (d *fixedDB) GetViews(ctx context.Context) ([]pocketdoc.View, error)       { return nil, nil }
+func (d *fixedDB) GetRoutines(ctx context.Context) ([]pocketdoc.Routine, error) { return nil, nil }
+func (d *fixedDB) GetSequences(ctx context.Context) ([]pocketdoc.Sequence, error) {
+	return nil, nil
+}
+func (d *fixedDB) GetTriggers(ctx context.Context) ([]pocketdoc.Trigger, error) { return nil, nil }
+func (d *fixedDB) GetSynonyms(ctx context.Context) ([]pocketdoc.Synonym, error) { return nil, nil }
+
+func (d *fixedDB) ExtractSchema(ctx context.Context) (*pocketdoc.Schema, error) {
+	if !d.connected {
+		return nil, errors.New("not connected")
+	}
+	name, version, _ := d.GetDatabaseInfo(ctx)
+	tables, _ := d.GetTables(ctx)
+	return &pocketdoc.Schema{DatabaseName: name, Version: version, Tables: tables}, nil
+}
+
+func init() {
+	pocketdoc.RegisterExtractor("fixeddb", func(cfg pocketdoc.ExtractorConfig) (pocketdoc.Extractor, error) {
+		if cfg.Options["mode"] == "fail" {
+			return nil, errors.New("unsupported mode")
+		}
+		return &fixedDB{cfg: cfg}, nil
+	}, "fixed")
+}
+
+// TestRegisterExtractor validates a database type registered from outside
+// the module is created from the database section, by name and alias,
+// and extracted through
+func TestRegisterExtractor(t *testing.T) {
+	cfg := pocketdoc.DefaultConfig()
+	cfg.Database.Type = "FIXED"
+	cfg.Database.Host = "db.local"
+	cfg.Database.Port = 7000
+	cfg.Database.Database = "events"
+	cfg.Database.SchemaFilter = []string{"APP", "AUDIT"}
+
+	ext, err := pocketdoc.NewExtractor(cfg)
+	if err != nil {
+		t.Fatalf("NewExtractor() error = %v", err)
+	}
+	db, ok := ext.(*fixedDB)
+	if !ok {
+		t.Fatalf("NewExtractor() = %T, want *fixedDB", ext)
+	}
+	if db.cfg.Host != "db.local" || db.cfg.Port != 7000 || db.cfg.Database != "events" {
+		t.Errorf("ExtractorConfig = %+v, want the database section", db.cfg)
+	}
+
+	schema, err := pocketdoc.Extract(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if schema.DatabaseName != "events" || len(schema.Tables) != 2 {
+		t.Errorf("Extract() = %s with %d tables, want events with 2", schema.DatabaseName, len(schema.Tables))
+	}
+
+	cfg.Database.Options = map[string]string{"mode": "fail"}
+	if _, err := pocketdoc.NewExtractor(cfg); err == nil || !bytes.Contains([]byte(err.Error()), []byte("unsupported mode")) {
+		t.Errorf("NewExtractor(mode: fail) error = %v, want the factory error", err)
+	}
+}